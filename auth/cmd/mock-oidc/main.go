@@ -0,0 +1,42 @@
+// Command mock-oidc runs a local OpenID Connect provider for trying the
+// social sign-in without registering a client at a real provider. Every
+// authorization is approved for the user configured by environment.
+package main
+
+import (
+	"log"
+	"net/http"
+	"os"
+
+	"github.com/Deevins/final-task-course-2-go-lang/auth/internal/oidc/oidctest"
+)
+
+func main() {
+	addr := getEnv("MOCK_OIDC_ADDR", ":9096")
+	provider, err := oidctest.New(
+		getEnv("MOCK_OIDC_ISSUER", "http://localhost:9096"),
+		getEnv("MOCK_OIDC_CLIENT_ID", "ledger"),
+		getEnv("MOCK_OIDC_CLIENT_SECRET", "secret"),
+		oidctest.User{
+			Subject:       getEnv("MOCK_OIDC_SUBJECT", "mock-user"),
+			Email:         getEnv("MOCK_OIDC_EMAIL", "user@example.com"),
+			EmailVerified: getEnv("MOCK_OIDC_EMAIL_VERIFIED", "true") == "true",
+			Name:          getEnv("MOCK_OIDC_NAME", "Mock User"),
+		},
+	)
+	if err != nil {
+		log.Fatalf("init provider: %v", err)
+	}
+
+	log.Printf("mock OIDC provider %s listening on %s", provider.Issuer, addr)
+	if err := http.ListenAndServe(addr, provider); err != nil {
+		log.Fatalf("server error: %v", err)
+	}
+}
+
+func getEnv(key, fallback string) string {
+	if value, ok := os.LookupEnv(key); ok {
+		return value
+	}
+	return fallback
+}
@@ -12,18 +12,31 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 	"golang.org/x/sync/errgroup"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
 
 	"github.com/Deevins/final-task-course-2-go-lang/auth/internal/config"
 	"github.com/Deevins/final-task-course-2-go-lang/auth/internal/grpcserver"
 	httpHandler "github.com/Deevins/final-task-course-2-go-lang/auth/internal/handler/http"
+	"github.com/Deevins/final-task-course-2-go-lang/auth/internal/ledgerpurge"
+	"github.com/Deevins/final-task-course-2-go-lang/auth/internal/mailer"
 	pb "github.com/Deevins/final-task-course-2-go-lang/auth/internal/pb/auth/v1"
+	ledgerv1 "github.com/Deevins/final-task-course-2-go-lang/auth/internal/pb/ledger/v1"
 	"github.com/Deevins/final-task-course-2-go-lang/auth/internal/repository"
 	"github.com/Deevins/final-task-course-2-go-lang/auth/internal/router"
 	"github.com/Deevins/final-task-course-2-go-lang/auth/internal/service"
+	"github.com/Deevins/final-task-course-2-go-lang/auth/internal/signing"
 	"github.com/Deevins/final-task-course-2-go-lang/auth/internal/storage"
 )
 
+// keyRotationCheckInterval is how often signing keys are reloaded and rotated when due.
+const keyRotationCheckInterval = time.Minute
+
 type App struct {
+	authService     *service.DefaultAuthService
+	keyring         *signing.Keyring
+	cleanupInterval time.Duration
+	purgeWorker     *ledgerpurge.Worker
+	ledgerConn      *grpc.ClientConn
 	httpServer      *http.Server
 	grpcServer      *grpc.Server
 	grpcListener    net.Listener
@@ -42,26 +55,62 @@ func New(ctx context.Context, cfg config.Config) (*App, error) {
 	}
 
 	repo := repository.NewPostgresAuthRepository(db)
-	authService := service.NewAuthService(repo, cfg.JWT)
+	keyring := signing.NewKeyring(repo, cfg.JWT)
+	if err := keyring.Rotate(ctx); err != nil {
+		db.Close()
+		return nil, err
+	}
+	mail, err := mailer.New(cfg.Email)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	passwordPolicy, err := service.NewPasswordPolicy(cfg.Password)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	authService := service.NewAuthService(repo, cfg.JWT, keyring, mail, cfg.Email, cfg.TwoFactor, cfg.Login, passwordPolicy, cfg.OIDC)
 
 	healthHandler := httpHandler.NewHealthHandler()
-	router.Register(engine, healthHandler)
+	jwksHandler := httpHandler.NewJWKSHandler(keyring)
+	router.Register(engine, healthHandler, jwksHandler)
 
 	httpServer := &http.Server{
 		Addr:    ":" + cfg.HTTPPort,
 		Handler: engine,
 	}
 
+	ledgerConn, err := grpc.NewClient(cfg.LedgerGRPCAddress, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	purgeWorker := ledgerpurge.NewWorker(
+		repo,
+		ledgerpurge.NewGRPCLedger(ledgerv1.NewLedgerServiceClient(ledgerConn), cfg.ServiceToken),
+		cfg.AccountPurgeInterval,
+	)
+
 	lis, err := net.Listen("tcp", ":"+cfg.GRPCPort)
 	if err != nil {
+		_ = ledgerConn.Close()
 		db.Close()
 		return nil, err
 	}
 
-	grpcSrv := grpc.NewServer()
+	if cfg.ServiceToken == "" {
+		log.Printf("SERVICE_TOKEN is not set: client addresses forwarded by the gateway are ignored")
+	}
+	grpcSrv := grpc.NewServer(grpc.UnaryInterceptor(grpcserver.ClientIPUnaryInterceptor(cfg.ServiceToken)))
 	pb.RegisterAuthServiceServer(grpcSrv, grpcserver.NewAuthServer(authService))
 
 	return &App{
+		authService:     authService,
+		keyring:         keyring,
+		cleanupInterval: cfg.TokenCleanupInterval,
+		purgeWorker:     purgeWorker,
+		ledgerConn:      ledgerConn,
 		httpServer:      httpServer,
 		grpcServer:      grpcSrv,
 		grpcListener:    lis,
@@ -91,6 +140,20 @@ func (a *App) Run(ctx context.Context) error {
 		return nil
 	})
 
+	g.Go(func() error {
+		a.runTokenCleanup(gctx)
+		return nil
+	})
+
+	g.Go(func() error {
+		a.keyring.Run(gctx, keyRotationCheckInterval)
+		return nil
+	})
+
+	g.Go(func() error {
+		return a.purgeWorker.Run(gctx)
+	})
+
 	g.Go(func() error {
 		<-gctx.Done()
 		shutdownCtx, cancel := context.WithTimeout(ctx, a.shutdownTimeout)
@@ -100,6 +163,9 @@ func (a *App) Run(ctx context.Context) error {
 			log.Printf("HTTP shutdown error: %v", err)
 		}
 		a.grpcServer.GracefulStop()
+		if a.ledgerConn != nil {
+			_ = a.ledgerConn.Close()
+		}
 		if a.db != nil {
 			a.db.Close()
 		}
@@ -111,3 +177,25 @@ func (a *App) Run(ctx context.Context) error {
 	}
 	return nil
 }
+
+// runTokenCleanup periodically removes expired refresh tokens and denylist entries.
+func (a *App) runTokenCleanup(ctx context.Context) {
+	ticker := time.NewTicker(a.cleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			deleted, err := a.authService.PurgeExpiredTokens(ctx)
+			if err != nil {
+				log.Printf("token cleanup error: %v", err)
+				continue
+			}
+			if deleted > 0 {
+				log.Printf("token cleanup removed %d expired records", deleted)
+			}
+		}
+	}
+}
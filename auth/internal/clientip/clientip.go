@@ -0,0 +1,23 @@
+// Package clientip carries the address of the client a request originates from.
+package clientip
+
+import "context"
+
+// MetadataKey is the gRPC metadata key the gateway uses to pass the address
+// of the HTTP client.
+const MetadataKey = "x-client-ip"
+
+// ServiceTokenMetadataKey is the gRPC metadata key internal services use to
+// pass the shared service token. MetadataKey is trusted only next to it.
+const ServiceTokenMetadataKey = "x-service-token"
+
+type contextKey struct{}
+
+func WithIP(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, contextKey{}, ip)
+}
+
+func FromContext(ctx context.Context) string {
+	ip, _ := ctx.Value(contextKey{}).(string)
+	return ip
+}
@@ -1,12 +1,35 @@
 package config
 
-import "os"
+import (
+	"os"
+	"time"
+)
+
+const (
+	DefaultTokenCleanupInterval = time.Hour
+	DefaultAccountPurgeInterval = 30 * time.Second
+)
 
 type Config struct {
 	HTTPPort    string
 	GRPCPort    string
 	PostgresDSN string
 	JWT         JWTConfig
+	Email       EmailConfig
+	TwoFactor   TwoFactorConfig
+	Login       LoginThrottleConfig
+	Password    PasswordPolicyConfig
+	OIDC        OIDCConfig
+	// TokenCleanupInterval is how often expired refresh tokens and denylist entries are removed.
+	TokenCleanupInterval time.Duration
+	// LedgerGRPCAddress is the ledger service that purges the data of deleted accounts.
+	LedgerGRPCAddress string
+	// AccountPurgeInterval is how often pending ledger purges of deleted accounts are retried.
+	AccountPurgeInterval time.Duration
+	// ServiceToken is the secret shared by the internal services. Auth trusts
+	// the client address forwarded by the gateway only on calls carrying it,
+	// and sends it with the purges of deleted accounts to the ledger.
+	ServiceToken string
 }
 
 func Load() (Config, error) {
@@ -14,12 +37,49 @@ func Load() (Config, error) {
 	if err != nil {
 		return Config{}, err
 	}
+	emailConfig, err := LoadEmailConfig()
+	if err != nil {
+		return Config{}, err
+	}
+	twoFactorConfig, err := LoadTwoFactorConfig()
+	if err != nil {
+		return Config{}, err
+	}
+	loginConfig, err := LoadLoginThrottleConfig()
+	if err != nil {
+		return Config{}, err
+	}
+	passwordConfig, err := LoadPasswordPolicyConfig()
+	if err != nil {
+		return Config{}, err
+	}
+	oidcConfig, err := LoadOIDCConfig()
+	if err != nil {
+		return Config{}, err
+	}
+	cleanupInterval, err := loadPositiveDuration("TOKEN_CLEANUP_INTERVAL", DefaultTokenCleanupInterval)
+	if err != nil {
+		return Config{}, err
+	}
+	purgeInterval, err := loadPositiveDuration("ACCOUNT_PURGE_INTERVAL", DefaultAccountPurgeInterval)
+	if err != nil {
+		return Config{}, err
+	}
 
 	return Config{
-		HTTPPort:    getEnv("HTTP_PORT", "8082"),
-		GRPCPort:    getEnv("GRPC_PORT", "9092"),
-		PostgresDSN: getEnv("AUTH_POSTGRES_DSN", ""),
-		JWT:         jwtConfig,
+		HTTPPort:             getEnv("HTTP_PORT", "8082"),
+		GRPCPort:             getEnv("GRPC_PORT", "9092"),
+		PostgresDSN:          getEnv("AUTH_POSTGRES_DSN", ""),
+		JWT:                  jwtConfig,
+		Email:                emailConfig,
+		TwoFactor:            twoFactorConfig,
+		Login:                loginConfig,
+		Password:             passwordConfig,
+		OIDC:                 oidcConfig,
+		TokenCleanupInterval: cleanupInterval,
+		LedgerGRPCAddress:    getEnv("LEDGER_GRPC_ADDRESS", "127.0.0.1:9091"),
+		AccountPurgeInterval: purgeInterval,
+		ServiceToken:         getEnv("SERVICE_TOKEN", ""),
 	}, nil
 }
 
@@ -0,0 +1,70 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	DefaultEmailVerificationTTL = 24 * time.Hour
+	DefaultPasswordResetTTL     = time.Hour
+
+	MailerSMTP = "smtp"
+	MailerFile = "file"
+	MailerLog  = "log"
+)
+
+type EmailConfig struct {
+	// Mailer selects how emails are delivered: smtp, file or log.
+	Mailer string
+	From   string
+
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
+
+	// FileDir is where the file mailer writes messages.
+	FileDir string
+
+	// BaseURL is the address of the web client used in links sent by email.
+	BaseURL          string
+	VerificationTTL  time.Duration
+	PasswordResetTTL time.Duration
+}
+
+func LoadEmailConfig() (EmailConfig, error) {
+	verificationTTL, err := loadPositiveDuration("EMAIL_VERIFICATION_TTL", DefaultEmailVerificationTTL)
+	if err != nil {
+		return EmailConfig{}, err
+	}
+	resetTTL, err := loadPositiveDuration("PASSWORD_RESET_TTL", DefaultPasswordResetTTL)
+	if err != nil {
+		return EmailConfig{}, err
+	}
+
+	cfg := EmailConfig{
+		Mailer:           getEnv("MAILER", MailerLog),
+		From:             getEnv("MAIL_FROM", "no-reply@localhost"),
+		SMTPHost:         getEnv("SMTP_HOST", ""),
+		SMTPPort:         getEnv("SMTP_PORT", "587"),
+		SMTPUsername:     getEnv("SMTP_USERNAME", ""),
+		SMTPPassword:     getEnv("SMTP_PASSWORD", ""),
+		FileDir:          getEnv("MAIL_FILE_DIR", "mail"),
+		BaseURL:          getEnv("APP_BASE_URL", "http://localhost:8081"),
+		VerificationTTL:  verificationTTL,
+		PasswordResetTTL: resetTTL,
+	}
+
+	switch cfg.Mailer {
+	case MailerSMTP:
+		if cfg.SMTPHost == "" {
+			return EmailConfig{}, fmt.Errorf("SMTP_HOST is required when MAILER is %s", MailerSMTP)
+		}
+	case MailerFile, MailerLog:
+	default:
+		return EmailConfig{}, fmt.Errorf("MAILER must be smtp, file or log, got %q", cfg.Mailer)
+	}
+
+	return cfg, nil
+}
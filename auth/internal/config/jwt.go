@@ -2,35 +2,79 @@ package config
 
 import (
 	"errors"
+	"fmt"
 	"os"
 	"time"
 )
 
-const DefaultJWTExpiry = 24 * time.Hour
+const (
+	DefaultJWTExpiry        = 15 * time.Minute
+	DefaultJWTRefreshExpiry = 30 * 24 * time.Hour
+	DefaultJWTAlgorithm     = "EdDSA"
+	DefaultJWTKeyRotation   = 7 * 24 * time.Hour
+	DefaultJWTKeyGrace      = 24 * time.Hour
+)
 
 type JWTConfig struct {
-	Secret string
-	Expiry time.Duration
+	Expiry        time.Duration
+	RefreshExpiry time.Duration
+	// Algorithm is the signing algorithm of new keys: RS256 or EdDSA.
+	Algorithm string
+	// KeyRotation is how long a key is used to sign new tokens.
+	KeyRotation time.Duration
+	// KeyGracePeriod is how long a retired key is still published and accepted.
+	// It must cover the access token lifetime.
+	KeyGracePeriod time.Duration
 }
 
 func LoadJWTConfig() (JWTConfig, error) {
-	secret := os.Getenv("JWT_SECRET")
-	if secret == "" {
-		return JWTConfig{}, errors.New("JWT_SECRET is required")
+	expiry, err := loadPositiveDuration("JWT_EXPIRY", DefaultJWTExpiry)
+	if err != nil {
+		return JWTConfig{}, err
+	}
+	refreshExpiry, err := loadPositiveDuration("JWT_REFRESH_EXPIRY", DefaultJWTRefreshExpiry)
+	if err != nil {
+		return JWTConfig{}, err
+	}
+	rotation, err := loadPositiveDuration("JWT_KEY_ROTATION", DefaultJWTKeyRotation)
+	if err != nil {
+		return JWTConfig{}, err
+	}
+	grace, err := loadPositiveDuration("JWT_KEY_GRACE_PERIOD", DefaultJWTKeyGrace)
+	if err != nil {
+		return JWTConfig{}, err
+	}
+	if grace < expiry {
+		return JWTConfig{}, errors.New("JWT_KEY_GRACE_PERIOD must not be shorter than JWT_EXPIRY")
 	}
 
-	expiryValue := os.Getenv("JWT_EXPIRY")
-	if expiryValue == "" {
-		return JWTConfig{Secret: secret, Expiry: DefaultJWTExpiry}, nil
+	algorithm := getEnv("JWT_SIGNING_ALGORITHM", DefaultJWTAlgorithm)
+	if algorithm != "RS256" && algorithm != "EdDSA" {
+		return JWTConfig{}, fmt.Errorf("JWT_SIGNING_ALGORITHM must be RS256 or EdDSA, got %q", algorithm)
 	}
 
-	expiry, err := time.ParseDuration(expiryValue)
+	return JWTConfig{
+		Expiry:         expiry,
+		RefreshExpiry:  refreshExpiry,
+		Algorithm:      algorithm,
+		KeyRotation:    rotation,
+		KeyGracePeriod: grace,
+	}, nil
+}
+
+func loadPositiveDuration(key string, fallback time.Duration) (time.Duration, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback, nil
+	}
+
+	duration, err := time.ParseDuration(value)
 	if err != nil {
-		return JWTConfig{}, err
+		return 0, err
 	}
-	if expiry <= 0 {
-		return JWTConfig{}, errors.New("JWT_EXPIRY must be positive")
+	if duration <= 0 {
+		return 0, errors.New(key + " must be positive")
 	}
 
-	return JWTConfig{Secret: secret, Expiry: expiry}, nil
+	return duration, nil
 }
@@ -0,0 +1,82 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+const (
+	DefaultLoginAccountMaxFailures = 5
+	DefaultLoginIPMaxFailures      = 20
+	DefaultLoginBackoffBase        = time.Second
+	DefaultLoginBackoffMax         = time.Minute
+	DefaultLoginLockout            = 15 * time.Minute
+	DefaultLoginFailureWindow      = time.Hour
+)
+
+// LoginThrottleConfig limits failed sign-in attempts. After each failure the
+// account and the client address have to wait BackoffBase, doubled with every
+// further failure up to BackoffMax. Reaching the max failures locks them for
+// Lockout. Failures older than FailureWindow are forgotten.
+type LoginThrottleConfig struct {
+	AccountMaxFailures int
+	IPMaxFailures      int
+	BackoffBase        time.Duration
+	BackoffMax         time.Duration
+	Lockout            time.Duration
+	FailureWindow      time.Duration
+}
+
+func LoadLoginThrottleConfig() (LoginThrottleConfig, error) {
+	accountMax, err := loadPositiveInt("LOGIN_ACCOUNT_MAX_FAILURES", DefaultLoginAccountMaxFailures)
+	if err != nil {
+		return LoginThrottleConfig{}, err
+	}
+	ipMax, err := loadPositiveInt("LOGIN_IP_MAX_FAILURES", DefaultLoginIPMaxFailures)
+	if err != nil {
+		return LoginThrottleConfig{}, err
+	}
+	base, err := loadPositiveDuration("LOGIN_BACKOFF_BASE", DefaultLoginBackoffBase)
+	if err != nil {
+		return LoginThrottleConfig{}, err
+	}
+	backoffMax, err := loadPositiveDuration("LOGIN_BACKOFF_MAX", DefaultLoginBackoffMax)
+	if err != nil {
+		return LoginThrottleConfig{}, err
+	}
+	lockout, err := loadPositiveDuration("LOGIN_LOCKOUT", DefaultLoginLockout)
+	if err != nil {
+		return LoginThrottleConfig{}, err
+	}
+	window, err := loadPositiveDuration("LOGIN_FAILURE_WINDOW", DefaultLoginFailureWindow)
+	if err != nil {
+		return LoginThrottleConfig{}, err
+	}
+	if window < lockout {
+		return LoginThrottleConfig{}, errors.New("LOGIN_FAILURE_WINDOW must not be shorter than LOGIN_LOCKOUT")
+	}
+
+	return LoginThrottleConfig{
+		AccountMaxFailures: accountMax,
+		IPMaxFailures:      ipMax,
+		BackoffBase:        base,
+		BackoffMax:         backoffMax,
+		Lockout:            lockout,
+		FailureWindow:      window,
+	}, nil
+}
+
+func loadPositiveInt(key string, fallback int) (int, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback, nil
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil || parsed <= 0 {
+		return 0, fmt.Errorf("%s must be a positive integer, got %q", key, value)
+	}
+	return parsed, nil
+}
@@ -0,0 +1,77 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const DefaultOIDCLoginTTL = 10 * time.Minute
+
+var providerNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+// OIDCConfig lists the external identity providers users can sign in with.
+type OIDCConfig struct {
+	Providers []OIDCProviderConfig
+	// LoginTTL is how long a started sign-in waits for the provider callback.
+	LoginTTL time.Duration
+}
+
+// OIDCProviderConfig is an OpenID Connect provider registered as a client.
+// Endpoints and keys are discovered from the issuer.
+type OIDCProviderConfig struct {
+	// Name identifies the provider in routes, e.g. google.
+	Name         string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	// RedirectURL is the gateway callback registered at the provider.
+	RedirectURL string
+	Scopes      []string
+}
+
+// LoadOIDCConfig reads the providers named in OIDC_PROVIDERS. Each provider
+// is configured by OIDC_<NAME>_ISSUER, _CLIENT_ID, _CLIENT_SECRET and
+// optionally _REDIRECT_URL and _SCOPES, where NAME is the upper-cased name
+// with dashes replaced by underscores.
+func LoadOIDCConfig() (OIDCConfig, error) {
+	loginTTL, err := loadPositiveDuration("OIDC_LOGIN_TTL", DefaultOIDCLoginTTL)
+	if err != nil {
+		return OIDCConfig{}, err
+	}
+	cfg := OIDCConfig{LoginTTL: loginTTL}
+
+	baseURL := strings.TrimRight(getEnv("APP_BASE_URL", "http://localhost:8081"), "/")
+	seen := make(map[string]bool)
+	for _, name := range strings.Split(os.Getenv("OIDC_PROVIDERS"), ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if !providerNamePattern.MatchString(name) {
+			return OIDCConfig{}, fmt.Errorf("OIDC_PROVIDERS: invalid provider name %q", name)
+		}
+		if seen[name] {
+			return OIDCConfig{}, fmt.Errorf("OIDC_PROVIDERS: duplicate provider %q", name)
+		}
+		seen[name] = true
+
+		prefix := "OIDC_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_")) + "_"
+		provider := OIDCProviderConfig{
+			Name:         name,
+			Issuer:       strings.TrimRight(os.Getenv(prefix+"ISSUER"), "/"),
+			ClientID:     os.Getenv(prefix + "CLIENT_ID"),
+			ClientSecret: os.Getenv(prefix + "CLIENT_SECRET"),
+			RedirectURL:  getEnv(prefix+"REDIRECT_URL", baseURL+"/api/auth/oidc/"+name+"/callback"),
+			Scopes:       strings.Fields(getEnv(prefix+"SCOPES", "openid email profile")),
+		}
+		if provider.Issuer == "" || provider.ClientID == "" {
+			return OIDCConfig{}, fmt.Errorf("%sISSUER and %sCLIENT_ID are required", prefix, prefix)
+		}
+		cfg.Providers = append(cfg.Providers, provider)
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,57 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+const (
+	DefaultPasswordMinLength      = 10
+	DefaultPasswordMinCharClasses = 2
+	// MaxPasswordBytes is the longest input bcrypt hashes; longer passwords
+	// would be silently truncated.
+	MaxPasswordBytes = 72
+)
+
+// PasswordPolicyConfig describes which new passwords are accepted.
+// MinCharClasses counts lowercase letters, uppercase letters, digits and
+// other characters. BreachedListPath is a file of known breached passwords,
+// one per line; an empty path disables the check.
+type PasswordPolicyConfig struct {
+	MinLength        int
+	MaxBytes         int
+	MinCharClasses   int
+	BreachedListPath string
+}
+
+func LoadPasswordPolicyConfig() (PasswordPolicyConfig, error) {
+	minLength, err := loadPositiveInt("PASSWORD_MIN_LENGTH", DefaultPasswordMinLength)
+	if err != nil {
+		return PasswordPolicyConfig{}, err
+	}
+	maxBytes, err := loadPositiveInt("PASSWORD_MAX_BYTES", MaxPasswordBytes)
+	if err != nil {
+		return PasswordPolicyConfig{}, err
+	}
+	if maxBytes > MaxPasswordBytes {
+		return PasswordPolicyConfig{}, fmt.Errorf("PASSWORD_MAX_BYTES must not exceed %d", MaxPasswordBytes)
+	}
+	if minLength > maxBytes {
+		return PasswordPolicyConfig{}, errors.New("PASSWORD_MIN_LENGTH must not exceed PASSWORD_MAX_BYTES")
+	}
+	minClasses, err := loadPositiveInt("PASSWORD_MIN_CHAR_CLASSES", DefaultPasswordMinCharClasses)
+	if err != nil {
+		return PasswordPolicyConfig{}, err
+	}
+	if minClasses > 4 {
+		return PasswordPolicyConfig{}, errors.New("PASSWORD_MIN_CHAR_CLASSES must be between 1 and 4")
+	}
+
+	return PasswordPolicyConfig{
+		MinLength:        minLength,
+		MaxBytes:         maxBytes,
+		MinCharClasses:   minClasses,
+		BreachedListPath: os.Getenv("PASSWORD_BREACHED_LIST"),
+	}, nil
+}
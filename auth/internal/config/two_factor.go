@@ -0,0 +1,45 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+const (
+	DefaultTOTPIssuer         = "Ledger"
+	DefaultTOTPSkew           = 1
+	DefaultSignInChallengeTTL = 5 * time.Minute
+)
+
+type TwoFactorConfig struct {
+	// Issuer is the name authenticator apps show next to the account.
+	Issuer string
+	// Skew is how many 30 second steps before and after the current one are
+	// accepted to tolerate clock drift.
+	Skew int
+	// ChallengeTTL is how long a sign-in waits for the one-time code.
+	ChallengeTTL time.Duration
+}
+
+func LoadTwoFactorConfig() (TwoFactorConfig, error) {
+	challengeTTL, err := loadPositiveDuration("SIGN_IN_CHALLENGE_TTL", DefaultSignInChallengeTTL)
+	if err != nil {
+		return TwoFactorConfig{}, err
+	}
+
+	skew := DefaultTOTPSkew
+	if value := os.Getenv("TOTP_SKEW"); value != "" {
+		skew, err = strconv.Atoi(value)
+		if err != nil || skew < 0 || skew > 10 {
+			return TwoFactorConfig{}, fmt.Errorf("TOTP_SKEW must be between 0 and 10, got %q", value)
+		}
+	}
+
+	return TwoFactorConfig{
+		Issuer:       getEnv("TOTP_ISSUER", DefaultTOTPIssuer),
+		Skew:         skew,
+		ChallengeTTL: challengeTTL,
+	}, nil
+}
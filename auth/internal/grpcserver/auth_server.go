@@ -3,12 +3,18 @@ package grpcserver
 import (
 	"context"
 	"errors"
+	"strings"
+	"time"
 
+	"github.com/Deevins/final-task-course-2-go-lang/auth/internal/model"
+	"github.com/Deevins/final-task-course-2-go-lang/auth/internal/oidc"
 	pb "github.com/Deevins/final-task-course-2-go-lang/auth/internal/pb/auth/v1"
 	"github.com/Deevins/final-task-course-2-go-lang/auth/internal/service"
 
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
@@ -30,6 +36,9 @@ func (s *AuthServer) SignUp(ctx context.Context, req *pb.SignUpRequest) (*pb.Sig
 
 	user, err := s.authService.Register(ctx, req.GetEmail(), req.GetPassword(), req.GetName())
 	if err != nil {
+		if st, ok := weakPasswordStatus("password", err); ok {
+			return nil, st
+		}
 		if service.IsDuplicate(err) {
 			return nil, status.Error(codes.AlreadyExists, "user already exists")
 		}
@@ -49,17 +58,33 @@ func (s *AuthServer) SignIn(ctx context.Context, req *pb.SignInRequest) (*pb.Sig
 		if errors.Is(err, service.ErrInvalidCredentials) {
 			return nil, status.Error(codes.Unauthenticated, "invalid credentials")
 		}
-		if service.IsNotFound(err) {
-			return nil, status.Error(codes.NotFound, "user not found")
+		if st, ok := throttledStatus(err); ok {
+			return nil, st
 		}
 
 		return nil, status.Errorf(codes.Internal, "login: %v", err)
 	}
 
-	return &pb.SignInResponse{
-		AccessToken: token.AccessToken,
-		ExpiresAt:   timestamppb.New(token.ExpiresAt),
-	}, nil
+	return toSignInResponse(token), nil
+}
+
+func (s *AuthServer) VerifyTwoFactor(ctx context.Context, req *pb.VerifyTwoFactorRequest) (*pb.SignInResponse, error) {
+	if req.GetChallengeToken() == "" || req.GetCode() == "" {
+		return nil, status.Error(codes.InvalidArgument, "challenge_token and code are required")
+	}
+
+	token, err := s.authService.VerifyTwoFactor(ctx, req.GetChallengeToken(), req.GetCode())
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidChallenge) || errors.Is(err, service.ErrInvalidTwoFactorCode) {
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		}
+		if st, ok := throttledStatus(err); ok {
+			return nil, st
+		}
+		return nil, status.Errorf(codes.Internal, "verify two-factor: %v", err)
+	}
+
+	return toSignInResponse(token), nil
 }
 
 func (s *AuthServer) ValidateToken(ctx context.Context, req *pb.ValidateTokenRequest) (*pb.ValidateTokenResponse, error) {
@@ -77,8 +102,491 @@ func (s *AuthServer) ValidateToken(ctx context.Context, req *pb.ValidateTokenReq
 	}
 
 	return &pb.ValidateTokenResponse{
-		Valid:     valid,
-		UserId:    token.UserID,
-		ExpiresAt: timestamppb.New(token.ExpiresAt),
+		Valid:         valid,
+		UserId:        token.UserID,
+		ExpiresAt:     timestamppb.New(token.ExpiresAt),
+		EmailVerified: token.EmailVerified,
+		Roles:         token.Roles,
 	}, nil
 }
+
+func (s *AuthServer) Refresh(ctx context.Context, req *pb.RefreshRequest) (*pb.RefreshResponse, error) {
+	if req.GetRefreshToken() == "" {
+		return nil, status.Error(codes.InvalidArgument, "refresh_token is required")
+	}
+
+	token, err := s.authService.Refresh(ctx, req.GetRefreshToken())
+	if err != nil {
+		if isSessionError(err) {
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		}
+		return nil, status.Errorf(codes.Internal, "refresh: %v", err)
+	}
+
+	return &pb.RefreshResponse{
+		AccessToken:      token.AccessToken,
+		ExpiresAt:        timestamppb.New(token.ExpiresAt),
+		RefreshToken:     token.RefreshToken,
+		RefreshExpiresAt: timestamppb.New(token.RefreshExpiresAt),
+	}, nil
+}
+
+func (s *AuthServer) Logout(ctx context.Context, req *pb.LogoutRequest) (*pb.LogoutResponse, error) {
+	if req.GetAccessToken() == "" {
+		return nil, status.Error(codes.InvalidArgument, "access_token is required")
+	}
+
+	if err := s.authService.Logout(ctx, req.GetAccessToken(), req.GetRefreshToken()); err != nil {
+		if isSessionError(err) {
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		}
+		return nil, status.Errorf(codes.Internal, "logout: %v", err)
+	}
+
+	return &pb.LogoutResponse{}, nil
+}
+
+func (s *AuthServer) RevokeAllSessions(ctx context.Context, req *pb.RevokeAllSessionsRequest) (*pb.RevokeAllSessionsResponse, error) {
+	if req.GetAccessToken() == "" {
+		return nil, status.Error(codes.InvalidArgument, "access_token is required")
+	}
+
+	revoked, err := s.authService.RevokeAllSessions(ctx, req.GetAccessToken())
+	if err != nil {
+		if isSessionError(err) {
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		}
+		return nil, status.Errorf(codes.Internal, "revoke all sessions: %v", err)
+	}
+
+	return &pb.RevokeAllSessionsResponse{RevokedSessions: revoked}, nil
+}
+
+func (s *AuthServer) ListRevokedTokens(ctx context.Context, req *pb.ListRevokedTokensRequest) (*pb.ListRevokedTokensResponse, error) {
+	var since time.Time
+	if req.GetSince() != nil {
+		since = req.GetSince().AsTime()
+	}
+
+	tokens, users, asOf, err := s.authService.ListRevokedTokens(ctx, since)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "list revoked tokens: %v", err)
+	}
+
+	resp := &pb.ListRevokedTokensResponse{
+		Tokens: make([]*pb.RevokedToken, 0, len(tokens)),
+		AsOf:   timestamppb.New(asOf),
+		Users:  make([]*pb.RevokedUser, 0, len(users)),
+	}
+	for _, token := range tokens {
+		resp.Tokens = append(resp.Tokens, &pb.RevokedToken{
+			Jti:       token.ID,
+			ExpiresAt: timestamppb.New(token.ExpiresAt),
+		})
+	}
+	for _, user := range users {
+		resp.Users = append(resp.Users, &pb.RevokedUser{
+			UserId:        user.UserID,
+			RevokedBefore: timestamppb.New(user.RevokedBefore),
+			ExpiresAt:     timestamppb.New(user.ExpiresAt),
+		})
+	}
+	return resp, nil
+}
+
+func (s *AuthServer) GetMe(ctx context.Context, req *pb.GetMeRequest) (*pb.UserResponse, error) {
+	if req.GetAccessToken() == "" {
+		return nil, status.Error(codes.InvalidArgument, "access_token is required")
+	}
+
+	user, err := s.authService.GetMe(ctx, req.GetAccessToken())
+	if err != nil {
+		return nil, profileError("get me", err)
+	}
+
+	return &pb.UserResponse{User: toProtoUser(user)}, nil
+}
+
+func (s *AuthServer) UpdateProfile(ctx context.Context, req *pb.UpdateProfileRequest) (*pb.UserResponse, error) {
+	if req.GetAccessToken() == "" {
+		return nil, status.Error(codes.InvalidArgument, "access_token is required")
+	}
+	if strings.TrimSpace(req.GetName()) == "" {
+		return nil, status.Error(codes.InvalidArgument, "name is required")
+	}
+
+	user, err := s.authService.UpdateProfile(ctx, req.GetAccessToken(), strings.TrimSpace(req.GetName()))
+	if err != nil {
+		return nil, profileError("update profile", err)
+	}
+
+	return &pb.UserResponse{User: toProtoUser(user)}, nil
+}
+
+func (s *AuthServer) ChangePassword(ctx context.Context, req *pb.ChangePasswordRequest) (*pb.ChangePasswordResponse, error) {
+	if req.GetAccessToken() == "" {
+		return nil, status.Error(codes.InvalidArgument, "access_token is required")
+	}
+	if req.GetCurrentPassword() == "" || req.GetNewPassword() == "" {
+		return nil, status.Error(codes.InvalidArgument, "current_password and new_password are required")
+	}
+
+	token, err := s.authService.ChangePassword(ctx, req.GetAccessToken(), req.GetCurrentPassword(), req.GetNewPassword())
+	if err != nil {
+		if st, ok := weakPasswordStatus("new_password", err); ok {
+			return nil, st
+		}
+		return nil, profileError("change password", err)
+	}
+
+	return &pb.ChangePasswordResponse{
+		AccessToken:      token.AccessToken,
+		ExpiresAt:        timestamppb.New(token.ExpiresAt),
+		RefreshToken:     token.RefreshToken,
+		RefreshExpiresAt: timestamppb.New(token.RefreshExpiresAt),
+	}, nil
+}
+
+func (s *AuthServer) DeleteAccount(ctx context.Context, req *pb.DeleteAccountRequest) (*pb.DeleteAccountResponse, error) {
+	if req.GetAccessToken() == "" {
+		return nil, status.Error(codes.InvalidArgument, "access_token is required")
+	}
+	if req.GetPassword() == "" {
+		return nil, status.Error(codes.InvalidArgument, "password is required")
+	}
+
+	if err := s.authService.DeleteAccount(ctx, req.GetAccessToken(), req.GetPassword()); err != nil {
+		return nil, profileError("delete account", err)
+	}
+
+	return &pb.DeleteAccountResponse{}, nil
+}
+
+func (s *AuthServer) RequestEmailVerification(ctx context.Context, req *pb.RequestEmailVerificationRequest) (*pb.RequestEmailVerificationResponse, error) {
+	if req.GetAccessToken() == "" {
+		return nil, status.Error(codes.InvalidArgument, "access_token is required")
+	}
+
+	if err := s.authService.RequestEmailVerification(ctx, req.GetAccessToken()); err != nil {
+		if errors.Is(err, service.ErrEmailAlreadyVerified) {
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		}
+		return nil, profileError("request email verification", err)
+	}
+
+	return &pb.RequestEmailVerificationResponse{}, nil
+}
+
+func (s *AuthServer) ConfirmEmail(ctx context.Context, req *pb.ConfirmEmailRequest) (*pb.ConfirmEmailResponse, error) {
+	if req.GetToken() == "" {
+		return nil, status.Error(codes.InvalidArgument, "token is required")
+	}
+
+	if err := s.authService.ConfirmEmail(ctx, req.GetToken()); err != nil {
+		if errors.Is(err, service.ErrInvalidEmailToken) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		return nil, status.Errorf(codes.Internal, "confirm email: %v", err)
+	}
+
+	return &pb.ConfirmEmailResponse{}, nil
+}
+
+func (s *AuthServer) RequestPasswordReset(ctx context.Context, req *pb.RequestPasswordResetRequest) (*pb.RequestPasswordResetResponse, error) {
+	if req.GetEmail() == "" {
+		return nil, status.Error(codes.InvalidArgument, "email is required")
+	}
+
+	if err := s.authService.RequestPasswordReset(ctx, req.GetEmail()); err != nil {
+		return nil, status.Errorf(codes.Internal, "request password reset: %v", err)
+	}
+
+	return &pb.RequestPasswordResetResponse{}, nil
+}
+
+func (s *AuthServer) ResetPassword(ctx context.Context, req *pb.ResetPasswordRequest) (*pb.ResetPasswordResponse, error) {
+	if req.GetToken() == "" || req.GetNewPassword() == "" {
+		return nil, status.Error(codes.InvalidArgument, "token and new_password are required")
+	}
+
+	if err := s.authService.ResetPassword(ctx, req.GetToken(), req.GetNewPassword()); err != nil {
+		if st, ok := weakPasswordStatus("new_password", err); ok {
+			return nil, st
+		}
+		if errors.Is(err, service.ErrInvalidEmailToken) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		return nil, status.Errorf(codes.Internal, "reset password: %v", err)
+	}
+
+	return &pb.ResetPasswordResponse{}, nil
+}
+
+func (s *AuthServer) EnrollTotp(ctx context.Context, req *pb.EnrollTotpRequest) (*pb.EnrollTotpResponse, error) {
+	if req.GetAccessToken() == "" {
+		return nil, status.Error(codes.InvalidArgument, "access_token is required")
+	}
+
+	enrollment, err := s.authService.EnrollTOTP(ctx, req.GetAccessToken())
+	if err != nil {
+		return nil, twoFactorError("enroll totp", err)
+	}
+
+	return &pb.EnrollTotpResponse{
+		Secret:          enrollment.Secret,
+		ProvisioningUri: enrollment.ProvisioningURI,
+	}, nil
+}
+
+func (s *AuthServer) ConfirmTotp(ctx context.Context, req *pb.ConfirmTotpRequest) (*pb.ConfirmTotpResponse, error) {
+	if req.GetAccessToken() == "" {
+		return nil, status.Error(codes.InvalidArgument, "access_token is required")
+	}
+	if req.GetCode() == "" {
+		return nil, status.Error(codes.InvalidArgument, "code is required")
+	}
+
+	recoveryCodes, err := s.authService.ConfirmTOTP(ctx, req.GetAccessToken(), req.GetCode())
+	if err != nil {
+		return nil, twoFactorError("confirm totp", err)
+	}
+
+	return &pb.ConfirmTotpResponse{RecoveryCodes: recoveryCodes}, nil
+}
+
+func (s *AuthServer) DisableTotp(ctx context.Context, req *pb.DisableTotpRequest) (*pb.DisableTotpResponse, error) {
+	if req.GetAccessToken() == "" {
+		return nil, status.Error(codes.InvalidArgument, "access_token is required")
+	}
+	if req.GetPassword() == "" || req.GetCode() == "" {
+		return nil, status.Error(codes.InvalidArgument, "password and code are required")
+	}
+
+	if err := s.authService.DisableTOTP(ctx, req.GetAccessToken(), req.GetPassword(), req.GetCode()); err != nil {
+		return nil, twoFactorError("disable totp", err)
+	}
+
+	return &pb.DisableTotpResponse{}, nil
+}
+
+func (s *AuthServer) UnlockAccount(ctx context.Context, req *pb.UnlockAccountRequest) (*pb.UnlockAccountResponse, error) {
+	if req.GetAccessToken() == "" {
+		return nil, status.Error(codes.InvalidArgument, "access_token is required")
+	}
+	if req.GetEmail() == "" {
+		return nil, status.Error(codes.InvalidArgument, "email is required")
+	}
+
+	unlocked, err := s.authService.UnlockAccount(ctx, req.GetAccessToken(), req.GetEmail(), req.GetIp())
+	if err != nil {
+		return nil, roleError("unlock account", err)
+	}
+
+	return &pb.UnlockAccountResponse{Unlocked: unlocked}, nil
+}
+
+func (s *AuthServer) ListIdentityProviders(ctx context.Context, req *pb.ListIdentityProvidersRequest) (*pb.ListIdentityProvidersResponse, error) {
+	return &pb.ListIdentityProvidersResponse{Providers: s.authService.IdentityProviders()}, nil
+}
+
+func (s *AuthServer) StartOidcLogin(ctx context.Context, req *pb.StartOidcLoginRequest) (*pb.StartOidcLoginResponse, error) {
+	if req.GetProvider() == "" {
+		return nil, status.Error(codes.InvalidArgument, "provider is required")
+	}
+
+	authorization, err := s.authService.StartOIDCLogin(ctx, req.GetProvider())
+	if err != nil {
+		return nil, oidcError("start oidc login", err)
+	}
+
+	return &pb.StartOidcLoginResponse{
+		AuthorizationUrl: authorization.URL,
+		State:            authorization.State,
+		ExpiresAt:        timestamppb.New(authorization.ExpiresAt),
+	}, nil
+}
+
+func (s *AuthServer) CompleteOidcLogin(ctx context.Context, req *pb.CompleteOidcLoginRequest) (*pb.SignInResponse, error) {
+	if req.GetProvider() == "" || req.GetState() == "" || req.GetCode() == "" {
+		return nil, status.Error(codes.InvalidArgument, "provider, state and code are required")
+	}
+
+	token, err := s.authService.CompleteOIDCLogin(ctx, req.GetProvider(), req.GetState(), req.GetCode())
+	if err != nil {
+		return nil, oidcError("complete oidc login", err)
+	}
+
+	return toSignInResponse(token), nil
+}
+
+func (s *AuthServer) SetUserRoles(ctx context.Context, req *pb.SetUserRolesRequest) (*pb.UserResponse, error) {
+	if req.GetAccessToken() == "" {
+		return nil, status.Error(codes.InvalidArgument, "access_token is required")
+	}
+	if req.GetUserId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "user_id is required")
+	}
+
+	user, err := s.authService.SetUserRoles(ctx, req.GetAccessToken(), req.GetUserId(), req.GetRoles())
+	if err != nil {
+		return nil, roleError("set user roles", err)
+	}
+
+	return &pb.UserResponse{User: toProtoUser(user)}, nil
+}
+
+func (s *AuthServer) LookupUser(ctx context.Context, req *pb.LookupUserRequest) (*pb.UserResponse, error) {
+	if req.GetAccessToken() == "" {
+		return nil, status.Error(codes.InvalidArgument, "access_token is required")
+	}
+	if req.GetEmail() == "" {
+		return nil, status.Error(codes.InvalidArgument, "email is required")
+	}
+
+	user, err := s.authService.LookupUser(ctx, req.GetAccessToken(), req.GetEmail())
+	if err != nil {
+		return nil, roleError("lookup user", err)
+	}
+
+	return &pb.UserResponse{User: toProtoUser(user)}, nil
+}
+
+// throttledStatus converts a LoginThrottledError to RESOURCE_EXHAUSTED with a
+// RetryInfo detail telling the client when to try again.
+func throttledStatus(err error) (error, bool) {
+	var throttled *service.LoginThrottledError
+	if !errors.As(err, &throttled) {
+		return nil, false
+	}
+	st := status.New(codes.ResourceExhausted, throttled.Error())
+	detailed, detailErr := st.WithDetails(&errdetails.RetryInfo{
+		RetryDelay: durationpb.New(throttled.RetryAfter.Round(time.Second)),
+	})
+	if detailErr != nil {
+		return st.Err(), true
+	}
+	return detailed.Err(), true
+}
+
+// weakPasswordStatus converts a PasswordPolicyError to INVALID_ARGUMENT with a
+// BadRequest detail listing every violation of the field.
+func weakPasswordStatus(field string, err error) (error, bool) {
+	var policyErr *service.PasswordPolicyError
+	if !errors.As(err, &policyErr) {
+		return nil, false
+	}
+	badRequest := &errdetails.BadRequest{}
+	for _, violation := range policyErr.Violations {
+		badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       field,
+			Description: violation.Description,
+			Reason:      violation.Reason,
+		})
+	}
+	st := status.New(codes.InvalidArgument, service.ErrWeakPassword.Error())
+	detailed, detailErr := st.WithDetails(badRequest)
+	if detailErr != nil {
+		return st.Err(), true
+	}
+	return detailed.Err(), true
+}
+
+// oidcError maps errors of the external sign-in RPCs.
+func oidcError(op string, err error) error {
+	switch {
+	case errors.Is(err, service.ErrUnknownIdentityProvider):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, service.ErrInvalidOIDCState):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, service.ErrExternalLoginFailed):
+		return status.Error(codes.Unauthenticated, service.ErrExternalLoginFailed.Error())
+	case errors.Is(err, service.ErrExternalEmailNotVerified):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, oidc.ErrProviderUnavailable):
+		return status.Errorf(codes.Unavailable, "%s: %v", op, err)
+	default:
+		return status.Errorf(codes.Internal, "%s: %v", op, err)
+	}
+}
+
+// roleError maps errors of the staff RPCs. Unlike in profileError, a missing
+// user is the subject of the request, not the caller.
+func roleError(op string, err error) error {
+	switch {
+	case isSessionError(err):
+		return status.Error(codes.Unauthenticated, service.ErrInvalidToken.Error())
+	case errors.Is(err, service.ErrAdminRequired), errors.Is(err, service.ErrStaffRequired):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, service.ErrInvalidRole):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, service.ErrOwnAdminRole):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case service.IsNotFound(err):
+		return status.Error(codes.NotFound, "user not found")
+	default:
+		return status.Errorf(codes.Internal, "%s: %v", op, err)
+	}
+}
+
+// twoFactorError maps errors of the two-factor management RPCs on top of
+// profileError.
+func twoFactorError(op string, err error) error {
+	switch {
+	case errors.Is(err, service.ErrInvalidTwoFactorCode):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, service.ErrTwoFactorEnabled),
+		errors.Is(err, service.ErrTwoFactorNotEnabled),
+		errors.Is(err, service.ErrTwoFactorNotEnrolled):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	default:
+		return profileError(op, err)
+	}
+}
+
+// profileError maps errors of the profile RPCs. A token whose user has been
+// deleted in the meantime is reported as unauthenticated as well.
+func profileError(op string, err error) error {
+	switch {
+	case isSessionError(err), service.IsNotFound(err):
+		return status.Error(codes.Unauthenticated, service.ErrInvalidToken.Error())
+	case errors.Is(err, service.ErrWrongPassword):
+		return status.Error(codes.PermissionDenied, err.Error())
+	default:
+		return status.Errorf(codes.Internal, "%s: %v", op, err)
+	}
+}
+
+func toProtoUser(user model.User) *pb.User {
+	return &pb.User{
+		Id:               user.ID,
+		Email:            user.Email,
+		Name:             user.Name,
+		CreatedAt:        timestamppb.New(user.CreatedAt),
+		UpdatedAt:        timestamppb.New(user.UpdatedAt),
+		EmailVerified:    user.EmailVerified(),
+		TwoFactorEnabled: user.TwoFactorEnabled(),
+		Roles:            user.Roles,
+	}
+}
+
+func toSignInResponse(token model.Token) *pb.SignInResponse {
+	if token.ChallengeToken != "" {
+		return &pb.SignInResponse{
+			TwoFactorRequired:  true,
+			ChallengeToken:     token.ChallengeToken,
+			ChallengeExpiresAt: timestamppb.New(token.ChallengeExpiresAt),
+		}
+	}
+	return &pb.SignInResponse{
+		AccessToken:      token.AccessToken,
+		ExpiresAt:        timestamppb.New(token.ExpiresAt),
+		RefreshToken:     token.RefreshToken,
+		RefreshExpiresAt: timestamppb.New(token.RefreshExpiresAt),
+	}
+}
+
+func isSessionError(err error) bool {
+	return errors.Is(err, service.ErrInvalidToken) ||
+		errors.Is(err, service.ErrInvalidRefreshToken) ||
+		errors.Is(err, service.ErrRefreshTokenReused)
+}
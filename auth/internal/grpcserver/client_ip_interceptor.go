@@ -0,0 +1,50 @@
+package grpcserver
+
+import (
+	"context"
+	"crypto/subtle"
+	"net"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+
+	"github.com/Deevins/final-task-course-2-go-lang/auth/internal/clientip"
+)
+
+// ClientIPUnaryInterceptor stores the client address in the request context.
+// The address passed by the gateway in metadata takes precedence over the
+// address of the gRPC peer, which is the gateway itself, but only on calls
+// carrying the service token: a client calling auth directly could otherwise
+// pick a fresh address for every sign-in attempt. An empty serviceToken
+// trusts no metadata.
+func ClientIPUnaryInterceptor(serviceToken string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		return handler(withClientIP(ctx, serviceToken), req)
+	}
+}
+
+func withClientIP(ctx context.Context, serviceToken string) context.Context {
+	if md, ok := metadata.FromIncomingContext(ctx); ok && hasServiceToken(md, serviceToken) {
+		if values := md.Get(clientip.MetadataKey); len(values) > 0 && values[0] != "" {
+			return clientip.WithIP(ctx, values[0])
+		}
+	}
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ctx
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return ctx
+	}
+	return clientip.WithIP(ctx, host)
+}
+
+func hasServiceToken(md metadata.MD, serviceToken string) bool {
+	if serviceToken == "" {
+		return false
+	}
+	values := md.Get(clientip.ServiceTokenMetadataKey)
+	return len(values) == 1 && subtle.ConstantTimeCompare([]byte(values[0]), []byte(serviceToken)) == 1
+}
@@ -0,0 +1,27 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/Deevins/final-task-course-2-go-lang/auth/internal/signing"
+)
+
+// jwksMaxAge lets verifiers cache the key set; they refetch on an unknown kid.
+const jwksMaxAge = "public, max-age=300"
+
+type JWKSHandler struct {
+	keyring *signing.Keyring
+}
+
+func NewJWKSHandler(keyring *signing.Keyring) *JWKSHandler {
+	return &JWKSHandler{keyring: keyring}
+}
+
+func (h *JWKSHandler) RegisterRoutes(router *gin.RouterGroup) {
+	router.GET("/jwks.json", func(c *gin.Context) {
+		c.Header("Cache-Control", jwksMaxAge)
+		c.JSON(http.StatusOK, h.keyring.JWKS())
+	})
+}
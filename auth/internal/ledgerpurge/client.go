@@ -0,0 +1,31 @@
+package ledgerpurge
+
+import (
+	"context"
+
+	"google.golang.org/grpc/metadata"
+
+	"github.com/Deevins/final-task-course-2-go-lang/auth/internal/clientip"
+	ledgerv1 "github.com/Deevins/final-task-course-2-go-lang/auth/internal/pb/ledger/v1"
+)
+
+// GRPCLedger calls the PurgeAccount RPC of the ledger service. The ledger
+// accepts purges only from internal services, which authenticate with the
+// shared service token.
+type GRPCLedger struct {
+	client       ledgerv1.LedgerServiceClient
+	serviceToken string
+}
+
+func NewGRPCLedger(client ledgerv1.LedgerServiceClient, serviceToken string) *GRPCLedger {
+	return &GRPCLedger{client: client, serviceToken: serviceToken}
+}
+
+func (l *GRPCLedger) PurgeAccount(ctx context.Context, accountID string) (int64, error) {
+	ctx = metadata.AppendToOutgoingContext(ctx, clientip.ServiceTokenMetadataKey, l.serviceToken)
+	resp, err := l.client.PurgeAccount(ctx, &ledgerv1.PurgeAccountRequest{AccountId: accountID})
+	if err != nil {
+		return 0, err
+	}
+	return resp.GetPurged(), nil
+}
@@ -0,0 +1,87 @@
+// Package ledgerpurge forwards account deletions to the ledger service so that
+// the ledger data of deleted users is removed as well.
+package ledgerpurge
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/Deevins/final-task-course-2-go-lang/auth/internal/model"
+)
+
+const defaultBatchSize = 50
+
+// Store keeps the purge requests recorded when users are deleted.
+type Store interface {
+	ListPendingAccountPurges(ctx context.Context, limit int) ([]model.AccountPurge, error)
+	CompleteAccountPurge(ctx context.Context, userID string) error
+	FailAccountPurge(ctx context.Context, userID, reason string) error
+}
+
+// Ledger removes all ledger data of an account. Purging an account without
+// data must succeed so that requests can be retried safely.
+type Ledger interface {
+	PurgeAccount(ctx context.Context, accountID string) (int64, error)
+}
+
+// Worker periodically delivers pending purge requests to the ledger. A request
+// is removed only after the ledger confirmed the purge, so deletions are never
+// lost when the ledger is unavailable.
+type Worker struct {
+	store     Store
+	ledger    Ledger
+	interval  time.Duration
+	batchSize int
+}
+
+func NewWorker(store Store, ledger Ledger, interval time.Duration) *Worker {
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+	return &Worker{store: store, ledger: ledger, interval: interval, batchSize: defaultBatchSize}
+}
+
+func (w *Worker) Run(ctx context.Context) error {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		processed, err := w.ProcessPending(ctx)
+		if err != nil {
+			log.Printf("account purge: %v", err)
+		} else if processed > 0 {
+			log.Printf("account purge: purged ledger data of %d accounts", processed)
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// ProcessPending sends one batch of pending requests to the ledger and returns
+// the number of completed purges. Failed requests are kept for the next run.
+func (w *Worker) ProcessPending(ctx context.Context) (int, error) {
+	purges, err := w.store.ListPendingAccountPurges(ctx, w.batchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	completed := 0
+	for _, purge := range purges {
+		if _, err := w.ledger.PurgeAccount(ctx, purge.UserID); err != nil {
+			log.Printf("account purge: user %s attempt %d: %v", purge.UserID, purge.Attempts+1, err)
+			if err := w.store.FailAccountPurge(ctx, purge.UserID, err.Error()); err != nil {
+				return completed, err
+			}
+			continue
+		}
+		if err := w.store.CompleteAccountPurge(ctx, purge.UserID); err != nil {
+			return completed, err
+		}
+		completed++
+	}
+
+	return completed, nil
+}
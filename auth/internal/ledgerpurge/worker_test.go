@@ -0,0 +1,89 @@
+package ledgerpurge
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Deevins/final-task-course-2-go-lang/auth/internal/model"
+)
+
+type memoryStore struct {
+	pending  []model.AccountPurge
+	failures map[string]string
+}
+
+func (s *memoryStore) ListPendingAccountPurges(ctx context.Context, limit int) ([]model.AccountPurge, error) {
+	items := append([]model.AccountPurge(nil), s.pending...)
+	if len(items) > limit {
+		items = items[:limit]
+	}
+	return items, nil
+}
+
+func (s *memoryStore) CompleteAccountPurge(ctx context.Context, userID string) error {
+	for i, purge := range s.pending {
+		if purge.UserID == userID {
+			s.pending = append(s.pending[:i], s.pending[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+func (s *memoryStore) FailAccountPurge(ctx context.Context, userID, reason string) error {
+	for i := range s.pending {
+		if s.pending[i].UserID == userID {
+			s.pending[i].Attempts++
+			s.pending[i].LastError = reason
+		}
+	}
+	s.failures[userID] = reason
+	return nil
+}
+
+type stubLedger struct {
+	failing map[string]bool
+	purged  []string
+}
+
+func (l *stubLedger) PurgeAccount(ctx context.Context, accountID string) (int64, error) {
+	if l.failing[accountID] {
+		return 0, errors.New("ledger unavailable")
+	}
+	l.purged = append(l.purged, accountID)
+	return 3, nil
+}
+
+func TestWorkerKeepsFailedPurgesForRetry(t *testing.T) {
+	ctx := context.Background()
+	store := &memoryStore{
+		pending:  []model.AccountPurge{{UserID: "user-1"}, {UserID: "user-2"}},
+		failures: make(map[string]string),
+	}
+	ledger := &stubLedger{failing: map[string]bool{"user-2": true}}
+	worker := NewWorker(store, ledger, 0)
+
+	completed, err := worker.ProcessPending(ctx)
+	if err != nil {
+		t.Fatalf("process pending: %v", err)
+	}
+	if completed != 1 || len(ledger.purged) != 1 || ledger.purged[0] != "user-1" {
+		t.Fatalf("expected user-1 to be purged, completed=%d purged=%v", completed, ledger.purged)
+	}
+	if len(store.pending) != 1 || store.pending[0].UserID != "user-2" || store.pending[0].Attempts != 1 {
+		t.Fatalf("expected failed purge to stay pending, got %+v", store.pending)
+	}
+	if store.failures["user-2"] == "" {
+		t.Fatal("expected failure reason to be recorded")
+	}
+
+	ledger.failing = nil
+	completed, err = worker.ProcessPending(ctx)
+	if err != nil {
+		t.Fatalf("process pending: %v", err)
+	}
+	if completed != 1 || len(store.pending) != 0 {
+		t.Fatalf("expected retry to complete, completed=%d pending=%+v", completed, store.pending)
+	}
+}
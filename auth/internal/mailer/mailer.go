@@ -0,0 +1,34 @@
+// Package mailer delivers account emails such as address verification and
+// password reset links.
+package mailer
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Deevins/final-task-course-2-go-lang/auth/internal/config"
+)
+
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// New returns the mailer selected by cfg.Mailer.
+func New(cfg config.EmailConfig) (Mailer, error) {
+	switch cfg.Mailer {
+	case config.MailerSMTP:
+		return NewSMTPMailer(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.From), nil
+	case config.MailerFile:
+		return NewFileMailer(cfg.FileDir, cfg.From), nil
+	case config.MailerLog:
+		return NewLogMailer(), nil
+	default:
+		return nil, fmt.Errorf("unknown mailer %q", cfg.Mailer)
+	}
+}
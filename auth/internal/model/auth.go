@@ -1,6 +1,17 @@
 package model
 
-import "time"
+import (
+	"slices"
+	"time"
+)
+
+// Roles of a user. Every user has RoleUser; RoleSupport and RoleAdmin grant
+// read access to the ledgers of other users, and only admins manage roles.
+const (
+	RoleUser    = "user"
+	RoleSupport = "support"
+	RoleAdmin   = "admin"
+)
 
 type User struct {
 	ID           string
@@ -9,10 +20,163 @@ type User struct {
 	PasswordHash string
 	CreatedAt    time.Time
 	UpdatedAt    time.Time
+	// EmailVerifiedAt is nil until the user confirms the email address.
+	EmailVerifiedAt *time.Time
+	// TOTPSecret is set on enrollment; two-factor sign-in is required only
+	// once the enrollment is confirmed and TOTPEnabledAt is set.
+	TOTPSecret    string
+	TOTPEnabledAt *time.Time
+	// TOTPLastStep is the time step of the last accepted code.
+	TOTPLastStep int64
+	// Roles always contains RoleUser.
+	Roles []string
+}
+
+func (u User) HasRole(role string) bool {
+	return slices.Contains(u.Roles, role)
+}
+
+func (u User) EmailVerified() bool {
+	return u.EmailVerifiedAt != nil
+}
+
+func (u User) TwoFactorEnabled() bool {
+	return u.TOTPEnabledAt != nil
 }
 
 type Token struct {
-	AccessToken string
+	AccessToken      string
+	UserID           string
+	ExpiresAt        time.Time
+	RefreshToken     string
+	RefreshExpiresAt time.Time
+	EmailVerified    bool
+	Roles            []string
+	// ChallengeToken is set instead of the tokens above when the user has
+	// two-factor authentication enabled. It is exchanged for the tokens
+	// together with a one-time code.
+	ChallengeToken     string
+	ChallengeExpiresAt time.Time
+}
+
+// TOTPEnrollment is a pending authenticator app enrollment.
+type TOTPEnrollment struct {
+	Secret          string
+	ProvisioningURI string
+}
+
+const (
+	TokenPurposeEmailVerification = "email_verification"
+	TokenPurposePasswordReset     = "password_reset"
+	TokenPurposeSignInChallenge   = "sign_in_challenge"
+)
+
+// UserToken is a single-use token handed to the user, e.g. by email to verify
+// the address or to reset the password, or as the challenge of a two-factor
+// sign-in. Only the SHA-256 hash is stored.
+type UserToken struct {
+	ID        string
+	UserID    string
+	Purpose   string
+	TokenHash string
+	ExpiresAt time.Time
+	CreatedAt time.Time
+	UsedAt    *time.Time
+}
+
+// RefreshToken is a stored refresh token. Only the SHA-256 hash of the token
+// value is kept. Tokens obtained by rotating each other share a FamilyID, which
+// identifies one login session. AccessTokenID and AccessExpiresAt describe the
+// access token issued together with this refresh token so it can be denylisted
+// when the session is revoked.
+type RefreshToken struct {
+	ID              string
+	UserID          string
+	FamilyID        string
+	TokenHash       string
+	AccessTokenID   string
+	AccessExpiresAt time.Time
+	ExpiresAt       time.Time
+	CreatedAt       time.Time
+	RevokedAt       *time.Time
+	ReplacedBy      string
+}
+
+// RevokedToken is a denylisted access token, kept until the token expires.
+type RevokedToken struct {
+	ID        string
+	UserID    string
+	ExpiresAt time.Time
+	RevokedAt time.Time
+}
+
+// RevokedUser rejects every access token of a user issued before
+// RevokedBefore. It is kept until ExpiresAt, when those tokens have expired.
+type RevokedUser struct {
+	UserID        string
+	RevokedBefore time.Time
+	ExpiresAt     time.Time
+	RevokedAt     time.Time
+}
+
+// SigningKey is a private key used to sign access tokens. A key signs new
+// tokens until RetiresAt and is still accepted and published until ExpiresAt.
+type SigningKey struct {
+	ID         string
+	Algorithm  string
+	PrivateKey string
+	CreatedAt  time.Time
+	RetiresAt  time.Time
+	ExpiresAt  time.Time
+}
+
+// AccountPurge is a pending request to remove the ledger data of a deleted
+// user. It is stored together with the deletion and removed once the ledger
+// service confirms the purge.
+type AccountPurge struct {
+	UserID        string
+	RequestedAt   time.Time
+	Attempts      int
+	LastError     string
+	LastAttemptAt *time.Time
+}
+
+// LoginFailure counts recent failed sign-in attempts for a key, which names
+// either an account or a client address.
+type LoginFailure struct {
+	Key           string
+	Failures      int
+	LastFailureAt time.Time
+}
+
+// Identity links a user to an account at an external identity provider,
+// identified by the provider name and the subject of its ID tokens.
+type Identity struct {
+	ID          string
 	UserID      string
-	ExpiresAt   time.Time
+	Provider    string
+	Subject     string
+	Email       string
+	CreatedAt   time.Time
+	LastLoginAt time.Time
+}
+
+// OIDCLogin is a started sign-in at an external identity provider. Only the
+// SHA-256 hash of the state is stored; the nonce and the PKCE code verifier
+// are needed to redeem the authorization code.
+type OIDCLogin struct {
+	StateHash    string
+	Provider     string
+	Nonce        string
+	CodeVerifier string
+	CreatedAt    time.Time
+	ExpiresAt    time.Time
+}
+
+// OIDCAuthorization is a started sign-in at an external identity provider.
+// The user is sent to URL; State comes back with the callback.
+type OIDCAuthorization struct {
+	URL       string
+	State     string
+	ExpiresAt time.Time
 }
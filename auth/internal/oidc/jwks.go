@@ -0,0 +1,87 @@
+package oidc
+
+import (
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// signingMethods are the ID token algorithms accepted from providers.
+var signingMethods = []string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodES256.Alg()}
+
+type jwk struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	N         string `json:"n"`
+	E         string `json:"e"`
+	Curve     string `json:"crv"`
+	X         string `json:"x"`
+	Y         string `json:"y"`
+}
+
+type publicKey struct {
+	algorithm string
+	key       interface{}
+}
+
+// parseJWK converts a provider signing key. Providers often omit alg, so it
+// is derived from the key type.
+func parseJWK(item jwk) (publicKey, error) {
+	if item.Use != "" && item.Use != "sig" {
+		return publicKey{}, fmt.Errorf("key use %q is not sig", item.Use)
+	}
+	switch item.KeyType {
+	case "RSA":
+		if item.Algorithm != "" && item.Algorithm != jwt.SigningMethodRS256.Alg() {
+			return publicKey{}, fmt.Errorf("unsupported RSA algorithm %q", item.Algorithm)
+		}
+		n, err := base64.RawURLEncoding.DecodeString(item.N)
+		if err != nil {
+			return publicKey{}, fmt.Errorf("decode n: %w", err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(item.E)
+		if err != nil {
+			return publicKey{}, fmt.Errorf("decode e: %w", err)
+		}
+		exponent := new(big.Int).SetBytes(e)
+		if !exponent.IsInt64() || exponent.Int64() > 1<<31-1 {
+			return publicKey{}, errors.New("invalid exponent")
+		}
+		return publicKey{
+			algorithm: jwt.SigningMethodRS256.Alg(),
+			key:       &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exponent.Int64())},
+		}, nil
+	case "EC":
+		if item.Curve != "P-256" || (item.Algorithm != "" && item.Algorithm != jwt.SigningMethodES256.Alg()) {
+			return publicKey{}, fmt.Errorf("unsupported EC key %q/%q", item.Curve, item.Algorithm)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(item.X)
+		if err != nil {
+			return publicKey{}, fmt.Errorf("decode x: %w", err)
+		}
+		y, err := base64.RawURLEncoding.DecodeString(item.Y)
+		if err != nil {
+			return publicKey{}, fmt.Errorf("decode y: %w", err)
+		}
+		if len(x) != 32 || len(y) != 32 {
+			return publicKey{}, errors.New("invalid P-256 coordinate size")
+		}
+		// ecdh rejects points that are not on the curve.
+		if _, err := ecdh.P256().NewPublicKey(append(append([]byte{4}, x...), y...)); err != nil {
+			return publicKey{}, fmt.Errorf("invalid EC point: %w", err)
+		}
+		key := &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		return publicKey{algorithm: jwt.SigningMethodES256.Alg(), key: key}, nil
+	default:
+		return publicKey{}, fmt.Errorf("unsupported key type %q", item.KeyType)
+	}
+}
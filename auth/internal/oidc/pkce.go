@@ -0,0 +1,28 @@
+package oidc
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+)
+
+// randomBytes is the entropy of states, nonces and code verifiers. Encoded
+// they are 43 characters long, the minimum length of a PKCE verifier.
+const randomBytes = 32
+
+// RandomString returns a URL-safe random value for a state, nonce or PKCE
+// code verifier.
+func RandomString() (string, error) {
+	value := make([]byte, randomBytes)
+	if _, err := rand.Read(value); err != nil {
+		return "", fmt.Errorf("generate random value: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(value), nil
+}
+
+// CodeChallenge returns the S256 PKCE challenge of a code verifier (RFC 7636).
+func CodeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
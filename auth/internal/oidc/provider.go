@@ -0,0 +1,317 @@
+// Package oidc implements the relying party side of the OpenID Connect
+// authorization code flow with PKCE.
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/Deevins/final-task-course-2-go-lang/auth/internal/config"
+)
+
+const (
+	// keysRefreshCooldown limits how often an unknown kid can trigger a JWKS fetch.
+	keysRefreshCooldown = 10 * time.Second
+	// clockLeeway tolerates clock drift between us and the provider.
+	clockLeeway = time.Minute
+	// maxResponseBytes caps the provider responses that are read.
+	maxResponseBytes = 1 << 20
+)
+
+var (
+	// ErrProviderUnavailable means the provider metadata, keys or token
+	// endpoint could not be reached.
+	ErrProviderUnavailable = errors.New("identity provider is unavailable")
+	// ErrCodeRejected means the provider did not accept the authorization code.
+	ErrCodeRejected = errors.New("authorization code was rejected")
+	// ErrInvalidIDToken means the ID token failed signature or claim checks.
+	ErrInvalidIDToken = errors.New("invalid ID token")
+)
+
+// Claims are the verified claims of an ID token.
+type Claims struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+type metadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+type idTokenClaims struct {
+	jwt.RegisteredClaims
+	Nonce         string       `json:"nonce"`
+	Email         string       `json:"email"`
+	EmailVerified flexibleBool `json:"email_verified"`
+	Name          string       `json:"name"`
+}
+
+// flexibleBool accepts both true and "true"; some providers send strings.
+type flexibleBool bool
+
+func (b *flexibleBool) UnmarshalJSON(data []byte) error {
+	switch string(data) {
+	case `true`, `"true"`:
+		*b = true
+	case `false`, `"false"`, `null`:
+		*b = false
+	default:
+		return fmt.Errorf("invalid boolean %s", data)
+	}
+	return nil
+}
+
+// Provider is a registered OpenID Connect provider. Its metadata and keys are
+// discovered from the issuer on first use and cached.
+type Provider struct {
+	config config.OIDCProviderConfig
+	client *http.Client
+
+	mu              sync.Mutex
+	metadata        *metadata
+	keys            map[string]publicKey
+	keysAttemptedAt time.Time
+}
+
+func NewProvider(cfg config.OIDCProviderConfig, client *http.Client) *Provider {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	if !slices.Contains(cfg.Scopes, "openid") {
+		cfg.Scopes = append([]string{"openid"}, cfg.Scopes...)
+	}
+	return &Provider{config: cfg, client: client, keys: make(map[string]publicKey)}
+}
+
+func (p *Provider) Name() string {
+	return p.config.Name
+}
+
+// AuthCodeURL returns the authorization endpoint URL the user is redirected to.
+func (p *Provider) AuthCodeURL(ctx context.Context, state, nonce, codeVerifier string) (string, error) {
+	meta, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+	endpoint, err := url.Parse(meta.AuthorizationEndpoint)
+	if err != nil {
+		return "", fmt.Errorf("%w: invalid authorization endpoint: %v", ErrProviderUnavailable, err)
+	}
+	query := endpoint.Query()
+	query.Set("response_type", "code")
+	query.Set("client_id", p.config.ClientID)
+	query.Set("redirect_uri", p.config.RedirectURL)
+	query.Set("scope", strings.Join(p.config.Scopes, " "))
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	query.Set("code_challenge", CodeChallenge(codeVerifier))
+	query.Set("code_challenge_method", "S256")
+	endpoint.RawQuery = query.Encode()
+	return endpoint.String(), nil
+}
+
+// Exchange redeems an authorization code and returns the verified claims of
+// the ID token, which has to carry the nonce of the sign-in.
+func (p *Provider) Exchange(ctx context.Context, code, codeVerifier, nonce string) (Claims, error) {
+	meta, err := p.discover(ctx)
+	if err != nil {
+		return Claims{}, err
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.config.RedirectURL},
+		"client_id":     {p.config.ClientID},
+		"code_verifier": {codeVerifier},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, meta.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return Claims{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if p.config.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(p.config.ClientID), url.QueryEscape(p.config.ClientSecret))
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return Claims{}, fmt.Errorf("%w: token request: %v", ErrProviderUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxResponseBytes)).Decode(&body); err != nil {
+		return Claims{}, fmt.Errorf("%w: decode token response (%s): %v", ErrProviderUnavailable, resp.Status, err)
+	}
+	if resp.StatusCode == http.StatusBadRequest || resp.StatusCode == http.StatusUnauthorized {
+		return Claims{}, fmt.Errorf("%w: %s %s", ErrCodeRejected, body.Error, body.ErrorDescription)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return Claims{}, fmt.Errorf("%w: token request: unexpected status %s", ErrProviderUnavailable, resp.Status)
+	}
+	if body.IDToken == "" {
+		return Claims{}, fmt.Errorf("%w: token response has no id_token", ErrInvalidIDToken)
+	}
+
+	return p.verifyIDToken(ctx, meta, body.IDToken, nonce)
+}
+
+func (p *Provider) verifyIDToken(ctx context.Context, meta *metadata, rawIDToken, nonce string) (Claims, error) {
+	claims := &idTokenClaims{}
+	_, err := jwt.ParseWithClaims(
+		rawIDToken,
+		claims,
+		p.keyfunc(ctx, meta),
+		jwt.WithValidMethods(signingMethods),
+		jwt.WithIssuer(meta.Issuer),
+		jwt.WithAudience(p.config.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(clockLeeway),
+	)
+	if err != nil {
+		if errors.Is(err, ErrProviderUnavailable) {
+			return Claims{}, err
+		}
+		return Claims{}, fmt.Errorf("%w: %v", ErrInvalidIDToken, err)
+	}
+	if claims.Subject == "" {
+		return Claims{}, fmt.Errorf("%w: no subject", ErrInvalidIDToken)
+	}
+	if claims.Nonce != nonce {
+		return Claims{}, fmt.Errorf("%w: nonce mismatch", ErrInvalidIDToken)
+	}
+
+	return Claims{
+		Subject:       claims.Subject,
+		Email:         claims.Email,
+		EmailVerified: bool(claims.EmailVerified),
+		Name:          claims.Name,
+	}, nil
+}
+
+// discover fetches the provider metadata once. A failed attempt is retried
+// on the next call.
+func (p *Provider) discover(ctx context.Context) (*metadata, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.metadata != nil {
+		return p.metadata, nil
+	}
+
+	var meta metadata
+	if err := p.getJSON(ctx, p.config.Issuer+"/.well-known/openid-configuration", &meta); err != nil {
+		return nil, fmt.Errorf("%w: discovery: %v", ErrProviderUnavailable, err)
+	}
+	if meta.Issuer != p.config.Issuer {
+		return nil, fmt.Errorf("%w: discovery returned issuer %q, expected %q", ErrProviderUnavailable, meta.Issuer, p.config.Issuer)
+	}
+	if meta.AuthorizationEndpoint == "" || meta.TokenEndpoint == "" || meta.JWKSURI == "" {
+		return nil, fmt.Errorf("%w: discovery document is incomplete", ErrProviderUnavailable)
+	}
+	p.metadata = &meta
+	return p.metadata, nil
+}
+
+// keyfunc resolves the ID token key by kid. An unknown kid triggers a
+// refresh, at most once per keysRefreshCooldown, since providers rotate keys.
+func (p *Provider) keyfunc(ctx context.Context, meta *metadata) jwt.Keyfunc {
+	return func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		found, ok := p.findKey(kid, token.Method.Alg())
+		if !ok && time.Since(p.keysAttemptedAt) >= keysRefreshCooldown {
+			if err := p.refreshKeys(ctx, meta); err != nil {
+				return nil, err
+			}
+			found, ok = p.findKey(kid, token.Method.Alg())
+		}
+		if !ok {
+			return nil, fmt.Errorf("unknown signing key %q", kid)
+		}
+		return found.key, nil
+	}
+}
+
+// findKey looks the key up by kid. Tokens without a kid are accepted only if
+// a single key of the algorithm is published.
+func (p *Provider) findKey(kid, algorithm string) (publicKey, bool) {
+	if kid != "" {
+		key, ok := p.keys[kid]
+		return key, ok && key.algorithm == algorithm
+	}
+	var candidates []publicKey
+	for _, key := range p.keys {
+		if key.algorithm == algorithm {
+			candidates = append(candidates, key)
+		}
+	}
+	if len(candidates) != 1 {
+		return publicKey{}, false
+	}
+	return candidates[0], true
+}
+
+func (p *Provider) refreshKeys(ctx context.Context, meta *metadata) error {
+	p.keysAttemptedAt = time.Now()
+
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := p.getJSON(ctx, meta.JWKSURI, &set); err != nil {
+		return fmt.Errorf("%w: fetch jwks: %v", ErrProviderUnavailable, err)
+	}
+	keys := make(map[string]publicKey, len(set.Keys))
+	for _, item := range set.Keys {
+		parsed, err := parseJWK(item)
+		if err != nil {
+			log.Printf("oidc provider %s: skip jwk %q: %v", p.config.Name, item.KeyID, err)
+			continue
+		}
+		keys[item.KeyID] = parsed
+	}
+	p.keys = keys
+	return nil
+}
+
+func (p *Provider) getJSON(ctx context.Context, target string, dst any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, maxResponseBytes)).Decode(dst)
+}
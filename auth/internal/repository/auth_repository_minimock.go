@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/Deevins/final-task-course-2-go-lang/auth/internal/model"
 	"github.com/gojuno/minimock/v3"
@@ -15,6 +16,53 @@ type AuthRepositoryMock struct {
 	CreateUserFunc   func(ctx context.Context, user model.User) (model.User, error)
 	GetUserByEmailFn func(ctx context.Context, email string) (model.User, error)
 	GetUserByIDFn    func(ctx context.Context, id string) (model.User, error)
+	UpdateUserFn     func(ctx context.Context, user model.User) (model.User, error)
+	DeleteUserFn     func(ctx context.Context, id string, requestedAt time.Time) error
+
+	SetUserRolesFn      func(ctx context.Context, userID string, roles []string, updatedAt time.Time) (model.User, error)
+	MarkEmailVerifiedFn func(ctx context.Context, userID string, verifiedAt time.Time) error
+
+	SetTOTPSecretFn   func(ctx context.Context, userID, secret string, updatedAt time.Time) error
+	EnableTOTPFn      func(ctx context.Context, userID string, step int64, enabledAt time.Time, recoveryCodeHashes []string) error
+	DisableTOTPFn     func(ctx context.Context, userID string, updatedAt time.Time) error
+	UseTOTPStepFn     func(ctx context.Context, userID string, step int64) error
+	UseRecoveryCodeFn func(ctx context.Context, userID, codeHash string, usedAt time.Time) error
+
+	ListLoginFailuresFn        func(ctx context.Context, keys []string, since time.Time) ([]model.LoginFailure, error)
+	RecordLoginFailureFn       func(ctx context.Context, key string, at, windowStart time.Time) (model.LoginFailure, error)
+	ReleaseLoginFailureFn      func(ctx context.Context, key string) error
+	ClearLoginFailuresFn       func(ctx context.Context, keys []string) (int64, error)
+	DeleteStaleLoginFailuresFn func(ctx context.Context, before time.Time) (int64, error)
+
+	CreateUserTokenFn  func(ctx context.Context, token model.UserToken) error
+	GetUserTokenFn     func(ctx context.Context, purpose, tokenHash string, now time.Time) (model.UserToken, error)
+	ConsumeUserTokenFn func(ctx context.Context, purpose, tokenHash string, now time.Time) (model.UserToken, error)
+
+	GetIdentityFn            func(ctx context.Context, provider, subject string) (model.Identity, error)
+	CreateIdentityFn         func(ctx context.Context, identity model.Identity) error
+	CreateUserWithIdentityFn func(ctx context.Context, user model.User, identity model.Identity) (model.User, error)
+	UpdateIdentityLoginFn    func(ctx context.Context, id, email string, at time.Time) error
+	CreateOIDCLoginFn        func(ctx context.Context, login model.OIDCLogin) error
+	ConsumeOIDCLoginFn       func(ctx context.Context, provider, stateHash string, now time.Time) (model.OIDCLogin, error)
+
+	CreateRefreshTokenFn       func(ctx context.Context, token model.RefreshToken) error
+	GetRefreshTokenByHashFn    func(ctx context.Context, tokenHash string) (model.RefreshToken, error)
+	RotateRefreshTokenFn       func(ctx context.Context, oldID string, next model.RefreshToken) error
+	RevokeRefreshTokenFamilyFn func(ctx context.Context, familyID string) error
+	RevokeUserRefreshTokensFn  func(ctx context.Context, userID string) (int64, error)
+	RevokeAccessTokenFn        func(ctx context.Context, token model.RevokedToken) error
+	RevokeUserAccessTokensFn   func(ctx context.Context, user model.RevokedUser) error
+	IsAccessTokenRevokedFn     func(ctx context.Context, tokenID, userID string, issuedAt time.Time) (bool, error)
+	ListRevokedTokensFn        func(ctx context.Context, since time.Time) ([]model.RevokedToken, error)
+	ListRevokedUsersFn         func(ctx context.Context, since time.Time) ([]model.RevokedUser, error)
+	DeleteExpiredTokensFn      func(ctx context.Context, before time.Time) (int64, error)
+	ListSigningKeysFn          func(ctx context.Context) ([]model.SigningKey, error)
+	CreateSigningKeyFn         func(ctx context.Context, key model.SigningKey) error
+	DeleteExpiredSigningKeysFn func(ctx context.Context, before time.Time) (int64, error)
+
+	ListPendingAccountPurgesFn func(ctx context.Context, limit int) ([]model.AccountPurge, error)
+	CompleteAccountPurgeFn     func(ctx context.Context, userID string) error
+	FailAccountPurgeFn         func(ctx context.Context, userID, reason string) error
 }
 
 // NewAuthRepositoryMock returns a new AuthRepositoryMock.
@@ -45,3 +93,323 @@ func (m *AuthRepositoryMock) GetUserByID(ctx context.Context, id string) (model.
 	}
 	return m.GetUserByIDFn(ctx, id)
 }
+
+func (m *AuthRepositoryMock) UpdateUser(ctx context.Context, user model.User) (model.User, error) {
+	if m.UpdateUserFn == nil {
+		m.ctrl.Fatalf("UpdateUser mock is not set")
+		return model.User{}, nil
+	}
+	return m.UpdateUserFn(ctx, user)
+}
+
+func (m *AuthRepositoryMock) SetUserRoles(ctx context.Context, userID string, roles []string, updatedAt time.Time) (model.User, error) {
+	if m.SetUserRolesFn == nil {
+		m.ctrl.Fatalf("SetUserRoles mock is not set")
+		return model.User{}, nil
+	}
+	return m.SetUserRolesFn(ctx, userID, roles, updatedAt)
+}
+
+func (m *AuthRepositoryMock) DeleteUser(ctx context.Context, id string, requestedAt time.Time) error {
+	if m.DeleteUserFn == nil {
+		m.ctrl.Fatalf("DeleteUser mock is not set")
+		return nil
+	}
+	return m.DeleteUserFn(ctx, id, requestedAt)
+}
+
+func (m *AuthRepositoryMock) MarkEmailVerified(ctx context.Context, userID string, verifiedAt time.Time) error {
+	if m.MarkEmailVerifiedFn == nil {
+		m.ctrl.Fatalf("MarkEmailVerified mock is not set")
+		return nil
+	}
+	return m.MarkEmailVerifiedFn(ctx, userID, verifiedAt)
+}
+
+func (m *AuthRepositoryMock) SetTOTPSecret(ctx context.Context, userID, secret string, updatedAt time.Time) error {
+	if m.SetTOTPSecretFn == nil {
+		m.ctrl.Fatalf("SetTOTPSecret mock is not set")
+		return nil
+	}
+	return m.SetTOTPSecretFn(ctx, userID, secret, updatedAt)
+}
+
+func (m *AuthRepositoryMock) EnableTOTP(ctx context.Context, userID string, step int64, enabledAt time.Time, recoveryCodeHashes []string) error {
+	if m.EnableTOTPFn == nil {
+		m.ctrl.Fatalf("EnableTOTP mock is not set")
+		return nil
+	}
+	return m.EnableTOTPFn(ctx, userID, step, enabledAt, recoveryCodeHashes)
+}
+
+func (m *AuthRepositoryMock) DisableTOTP(ctx context.Context, userID string, updatedAt time.Time) error {
+	if m.DisableTOTPFn == nil {
+		m.ctrl.Fatalf("DisableTOTP mock is not set")
+		return nil
+	}
+	return m.DisableTOTPFn(ctx, userID, updatedAt)
+}
+
+func (m *AuthRepositoryMock) UseTOTPStep(ctx context.Context, userID string, step int64) error {
+	if m.UseTOTPStepFn == nil {
+		m.ctrl.Fatalf("UseTOTPStep mock is not set")
+		return nil
+	}
+	return m.UseTOTPStepFn(ctx, userID, step)
+}
+
+func (m *AuthRepositoryMock) UseRecoveryCode(ctx context.Context, userID, codeHash string, usedAt time.Time) error {
+	if m.UseRecoveryCodeFn == nil {
+		m.ctrl.Fatalf("UseRecoveryCode mock is not set")
+		return nil
+	}
+	return m.UseRecoveryCodeFn(ctx, userID, codeHash, usedAt)
+}
+
+func (m *AuthRepositoryMock) ListLoginFailures(ctx context.Context, keys []string, since time.Time) ([]model.LoginFailure, error) {
+	if m.ListLoginFailuresFn == nil {
+		m.ctrl.Fatalf("ListLoginFailures mock is not set")
+		return nil, nil
+	}
+	return m.ListLoginFailuresFn(ctx, keys, since)
+}
+
+func (m *AuthRepositoryMock) RecordLoginFailure(ctx context.Context, key string, at, windowStart time.Time) (model.LoginFailure, error) {
+	if m.RecordLoginFailureFn == nil {
+		m.ctrl.Fatalf("RecordLoginFailure mock is not set")
+		return model.LoginFailure{}, nil
+	}
+	return m.RecordLoginFailureFn(ctx, key, at, windowStart)
+}
+
+func (m *AuthRepositoryMock) ReleaseLoginFailure(ctx context.Context, key string) error {
+	if m.ReleaseLoginFailureFn == nil {
+		m.ctrl.Fatalf("ReleaseLoginFailure mock is not set")
+		return nil
+	}
+	return m.ReleaseLoginFailureFn(ctx, key)
+}
+
+func (m *AuthRepositoryMock) ClearLoginFailures(ctx context.Context, keys []string) (int64, error) {
+	if m.ClearLoginFailuresFn == nil {
+		m.ctrl.Fatalf("ClearLoginFailures mock is not set")
+		return 0, nil
+	}
+	return m.ClearLoginFailuresFn(ctx, keys)
+}
+
+func (m *AuthRepositoryMock) DeleteStaleLoginFailures(ctx context.Context, before time.Time) (int64, error) {
+	if m.DeleteStaleLoginFailuresFn == nil {
+		m.ctrl.Fatalf("DeleteStaleLoginFailures mock is not set")
+		return 0, nil
+	}
+	return m.DeleteStaleLoginFailuresFn(ctx, before)
+}
+
+func (m *AuthRepositoryMock) CreateUserToken(ctx context.Context, token model.UserToken) error {
+	if m.CreateUserTokenFn == nil {
+		m.ctrl.Fatalf("CreateUserToken mock is not set")
+		return nil
+	}
+	return m.CreateUserTokenFn(ctx, token)
+}
+
+func (m *AuthRepositoryMock) GetUserToken(ctx context.Context, purpose, tokenHash string, now time.Time) (model.UserToken, error) {
+	if m.GetUserTokenFn == nil {
+		m.ctrl.Fatalf("GetUserToken mock is not set")
+		return model.UserToken{}, nil
+	}
+	return m.GetUserTokenFn(ctx, purpose, tokenHash, now)
+}
+
+func (m *AuthRepositoryMock) ConsumeUserToken(ctx context.Context, purpose, tokenHash string, now time.Time) (model.UserToken, error) {
+	if m.ConsumeUserTokenFn == nil {
+		m.ctrl.Fatalf("ConsumeUserToken mock is not set")
+		return model.UserToken{}, nil
+	}
+	return m.ConsumeUserTokenFn(ctx, purpose, tokenHash, now)
+}
+
+func (m *AuthRepositoryMock) GetIdentity(ctx context.Context, provider, subject string) (model.Identity, error) {
+	if m.GetIdentityFn == nil {
+		m.ctrl.Fatalf("GetIdentity mock is not set")
+		return model.Identity{}, nil
+	}
+	return m.GetIdentityFn(ctx, provider, subject)
+}
+
+func (m *AuthRepositoryMock) CreateIdentity(ctx context.Context, identity model.Identity) error {
+	if m.CreateIdentityFn == nil {
+		m.ctrl.Fatalf("CreateIdentity mock is not set")
+		return nil
+	}
+	return m.CreateIdentityFn(ctx, identity)
+}
+
+func (m *AuthRepositoryMock) CreateUserWithIdentity(ctx context.Context, user model.User, identity model.Identity) (model.User, error) {
+	if m.CreateUserWithIdentityFn == nil {
+		m.ctrl.Fatalf("CreateUserWithIdentity mock is not set")
+		return model.User{}, nil
+	}
+	return m.CreateUserWithIdentityFn(ctx, user, identity)
+}
+
+func (m *AuthRepositoryMock) UpdateIdentityLogin(ctx context.Context, id, email string, at time.Time) error {
+	if m.UpdateIdentityLoginFn == nil {
+		m.ctrl.Fatalf("UpdateIdentityLogin mock is not set")
+		return nil
+	}
+	return m.UpdateIdentityLoginFn(ctx, id, email, at)
+}
+
+func (m *AuthRepositoryMock) CreateOIDCLogin(ctx context.Context, login model.OIDCLogin) error {
+	if m.CreateOIDCLoginFn == nil {
+		m.ctrl.Fatalf("CreateOIDCLogin mock is not set")
+		return nil
+	}
+	return m.CreateOIDCLoginFn(ctx, login)
+}
+
+func (m *AuthRepositoryMock) ConsumeOIDCLogin(ctx context.Context, provider, stateHash string, now time.Time) (model.OIDCLogin, error) {
+	if m.ConsumeOIDCLoginFn == nil {
+		m.ctrl.Fatalf("ConsumeOIDCLogin mock is not set")
+		return model.OIDCLogin{}, nil
+	}
+	return m.ConsumeOIDCLoginFn(ctx, provider, stateHash, now)
+}
+
+func (m *AuthRepositoryMock) CreateRefreshToken(ctx context.Context, token model.RefreshToken) error {
+	if m.CreateRefreshTokenFn == nil {
+		m.ctrl.Fatalf("CreateRefreshToken mock is not set")
+		return nil
+	}
+	return m.CreateRefreshTokenFn(ctx, token)
+}
+
+func (m *AuthRepositoryMock) GetRefreshTokenByHash(ctx context.Context, tokenHash string) (model.RefreshToken, error) {
+	if m.GetRefreshTokenByHashFn == nil {
+		m.ctrl.Fatalf("GetRefreshTokenByHash mock is not set")
+		return model.RefreshToken{}, nil
+	}
+	return m.GetRefreshTokenByHashFn(ctx, tokenHash)
+}
+
+func (m *AuthRepositoryMock) RotateRefreshToken(ctx context.Context, oldID string, next model.RefreshToken) error {
+	if m.RotateRefreshTokenFn == nil {
+		m.ctrl.Fatalf("RotateRefreshToken mock is not set")
+		return nil
+	}
+	return m.RotateRefreshTokenFn(ctx, oldID, next)
+}
+
+func (m *AuthRepositoryMock) RevokeRefreshTokenFamily(ctx context.Context, familyID string) error {
+	if m.RevokeRefreshTokenFamilyFn == nil {
+		m.ctrl.Fatalf("RevokeRefreshTokenFamily mock is not set")
+		return nil
+	}
+	return m.RevokeRefreshTokenFamilyFn(ctx, familyID)
+}
+
+func (m *AuthRepositoryMock) RevokeUserRefreshTokens(ctx context.Context, userID string) (int64, error) {
+	if m.RevokeUserRefreshTokensFn == nil {
+		m.ctrl.Fatalf("RevokeUserRefreshTokens mock is not set")
+		return 0, nil
+	}
+	return m.RevokeUserRefreshTokensFn(ctx, userID)
+}
+
+func (m *AuthRepositoryMock) RevokeAccessToken(ctx context.Context, token model.RevokedToken) error {
+	if m.RevokeAccessTokenFn == nil {
+		m.ctrl.Fatalf("RevokeAccessToken mock is not set")
+		return nil
+	}
+	return m.RevokeAccessTokenFn(ctx, token)
+}
+
+func (m *AuthRepositoryMock) RevokeUserAccessTokens(ctx context.Context, user model.RevokedUser) error {
+	if m.RevokeUserAccessTokensFn == nil {
+		m.ctrl.Fatalf("RevokeUserAccessTokens mock is not set")
+		return nil
+	}
+	return m.RevokeUserAccessTokensFn(ctx, user)
+}
+
+func (m *AuthRepositoryMock) IsAccessTokenRevoked(ctx context.Context, tokenID, userID string, issuedAt time.Time) (bool, error) {
+	if m.IsAccessTokenRevokedFn == nil {
+		m.ctrl.Fatalf("IsAccessTokenRevoked mock is not set")
+		return false, nil
+	}
+	return m.IsAccessTokenRevokedFn(ctx, tokenID, userID, issuedAt)
+}
+
+func (m *AuthRepositoryMock) DeleteExpiredTokens(ctx context.Context, before time.Time) (int64, error) {
+	if m.DeleteExpiredTokensFn == nil {
+		m.ctrl.Fatalf("DeleteExpiredTokens mock is not set")
+		return 0, nil
+	}
+	return m.DeleteExpiredTokensFn(ctx, before)
+}
+
+func (m *AuthRepositoryMock) ListSigningKeys(ctx context.Context) ([]model.SigningKey, error) {
+	if m.ListSigningKeysFn == nil {
+		m.ctrl.Fatalf("ListSigningKeys mock is not set")
+		return nil, nil
+	}
+	return m.ListSigningKeysFn(ctx)
+}
+
+func (m *AuthRepositoryMock) CreateSigningKey(ctx context.Context, key model.SigningKey) error {
+	if m.CreateSigningKeyFn == nil {
+		m.ctrl.Fatalf("CreateSigningKey mock is not set")
+		return nil
+	}
+	return m.CreateSigningKeyFn(ctx, key)
+}
+
+func (m *AuthRepositoryMock) DeleteExpiredSigningKeys(ctx context.Context, before time.Time) (int64, error) {
+	if m.DeleteExpiredSigningKeysFn == nil {
+		m.ctrl.Fatalf("DeleteExpiredSigningKeys mock is not set")
+		return 0, nil
+	}
+	return m.DeleteExpiredSigningKeysFn(ctx, before)
+}
+
+func (m *AuthRepositoryMock) ListRevokedTokens(ctx context.Context, since time.Time) ([]model.RevokedToken, error) {
+	if m.ListRevokedTokensFn == nil {
+		m.ctrl.Fatalf("ListRevokedTokens mock is not set")
+		return nil, nil
+	}
+	return m.ListRevokedTokensFn(ctx, since)
+}
+
+func (m *AuthRepositoryMock) ListRevokedUsers(ctx context.Context, since time.Time) ([]model.RevokedUser, error) {
+	if m.ListRevokedUsersFn == nil {
+		m.ctrl.Fatalf("ListRevokedUsers mock is not set")
+		return nil, nil
+	}
+	return m.ListRevokedUsersFn(ctx, since)
+}
+
+func (m *AuthRepositoryMock) ListPendingAccountPurges(ctx context.Context, limit int) ([]model.AccountPurge, error) {
+	if m.ListPendingAccountPurgesFn == nil {
+		m.ctrl.Fatalf("ListPendingAccountPurges mock is not set")
+		return nil, nil
+	}
+	return m.ListPendingAccountPurgesFn(ctx, limit)
+}
+
+func (m *AuthRepositoryMock) CompleteAccountPurge(ctx context.Context, userID string) error {
+	if m.CompleteAccountPurgeFn == nil {
+		m.ctrl.Fatalf("CompleteAccountPurge mock is not set")
+		return nil
+	}
+	return m.CompleteAccountPurgeFn(ctx, userID)
+}
+
+func (m *AuthRepositoryMock) FailAccountPurge(ctx context.Context, userID, reason string) error {
+	if m.FailAccountPurgeFn == nil {
+		m.ctrl.Fatalf("FailAccountPurge mock is not set")
+		return nil
+	}
+	return m.FailAccountPurgeFn(ctx, userID, reason)
+}
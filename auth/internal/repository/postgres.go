@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
@@ -12,6 +13,9 @@ import (
 	"github.com/Deevins/final-task-course-2-go-lang/auth/internal/storage"
 )
 
+const userColumns = `id, email, name, password_hash, created_at, updated_at, email_verified_at,
+	totp_secret, totp_enabled_at, totp_last_step, roles`
+
 type PostgresAuthRepository struct {
 	db *pgxpool.Pool
 }
@@ -22,8 +26,8 @@ func NewPostgresAuthRepository(db *pgxpool.Pool) *PostgresAuthRepository {
 
 func (r *PostgresAuthRepository) CreateUser(ctx context.Context, user model.User) (model.User, error) {
 	const query = `
-		INSERT INTO users (id, email, name, password_hash, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6)`
+		INSERT INTO users (id, email, name, password_hash, created_at, updated_at, email_verified_at, roles)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
 	_, err := r.db.Exec(
 		ctx,
 		query,
@@ -33,6 +37,8 @@ func (r *PostgresAuthRepository) CreateUser(ctx context.Context, user model.User
 		user.PasswordHash,
 		user.CreatedAt,
 		user.UpdatedAt,
+		user.EmailVerifiedAt,
+		user.Roles,
 	)
 	if err != nil {
 		var pgErr *pgconn.PgError
@@ -46,42 +52,81 @@ func (r *PostgresAuthRepository) CreateUser(ctx context.Context, user model.User
 }
 
 func (r *PostgresAuthRepository) GetUserByEmail(ctx context.Context, email string) (model.User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE email = $1`
+	return scanUser(r.db.QueryRow(ctx, query, email))
+}
+
+func (r *PostgresAuthRepository) GetUserByID(ctx context.Context, id string) (model.User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE id = $1`
+	return scanUser(r.db.QueryRow(ctx, query, id))
+}
+
+func (r *PostgresAuthRepository) UpdateUser(ctx context.Context, user model.User) (model.User, error) {
+	query := `
+		UPDATE users
+		SET name = $2, password_hash = $3, updated_at = $4
+		WHERE id = $1
+		RETURNING ` + userColumns
+	return scanUser(r.db.QueryRow(ctx, query, user.ID, user.Name, user.PasswordHash, user.UpdatedAt))
+}
+
+func (r *PostgresAuthRepository) SetUserRoles(ctx context.Context, userID string, roles []string, updatedAt time.Time) (model.User, error) {
+	query := `
+		UPDATE users
+		SET roles = $2, updated_at = $3
+		WHERE id = $1
+		RETURNING ` + userColumns
+	return scanUser(r.db.QueryRow(ctx, query, userID, roles, updatedAt))
+}
+
+func (r *PostgresAuthRepository) MarkEmailVerified(ctx context.Context, userID string, verifiedAt time.Time) error {
 	const query = `
-		SELECT id, email, name, password_hash, created_at, updated_at
-		FROM users
-		WHERE email = $1`
-	var user model.User
-	err := r.db.QueryRow(ctx, query, email).Scan(
-		&user.ID,
-		&user.Email,
-		&user.Name,
-		&user.PasswordHash,
-		&user.CreatedAt,
-		&user.UpdatedAt,
-	)
+		UPDATE users
+		SET email_verified_at = COALESCE(email_verified_at, $2), updated_at = $2
+		WHERE id = $1`
+	tag, err := r.db.Exec(ctx, query, userID, verifiedAt)
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return model.User{}, storage.ErrNotFound
-		}
-		return model.User{}, err
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return storage.ErrNotFound
 	}
+	return nil
+}
 
-	return user, nil
+func (r *PostgresAuthRepository) DeleteUser(ctx context.Context, id string, requestedAt time.Time) error {
+	return pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, `DELETE FROM users WHERE id = $1`, id)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return storage.ErrNotFound
+		}
+
+		const query = `
+			INSERT INTO account_purges (user_id, requested_at)
+			VALUES ($1, $2)
+			ON CONFLICT (user_id) DO NOTHING`
+		_, err = tx.Exec(ctx, query, id, requestedAt)
+		return err
+	})
 }
 
-func (r *PostgresAuthRepository) GetUserByID(ctx context.Context, id string) (model.User, error) {
-	const query = `
-		SELECT id, email, name, password_hash, created_at, updated_at
-		FROM users
-		WHERE id = $1`
+func scanUser(row pgx.Row) (model.User, error) {
 	var user model.User
-	err := r.db.QueryRow(ctx, query, id).Scan(
+	err := row.Scan(
 		&user.ID,
 		&user.Email,
 		&user.Name,
 		&user.PasswordHash,
 		&user.CreatedAt,
 		&user.UpdatedAt,
+		&user.EmailVerifiedAt,
+		&user.TOTPSecret,
+		&user.TOTPEnabledAt,
+		&user.TOTPLastStep,
+		&user.Roles,
 	)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/Deevins/final-task-course-2-go-lang/auth/internal/model"
+)
+
+func (r *PostgresAuthRepository) ListPendingAccountPurges(ctx context.Context, limit int) ([]model.AccountPurge, error) {
+	const query = `
+		SELECT user_id, requested_at, attempts, last_error, last_attempt_at
+		FROM account_purges
+		ORDER BY requested_at
+		LIMIT $1`
+	rows, err := r.db.Query(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var purges []model.AccountPurge
+	for rows.Next() {
+		var purge model.AccountPurge
+		if err := rows.Scan(
+			&purge.UserID,
+			&purge.RequestedAt,
+			&purge.Attempts,
+			&purge.LastError,
+			&purge.LastAttemptAt,
+		); err != nil {
+			return nil, err
+		}
+		purges = append(purges, purge)
+	}
+
+	return purges, rows.Err()
+}
+
+func (r *PostgresAuthRepository) CompleteAccountPurge(ctx context.Context, userID string) error {
+	_, err := r.db.Exec(ctx, `DELETE FROM account_purges WHERE user_id = $1`, userID)
+	return err
+}
+
+func (r *PostgresAuthRepository) FailAccountPurge(ctx context.Context, userID, reason string) error {
+	const query = `
+		UPDATE account_purges
+		SET attempts = attempts + 1, last_error = $2, last_attempt_at = now()
+		WHERE user_id = $1`
+	_, err := r.db.Exec(ctx, query, userID, reason)
+	return err
+}
@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/Deevins/final-task-course-2-go-lang/auth/internal/model"
+	"github.com/Deevins/final-task-course-2-go-lang/auth/internal/storage"
+)
+
+func (r *PostgresAuthRepository) GetIdentity(ctx context.Context, provider, subject string) (model.Identity, error) {
+	const query = `
+		SELECT id, user_id, provider, subject, email, created_at, last_login_at
+		FROM identities
+		WHERE provider = $1 AND subject = $2`
+	var identity model.Identity
+	err := r.db.QueryRow(ctx, query, provider, subject).Scan(
+		&identity.ID,
+		&identity.UserID,
+		&identity.Provider,
+		&identity.Subject,
+		&identity.Email,
+		&identity.CreatedAt,
+		&identity.LastLoginAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.Identity{}, storage.ErrNotFound
+		}
+		return model.Identity{}, err
+	}
+	return identity, nil
+}
+
+func (r *PostgresAuthRepository) CreateIdentity(ctx context.Context, identity model.Identity) error {
+	return insertIdentity(ctx, r.db, identity)
+}
+
+func (r *PostgresAuthRepository) CreateUserWithIdentity(ctx context.Context, user model.User, identity model.Identity) (model.User, error) {
+	err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		const query = `
+			INSERT INTO users (id, email, name, password_hash, created_at, updated_at, email_verified_at, roles)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+		_, err := tx.Exec(
+			ctx,
+			query,
+			user.ID,
+			user.Email,
+			user.Name,
+			user.PasswordHash,
+			user.CreatedAt,
+			user.UpdatedAt,
+			user.EmailVerifiedAt,
+			user.Roles,
+		)
+		if err != nil {
+			return duplicateError(err)
+		}
+		return insertIdentity(ctx, tx, identity)
+	})
+	if err != nil {
+		return model.User{}, err
+	}
+	return user, nil
+}
+
+func (r *PostgresAuthRepository) UpdateIdentityLogin(ctx context.Context, id, email string, at time.Time) error {
+	tag, err := r.db.Exec(ctx, `UPDATE identities SET email = $2, last_login_at = $3 WHERE id = $1`, id, email, at)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
+
+func (r *PostgresAuthRepository) CreateOIDCLogin(ctx context.Context, login model.OIDCLogin) error {
+	const query = `
+		INSERT INTO oidc_logins (state_hash, provider, nonce, code_verifier, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)`
+	_, err := r.db.Exec(
+		ctx,
+		query,
+		login.StateHash,
+		login.Provider,
+		login.Nonce,
+		login.CodeVerifier,
+		login.CreatedAt,
+		login.ExpiresAt,
+	)
+	return err
+}
+
+func (r *PostgresAuthRepository) ConsumeOIDCLogin(ctx context.Context, provider, stateHash string, now time.Time) (model.OIDCLogin, error) {
+	const query = `
+		DELETE FROM oidc_logins
+		WHERE state_hash = $1 AND provider = $2 AND expires_at > $3
+		RETURNING state_hash, provider, nonce, code_verifier, created_at, expires_at`
+	var login model.OIDCLogin
+	err := r.db.QueryRow(ctx, query, stateHash, provider, now).Scan(
+		&login.StateHash,
+		&login.Provider,
+		&login.Nonce,
+		&login.CodeVerifier,
+		&login.CreatedAt,
+		&login.ExpiresAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.OIDCLogin{}, storage.ErrNotFound
+		}
+		return model.OIDCLogin{}, err
+	}
+	return login, nil
+}
+
+func insertIdentity(ctx context.Context, db execer, identity model.Identity) error {
+	const query = `
+		INSERT INTO identities (id, user_id, provider, subject, email, created_at, last_login_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`
+	_, err := db.Exec(
+		ctx,
+		query,
+		identity.ID,
+		identity.UserID,
+		identity.Provider,
+		identity.Subject,
+		identity.Email,
+		identity.CreatedAt,
+		identity.LastLoginAt,
+	)
+	return duplicateError(err)
+}
+
+// duplicateError maps unique violations to storage.ErrDuplicate.
+func duplicateError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return storage.ErrDuplicate
+	}
+	return err
+}
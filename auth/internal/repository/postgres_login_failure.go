@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Deevins/final-task-course-2-go-lang/auth/internal/model"
+)
+
+func (r *PostgresAuthRepository) ListLoginFailures(ctx context.Context, keys []string, since time.Time) ([]model.LoginFailure, error) {
+	const query = `
+		SELECT key, failures, last_failure_at
+		FROM login_failures
+		WHERE key = ANY($1) AND last_failure_at >= $2`
+	rows, err := r.db.Query(ctx, query, keys, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var failures []model.LoginFailure
+	for rows.Next() {
+		var failure model.LoginFailure
+		if err := rows.Scan(&failure.Key, &failure.Failures, &failure.LastFailureAt); err != nil {
+			return nil, err
+		}
+		failures = append(failures, failure)
+	}
+	return failures, rows.Err()
+}
+
+func (r *PostgresAuthRepository) RecordLoginFailure(ctx context.Context, key string, at, windowStart time.Time) (model.LoginFailure, error) {
+	const query = `
+		INSERT INTO login_failures (key, failures, last_failure_at)
+		VALUES ($1, 1, $2)
+		ON CONFLICT (key) DO UPDATE
+		SET failures = CASE
+				WHEN login_failures.last_failure_at < $3 THEN 1
+				ELSE login_failures.failures + 1
+			END,
+			last_failure_at = $2
+		RETURNING key, failures, last_failure_at`
+	var failure model.LoginFailure
+	err := r.db.QueryRow(ctx, query, key, at, windowStart).Scan(&failure.Key, &failure.Failures, &failure.LastFailureAt)
+	if err != nil {
+		return model.LoginFailure{}, err
+	}
+	return failure, nil
+}
+
+func (r *PostgresAuthRepository) ReleaseLoginFailure(ctx context.Context, key string) error {
+	const query = `
+		UPDATE login_failures
+		SET failures = GREATEST(failures - 1, 0)
+		WHERE key = $1`
+	_, err := r.db.Exec(ctx, query, key)
+	return err
+}
+
+func (r *PostgresAuthRepository) ClearLoginFailures(ctx context.Context, keys []string) (int64, error) {
+	tag, err := r.db.Exec(ctx, `DELETE FROM login_failures WHERE key = ANY($1)`, keys)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+func (r *PostgresAuthRepository) DeleteStaleLoginFailures(ctx context.Context, before time.Time) (int64, error) {
+	tag, err := r.db.Exec(ctx, `DELETE FROM login_failures WHERE last_failure_at < $1`, before)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Deevins/final-task-course-2-go-lang/auth/internal/model"
+)
+
+func (r *PostgresAuthRepository) ListSigningKeys(ctx context.Context) ([]model.SigningKey, error) {
+	const query = `
+		SELECT kid, algorithm, private_key, created_at, retires_at, expires_at
+		FROM signing_keys
+		WHERE expires_at > now()
+		ORDER BY created_at DESC`
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []model.SigningKey
+	for rows.Next() {
+		var key model.SigningKey
+		if err := rows.Scan(
+			&key.ID,
+			&key.Algorithm,
+			&key.PrivateKey,
+			&key.CreatedAt,
+			&key.RetiresAt,
+			&key.ExpiresAt,
+		); err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, rows.Err()
+}
+
+func (r *PostgresAuthRepository) CreateSigningKey(ctx context.Context, key model.SigningKey) error {
+	const query = `
+		INSERT INTO signing_keys (kid, algorithm, private_key, created_at, retires_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)`
+	_, err := r.db.Exec(
+		ctx,
+		query,
+		key.ID,
+		key.Algorithm,
+		key.PrivateKey,
+		key.CreatedAt,
+		key.RetiresAt,
+		key.ExpiresAt,
+	)
+	return err
+}
+
+func (r *PostgresAuthRepository) DeleteExpiredSigningKeys(ctx context.Context, before time.Time) (int64, error) {
+	tag, err := r.db.Exec(ctx, `DELETE FROM signing_keys WHERE expires_at < $1`, before)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
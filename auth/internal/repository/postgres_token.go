@@ -0,0 +1,253 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/Deevins/final-task-course-2-go-lang/auth/internal/model"
+	"github.com/Deevins/final-task-course-2-go-lang/auth/internal/storage"
+)
+
+const refreshTokenColumns = `id, user_id, family_id, token_hash, access_token_id, access_expires_at,
+		expires_at, created_at, revoked_at, COALESCE(replaced_by, '')`
+
+func (r *PostgresAuthRepository) CreateRefreshToken(ctx context.Context, token model.RefreshToken) error {
+	return insertRefreshToken(ctx, r.db, token)
+}
+
+func (r *PostgresAuthRepository) GetRefreshTokenByHash(ctx context.Context, tokenHash string) (model.RefreshToken, error) {
+	query := `SELECT ` + refreshTokenColumns + ` FROM refresh_tokens WHERE token_hash = $1`
+	var token model.RefreshToken
+	err := r.db.QueryRow(ctx, query, tokenHash).Scan(
+		&token.ID,
+		&token.UserID,
+		&token.FamilyID,
+		&token.TokenHash,
+		&token.AccessTokenID,
+		&token.AccessExpiresAt,
+		&token.ExpiresAt,
+		&token.CreatedAt,
+		&token.RevokedAt,
+		&token.ReplacedBy,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.RefreshToken{}, storage.ErrNotFound
+		}
+		return model.RefreshToken{}, err
+	}
+
+	return token, nil
+}
+
+func (r *PostgresAuthRepository) RotateRefreshToken(ctx context.Context, oldID string, next model.RefreshToken) error {
+	return pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		const query = `
+			UPDATE refresh_tokens
+			SET revoked_at = now(), replaced_by = $2
+			WHERE id = $1 AND revoked_at IS NULL`
+		tag, err := tx.Exec(ctx, query, oldID, next.ID)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return storage.ErrNotFound
+		}
+
+		return insertRefreshToken(ctx, tx, next)
+	})
+}
+
+func (r *PostgresAuthRepository) RevokeRefreshTokenFamily(ctx context.Context, familyID string) error {
+	_, err := r.revokeRefreshTokens(ctx, "family_id", familyID)
+	return err
+}
+
+func (r *PostgresAuthRepository) RevokeUserRefreshTokens(ctx context.Context, userID string) (int64, error) {
+	return r.revokeRefreshTokens(ctx, "user_id", userID)
+}
+
+// revokeRefreshTokens revokes the active refresh tokens matching column = value
+// and denylists every access token issued with them that is still valid.
+// column is always a constant supplied by the caller.
+func (r *PostgresAuthRepository) revokeRefreshTokens(ctx context.Context, column, value string) (int64, error) {
+	var revoked int64
+	err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		denylistQuery := `
+			INSERT INTO revoked_tokens (jti, user_id, expires_at)
+			SELECT access_token_id, user_id, access_expires_at
+			FROM refresh_tokens
+			WHERE ` + column + ` = $1 AND access_expires_at > now()
+			ON CONFLICT (jti) DO NOTHING`
+		if _, err := tx.Exec(ctx, denylistQuery, value); err != nil {
+			return err
+		}
+
+		revokeQuery := `
+			UPDATE refresh_tokens
+			SET revoked_at = now()
+			WHERE ` + column + ` = $1 AND revoked_at IS NULL`
+		tag, err := tx.Exec(ctx, revokeQuery, value)
+		if err != nil {
+			return err
+		}
+		revoked = tag.RowsAffected()
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return revoked, nil
+}
+
+func (r *PostgresAuthRepository) RevokeAccessToken(ctx context.Context, token model.RevokedToken) error {
+	const query = `
+		INSERT INTO revoked_tokens (jti, user_id, expires_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (jti) DO NOTHING`
+	_, err := r.db.Exec(ctx, query, token.ID, token.UserID, token.ExpiresAt)
+	return err
+}
+
+func (r *PostgresAuthRepository) RevokeUserAccessTokens(ctx context.Context, user model.RevokedUser) error {
+	const query = `
+		INSERT INTO revoked_users (user_id, revoked_before, expires_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id) DO UPDATE
+		SET revoked_before = GREATEST(revoked_users.revoked_before, EXCLUDED.revoked_before),
+			expires_at = GREATEST(revoked_users.expires_at, EXCLUDED.expires_at),
+			revoked_at = now()`
+	_, err := r.db.Exec(ctx, query, user.UserID, user.RevokedBefore, user.ExpiresAt)
+	return err
+}
+
+func (r *PostgresAuthRepository) IsAccessTokenRevoked(ctx context.Context, tokenID, userID string, issuedAt time.Time) (bool, error) {
+	const query = `
+		SELECT EXISTS (SELECT 1 FROM revoked_tokens WHERE jti = $1)
+			OR EXISTS (SELECT 1 FROM revoked_users WHERE user_id = $2 AND revoked_before > $3)`
+	var revoked bool
+	if err := r.db.QueryRow(ctx, query, tokenID, userID, issuedAt).Scan(&revoked); err != nil {
+		return false, err
+	}
+
+	return revoked, nil
+}
+
+func (r *PostgresAuthRepository) ListRevokedTokens(ctx context.Context, since time.Time) ([]model.RevokedToken, error) {
+	const query = `
+		SELECT jti, user_id, expires_at, revoked_at
+		FROM revoked_tokens
+		WHERE revoked_at > $1 AND expires_at > now()
+		ORDER BY revoked_at`
+	rows, err := r.db.Query(ctx, query, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tokens []model.RevokedToken
+	for rows.Next() {
+		var token model.RevokedToken
+		if err := rows.Scan(&token.ID, &token.UserID, &token.ExpiresAt, &token.RevokedAt); err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, token)
+	}
+
+	return tokens, rows.Err()
+}
+
+func (r *PostgresAuthRepository) ListRevokedUsers(ctx context.Context, since time.Time) ([]model.RevokedUser, error) {
+	const query = `
+		SELECT user_id, revoked_before, expires_at, revoked_at
+		FROM revoked_users
+		WHERE revoked_at > $1 AND expires_at > now()
+		ORDER BY revoked_at`
+	rows, err := r.db.Query(ctx, query, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []model.RevokedUser
+	for rows.Next() {
+		var user model.RevokedUser
+		if err := rows.Scan(&user.UserID, &user.RevokedBefore, &user.ExpiresAt, &user.RevokedAt); err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+
+	return users, rows.Err()
+}
+
+func (r *PostgresAuthRepository) DeleteExpiredTokens(ctx context.Context, before time.Time) (int64, error) {
+	var deleted int64
+	err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, `DELETE FROM refresh_tokens WHERE expires_at < $1`, before)
+		if err != nil {
+			return err
+		}
+		deleted += tag.RowsAffected()
+
+		tag, err = tx.Exec(ctx, `DELETE FROM revoked_tokens WHERE expires_at < $1`, before)
+		if err != nil {
+			return err
+		}
+		deleted += tag.RowsAffected()
+
+		tag, err = tx.Exec(ctx, `DELETE FROM revoked_users WHERE expires_at < $1`, before)
+		if err != nil {
+			return err
+		}
+		deleted += tag.RowsAffected()
+
+		tag, err = tx.Exec(ctx, `DELETE FROM user_tokens WHERE expires_at < $1`, before)
+		if err != nil {
+			return err
+		}
+		deleted += tag.RowsAffected()
+
+		tag, err = tx.Exec(ctx, `DELETE FROM oidc_logins WHERE expires_at < $1`, before)
+		if err != nil {
+			return err
+		}
+		deleted += tag.RowsAffected()
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return deleted, nil
+}
+
+type execer interface {
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+}
+
+func insertRefreshToken(ctx context.Context, db execer, token model.RefreshToken) error {
+	const query = `
+		INSERT INTO refresh_tokens (
+			id, user_id, family_id, token_hash, access_token_id, access_expires_at, expires_at, created_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+	_, err := db.Exec(
+		ctx,
+		query,
+		token.ID,
+		token.UserID,
+		token.FamilyID,
+		token.TokenHash,
+		token.AccessTokenID,
+		token.AccessExpiresAt,
+		token.ExpiresAt,
+		token.CreatedAt,
+	)
+	return err
+}
@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/Deevins/final-task-course-2-go-lang/auth/internal/storage"
+)
+
+func (r *PostgresAuthRepository) SetTOTPSecret(ctx context.Context, userID, secret string, updatedAt time.Time) error {
+	const query = `
+		UPDATE users
+		SET totp_secret = $2, updated_at = $3
+		WHERE id = $1 AND totp_enabled_at IS NULL`
+	tag, err := r.db.Exec(ctx, query, userID, secret, updatedAt)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
+
+func (r *PostgresAuthRepository) EnableTOTP(
+	ctx context.Context,
+	userID string,
+	step int64,
+	enabledAt time.Time,
+	recoveryCodeHashes []string,
+) error {
+	return pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		const updateQuery = `
+			UPDATE users
+			SET totp_enabled_at = $2, totp_last_step = $3, updated_at = $2
+			WHERE id = $1 AND totp_secret <> '' AND totp_enabled_at IS NULL`
+		tag, err := tx.Exec(ctx, updateQuery, userID, enabledAt, step)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return storage.ErrNotFound
+		}
+
+		return replaceRecoveryCodes(ctx, tx, userID, recoveryCodeHashes, enabledAt)
+	})
+}
+
+func (r *PostgresAuthRepository) DisableTOTP(ctx context.Context, userID string, updatedAt time.Time) error {
+	return pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		const updateQuery = `
+			UPDATE users
+			SET totp_secret = '', totp_enabled_at = NULL, totp_last_step = 0, updated_at = $2
+			WHERE id = $1`
+		tag, err := tx.Exec(ctx, updateQuery, userID, updatedAt)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return storage.ErrNotFound
+		}
+
+		_, err = tx.Exec(ctx, `DELETE FROM recovery_codes WHERE user_id = $1`, userID)
+		return err
+	})
+}
+
+func (r *PostgresAuthRepository) UseTOTPStep(ctx context.Context, userID string, step int64) error {
+	const query = `
+		UPDATE users
+		SET totp_last_step = $2
+		WHERE id = $1 AND totp_enabled_at IS NOT NULL AND totp_last_step < $2`
+	tag, err := r.db.Exec(ctx, query, userID, step)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
+
+func (r *PostgresAuthRepository) UseRecoveryCode(ctx context.Context, userID, codeHash string, usedAt time.Time) error {
+	const query = `
+		UPDATE recovery_codes
+		SET used_at = $3
+		WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL`
+	tag, err := r.db.Exec(ctx, query, userID, codeHash, usedAt)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
+
+func replaceRecoveryCodes(ctx context.Context, tx pgx.Tx, userID string, codeHashes []string, createdAt time.Time) error {
+	if _, err := tx.Exec(ctx, `DELETE FROM recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+
+	const insertQuery = `
+		INSERT INTO recovery_codes (id, user_id, code_hash, created_at)
+		VALUES ($1, $2, $3, $4)`
+	batch := &pgx.Batch{}
+	for _, hash := range codeHashes {
+		batch.Queue(insertQuery, uuid.NewString(), userID, hash, createdAt)
+	}
+	return tx.SendBatch(ctx, batch).Close()
+}
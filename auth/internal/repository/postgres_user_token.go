@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/Deevins/final-task-course-2-go-lang/auth/internal/model"
+	"github.com/Deevins/final-task-course-2-go-lang/auth/internal/storage"
+)
+
+func (r *PostgresAuthRepository) CreateUserToken(ctx context.Context, token model.UserToken) error {
+	return pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		const deleteQuery = `DELETE FROM user_tokens WHERE user_id = $1 AND purpose = $2 AND used_at IS NULL`
+		if _, err := tx.Exec(ctx, deleteQuery, token.UserID, token.Purpose); err != nil {
+			return err
+		}
+
+		const insertQuery = `
+			INSERT INTO user_tokens (id, user_id, purpose, token_hash, expires_at, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6)`
+		_, err := tx.Exec(
+			ctx,
+			insertQuery,
+			token.ID,
+			token.UserID,
+			token.Purpose,
+			token.TokenHash,
+			token.ExpiresAt,
+			token.CreatedAt,
+		)
+		return err
+	})
+}
+
+func (r *PostgresAuthRepository) GetUserToken(ctx context.Context, purpose, tokenHash string, now time.Time) (model.UserToken, error) {
+	const query = `
+		SELECT id, user_id, purpose, token_hash, expires_at, created_at, used_at
+		FROM user_tokens
+		WHERE token_hash = $1 AND purpose = $2 AND used_at IS NULL AND expires_at > $3`
+	return scanUserToken(r.db.QueryRow(ctx, query, tokenHash, purpose, now))
+}
+
+func (r *PostgresAuthRepository) ConsumeUserToken(ctx context.Context, purpose, tokenHash string, now time.Time) (model.UserToken, error) {
+	const query = `
+		UPDATE user_tokens
+		SET used_at = $3
+		WHERE token_hash = $1 AND purpose = $2 AND used_at IS NULL AND expires_at > $3
+		RETURNING id, user_id, purpose, token_hash, expires_at, created_at, used_at`
+	return scanUserToken(r.db.QueryRow(ctx, query, tokenHash, purpose, now))
+}
+
+func scanUserToken(row pgx.Row) (model.UserToken, error) {
+	var token model.UserToken
+	err := row.Scan(
+		&token.ID,
+		&token.UserID,
+		&token.Purpose,
+		&token.TokenHash,
+		&token.ExpiresAt,
+		&token.CreatedAt,
+		&token.UsedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.UserToken{}, storage.ErrNotFound
+		}
+		return model.UserToken{}, err
+	}
+
+	return token, nil
+}
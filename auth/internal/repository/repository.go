@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/Deevins/final-task-course-2-go-lang/auth/internal/model"
 )
@@ -12,4 +13,107 @@ type AuthRepository interface {
 	CreateUser(ctx context.Context, user model.User) (model.User, error)
 	GetUserByEmail(ctx context.Context, email string) (model.User, error)
 	GetUserByID(ctx context.Context, id string) (model.User, error)
+	// UpdateUser stores the name, password hash and update time of an existing
+	// user. It returns storage.ErrNotFound if the user does not exist.
+	UpdateUser(ctx context.Context, user model.User) (model.User, error)
+	// DeleteUser removes the user with its refresh tokens and records an
+	// account purge request for the ledger in the same transaction.
+	DeleteUser(ctx context.Context, id string, requestedAt time.Time) error
+	// SetUserRoles replaces the roles of a user and returns the updated user.
+	// It returns storage.ErrNotFound if the user does not exist.
+	SetUserRoles(ctx context.Context, userID string, roles []string, updatedAt time.Time) (model.User, error)
+	// MarkEmailVerified records that the user confirmed the email address.
+	// It returns storage.ErrNotFound if the user does not exist.
+	MarkEmailVerified(ctx context.Context, userID string, verifiedAt time.Time) error
+
+	// SetTOTPSecret stores the secret of a pending enrollment. It returns
+	// storage.ErrNotFound if the user does not exist or already has two-factor
+	// authentication enabled.
+	SetTOTPSecret(ctx context.Context, userID, secret string, updatedAt time.Time) error
+	// EnableTOTP confirms the pending enrollment, records the step of the code
+	// that confirmed it and replaces the recovery codes of the user.
+	EnableTOTP(ctx context.Context, userID string, step int64, enabledAt time.Time, recoveryCodeHashes []string) error
+	// DisableTOTP removes the secret and the recovery codes of the user.
+	DisableTOTP(ctx context.Context, userID string, updatedAt time.Time) error
+	// UseTOTPStep records an accepted code. It returns storage.ErrNotFound if
+	// a code of the same or a later step was accepted before.
+	UseTOTPStep(ctx context.Context, userID string, step int64) error
+	// UseRecoveryCode marks an unused recovery code as used. It returns
+	// storage.ErrNotFound if the user has no such unused code.
+	UseRecoveryCode(ctx context.Context, userID, codeHash string, usedAt time.Time) error
+
+	// ListLoginFailures returns the failure counters of the given keys that
+	// were updated since the given time.
+	ListLoginFailures(ctx context.Context, keys []string, since time.Time) ([]model.LoginFailure, error)
+	// RecordLoginFailure increments the failure counter of key and returns it.
+	// A counter last updated before windowStart starts over.
+	RecordLoginFailure(ctx context.Context, key string, at, windowStart time.Time) (model.LoginFailure, error)
+	// ReleaseLoginFailure decrements the failure counter of key, giving back an
+	// attempt that was counted before it turned out to be right.
+	ReleaseLoginFailure(ctx context.Context, key string) error
+	// ClearLoginFailures resets the counters of the given keys and returns how
+	// many existed.
+	ClearLoginFailures(ctx context.Context, keys []string) (int64, error)
+	DeleteStaleLoginFailures(ctx context.Context, before time.Time) (int64, error)
+
+	// CreateUserToken stores a single-use token and invalidates the unused
+	// tokens of the same user and purpose.
+	CreateUserToken(ctx context.Context, token model.UserToken) error
+	// GetUserToken returns an unused, unexpired token without consuming it.
+	GetUserToken(ctx context.Context, purpose, tokenHash string, now time.Time) (model.UserToken, error)
+	// ConsumeUserToken marks an unused, unexpired token as used and returns it.
+	// It returns storage.ErrNotFound otherwise, so a token works only once.
+	ConsumeUserToken(ctx context.Context, purpose, tokenHash string, now time.Time) (model.UserToken, error)
+
+	// GetIdentity returns the identity of a provider subject or storage.ErrNotFound.
+	GetIdentity(ctx context.Context, provider, subject string) (model.Identity, error)
+	// CreateIdentity links an identity to an existing user. It returns
+	// storage.ErrDuplicate if the subject is linked already.
+	CreateIdentity(ctx context.Context, identity model.Identity) error
+	// CreateUserWithIdentity creates a user together with its first identity.
+	CreateUserWithIdentity(ctx context.Context, user model.User, identity model.Identity) (model.User, error)
+	// UpdateIdentityLogin records a sign-in and the email the provider reported.
+	UpdateIdentityLogin(ctx context.Context, id, email string, at time.Time) error
+	CreateOIDCLogin(ctx context.Context, login model.OIDCLogin) error
+	// ConsumeOIDCLogin removes and returns an unexpired sign-in of the
+	// provider, so a state works only once.
+	ConsumeOIDCLogin(ctx context.Context, provider, stateHash string, now time.Time) (model.OIDCLogin, error)
+
+	CreateRefreshToken(ctx context.Context, token model.RefreshToken) error
+	GetRefreshTokenByHash(ctx context.Context, tokenHash string) (model.RefreshToken, error)
+	// RotateRefreshToken revokes the active token oldID and stores next as its
+	// replacement. It returns storage.ErrNotFound if oldID is no longer active.
+	RotateRefreshToken(ctx context.Context, oldID string, next model.RefreshToken) error
+	// RevokeRefreshTokenFamily revokes every token of a session and denylists
+	// the access tokens issued with them that have not expired yet.
+	RevokeRefreshTokenFamily(ctx context.Context, familyID string) error
+	// RevokeUserRefreshTokens does the same for all sessions of a user and
+	// returns the number of sessions that were active.
+	RevokeUserRefreshTokens(ctx context.Context, userID string) (int64, error)
+
+	RevokeAccessToken(ctx context.Context, token model.RevokedToken) error
+	// RevokeUserAccessTokens stores the cutoff of a user. An earlier cutoff
+	// never replaces a later one.
+	RevokeUserAccessTokens(ctx context.Context, user model.RevokedUser) error
+	// IsAccessTokenRevoked reports whether the token is denylisted or was
+	// issued before the cutoff of its user.
+	IsAccessTokenRevoked(ctx context.Context, tokenID, userID string, issuedAt time.Time) (bool, error)
+	// ListRevokedTokens returns unexpired denylist entries revoked after since.
+	ListRevokedTokens(ctx context.Context, since time.Time) ([]model.RevokedToken, error)
+	// ListRevokedUsers returns unexpired user cutoffs set after since.
+	ListRevokedUsers(ctx context.Context, since time.Time) ([]model.RevokedUser, error)
+	// DeleteExpiredTokens removes refresh tokens, denylist entries, user
+	// cutoffs, emailed tokens and external sign-ins that expired before the cutoff.
+	DeleteExpiredTokens(ctx context.Context, before time.Time) (int64, error)
+
+	// ListSigningKeys returns the signing keys that have not expired, newest first.
+	ListSigningKeys(ctx context.Context) ([]model.SigningKey, error)
+	CreateSigningKey(ctx context.Context, key model.SigningKey) error
+	DeleteExpiredSigningKeys(ctx context.Context, before time.Time) (int64, error)
+
+	// ListPendingAccountPurges returns the oldest purge requests that have not been completed.
+	ListPendingAccountPurges(ctx context.Context, limit int) ([]model.AccountPurge, error)
+	CompleteAccountPurge(ctx context.Context, userID string) error
+	// FailAccountPurge records a failed attempt; the request stays pending.
+	FailAccountPurge(ctx context.Context, userID, reason string) error
 }
@@ -6,7 +6,8 @@ import (
 	httpHandler "github.com/Deevins/final-task-course-2-go-lang/auth/internal/handler/http"
 )
 
-func Register(engine *gin.Engine, healthHandler *httpHandler.HealthHandler) {
+func Register(engine *gin.Engine, healthHandler *httpHandler.HealthHandler, jwksHandler *httpHandler.JWKSHandler) {
 	api := engine.Group("/api/v1")
 	healthHandler.RegisterRoutes(api)
+	jwksHandler.RegisterRoutes(engine.Group("/.well-known"))
 }
@@ -3,7 +3,7 @@ package service
 import (
 	"context"
 	"errors"
-	"fmt"
+	"log"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -11,29 +11,120 @@ import (
 	"golang.org/x/crypto/bcrypt"
 
 	"github.com/Deevins/final-task-course-2-go-lang/auth/internal/config"
+	"github.com/Deevins/final-task-course-2-go-lang/auth/internal/mailer"
 	"github.com/Deevins/final-task-course-2-go-lang/auth/internal/model"
+	"github.com/Deevins/final-task-course-2-go-lang/auth/internal/oidc"
 	"github.com/Deevins/final-task-course-2-go-lang/auth/internal/repository"
+	"github.com/Deevins/final-task-course-2-go-lang/auth/internal/signing"
 	"github.com/Deevins/final-task-course-2-go-lang/auth/internal/storage"
 )
 
-var ErrInvalidCredentials = errors.New("invalid credentials")
+var (
+	ErrInvalidCredentials = errors.New("invalid credentials")
+	ErrInvalidToken       = errors.New("invalid token")
+	// ErrInvalidRefreshToken is returned for unknown, expired or logged out refresh tokens.
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	// ErrRefreshTokenReused is returned when an already rotated refresh token is
+	// presented again. The whole session is revoked in that case.
+	ErrRefreshTokenReused = errors.New("refresh token reuse detected")
+	// ErrWrongPassword is returned when the current password given to confirm
+	// a profile change does not match.
+	ErrWrongPassword = errors.New("wrong password")
+	// ErrInvalidEmailToken is returned for unknown, expired or already used
+	// email verification and password reset tokens.
+	ErrInvalidEmailToken    = errors.New("invalid or expired token")
+	ErrEmailAlreadyVerified = errors.New("email is already verified")
+	// ErrInvalidChallenge is returned for unknown, expired or already used
+	// sign-in challenges.
+	ErrInvalidChallenge     = errors.New("invalid or expired sign-in challenge")
+	ErrInvalidTwoFactorCode = errors.New("invalid two-factor code")
+	ErrTwoFactorEnabled     = errors.New("two-factor authentication is already enabled")
+	ErrTwoFactorNotEnabled  = errors.New("two-factor authentication is not enabled")
+	ErrTwoFactorNotEnrolled = errors.New("two-factor enrollment has not been started")
+)
 
 type AuthService interface {
 	Register(ctx context.Context, email, password, name string) (model.User, error)
 	Login(ctx context.Context, email, password string) (model.Token, error)
 	ValidateToken(ctx context.Context, accessToken string) (model.Token, bool, error)
+	Refresh(ctx context.Context, refreshToken string) (model.Token, error)
+	Logout(ctx context.Context, accessToken, refreshToken string) error
+	RevokeAllSessions(ctx context.Context, accessToken string) (int64, error)
+	ListRevokedTokens(ctx context.Context, since time.Time) ([]model.RevokedToken, []model.RevokedUser, time.Time, error)
+	GetMe(ctx context.Context, accessToken string) (model.User, error)
+	UpdateProfile(ctx context.Context, accessToken, name string) (model.User, error)
+	ChangePassword(ctx context.Context, accessToken, currentPassword, newPassword string) (model.Token, error)
+	DeleteAccount(ctx context.Context, accessToken, password string) error
+	RequestEmailVerification(ctx context.Context, accessToken string) error
+	ConfirmEmail(ctx context.Context, token string) error
+	RequestPasswordReset(ctx context.Context, email string) error
+	ResetPassword(ctx context.Context, token, newPassword string) error
+	EnrollTOTP(ctx context.Context, accessToken string) (model.TOTPEnrollment, error)
+	ConfirmTOTP(ctx context.Context, accessToken, code string) ([]string, error)
+	DisableTOTP(ctx context.Context, accessToken, password, code string) error
+	VerifyTwoFactor(ctx context.Context, challengeToken, code string) (model.Token, error)
+	UnlockAccount(ctx context.Context, accessToken, email, ip string) (bool, error)
+	IdentityProviders() []string
+	StartOIDCLogin(ctx context.Context, provider string) (model.OIDCAuthorization, error)
+	CompleteOIDCLogin(ctx context.Context, provider, state, code string) (model.Token, error)
+	SetUserRoles(ctx context.Context, accessToken, userID string, roles []string) (model.User, error)
+	LookupUser(ctx context.Context, accessToken, email string) (model.User, error)
 }
 
 type DefaultAuthService struct {
-	repo      repository.AuthRepository
-	jwtConfig config.JWTConfig
+	repo            repository.AuthRepository
+	jwtConfig       config.JWTConfig
+	keys            *signing.Keyring
+	mailer          mailer.Mailer
+	emailConfig     config.EmailConfig
+	twoFactorConfig config.TwoFactorConfig
+	loginConfig     config.LoginThrottleConfig
+	passwordPolicy  *PasswordPolicy
+	oidcConfig      config.OIDCConfig
+	// identityProviders are the external OpenID Connect providers by name.
+	identityProviders map[string]*oidc.Provider
 }
 
-func NewAuthService(repo repository.AuthRepository, jwtConfig config.JWTConfig) *DefaultAuthService {
-	return &DefaultAuthService{repo: repo, jwtConfig: jwtConfig}
+func NewAuthService(
+	repo repository.AuthRepository,
+	jwtConfig config.JWTConfig,
+	keys *signing.Keyring,
+	mail mailer.Mailer,
+	emailConfig config.EmailConfig,
+	twoFactorConfig config.TwoFactorConfig,
+	loginConfig config.LoginThrottleConfig,
+	passwordPolicy *PasswordPolicy,
+	oidcConfig config.OIDCConfig,
+) *DefaultAuthService {
+	return &DefaultAuthService{
+		repo:            repo,
+		jwtConfig:       jwtConfig,
+		keys:            keys,
+		mailer:          mail,
+		emailConfig:     emailConfig,
+		twoFactorConfig: twoFactorConfig,
+		loginConfig:     loginConfig,
+		passwordPolicy:  passwordPolicy,
+		oidcConfig:      oidcConfig,
+
+		identityProviders: newIdentityProviders(oidcConfig),
+	}
 }
 
+// accessClaims are the claims of an access token. EmailVerified and Roles let
+// the gateway authorize requests without asking the auth service.
+type accessClaims struct {
+	jwt.RegisteredClaims
+	EmailVerified bool     `json:"email_verified"`
+	Roles         []string `json:"roles,omitempty"`
+}
+
+// Register creates an unverified user and emails a verification link. The
+// password has to pass the password policy.
 func (s *DefaultAuthService) Register(ctx context.Context, email, password, name string) (model.User, error) {
+	if err := s.passwordPolicy.Check(password, email, name); err != nil {
+		return model.User{}, err
+	}
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	if err != nil {
 		return model.User{}, err
@@ -46,47 +137,67 @@ func (s *DefaultAuthService) Register(ctx context.Context, email, password, name
 		PasswordHash: string(hashedPassword),
 		CreatedAt:    now,
 		UpdatedAt:    now,
+		Roles:        []string{model.RoleUser},
 	}
 
-	return s.repo.CreateUser(ctx, user)
+	created, err := s.repo.CreateUser(ctx, user)
+	if err != nil {
+		return model.User{}, err
+	}
+
+	// The account exists at this point; a failed email can be requested again.
+	if err := s.sendVerificationEmail(ctx, created); err != nil {
+		log.Printf("send verification email to user %s: %v", created.ID, err)
+	}
+	return created, nil
 }
 
+// Login checks the credentials and starts a session. For users with
+// two-factor authentication enabled only a challenge is returned, which
+// VerifyTwoFactor exchanges for the session tokens.
+//
+// Unknown emails and wrong passwords both result in ErrInvalidCredentials
+// and take the same time. Attempts are counted per email and per client
+// address before the password is checked; while either has to wait, a
+// LoginThrottledError is returned.
 func (s *DefaultAuthService) Login(ctx context.Context, email, password string) (model.Token, error) {
+	keys := loginThrottleKeys(ctx, email)
+	if err := s.beginLoginAttempt(ctx, keys); err != nil {
+		return model.Token{}, err
+	}
+
 	user, err := s.repo.GetUserByEmail(ctx, email)
 	if err != nil {
-		return model.Token{}, err
+		if !IsNotFound(err) {
+			return model.Token{}, err
+		}
+		compareDummyPassword(password)
+		return model.Token{}, ErrInvalidCredentials
+	}
+	if user.PasswordHash == "" {
+		// Users created by an external sign-in have no password.
+		compareDummyPassword(password)
+		return model.Token{}, ErrInvalidCredentials
 	}
 	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
 		return model.Token{}, ErrInvalidCredentials
 	}
-
-	expiresAt := time.Now().UTC().Add(s.jwtConfig.Expiry)
-	claims := jwt.RegisteredClaims{
-		Subject:   user.ID,
-		ExpiresAt: jwt.NewNumericDate(expiresAt),
-		IssuedAt:  jwt.NewNumericDate(time.Now().UTC()),
+	if user.TwoFactorEnabled() {
+		// The account counter is reset once the second factor is verified.
+		if err := s.finishLoginAttempt(ctx, keys, false); err != nil {
+			return model.Token{}, err
+		}
+		return s.startChallenge(ctx, user)
 	}
-	jwtToken := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	accessToken, err := jwtToken.SignedString([]byte(s.jwtConfig.Secret))
-	if err != nil {
+
+	if err := s.finishLoginAttempt(ctx, keys, true); err != nil {
 		return model.Token{}, err
 	}
-
-	return model.Token{
-		AccessToken: accessToken,
-		UserID:      user.ID,
-		ExpiresAt:   expiresAt,
-	}, nil
+	return s.startSession(ctx, user)
 }
 
 func (s *DefaultAuthService) ValidateToken(ctx context.Context, accessToken string) (model.Token, bool, error) {
-	claims := &jwt.RegisteredClaims{}
-	parsedToken, err := jwt.ParseWithClaims(accessToken, claims, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %s", token.Method.Alg())
-		}
-		return []byte(s.jwtConfig.Secret), nil
-	})
+	claims, parsedToken, err := s.parseClaims(ctx, accessToken)
 	if err != nil {
 		if errors.Is(err, jwt.ErrTokenExpired) {
 			expiresAt := time.Time{}
@@ -110,12 +221,29 @@ func (s *DefaultAuthService) ValidateToken(ctx context.Context, accessToken stri
 	if claims.ExpiresAt != nil {
 		expiresAt = claims.ExpiresAt.Time
 	}
+	token := model.Token{
+		AccessToken:   accessToken,
+		UserID:        claims.Subject,
+		ExpiresAt:     expiresAt,
+		EmailVerified: claims.EmailVerified,
+		Roles:         claims.Roles,
+	}
+
+	revoked, err := s.isRevoked(ctx, claims)
+	if err != nil {
+		return model.Token{}, false, err
+	}
+	if revoked {
+		return token, false, nil
+	}
+
+	return token, true, nil
+}
 
-	return model.Token{
-		AccessToken: accessToken,
-		UserID:      claims.Subject,
-		ExpiresAt:   expiresAt,
-	}, true, nil
+func (s *DefaultAuthService) parseClaims(ctx context.Context, accessToken string) (*accessClaims, *jwt.Token, error) {
+	claims := &accessClaims{}
+	parsedToken, err := jwt.ParseWithClaims(accessToken, claims, s.keys.Keyfunc(ctx), jwt.WithValidMethods(signing.ValidMethods()))
+	return claims, parsedToken, err
 }
 
 func IsNotFound(err error) bool {
@@ -3,6 +3,10 @@ package service
 import (
 	"context"
 	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
 	"testing"
 	"time"
 
@@ -11,8 +15,10 @@ import (
 	"golang.org/x/crypto/bcrypt"
 
 	"github.com/Deevins/final-task-course-2-go-lang/auth/internal/config"
+	"github.com/Deevins/final-task-course-2-go-lang/auth/internal/mailer"
 	"github.com/Deevins/final-task-course-2-go-lang/auth/internal/model"
 	"github.com/Deevins/final-task-course-2-go-lang/auth/internal/repository"
+	"github.com/Deevins/final-task-course-2-go-lang/auth/internal/signing"
 	"github.com/Deevins/final-task-course-2-go-lang/auth/internal/storage"
 	"github.com/gojuno/minimock/v3"
 )
@@ -29,9 +35,16 @@ func TestAuthService(t *testing.T) {
 				ctrl := minimock.NewController(t)
 				repo := repository.NewAuthRepositoryMock(ctrl)
 
-				jwtConfig := config.JWTConfig{Secret: "test-secret", Expiry: time.Hour}
-				service := NewAuthService(repo, jwtConfig)
+				jwtConfig := testJWTConfig()
+				keyring := newTestKeyring(t, jwtConfig)
+				mail := &recordingMailer{}
+				service := NewAuthService(repo, jwtConfig, keyring, mail, testEmailConfig(), testTwoFactorConfig(), testLoginThrottleConfig(), newTestPasswordPolicy(t), testOIDCConfig())
 
+				var verification model.UserToken
+				repo.CreateUserTokenFn = func(ctx context.Context, token model.UserToken) error {
+					verification = token
+					return nil
+				}
 				repo.CreateUserFunc = func(ctx context.Context, user model.User) (model.User, error) {
 					if user.PasswordHash == "" || user.PasswordHash == "password123" {
 						t.Fatalf("expected password to be hashed")
@@ -49,6 +62,15 @@ func TestAuthService(t *testing.T) {
 				if user.ID == "" {
 					t.Fatal("expected user ID to be set")
 				}
+				if user.EmailVerified() {
+					t.Fatal("expected new user to be unverified")
+				}
+				if verification.UserID != user.ID || verification.Purpose != model.TokenPurposeEmailVerification {
+					t.Fatalf("expected verification token for the new user, got %+v", verification)
+				}
+				if len(mail.messages) != 1 || hashToken(mail.lastToken(t)) != verification.TokenHash {
+					t.Fatalf("expected verification email with the stored token, got %+v", mail.messages)
+				}
 			},
 		},
 		{
@@ -58,8 +80,9 @@ func TestAuthService(t *testing.T) {
 				ctrl := minimock.NewController(t)
 				t.Cleanup(ctrl.Finish)
 				repo := repository.NewAuthRepositoryMock(ctrl)
-				jwtConfig := config.JWTConfig{Secret: "test-secret", Expiry: time.Hour}
-				service := NewAuthService(repo, jwtConfig)
+				jwtConfig := testJWTConfig()
+				keyring := newTestKeyring(t, jwtConfig)
+				service := NewAuthService(repo, jwtConfig, keyring, &recordingMailer{}, testEmailConfig(), testTwoFactorConfig(), testLoginThrottleConfig(), newTestPasswordPolicy(t), testOIDCConfig())
 
 				passwordHash := mustHashPassword(t, "password123")
 				user := model.User{ID: uuid.NewString(), Email: "user@example.com", PasswordHash: passwordHash}
@@ -69,6 +92,24 @@ func TestAuthService(t *testing.T) {
 					}
 					return user, nil
 				}
+				repo.CreateRefreshTokenFn = func(ctx context.Context, token model.RefreshToken) error {
+					if token.UserID != user.ID {
+						t.Fatalf("expected refresh token for user %q, got %q", user.ID, token.UserID)
+					}
+					return nil
+				}
+				repo.ListLoginFailuresFn = func(ctx context.Context, keys []string, since time.Time) ([]model.LoginFailure, error) {
+					return nil, nil
+				}
+				repo.RecordLoginFailureFn = func(ctx context.Context, key string, at, windowStart time.Time) (model.LoginFailure, error) {
+					return model.LoginFailure{Key: key, Failures: 1, LastFailureAt: at}, nil
+				}
+				repo.ClearLoginFailuresFn = func(ctx context.Context, keys []string) (int64, error) {
+					if len(keys) != 1 || keys[0] != "account:user@example.com" {
+						t.Fatalf("expected account counter to be reset, got %v", keys)
+					}
+					return 0, nil
+				}
 
 				token, err := service.Login(ctx, user.Email, "password123")
 				if err != nil {
@@ -83,6 +124,9 @@ func TestAuthService(t *testing.T) {
 				if token.ExpiresAt.Before(time.Now().UTC()) {
 					t.Fatal("expected token expiry to be in the future")
 				}
+				if token.RefreshToken == "" {
+					t.Fatal("expected refresh token to be set")
+				}
 			},
 		},
 		{
@@ -91,18 +135,36 @@ func TestAuthService(t *testing.T) {
 				ctx := context.Background()
 				ctrl := minimock.NewController(t)
 				repo := repository.NewAuthRepositoryMock(ctrl)
-				jwtConfig := config.JWTConfig{Secret: "test-secret", Expiry: time.Hour}
-				service := NewAuthService(repo, jwtConfig)
+				jwtConfig := testJWTConfig()
+				keyring := newTestKeyring(t, jwtConfig)
+				service := NewAuthService(repo, jwtConfig, keyring, &recordingMailer{}, testEmailConfig(), testTwoFactorConfig(), testLoginThrottleConfig(), newTestPasswordPolicy(t), testOIDCConfig())
 
 				passwordHash := mustHashPassword(t, "password123")
 				user := model.User{ID: uuid.NewString(), Email: "user@example.com", PasswordHash: passwordHash}
 				repo.GetUserByEmailFn = func(ctx context.Context, email string) (model.User, error) {
+					if email != user.Email {
+						return model.User{}, storage.ErrNotFound
+					}
 					return user, nil
 				}
+				repo.ListLoginFailuresFn = func(ctx context.Context, keys []string, since time.Time) ([]model.LoginFailure, error) {
+					return nil, nil
+				}
+				var recorded []string
+				repo.RecordLoginFailureFn = func(ctx context.Context, key string, at, windowStart time.Time) (model.LoginFailure, error) {
+					recorded = append(recorded, key)
+					return model.LoginFailure{Key: key, Failures: 1, LastFailureAt: at}, nil
+				}
 
-				_, err := service.Login(ctx, user.Email, "wrong")
-				if !errors.Is(err, ErrInvalidCredentials) {
-					t.Fatalf("expected invalid credentials error, got %v", err)
+				// Unknown emails get the same answer as wrong passwords.
+				for _, email := range []string{user.Email, "unknown@example.com"} {
+					_, err := service.Login(ctx, email, "wrong")
+					if !errors.Is(err, ErrInvalidCredentials) {
+						t.Fatalf("expected invalid credentials error for %s, got %v", email, err)
+					}
+				}
+				if len(recorded) != 2 || recorded[0] != "account:user@example.com" || recorded[1] != "account:unknown@example.com" {
+					t.Fatalf("expected failures to be recorded per email, got %v", recorded)
 				}
 			},
 		},
@@ -113,11 +175,12 @@ func TestAuthService(t *testing.T) {
 				ctrl := minimock.NewController(t)
 				t.Cleanup(ctrl.Finish)
 				repo := repository.NewAuthRepositoryMock(ctrl)
-				jwtConfig := config.JWTConfig{Secret: "test-secret", Expiry: time.Hour}
-				service := NewAuthService(repo, jwtConfig)
+				jwtConfig := testJWTConfig()
+				keyring := newTestKeyring(t, jwtConfig)
+				service := NewAuthService(repo, jwtConfig, keyring, &recordingMailer{}, testEmailConfig(), testTwoFactorConfig(), testLoginThrottleConfig(), newTestPasswordPolicy(t), testOIDCConfig())
 
 				userID := uuid.NewString()
-				expiredToken := makeToken(t, jwtConfig.Secret, userID, time.Now().UTC().Add(-time.Minute))
+				expiredToken := makeToken(t, keyring, userID, time.Now().UTC().Add(-time.Minute))
 
 				token, ok, err := service.ValidateToken(ctx, expiredToken)
 
@@ -139,11 +202,15 @@ func TestAuthService(t *testing.T) {
 				ctx := context.Background()
 				ctrl := minimock.NewController(t)
 				repo := repository.NewAuthRepositoryMock(ctrl)
-				jwtConfig := config.JWTConfig{Secret: "test-secret", Expiry: time.Hour}
-				service := NewAuthService(repo, jwtConfig)
+				jwtConfig := testJWTConfig()
+				keyring := newTestKeyring(t, jwtConfig)
+				service := NewAuthService(repo, jwtConfig, keyring, &recordingMailer{}, testEmailConfig(), testTwoFactorConfig(), testLoginThrottleConfig(), newTestPasswordPolicy(t), testOIDCConfig())
 
 				userID := uuid.NewString()
-				validToken := makeToken(t, jwtConfig.Secret, userID, time.Now().UTC().Add(time.Minute))
+				validToken := makeToken(t, keyring, userID, time.Now().UTC().Add(time.Minute))
+				repo.IsAccessTokenRevokedFn = func(ctx context.Context, tokenID, userID string, issuedAt time.Time) (bool, error) {
+					return false, nil
+				}
 
 				token, ok, err := service.ValidateToken(ctx, validToken)
 				if err != nil {
@@ -167,21 +234,273 @@ func TestAuthService(t *testing.T) {
 	}
 }
 
-func makeToken(t *testing.T, secret, subject string, expiresAt time.Time) string {
+func TestAuthServiceKeyRotation(t *testing.T) {
+	ctx := context.Background()
+	ctrl := minimock.NewController(t)
+	repo := repository.NewAuthRepositoryMock(ctrl)
+	repo.IsAccessTokenRevokedFn = func(ctx context.Context, tokenID, userID string, issuedAt time.Time) (bool, error) {
+		return false, nil
+	}
+
+	jwtConfig := testJWTConfig()
+	store := &memoryKeyStore{}
+	keyring := signing.NewKeyring(store, jwtConfig)
+	if err := keyring.Rotate(ctx); err != nil {
+		t.Fatalf("rotate keys: %v", err)
+	}
+	service := NewAuthService(repo, jwtConfig, keyring, &recordingMailer{}, testEmailConfig(), testTwoFactorConfig(), testLoginThrottleConfig(), newTestPasswordPolicy(t), testOIDCConfig())
+
+	userID := uuid.NewString()
+	oldToken := makeToken(t, keyring, userID, time.Now().UTC().Add(time.Minute))
+	oldKeyID := store.keys[0].ID
+
+	// Retire the first key: the next rotation must create a new one while the
+	// old key stays published for its grace period.
+	store.keys[0].RetiresAt = time.Now().UTC().Add(-time.Minute)
+	if err := keyring.Rotate(ctx); err != nil {
+		t.Fatalf("rotate keys: %v", err)
+	}
+	if len(store.keys) != 2 {
+		t.Fatalf("expected 2 keys after rotation, got %d", len(store.keys))
+	}
+	newToken := makeToken(t, keyring, userID, time.Now().UTC().Add(time.Minute))
+
+	for name, token := range map[string]string{"old": oldToken, "new": newToken} {
+		if _, ok, err := service.ValidateToken(ctx, token); err != nil || !ok {
+			t.Fatalf("expected %s key token to be valid, ok=%v err=%v", name, ok, err)
+		}
+	}
+
+	jwks := keyring.JWKS()
+	if len(jwks.Keys) != 2 {
+		t.Fatalf("expected 2 published keys, got %d", len(jwks.Keys))
+	}
+	for _, key := range jwks.Keys {
+		if key.KeyType != "OKP" || key.Curve != "Ed25519" || key.X == "" {
+			t.Fatalf("unexpected JWK %+v", key)
+		}
+	}
+
+	// After the grace period the old key is neither accepted nor published.
+	store.keys[0].ExpiresAt = time.Now().UTC().Add(-time.Second)
+	if err := keyring.Load(ctx); err != nil {
+		t.Fatalf("load keys: %v", err)
+	}
+	if _, _, err := service.ValidateToken(ctx, oldToken); err == nil {
+		t.Fatal("expected token signed with an expired key to be rejected")
+	}
+	for _, key := range keyring.JWKS().Keys {
+		if key.KeyID == oldKeyID {
+			t.Fatal("expected expired key to be unpublished")
+		}
+	}
+
+	hmacToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{
+		ID:        uuid.NewString(),
+		Subject:   userID,
+		ExpiresAt: jwt.NewNumericDate(time.Now().UTC().Add(time.Minute)),
+	}).SignedString([]byte("shared-secret"))
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+	if _, ok, _ := service.ValidateToken(ctx, hmacToken); ok {
+		t.Fatal("expected HS256 token to be rejected")
+	}
+}
+
+func TestKeyringRS256(t *testing.T) {
+	jwtConfig := testJWTConfig()
+	jwtConfig.Algorithm = signing.AlgorithmRS256
+	keyring := newTestKeyring(t, jwtConfig)
+
+	token := makeToken(t, keyring, uuid.NewString(), time.Now().UTC().Add(time.Minute))
+	parsed, err := jwt.Parse(token, keyring.Keyfunc(context.Background()))
+	if err != nil || !parsed.Valid {
+		t.Fatalf("expected RS256 token to verify, err=%v", err)
+	}
+	if parsed.Method.Alg() != signing.AlgorithmRS256 {
+		t.Fatalf("expected RS256, got %s", parsed.Method.Alg())
+	}
+
+	jwks := keyring.JWKS()
+	if len(jwks.Keys) != 1 || jwks.Keys[0].KeyType != "RSA" || jwks.Keys[0].N == "" || jwks.Keys[0].E != "AQAB" {
+		t.Fatalf("unexpected JWKS %+v", jwks)
+	}
+}
+
+func makeToken(t *testing.T, keyring *signing.Keyring, subject string, expiresAt time.Time) string {
 	t.Helper()
-	claims := jwt.RegisteredClaims{
+	return signTestToken(t, keyring, jwt.RegisteredClaims{
+		ID:        uuid.NewString(),
 		Subject:   subject,
 		ExpiresAt: jwt.NewNumericDate(expiresAt),
 		IssuedAt:  jwt.NewNumericDate(time.Now().UTC()),
-	}
-	jwtToken := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	signed, err := jwtToken.SignedString([]byte(secret))
+	})
+}
+
+// makeTokenIssuedAt signs a token issued at the given time that belongs to no session.
+func makeTokenIssuedAt(t *testing.T, keyring *signing.Keyring, subject string, issuedAt time.Time) string {
+	t.Helper()
+	return signTestToken(t, keyring, jwt.RegisteredClaims{
+		ID:        uuid.NewString(),
+		Subject:   subject,
+		ExpiresAt: jwt.NewNumericDate(issuedAt.Add(time.Hour)),
+		IssuedAt:  jwt.NewNumericDate(issuedAt),
+	})
+}
+
+func makeTokenWithoutID(t *testing.T, keyring *signing.Keyring, subject string, expiresAt time.Time) string {
+	t.Helper()
+	return signTestToken(t, keyring, jwt.RegisteredClaims{
+		Subject:   subject,
+		ExpiresAt: jwt.NewNumericDate(expiresAt),
+		IssuedAt:  jwt.NewNumericDate(time.Now().UTC()),
+	})
+}
+
+func signTestToken(t *testing.T, keyring *signing.Keyring, claims jwt.RegisteredClaims) string {
+	t.Helper()
+	signed, err := keyring.Sign(claims)
 	if err != nil {
 		t.Fatalf("sign token: %v", err)
 	}
 	return signed
 }
 
+func testJWTConfig() config.JWTConfig {
+	return config.JWTConfig{
+		Expiry:         time.Hour,
+		RefreshExpiry:  24 * time.Hour,
+		Algorithm:      signing.AlgorithmEdDSA,
+		KeyRotation:    24 * time.Hour,
+		KeyGracePeriod: 2 * time.Hour,
+	}
+}
+
+// newTestKeyring returns a keyring with one active key kept in memory.
+func newTestKeyring(t *testing.T, jwtConfig config.JWTConfig) *signing.Keyring {
+	t.Helper()
+	keyring := signing.NewKeyring(&memoryKeyStore{}, jwtConfig)
+	if err := keyring.Rotate(context.Background()); err != nil {
+		t.Fatalf("rotate keys: %v", err)
+	}
+	return keyring
+}
+
+type memoryKeyStore struct {
+	keys []model.SigningKey
+}
+
+func (s *memoryKeyStore) ListSigningKeys(ctx context.Context) ([]model.SigningKey, error) {
+	keys := make([]model.SigningKey, 0, len(s.keys))
+	for i := len(s.keys) - 1; i >= 0; i-- {
+		if s.keys[i].ExpiresAt.After(time.Now().UTC()) {
+			keys = append(keys, s.keys[i])
+		}
+	}
+	return keys, nil
+}
+
+func (s *memoryKeyStore) CreateSigningKey(ctx context.Context, key model.SigningKey) error {
+	s.keys = append(s.keys, key)
+	return nil
+}
+
+func (s *memoryKeyStore) DeleteExpiredSigningKeys(ctx context.Context, before time.Time) (int64, error) {
+	var deleted int64
+	kept := s.keys[:0]
+	for _, key := range s.keys {
+		if key.ExpiresAt.Before(before) {
+			deleted++
+			continue
+		}
+		kept = append(kept, key)
+	}
+	s.keys = kept
+	return deleted, nil
+}
+
+func testEmailConfig() config.EmailConfig {
+	return config.EmailConfig{
+		Mailer:           config.MailerLog,
+		BaseURL:          "http://localhost:8081",
+		VerificationTTL:  time.Hour,
+		PasswordResetTTL: time.Hour,
+	}
+}
+
+func testTwoFactorConfig() config.TwoFactorConfig {
+	return config.TwoFactorConfig{
+		Issuer:       "Ledger",
+		Skew:         1,
+		ChallengeTTL: time.Minute,
+	}
+}
+
+// testLoginThrottleConfig effectively disables the backoff; the lockout
+// still applies after the max failures.
+func testLoginThrottleConfig() config.LoginThrottleConfig {
+	return config.LoginThrottleConfig{
+		AccountMaxFailures: 5,
+		IPMaxFailures:      20,
+		BackoffBase:        time.Nanosecond,
+		BackoffMax:         time.Nanosecond,
+		Lockout:            time.Hour,
+		FailureWindow:      time.Hour,
+	}
+}
+
+func testOIDCConfig() config.OIDCConfig {
+	return config.OIDCConfig{LoginTTL: time.Minute}
+}
+
+// newTestPasswordPolicy writes the breached passwords, if any, to a temporary
+// list file.
+func newTestPasswordPolicy(t *testing.T, breached ...string) *PasswordPolicy {
+	t.Helper()
+	cfg := config.PasswordPolicyConfig{
+		MinLength:      8,
+		MaxBytes:       config.MaxPasswordBytes,
+		MinCharClasses: 2,
+	}
+	if len(breached) > 0 {
+		cfg.BreachedListPath = filepath.Join(t.TempDir(), "breached.txt")
+		if err := os.WriteFile(cfg.BreachedListPath, []byte(strings.Join(breached, "\n")), 0o600); err != nil {
+			t.Fatalf("write breached list: %v", err)
+		}
+	}
+	policy, err := NewPasswordPolicy(cfg)
+	if err != nil {
+		t.Fatalf("new password policy: %v", err)
+	}
+	return policy
+}
+
+var emailTokenPattern = regexp.MustCompile(`token=([A-Za-z0-9_-]+)`)
+
+// recordingMailer keeps sent messages in memory.
+type recordingMailer struct {
+	messages []mailer.Message
+}
+
+func (m *recordingMailer) Send(ctx context.Context, msg mailer.Message) error {
+	m.messages = append(m.messages, msg)
+	return nil
+}
+
+// lastToken returns the token from the link in the last sent message.
+func (m *recordingMailer) lastToken(t *testing.T) string {
+	t.Helper()
+	if len(m.messages) == 0 {
+		t.Fatal("expected an email to be sent")
+	}
+	match := emailTokenPattern.FindStringSubmatch(m.messages[len(m.messages)-1].Body)
+	if match == nil {
+		t.Fatalf("expected a token link in %q", m.messages[len(m.messages)-1].Body)
+	}
+	return match[1]
+}
+
 func mustHashPassword(t *testing.T, value string) string {
 	t.Helper()
 	hash, err := bcrypt.GenerateFromPassword([]byte(value), bcrypt.DefaultCost)
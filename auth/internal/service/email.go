@@ -0,0 +1,176 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/Deevins/final-task-course-2-go-lang/auth/internal/mailer"
+	"github.com/Deevins/final-task-course-2-go-lang/auth/internal/model"
+)
+
+const userTokenBytes = 32
+
+// RequestEmailVerification sends a new verification link to the token owner.
+// Links sent earlier stop working.
+func (s *DefaultAuthService) RequestEmailVerification(ctx context.Context, accessToken string) error {
+	user, err := s.GetMe(ctx, accessToken)
+	if err != nil {
+		return err
+	}
+	if user.EmailVerified() {
+		return ErrEmailAlreadyVerified
+	}
+	return s.sendVerificationEmail(ctx, user)
+}
+
+// ConfirmEmail marks the email address of the token owner as verified. Access
+// tokens issued before carry the old state until they are refreshed.
+func (s *DefaultAuthService) ConfirmEmail(ctx context.Context, token string) error {
+	stored, err := s.consumeEmailToken(ctx, model.TokenPurposeEmailVerification, token)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.MarkEmailVerified(ctx, stored.UserID, time.Now().UTC()); err != nil {
+		if IsNotFound(err) {
+			return ErrInvalidEmailToken
+		}
+		return err
+	}
+	return nil
+}
+
+// RequestPasswordReset emails a password reset link if an account with the
+// address exists. Unknown addresses are not reported, so the endpoint cannot
+// be used to find out which emails are registered.
+func (s *DefaultAuthService) RequestPasswordReset(ctx context.Context, email string) error {
+	user, err := s.repo.GetUserByEmail(ctx, email)
+	if err != nil {
+		if IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	token, err := s.createUserToken(ctx, user.ID, model.TokenPurposePasswordReset, s.emailConfig.PasswordResetTTL)
+	if err != nil {
+		return err
+	}
+	return s.mailer.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf(
+			"A password reset was requested for your account.\n\nOpen the link below to choose a new password:\n%s\n\nThe link expires in %s. If you did not request a reset, ignore this email.\n",
+			s.emailLink("/reset-password", token),
+			s.emailConfig.PasswordResetTTL,
+		),
+	})
+}
+
+// ResetPassword sets a new password using an emailed reset token. All sessions
+// of the user are revoked. Following the link proves access to the mailbox,
+// so the email address is marked as verified as well. A password rejected by
+// the policy leaves the token usable for another try.
+func (s *DefaultAuthService) ResetPassword(ctx context.Context, token, newPassword string) error {
+	pending, err := s.repo.GetUserToken(ctx, model.TokenPurposePasswordReset, hashToken(token), time.Now().UTC())
+	if err != nil {
+		if IsNotFound(err) {
+			return ErrInvalidEmailToken
+		}
+		return err
+	}
+	user, err := s.repo.GetUserByID(ctx, pending.UserID)
+	if err != nil {
+		if IsNotFound(err) {
+			return ErrInvalidEmailToken
+		}
+		return err
+	}
+	if err := s.passwordPolicy.Check(newPassword, user.Email, user.Name); err != nil {
+		return err
+	}
+	if _, err := s.consumeEmailToken(ctx, model.TokenPurposePasswordReset, token); err != nil {
+		return err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	user.PasswordHash = string(hashedPassword)
+	user.UpdatedAt = now
+	if _, err := s.repo.UpdateUser(ctx, user); err != nil {
+		return err
+	}
+	if !user.EmailVerified() {
+		if err := s.repo.MarkEmailVerified(ctx, user.ID, now); err != nil {
+			return err
+		}
+	}
+
+	_, err = s.revokeUserSessions(ctx, user.ID)
+	return err
+}
+
+func (s *DefaultAuthService) sendVerificationEmail(ctx context.Context, user model.User) error {
+	token, err := s.createUserToken(ctx, user.ID, model.TokenPurposeEmailVerification, s.emailConfig.VerificationTTL)
+	if err != nil {
+		return err
+	}
+	return s.mailer.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Confirm your email address",
+		Body: fmt.Sprintf(
+			"Open the link below to confirm your email address:\n%s\n\nThe link expires in %s.\n",
+			s.emailLink("/verify-email", token),
+			s.emailConfig.VerificationTTL,
+		),
+	})
+}
+
+// createUserToken stores a new single-use token and returns its value. It is
+// used for emailed links and for sign-in challenges.
+func (s *DefaultAuthService) createUserToken(ctx context.Context, userID, purpose string, ttl time.Duration) (string, error) {
+	value := make([]byte, userTokenBytes)
+	if _, err := rand.Read(value); err != nil {
+		return "", fmt.Errorf("generate user token: %w", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(value)
+
+	now := time.Now().UTC()
+	err := s.repo.CreateUserToken(ctx, model.UserToken{
+		ID:        uuid.NewString(),
+		UserID:    userID,
+		Purpose:   purpose,
+		TokenHash: hashToken(token),
+		ExpiresAt: now.Add(ttl),
+		CreatedAt: now,
+	})
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+func (s *DefaultAuthService) consumeEmailToken(ctx context.Context, purpose, token string) (model.UserToken, error) {
+	stored, err := s.repo.ConsumeUserToken(ctx, purpose, hashToken(token), time.Now().UTC())
+	if err != nil {
+		if IsNotFound(err) {
+			return model.UserToken{}, ErrInvalidEmailToken
+		}
+		return model.UserToken{}, err
+	}
+	return stored, nil
+}
+
+func (s *DefaultAuthService) emailLink(path, token string) string {
+	return strings.TrimRight(s.emailConfig.BaseURL, "/") + path + "?token=" + url.QueryEscape(token)
+}
@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gojuno/minimock/v3"
+
+	"github.com/Deevins/final-task-course-2-go-lang/auth/internal/repository"
+)
+
+func TestAuthServiceEmailFlows(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T, service *DefaultAuthService, store *sessionStore)
+	}{
+		{
+			name: "email verification is single use and reflected in new tokens",
+			run: func(t *testing.T, service *DefaultAuthService, store *sessionStore) {
+				ctx := context.Background()
+				first, err := service.Login(ctx, store.user.Email, "password123")
+				if err != nil {
+					t.Fatalf("login: %v", err)
+				}
+				if first.EmailVerified {
+					t.Fatal("expected token of an unverified user")
+				}
+
+				if err := service.RequestEmailVerification(ctx, first.AccessToken); err != nil {
+					t.Fatalf("request verification: %v", err)
+				}
+				stale := store.mail.lastToken(t)
+				if err := service.RequestEmailVerification(ctx, first.AccessToken); err != nil {
+					t.Fatalf("request verification: %v", err)
+				}
+				token := store.mail.lastToken(t)
+
+				if err := service.ConfirmEmail(ctx, stale); !errors.Is(err, ErrInvalidEmailToken) {
+					t.Fatalf("expected superseded token to be rejected, got %v", err)
+				}
+				if err := service.ConfirmEmail(ctx, token); err != nil {
+					t.Fatalf("confirm email: %v", err)
+				}
+				if err := service.ConfirmEmail(ctx, token); !errors.Is(err, ErrInvalidEmailToken) {
+					t.Fatalf("expected used token to be rejected, got %v", err)
+				}
+				if !store.user.EmailVerified() {
+					t.Fatal("expected email to be verified")
+				}
+
+				refreshed, err := service.Refresh(ctx, first.RefreshToken)
+				if err != nil {
+					t.Fatalf("refresh: %v", err)
+				}
+				validated, ok, err := service.ValidateToken(ctx, refreshed.AccessToken)
+				if err != nil || !ok || !validated.EmailVerified {
+					t.Fatalf("expected refreshed token to carry verified email, token=%+v ok=%v err=%v", validated, ok, err)
+				}
+				if err := service.RequestEmailVerification(ctx, refreshed.AccessToken); !errors.Is(err, ErrEmailAlreadyVerified) {
+					t.Fatalf("expected already verified error, got %v", err)
+				}
+			},
+		},
+		{
+			name: "expired verification token is rejected",
+			run: func(t *testing.T, service *DefaultAuthService, store *sessionStore) {
+				ctx := context.Background()
+				first, err := service.Login(ctx, store.user.Email, "password123")
+				if err != nil {
+					t.Fatalf("login: %v", err)
+				}
+				if err := service.RequestEmailVerification(ctx, first.AccessToken); err != nil {
+					t.Fatalf("request verification: %v", err)
+				}
+				token := store.mail.lastToken(t)
+				stored := store.emailTokens[hashToken(token)]
+				stored.ExpiresAt = time.Now().UTC().Add(-time.Minute)
+				store.emailTokens[stored.TokenHash] = stored
+
+				if err := service.ConfirmEmail(ctx, token); !errors.Is(err, ErrInvalidEmailToken) {
+					t.Fatalf("expected expired token to be rejected, got %v", err)
+				}
+			},
+		},
+		{
+			name: "password reset replaces password and revokes sessions",
+			run: func(t *testing.T, service *DefaultAuthService, store *sessionStore) {
+				ctx := context.Background()
+				session, err := service.Login(ctx, store.user.Email, "password123")
+				if err != nil {
+					t.Fatalf("login: %v", err)
+				}
+
+				if err := service.RequestPasswordReset(ctx, "unknown@example.com"); err != nil {
+					t.Fatalf("expected unknown email to be accepted silently, got %v", err)
+				}
+				if len(store.mail.messages) != 0 {
+					t.Fatal("expected no email for an unknown address")
+				}
+
+				if err := service.RequestPasswordReset(ctx, store.user.Email); err != nil {
+					t.Fatalf("request reset: %v", err)
+				}
+				token := store.mail.lastToken(t)
+				if err := service.ConfirmEmail(ctx, token); !errors.Is(err, ErrInvalidEmailToken) {
+					t.Fatalf("expected reset token to be rejected for verification, got %v", err)
+				}
+				if err := service.ResetPassword(ctx, token, "new-password"); err != nil {
+					t.Fatalf("reset password: %v", err)
+				}
+				if err := service.ResetPassword(ctx, token, "other-password"); !errors.Is(err, ErrInvalidEmailToken) {
+					t.Fatalf("expected used reset token to be rejected, got %v", err)
+				}
+
+				if _, err := service.Refresh(ctx, session.RefreshToken); !errors.Is(err, ErrInvalidRefreshToken) {
+					t.Fatalf("expected existing session to be revoked, got %v", err)
+				}
+				if _, err := service.Login(ctx, store.user.Email, "password123"); !errors.Is(err, ErrInvalidCredentials) {
+					t.Fatalf("expected old password to be rejected, got %v", err)
+				}
+				token2, err := service.Login(ctx, store.user.Email, "new-password")
+				if err != nil {
+					t.Fatalf("login with new password: %v", err)
+				}
+				if !token2.EmailVerified {
+					t.Fatal("expected password reset to verify the email address")
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := minimock.NewController(t)
+			repo := repository.NewAuthRepositoryMock(ctrl)
+			store := newSessionStore(t, repo)
+			jwtConfig := testJWTConfig()
+			tt.run(t, NewAuthService(repo, jwtConfig, newTestKeyring(t, jwtConfig), store.mail, testEmailConfig(), testTwoFactorConfig(), testLoginThrottleConfig(), newTestPasswordPolicy(t), testOIDCConfig()), store)
+		})
+	}
+}
@@ -0,0 +1,183 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/Deevins/final-task-course-2-go-lang/auth/internal/clientip"
+	"github.com/Deevins/final-task-course-2-go-lang/auth/internal/model"
+)
+
+// ErrTooManyAttempts is wrapped by LoginThrottledError.
+var ErrTooManyAttempts = errors.New("too many failed sign-in attempts")
+
+// LoginThrottledError is returned while an account or a client address has to
+// wait after failed sign-in attempts. The attempt is rejected before the
+// password is checked.
+type LoginThrottledError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LoginThrottledError) Error() string {
+	return ErrTooManyAttempts.Error()
+}
+
+func (e *LoginThrottledError) Unwrap() error {
+	return ErrTooManyAttempts
+}
+
+var (
+	dummyHashOnce sync.Once
+	dummyHash     []byte
+)
+
+// UnlockAccount resets, on behalf of the admin owning the access token, the
+// failed sign-in attempts of an email address and, if given, of a client
+// address. It returns whether any counter existed.
+func (s *DefaultAuthService) UnlockAccount(ctx context.Context, accessToken, email, ip string) (bool, error) {
+	caller, err := s.GetMe(ctx, accessToken)
+	if err != nil {
+		return false, err
+	}
+	if !caller.HasRole(model.RoleAdmin) {
+		return false, ErrAdminRequired
+	}
+
+	keys := []string{accountThrottleKey(email)}
+	if ip != "" {
+		keys = append(keys, ipThrottleKey(ip))
+	}
+	cleared, err := s.repo.ClearLoginFailures(ctx, keys)
+	if err != nil {
+		return false, err
+	}
+	return cleared > 0, nil
+}
+
+// loginThrottleKeys returns the counters a sign-in attempt for the email
+// counts against. Unknown emails are tracked the same way as registered ones,
+// so the responses do not reveal which accounts exist.
+func loginThrottleKeys(ctx context.Context, email string) []string {
+	keys := []string{accountThrottleKey(email)}
+	if ip := clientip.FromContext(ctx); ip != "" {
+		keys = append(keys, ipThrottleKey(ip))
+	}
+	return keys
+}
+
+// beginLoginAttempt returns a LoginThrottledError if any of the keys has to
+// wait before the next attempt. Otherwise it counts the attempt as a failure
+// against every key before the credentials are checked, and rejects it if that
+// takes a key past its max failures. Counters are incremented atomically, so
+// concurrent attempts cannot all pass the check before any of them is counted.
+// Attempts that turn out right are given back by finishLoginAttempt.
+func (s *DefaultAuthService) beginLoginAttempt(ctx context.Context, keys []string) error {
+	if err := s.checkLoginThrottle(ctx, keys); err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	for _, key := range keys {
+		failure, err := s.repo.RecordLoginFailure(ctx, key, now, now.Add(-s.loginConfig.FailureWindow))
+		if err != nil {
+			return err
+		}
+		if failure.Failures > s.maxFailures(key) {
+			return &LoginThrottledError{RetryAfter: s.loginConfig.Lockout}
+		}
+	}
+	return nil
+}
+
+// checkLoginThrottle returns a LoginThrottledError if any of the keys has to
+// wait before the next attempt.
+func (s *DefaultAuthService) checkLoginThrottle(ctx context.Context, keys []string) error {
+	now := time.Now().UTC()
+	failures, err := s.repo.ListLoginFailures(ctx, keys, now.Add(-s.loginConfig.FailureWindow))
+	if err != nil {
+		return err
+	}
+
+	var retryAfter time.Duration
+	for _, failure := range failures {
+		if failure.Failures == 0 {
+			continue
+		}
+		wait := failure.LastFailureAt.Add(s.loginDelay(failure)).Sub(now)
+		if wait > retryAfter {
+			retryAfter = wait
+		}
+	}
+	if retryAfter > 0 {
+		return &LoginThrottledError{RetryAfter: retryAfter}
+	}
+	return nil
+}
+
+// finishLoginAttempt gives back an attempt counted by beginLoginAttempt whose
+// credentials were right. With clearAccount the account counter is reset, as
+// after a successful sign-in. The address counter only loses this attempt,
+// otherwise signing in to an own account would let a client continue guessing
+// passwords of others.
+func (s *DefaultAuthService) finishLoginAttempt(ctx context.Context, keys []string, clearAccount bool) error {
+	for _, key := range keys {
+		if clearAccount && strings.HasPrefix(key, accountThrottlePrefix) {
+			if _, err := s.repo.ClearLoginFailures(ctx, []string{key}); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := s.repo.ReleaseLoginFailure(ctx, key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loginDelay is how long after the last failure the next attempt is allowed.
+func (s *DefaultAuthService) loginDelay(failure model.LoginFailure) time.Duration {
+	if failure.Failures >= s.maxFailures(failure.Key) {
+		return s.loginConfig.Lockout
+	}
+
+	delay := s.loginConfig.BackoffBase
+	for i := 1; i < failure.Failures && delay < s.loginConfig.BackoffMax; i++ {
+		delay *= 2
+	}
+	return min(delay, s.loginConfig.BackoffMax)
+}
+
+// maxFailures is the number of failures that locks the key.
+func (s *DefaultAuthService) maxFailures(key string) int {
+	if strings.HasPrefix(key, ipThrottlePrefix) {
+		return s.loginConfig.IPMaxFailures
+	}
+	return s.loginConfig.AccountMaxFailures
+}
+
+// compareDummyPassword spends as much time as checking a real password, so
+// unknown emails cannot be told apart by the response time.
+func compareDummyPassword(password string) {
+	dummyHashOnce.Do(func() {
+		dummyHash, _ = bcrypt.GenerateFromPassword([]byte("dummy password"), bcrypt.DefaultCost)
+	})
+	_ = bcrypt.CompareHashAndPassword(dummyHash, []byte(password))
+}
+
+const (
+	accountThrottlePrefix = "account:"
+	ipThrottlePrefix      = "ip:"
+)
+
+func accountThrottleKey(email string) string {
+	return accountThrottlePrefix + strings.ToLower(strings.TrimSpace(email))
+}
+
+func ipThrottleKey(ip string) string {
+	return ipThrottlePrefix + ip
+}
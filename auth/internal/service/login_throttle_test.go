@@ -0,0 +1,197 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gojuno/minimock/v3"
+
+	"github.com/Deevins/final-task-course-2-go-lang/auth/internal/clientip"
+	"github.com/Deevins/final-task-course-2-go-lang/auth/internal/config"
+	"github.com/Deevins/final-task-course-2-go-lang/auth/internal/model"
+	"github.com/Deevins/final-task-course-2-go-lang/auth/internal/repository"
+)
+
+func TestAuthServiceLoginThrottle(t *testing.T) {
+	tests := []struct {
+		name   string
+		config func(cfg *config.LoginThrottleConfig)
+		run    func(t *testing.T, service *DefaultAuthService, store *sessionStore)
+	}{
+		{
+			name: "failures delay the next attempt for known and unknown emails",
+			config: func(cfg *config.LoginThrottleConfig) {
+				cfg.BackoffBase = time.Minute
+				cfg.BackoffMax = time.Minute
+			},
+			run: func(t *testing.T, service *DefaultAuthService, store *sessionStore) {
+				ctx := context.Background()
+				for _, email := range []string{store.user.Email, "unknown@example.com"} {
+					if _, err := service.Login(ctx, email, "wrong"); !errors.Is(err, ErrInvalidCredentials) {
+						t.Fatalf("expected invalid credentials for %s, got %v", email, err)
+					}
+					_, err := service.Login(ctx, email, "password123")
+					var throttled *LoginThrottledError
+					if !errors.As(err, &throttled) || !errors.Is(err, ErrTooManyAttempts) {
+						t.Fatalf("expected throttled attempt for %s, got %v", email, err)
+					}
+					if throttled.RetryAfter <= 0 || throttled.RetryAfter > time.Minute {
+						t.Fatalf("expected retry within the backoff, got %s", throttled.RetryAfter)
+					}
+				}
+			},
+		},
+		{
+			name: "account is locked after max failures until an admin unlocks it",
+			run: func(t *testing.T, service *DefaultAuthService, store *sessionStore) {
+				ctx := context.Background()
+				admin := mustLogin(t, service, store)
+				for range service.loginConfig.AccountMaxFailures {
+					if _, err := service.Login(ctx, store.user.Email, "wrong"); !errors.Is(err, ErrInvalidCredentials) {
+						t.Fatalf("expected invalid credentials, got %v", err)
+					}
+				}
+				_, err := service.Login(ctx, store.user.Email, "password123")
+				var throttled *LoginThrottledError
+				if !errors.As(err, &throttled) || throttled.RetryAfter < 59*time.Minute {
+					t.Fatalf("expected lockout, got %v", err)
+				}
+
+				if _, err := service.UnlockAccount(ctx, admin.AccessToken, "USER@example.com", ""); !errors.Is(err, ErrAdminRequired) {
+					t.Fatalf("expected ErrAdminRequired, got %v", err)
+				}
+				store.user.Roles = []string{model.RoleUser, model.RoleAdmin}
+				unlocked, err := service.UnlockAccount(ctx, admin.AccessToken, "USER@example.com", "")
+				if err != nil || !unlocked {
+					t.Fatalf("expected account to be unlocked, unlocked=%v err=%v", unlocked, err)
+				}
+				if _, err := service.Login(ctx, store.user.Email, "password123"); err != nil {
+					t.Fatalf("login after unlock: %v", err)
+				}
+			},
+		},
+		{
+			name: "successful login resets the account but not the address",
+			run: func(t *testing.T, service *DefaultAuthService, store *sessionStore) {
+				ctx := clientip.WithIP(context.Background(), "203.0.113.7")
+				if _, err := service.Login(ctx, store.user.Email, "wrong"); !errors.Is(err, ErrInvalidCredentials) {
+					t.Fatalf("expected invalid credentials, got %v", err)
+				}
+				if _, err := service.Login(ctx, store.user.Email, "password123"); err != nil {
+					t.Fatalf("login: %v", err)
+				}
+				if _, ok := store.loginFailures[accountThrottleKey(store.user.Email)]; ok {
+					t.Fatal("expected account failures to be reset")
+				}
+				if store.loginFailures[ipThrottleKey("203.0.113.7")].Failures != 1 {
+					t.Fatalf("expected address failures to be kept, got %+v", store.loginFailures)
+				}
+			},
+		},
+		{
+			name: "address is locked across accounts",
+			config: func(cfg *config.LoginThrottleConfig) {
+				cfg.IPMaxFailures = 3
+			},
+			run: func(t *testing.T, service *DefaultAuthService, store *sessionStore) {
+				store.user.Roles = []string{model.RoleUser, model.RoleAdmin}
+				admin := mustLogin(t, service, store)
+				ctx := clientip.WithIP(context.Background(), "203.0.113.7")
+				for _, email := range []string{"a@example.com", "b@example.com", "c@example.com"} {
+					if _, err := service.Login(ctx, email, "wrong"); !errors.Is(err, ErrInvalidCredentials) {
+						t.Fatalf("expected invalid credentials, got %v", err)
+					}
+				}
+				if _, err := service.Login(ctx, store.user.Email, "password123"); !errors.Is(err, ErrTooManyAttempts) {
+					t.Fatalf("expected address to be locked, got %v", err)
+				}
+				if _, err := service.Login(context.Background(), store.user.Email, "password123"); err != nil {
+					t.Fatalf("expected other addresses to sign in, got %v", err)
+				}
+
+				if _, err := service.UnlockAccount(ctx, admin.AccessToken, "a@example.com", "203.0.113.7"); err != nil {
+					t.Fatalf("unlock: %v", err)
+				}
+				if _, err := service.Login(ctx, store.user.Email, "password123"); err != nil {
+					t.Fatalf("login after unlock: %v", err)
+				}
+			},
+		},
+		{
+			name: "wrong two-factor codes count as failures",
+			config: func(cfg *config.LoginThrottleConfig) {
+				cfg.BackoffBase = time.Minute
+				cfg.BackoffMax = time.Minute
+			},
+			run: func(t *testing.T, service *DefaultAuthService, store *sessionStore) {
+				ctx := context.Background()
+				secret, step, _ := enableTwoFactor(t, service, store)
+				challenge, err := service.Login(ctx, store.user.Email, "password123")
+				if err != nil {
+					t.Fatalf("login: %v", err)
+				}
+				if _, err := service.VerifyTwoFactor(ctx, challenge.ChallengeToken, codeAt(t, secret, step-5)); !errors.Is(err, ErrInvalidTwoFactorCode) {
+					t.Fatalf("expected invalid code, got %v", err)
+				}
+				if _, err := service.Login(ctx, store.user.Email, "password123"); !errors.Is(err, ErrTooManyAttempts) {
+					t.Fatalf("expected throttled attempt after a wrong code, got %v", err)
+				}
+			},
+		},
+		{
+			name: "concurrent failures cannot exceed the lockout",
+			run: func(t *testing.T, service *DefaultAuthService, store *sessionStore) {
+				ctx := context.Background()
+				var (
+					wg        sync.WaitGroup
+					mu        sync.Mutex
+					invalid   int
+					throttled int
+				)
+				for range 4 * service.loginConfig.AccountMaxFailures {
+					wg.Add(1)
+					go func() {
+						defer wg.Done()
+						_, err := service.Login(ctx, store.user.Email, "wrong")
+						mu.Lock()
+						defer mu.Unlock()
+						switch {
+						case errors.Is(err, ErrInvalidCredentials):
+							invalid++
+						case errors.Is(err, ErrTooManyAttempts):
+							throttled++
+						default:
+							t.Errorf("unexpected error %v", err)
+						}
+					}()
+				}
+				wg.Wait()
+
+				if invalid != service.loginConfig.AccountMaxFailures {
+					t.Fatalf("expected %d passwords to be checked, got %d (%d throttled)", service.loginConfig.AccountMaxFailures, invalid, throttled)
+				}
+				if _, err := service.Login(ctx, store.user.Email, "password123"); !errors.Is(err, ErrTooManyAttempts) {
+					t.Fatalf("expected the account to stay locked, got %v", err)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := minimock.NewController(t)
+			repo := repository.NewAuthRepositoryMock(ctrl)
+			store := newSessionStore(t, repo)
+			jwtConfig := testJWTConfig()
+			loginConfig := testLoginThrottleConfig()
+			if tt.config != nil {
+				tt.config(&loginConfig)
+			}
+			service := NewAuthService(repo, jwtConfig, newTestKeyring(t, jwtConfig), store.mail, testEmailConfig(), testTwoFactorConfig(), loginConfig, newTestPasswordPolicy(t), testOIDCConfig())
+			tt.run(t, service, store)
+		})
+	}
+}
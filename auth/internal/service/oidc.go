@@ -0,0 +1,206 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Deevins/final-task-course-2-go-lang/auth/internal/config"
+	"github.com/Deevins/final-task-course-2-go-lang/auth/internal/model"
+	"github.com/Deevins/final-task-course-2-go-lang/auth/internal/oidc"
+)
+
+var (
+	ErrUnknownIdentityProvider = errors.New("unknown identity provider")
+	// ErrInvalidOIDCState is returned for unknown, expired or already used
+	// external sign-in states.
+	ErrInvalidOIDCState = errors.New("invalid or expired sign-in state")
+	// ErrExternalLoginFailed is returned when the provider rejects the
+	// authorization code or returns an invalid ID token.
+	ErrExternalLoginFailed = errors.New("external sign-in failed")
+	// ErrExternalEmailNotVerified is returned for a new identity whose email
+	// address the provider has not verified, since accounts are linked by it.
+	ErrExternalEmailNotVerified = errors.New("identity provider did not verify the email address")
+)
+
+func newIdentityProviders(cfg config.OIDCConfig) map[string]*oidc.Provider {
+	providers := make(map[string]*oidc.Provider, len(cfg.Providers))
+	for _, providerConfig := range cfg.Providers {
+		providers[providerConfig.Name] = oidc.NewProvider(providerConfig, nil)
+	}
+	return providers
+}
+
+// IdentityProviders returns the names of the configured external identity
+// providers in alphabetical order.
+func (s *DefaultAuthService) IdentityProviders() []string {
+	names := make([]string, 0, len(s.identityProviders))
+	for name := range s.identityProviders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// StartOIDCLogin begins an authorization code flow with PKCE at the provider.
+// The state, nonce and code verifier are kept until the callback.
+func (s *DefaultAuthService) StartOIDCLogin(ctx context.Context, providerName string) (model.OIDCAuthorization, error) {
+	provider, ok := s.identityProviders[providerName]
+	if !ok {
+		return model.OIDCAuthorization{}, ErrUnknownIdentityProvider
+	}
+
+	var values [3]string
+	for i := range values {
+		value, err := oidc.RandomString()
+		if err != nil {
+			return model.OIDCAuthorization{}, err
+		}
+		values[i] = value
+	}
+	state, nonce, codeVerifier := values[0], values[1], values[2]
+
+	authURL, err := provider.AuthCodeURL(ctx, state, nonce, codeVerifier)
+	if err != nil {
+		return model.OIDCAuthorization{}, err
+	}
+
+	now := time.Now().UTC()
+	login := model.OIDCLogin{
+		StateHash:    hashToken(state),
+		Provider:     providerName,
+		Nonce:        nonce,
+		CodeVerifier: codeVerifier,
+		CreatedAt:    now,
+		ExpiresAt:    now.Add(s.oidcConfig.LoginTTL),
+	}
+	if err := s.repo.CreateOIDCLogin(ctx, login); err != nil {
+		return model.OIDCAuthorization{}, err
+	}
+
+	return model.OIDCAuthorization{URL: authURL, State: state, ExpiresAt: login.ExpiresAt}, nil
+}
+
+// CompleteOIDCLogin redeems the authorization code of a callback and signs in
+// the user of the external identity. A new identity is linked to the user
+// with the same verified email address, or to a new user without a password.
+// As with Login, users with two-factor authentication get a challenge.
+func (s *DefaultAuthService) CompleteOIDCLogin(ctx context.Context, providerName, state, code string) (model.Token, error) {
+	provider, ok := s.identityProviders[providerName]
+	if !ok {
+		return model.Token{}, ErrUnknownIdentityProvider
+	}
+	login, err := s.repo.ConsumeOIDCLogin(ctx, providerName, hashToken(state), time.Now().UTC())
+	if err != nil {
+		if IsNotFound(err) {
+			return model.Token{}, ErrInvalidOIDCState
+		}
+		return model.Token{}, err
+	}
+
+	claims, err := provider.Exchange(ctx, code, login.CodeVerifier, login.Nonce)
+	if err != nil {
+		if errors.Is(err, oidc.ErrProviderUnavailable) {
+			return model.Token{}, err
+		}
+		return model.Token{}, fmt.Errorf("%w: %v", ErrExternalLoginFailed, err)
+	}
+
+	user, err := s.userForIdentity(ctx, providerName, claims)
+	if err != nil {
+		return model.Token{}, err
+	}
+	if user.TwoFactorEnabled() {
+		return s.startChallenge(ctx, user)
+	}
+	return s.startSession(ctx, user)
+}
+
+// userForIdentity returns the user linked to the identity, linking or
+// creating one on the first sign-in.
+func (s *DefaultAuthService) userForIdentity(ctx context.Context, providerName string, claims oidc.Claims) (model.User, error) {
+	now := time.Now().UTC()
+	identity, err := s.repo.GetIdentity(ctx, providerName, claims.Subject)
+	if err == nil {
+		if err := s.repo.UpdateIdentityLogin(ctx, identity.ID, claims.Email, now); err != nil {
+			return model.User{}, err
+		}
+		return s.repo.GetUserByID(ctx, identity.UserID)
+	}
+	if !IsNotFound(err) {
+		return model.User{}, err
+	}
+
+	if claims.Email == "" || !claims.EmailVerified {
+		return model.User{}, ErrExternalEmailNotVerified
+	}
+	identity = model.Identity{
+		ID:          uuid.NewString(),
+		Provider:    providerName,
+		Subject:     claims.Subject,
+		Email:       claims.Email,
+		CreatedAt:   now,
+		LastLoginAt: now,
+	}
+
+	user, err := s.repo.GetUserByEmail(ctx, claims.Email)
+	if err == nil {
+		identity.UserID = user.ID
+		return s.linkIdentity(ctx, user, identity)
+	}
+	if !IsNotFound(err) {
+		return model.User{}, err
+	}
+
+	user = model.User{
+		ID:              uuid.NewString(),
+		Email:           claims.Email,
+		Name:            identityDisplayName(claims),
+		CreatedAt:       now,
+		UpdatedAt:       now,
+		EmailVerifiedAt: &now,
+		Roles:           []string{model.RoleUser},
+	}
+	identity.UserID = user.ID
+	return s.repo.CreateUserWithIdentity(ctx, user, identity)
+}
+
+// linkIdentity links a new identity to an existing user. If the user never
+// confirmed the email address, whoever registered it may not own it: the
+// password is removed and the sessions are revoked before the provider's
+// verification is taken over.
+func (s *DefaultAuthService) linkIdentity(ctx context.Context, user model.User, identity model.Identity) (model.User, error) {
+	if !user.EmailVerified() {
+		now := identity.CreatedAt
+		user.PasswordHash = ""
+		user.UpdatedAt = now
+		if _, err := s.repo.UpdateUser(ctx, user); err != nil {
+			return model.User{}, err
+		}
+		if err := s.repo.MarkEmailVerified(ctx, user.ID, now); err != nil {
+			return model.User{}, err
+		}
+		if _, err := s.repo.RevokeUserRefreshTokens(ctx, user.ID); err != nil {
+			return model.User{}, err
+		}
+		user.EmailVerifiedAt = &now
+	}
+
+	if err := s.repo.CreateIdentity(ctx, identity); err != nil {
+		return model.User{}, err
+	}
+	return user, nil
+}
+
+func identityDisplayName(claims oidc.Claims) string {
+	if name := strings.TrimSpace(claims.Name); name != "" {
+		return name
+	}
+	local, _, _ := strings.Cut(claims.Email, "@")
+	return local
+}
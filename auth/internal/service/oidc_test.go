@@ -0,0 +1,225 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gojuno/minimock/v3"
+
+	"github.com/Deevins/final-task-course-2-go-lang/auth/internal/config"
+	"github.com/Deevins/final-task-course-2-go-lang/auth/internal/model"
+	"github.com/Deevins/final-task-course-2-go-lang/auth/internal/oidc/oidctest"
+	"github.com/Deevins/final-task-course-2-go-lang/auth/internal/repository"
+)
+
+func TestOIDCLogin(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T, service *DefaultAuthService, store *sessionStore, provider *oidctest.Provider)
+	}{
+		{
+			name: "new identity creates a verified user without a password",
+			run: func(t *testing.T, service *DefaultAuthService, store *sessionStore, provider *oidctest.Provider) {
+				ctx := context.Background()
+				provider.SetUser(oidctest.User{Subject: "sub-1", Email: "new@example.com", EmailVerified: true, Name: "New User"})
+
+				token := completeOIDCLogin(t, service)
+				if token.AccessToken == "" || !token.EmailVerified {
+					t.Fatalf("expected a session of a verified user, got %+v", token)
+				}
+				if store.user.Email != "new@example.com" || store.user.Name != "New User" || store.user.PasswordHash != "" {
+					t.Fatalf("expected a new passwordless user, got %+v", store.user)
+				}
+				if token.UserID != store.user.ID {
+					t.Fatalf("expected session of the new user, got %s", token.UserID)
+				}
+
+				provider.SetUser(oidctest.User{Subject: "sub-1", Email: "renamed@example.com", EmailVerified: true})
+				again := completeOIDCLogin(t, service)
+				if again.UserID != store.user.ID {
+					t.Fatalf("expected the linked user on the next sign-in, got %s", again.UserID)
+				}
+				if identity := store.identities["mock|sub-1"]; identity.Email != "renamed@example.com" {
+					t.Fatalf("expected identity email to follow the provider, got %+v", identity)
+				}
+
+				if _, err := service.Login(ctx, "new@example.com", ""); !errors.Is(err, ErrInvalidCredentials) {
+					t.Fatalf("expected password sign-in to fail without a password, got %v", err)
+				}
+			},
+		},
+		{
+			name: "verified email links the existing user",
+			run: func(t *testing.T, service *DefaultAuthService, store *sessionStore, provider *oidctest.Provider) {
+				verifiedAt := time.Now().UTC()
+				store.user.EmailVerifiedAt = &verifiedAt
+				provider.SetUser(oidctest.User{Subject: "sub-2", Email: store.user.Email, EmailVerified: true})
+
+				token := completeOIDCLogin(t, service)
+				if token.UserID != store.user.ID {
+					t.Fatalf("expected the existing user, got %s", token.UserID)
+				}
+				if identity := store.identities["mock|sub-2"]; identity.UserID != store.user.ID {
+					t.Fatalf("expected identity to be linked, got %+v", identity)
+				}
+				mustLogin(t, service, store)
+			},
+		},
+		{
+			name: "linking an unverified account removes its password and sessions",
+			run: func(t *testing.T, service *DefaultAuthService, store *sessionStore, provider *oidctest.Provider) {
+				ctx := context.Background()
+				session := mustLogin(t, service, store)
+				provider.SetUser(oidctest.User{Subject: "sub-3", Email: store.user.Email, EmailVerified: true})
+
+				token := completeOIDCLogin(t, service)
+				if token.UserID != store.user.ID || !store.user.EmailVerified() {
+					t.Fatalf("expected the existing user to be linked and verified, got %+v", store.user)
+				}
+				if _, err := service.Refresh(ctx, session.RefreshToken); !errors.Is(err, ErrInvalidRefreshToken) {
+					t.Fatalf("expected earlier session to be revoked, got %v", err)
+				}
+				if _, err := service.Login(ctx, store.user.Email, "password123"); !errors.Is(err, ErrInvalidCredentials) {
+					t.Fatalf("expected the old password to be removed, got %v", err)
+				}
+			},
+		},
+		{
+			name: "unverified provider email is rejected",
+			run: func(t *testing.T, service *DefaultAuthService, store *sessionStore, provider *oidctest.Provider) {
+				provider.SetUser(oidctest.User{Subject: "sub-4", Email: store.user.Email})
+
+				login := startOIDCLogin(t, service)
+				state, code := authorizeAt(t, login.URL)
+				if _, err := service.CompleteOIDCLogin(context.Background(), "mock", state, code); !errors.Is(err, ErrExternalEmailNotVerified) {
+					t.Fatalf("expected ErrExternalEmailNotVerified, got %v", err)
+				}
+				if len(store.identities) != 0 {
+					t.Fatalf("expected no identity to be linked, got %+v", store.identities)
+				}
+			},
+		},
+		{
+			name: "state works once and only for its provider",
+			run: func(t *testing.T, service *DefaultAuthService, store *sessionStore, provider *oidctest.Provider) {
+				ctx := context.Background()
+				provider.SetUser(oidctest.User{Subject: "sub-5", Email: "other@example.com", EmailVerified: true})
+
+				if _, err := service.StartOIDCLogin(ctx, "unknown"); !errors.Is(err, ErrUnknownIdentityProvider) {
+					t.Fatalf("expected ErrUnknownIdentityProvider, got %v", err)
+				}
+				login := startOIDCLogin(t, service)
+				state, code := authorizeAt(t, login.URL)
+				if state != login.State {
+					t.Fatalf("expected the provider to return the state, got %q", state)
+				}
+				if _, err := service.CompleteOIDCLogin(ctx, "mock", "forged", code); !errors.Is(err, ErrInvalidOIDCState) {
+					t.Fatalf("expected forged state to be rejected, got %v", err)
+				}
+				if _, err := service.CompleteOIDCLogin(ctx, "mock", state, "wrong-code"); !errors.Is(err, ErrExternalLoginFailed) {
+					t.Fatalf("expected wrong code to be rejected, got %v", err)
+				}
+				if _, err := service.CompleteOIDCLogin(ctx, "mock", state, code); !errors.Is(err, ErrInvalidOIDCState) {
+					t.Fatalf("expected used state to be rejected, got %v", err)
+				}
+			},
+		},
+		{
+			name: "two-factor users get a challenge",
+			run: func(t *testing.T, service *DefaultAuthService, store *sessionStore, provider *oidctest.Provider) {
+				verifiedAt := time.Now().UTC()
+				store.user.EmailVerifiedAt = &verifiedAt
+				secret, step, _ := enableTwoFactor(t, service, store)
+				provider.SetUser(oidctest.User{Subject: "sub-6", Email: store.user.Email, EmailVerified: true})
+
+				challenge := completeOIDCLogin(t, service)
+				if challenge.AccessToken != "" || challenge.ChallengeToken == "" {
+					t.Fatalf("expected a challenge instead of tokens, got %+v", challenge)
+				}
+				token, err := service.VerifyTwoFactor(context.Background(), challenge.ChallengeToken, codeAt(t, secret, step+1))
+				if err != nil {
+					t.Fatalf("verify two factor: %v", err)
+				}
+				if token.UserID != store.user.ID {
+					t.Fatalf("expected session of the linked user, got %s", token.UserID)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider, server, err := oidctest.NewServer("ledger", "client-secret", oidctest.User{})
+			if err != nil {
+				t.Fatalf("start provider: %v", err)
+			}
+			t.Cleanup(server.Close)
+
+			ctrl := minimock.NewController(t)
+			repo := repository.NewAuthRepositoryMock(ctrl)
+			store := newSessionStore(t, repo)
+			oidcConfig := testOIDCConfig()
+			oidcConfig.Providers = []config.OIDCProviderConfig{{
+				Name:         "mock",
+				Issuer:       server.URL,
+				ClientID:     "ledger",
+				ClientSecret: "client-secret",
+				RedirectURL:  "http://gateway.test/api/auth/oidc/mock/callback",
+				Scopes:       []string{"email", "profile"},
+			}}
+			jwtConfig := testJWTConfig()
+			service := NewAuthService(repo, jwtConfig, newTestKeyring(t, jwtConfig), store.mail, testEmailConfig(), testTwoFactorConfig(), testLoginThrottleConfig(), newTestPasswordPolicy(t), oidcConfig)
+			tt.run(t, service, store, provider)
+		})
+	}
+}
+
+func startOIDCLogin(t *testing.T, service *DefaultAuthService) model.OIDCAuthorization {
+	t.Helper()
+	login, err := service.StartOIDCLogin(context.Background(), "mock")
+	if err != nil {
+		t.Fatalf("start oidc login: %v", err)
+	}
+	return login
+}
+
+// completeOIDCLogin signs in at the mock provider and completes the callback.
+func completeOIDCLogin(t *testing.T, service *DefaultAuthService) model.Token {
+	t.Helper()
+	login := startOIDCLogin(t, service)
+	state, code := authorizeAt(t, login.URL)
+	token, err := service.CompleteOIDCLogin(context.Background(), "mock", state, code)
+	if err != nil {
+		t.Fatalf("complete oidc login: %v", err)
+	}
+	return token
+}
+
+// authorizeAt follows the authorization URL like a browser would and returns
+// the state and code of the redirect back to the callback.
+func authorizeAt(t *testing.T, authURL string) (string, string) {
+	t.Helper()
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	resp, err := client.Get(authURL)
+	if err != nil {
+		t.Fatalf("authorize: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusFound {
+		t.Fatalf("expected redirect to the callback, got %s", resp.Status)
+	}
+	location, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		t.Fatalf("parse callback: %v", err)
+	}
+	if location.Host != "gateway.test" {
+		t.Fatalf("expected redirect to the registered callback, got %s", location)
+	}
+	return location.Query().Get("state"), location.Query().Get("code")
+}
@@ -0,0 +1,176 @@
+package service
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/Deevins/final-task-course-2-go-lang/auth/internal/config"
+)
+
+// Reasons of password policy violations.
+const (
+	PasswordTooShort      = "PASSWORD_TOO_SHORT"
+	PasswordTooLong       = "PASSWORD_TOO_LONG"
+	PasswordTooFewClasses = "PASSWORD_TOO_FEW_CHAR_CLASSES"
+	PasswordContainsEmail = "PASSWORD_CONTAINS_EMAIL"
+	PasswordContainsName  = "PASSWORD_CONTAINS_NAME"
+	PasswordBreached      = "PASSWORD_BREACHED"
+)
+
+// minPersonalFragmentSize keeps short names and email local parts like "al"
+// from rejecting most passwords.
+const minPersonalFragmentSize = 3
+
+// ErrWeakPassword is wrapped by PasswordPolicyError.
+var ErrWeakPassword = errors.New("password does not meet the policy")
+
+// PasswordViolation is a single broken password rule.
+type PasswordViolation struct {
+	Reason      string
+	Description string
+}
+
+// PasswordPolicyError is returned when a new password is rejected. It lists
+// every broken rule, so the client can show them all at once.
+type PasswordPolicyError struct {
+	Violations []PasswordViolation
+}
+
+func (e *PasswordPolicyError) Error() string {
+	descriptions := make([]string, 0, len(e.Violations))
+	for _, violation := range e.Violations {
+		descriptions = append(descriptions, violation.Description)
+	}
+	return ErrWeakPassword.Error() + ": " + strings.Join(descriptions, "; ")
+}
+
+func (e *PasswordPolicyError) Unwrap() error {
+	return ErrWeakPassword
+}
+
+// PasswordPolicy checks new passwords on sign up, password change and reset.
+type PasswordPolicy struct {
+	config   config.PasswordPolicyConfig
+	breached map[string]struct{}
+}
+
+// NewPasswordPolicy loads the breached password list of the config, if any.
+// Entries are compared case-insensitively; empty lines and lines starting
+// with # are skipped.
+func NewPasswordPolicy(cfg config.PasswordPolicyConfig) (*PasswordPolicy, error) {
+	policy := &PasswordPolicy{config: cfg, breached: map[string]struct{}{}}
+	if cfg.BreachedListPath == "" {
+		return policy, nil
+	}
+
+	file, err := os.Open(cfg.BreachedListPath)
+	if err != nil {
+		return nil, fmt.Errorf("open breached password list: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		policy.breached[strings.ToLower(line)] = struct{}{}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read breached password list: %w", err)
+	}
+	return policy, nil
+}
+
+// Check returns a PasswordPolicyError if the password of the user with the
+// given email and name breaks any rule.
+func (p *PasswordPolicy) Check(password, email, name string) error {
+	var violations []PasswordViolation
+	add := func(reason, description string) {
+		violations = append(violations, PasswordViolation{Reason: reason, Description: description})
+	}
+
+	if utf8.RuneCountInString(password) < p.config.MinLength {
+		add(PasswordTooShort, fmt.Sprintf("must be at least %d characters long", p.config.MinLength))
+	}
+	if len(password) > p.config.MaxBytes {
+		add(PasswordTooLong, fmt.Sprintf("must not be longer than %d bytes", p.config.MaxBytes))
+	}
+	if countCharClasses(password) < p.config.MinCharClasses {
+		add(PasswordTooFewClasses, fmt.Sprintf(
+			"must mix at least %d of lowercase letters, uppercase letters, digits and symbols",
+			p.config.MinCharClasses,
+		))
+	}
+
+	lowered := strings.ToLower(password)
+	if containsAny(lowered, emailFragments(email)) {
+		add(PasswordContainsEmail, "must not contain the email address")
+	}
+	if containsAny(lowered, nameFragments(name)) {
+		add(PasswordContainsName, "must not contain the name")
+	}
+	if _, ok := p.breached[lowered]; ok {
+		add(PasswordBreached, "is in a list of breached passwords")
+	}
+
+	if len(violations) > 0 {
+		return &PasswordPolicyError{Violations: violations}
+	}
+	return nil
+}
+
+func countCharClasses(password string) int {
+	var lower, upper, digit, other bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		default:
+			other = true
+		}
+	}
+	count := 0
+	for _, present := range []bool{lower, upper, digit, other} {
+		if present {
+			count++
+		}
+	}
+	return count
+}
+
+// emailFragments returns the address and its local part.
+func emailFragments(email string) []string {
+	email = strings.ToLower(strings.TrimSpace(email))
+	fragments := []string{email}
+	if local, _, ok := strings.Cut(email, "@"); ok {
+		fragments = append(fragments, local)
+	}
+	return fragments
+}
+
+// nameFragments returns the name and each of its words.
+func nameFragments(name string) []string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	return append([]string{name}, strings.Fields(name)...)
+}
+
+// containsAny reports whether s contains a fragment long enough to matter.
+func containsAny(s string, fragments []string) bool {
+	for _, fragment := range fragments {
+		if utf8.RuneCountInString(fragment) >= minPersonalFragmentSize && strings.Contains(s, fragment) {
+			return true
+		}
+	}
+	return false
+}
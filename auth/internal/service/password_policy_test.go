@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gojuno/minimock/v3"
+
+	"github.com/Deevins/final-task-course-2-go-lang/auth/internal/model"
+	"github.com/Deevins/final-task-course-2-go-lang/auth/internal/repository"
+)
+
+func TestPasswordPolicy(t *testing.T) {
+	policy := newTestPasswordPolicy(t, "# common passwords", "Correct-Horse1")
+
+	tests := []struct {
+		name     string
+		password string
+		reasons  []string
+	}{
+		{name: "accepted", password: "tulip-garden-42"},
+		{name: "too short", password: "ab1-", reasons: []string{PasswordTooShort}},
+		{name: "too long", password: strings.Repeat("ab1-", 19), reasons: []string{PasswordTooLong}},
+		{name: "multibyte counted in bytes", password: strings.Repeat("пароль1", 6), reasons: []string{PasswordTooLong}},
+		{name: "single class", password: "onlylowercase", reasons: []string{PasswordTooFewClasses}},
+		{name: "contains email", password: "Jane.Doe@example.com", reasons: []string{PasswordContainsEmail}},
+		{name: "contains email local part", password: "xx-jane.doe-xx", reasons: []string{PasswordContainsEmail}},
+		{name: "contains name word", password: "smith-1234", reasons: []string{PasswordContainsName}},
+		{name: "breached ignores case", password: "correct-horse1", reasons: []string{PasswordBreached}},
+		{
+			name:     "all violations reported",
+			password: "smith",
+			reasons:  []string{PasswordTooShort, PasswordTooFewClasses, PasswordContainsName},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := policy.Check(tt.password, "jane.doe@example.com", "Alex Smith")
+			if len(tt.reasons) == 0 {
+				if err != nil {
+					t.Fatalf("expected password to be accepted, got %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrWeakPassword) {
+				t.Fatalf("expected ErrWeakPassword, got %v", err)
+			}
+			if got := violationReasons(err); !reflect.DeepEqual(got, tt.reasons) {
+				t.Fatalf("expected violations %v, got %v", tt.reasons, got)
+			}
+		})
+	}
+}
+
+func TestPasswordPolicyOnPasswordChanges(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T, service *DefaultAuthService, store *sessionStore)
+	}{
+		{
+			name: "register rejects a weak password before creating the user",
+			run: func(t *testing.T, service *DefaultAuthService, store *sessionStore) {
+				_, err := service.Register(context.Background(), "new@example.com", "123", "New User")
+				if got := violationReasons(err); !reflect.DeepEqual(got, []string{PasswordTooShort, PasswordTooFewClasses}) {
+					t.Fatalf("expected short single-class password to be rejected, got %v", err)
+				}
+			},
+		},
+		{
+			name: "change password rejects the name",
+			run: func(t *testing.T, service *DefaultAuthService, store *sessionStore) {
+				ctx := context.Background()
+				session := mustLogin(t, service, store)
+
+				_, err := service.ChangePassword(ctx, session.AccessToken, "password123", "maxwell-2024")
+				if got := violationReasons(err); !reflect.DeepEqual(got, []string{PasswordContainsName}) {
+					t.Fatalf("expected password with the name to be rejected, got %v", err)
+				}
+				if _, err := service.Refresh(ctx, session.RefreshToken); err != nil {
+					t.Fatalf("expected session to survive a rejected change, got %v", err)
+				}
+			},
+		},
+		{
+			name: "rejected reset keeps the token usable",
+			run: func(t *testing.T, service *DefaultAuthService, store *sessionStore) {
+				ctx := context.Background()
+				if err := service.RequestPasswordReset(ctx, store.user.Email); err != nil {
+					t.Fatalf("request reset: %v", err)
+				}
+				token := store.mail.lastToken(t)
+
+				if err := service.ResetPassword(ctx, token, "user@example.com"); !errors.Is(err, ErrWeakPassword) {
+					t.Fatalf("expected password with the email to be rejected, got %v", err)
+				}
+				if err := service.ResetPassword(ctx, token, "new-password"); err != nil {
+					t.Fatalf("expected token to work after a rejected password, got %v", err)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := minimock.NewController(t)
+			repo := repository.NewAuthRepositoryMock(ctrl)
+			store := newSessionStore(t, repo)
+			store.user.Name = "Max Maxwell"
+			repo.CreateUserFunc = func(ctx context.Context, user model.User) (model.User, error) {
+				t.Fatal("expected no user to be created")
+				return model.User{}, nil
+			}
+			jwtConfig := testJWTConfig()
+			tt.run(t, NewAuthService(repo, jwtConfig, newTestKeyring(t, jwtConfig), store.mail, testEmailConfig(), testTwoFactorConfig(), testLoginThrottleConfig(), newTestPasswordPolicy(t), testOIDCConfig()), store)
+		})
+	}
+}
+
+func violationReasons(err error) []string {
+	var policyErr *PasswordPolicyError
+	if !errors.As(err, &policyErr) {
+		return nil
+	}
+	reasons := make([]string, 0, len(policyErr.Violations))
+	for _, violation := range policyErr.Violations {
+		reasons = append(reasons, violation.Reason)
+	}
+	return reasons
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/Deevins/final-task-course-2-go-lang/auth/internal/model"
+)
+
+// GetMe returns the owner of the access token.
+func (s *DefaultAuthService) GetMe(ctx context.Context, accessToken string) (model.User, error) {
+	claims, err := s.parseAccessToken(ctx, accessToken)
+	if err != nil {
+		return model.User{}, err
+	}
+	return s.repo.GetUserByID(ctx, claims.Subject)
+}
+
+// UpdateProfile renames the owner of the access token.
+func (s *DefaultAuthService) UpdateProfile(ctx context.Context, accessToken, name string) (model.User, error) {
+	user, err := s.GetMe(ctx, accessToken)
+	if err != nil {
+		return model.User{}, err
+	}
+	user.Name = name
+	user.UpdatedAt = time.Now().UTC()
+	return s.repo.UpdateUser(ctx, user)
+}
+
+// ChangePassword replaces the password of the token owner after checking the
+// current one. The new password has to pass the password policy. Every
+// existing session, including the caller's, is revoked together with all access
+// tokens issued so far, and a new session is started for the caller.
+func (s *DefaultAuthService) ChangePassword(ctx context.Context, accessToken, currentPassword, newPassword string) (model.Token, error) {
+	claims, err := s.parseAccessToken(ctx, accessToken)
+	if err != nil {
+		return model.Token{}, err
+	}
+	user, err := s.repo.GetUserByID(ctx, claims.Subject)
+	if err != nil {
+		return model.Token{}, err
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(currentPassword)); err != nil {
+		return model.Token{}, ErrWrongPassword
+	}
+	if err := s.passwordPolicy.Check(newPassword, user.Email, user.Name); err != nil {
+		return model.Token{}, err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return model.Token{}, err
+	}
+	user.PasswordHash = string(hashedPassword)
+	user.UpdatedAt = time.Now().UTC()
+	if _, err := s.repo.UpdateUser(ctx, user); err != nil {
+		return model.Token{}, err
+	}
+
+	if _, err := s.revokeUserSessions(ctx, user.ID); err != nil {
+		return model.Token{}, err
+	}
+	if err := s.repo.RevokeAccessToken(ctx, revokedToken(claims)); err != nil {
+		return model.Token{}, err
+	}
+
+	return s.startSession(ctx, user)
+}
+
+// DeleteAccount removes the token owner after checking the password. All
+// sessions and access tokens are revoked first; the user's ledger data is
+// purged asynchronously from the purge request stored with the deletion.
+func (s *DefaultAuthService) DeleteAccount(ctx context.Context, accessToken, password string) error {
+	claims, err := s.parseAccessToken(ctx, accessToken)
+	if err != nil {
+		return err
+	}
+	user, err := s.repo.GetUserByID(ctx, claims.Subject)
+	if err != nil {
+		return err
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+		return ErrWrongPassword
+	}
+
+	if _, err := s.revokeUserSessions(ctx, user.ID); err != nil {
+		return err
+	}
+	if err := s.repo.RevokeAccessToken(ctx, revokedToken(claims)); err != nil {
+		return err
+	}
+
+	return s.repo.DeleteUser(ctx, user.ID, time.Now().UTC())
+}
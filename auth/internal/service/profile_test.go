@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gojuno/minimock/v3"
+
+	"github.com/Deevins/final-task-course-2-go-lang/auth/internal/model"
+	"github.com/Deevins/final-task-course-2-go-lang/auth/internal/repository"
+)
+
+func TestAuthServiceProfile(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T, service *DefaultAuthService, store *sessionStore)
+	}{
+		{
+			name: "get me and update profile",
+			run: func(t *testing.T, service *DefaultAuthService, store *sessionStore) {
+				ctx := context.Background()
+				token, err := service.Login(ctx, store.user.Email, "password123")
+				if err != nil {
+					t.Fatalf("login: %v", err)
+				}
+
+				user, err := service.GetMe(ctx, token.AccessToken)
+				if err != nil {
+					t.Fatalf("get me: %v", err)
+				}
+				if user.ID != store.user.ID || user.Email != store.user.Email {
+					t.Fatalf("unexpected user: %+v", user)
+				}
+
+				updated, err := service.UpdateProfile(ctx, token.AccessToken, "Alice")
+				if err != nil {
+					t.Fatalf("update profile: %v", err)
+				}
+				if updated.Name != "Alice" || updated.UpdatedAt.IsZero() {
+					t.Fatalf("expected renamed user, got %+v", updated)
+				}
+				if _, err := service.GetMe(ctx, "bad-token"); !errors.Is(err, ErrInvalidToken) {
+					t.Fatalf("expected invalid token, got %v", err)
+				}
+			},
+		},
+		{
+			name: "change password revokes sessions",
+			run: func(t *testing.T, service *DefaultAuthService, store *sessionStore) {
+				ctx := context.Background()
+				current, err := service.Login(ctx, store.user.Email, "password123")
+				if err != nil {
+					t.Fatalf("login: %v", err)
+				}
+				other, err := service.Login(ctx, store.user.Email, "password123")
+				if err != nil {
+					t.Fatalf("login: %v", err)
+				}
+				unsessioned := makeTokenIssuedAt(t, service.keys, store.user.ID, time.Now().UTC().Add(-time.Minute))
+
+				if _, err := service.ChangePassword(ctx, current.AccessToken, "wrong", "new-password"); !errors.Is(err, ErrWrongPassword) {
+					t.Fatalf("expected wrong password, got %v", err)
+				}
+
+				next, err := service.ChangePassword(ctx, current.AccessToken, "password123", "new-password")
+				if err != nil {
+					t.Fatalf("change password: %v", err)
+				}
+				for _, token := range []model.Token{current, other} {
+					if _, ok, _ := service.ValidateToken(ctx, token.AccessToken); ok {
+						t.Fatal("expected existing access tokens to be revoked")
+					}
+					if _, err := service.Refresh(ctx, token.RefreshToken); !errors.Is(err, ErrInvalidRefreshToken) {
+						t.Fatalf("expected existing refresh tokens to be revoked, got %v", err)
+					}
+				}
+				if _, ok, _ := service.ValidateToken(ctx, unsessioned); ok {
+					t.Fatal("expected an access token without a session to be revoked")
+				}
+				if _, ok, err := service.ValidateToken(ctx, next.AccessToken); err != nil || !ok {
+					t.Fatalf("expected new session to be valid, ok=%v err=%v", ok, err)
+				}
+				if _, err := service.Login(ctx, store.user.Email, "password123"); !errors.Is(err, ErrInvalidCredentials) {
+					t.Fatalf("expected old password to be rejected, got %v", err)
+				}
+				if _, err := service.Login(ctx, store.user.Email, "new-password"); err != nil {
+					t.Fatalf("login with new password: %v", err)
+				}
+			},
+		},
+		{
+			name: "delete account revokes sessions and schedules ledger purge",
+			run: func(t *testing.T, service *DefaultAuthService, store *sessionStore) {
+				ctx := context.Background()
+				token, err := service.Login(ctx, store.user.Email, "password123")
+				if err != nil {
+					t.Fatalf("login: %v", err)
+				}
+				other, err := service.Login(ctx, store.user.Email, "password123")
+				if err != nil {
+					t.Fatalf("login: %v", err)
+				}
+				unsessioned := makeTokenIssuedAt(t, service.keys, store.user.ID, time.Now().UTC().Add(-time.Minute))
+
+				if err := service.DeleteAccount(ctx, token.AccessToken, "wrong"); !errors.Is(err, ErrWrongPassword) {
+					t.Fatalf("expected wrong password, got %v", err)
+				}
+				if store.deleted {
+					t.Fatal("expected user to be kept after a wrong password")
+				}
+
+				if err := service.DeleteAccount(ctx, token.AccessToken, "password123"); err != nil {
+					t.Fatalf("delete account: %v", err)
+				}
+				if !store.deleted || len(store.purges) != 1 || store.purges[0] != store.user.ID {
+					t.Fatalf("expected user deletion with a purge request, got deleted=%v purges=%v", store.deleted, store.purges)
+				}
+				for _, accessToken := range []string{token.AccessToken, other.AccessToken, unsessioned} {
+					if _, ok, _ := service.ValidateToken(ctx, accessToken); ok {
+						t.Fatal("expected every access token of the user to be revoked")
+					}
+				}
+				if _, err := service.Refresh(ctx, other.RefreshToken); !errors.Is(err, ErrInvalidRefreshToken) {
+					t.Fatalf("expected refresh token to be revoked, got %v", err)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := minimock.NewController(t)
+			repo := repository.NewAuthRepositoryMock(ctrl)
+			store := newSessionStore(t, repo)
+			jwtConfig := testJWTConfig()
+			tt.run(t, NewAuthService(repo, jwtConfig, newTestKeyring(t, jwtConfig), store.mail, testEmailConfig(), testTwoFactorConfig(), testLoginThrottleConfig(), newTestPasswordPolicy(t), testOIDCConfig()), store)
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/Deevins/final-task-course-2-go-lang/auth/internal/model"
+)
+
+var (
+	ErrInvalidRole = errors.New("invalid role")
+	// ErrAdminRequired is returned when the caller is not an admin. The role
+	// is checked against the stored user, not the token claims, so a revoked
+	// role stops working right away.
+	ErrAdminRequired = errors.New("admin role is required")
+	ErrStaffRequired = errors.New("support or admin role is required")
+	// ErrOwnAdminRole is returned when an admin tries to remove their own
+	// admin role, which could leave nobody to manage roles.
+	ErrOwnAdminRole = errors.New("admins cannot remove their own admin role")
+)
+
+// knownRoles lists the roles in the order they are stored.
+var knownRoles = []string{model.RoleUser, model.RoleSupport, model.RoleAdmin}
+
+// SetUserRoles replaces the roles of a user on behalf of the admin owning the
+// access token. RoleUser is always kept. The sessions of the user are revoked
+// together with every access token issued so far, so tokens with the previous
+// roles stop working.
+func (s *DefaultAuthService) SetUserRoles(ctx context.Context, accessToken, userID string, roles []string) (model.User, error) {
+	caller, err := s.GetMe(ctx, accessToken)
+	if err != nil {
+		return model.User{}, err
+	}
+	if !caller.HasRole(model.RoleAdmin) {
+		return model.User{}, ErrAdminRequired
+	}
+
+	normalized, err := normalizeRoles(roles)
+	if err != nil {
+		return model.User{}, err
+	}
+	if userID == caller.ID && !slices.Contains(normalized, model.RoleAdmin) {
+		return model.User{}, ErrOwnAdminRole
+	}
+
+	user, err := s.repo.SetUserRoles(ctx, userID, normalized, time.Now().UTC())
+	if err != nil {
+		return model.User{}, err
+	}
+	if _, err := s.revokeUserSessions(ctx, user.ID); err != nil {
+		return model.User{}, err
+	}
+	return user, nil
+}
+
+// LookupUser finds a user by email on behalf of the support or admin user
+// owning the access token, e.g. to open their ledger for a support request.
+func (s *DefaultAuthService) LookupUser(ctx context.Context, accessToken, email string) (model.User, error) {
+	caller, err := s.GetMe(ctx, accessToken)
+	if err != nil {
+		return model.User{}, err
+	}
+	if !caller.HasRole(model.RoleSupport) && !caller.HasRole(model.RoleAdmin) {
+		return model.User{}, ErrStaffRequired
+	}
+	return s.repo.GetUserByEmail(ctx, email)
+}
+
+// normalizeRoles validates the roles, adds RoleUser and removes duplicates.
+func normalizeRoles(roles []string) ([]string, error) {
+	for _, role := range roles {
+		if !slices.Contains(knownRoles, role) {
+			return nil, fmt.Errorf("%w %q", ErrInvalidRole, role)
+		}
+	}
+	normalized := []string{model.RoleUser}
+	for _, role := range knownRoles[1:] {
+		if slices.Contains(roles, role) {
+			normalized = append(normalized, role)
+		}
+	}
+	return normalized, nil
+}
@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/gojuno/minimock/v3"
+
+	"github.com/Deevins/final-task-course-2-go-lang/auth/internal/model"
+	"github.com/Deevins/final-task-course-2-go-lang/auth/internal/repository"
+)
+
+func TestAuthServiceRoles(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T, service *DefaultAuthService, store *sessionStore, repo *repository.AuthRepositoryMock)
+	}{
+		{
+			name: "roles are embedded in tokens",
+			run: func(t *testing.T, service *DefaultAuthService, store *sessionStore, repo *repository.AuthRepositoryMock) {
+				store.user.Roles = []string{model.RoleUser, model.RoleSupport}
+				token := mustLogin(t, service, store)
+				if !slices.Equal(token.Roles, store.user.Roles) {
+					t.Fatalf("expected roles in the session, got %v", token.Roles)
+				}
+
+				validated, valid, err := service.ValidateToken(context.Background(), token.AccessToken)
+				if err != nil || !valid {
+					t.Fatalf("validate token: valid=%v err=%v", valid, err)
+				}
+				if !slices.Equal(validated.Roles, []string{model.RoleUser, model.RoleSupport}) {
+					t.Fatalf("expected roles from the claims, got %v", validated.Roles)
+				}
+			},
+		},
+		{
+			name: "only admins change roles",
+			run: func(t *testing.T, service *DefaultAuthService, store *sessionStore, repo *repository.AuthRepositoryMock) {
+				store.user.Roles = []string{model.RoleUser, model.RoleSupport}
+				token := mustLogin(t, service, store)
+				if _, err := service.SetUserRoles(context.Background(), token.AccessToken, store.user.ID, []string{model.RoleAdmin}); !errors.Is(err, ErrAdminRequired) {
+					t.Fatalf("expected ErrAdminRequired, got %v", err)
+				}
+			},
+		},
+		{
+			name: "support looks up users by email",
+			run: func(t *testing.T, service *DefaultAuthService, store *sessionStore, repo *repository.AuthRepositoryMock) {
+				ctx := context.Background()
+				token := mustLogin(t, service, store)
+				if _, err := service.LookupUser(ctx, token.AccessToken, store.user.Email); !errors.Is(err, ErrStaffRequired) {
+					t.Fatalf("expected ErrStaffRequired, got %v", err)
+				}
+
+				store.user.Roles = []string{model.RoleUser, model.RoleSupport}
+				user, err := service.LookupUser(ctx, token.AccessToken, store.user.Email)
+				if err != nil {
+					t.Fatalf("lookup user: %v", err)
+				}
+				if user.ID != store.user.ID {
+					t.Fatalf("expected user %s, got %s", store.user.ID, user.ID)
+				}
+				if _, err := service.LookupUser(ctx, token.AccessToken, "missing@example.com"); !IsNotFound(err) {
+					t.Fatalf("expected not found, got %v", err)
+				}
+			},
+		},
+		{
+			name: "admin role is checked against the stored user",
+			run: func(t *testing.T, service *DefaultAuthService, store *sessionStore, repo *repository.AuthRepositoryMock) {
+				store.user.Roles = []string{model.RoleUser, model.RoleAdmin}
+				token := mustLogin(t, service, store)
+				store.user.Roles = []string{model.RoleUser}
+
+				if _, err := service.SetUserRoles(context.Background(), token.AccessToken, "other-user", []string{model.RoleSupport}); !errors.Is(err, ErrAdminRequired) {
+					t.Fatalf("expected ErrAdminRequired for a revoked admin, got %v", err)
+				}
+			},
+		},
+		{
+			name: "admin grants roles to another user and revokes their sessions",
+			run: func(t *testing.T, service *DefaultAuthService, store *sessionStore, repo *repository.AuthRepositoryMock) {
+				ctx := context.Background()
+				store.user.Roles = []string{model.RoleUser, model.RoleAdmin}
+				token := mustLogin(t, service, store)
+
+				var stored []string
+				repo.SetUserRolesFn = func(ctx context.Context, userID string, roles []string, updatedAt time.Time) (model.User, error) {
+					if userID != "other-user" {
+						t.Fatalf("unexpected user %s", userID)
+					}
+					stored = roles
+					return model.User{ID: userID, Roles: roles}, nil
+				}
+				var revokedFor string
+				repo.RevokeUserRefreshTokensFn = func(ctx context.Context, userID string) (int64, error) {
+					revokedFor = userID
+					return 1, nil
+				}
+
+				user, err := service.SetUserRoles(ctx, token.AccessToken, "other-user", []string{model.RoleSupport, model.RoleSupport})
+				if err != nil {
+					t.Fatalf("set roles: %v", err)
+				}
+				if !slices.Equal(stored, []string{model.RoleUser, model.RoleSupport}) || !slices.Equal(user.Roles, stored) {
+					t.Fatalf("expected normalized roles, got %v", stored)
+				}
+				if revokedFor != "other-user" {
+					t.Fatalf("expected sessions of the user to be revoked, got %q", revokedFor)
+				}
+				if _, ok := store.revokedUsers["other-user"]; !ok {
+					t.Fatal("expected access tokens with the previous roles to be revoked")
+				}
+			},
+		},
+		{
+			name: "admin keeps own admin role",
+			run: func(t *testing.T, service *DefaultAuthService, store *sessionStore, repo *repository.AuthRepositoryMock) {
+				ctx := context.Background()
+				store.user.Roles = []string{model.RoleUser, model.RoleAdmin}
+				token := mustLogin(t, service, store)
+
+				if _, err := service.SetUserRoles(ctx, token.AccessToken, store.user.ID, []string{model.RoleSupport}); !errors.Is(err, ErrOwnAdminRole) {
+					t.Fatalf("expected ErrOwnAdminRole, got %v", err)
+				}
+				if _, err := service.SetUserRoles(ctx, token.AccessToken, store.user.ID, []string{"root"}); !errors.Is(err, ErrInvalidRole) {
+					t.Fatalf("expected ErrInvalidRole, got %v", err)
+				}
+
+				user, err := service.SetUserRoles(ctx, token.AccessToken, store.user.ID, []string{model.RoleAdmin, model.RoleSupport})
+				if err != nil {
+					t.Fatalf("set own roles: %v", err)
+				}
+				want := []string{model.RoleUser, model.RoleSupport, model.RoleAdmin}
+				if !slices.Equal(user.Roles, want) {
+					t.Fatalf("expected %v, got %v", want, user.Roles)
+				}
+				if _, err := service.Refresh(ctx, token.RefreshToken); !errors.Is(err, ErrInvalidRefreshToken) {
+					t.Fatalf("expected the session with the old roles to be revoked, got %v", err)
+				}
+				if token := mustLogin(t, service, store); !slices.Equal(token.Roles, want) {
+					t.Fatalf("expected new roles in the next session, got %v", token.Roles)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := minimock.NewController(t)
+			repo := repository.NewAuthRepositoryMock(ctrl)
+			store := newSessionStore(t, repo)
+			jwtConfig := testJWTConfig()
+			service := NewAuthService(repo, jwtConfig, newTestKeyring(t, jwtConfig), store.mail, testEmailConfig(), testTwoFactorConfig(), testLoginThrottleConfig(), newTestPasswordPolicy(t), testOIDCConfig())
+			tt.run(t, service, store, repo)
+		})
+	}
+}
@@ -0,0 +1,278 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+
+	"github.com/Deevins/final-task-course-2-go-lang/auth/internal/model"
+)
+
+const refreshTokenBytes = 32
+
+// Refresh exchanges a refresh token for a new access and refresh token pair.
+// The presented token is rotated: it stops working once a successor is issued,
+// and presenting it again revokes the whole session.
+func (s *DefaultAuthService) Refresh(ctx context.Context, refreshToken string) (model.Token, error) {
+	stored, err := s.repo.GetRefreshTokenByHash(ctx, hashToken(refreshToken))
+	if err != nil {
+		if IsNotFound(err) {
+			return model.Token{}, ErrInvalidRefreshToken
+		}
+		return model.Token{}, err
+	}
+	if stored.RevokedAt != nil {
+		if stored.ReplacedBy == "" {
+			return model.Token{}, ErrInvalidRefreshToken
+		}
+		return model.Token{}, s.revokeReusedSession(ctx, stored)
+	}
+	if !time.Now().UTC().Before(stored.ExpiresAt) {
+		return model.Token{}, ErrInvalidRefreshToken
+	}
+
+	user, err := s.repo.GetUserByID(ctx, stored.UserID)
+	if err != nil {
+		if IsNotFound(err) {
+			return model.Token{}, ErrInvalidRefreshToken
+		}
+		return model.Token{}, err
+	}
+
+	token, next, err := s.issueTokens(user, stored.FamilyID)
+	if err != nil {
+		return model.Token{}, err
+	}
+	if err := s.repo.RotateRefreshToken(ctx, stored.ID, next); err != nil {
+		if IsNotFound(err) {
+			// A concurrent request rotated the same token first.
+			return model.Token{}, s.revokeReusedSession(ctx, stored)
+		}
+		return model.Token{}, err
+	}
+
+	return token, nil
+}
+
+// Logout denylists the access token and, when a refresh token is given, revokes
+// the session it belongs to.
+func (s *DefaultAuthService) Logout(ctx context.Context, accessToken, refreshToken string) error {
+	claims, err := s.parseAccessToken(ctx, accessToken)
+	if err != nil {
+		return err
+	}
+
+	if refreshToken != "" {
+		stored, err := s.repo.GetRefreshTokenByHash(ctx, hashToken(refreshToken))
+		if err != nil {
+			if IsNotFound(err) {
+				return ErrInvalidRefreshToken
+			}
+			return err
+		}
+		if stored.UserID != claims.Subject {
+			return ErrInvalidRefreshToken
+		}
+		if err := s.repo.RevokeRefreshTokenFamily(ctx, stored.FamilyID); err != nil {
+			return err
+		}
+	}
+
+	return s.repo.RevokeAccessToken(ctx, revokedToken(claims))
+}
+
+// RevokeAllSessions revokes every session of the token owner, including the
+// one the access token belongs to, and returns the number of revoked sessions.
+// Every access token issued to the owner so far stops working.
+func (s *DefaultAuthService) RevokeAllSessions(ctx context.Context, accessToken string) (int64, error) {
+	claims, err := s.parseAccessToken(ctx, accessToken)
+	if err != nil {
+		return 0, err
+	}
+
+	revoked, err := s.revokeUserSessions(ctx, claims.Subject)
+	if err != nil {
+		return 0, err
+	}
+	if err := s.repo.RevokeAccessToken(ctx, revokedToken(claims)); err != nil {
+		return 0, err
+	}
+
+	return revoked, nil
+}
+
+// ListRevokedTokens returns denylist entries and user cutoffs set after since,
+// so verifiers can keep a local copy of the denylist. The returned time is when
+// the listing started and can be passed as since to the next call.
+func (s *DefaultAuthService) ListRevokedTokens(ctx context.Context, since time.Time) ([]model.RevokedToken, []model.RevokedUser, time.Time, error) {
+	asOf := time.Now().UTC()
+	tokens, err := s.repo.ListRevokedTokens(ctx, since)
+	if err != nil {
+		return nil, nil, time.Time{}, err
+	}
+	users, err := s.repo.ListRevokedUsers(ctx, since)
+	if err != nil {
+		return nil, nil, time.Time{}, err
+	}
+	return tokens, users, asOf, nil
+}
+
+// PurgeExpiredTokens removes refresh tokens and denylist entries that can no
+// longer be used, together with failed sign-in counters outside the window.
+func (s *DefaultAuthService) PurgeExpiredTokens(ctx context.Context) (int64, error) {
+	now := time.Now().UTC()
+	deleted, err := s.repo.DeleteExpiredTokens(ctx, now)
+	if err != nil {
+		return 0, err
+	}
+	stale, err := s.repo.DeleteStaleLoginFailures(ctx, now.Add(-s.loginConfig.FailureWindow))
+	if err != nil {
+		return deleted, err
+	}
+	return deleted + stale, nil
+}
+
+func (s *DefaultAuthService) startSession(ctx context.Context, user model.User) (model.Token, error) {
+	token, refresh, err := s.issueTokens(user, uuid.NewString())
+	if err != nil {
+		return model.Token{}, err
+	}
+	if err := s.repo.CreateRefreshToken(ctx, refresh); err != nil {
+		return model.Token{}, err
+	}
+
+	return token, nil
+}
+
+// issueTokens signs a new access token and generates a refresh token for the given session.
+func (s *DefaultAuthService) issueTokens(user model.User, familyID string) (model.Token, model.RefreshToken, error) {
+	now := time.Now().UTC()
+	expiresAt := now.Add(s.jwtConfig.Expiry)
+	claims := accessClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			Subject:   user.ID,
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
+		EmailVerified: user.EmailVerified(),
+		Roles:         user.Roles,
+	}
+	accessToken, err := s.keys.Sign(claims)
+	if err != nil {
+		return model.Token{}, model.RefreshToken{}, err
+	}
+
+	value := make([]byte, refreshTokenBytes)
+	if _, err := rand.Read(value); err != nil {
+		return model.Token{}, model.RefreshToken{}, fmt.Errorf("generate refresh token: %w", err)
+	}
+	refreshToken := base64.RawURLEncoding.EncodeToString(value)
+	refreshExpiresAt := now.Add(s.jwtConfig.RefreshExpiry)
+
+	token := model.Token{
+		AccessToken:      accessToken,
+		UserID:           user.ID,
+		ExpiresAt:        expiresAt,
+		RefreshToken:     refreshToken,
+		RefreshExpiresAt: refreshExpiresAt,
+		EmailVerified:    user.EmailVerified(),
+		Roles:            user.Roles,
+	}
+	refresh := model.RefreshToken{
+		ID:              uuid.NewString(),
+		UserID:          user.ID,
+		FamilyID:        familyID,
+		TokenHash:       hashToken(refreshToken),
+		AccessTokenID:   claims.ID,
+		AccessExpiresAt: expiresAt,
+		ExpiresAt:       refreshExpiresAt,
+		CreatedAt:       now,
+	}
+
+	return token, refresh, nil
+}
+
+// revokeUserSessions revokes every session of the user and sets a cutoff that
+// rejects the access tokens issued to the user so far, including tokens that
+// belong to no stored session. Tokens carry their issue time in whole seconds,
+// so the cutoff is truncated to the second: tokens issued earlier in that
+// second are denylisted by jti together with their sessions, while a session
+// started right after the revocation keeps working.
+func (s *DefaultAuthService) revokeUserSessions(ctx context.Context, userID string) (int64, error) {
+	revoked, err := s.repo.RevokeUserRefreshTokens(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	revokedBefore := time.Now().UTC().Truncate(time.Second)
+	err = s.repo.RevokeUserAccessTokens(ctx, model.RevokedUser{
+		UserID:        userID,
+		RevokedBefore: revokedBefore,
+		ExpiresAt:     revokedBefore.Add(s.jwtConfig.Expiry),
+	})
+	if err != nil {
+		return 0, err
+	}
+	return revoked, nil
+}
+
+func (s *DefaultAuthService) revokeReusedSession(ctx context.Context, stored model.RefreshToken) error {
+	if err := s.repo.RevokeRefreshTokenFamily(ctx, stored.FamilyID); err != nil {
+		return err
+	}
+	return ErrRefreshTokenReused
+}
+
+// parseAccessToken returns the claims of a valid, not revoked access token.
+func (s *DefaultAuthService) parseAccessToken(ctx context.Context, accessToken string) (*accessClaims, error) {
+	claims, parsedToken, err := s.parseClaims(ctx, accessToken)
+	if err != nil || !parsedToken.Valid {
+		return nil, ErrInvalidToken
+	}
+
+	revoked, err := s.isRevoked(ctx, claims)
+	if err != nil {
+		return nil, err
+	}
+	if revoked {
+		return nil, ErrInvalidToken
+	}
+	return claims, nil
+}
+
+// isRevoked reports whether the access token is on the denylist or was issued
+// before the cutoff of its owner. Tokens without a jti cannot be revoked
+// individually and are therefore not accepted.
+func (s *DefaultAuthService) isRevoked(ctx context.Context, claims *accessClaims) (bool, error) {
+	if claims.ID == "" {
+		return true, nil
+	}
+	var issuedAt time.Time
+	if claims.IssuedAt != nil {
+		issuedAt = claims.IssuedAt.Time
+	}
+	return s.repo.IsAccessTokenRevoked(ctx, claims.ID, claims.Subject, issuedAt)
+}
+
+func revokedToken(claims *accessClaims) model.RevokedToken {
+	token := model.RevokedToken{ID: claims.ID, UserID: claims.Subject}
+	if claims.ExpiresAt != nil {
+		token.ExpiresAt = claims.ExpiresAt.Time
+	}
+	return token
+}
+
+// hashToken returns the hex-encoded SHA-256 of a refresh token. Refresh tokens
+// carry 256 bits of randomness, so an unsalted fast hash is sufficient.
+func hashToken(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,565 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gojuno/minimock/v3"
+	"github.com/google/uuid"
+
+	"github.com/Deevins/final-task-course-2-go-lang/auth/internal/model"
+	"github.com/Deevins/final-task-course-2-go-lang/auth/internal/repository"
+	"github.com/Deevins/final-task-course-2-go-lang/auth/internal/storage"
+)
+
+func TestAuthServiceSessions(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T, service *DefaultAuthService, store *sessionStore)
+	}{
+		{
+			name: "refresh rotates tokens",
+			run: func(t *testing.T, service *DefaultAuthService, store *sessionStore) {
+				ctx := context.Background()
+				first, err := service.Login(ctx, store.user.Email, "password123")
+				if err != nil {
+					t.Fatalf("login: %v", err)
+				}
+
+				second, err := service.Refresh(ctx, first.RefreshToken)
+				if err != nil {
+					t.Fatalf("refresh: %v", err)
+				}
+				if second.RefreshToken == first.RefreshToken || second.AccessToken == first.AccessToken {
+					t.Fatal("expected a new token pair")
+				}
+				if second.UserID != store.user.ID {
+					t.Fatalf("expected user %q, got %q", store.user.ID, second.UserID)
+				}
+				if _, ok, err := service.ValidateToken(ctx, second.AccessToken); err != nil || !ok {
+					t.Fatalf("expected refreshed access token to be valid, ok=%v err=%v", ok, err)
+				}
+			},
+		},
+		{
+			name: "reused refresh token revokes session",
+			run: func(t *testing.T, service *DefaultAuthService, store *sessionStore) {
+				ctx := context.Background()
+				first, err := service.Login(ctx, store.user.Email, "password123")
+				if err != nil {
+					t.Fatalf("login: %v", err)
+				}
+				second, err := service.Refresh(ctx, first.RefreshToken)
+				if err != nil {
+					t.Fatalf("refresh: %v", err)
+				}
+
+				if _, err := service.Refresh(ctx, first.RefreshToken); !errors.Is(err, ErrRefreshTokenReused) {
+					t.Fatalf("expected reuse error, got %v", err)
+				}
+				if _, err := service.Refresh(ctx, second.RefreshToken); !errors.Is(err, ErrInvalidRefreshToken) {
+					t.Fatalf("expected rotated successor to be revoked, got %v", err)
+				}
+				if _, ok, _ := service.ValidateToken(ctx, second.AccessToken); ok {
+					t.Fatal("expected access token of the revoked session to be denylisted")
+				}
+			},
+		},
+		{
+			name: "refresh rejects unknown and expired tokens",
+			run: func(t *testing.T, service *DefaultAuthService, store *sessionStore) {
+				ctx := context.Background()
+				if _, err := service.Refresh(ctx, "unknown"); !errors.Is(err, ErrInvalidRefreshToken) {
+					t.Fatalf("expected invalid refresh token, got %v", err)
+				}
+
+				token, err := service.Login(ctx, store.user.Email, "password123")
+				if err != nil {
+					t.Fatalf("login: %v", err)
+				}
+				stored := store.tokens[hashToken(token.RefreshToken)]
+				stored.ExpiresAt = time.Now().UTC().Add(-time.Minute)
+				store.tokens[stored.TokenHash] = stored
+
+				if _, err := service.Refresh(ctx, token.RefreshToken); !errors.Is(err, ErrInvalidRefreshToken) {
+					t.Fatalf("expected expired refresh token to be rejected, got %v", err)
+				}
+			},
+		},
+		{
+			name: "logout denylists access token and ends session",
+			run: func(t *testing.T, service *DefaultAuthService, store *sessionStore) {
+				ctx := context.Background()
+				token, err := service.Login(ctx, store.user.Email, "password123")
+				if err != nil {
+					t.Fatalf("login: %v", err)
+				}
+
+				if err := service.Logout(ctx, token.AccessToken, token.RefreshToken); err != nil {
+					t.Fatalf("logout: %v", err)
+				}
+				if _, ok, err := service.ValidateToken(ctx, token.AccessToken); err != nil || ok {
+					t.Fatalf("expected access token to be revoked, ok=%v err=%v", ok, err)
+				}
+				if _, err := service.Refresh(ctx, token.RefreshToken); !errors.Is(err, ErrInvalidRefreshToken) {
+					t.Fatalf("expected refresh after logout to fail, got %v", err)
+				}
+				if err := service.Logout(ctx, token.AccessToken, ""); !errors.Is(err, ErrInvalidToken) {
+					t.Fatalf("expected second logout to fail, got %v", err)
+				}
+			},
+		},
+		{
+			name: "list revoked tokens returns new entries",
+			run: func(t *testing.T, service *DefaultAuthService, store *sessionStore) {
+				ctx := context.Background()
+				token, err := service.Login(ctx, store.user.Email, "password123")
+				if err != nil {
+					t.Fatalf("login: %v", err)
+				}
+				if err := service.Logout(ctx, token.AccessToken, ""); err != nil {
+					t.Fatalf("logout: %v", err)
+				}
+
+				revoked, users, asOf, err := service.ListRevokedTokens(ctx, time.Time{})
+				if err != nil {
+					t.Fatalf("list revoked tokens: %v", err)
+				}
+				if len(revoked) != 1 || revoked[0].UserID != store.user.ID || len(users) != 0 {
+					t.Fatalf("expected the logged out token, got %+v and %+v", revoked, users)
+				}
+
+				if _, err := service.RevokeAllSessions(ctx, mustLogin(t, service, store).AccessToken); err != nil {
+					t.Fatalf("revoke all sessions: %v", err)
+				}
+				_, users, _, err = service.ListRevokedTokens(ctx, asOf)
+				if err != nil {
+					t.Fatalf("list revoked tokens: %v", err)
+				}
+				if len(users) != 1 || users[0].UserID != store.user.ID {
+					t.Fatalf("expected the cutoff of the user, got %+v", users)
+				}
+
+				_, _, asOf, err = service.ListRevokedTokens(ctx, asOf)
+				if err != nil {
+					t.Fatalf("list revoked tokens: %v", err)
+				}
+				revoked, users, _, err = service.ListRevokedTokens(ctx, asOf)
+				if err != nil {
+					t.Fatalf("list revoked tokens: %v", err)
+				}
+				if len(revoked) != 0 || len(users) != 0 {
+					t.Fatalf("expected nothing revoked after %s, got %d tokens and %d users", asOf, len(revoked), len(users))
+				}
+			},
+		},
+		{
+			name: "revoke all sessions",
+			run: func(t *testing.T, service *DefaultAuthService, store *sessionStore) {
+				ctx := context.Background()
+				first, err := service.Login(ctx, store.user.Email, "password123")
+				if err != nil {
+					t.Fatalf("login: %v", err)
+				}
+				second, err := service.Login(ctx, store.user.Email, "password123")
+				if err != nil {
+					t.Fatalf("login: %v", err)
+				}
+				// A token that belongs to no stored session is revoked by the cutoff only.
+				unsessioned := makeTokenIssuedAt(t, service.keys, store.user.ID, time.Now().UTC().Add(-time.Minute))
+
+				revoked, err := service.RevokeAllSessions(ctx, first.AccessToken)
+				if err != nil {
+					t.Fatalf("revoke all sessions: %v", err)
+				}
+				if revoked != 2 {
+					t.Fatalf("expected 2 revoked sessions, got %d", revoked)
+				}
+				for _, token := range []model.Token{first, second} {
+					if _, ok, _ := service.ValidateToken(ctx, token.AccessToken); ok {
+						t.Fatal("expected access tokens of all sessions to be revoked")
+					}
+					if _, err := service.Refresh(ctx, token.RefreshToken); !errors.Is(err, ErrInvalidRefreshToken) {
+						t.Fatalf("expected refresh to fail, got %v", err)
+					}
+				}
+				if _, ok, _ := service.ValidateToken(ctx, unsessioned); ok {
+					t.Fatal("expected an access token issued before the revocation to be rejected")
+				}
+				if _, ok, err := service.ValidateToken(ctx, mustLogin(t, service, store).AccessToken); err != nil || !ok {
+					t.Fatalf("expected a new session to work, ok=%v err=%v", ok, err)
+				}
+			},
+		},
+		{
+			name: "validate token rejects tokens without jti",
+			run: func(t *testing.T, service *DefaultAuthService, store *sessionStore) {
+				token := makeTokenWithoutID(t, service.keys, store.user.ID, time.Now().UTC().Add(time.Minute))
+				if _, ok, err := service.ValidateToken(context.Background(), token); err != nil || ok {
+					t.Fatalf("expected token without jti to be rejected, ok=%v err=%v", ok, err)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := minimock.NewController(t)
+			repo := repository.NewAuthRepositoryMock(ctrl)
+			store := newSessionStore(t, repo)
+			jwtConfig := testJWTConfig()
+			tt.run(t, NewAuthService(repo, jwtConfig, newTestKeyring(t, jwtConfig), store.mail, testEmailConfig(), testTwoFactorConfig(), testLoginThrottleConfig(), newTestPasswordPolicy(t), testOIDCConfig()), store)
+		})
+	}
+}
+
+// sessionStore backs the token methods of the repository mock with maps.
+type sessionStore struct {
+	user    model.User
+	deleted bool
+	purges  []string
+	tokens  map[string]model.RefreshToken
+	revoked map[string]model.RevokedToken
+	// revokedUsers are the access token cutoffs by user.
+	revokedUsers map[string]model.RevokedUser
+	// emailTokens are the verification and reset tokens by hash.
+	emailTokens map[string]model.UserToken
+	// recoveryCodes tells by hash whether a recovery code has been used.
+	recoveryCodes map[string]bool
+	// loginFailures are the failed sign-in counters by key, guarded by
+	// loginMu for concurrent sign-ins.
+	loginMu       sync.Mutex
+	loginFailures map[string]model.LoginFailure
+	// identities are the linked external identities by provider and subject.
+	identities map[string]model.Identity
+	oidcLogins map[string]model.OIDCLogin
+	mail       *recordingMailer
+}
+
+func newSessionStore(t *testing.T, repo *repository.AuthRepositoryMock) *sessionStore {
+	t.Helper()
+	store := &sessionStore{
+		user: model.User{
+			ID:           uuid.NewString(),
+			Email:        "user@example.com",
+			PasswordHash: mustHashPassword(t, "password123"),
+			Roles:        []string{model.RoleUser},
+		},
+		tokens:        make(map[string]model.RefreshToken),
+		revoked:       make(map[string]model.RevokedToken),
+		revokedUsers:  make(map[string]model.RevokedUser),
+		emailTokens:   make(map[string]model.UserToken),
+		recoveryCodes: make(map[string]bool),
+		loginFailures: make(map[string]model.LoginFailure),
+		identities:    make(map[string]model.Identity),
+		oidcLogins:    make(map[string]model.OIDCLogin),
+		mail:          &recordingMailer{},
+	}
+
+	repo.GetUserByEmailFn = func(ctx context.Context, email string) (model.User, error) {
+		if email != store.user.Email {
+			return model.User{}, storage.ErrNotFound
+		}
+		return store.user, nil
+	}
+	repo.GetUserByIDFn = func(ctx context.Context, id string) (model.User, error) {
+		if id != store.user.ID || store.deleted {
+			return model.User{}, storage.ErrNotFound
+		}
+		return store.user, nil
+	}
+	repo.UpdateUserFn = func(ctx context.Context, user model.User) (model.User, error) {
+		if user.ID != store.user.ID || store.deleted {
+			return model.User{}, storage.ErrNotFound
+		}
+		store.user.Name = user.Name
+		store.user.PasswordHash = user.PasswordHash
+		store.user.UpdatedAt = user.UpdatedAt
+		return store.user, nil
+	}
+	repo.DeleteUserFn = func(ctx context.Context, id string, requestedAt time.Time) error {
+		if id != store.user.ID || store.deleted {
+			return storage.ErrNotFound
+		}
+		store.deleted = true
+		store.purges = append(store.purges, id)
+		// Refresh tokens are deleted with the user.
+		for hash, token := range store.tokens {
+			if token.UserID == id {
+				delete(store.tokens, hash)
+			}
+		}
+		return nil
+	}
+	repo.SetUserRolesFn = func(ctx context.Context, userID string, roles []string, updatedAt time.Time) (model.User, error) {
+		if userID != store.user.ID || store.deleted {
+			return model.User{}, storage.ErrNotFound
+		}
+		store.user.Roles = roles
+		store.user.UpdatedAt = updatedAt
+		return store.user, nil
+	}
+	repo.MarkEmailVerifiedFn = func(ctx context.Context, userID string, verifiedAt time.Time) error {
+		if userID != store.user.ID || store.deleted {
+			return storage.ErrNotFound
+		}
+		if store.user.EmailVerifiedAt == nil {
+			store.user.EmailVerifiedAt = &verifiedAt
+		}
+		return nil
+	}
+	repo.SetTOTPSecretFn = func(ctx context.Context, userID, secret string, updatedAt time.Time) error {
+		if userID != store.user.ID || store.deleted || store.user.TwoFactorEnabled() {
+			return storage.ErrNotFound
+		}
+		store.user.TOTPSecret = secret
+		return nil
+	}
+	repo.EnableTOTPFn = func(ctx context.Context, userID string, step int64, enabledAt time.Time, recoveryCodeHashes []string) error {
+		if userID != store.user.ID || store.deleted || store.user.TOTPSecret == "" || store.user.TwoFactorEnabled() {
+			return storage.ErrNotFound
+		}
+		store.user.TOTPEnabledAt = &enabledAt
+		store.user.TOTPLastStep = step
+		store.recoveryCodes = make(map[string]bool)
+		for _, hash := range recoveryCodeHashes {
+			store.recoveryCodes[hash] = false
+		}
+		return nil
+	}
+	repo.DisableTOTPFn = func(ctx context.Context, userID string, updatedAt time.Time) error {
+		if userID != store.user.ID || store.deleted {
+			return storage.ErrNotFound
+		}
+		store.user.TOTPSecret = ""
+		store.user.TOTPEnabledAt = nil
+		store.user.TOTPLastStep = 0
+		store.recoveryCodes = make(map[string]bool)
+		return nil
+	}
+	repo.UseTOTPStepFn = func(ctx context.Context, userID string, step int64) error {
+		if userID != store.user.ID || !store.user.TwoFactorEnabled() || store.user.TOTPLastStep >= step {
+			return storage.ErrNotFound
+		}
+		store.user.TOTPLastStep = step
+		return nil
+	}
+	repo.UseRecoveryCodeFn = func(ctx context.Context, userID, codeHash string, usedAt time.Time) error {
+		used, ok := store.recoveryCodes[codeHash]
+		if userID != store.user.ID || !ok || used {
+			return storage.ErrNotFound
+		}
+		store.recoveryCodes[codeHash] = true
+		return nil
+	}
+	repo.ListLoginFailuresFn = func(ctx context.Context, keys []string, since time.Time) ([]model.LoginFailure, error) {
+		store.loginMu.Lock()
+		defer store.loginMu.Unlock()
+		var failures []model.LoginFailure
+		for _, key := range keys {
+			if failure, ok := store.loginFailures[key]; ok && !failure.LastFailureAt.Before(since) {
+				failures = append(failures, failure)
+			}
+		}
+		return failures, nil
+	}
+	repo.RecordLoginFailureFn = func(ctx context.Context, key string, at, windowStart time.Time) (model.LoginFailure, error) {
+		store.loginMu.Lock()
+		defer store.loginMu.Unlock()
+		failure := store.loginFailures[key]
+		if failure.LastFailureAt.Before(windowStart) {
+			failure.Failures = 0
+		}
+		failure.Key = key
+		failure.Failures++
+		failure.LastFailureAt = at
+		store.loginFailures[key] = failure
+		return failure, nil
+	}
+	repo.ReleaseLoginFailureFn = func(ctx context.Context, key string) error {
+		store.loginMu.Lock()
+		defer store.loginMu.Unlock()
+		if failure, ok := store.loginFailures[key]; ok && failure.Failures > 0 {
+			failure.Failures--
+			store.loginFailures[key] = failure
+		}
+		return nil
+	}
+	repo.ClearLoginFailuresFn = func(ctx context.Context, keys []string) (int64, error) {
+		store.loginMu.Lock()
+		defer store.loginMu.Unlock()
+		var cleared int64
+		for _, key := range keys {
+			if _, ok := store.loginFailures[key]; ok {
+				delete(store.loginFailures, key)
+				cleared++
+			}
+		}
+		return cleared, nil
+	}
+	repo.CreateUserTokenFn = func(ctx context.Context, token model.UserToken) error {
+		for hash, existing := range store.emailTokens {
+			if existing.UserID == token.UserID && existing.Purpose == token.Purpose && existing.UsedAt == nil {
+				delete(store.emailTokens, hash)
+			}
+		}
+		store.emailTokens[token.TokenHash] = token
+		return nil
+	}
+	repo.GetUserTokenFn = func(ctx context.Context, purpose, tokenHash string, now time.Time) (model.UserToken, error) {
+		token, ok := store.emailTokens[tokenHash]
+		if !ok || token.Purpose != purpose || token.UsedAt != nil || !token.ExpiresAt.After(now) {
+			return model.UserToken{}, storage.ErrNotFound
+		}
+		return token, nil
+	}
+	repo.ConsumeUserTokenFn = func(ctx context.Context, purpose, tokenHash string, now time.Time) (model.UserToken, error) {
+		token, ok := store.emailTokens[tokenHash]
+		if !ok || token.Purpose != purpose || token.UsedAt != nil || !token.ExpiresAt.After(now) {
+			return model.UserToken{}, storage.ErrNotFound
+		}
+		token.UsedAt = &now
+		store.emailTokens[tokenHash] = token
+		return token, nil
+	}
+	repo.GetIdentityFn = func(ctx context.Context, provider, subject string) (model.Identity, error) {
+		identity, ok := store.identities[provider+"|"+subject]
+		if !ok {
+			return model.Identity{}, storage.ErrNotFound
+		}
+		return identity, nil
+	}
+	repo.CreateIdentityFn = func(ctx context.Context, identity model.Identity) error {
+		key := identity.Provider + "|" + identity.Subject
+		if _, ok := store.identities[key]; ok {
+			return storage.ErrDuplicate
+		}
+		store.identities[key] = identity
+		return nil
+	}
+	// The store holds a single user, so a created user replaces it.
+	repo.CreateUserWithIdentityFn = func(ctx context.Context, user model.User, identity model.Identity) (model.User, error) {
+		if user.Email == store.user.Email && !store.deleted {
+			return model.User{}, storage.ErrDuplicate
+		}
+		store.user = user
+		store.deleted = false
+		store.identities[identity.Provider+"|"+identity.Subject] = identity
+		return user, nil
+	}
+	repo.UpdateIdentityLoginFn = func(ctx context.Context, id, email string, at time.Time) error {
+		for key, identity := range store.identities {
+			if identity.ID == id {
+				identity.Email = email
+				identity.LastLoginAt = at
+				store.identities[key] = identity
+				return nil
+			}
+		}
+		return storage.ErrNotFound
+	}
+	repo.CreateOIDCLoginFn = func(ctx context.Context, login model.OIDCLogin) error {
+		store.oidcLogins[login.StateHash] = login
+		return nil
+	}
+	repo.ConsumeOIDCLoginFn = func(ctx context.Context, provider, stateHash string, now time.Time) (model.OIDCLogin, error) {
+		login, ok := store.oidcLogins[stateHash]
+		if !ok || login.Provider != provider || !login.ExpiresAt.After(now) {
+			return model.OIDCLogin{}, storage.ErrNotFound
+		}
+		delete(store.oidcLogins, stateHash)
+		return login, nil
+	}
+	repo.CreateRefreshTokenFn = func(ctx context.Context, token model.RefreshToken) error {
+		store.tokens[token.TokenHash] = token
+		return nil
+	}
+	repo.GetRefreshTokenByHashFn = func(ctx context.Context, tokenHash string) (model.RefreshToken, error) {
+		token, ok := store.tokens[tokenHash]
+		if !ok {
+			return model.RefreshToken{}, storage.ErrNotFound
+		}
+		return token, nil
+	}
+	repo.RotateRefreshTokenFn = func(ctx context.Context, oldID string, next model.RefreshToken) error {
+		for hash, token := range store.tokens {
+			if token.ID == oldID && token.RevokedAt == nil {
+				now := time.Now().UTC()
+				token.RevokedAt = &now
+				token.ReplacedBy = next.ID
+				store.tokens[hash] = token
+				store.tokens[next.TokenHash] = next
+				return nil
+			}
+		}
+		return storage.ErrNotFound
+	}
+	revokeWhere := func(match func(model.RefreshToken) bool) int64 {
+		now := time.Now().UTC()
+		var revoked int64
+		for hash, token := range store.tokens {
+			if !match(token) {
+				continue
+			}
+			if token.AccessExpiresAt.After(now) {
+				store.revoked[token.AccessTokenID] = model.RevokedToken{ID: token.AccessTokenID, UserID: token.UserID, ExpiresAt: token.AccessExpiresAt}
+			}
+			if token.RevokedAt == nil {
+				token.RevokedAt = &now
+				store.tokens[hash] = token
+				revoked++
+			}
+		}
+		return revoked
+	}
+	repo.RevokeRefreshTokenFamilyFn = func(ctx context.Context, familyID string) error {
+		revokeWhere(func(token model.RefreshToken) bool { return token.FamilyID == familyID })
+		return nil
+	}
+	repo.RevokeUserRefreshTokensFn = func(ctx context.Context, userID string) (int64, error) {
+		return revokeWhere(func(token model.RefreshToken) bool { return token.UserID == userID }), nil
+	}
+	repo.RevokeAccessTokenFn = func(ctx context.Context, token model.RevokedToken) error {
+		token.RevokedAt = time.Now().UTC()
+		store.revoked[token.ID] = token
+		return nil
+	}
+	repo.ListRevokedTokensFn = func(ctx context.Context, since time.Time) ([]model.RevokedToken, error) {
+		var tokens []model.RevokedToken
+		for _, token := range store.revoked {
+			if token.RevokedAt.After(since) {
+				tokens = append(tokens, token)
+			}
+		}
+		return tokens, nil
+	}
+	repo.RevokeUserAccessTokensFn = func(ctx context.Context, user model.RevokedUser) error {
+		if existing, ok := store.revokedUsers[user.UserID]; ok && existing.RevokedBefore.After(user.RevokedBefore) {
+			user.RevokedBefore = existing.RevokedBefore
+		}
+		user.RevokedAt = time.Now().UTC()
+		store.revokedUsers[user.UserID] = user
+		return nil
+	}
+	repo.ListRevokedUsersFn = func(ctx context.Context, since time.Time) ([]model.RevokedUser, error) {
+		var users []model.RevokedUser
+		for _, user := range store.revokedUsers {
+			if user.RevokedAt.After(since) {
+				users = append(users, user)
+			}
+		}
+		return users, nil
+	}
+	repo.IsAccessTokenRevokedFn = func(ctx context.Context, tokenID, userID string, issuedAt time.Time) (bool, error) {
+		if _, ok := store.revoked[tokenID]; ok {
+			return true, nil
+		}
+		user, ok := store.revokedUsers[userID]
+		return ok && user.RevokedBefore.After(issuedAt), nil
+	}
+
+	return store
+}
@@ -0,0 +1,220 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/Deevins/final-task-course-2-go-lang/auth/internal/model"
+	"github.com/Deevins/final-task-course-2-go-lang/auth/internal/totp"
+)
+
+const (
+	recoveryCodeCount = 10
+	// recoveryCodeBytes is enough randomness for the 10 base32 characters,
+	// i.e. 50 bits, of a recovery code.
+	recoveryCodeBytes = 7
+)
+
+var recoveryCodeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// EnrollTOTP generates a new authenticator secret for the token owner. The
+// secret has no effect until ConfirmTOTP is called with a code it produced,
+// and enrolling again replaces a pending secret.
+func (s *DefaultAuthService) EnrollTOTP(ctx context.Context, accessToken string) (model.TOTPEnrollment, error) {
+	user, err := s.GetMe(ctx, accessToken)
+	if err != nil {
+		return model.TOTPEnrollment{}, err
+	}
+	if user.TwoFactorEnabled() {
+		return model.TOTPEnrollment{}, ErrTwoFactorEnabled
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return model.TOTPEnrollment{}, err
+	}
+	if err := s.repo.SetTOTPSecret(ctx, user.ID, secret, time.Now().UTC()); err != nil {
+		if IsNotFound(err) {
+			// Enabled concurrently.
+			return model.TOTPEnrollment{}, ErrTwoFactorEnabled
+		}
+		return model.TOTPEnrollment{}, err
+	}
+
+	return model.TOTPEnrollment{
+		Secret:          secret,
+		ProvisioningURI: totp.ProvisioningURI(s.twoFactorConfig.Issuer, user.Email, secret),
+	}, nil
+}
+
+// ConfirmTOTP enables two-factor authentication once the user proves the
+// authenticator app works, and returns new recovery codes. The codes are
+// only stored hashed and cannot be shown again.
+func (s *DefaultAuthService) ConfirmTOTP(ctx context.Context, accessToken, code string) ([]string, error) {
+	user, err := s.GetMe(ctx, accessToken)
+	if err != nil {
+		return nil, err
+	}
+	if user.TwoFactorEnabled() {
+		return nil, ErrTwoFactorEnabled
+	}
+	if user.TOTPSecret == "" {
+		return nil, ErrTwoFactorNotEnrolled
+	}
+
+	now := time.Now().UTC()
+	step, ok := totp.Validate(user.TOTPSecret, code, now, s.twoFactorConfig.Skew)
+	if !ok {
+		return nil, ErrInvalidTwoFactorCode
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.EnableTOTP(ctx, user.ID, step, now, hashes); err != nil {
+		if IsNotFound(err) {
+			return nil, ErrTwoFactorEnabled
+		}
+		return nil, err
+	}
+	return codes, nil
+}
+
+// DisableTOTP turns two-factor authentication off. Both the password and a
+// current code or an unused recovery code are required.
+func (s *DefaultAuthService) DisableTOTP(ctx context.Context, accessToken, password, code string) error {
+	user, err := s.GetMe(ctx, accessToken)
+	if err != nil {
+		return err
+	}
+	if !user.TwoFactorEnabled() {
+		return ErrTwoFactorNotEnabled
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+		return ErrWrongPassword
+	}
+	if err := s.checkSecondFactor(ctx, user, code); err != nil {
+		return err
+	}
+
+	return s.repo.DisableTOTP(ctx, user.ID, time.Now().UTC())
+}
+
+// VerifyTwoFactor completes a sign-in started by Login. The challenge works
+// once: after a wrong code the user signs in with the password again, which
+// keeps guessing codes as expensive as guessing the password.
+func (s *DefaultAuthService) VerifyTwoFactor(ctx context.Context, challengeToken, code string) (model.Token, error) {
+	stored, err := s.repo.ConsumeUserToken(ctx, model.TokenPurposeSignInChallenge, hashToken(challengeToken), time.Now().UTC())
+	if err != nil {
+		if IsNotFound(err) {
+			return model.Token{}, ErrInvalidChallenge
+		}
+		return model.Token{}, err
+	}
+	user, err := s.repo.GetUserByID(ctx, stored.UserID)
+	if err != nil {
+		if IsNotFound(err) {
+			return model.Token{}, ErrInvalidChallenge
+		}
+		return model.Token{}, err
+	}
+	if !user.TwoFactorEnabled() {
+		// Disabled after the challenge was issued; a new sign-in needs no code.
+		return model.Token{}, ErrInvalidChallenge
+	}
+
+	// Wrong codes count as failed sign-in attempts, so the password cannot
+	// be used to request challenges for guessing codes without limits.
+	keys := loginThrottleKeys(ctx, user.Email)
+	if err := s.beginLoginAttempt(ctx, keys); err != nil {
+		return model.Token{}, err
+	}
+	if err := s.checkSecondFactor(ctx, user, code); err != nil {
+		if !errors.Is(err, ErrInvalidTwoFactorCode) {
+			if releaseErr := s.finishLoginAttempt(ctx, keys, false); releaseErr != nil {
+				return model.Token{}, releaseErr
+			}
+		}
+		return model.Token{}, err
+	}
+
+	if err := s.finishLoginAttempt(ctx, keys, true); err != nil {
+		return model.Token{}, err
+	}
+	return s.startSession(ctx, user)
+}
+
+// startChallenge is the first step of a two-factor sign-in.
+func (s *DefaultAuthService) startChallenge(ctx context.Context, user model.User) (model.Token, error) {
+	ttl := s.twoFactorConfig.ChallengeTTL
+	token, err := s.createUserToken(ctx, user.ID, model.TokenPurposeSignInChallenge, ttl)
+	if err != nil {
+		return model.Token{}, err
+	}
+	return model.Token{
+		ChallengeToken:     token,
+		ChallengeExpiresAt: time.Now().UTC().Add(ttl),
+	}, nil
+}
+
+// checkSecondFactor accepts either a current TOTP code that was not used
+// before or an unused recovery code, which is used up.
+func (s *DefaultAuthService) checkSecondFactor(ctx context.Context, user model.User, code string) error {
+	code = strings.TrimSpace(code)
+	if len(code) == totp.Digits {
+		step, ok := totp.Validate(user.TOTPSecret, code, time.Now().UTC(), s.twoFactorConfig.Skew)
+		if !ok || step <= user.TOTPLastStep {
+			return ErrInvalidTwoFactorCode
+		}
+		if err := s.repo.UseTOTPStep(ctx, user.ID, step); err != nil {
+			if IsNotFound(err) {
+				return ErrInvalidTwoFactorCode
+			}
+			return err
+		}
+		return nil
+	}
+
+	normalized := normalizeRecoveryCode(code)
+	if normalized == "" {
+		return ErrInvalidTwoFactorCode
+	}
+	if err := s.repo.UseRecoveryCode(ctx, user.ID, hashToken(normalized), time.Now().UTC()); err != nil {
+		if IsNotFound(err) {
+			return ErrInvalidTwoFactorCode
+		}
+		return err
+	}
+	return nil
+}
+
+// generateRecoveryCodes returns codes formatted as xxxxx-xxxxx together with
+// the hashes of their normalized form.
+func generateRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	for range recoveryCodeCount {
+		value := make([]byte, recoveryCodeBytes)
+		if _, err := rand.Read(value); err != nil {
+			return nil, nil, fmt.Errorf("generate recovery code: %w", err)
+		}
+		raw := strings.ToLower(recoveryCodeEncoding.EncodeToString(value))[:10]
+		codes = append(codes, raw[:5]+"-"+raw[5:])
+		hashes = append(hashes, hashToken(raw))
+	}
+	return codes, hashes, nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	code = strings.ReplaceAll(code, "-", "")
+	return strings.ReplaceAll(code, " ", "")
+}
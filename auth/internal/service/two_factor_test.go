@@ -0,0 +1,211 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gojuno/minimock/v3"
+
+	"github.com/Deevins/final-task-course-2-go-lang/auth/internal/model"
+	"github.com/Deevins/final-task-course-2-go-lang/auth/internal/repository"
+	"github.com/Deevins/final-task-course-2-go-lang/auth/internal/totp"
+)
+
+func TestAuthServiceTwoFactor(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T, service *DefaultAuthService, store *sessionStore)
+	}{
+		{
+			name: "enrollment requires a valid code",
+			run: func(t *testing.T, service *DefaultAuthService, store *sessionStore) {
+				ctx := context.Background()
+				session := mustLogin(t, service, store)
+
+				if _, err := service.ConfirmTOTP(ctx, session.AccessToken, "123456"); !errors.Is(err, ErrTwoFactorNotEnrolled) {
+					t.Fatalf("expected enrollment to be required, got %v", err)
+				}
+				enrollment, err := service.EnrollTOTP(ctx, session.AccessToken)
+				if err != nil {
+					t.Fatalf("enroll: %v", err)
+				}
+				if !strings.HasPrefix(enrollment.ProvisioningURI, "otpauth://totp/Ledger:user@example.com?") ||
+					!strings.Contains(enrollment.ProvisioningURI, "secret="+enrollment.Secret) {
+					t.Fatalf("unexpected provisioning uri %q", enrollment.ProvisioningURI)
+				}
+				if _, err := service.ConfirmTOTP(ctx, session.AccessToken, "abcdef"); !errors.Is(err, ErrInvalidTwoFactorCode) {
+					t.Fatalf("expected invalid code, got %v", err)
+				}
+				if store.user.TwoFactorEnabled() {
+					t.Fatal("expected two-factor to stay disabled until confirmed")
+				}
+
+				recoveryCodes, err := service.ConfirmTOTP(ctx, session.AccessToken, codeAt(t, enrollment.Secret, totp.Step(time.Now())))
+				if err != nil {
+					t.Fatalf("confirm: %v", err)
+				}
+				if len(recoveryCodes) != recoveryCodeCount || len(store.recoveryCodes) != recoveryCodeCount {
+					t.Fatalf("expected %d recovery codes, got %d", recoveryCodeCount, len(recoveryCodes))
+				}
+				if _, ok := store.recoveryCodes[hashToken(normalizeRecoveryCode(recoveryCodes[0]))]; !ok {
+					t.Fatal("expected recovery codes to be stored hashed")
+				}
+				if _, err := service.EnrollTOTP(ctx, session.AccessToken); !errors.Is(err, ErrTwoFactorEnabled) {
+					t.Fatalf("expected enabled two-factor to block enrollment, got %v", err)
+				}
+				user, err := service.GetMe(ctx, session.AccessToken)
+				if err != nil || !user.TwoFactorEnabled() {
+					t.Fatalf("expected two-factor to be enabled, user=%+v err=%v", user, err)
+				}
+			},
+		},
+		{
+			name: "sign in returns a challenge that needs a fresh code",
+			run: func(t *testing.T, service *DefaultAuthService, store *sessionStore) {
+				ctx := context.Background()
+				secret, step, _ := enableTwoFactor(t, service, store)
+
+				challenge, err := service.Login(ctx, store.user.Email, "password123")
+				if err != nil {
+					t.Fatalf("login: %v", err)
+				}
+				if challenge.ChallengeToken == "" || challenge.AccessToken != "" || challenge.RefreshToken != "" {
+					t.Fatalf("expected only a challenge, got %+v", challenge)
+				}
+
+				// The code that confirmed the enrollment cannot be replayed, and a
+				// failed attempt uses up the challenge.
+				if _, err := service.VerifyTwoFactor(ctx, challenge.ChallengeToken, codeAt(t, secret, step)); !errors.Is(err, ErrInvalidTwoFactorCode) {
+					t.Fatalf("expected replayed code to be rejected, got %v", err)
+				}
+				if _, err := service.VerifyTwoFactor(ctx, challenge.ChallengeToken, codeAt(t, secret, step+1)); !errors.Is(err, ErrInvalidChallenge) {
+					t.Fatalf("expected used challenge to be rejected, got %v", err)
+				}
+
+				challenge, err = service.Login(ctx, store.user.Email, "password123")
+				if err != nil {
+					t.Fatalf("login: %v", err)
+				}
+				// A code of the next step is accepted within the skew window.
+				token, err := service.VerifyTwoFactor(ctx, challenge.ChallengeToken, codeAt(t, secret, step+1))
+				if err != nil {
+					t.Fatalf("verify: %v", err)
+				}
+				if _, ok, err := service.ValidateToken(ctx, token.AccessToken); err != nil || !ok {
+					t.Fatalf("expected a valid access token, ok=%v err=%v", ok, err)
+				}
+				if _, err := service.Refresh(ctx, token.RefreshToken); err != nil {
+					t.Fatalf("refresh: %v", err)
+				}
+			},
+		},
+		{
+			name: "recovery codes work once",
+			run: func(t *testing.T, service *DefaultAuthService, store *sessionStore) {
+				ctx := context.Background()
+				_, _, recoveryCodes := enableTwoFactor(t, service, store)
+
+				challenge, err := service.Login(ctx, store.user.Email, "password123")
+				if err != nil {
+					t.Fatalf("login: %v", err)
+				}
+				if _, err := service.VerifyTwoFactor(ctx, challenge.ChallengeToken, strings.ToUpper(recoveryCodes[0])); err != nil {
+					t.Fatalf("verify with recovery code: %v", err)
+				}
+
+				challenge, err = service.Login(ctx, store.user.Email, "password123")
+				if err != nil {
+					t.Fatalf("login: %v", err)
+				}
+				if _, err := service.VerifyTwoFactor(ctx, challenge.ChallengeToken, recoveryCodes[0]); !errors.Is(err, ErrInvalidTwoFactorCode) {
+					t.Fatalf("expected used recovery code to be rejected, got %v", err)
+				}
+			},
+		},
+		{
+			name: "disable requires password and code",
+			run: func(t *testing.T, service *DefaultAuthService, store *sessionStore) {
+				ctx := context.Background()
+				secret, step, recoveryCodes := enableTwoFactor(t, service, store)
+				challenge, err := service.Login(ctx, store.user.Email, "password123")
+				if err != nil {
+					t.Fatalf("login: %v", err)
+				}
+				session, err := service.VerifyTwoFactor(ctx, challenge.ChallengeToken, recoveryCodes[0])
+				if err != nil {
+					t.Fatalf("verify: %v", err)
+				}
+
+				if err := service.DisableTOTP(ctx, session.AccessToken, "wrong-password", recoveryCodes[1]); !errors.Is(err, ErrWrongPassword) {
+					t.Fatalf("expected wrong password, got %v", err)
+				}
+				if err := service.DisableTOTP(ctx, session.AccessToken, "password123", codeAt(t, secret, step-5)); !errors.Is(err, ErrInvalidTwoFactorCode) {
+					t.Fatalf("expected invalid code, got %v", err)
+				}
+				if err := service.DisableTOTP(ctx, session.AccessToken, "password123", recoveryCodes[1]); err != nil {
+					t.Fatalf("disable: %v", err)
+				}
+				if store.user.TwoFactorEnabled() || len(store.recoveryCodes) != 0 {
+					t.Fatal("expected two-factor state to be removed")
+				}
+
+				token, err := service.Login(ctx, store.user.Email, "password123")
+				if err != nil || token.AccessToken == "" {
+					t.Fatalf("expected a session without challenge, token=%+v err=%v", token, err)
+				}
+				if err := service.DisableTOTP(ctx, token.AccessToken, "password123", recoveryCodes[2]); !errors.Is(err, ErrTwoFactorNotEnabled) {
+					t.Fatalf("expected two-factor to be disabled, got %v", err)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := minimock.NewController(t)
+			repo := repository.NewAuthRepositoryMock(ctrl)
+			store := newSessionStore(t, repo)
+			jwtConfig := testJWTConfig()
+			tt.run(t, NewAuthService(repo, jwtConfig, newTestKeyring(t, jwtConfig), store.mail, testEmailConfig(), testTwoFactorConfig(), testLoginThrottleConfig(), newTestPasswordPolicy(t), testOIDCConfig()), store)
+		})
+	}
+}
+
+func mustLogin(t *testing.T, service *DefaultAuthService, store *sessionStore) model.Token {
+	t.Helper()
+	token, err := service.Login(context.Background(), store.user.Email, "password123")
+	if err != nil {
+		t.Fatalf("login: %v", err)
+	}
+	return token
+}
+
+// enableTwoFactor enrolls the store user with a code of the current step and
+// returns the secret, that step and the recovery codes.
+func enableTwoFactor(t *testing.T, service *DefaultAuthService, store *sessionStore) (string, int64, []string) {
+	t.Helper()
+	ctx := context.Background()
+	session := mustLogin(t, service, store)
+	enrollment, err := service.EnrollTOTP(ctx, session.AccessToken)
+	if err != nil {
+		t.Fatalf("enroll: %v", err)
+	}
+	step := totp.Step(time.Now())
+	recoveryCodes, err := service.ConfirmTOTP(ctx, session.AccessToken, codeAt(t, enrollment.Secret, step))
+	if err != nil {
+		t.Fatalf("confirm: %v", err)
+	}
+	return enrollment.Secret, step, recoveryCodes
+}
+
+func codeAt(t *testing.T, secret string, step int64) string {
+	t.Helper()
+	code, err := totp.Code(secret, step)
+	if err != nil {
+		t.Fatalf("generate code: %v", err)
+	}
+	return code
+}
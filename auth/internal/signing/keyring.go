@@ -0,0 +1,299 @@
+package signing
+
+import (
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+
+	"github.com/Deevins/final-task-course-2-go-lang/auth/internal/config"
+	"github.com/Deevins/final-task-course-2-go-lang/auth/internal/model"
+)
+
+// Signing algorithms supported for access tokens.
+const (
+	AlgorithmRS256 = "RS256"
+	AlgorithmEdDSA = "EdDSA"
+)
+
+const (
+	rsaKeyBits = 2048
+	// reloadCooldown limits how often an unknown kid can trigger a reload.
+	reloadCooldown = 5 * time.Second
+)
+
+var ErrNoSigningKey = errors.New("no active signing key")
+
+// Store persists signing keys so that every auth instance signs with the same
+// keys and tokens survive restarts.
+type Store interface {
+	ListSigningKeys(ctx context.Context) ([]model.SigningKey, error)
+	CreateSigningKey(ctx context.Context, key model.SigningKey) error
+	DeleteExpiredSigningKeys(ctx context.Context, before time.Time) (int64, error)
+}
+
+type key struct {
+	id        string
+	method    jwt.SigningMethod
+	private   crypto.Signer
+	retiresAt time.Time
+	expiresAt time.Time
+}
+
+// Keyring holds the signing keys loaded from the store. New tokens are signed
+// with the newest active key and carry its id in the kid header; tokens signed
+// with retired keys are accepted until the key expires.
+type Keyring struct {
+	store     Store
+	algorithm string
+	rotation  time.Duration
+	grace     time.Duration
+
+	mu       sync.RWMutex
+	keys     []key
+	loadedAt time.Time
+}
+
+func NewKeyring(store Store, cfg config.JWTConfig) *Keyring {
+	return &Keyring{
+		store:     store,
+		algorithm: cfg.Algorithm,
+		rotation:  cfg.KeyRotation,
+		grace:     cfg.KeyGracePeriod,
+	}
+}
+
+// Load replaces the in-memory keys with the ones from the store.
+func (k *Keyring) Load(ctx context.Context) error {
+	stored, err := k.store.ListSigningKeys(ctx)
+	if err != nil {
+		return fmt.Errorf("list signing keys: %w", err)
+	}
+
+	keys := make([]key, 0, len(stored))
+	for _, item := range stored {
+		parsed, err := parseKey(item)
+		if err != nil {
+			return fmt.Errorf("parse signing key %s: %w", item.ID, err)
+		}
+		keys = append(keys, parsed)
+	}
+
+	k.mu.Lock()
+	k.keys = keys
+	k.loadedAt = time.Now()
+	k.mu.Unlock()
+	return nil
+}
+
+// Rotate loads the keys and creates a new one when no key of the configured
+// algorithm is active. Expired keys are removed from the store.
+func (k *Keyring) Rotate(ctx context.Context) error {
+	if err := k.Load(ctx); err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	if _, err := k.current(now); err == nil {
+		return nil
+	}
+
+	created, err := generateKey(k.algorithm, now, k.rotation, k.grace)
+	if err != nil {
+		return err
+	}
+	if err := k.store.CreateSigningKey(ctx, created); err != nil {
+		return fmt.Errorf("create signing key: %w", err)
+	}
+	if _, err := k.store.DeleteExpiredSigningKeys(ctx, now); err != nil {
+		return fmt.Errorf("delete expired signing keys: %w", err)
+	}
+	log.Printf("created %s signing key %s", created.Algorithm, created.ID)
+
+	return k.Load(ctx)
+}
+
+// Run rotates keys every interval until the context is canceled. Rotating
+// also picks up keys created by other instances.
+func (k *Keyring) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := k.Rotate(ctx); err != nil {
+				log.Printf("signing key rotation error: %v", err)
+			}
+		}
+	}
+}
+
+// Sign signs the claims with the current key.
+func (k *Keyring) Sign(claims jwt.Claims) (string, error) {
+	current, err := k.current(time.Now().UTC())
+	if err != nil {
+		return "", err
+	}
+
+	token := jwt.NewWithClaims(current.method, claims)
+	token.Header["kid"] = current.id
+	return token.SignedString(current.private)
+}
+
+// Keyfunc resolves the verification key from the kid header. An unknown kid
+// triggers a reload, at most once per reloadCooldown, since another instance
+// may have rotated the keys.
+func (k *Keyring) Keyfunc(ctx context.Context) jwt.Keyfunc {
+	return func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		if kid == "" {
+			return nil, errors.New("token has no kid")
+		}
+
+		found, ok := k.lookup(kid)
+		if !ok && k.canReload() {
+			if err := k.Load(ctx); err != nil {
+				return nil, err
+			}
+			found, ok = k.lookup(kid)
+		}
+		if !ok {
+			return nil, fmt.Errorf("unknown signing key %q", kid)
+		}
+		if token.Method.Alg() != found.method.Alg() {
+			return nil, fmt.Errorf("unexpected signing method: %s", token.Method.Alg())
+		}
+		return found.private.Public(), nil
+	}
+}
+
+// ValidMethods lists the algorithms accepted when parsing tokens.
+func ValidMethods() []string {
+	return []string{AlgorithmRS256, AlgorithmEdDSA}
+}
+
+func (k *Keyring) current(now time.Time) (key, error) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	for _, item := range k.keys {
+		if item.method.Alg() == k.algorithm && now.Before(item.retiresAt) {
+			return item, nil
+		}
+	}
+	return key{}, ErrNoSigningKey
+}
+
+func (k *Keyring) canReload() bool {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return time.Since(k.loadedAt) >= reloadCooldown
+}
+
+func (k *Keyring) lookup(kid string) (key, bool) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	now := time.Now().UTC()
+	for _, item := range k.keys {
+		if item.id == kid && now.Before(item.expiresAt) {
+			return item, true
+		}
+	}
+	return key{}, false
+}
+
+func (k *Keyring) published() []key {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	now := time.Now().UTC()
+	keys := make([]key, 0, len(k.keys))
+	for _, item := range k.keys {
+		if now.Before(item.expiresAt) {
+			keys = append(keys, item)
+		}
+	}
+	return keys
+}
+
+func generateKey(algorithm string, now time.Time, rotation, grace time.Duration) (model.SigningKey, error) {
+	var private crypto.Signer
+	switch algorithm {
+	case AlgorithmRS256:
+		rsaKey, err := rsa.GenerateKey(rand.Reader, rsaKeyBits)
+		if err != nil {
+			return model.SigningKey{}, fmt.Errorf("generate RSA key: %w", err)
+		}
+		private = rsaKey
+	case AlgorithmEdDSA:
+		_, edKey, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return model.SigningKey{}, fmt.Errorf("generate Ed25519 key: %w", err)
+		}
+		private = edKey
+	default:
+		return model.SigningKey{}, fmt.Errorf("unsupported signing algorithm %q", algorithm)
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return model.SigningKey{}, fmt.Errorf("marshal private key: %w", err)
+	}
+
+	retiresAt := now.Add(rotation)
+	return model.SigningKey{
+		ID:         uuid.NewString(),
+		Algorithm:  algorithm,
+		PrivateKey: string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		CreatedAt:  now,
+		RetiresAt:  retiresAt,
+		ExpiresAt:  retiresAt.Add(grace),
+	}, nil
+}
+
+func parseKey(stored model.SigningKey) (key, error) {
+	block, _ := pem.Decode([]byte(stored.PrivateKey))
+	if block == nil {
+		return key{}, errors.New("invalid PEM")
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return key{}, err
+	}
+
+	var method jwt.SigningMethod
+	switch parsed.(type) {
+	case *rsa.PrivateKey:
+		method = jwt.SigningMethodRS256
+	case ed25519.PrivateKey:
+		method = jwt.SigningMethodEdDSA
+	default:
+		return key{}, fmt.Errorf("unsupported key type %T", parsed)
+	}
+	if method.Alg() != stored.Algorithm {
+		return key{}, fmt.Errorf("key type does not match algorithm %q", stored.Algorithm)
+	}
+
+	return key{
+		id:        stored.ID,
+		method:    method,
+		private:   parsed.(crypto.Signer),
+		retiresAt: stored.RetiresAt,
+		expiresAt: stored.ExpiresAt,
+	}, nil
+}
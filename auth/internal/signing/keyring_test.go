@@ -0,0 +1,204 @@
+package signing
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/Deevins/final-task-course-2-go-lang/auth/internal/config"
+	"github.com/Deevins/final-task-course-2-go-lang/auth/internal/model"
+)
+
+type memoryStore struct {
+	keys  []model.SigningKey
+	lists int
+}
+
+func (s *memoryStore) ListSigningKeys(ctx context.Context) ([]model.SigningKey, error) {
+	s.lists++
+	return append([]model.SigningKey(nil), s.keys...), nil
+}
+
+func (s *memoryStore) CreateSigningKey(ctx context.Context, key model.SigningKey) error {
+	s.keys = append(s.keys, key)
+	return nil
+}
+
+func (s *memoryStore) DeleteExpiredSigningKeys(ctx context.Context, before time.Time) (int64, error) {
+	kept := s.keys[:0]
+	for _, key := range s.keys {
+		if key.ExpiresAt.After(before) {
+			kept = append(kept, key)
+		}
+	}
+	deleted := int64(len(s.keys) - len(kept))
+	s.keys = kept
+	return deleted, nil
+}
+
+var testJWTConfig = config.JWTConfig{
+	Algorithm:      AlgorithmEdDSA,
+	KeyRotation:    time.Hour,
+	KeyGracePeriod: 2 * time.Hour,
+}
+
+// mustGenerateKey creates a stored key whose rotation period started at createdAt.
+func mustGenerateKey(t *testing.T, algorithm string, createdAt time.Time) model.SigningKey {
+	t.Helper()
+	stored, err := generateKey(algorithm, createdAt, testJWTConfig.KeyRotation, testJWTConfig.KeyGracePeriod)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	return stored
+}
+
+func signWith(t *testing.T, stored model.SigningKey) string {
+	t.Helper()
+	parsed, err := parseKey(stored)
+	if err != nil {
+		t.Fatalf("parse key: %v", err)
+	}
+	token := jwt.NewWithClaims(parsed.method, jwt.RegisteredClaims{
+		Subject:   "user-1",
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+	})
+	token.Header["kid"] = parsed.id
+	signed, err := token.SignedString(parsed.private)
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+	return signed
+}
+
+func parseWith(keyring *Keyring, token string) error {
+	_, err := jwt.Parse(token, keyring.Keyfunc(context.Background()), jwt.WithValidMethods(ValidMethods()))
+	return err
+}
+
+func TestRotateCreatesKeyOnlyWhenNoneIsActive(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now().UTC()
+	retired := mustGenerateKey(t, AlgorithmEdDSA, now.Add(-90*time.Minute))
+	expired := mustGenerateKey(t, AlgorithmEdDSA, now.Add(-4*time.Hour))
+	store := &memoryStore{keys: []model.SigningKey{retired, expired}}
+	keyring := NewKeyring(store, testJWTConfig)
+
+	if err := keyring.Rotate(ctx); err != nil {
+		t.Fatalf("rotate: %v", err)
+	}
+	if len(store.keys) != 2 || store.keys[0].ID != retired.ID {
+		t.Fatalf("expected a new key next to the retired one and the expired key removed, got %+v", store.keys)
+	}
+	created := store.keys[1]
+
+	token, err := keyring.Sign(jwt.RegisteredClaims{Subject: "user-1", ExpiresAt: jwt.NewNumericDate(now.Add(time.Minute))})
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	parsed, _, err := jwt.NewParser().ParseUnverified(token, &jwt.RegisteredClaims{})
+	if err != nil {
+		t.Fatalf("parse token: %v", err)
+	}
+	if parsed.Header["kid"] != created.ID {
+		t.Fatalf("expected the token to be signed with the new key %s, got %v", created.ID, parsed.Header["kid"])
+	}
+
+	if err := keyring.Rotate(ctx); err != nil {
+		t.Fatalf("rotate: %v", err)
+	}
+	if len(store.keys) != 2 {
+		t.Fatalf("expected no key while one is active, got %d keys", len(store.keys))
+	}
+}
+
+func TestKeyfuncAcceptsRetiredKeysUntilTheyExpire(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now().UTC()
+	retired := mustGenerateKey(t, AlgorithmEdDSA, now.Add(-90*time.Minute))
+	expired := mustGenerateKey(t, AlgorithmEdDSA, now.Add(-4*time.Hour))
+	store := &memoryStore{keys: []model.SigningKey{retired, expired}}
+	keyring := NewKeyring(store, testJWTConfig)
+	if err := keyring.Load(ctx); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+
+	if _, err := keyring.Sign(jwt.RegisteredClaims{}); err != ErrNoSigningKey {
+		t.Fatalf("expected retired keys not to sign, got %v", err)
+	}
+	if err := parseWith(keyring, signWith(t, retired)); err != nil {
+		t.Fatalf("expected a retired key to verify within its grace period, got %v", err)
+	}
+	if err := parseWith(keyring, signWith(t, expired)); err == nil {
+		t.Fatal("expected an expired key to be rejected")
+	}
+	for _, item := range keyring.JWKS().Keys {
+		if item.KeyID == expired.ID {
+			t.Fatal("expected the expired key not to be published")
+		}
+	}
+}
+
+func TestKeyfuncReloadsUnknownKidAfterCooldown(t *testing.T) {
+	ctx := context.Background()
+	store := &memoryStore{}
+	keyring := NewKeyring(store, testJWTConfig)
+	if err := keyring.Rotate(ctx); err != nil {
+		t.Fatalf("rotate: %v", err)
+	}
+	lists := store.lists
+
+	// Another instance rotates the keys.
+	rotated := mustGenerateKey(t, AlgorithmEdDSA, time.Now().UTC())
+	store.keys = append(store.keys, rotated)
+	token := signWith(t, rotated)
+
+	if err := parseWith(keyring, token); err == nil {
+		t.Fatal("expected an unknown kid to be rejected within the cooldown")
+	}
+	if store.lists != lists {
+		t.Fatalf("expected no reload within the cooldown, got %d loads", store.lists-lists)
+	}
+
+	keyring.mu.Lock()
+	keyring.loadedAt = time.Now().Add(-reloadCooldown)
+	keyring.mu.Unlock()
+	if err := parseWith(keyring, token); err != nil {
+		t.Fatalf("expected the rotated key to be loaded, got %v", err)
+	}
+	if store.lists != lists+1 {
+		t.Fatalf("expected one reload, got %d", store.lists-lists)
+	}
+}
+
+func TestKeyfuncRejectsAnotherAlgorithmForTheKid(t *testing.T) {
+	ctx := context.Background()
+	stored := mustGenerateKey(t, AlgorithmEdDSA, time.Now().UTC())
+	keyring := NewKeyring(&memoryStore{keys: []model.SigningKey{stored}}, testJWTConfig)
+	if err := keyring.Load(ctx); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+
+	token := &jwt.Token{Method: jwt.SigningMethodRS256, Header: map[string]interface{}{"kid": stored.ID}}
+	if _, err := keyring.Keyfunc(ctx)(token); err == nil {
+		t.Fatal("expected RS256 to be rejected for an EdDSA key")
+	}
+	token.Method = jwt.SigningMethodEdDSA
+	if _, err := keyring.Keyfunc(ctx)(token); err != nil {
+		t.Fatalf("expected EdDSA to be accepted, got %v", err)
+	}
+}
+
+func TestParseKeyRejectsAlgorithmMismatch(t *testing.T) {
+	stored := mustGenerateKey(t, AlgorithmEdDSA, time.Now().UTC())
+	stored.Algorithm = AlgorithmRS256
+	if _, err := parseKey(stored); err == nil {
+		t.Fatal("expected an Ed25519 key stored as RS256 to be rejected")
+	}
+
+	keyring := NewKeyring(&memoryStore{keys: []model.SigningKey{stored}}, testJWTConfig)
+	if err := keyring.Load(context.Background()); err == nil {
+		t.Fatal("expected load to fail on a mismatched key")
+	}
+}
@@ -0,0 +1,78 @@
+package totp
+
+import (
+	"encoding/base32"
+	"testing"
+	"time"
+)
+
+// rfcSecret is the SHA-1 seed of RFC 6238 Appendix B, "12345678901234567890".
+var rfcSecret = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+func TestCodeMatchesRFC6238Vectors(t *testing.T) {
+	// The RFC lists 8-digit codes; these are their last 6 digits.
+	tests := []struct {
+		unix int64
+		code string
+	}{
+		{unix: 59, code: "287082"},
+		{unix: 1111111109, code: "081804"},
+		{unix: 1111111111, code: "050471"},
+		{unix: 1234567890, code: "005924"},
+		{unix: 2000000000, code: "279037"},
+		{unix: 20000000000, code: "353130"},
+	}
+
+	for _, tt := range tests {
+		code, err := Code(rfcSecret, Step(time.Unix(tt.unix, 0)))
+		if err != nil {
+			t.Fatalf("T=%d: code: %v", tt.unix, err)
+		}
+		if code != tt.code {
+			t.Fatalf("T=%d: expected %s, got %s", tt.unix, tt.code, code)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	current := Step(now)
+	codeAt := func(step int64) string {
+		code, err := Code(rfcSecret, step)
+		if err != nil {
+			t.Fatalf("code: %v", err)
+		}
+		return code
+	}
+
+	tests := []struct {
+		name     string
+		code     string
+		skew     int
+		wantStep int64
+		wantOK   bool
+	}{
+		{name: "current step", code: codeAt(current), skew: 0, wantStep: current, wantOK: true},
+		{name: "previous step within skew", code: codeAt(current - 1), skew: 1, wantStep: current - 1, wantOK: true},
+		{name: "next step within skew", code: codeAt(current + 1), skew: 1, wantStep: current + 1, wantOK: true},
+		{name: "previous step without skew", code: codeAt(current - 1), skew: 0},
+		{name: "step beyond skew", code: codeAt(current + 2), skew: 1},
+		{name: "surrounding spaces", code: " " + codeAt(current) + " ", skew: 0, wantStep: current, wantOK: true},
+		{name: "too short", code: codeAt(current)[:5], skew: 1},
+		{name: "too long", code: codeAt(current) + "0", skew: 1},
+		{name: "empty", code: "", skew: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, ok := Validate(rfcSecret, tt.code, now, tt.skew)
+			if ok != tt.wantOK || step != tt.wantStep {
+				t.Fatalf("expected (%d, %v), got (%d, %v)", tt.wantStep, tt.wantOK, step, ok)
+			}
+		})
+	}
+
+	if _, ok := Validate("not base32!", "123456", now, 1); ok {
+		t.Fatal("expected an invalid secret to fail validation")
+	}
+}
@@ -0,0 +1,8 @@
+-- +goose Up
+ALTER TABLE revoked_tokens ADD COLUMN IF NOT EXISTS revoked_at TIMESTAMPTZ NOT NULL DEFAULT now();
+
+CREATE INDEX IF NOT EXISTS revoked_tokens_revoked_at_idx ON revoked_tokens (revoked_at);
+
+-- +goose Down
+DROP INDEX IF EXISTS revoked_tokens_revoked_at_idx;
+ALTER TABLE revoked_tokens DROP COLUMN IF EXISTS revoked_at;
@@ -0,0 +1,13 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS account_purges (
+    user_id TEXT PRIMARY KEY,
+    requested_at TIMESTAMPTZ NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL DEFAULT '',
+    last_attempt_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS account_purges_requested_at_idx ON account_purges (requested_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS account_purges;
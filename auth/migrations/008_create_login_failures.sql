@@ -0,0 +1,13 @@
+-- +goose Up
+-- login_failures counts failed sign-in attempts per account (account:<email>)
+-- and per client address (ip:<address>).
+CREATE TABLE IF NOT EXISTS login_failures (
+    key TEXT PRIMARY KEY,
+    failures INTEGER NOT NULL,
+    last_failure_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS login_failures_last_failure_at_idx ON login_failures (last_failure_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS login_failures;
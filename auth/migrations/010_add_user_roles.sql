@@ -0,0 +1,6 @@
+-- +goose Up
+-- roles always contains 'user'; 'support' and 'admin' grant access to other accounts.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS roles TEXT[] NOT NULL DEFAULT '{user}';
+
+-- +goose Down
+ALTER TABLE users DROP COLUMN IF EXISTS roles;
@@ -0,0 +1,16 @@
+-- +goose Up
+-- revoked_users rejects every access token of a user issued before
+-- revoked_before. There is no foreign key: the cutoff has to outlive a deleted
+-- user until the tokens issued before it expire at expires_at.
+CREATE TABLE IF NOT EXISTS revoked_users (
+    user_id TEXT PRIMARY KEY,
+    revoked_before TIMESTAMPTZ NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    revoked_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS revoked_users_revoked_at_idx ON revoked_users (revoked_at);
+CREATE INDEX IF NOT EXISTS revoked_users_expires_at_idx ON revoked_users (expires_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS revoked_users;
@@ -4,10 +4,14 @@ go 1.25.2
 
 require (
 	github.com/gin-gonic/gin v1.11.0
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20251103181224-f26f9409b101
 	google.golang.org/grpc v1.77.0
 )
 
-require google.golang.org/protobuf v1.36.11
+require (
+	github.com/golang-jwt/jwt/v5 v5.3.0
+	google.golang.org/protobuf v1.36.11
+)
 
 require (
 	github.com/bytedance/sonic v1.14.0 // indirect
@@ -40,5 +44,4 @@ require (
 	golang.org/x/sys v0.37.0 // indirect
 	golang.org/x/text v0.30.0 // indirect
 	golang.org/x/tools v0.37.0 // indirect
-	google.golang.org/genproto/googleapis/rpc v0.0.0-20251103181224-f26f9409b101 // indirect
 )
@@ -11,6 +11,7 @@ import (
 	"github.com/Deevins/final-task-course-2-go-lang/gateway/internal/config"
 	"github.com/Deevins/final-task-course-2-go-lang/gateway/internal/grpcclient"
 	"github.com/Deevins/final-task-course-2-go-lang/gateway/internal/handler"
+	"github.com/Deevins/final-task-course-2-go-lang/gateway/internal/jwtverify"
 	"github.com/Deevins/final-task-course-2-go-lang/gateway/internal/middleware"
 	authv1 "github.com/Deevins/final-task-course-2-go-lang/gateway/internal/pb/auth/v1"
 	ledgerv1 "github.com/Deevins/final-task-course-2-go-lang/gateway/internal/pb/ledger/v1"
@@ -24,18 +25,21 @@ type App struct {
 	authConn   *grpc.ClientConn
 	ledgerConn *grpc.ClientConn
 	address    string
+	keys       *jwtverify.KeySet
+	denylist   *jwtverify.Denylist
+	jwtConfig  config.JWTConfig
 }
 
 func New(cfg config.Config) (*App, error) {
 	log.Printf("Connecting to auth gRPC backend at %s", cfg.GRPC.AuthAddress)
-	authConn, err := grpcclient.Dial(cfg.GRPC.AuthAddress)
+	authConn, err := grpcclient.Dial(cfg.GRPC.AuthAddress, cfg.GRPC.ServiceToken)
 	if err != nil {
 		return nil, err
 	}
 	log.Printf("Connected to auth gRPC backend successfully")
 
 	log.Printf("Connecting to ledger gRPC backend at %s", cfg.GRPC.LedgerAddress)
-	ledgerConn, err := grpcclient.Dial(cfg.GRPC.LedgerAddress)
+	ledgerConn, err := grpcclient.Dial(cfg.GRPC.LedgerAddress, cfg.GRPC.ServiceToken)
 	if err != nil {
 		authConn.Close()
 		return nil, err
@@ -46,23 +50,37 @@ func New(cfg config.Config) (*App, error) {
 	ledgerService := service.NewLedgerGatewayService(ledgerv1.NewLedgerServiceClient(ledgerConn))
 	authHandler := handler.NewAuthHandler(authService)
 	ledgerHandler := handler.NewLedgerHandler(ledgerService)
+	adminHandler := handler.NewAdminHandler(authService, ledgerService)
 
-	engine := gin.New()
-	engine.Use(gin.Logger(), gin.Recovery())
-	router.Register(engine, authHandler, ledgerHandler, middleware.JWTAuth(authService))
-
-	server := httpserver.New(cfg.HTTP, engine)
-
-	return &App{
-		server:     server,
+	app := &App{
 		authConn:   authConn,
 		ledgerConn: ledgerConn,
 		address:    cfg.HTTP.Address,
-	}, nil
+		jwtConfig:  cfg.JWT,
+	}
+	verifier := jwtverify.NewRemoteVerifier(authService)
+	if cfg.JWT.JWKSURL != "" {
+		app.keys = jwtverify.NewKeySet(cfg.JWT.JWKSURL, nil)
+		app.denylist = jwtverify.NewDenylist(authService, cfg.JWT.DenylistMaxStaleness)
+		verifier = jwtverify.NewVerifier(app.keys, app.denylist, authService)
+		log.Printf("Verifying tokens locally with keys from %s", cfg.JWT.JWKSURL)
+	}
+
+	engine := gin.New()
+	if err := engine.SetTrustedProxies(cfg.HTTP.TrustedProxies); err != nil {
+		app.closeConnections()
+		return nil, err
+	}
+	engine.Use(gin.Logger(), gin.Recovery())
+	router.Register(engine, authHandler, ledgerHandler, adminHandler, middleware.JWTAuth(verifier))
+
+	app.server = httpserver.New(cfg.HTTP, engine)
+	return app, nil
 }
 
 func (a *App) Run(ctx context.Context) error {
 	defer a.closeConnections()
+	a.startTokenCaches(ctx)
 
 	errCh := make(chan error, 1)
 	go func() {
@@ -92,3 +110,20 @@ func (a *App) closeConnections() {
 		a.ledgerConn.Close()
 	}
 }
+
+// startTokenCaches loads the JWKS and the denylist and keeps them up to date.
+// Until the first successful load, tokens are validated by the auth service.
+func (a *App) startTokenCaches(ctx context.Context) {
+	if a.keys == nil {
+		return
+	}
+
+	if err := a.keys.Refresh(ctx); err != nil {
+		log.Printf("initial jwks fetch failed: %v", err)
+	}
+	if err := a.denylist.Sync(ctx); err != nil {
+		log.Printf("initial denylist sync failed: %v", err)
+	}
+	go a.keys.Run(ctx, a.jwtConfig.JWKSRefreshInterval)
+	go a.denylist.Run(ctx, a.jwtConfig.DenylistSyncInterval)
+}
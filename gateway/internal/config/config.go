@@ -2,6 +2,7 @@ package config
 
 import (
 	"os"
+	"strings"
 	"time"
 )
 
@@ -10,16 +11,34 @@ type HTTPConfig struct {
 	ReadTimeout     time.Duration
 	WriteTimeout    time.Duration
 	ShutdownTimeout time.Duration
+	// TrustedProxies are the addresses whose X-Forwarded-For headers are used
+	// to find the client address. By default no proxy is trusted.
+	TrustedProxies []string
 }
 
 type GRPCConfig struct {
 	AuthAddress   string
 	LedgerAddress string
+	// ServiceToken is the secret shared by the internal services and sent
+	// with every backend call.
+	ServiceToken string
+}
+
+// JWTConfig configures local verification of access tokens. An empty
+// JWKSURL disables it, and every token is validated by the auth service.
+type JWTConfig struct {
+	JWKSURL              string
+	JWKSRefreshInterval  time.Duration
+	DenylistSyncInterval time.Duration
+	// DenylistMaxStaleness is how long after the last successful sync the local
+	// denylist is trusted; after that tokens are validated remotely.
+	DenylistMaxStaleness time.Duration
 }
 
 type Config struct {
 	HTTP HTTPConfig
 	GRPC GRPCConfig
+	JWT  JWTConfig
 }
 
 func Load() Config {
@@ -29,10 +48,18 @@ func Load() Config {
 			ReadTimeout:     5 * time.Second,
 			WriteTimeout:    10 * time.Second,
 			ShutdownTimeout: 5 * time.Second,
+			TrustedProxies:  getList("TRUSTED_PROXIES"),
 		},
 		GRPC: GRPCConfig{
 			AuthAddress:   getEnv("AUTH_GRPC_ADDRESS", "127.0.0.1:9092"),
 			LedgerAddress: getEnv("LEDGER_GRPC_ADDRESS", "127.0.0.1:9091"),
+			ServiceToken:  getEnv("SERVICE_TOKEN", ""),
+		},
+		JWT: JWTConfig{
+			JWKSURL:              lookupEnv("AUTH_JWKS_URL", "http://127.0.0.1:8082/.well-known/jwks.json"),
+			JWKSRefreshInterval:  getDuration("JWKS_REFRESH_INTERVAL", 5*time.Minute),
+			DenylistSyncInterval: getDuration("DENYLIST_SYNC_INTERVAL", 10*time.Second),
+			DenylistMaxStaleness: getDuration("DENYLIST_MAX_STALENESS", time.Minute),
 		},
 	}
 }
@@ -43,3 +70,30 @@ func getEnv(key, fallback string) string {
 	}
 	return fallback
 }
+
+// lookupEnv is like getEnv but keeps an explicitly empty value.
+func lookupEnv(key, fallback string) string {
+	if val, ok := os.LookupEnv(key); ok {
+		return val
+	}
+	return fallback
+}
+
+// getList splits a comma-separated value and drops empty items.
+func getList(key string) []string {
+	var items []string
+	for _, item := range strings.Split(os.Getenv(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func getDuration(key string, fallback time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(key))
+	if err != nil || value <= 0 {
+		return fallback
+	}
+	return value
+}
@@ -0,0 +1,252 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/Deevins/final-task-course-2-go-lang/gateway/internal/middleware"
+	"github.com/Deevins/final-task-course-2-go-lang/gateway/internal/model"
+	"github.com/gin-gonic/gin"
+)
+
+// accountIDHeader selects the account a ledger request works with. Without it
+// the personal account of the user is used.
+const accountIDHeader = "X-Account-ID"
+
+// accountIDFromRequest returns the account selected by the X-Account-ID header,
+// falling back to the personal account, whose ID is the user ID. Membership is
+// checked by the ledger.
+func accountIDFromRequest(c *gin.Context) string {
+	if accountID := c.GetHeader(accountIDHeader); accountID != "" {
+		return accountID
+	}
+	return middleware.UserIDFromContext(c)
+}
+
+// ListAccounts godoc
+// @Summary Получить счета пользователя
+// @Description Возвращает личный счет пользователя и общие счета, в которых он участвует, с его ролью в каждом.
+// @Tags accounts
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} model.AccountsResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/ledger/accounts [get]
+func (h *LedgerHandler) ListAccounts(c *gin.Context) {
+	items, err := h.service.ListAccounts(c.Request.Context())
+	if err != nil {
+		writeAuthError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, model.AccountsResponse{Accounts: items})
+}
+
+// CreateAccount godoc
+// @Summary Создать общий счет
+// @Description Создает общий счет. Создатель становится его владельцем.
+// @Tags accounts
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body model.CreateAccountRequest true "Название счета"
+// @Success 201 {object} model.Account
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/ledger/accounts [post]
+func (h *LedgerHandler) CreateAccount(c *gin.Context) {
+	var req model.CreateAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	account, err := h.service.CreateAccount(c.Request.Context(), req)
+	if err != nil {
+		writeAuthError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, account)
+}
+
+// ListMembers godoc
+// @Summary Получить участников общего счета
+// @Description Возвращает участников общего счета и их роли. Доступно любому участнику.
+// @Tags accounts
+// @Produce json
+// @Security BearerAuth
+// @Param account_id path string true "ID счета"
+// @Success 200 {object} model.MembersResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/ledger/accounts/{account_id}/members [get]
+func (h *LedgerHandler) ListMembers(c *gin.Context) {
+	items, err := h.service.ListMembers(c.Request.Context(), c.Param("account_id"))
+	if err != nil {
+		writeAuthError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, model.MembersResponse{Members: items})
+}
+
+// UpdateMember godoc
+// @Summary Изменить роль участника
+// @Description Меняет роль участника общего счета: owner, editor или viewer. Доступно владельцам. Последнего владельца понизить нельзя.
+// @Tags accounts
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param account_id path string true "ID счета"
+// @Param user_id path string true "ID пользователя"
+// @Param request body model.UpdateMemberRequest true "Роль"
+// @Success 200 {object} model.AccountMember
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 409 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/ledger/accounts/{account_id}/members/{user_id} [patch]
+func (h *LedgerHandler) UpdateMember(c *gin.Context) {
+	var req model.UpdateMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	member, err := h.service.UpdateMember(c.Request.Context(), c.Param("account_id"), c.Param("user_id"), req)
+	if err != nil {
+		writeAuthError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, member)
+}
+
+// RemoveMember godoc
+// @Summary Удалить участника
+// @Description Удаляет участника из общего счета. Владельцы удаляют любого участника, остальные могут удалить только себя, то есть покинуть счет. Последнего владельца удалить нельзя.
+// @Tags accounts
+// @Produce json
+// @Security BearerAuth
+// @Param account_id path string true "ID счета"
+// @Param user_id path string true "ID пользователя"
+// @Success 200 {object} model.DeleteResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 409 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/ledger/accounts/{account_id}/members/{user_id} [delete]
+func (h *LedgerHandler) RemoveMember(c *gin.Context) {
+	deleted, err := h.service.RemoveMember(c.Request.Context(), c.Param("account_id"), c.Param("user_id"))
+	if err != nil {
+		writeAuthError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, model.DeleteResponse{Deleted: deleted})
+}
+
+// ListInvitations godoc
+// @Summary Получить приглашения в общий счет
+// @Description Возвращает неиспользованные и непросроченные приглашения. Доступно владельцам.
+// @Tags accounts
+// @Produce json
+// @Security BearerAuth
+// @Param account_id path string true "ID счета"
+// @Success 200 {object} model.InvitationsResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/ledger/accounts/{account_id}/invitations [get]
+func (h *LedgerHandler) ListInvitations(c *gin.Context) {
+	items, err := h.service.ListInvitations(c.Request.Context(), c.Param("account_id"))
+	if err != nil {
+		writeAuthError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, model.InvitationsResponse{Invitations: items})
+}
+
+// CreateInvitation godoc
+// @Summary Пригласить в общий счет
+// @Description Создает одноразовое приглашение с ролью на 7 дней. Токен возвращается только в этом ответе и передается приглашенному. Доступно владельцам.
+// @Tags accounts
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param account_id path string true "ID счета"
+// @Param request body model.CreateInvitationRequest true "Роль"
+// @Success 201 {object} model.CreateInvitationResponse
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/ledger/accounts/{account_id}/invitations [post]
+func (h *LedgerHandler) CreateInvitation(c *gin.Context) {
+	var req model.CreateInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := h.service.CreateInvitation(c.Request.Context(), c.Param("account_id"), req)
+	if err != nil {
+		writeAuthError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, resp)
+}
+
+// RevokeInvitation godoc
+// @Summary Отозвать приглашение
+// @Description Удаляет неиспользованное приглашение. Доступно владельцам.
+// @Tags accounts
+// @Produce json
+// @Security BearerAuth
+// @Param account_id path string true "ID счета"
+// @Param id path string true "ID приглашения"
+// @Success 200 {object} model.DeleteResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/ledger/accounts/{account_id}/invitations/{id} [delete]
+func (h *LedgerHandler) RevokeInvitation(c *gin.Context) {
+	deleted, err := h.service.RevokeInvitation(c.Request.Context(), c.Param("account_id"), c.Param("id"))
+	if err != nil {
+		writeAuthError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, model.DeleteResponse{Deleted: deleted})
+}
+
+// AcceptInvitation godoc
+// @Summary Принять приглашение
+// @Description Добавляет пользователя в общий счет с ролью из приглашения. Приглашение действует один раз.
+// @Tags accounts
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body model.AcceptInvitationRequest true "Токен приглашения"
+// @Success 200 {object} model.AccountMember
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 409 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/ledger/invitations/accept [post]
+func (h *LedgerHandler) AcceptInvitation(c *gin.Context) {
+	var req model.AcceptInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	member, err := h.service.AcceptInvitation(c.Request.Context(), req)
+	if err != nil {
+		writeAuthError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, member)
+}
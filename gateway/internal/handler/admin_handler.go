@@ -0,0 +1,214 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/Deevins/final-task-course-2-go-lang/gateway/internal/middleware"
+	"github.com/Deevins/final-task-course-2-go-lang/gateway/internal/model"
+	"github.com/Deevins/final-task-course-2-go-lang/gateway/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	roleSupport = "support"
+	roleAdmin   = "admin"
+)
+
+// AdminHandler serves the support and admin endpoints. Every read of another
+// user's ledger is checked and audited by the ledger service.
+type AdminHandler struct {
+	auth   service.AuthGatewayService
+	ledger service.LedgerGatewayService
+}
+
+func NewAdminHandler(auth service.AuthGatewayService, ledger service.LedgerGatewayService) *AdminHandler {
+	if auth == nil || ledger == nil {
+		panic("AdminHandler requires auth and ledger services")
+	}
+	return &AdminHandler{auth: auth, ledger: ledger}
+}
+
+func (h *AdminHandler) Register(r *gin.RouterGroup, authMiddleware gin.HandlerFunc) {
+	admin := r.Group("/admin")
+	admin.Use(authMiddleware, middleware.RequireRole(roleSupport, roleAdmin))
+	{
+		admin.GET("/users", h.LookupUser)
+		admin.PUT("/users/:id/roles", middleware.RequireRole(roleAdmin), h.SetUserRoles)
+		accounts := admin.Group("/accounts/:account_id")
+		{
+			accounts.GET("/transactions", h.ListTransactions)
+			accounts.GET("/budgets", h.ListBudgets)
+			accounts.GET("/reports", h.ListReports)
+			accounts.GET("/access-log", h.AccessLog)
+		}
+	}
+}
+
+// LookupUser godoc
+// @Summary Найти пользователя по email
+// @Description Возвращает профиль пользователя с ролями. Доступно ролям support и admin.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param email query string true "Email пользователя"
+// @Success 200 {object} model.UserProfile
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/admin/users [get]
+func (h *AdminHandler) LookupUser(c *gin.Context) {
+	email := c.Query("email")
+	if email == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "email is required"})
+		return
+	}
+
+	user, err := h.auth.LookupUser(c.Request.Context(), middleware.AccessTokenFromContext(c), email)
+	if err != nil {
+		writeAuthError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, user)
+}
+
+// SetUserRoles godoc
+// @Summary Назначить роли пользователю
+// @Description Заменяет роли пользователя. Роль user назначается всегда, снять роль admin с себя нельзя. Сессии пользователя отзываются, чтобы новые роли попали в токены. Доступно только роли admin.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "ID пользователя"
+// @Param request body model.SetUserRolesRequest true "Роли"
+// @Success 200 {object} model.UserProfile
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 409 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/admin/users/{id}/roles [put]
+func (h *AdminHandler) SetUserRoles(c *gin.Context) {
+	var req model.SetUserRolesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, err := h.auth.SetUserRoles(c.Request.Context(), middleware.AccessTokenFromContext(c), c.Param("id"), req)
+	if err != nil {
+		writeAuthError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, user)
+}
+
+// ListTransactions godoc
+// @Summary Получить транзакции пользователя
+// @Description Возвращает транзакции указанного счета с теми же параметрами поиска, что и /api/ledger/transactions. Доступ записывается в журнал доступа счета.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param account_id path string true "ID счета"
+// @Param from query string false "Начало периода (RFC3339 или YYYY-MM-DD)"
+// @Param to query string false "Конец периода (RFC3339 или YYYY-MM-DD, включительно)"
+// @Param category query string false "Категория"
+// @Param currency query string false "Валюта"
+// @Param min query number false "Минимальная сумма по модулю"
+// @Param max query number false "Максимальная сумма по модулю"
+// @Param q query string false "Подстрока в описании"
+// @Param sort query string false "Сортировка: occurred_at, -occurred_at, amount, -amount"
+// @Param limit query int false "Количество записей"
+// @Param offset query int false "Смещение"
+// @Success 200 {object} model.TransactionsResponse
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/admin/accounts/{account_id}/transactions [get]
+func (h *AdminHandler) ListTransactions(c *gin.Context) {
+	accountID := c.Param("account_id")
+	query, ok, err := parseTransactionSearchQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var items []model.Transaction
+	if ok {
+		items, err = h.ledger.SearchTransactions(c.Request.Context(), accountID, query)
+	} else {
+		items, err = h.ledger.ListTransactions(c.Request.Context(), accountID)
+	}
+	if err != nil {
+		writeAuthError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"transactions": items})
+}
+
+// ListBudgets godoc
+// @Summary Получить бюджеты пользователя
+// @Description Возвращает бюджеты указанного счета. Доступ записывается в журнал доступа счета.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param account_id path string true "ID счета"
+// @Success 200 {object} model.BudgetsResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/admin/accounts/{account_id}/budgets [get]
+func (h *AdminHandler) ListBudgets(c *gin.Context) {
+	items, err := h.ledger.ListBudgets(c.Request.Context(), c.Param("account_id"))
+	if err != nil {
+		writeAuthError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"budgets": items})
+}
+
+// ListReports godoc
+// @Summary Получить отчеты пользователя
+// @Description Возвращает отчеты указанного счета. Доступ записывается в журнал доступа счета.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param account_id path string true "ID счета"
+// @Success 200 {object} model.ReportsResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/admin/accounts/{account_id}/reports [get]
+func (h *AdminHandler) ListReports(c *gin.Context) {
+	items, err := h.ledger.ListReports(c.Request.Context(), c.Param("account_id"))
+	if err != nil {
+		writeAuthError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"reports": items})
+}
+
+// AccessLog godoc
+// @Summary Получить журнал доступа к счету
+// @Description Возвращает записи о просмотре данных счета сотрудниками поддержки и администраторами.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param account_id path string true "ID счета"
+// @Success 200 {object} model.HistoryResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/admin/accounts/{account_id}/access-log [get]
+func (h *AdminHandler) AccessLog(c *gin.Context) {
+	accountID := c.Param("account_id")
+	entries, err := h.ledger.ListHistory(c.Request.Context(), accountID, "account", accountID)
+	if err != nil {
+		writeAuthError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, model.HistoryResponse{Entries: entries})
+}
@@ -0,0 +1,187 @@
+package handler
+
+import (
+	"errors"
+	"mime"
+	"net/http"
+
+	"github.com/Deevins/final-task-course-2-go-lang/gateway/internal/model"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// maxAttachmentSize matches the limit enforced by the ledger, so oversized
+	// files are rejected before they are streamed.
+	maxAttachmentSize = 10 << 20
+	// multipartOverhead leaves room for the multipart envelope around the file.
+	multipartOverhead = 1 << 20
+)
+
+// UploadAttachment godoc
+// @Summary Прикрепить файл к транзакции
+// @Description Загружает фото чека или счет к транзакции. Размер файла не больше 10 МБ, допустимые типы: JPEG, PNG, GIF, WebP и PDF. Тип определяется по содержимому файла.
+// @Tags attachments
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param X-Account-ID header string false "ID общего счета; по умолчанию личный счет пользователя"
+// @Param id path string true "ID транзакции"
+// @Param file formData file true "Файл вложения"
+// @Success 201 {object} model.Attachment
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 413 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/ledger/transactions/{id}/attachments [post]
+func (h *LedgerHandler) UploadAttachment(c *gin.Context) {
+	accountID := accountIDFromRequest(c)
+	if accountID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "account_id is required"})
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxAttachmentSize+multipartOverhead)
+	file, header, err := c.Request.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "attachment is too large"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "file is required"})
+		return
+	}
+	defer file.Close()
+	if header.Size > maxAttachmentSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "attachment is too large"})
+		return
+	}
+
+	created, err := h.service.UploadAttachment(c.Request.Context(), accountID, c.Param("id"), header.Filename, file)
+	if err != nil {
+		writeAuthError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, created)
+}
+
+// ListAttachments godoc
+// @Summary Получить вложения транзакции
+// @Description Возвращает вложения транзакции в порядке загрузки.
+// @Tags attachments
+// @Produce json
+// @Security BearerAuth
+// @Param X-Account-ID header string false "ID общего счета; по умолчанию личный счет пользователя"
+// @Param id path string true "ID транзакции"
+// @Success 200 {object} model.AttachmentsResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/ledger/transactions/{id}/attachments [get]
+func (h *LedgerHandler) ListAttachments(c *gin.Context) {
+	accountID := accountIDFromRequest(c)
+	if accountID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "account_id is required"})
+		return
+	}
+
+	items, err := h.service.ListAttachments(c.Request.Context(), accountID, c.Param("id"))
+	if err != nil {
+		writeAuthError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, model.AttachmentsResponse{Attachments: items})
+}
+
+// GetAttachment godoc
+// @Summary Получить вложение
+// @Description Возвращает метаданные вложения: имя, тип, размер и контрольную сумму SHA-256.
+// @Tags attachments
+// @Produce json
+// @Security BearerAuth
+// @Param X-Account-ID header string false "ID общего счета; по умолчанию личный счет пользователя"
+// @Param id path string true "ID вложения"
+// @Success 200 {object} model.Attachment
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/ledger/attachments/{id} [get]
+func (h *LedgerHandler) GetAttachment(c *gin.Context) {
+	accountID := accountIDFromRequest(c)
+	if accountID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "account_id is required"})
+		return
+	}
+
+	item, err := h.service.GetAttachment(c.Request.Context(), accountID, c.Param("id"))
+	if err != nil {
+		writeAuthError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, item)
+}
+
+// DownloadAttachment godoc
+// @Summary Скачать вложение
+// @Description Возвращает содержимое вложения с исходным именем файла.
+// @Tags attachments
+// @Produce octet-stream
+// @Security BearerAuth
+// @Param X-Account-ID header string false "ID общего счета; по умолчанию личный счет пользователя"
+// @Param id path string true "ID вложения"
+// @Success 200 {file} file
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/ledger/attachments/{id}/content [get]
+func (h *LedgerHandler) DownloadAttachment(c *gin.Context) {
+	accountID := accountIDFromRequest(c)
+	if accountID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "account_id is required"})
+		return
+	}
+
+	item, content, err := h.service.DownloadAttachment(c.Request.Context(), accountID, c.Param("id"))
+	if err != nil {
+		writeAuthError(c, err)
+		return
+	}
+	defer content.Close()
+	c.DataFromReader(http.StatusOK, item.Size, item.ContentType, content, map[string]string{
+		"Content-Disposition":    mime.FormatMediaType("attachment", map[string]string{"filename": item.FileName}),
+		"X-Content-Type-Options": "nosniff",
+	})
+}
+
+// DeleteAttachment godoc
+// @Summary Удалить вложение
+// @Description Удаляет вложение вместе с его содержимым в хранилище.
+// @Tags attachments
+// @Produce json
+// @Security BearerAuth
+// @Param X-Account-ID header string false "ID общего счета; по умолчанию личный счет пользователя"
+// @Param id path string true "ID вложения"
+// @Success 200 {object} model.DeleteResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/ledger/attachments/{id} [delete]
+func (h *LedgerHandler) DeleteAttachment(c *gin.Context) {
+	accountID := accountIDFromRequest(c)
+	if accountID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "account_id is required"})
+		return
+	}
+
+	deleted, err := h.service.DeleteAttachment(c.Request.Context(), accountID, c.Param("id"))
+	if err != nil {
+		writeAuthError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, model.DeleteResponse{Deleted: deleted})
+}
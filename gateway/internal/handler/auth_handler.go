@@ -1,11 +1,19 @@
 package handler
 
 import (
+	"crypto/subtle"
+	"math"
 	"net/http"
+	"strconv"
+	"time"
 
+	"github.com/Deevins/final-task-course-2-go-lang/gateway/internal/middleware"
 	"github.com/Deevins/final-task-course-2-go-lang/gateway/internal/model"
 	"github.com/Deevins/final-task-course-2-go-lang/gateway/internal/service"
 	"github.com/gin-gonic/gin"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 type AuthHandler struct {
@@ -19,23 +27,43 @@ func NewAuthHandler(s service.AuthGatewayService) *AuthHandler {
 	return &AuthHandler{service: s}
 }
 
-func (h *AuthHandler) Register(r *gin.RouterGroup) {
+func (h *AuthHandler) Register(r *gin.RouterGroup, authMiddleware gin.HandlerFunc) {
 	auth := r.Group("/auth")
+	auth.Use(middleware.ForwardClientIP())
 	{
 		auth.POST("/signup", h.SignUp)
 		auth.POST("/signin", h.SignIn)
+		auth.POST("/signin/2fa", h.VerifyTwoFactor)
+		auth.POST("/refresh", h.Refresh)
+		auth.POST("/logout", authMiddleware, h.Logout)
+		auth.DELETE("/sessions", authMiddleware, h.RevokeAllSessions)
+		auth.GET("/me", authMiddleware, h.GetMe)
+		auth.PATCH("/me", authMiddleware, h.UpdateProfile)
+		auth.POST("/me/password", authMiddleware, h.ChangePassword)
+		auth.DELETE("/me", authMiddleware, h.DeleteAccount)
+		auth.POST("/email/verification", authMiddleware, h.RequestEmailVerification)
+		auth.POST("/email/verification/confirm", h.ConfirmEmail)
+		auth.POST("/password/reset", h.RequestPasswordReset)
+		auth.POST("/password/reset/confirm", h.ResetPassword)
+		auth.POST("/2fa/totp", authMiddleware, h.EnrollTOTP)
+		auth.POST("/2fa/totp/confirm", authMiddleware, h.ConfirmTOTP)
+		auth.DELETE("/2fa/totp", authMiddleware, h.DisableTOTP)
+		auth.GET("/oidc/providers", h.ListIdentityProviders)
+		auth.GET("/oidc/:provider/login", h.StartOIDCLogin)
+		auth.GET("/oidc/:provider/callback", h.CompleteOIDCLogin)
 	}
 }
 
 // SignUp godoc
 // @Summary Зарегистрировать пользователя
-// @Description Создает нового пользователя и возвращает его идентификатор.
+// @Description Создает нового пользователя и возвращает его идентификатор. Пароль должен соответствовать политике паролей; нарушенные правила перечисляются в ответе 400.
 // @Tags auth
 // @Accept json
 // @Produce json
 // @Param request body model.SignUpRequest true "Данные регистрации"
 // @Success 201 {object} model.SignUpResponse
-// @Failure 400 {object} model.ErrorResponse
+// @Failure 400 {object} model.ValidationErrorResponse
+// @Failure 409 {object} model.ErrorResponse
 // @Failure 500 {object} model.ErrorResponse
 // @Router /api/auth/signup [post]
 func (h *AuthHandler) SignUp(c *gin.Context) {
@@ -47,7 +75,7 @@ func (h *AuthHandler) SignUp(c *gin.Context) {
 
 	resp, err := h.service.SignUp(c.Request.Context(), req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeAuthError(c, err)
 		return
 	}
 
@@ -56,7 +84,7 @@ func (h *AuthHandler) SignUp(c *gin.Context) {
 
 // SignIn godoc
 // @Summary Войти в систему
-// @Description Проверяет учетные данные и возвращает JWT токен.
+// @Description Проверяет учетные данные и возвращает короткоживущий JWT токен и refresh token. Если включена двухфакторная аутентификация, вместо токенов возвращается challenge_token для /api/auth/signin/2fa. После неудачных попыток вход временно блокируется для email и IP-адреса: ответ 429 с заголовком Retry-After.
 // @Tags auth
 // @Accept json
 // @Produce json
@@ -64,6 +92,8 @@ func (h *AuthHandler) SignUp(c *gin.Context) {
 // @Success 200 {object} model.SignInResponse
 // @Failure 400 {object} model.ErrorResponse
 // @Failure 401 {object} model.ErrorResponse
+// @Failure 429 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
 // @Router /api/auth/signin [post]
 func (h *AuthHandler) SignIn(c *gin.Context) {
 	var req model.SignInRequest
@@ -73,6 +103,62 @@ func (h *AuthHandler) SignIn(c *gin.Context) {
 	}
 
 	resp, err := h.service.SignIn(c.Request.Context(), req)
+	if err != nil {
+		writeAuthError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// VerifyTwoFactor godoc
+// @Summary Подтвердить вход кодом
+// @Description Обменивает challenge_token из ответа на вход и код из приложения-аутентификатора (или резервный код) на пару токенов. Challenge одноразовый: после неверного кода нужно войти заново.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body model.VerifyTwoFactorRequest true "Challenge и код"
+// @Success 200 {object} model.SignInResponse
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 429 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/auth/signin/2fa [post]
+func (h *AuthHandler) VerifyTwoFactor(c *gin.Context) {
+	var req model.VerifyTwoFactorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := h.service.VerifyTwoFactor(c.Request.Context(), req)
+	if err != nil {
+		writeAuthError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// Refresh godoc
+// @Summary Обновить токены
+// @Description Обменивает refresh token на новую пару токенов. Предъявленный refresh token становится недействительным; его повторное использование завершает всю сессию.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body model.RefreshRequest true "Refresh token"
+// @Success 200 {object} model.SignInResponse
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Router /api/auth/refresh [post]
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	var req model.RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := h.service.Refresh(c.Request.Context(), req)
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
 		return
@@ -80,3 +166,553 @@ func (h *AuthHandler) SignIn(c *gin.Context) {
 
 	c.JSON(http.StatusOK, resp)
 }
+
+// Logout godoc
+// @Summary Выйти из системы
+// @Description Отзывает текущий access token. Если передан refresh_token, завершается и его сессия.
+// @Tags auth
+// @Accept json
+// @Security BearerAuth
+// @Param request body model.LogoutRequest false "Refresh token текущей сессии"
+// @Success 204
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/auth/logout [post]
+func (h *AuthHandler) Logout(c *gin.Context) {
+	accessToken := middleware.AccessTokenFromContext(c)
+	if accessToken == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "token not found in context"})
+		return
+	}
+
+	var req model.LogoutRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	if err := h.service.Logout(c.Request.Context(), accessToken, req); err != nil {
+		if status.Code(err) == codes.Unauthenticated {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": status.Convert(err).Message()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// RevokeAllSessions godoc
+// @Summary Завершить все сессии
+// @Description Отзывает все refresh token пользователя и выданные с ними access token, включая текущий.
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} model.RevokeAllSessionsResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/auth/sessions [delete]
+func (h *AuthHandler) RevokeAllSessions(c *gin.Context) {
+	accessToken := middleware.AccessTokenFromContext(c)
+	if accessToken == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "token not found in context"})
+		return
+	}
+
+	resp, err := h.service.RevokeAllSessions(c.Request.Context(), accessToken)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetMe godoc
+// @Summary Получить профиль
+// @Description Возвращает профиль текущего пользователя.
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} model.UserProfile
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/auth/me [get]
+func (h *AuthHandler) GetMe(c *gin.Context) {
+	accessToken := middleware.AccessTokenFromContext(c)
+	if accessToken == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "token not found in context"})
+		return
+	}
+
+	resp, err := h.service.GetMe(c.Request.Context(), accessToken)
+	if err != nil {
+		writeAuthError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// UpdateProfile godoc
+// @Summary Изменить профиль
+// @Description Изменяет имя текущего пользователя.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body model.UpdateProfileRequest true "Новые данные профиля"
+// @Success 200 {object} model.UserProfile
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/auth/me [patch]
+func (h *AuthHandler) UpdateProfile(c *gin.Context) {
+	accessToken := middleware.AccessTokenFromContext(c)
+	if accessToken == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "token not found in context"})
+		return
+	}
+
+	var req model.UpdateProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := h.service.UpdateProfile(c.Request.Context(), accessToken, req)
+	if err != nil {
+		writeAuthError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// ChangePassword godoc
+// @Summary Сменить пароль
+// @Description Проверяет текущий пароль и устанавливает новый, если он соответствует политике паролей. Все сессии пользователя завершаются, в ответе возвращается пара токенов новой сессии.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body model.ChangePasswordRequest true "Текущий и новый пароль"
+// @Success 200 {object} model.SignInResponse
+// @Failure 400 {object} model.ValidationErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/auth/me/password [post]
+func (h *AuthHandler) ChangePassword(c *gin.Context) {
+	accessToken := middleware.AccessTokenFromContext(c)
+	if accessToken == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "token not found in context"})
+		return
+	}
+
+	var req model.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := h.service.ChangePassword(c.Request.Context(), accessToken, req)
+	if err != nil {
+		writeAuthError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// DeleteAccount godoc
+// @Summary Удалить аккаунт
+// @Description Удаляет текущего пользователя после проверки пароля и завершает все его сессии. Данные пользователя в ledger удаляются асинхронно.
+// @Tags auth
+// @Accept json
+// @Security BearerAuth
+// @Param request body model.DeleteAccountRequest true "Пароль для подтверждения"
+// @Success 204
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/auth/me [delete]
+func (h *AuthHandler) DeleteAccount(c *gin.Context) {
+	accessToken := middleware.AccessTokenFromContext(c)
+	if accessToken == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "token not found in context"})
+		return
+	}
+
+	var req model.DeleteAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.service.DeleteAccount(c.Request.Context(), accessToken, req); err != nil {
+		writeAuthError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// RequestEmailVerification godoc
+// @Summary Отправить письмо для подтверждения email
+// @Description Отправляет новую ссылку для подтверждения email текущего пользователя. Ранее отправленные ссылки перестают действовать.
+// @Tags auth
+// @Security BearerAuth
+// @Success 204
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 409 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/auth/email/verification [post]
+func (h *AuthHandler) RequestEmailVerification(c *gin.Context) {
+	accessToken := middleware.AccessTokenFromContext(c)
+	if accessToken == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "token not found in context"})
+		return
+	}
+
+	if err := h.service.RequestEmailVerification(c.Request.Context(), accessToken); err != nil {
+		writeAuthError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ConfirmEmail godoc
+// @Summary Подтвердить email
+// @Description Подтверждает email по одноразовому токену из письма. Новое состояние попадает в access token после обновления токенов.
+// @Tags auth
+// @Accept json
+// @Param request body model.ConfirmEmailRequest true "Токен из письма"
+// @Success 204
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/auth/email/verification/confirm [post]
+func (h *AuthHandler) ConfirmEmail(c *gin.Context) {
+	var req model.ConfirmEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.service.ConfirmEmail(c.Request.Context(), req); err != nil {
+		writeAuthError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// RequestPasswordReset godoc
+// @Summary Запросить сброс пароля
+// @Description Отправляет ссылку для сброса пароля, если пользователь с таким email существует. Ответ не зависит от того, зарегистрирован ли email.
+// @Tags auth
+// @Accept json
+// @Param request body model.PasswordResetRequest true "Email пользователя"
+// @Success 202
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/auth/password/reset [post]
+func (h *AuthHandler) RequestPasswordReset(c *gin.Context) {
+	var req model.PasswordResetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.service.RequestPasswordReset(c.Request.Context(), req); err != nil {
+		writeAuthError(c, err)
+		return
+	}
+
+	c.Status(http.StatusAccepted)
+}
+
+// ResetPassword godoc
+// @Summary Сбросить пароль
+// @Description Устанавливает новый пароль по одноразовому токену из письма и завершает все сессии пользователя. Если пароль не соответствует политике паролей, токен остается действительным.
+// @Tags auth
+// @Accept json
+// @Param request body model.ResetPasswordRequest true "Токен из письма и новый пароль"
+// @Success 204
+// @Failure 400 {object} model.ValidationErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/auth/password/reset/confirm [post]
+func (h *AuthHandler) ResetPassword(c *gin.Context) {
+	var req model.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.service.ResetPassword(c.Request.Context(), req); err != nil {
+		writeAuthError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// EnrollTOTP godoc
+// @Summary Начать подключение двухфакторной аутентификации
+// @Description Создает секрет для приложения-аутентификатора и возвращает otpauth:// URI для QR-кода. Двухфакторная аутентификация включается только после подтверждения кодом.
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} model.EnrollTOTPResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 409 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/auth/2fa/totp [post]
+func (h *AuthHandler) EnrollTOTP(c *gin.Context) {
+	accessToken := middleware.AccessTokenFromContext(c)
+	if accessToken == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "token not found in context"})
+		return
+	}
+
+	resp, err := h.service.EnrollTOTP(c.Request.Context(), accessToken)
+	if err != nil {
+		writeAuthError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// ConfirmTOTP godoc
+// @Summary Включить двухфакторную аутентификацию
+// @Description Проверяет код из приложения-аутентификатора, включает двухфакторную аутентификацию и возвращает резервные коды. Коды хранятся в виде хешей и больше не показываются.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body model.ConfirmTOTPRequest true "Код из приложения"
+// @Success 200 {object} model.ConfirmTOTPResponse
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 409 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/auth/2fa/totp/confirm [post]
+func (h *AuthHandler) ConfirmTOTP(c *gin.Context) {
+	accessToken := middleware.AccessTokenFromContext(c)
+	if accessToken == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "token not found in context"})
+		return
+	}
+
+	var req model.ConfirmTOTPRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := h.service.ConfirmTOTP(c.Request.Context(), accessToken, req)
+	if err != nil {
+		writeAuthError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// DisableTOTP godoc
+// @Summary Отключить двухфакторную аутентификацию
+// @Description Отключает двухфакторную аутентификацию и удаляет резервные коды. Требуются пароль и текущий код или резервный код.
+// @Tags auth
+// @Accept json
+// @Security BearerAuth
+// @Param request body model.DisableTOTPRequest true "Пароль и код"
+// @Success 204
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 409 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/auth/2fa/totp [delete]
+func (h *AuthHandler) DisableTOTP(c *gin.Context) {
+	accessToken := middleware.AccessTokenFromContext(c)
+	if accessToken == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "token not found in context"})
+		return
+	}
+
+	var req model.DisableTOTPRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.service.DisableTOTP(c.Request.Context(), accessToken, req); err != nil {
+		writeAuthError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListIdentityProviders godoc
+// @Summary Список внешних провайдеров входа
+// @Description Возвращает имена настроенных OpenID Connect провайдеров, через которых можно войти.
+// @Tags auth
+// @Produce json
+// @Success 200 {object} model.IdentityProvidersResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/auth/oidc/providers [get]
+func (h *AuthHandler) ListIdentityProviders(c *gin.Context) {
+	resp, err := h.service.ListIdentityProviders(c.Request.Context())
+	if err != nil {
+		writeAuthError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// StartOIDCLogin godoc
+// @Summary Войти через внешнего провайдера
+// @Description Перенаправляет на страницу входа провайдера (authorization code flow с PKCE). State дополнительно сохраняется в HttpOnly cookie и проверяется при возврате на callback.
+// @Tags auth
+// @Param provider path string true "Имя провайдера"
+// @Success 302
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Failure 503 {object} model.ErrorResponse
+// @Router /api/auth/oidc/{provider}/login [get]
+func (h *AuthHandler) StartOIDCLogin(c *gin.Context) {
+	authorization, err := h.service.StartOIDCLogin(c.Request.Context(), c.Param("provider"))
+	if err != nil {
+		writeAuthError(c, err)
+		return
+	}
+
+	maxAge := int(time.Until(authorization.ExpiresAt).Seconds())
+	setOIDCStateCookie(c, authorization.State, maxAge)
+	c.Redirect(http.StatusFound, authorization.URL)
+}
+
+// CompleteOIDCLogin godoc
+// @Summary Завершить вход через внешнего провайдера
+// @Description Адрес возврата от провайдера. Проверяет state по cookie, обменивает код на токены провайдера и возвращает пару токенов. Новая внешняя учетная запись привязывается к пользователю с тем же подтвержденным email или создает нового пользователя без пароля. Если включена двухфакторная аутентификация, возвращается challenge_token.
+// @Tags auth
+// @Produce json
+// @Param provider path string true "Имя провайдера"
+// @Param code query string false "Код авторизации"
+// @Param state query string false "State из запроса на вход"
+// @Param error query string false "Ошибка, возвращенная провайдером"
+// @Success 200 {object} model.SignInResponse
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 409 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Failure 503 {object} model.ErrorResponse
+// @Router /api/auth/oidc/{provider}/callback [get]
+func (h *AuthHandler) CompleteOIDCLogin(c *gin.Context) {
+	cookieState, _ := c.Cookie(oidcStateCookie)
+	setOIDCStateCookie(c, "", -1)
+
+	if providerErr := c.Query("error"); providerErr != "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "identity provider returned " + providerErr})
+		return
+	}
+	state, code := c.Query("state"), c.Query("code")
+	if state == "" || code == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "state and code are required"})
+		return
+	}
+	if subtle.ConstantTimeCompare([]byte(state), []byte(cookieState)) != 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "state does not match the sign-in request"})
+		return
+	}
+
+	resp, err := h.service.CompleteOIDCLogin(c.Request.Context(), c.Param("provider"), state, code)
+	if err != nil {
+		writeAuthError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// oidcStateCookie binds the callback to the browser that started the sign-in,
+// so a callback URL planted by someone else is rejected.
+const oidcStateCookie = "oidc_state"
+
+func setOIDCStateCookie(c *gin.Context, value string, maxAge int) {
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcStateCookie, value, maxAge, "/api/auth/oidc", "", c.Request.TLS != nil, true)
+}
+
+// writeAuthError maps a gRPC error returned by the auth or ledger service to an
+// HTTP response.
+func writeAuthError(c *gin.Context, err error) {
+	code := http.StatusInternalServerError
+	switch status.Code(err) {
+	case codes.ResourceExhausted:
+		code = http.StatusTooManyRequests
+		if retryAfter, ok := retryDelay(err); ok {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+		}
+	case codes.InvalidArgument:
+		code = http.StatusBadRequest
+	case codes.Unauthenticated:
+		code = http.StatusUnauthorized
+	case codes.PermissionDenied:
+		code = http.StatusForbidden
+	case codes.NotFound:
+		code = http.StatusNotFound
+	case codes.FailedPrecondition, codes.AlreadyExists:
+		code = http.StatusConflict
+	case codes.Unavailable:
+		code = http.StatusServiceUnavailable
+	}
+	if code == http.StatusInternalServerError {
+		c.JSON(code, gin.H{"error": err.Error()})
+		return
+	}
+	if violations := fieldViolations(err); len(violations) > 0 {
+		c.JSON(code, model.ValidationErrorResponse{Error: status.Convert(err).Message(), Violations: violations})
+		return
+	}
+	c.JSON(code, gin.H{"error": status.Convert(err).Message()})
+}
+
+// fieldViolations returns the BadRequest field violations of a gRPC error.
+func fieldViolations(err error) []model.FieldViolation {
+	var violations []model.FieldViolation
+	for _, detail := range status.Convert(err).Details() {
+		badRequest, ok := detail.(*errdetails.BadRequest)
+		if !ok {
+			continue
+		}
+		for _, violation := range badRequest.GetFieldViolations() {
+			violations = append(violations, model.FieldViolation{
+				Field:       violation.GetField(),
+				Reason:      violation.GetReason(),
+				Description: violation.GetDescription(),
+			})
+		}
+	}
+	return violations
+}
+
+// retryDelay returns the RetryInfo detail of a gRPC error.
+func retryDelay(err error) (time.Duration, bool) {
+	for _, detail := range status.Convert(err).Details() {
+		if info, ok := detail.(*errdetails.RetryInfo); ok && info.GetRetryDelay() != nil {
+			return info.GetRetryDelay().AsDuration(), true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,166 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/Deevins/final-task-course-2-go-lang/gateway/internal/model"
+	"github.com/gin-gonic/gin"
+)
+
+// ListGoals godoc
+// @Summary Получить список целей накоплений
+// @Description Возвращает цели счета по сроку с текущим прогрессом: накопленной суммой, остатком и ежемесячным взносом, нужным, чтобы успеть к сроку.
+// @Tags goals
+// @Produce json
+// @Security BearerAuth
+// @Param X-Account-ID header string false "ID общего счета; по умолчанию личный счет пользователя"
+// @Success 200 {object} model.GoalsResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/ledger/goals [get]
+func (h *LedgerHandler) ListGoals(c *gin.Context) {
+	accountID := accountIDFromRequest(c)
+	if accountID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "account_id is required"})
+		return
+	}
+
+	items, err := h.service.ListGoals(c.Request.Context(), accountID)
+	if err != nil {
+		writeAuthError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, model.GoalsResponse{Goals: items})
+}
+
+// CreateGoal godoc
+// @Summary Создать цель накоплений
+// @Description Создает цель с целевой суммой, валютой и сроком. Взносы учитываются либо расходами в категории category или с тегом tag за вычетом возвратов и доходов в них, либо балансом кошелька wallet_id; нужно указать ровно один источник. Валюта цели с кошельком должна совпадать с валютой кошелька.
+// @Tags goals
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param X-Account-ID header string false "ID общего счета; по умолчанию личный счет пользователя"
+// @Param request body model.GoalRequest true "Данные цели"
+// @Success 201 {object} model.Goal
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/ledger/goals [post]
+func (h *LedgerHandler) CreateGoal(c *gin.Context) {
+	var req model.GoalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	accountID := accountIDFromRequest(c)
+	if accountID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "account_id is required"})
+		return
+	}
+
+	created, err := h.service.CreateGoal(c.Request.Context(), accountID, req)
+	if err != nil {
+		writeAuthError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, created)
+}
+
+// GetGoal godoc
+// @Summary Получить цель накоплений
+// @Description Возвращает цель счета с текущим прогрессом.
+// @Tags goals
+// @Produce json
+// @Security BearerAuth
+// @Param X-Account-ID header string false "ID общего счета; по умолчанию личный счет пользователя"
+// @Param id path string true "ID цели"
+// @Success 200 {object} model.Goal
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/ledger/goals/{id} [get]
+func (h *LedgerHandler) GetGoal(c *gin.Context) {
+	accountID := accountIDFromRequest(c)
+	if accountID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "account_id is required"})
+		return
+	}
+
+	item, err := h.service.GetGoal(c.Request.Context(), accountID, c.Param("id"))
+	if err != nil {
+		writeAuthError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, item)
+}
+
+// UpdateGoal godoc
+// @Summary Изменить цель накоплений
+// @Description Заменяет название, целевую сумму, валюту, срок и источник взносов цели.
+// @Tags goals
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param X-Account-ID header string false "ID общего счета; по умолчанию личный счет пользователя"
+// @Param id path string true "ID цели"
+// @Param request body model.GoalRequest true "Данные цели"
+// @Success 200 {object} model.Goal
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/ledger/goals/{id} [put]
+func (h *LedgerHandler) UpdateGoal(c *gin.Context) {
+	var req model.GoalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	accountID := accountIDFromRequest(c)
+	if accountID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "account_id is required"})
+		return
+	}
+
+	updated, err := h.service.UpdateGoal(c.Request.Context(), accountID, c.Param("id"), req)
+	if err != nil {
+		writeAuthError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, updated)
+}
+
+// DeleteGoal godoc
+// @Summary Удалить цель накоплений
+// @Description Удаляет цель. Транзакции-взносы не меняются.
+// @Tags goals
+// @Produce json
+// @Security BearerAuth
+// @Param X-Account-ID header string false "ID общего счета; по умолчанию личный счет пользователя"
+// @Param id path string true "ID цели"
+// @Success 200 {object} model.DeleteResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/ledger/goals/{id} [delete]
+func (h *LedgerHandler) DeleteGoal(c *gin.Context) {
+	accountID := accountIDFromRequest(c)
+	if accountID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "account_id is required"})
+		return
+	}
+
+	deleted, err := h.service.DeleteGoal(c.Request.Context(), accountID, c.Param("id"))
+	if err != nil {
+		writeAuthError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, model.DeleteResponse{Deleted: deleted})
+}
@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/Deevins/final-task-course-2-go-lang/gateway/internal/model"
+	"github.com/gin-gonic/gin"
+)
+
+// ListJournalEntries godoc
+// @Summary Получить журнал двойной записи
+// @Description Возвращает записи журнала по транзакциям счета. У каждой записи не меньше двух проводок, их сумма в каждой валюте равна нулю.
+// @Tags journal
+// @Produce json
+// @Security BearerAuth
+// @Param X-Account-ID header string false "ID общего счета; по умолчанию личный счет пользователя"
+// @Success 200 {object} model.JournalEntriesResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/ledger/journal [get]
+func (h *LedgerHandler) ListJournalEntries(c *gin.Context) {
+	accountID := accountIDFromRequest(c)
+	if accountID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not found in context"})
+		return
+	}
+
+	entries, err := h.service.ListJournalEntries(c.Request.Context(), accountID)
+	if err != nil {
+		writeAuthError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, model.JournalEntriesResponse{Entries: entries})
+}
+
+// GetTrialBalance godoc
+// @Summary Получить оборотно-сальдовую ведомость
+// @Description Возвращает сальдо каждого счета журнала по валютам и итоги дебета и кредита.
+// @Tags journal
+// @Produce json
+// @Security BearerAuth
+// @Param X-Account-ID header string false "ID общего счета; по умолчанию личный счет пользователя"
+// @Success 200 {object} model.TrialBalance
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/ledger/journal/trial-balance [get]
+func (h *LedgerHandler) GetTrialBalance(c *gin.Context) {
+	accountID := accountIDFromRequest(c)
+	if accountID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not found in context"})
+		return
+	}
+
+	balance, err := h.service.GetTrialBalance(c.Request.Context(), accountID)
+	if err != nil {
+		writeAuthError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, balance)
+}
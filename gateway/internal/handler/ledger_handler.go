@@ -22,16 +22,25 @@ func NewLedgerHandler(s service.LedgerGatewayService) *LedgerHandler {
 
 func (h *LedgerHandler) Register(r *gin.RouterGroup, authMiddleware gin.HandlerFunc) {
 	ledger := r.Group("/ledger")
-	ledger.Use(authMiddleware)
+	ledger.Use(authMiddleware, middleware.RequireVerifiedEmail())
 	{
 		transactions := ledger.Group("/transactions")
 		{
 			transactions.GET("", h.ListTransactions)
 			transactions.POST("", h.CreateTransaction)
+			transactions.POST("/batch", h.BatchCreateTransactions)
+			transactions.PUT("/batch", h.BatchUpdateTransactions)
+			transactions.POST("/batch/delete", h.BatchDeleteTransactions)
 			transactions.GET("/:id", h.GetTransaction)
 			transactions.PUT("/:id", h.UpdateTransaction)
 			transactions.PATCH("/:id", h.UpdateTransaction)
 			transactions.DELETE("/:id", h.DeleteTransaction)
+			transactions.GET("/:id/history", h.TransactionHistory)
+			transactions.POST("/:id/restore", h.RestoreTransaction)
+			transactions.POST("/:id/refunds", h.RefundTransaction)
+			transactions.GET("/:id/refunds", h.ListRefunds)
+			transactions.POST("/:id/attachments", h.UploadAttachment)
+			transactions.GET("/:id/attachments", h.ListAttachments)
 		}
 		budgets := ledger.Group("/budgets")
 		{
@@ -41,6 +50,8 @@ func (h *LedgerHandler) Register(r *gin.RouterGroup, authMiddleware gin.HandlerF
 			budgets.PUT("/:id", h.UpdateBudget)
 			budgets.PATCH("/:id", h.UpdateBudget)
 			budgets.DELETE("/:id", h.DeleteBudget)
+			budgets.GET("/:id/history", h.BudgetHistory)
+			budgets.POST("/:id/restore", h.RestoreBudget)
 		}
 		reports := ledger.Group("/reports")
 		{
@@ -50,32 +61,127 @@ func (h *LedgerHandler) Register(r *gin.RouterGroup, authMiddleware gin.HandlerF
 			reports.PUT("/:id", h.UpdateReport)
 			reports.PATCH("/:id", h.UpdateReport)
 			reports.DELETE("/:id", h.DeleteReport)
+			reports.POST("/:id/restore", h.RestoreReport)
 		}
+		wallets := ledger.Group("/wallets")
+		{
+			wallets.GET("", h.ListWallets)
+			wallets.POST("", h.CreateWallet)
+			wallets.GET("/:id", h.GetWallet)
+			wallets.PUT("/:id", h.UpdateWallet)
+			wallets.DELETE("/:id", h.DeleteWallet)
+			wallets.GET("/:id/balance-history", h.WalletBalanceHistory)
+		}
+		tags := ledger.Group("/tags")
+		{
+			tags.GET("", h.ListTags)
+			tags.POST("", h.CreateTag)
+			tags.GET("/:id", h.GetTag)
+			tags.PUT("/:id", h.UpdateTag)
+			tags.DELETE("/:id", h.DeleteTag)
+		}
+		rules := ledger.Group("/rules")
+		{
+			rules.GET("", h.ListRules)
+			rules.POST("", h.CreateRule)
+			rules.POST("/preview", h.PreviewRule)
+			rules.POST("/apply", h.ApplyRules)
+			rules.GET("/:id", h.GetRule)
+			rules.PUT("/:id", h.UpdateRule)
+			rules.DELETE("/:id", h.DeleteRule)
+		}
+		payees := ledger.Group("/payees")
+		{
+			payees.GET("", h.ListPayees)
+			payees.POST("", h.CreatePayee)
+			payees.GET("/:id", h.GetPayee)
+			payees.PUT("/:id", h.UpdatePayee)
+			payees.DELETE("/:id", h.DeletePayee)
+		}
+		goals := ledger.Group("/goals")
+		{
+			goals.GET("", h.ListGoals)
+			goals.POST("", h.CreateGoal)
+			goals.GET("/:id", h.GetGoal)
+			goals.PUT("/:id", h.UpdateGoal)
+			goals.DELETE("/:id", h.DeleteGoal)
+		}
+		attachments := ledger.Group("/attachments")
+		{
+			attachments.GET("/:id", h.GetAttachment)
+			attachments.GET("/:id/content", h.DownloadAttachment)
+			attachments.DELETE("/:id", h.DeleteAttachment)
+		}
+		ledger.POST("/transfers", h.CreateTransfer)
+		ledger.DELETE("/transfers/:id", h.DeleteTransfer)
+		ledger.GET("/journal", h.ListJournalEntries)
+		ledger.GET("/journal/trial-balance", h.GetTrialBalance)
 		ledger.POST("/import", h.ImportTransactions)
 		ledger.GET("/export", h.ExportTransactions)
+		ledger.GET("/trash", h.ListTrash)
+		ledger.GET("/access-log", h.AccessLog)
+		accounts := ledger.Group("/accounts")
+		{
+			accounts.GET("", h.ListAccounts)
+			accounts.POST("", h.CreateAccount)
+			accounts.GET("/:account_id/members", h.ListMembers)
+			accounts.PATCH("/:account_id/members/:user_id", h.UpdateMember)
+			accounts.DELETE("/:account_id/members/:user_id", h.RemoveMember)
+			accounts.GET("/:account_id/invitations", h.ListInvitations)
+			accounts.POST("/:account_id/invitations", h.CreateInvitation)
+			accounts.DELETE("/:account_id/invitations/:id", h.RevokeInvitation)
+		}
+		ledger.POST("/invitations/accept", h.AcceptInvitation)
 	}
 }
 
 // ListTransactions godoc
 // @Summary Получить список транзакций
-// @Description Возвращает транзакции пользователя из Ledger.
+// @Description Возвращает транзакции пользователя из Ledger. Если передан хотя бы один параметр поиска, транзакции фильтруются и сортируются на стороне Ledger.
 // @Tags ledger
 // @Produce json
 // @Security BearerAuth
+// @Param X-Account-ID header string false "ID общего счета; по умолчанию личный счет пользователя"
+// @Param from query string false "Начало периода (RFC3339 или YYYY-MM-DD)"
+// @Param to query string false "Конец периода (RFC3339 или YYYY-MM-DD, включительно)"
+// @Param category query string false "Категория"
+// @Param currency query string false "Валюта"
+// @Param wallet_id query string false "ID кошелька"
+// @Param tag query string false "Тег"
+// @Param payee_id query string false "ID получателя платежа"
+// @Param min query number false "Минимальная сумма по модулю"
+// @Param max query number false "Максимальная сумма по модулю"
+// @Param q query string false "Подстрока в описании"
+// @Param sort query string false "Сортировка: occurred_at, -occurred_at, amount, -amount"
+// @Param limit query int false "Количество записей"
+// @Param offset query int false "Смещение"
 // @Success 200 {object} model.TransactionsResponse
+// @Failure 400 {object} model.ErrorResponse
 // @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
 // @Failure 500 {object} model.ErrorResponse
 // @Router /api/ledger/transactions [get]
 func (h *LedgerHandler) ListTransactions(c *gin.Context) {
-	accountID := middleware.UserIDFromContext(c)
+	accountID := accountIDFromRequest(c)
 	if accountID == "" {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not found in context"})
 		return
 	}
 
-	items, err := h.service.ListTransactions(c.Request.Context(), accountID)
+	query, ok, err := parseTransactionSearchQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var items []model.Transaction
+	if ok {
+		items, err = h.service.SearchTransactions(c.Request.Context(), accountID, query)
+	} else {
+		items, err = h.service.ListTransactions(c.Request.Context(), accountID)
+	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeAuthError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"transactions": items})
@@ -83,15 +189,17 @@ func (h *LedgerHandler) ListTransactions(c *gin.Context) {
 
 // CreateTransaction godoc
 // @Summary Создать транзакцию
-// @Description Создает транзакцию. Если account_id не указан, берется из JWT.
+// @Description Создает транзакцию. Если account_id не указан, используется счет из заголовка X-Account-ID или личный счет пользователя. Автор транзакции сохраняется в created_by. Чтобы разделить сумму между категориями, передайте splits вместо category: не меньше двух частей со знаком суммы, в сумме равных ей.
 // @Tags ledger
 // @Accept json
 // @Produce json
 // @Security BearerAuth
+// @Param X-Account-ID header string false "ID общего счета; по умолчанию личный счет пользователя"
 // @Param request body model.CreateTransactionRequest true "Данные транзакции"
 // @Success 201 {object} model.Transaction
 // @Failure 400 {object} model.ErrorResponse
 // @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
 // @Failure 500 {object} model.ErrorResponse
 // @Router /api/ledger/transactions [post]
 func (h *LedgerHandler) CreateTransaction(c *gin.Context) {
@@ -102,7 +210,7 @@ func (h *LedgerHandler) CreateTransaction(c *gin.Context) {
 	}
 
 	if req.AccountID == "" {
-		req.AccountID = middleware.UserIDFromContext(c)
+		req.AccountID = accountIDFromRequest(c)
 	}
 	if req.AccountID == "" {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "account_id is required"})
@@ -111,7 +219,7 @@ func (h *LedgerHandler) CreateTransaction(c *gin.Context) {
 
 	created, err := h.service.CreateTransaction(c.Request.Context(), req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeAuthError(c, err)
 		return
 	}
 	c.JSON(http.StatusCreated, created)
@@ -127,6 +235,7 @@ func (h *LedgerHandler) CreateTransaction(c *gin.Context) {
 // @Success 200 {object} model.Transaction
 // @Failure 400 {object} model.ErrorResponse
 // @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
 // @Failure 500 {object} model.ErrorResponse
 // @Router /api/ledger/transactions/{id} [get]
 func (h *LedgerHandler) GetTransaction(c *gin.Context) {
@@ -138,7 +247,7 @@ func (h *LedgerHandler) GetTransaction(c *gin.Context) {
 
 	item, err := h.service.GetTransaction(c.Request.Context(), id)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeAuthError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, item)
@@ -146,16 +255,19 @@ func (h *LedgerHandler) GetTransaction(c *gin.Context) {
 
 // UpdateTransaction godoc
 // @Summary Обновить транзакцию
-// @Description Обновляет транзакцию по идентификатору.
+// @Description Обновляет транзакцию по идентификатору, в том числе ее части (splits). Транзакции перевода между кошельками и возвраты изменить нельзя; у расхода с возвратами каждая часть должна покрывать возвраты по ней.
 // @Tags ledger
 // @Accept json
 // @Produce json
 // @Security BearerAuth
+// @Param X-Account-ID header string false "ID общего счета; по умолчанию личный счет пользователя"
 // @Param id path string true "ID транзакции"
 // @Param request body model.UpdateTransactionRequest true "Данные транзакции"
 // @Success 200 {object} model.Transaction
 // @Failure 400 {object} model.ErrorResponse
 // @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 409 {object} model.ErrorResponse
 // @Failure 500 {object} model.ErrorResponse
 // @Router /api/ledger/transactions/{id} [put]
 // @Router /api/ledger/transactions/{id} [patch]
@@ -173,7 +285,7 @@ func (h *LedgerHandler) UpdateTransaction(c *gin.Context) {
 	}
 
 	if req.AccountID == "" {
-		req.AccountID = middleware.UserIDFromContext(c)
+		req.AccountID = accountIDFromRequest(c)
 	}
 	if req.AccountID == "" {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "account_id is required"})
@@ -182,7 +294,7 @@ func (h *LedgerHandler) UpdateTransaction(c *gin.Context) {
 
 	updated, err := h.service.UpdateTransaction(c.Request.Context(), id, req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeAuthError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, updated)
@@ -190,7 +302,7 @@ func (h *LedgerHandler) UpdateTransaction(c *gin.Context) {
 
 // DeleteTransaction godoc
 // @Summary Удалить транзакцию
-// @Description Удаляет транзакцию по идентификатору.
+// @Description Удаляет транзакцию по идентификатору. Транзакции перевода удаляются только вместе через DELETE /api/ledger/transfers/{id}. Расход с возвратами можно удалить только после его возвратов.
 // @Tags ledger
 // @Produce json
 // @Security BearerAuth
@@ -198,6 +310,8 @@ func (h *LedgerHandler) UpdateTransaction(c *gin.Context) {
 // @Success 200 {object} model.DeleteResponse
 // @Failure 400 {object} model.ErrorResponse
 // @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 409 {object} model.ErrorResponse
 // @Failure 500 {object} model.ErrorResponse
 // @Router /api/ledger/transactions/{id} [delete]
 func (h *LedgerHandler) DeleteTransaction(c *gin.Context) {
@@ -209,24 +323,134 @@ func (h *LedgerHandler) DeleteTransaction(c *gin.Context) {
 
 	deleted, err := h.service.DeleteTransaction(c.Request.Context(), id)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeAuthError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, model.DeleteResponse{Deleted: deleted})
 }
 
+// BatchCreateTransactions godoc
+// @Summary Создать транзакции пакетом
+// @Description Создает до 500 транзакций за один запрос. В режиме atomic транзакции сохраняются, только если все элементы прошли проверки; иначе возвращается результат по каждому элементу.
+// @Tags ledger
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param X-Account-ID header string false "ID общего счета; по умолчанию личный счет пользователя"
+// @Param request body model.BatchCreateTransactionsRequest true "Транзакции"
+// @Success 200 {object} model.BatchTransactionsResponse
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/ledger/transactions/batch [post]
+func (h *LedgerHandler) BatchCreateTransactions(c *gin.Context) {
+	var req model.BatchCreateTransactionsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	accountID := accountIDFromRequest(c)
+	if accountID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not found in context"})
+		return
+	}
+
+	resp, err := h.service.BatchCreateTransactions(c.Request.Context(), accountID, req)
+	if err != nil {
+		writeAuthError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// BatchUpdateTransactions godoc
+// @Summary Обновить транзакции пакетом
+// @Description Обновляет до 500 транзакций за один запрос и возвращает результат по каждому элементу.
+// @Tags ledger
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param X-Account-ID header string false "ID общего счета; по умолчанию личный счет пользователя"
+// @Param request body model.BatchUpdateTransactionsRequest true "Транзакции"
+// @Success 200 {object} model.BatchTransactionsResponse
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/ledger/transactions/batch [put]
+func (h *LedgerHandler) BatchUpdateTransactions(c *gin.Context) {
+	var req model.BatchUpdateTransactionsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	accountID := accountIDFromRequest(c)
+	if accountID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not found in context"})
+		return
+	}
+
+	resp, err := h.service.BatchUpdateTransactions(c.Request.Context(), accountID, req)
+	if err != nil {
+		writeAuthError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// BatchDeleteTransactions godoc
+// @Summary Удалить транзакции пакетом
+// @Description Перемещает до 500 транзакций в корзину за один запрос и возвращает результат по каждому элементу.
+// @Tags ledger
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param X-Account-ID header string false "ID общего счета; по умолчанию личный счет пользователя"
+// @Param request body model.BatchDeleteTransactionsRequest true "Идентификаторы транзакций"
+// @Success 200 {object} model.BatchTransactionsResponse
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/ledger/transactions/batch/delete [post]
+func (h *LedgerHandler) BatchDeleteTransactions(c *gin.Context) {
+	var req model.BatchDeleteTransactionsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	accountID := accountIDFromRequest(c)
+	if accountID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not found in context"})
+		return
+	}
+
+	resp, err := h.service.BatchDeleteTransactions(c.Request.Context(), accountID, req)
+	if err != nil {
+		writeAuthError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
 // ListBudgets godoc
 // @Summary Получить список бюджетов
 // @Description Возвращает бюджеты из Ledger.
 // @Tags ledger
 // @Produce json
 // @Security BearerAuth
+// @Param X-Account-ID header string false "ID общего счета; по умолчанию личный счет пользователя"
 // @Success 200 {object} model.BudgetsResponse
 // @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
 // @Failure 500 {object} model.ErrorResponse
 // @Router /api/ledger/budgets [get]
 func (h *LedgerHandler) ListBudgets(c *gin.Context) {
-	accountID := middleware.UserIDFromContext(c)
+	accountID := accountIDFromRequest(c)
 	if accountID == "" {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not found in context"})
 		return
@@ -234,7 +458,7 @@ func (h *LedgerHandler) ListBudgets(c *gin.Context) {
 
 	items, err := h.service.ListBudgets(c.Request.Context(), accountID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeAuthError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"budgets": items})
@@ -247,10 +471,12 @@ func (h *LedgerHandler) ListBudgets(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Security BearerAuth
+// @Param X-Account-ID header string false "ID общего счета; по умолчанию личный счет пользователя"
 // @Param request body model.CreateBudgetRequest true "Данные бюджета"
 // @Success 201 {object} model.Budget
 // @Failure 400 {object} model.ErrorResponse
 // @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
 // @Failure 500 {object} model.ErrorResponse
 // @Router /api/ledger/budgets [post]
 func (h *LedgerHandler) CreateBudget(c *gin.Context) {
@@ -260,7 +486,7 @@ func (h *LedgerHandler) CreateBudget(c *gin.Context) {
 		return
 	}
 
-	accountID := middleware.UserIDFromContext(c)
+	accountID := accountIDFromRequest(c)
 	if accountID == "" {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "account_id is required"})
 		return
@@ -268,7 +494,7 @@ func (h *LedgerHandler) CreateBudget(c *gin.Context) {
 
 	created, err := h.service.CreateBudget(c.Request.Context(), accountID, req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeAuthError(c, err)
 		return
 	}
 	c.JSON(http.StatusCreated, created)
@@ -280,10 +506,12 @@ func (h *LedgerHandler) CreateBudget(c *gin.Context) {
 // @Tags ledger
 // @Produce json
 // @Security BearerAuth
+// @Param X-Account-ID header string false "ID общего счета; по умолчанию личный счет пользователя"
 // @Param id path string true "ID бюджета"
 // @Success 200 {object} model.Budget
 // @Failure 400 {object} model.ErrorResponse
 // @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
 // @Failure 500 {object} model.ErrorResponse
 // @Router /api/ledger/budgets/{id} [get]
 func (h *LedgerHandler) GetBudget(c *gin.Context) {
@@ -293,7 +521,7 @@ func (h *LedgerHandler) GetBudget(c *gin.Context) {
 		return
 	}
 
-	accountID := middleware.UserIDFromContext(c)
+	accountID := accountIDFromRequest(c)
 	if accountID == "" {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "account_id is required"})
 		return
@@ -301,7 +529,7 @@ func (h *LedgerHandler) GetBudget(c *gin.Context) {
 
 	item, err := h.service.GetBudget(c.Request.Context(), accountID, id)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeAuthError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, item)
@@ -314,11 +542,13 @@ func (h *LedgerHandler) GetBudget(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Security BearerAuth
+// @Param X-Account-ID header string false "ID общего счета; по умолчанию личный счет пользователя"
 // @Param id path string true "ID бюджета"
 // @Param request body model.UpdateBudgetRequest true "Данные бюджета"
 // @Success 200 {object} model.Budget
 // @Failure 400 {object} model.ErrorResponse
 // @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
 // @Failure 500 {object} model.ErrorResponse
 // @Router /api/ledger/budgets/{id} [put]
 // @Router /api/ledger/budgets/{id} [patch]
@@ -335,7 +565,7 @@ func (h *LedgerHandler) UpdateBudget(c *gin.Context) {
 		return
 	}
 
-	accountID := middleware.UserIDFromContext(c)
+	accountID := accountIDFromRequest(c)
 	if accountID == "" {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "account_id is required"})
 		return
@@ -343,7 +573,7 @@ func (h *LedgerHandler) UpdateBudget(c *gin.Context) {
 
 	updated, err := h.service.UpdateBudget(c.Request.Context(), accountID, id, req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeAuthError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, updated)
@@ -355,10 +585,12 @@ func (h *LedgerHandler) UpdateBudget(c *gin.Context) {
 // @Tags ledger
 // @Produce json
 // @Security BearerAuth
+// @Param X-Account-ID header string false "ID общего счета; по умолчанию личный счет пользователя"
 // @Param id path string true "ID бюджета"
 // @Success 200 {object} model.DeleteResponse
 // @Failure 400 {object} model.ErrorResponse
 // @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
 // @Failure 500 {object} model.ErrorResponse
 // @Router /api/ledger/budgets/{id} [delete]
 func (h *LedgerHandler) DeleteBudget(c *gin.Context) {
@@ -368,7 +600,7 @@ func (h *LedgerHandler) DeleteBudget(c *gin.Context) {
 		return
 	}
 
-	accountID := middleware.UserIDFromContext(c)
+	accountID := accountIDFromRequest(c)
 	if accountID == "" {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "account_id is required"})
 		return
@@ -376,7 +608,7 @@ func (h *LedgerHandler) DeleteBudget(c *gin.Context) {
 
 	deleted, err := h.service.DeleteBudget(c.Request.Context(), accountID, id)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeAuthError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, model.DeleteResponse{Deleted: deleted})
@@ -388,12 +620,14 @@ func (h *LedgerHandler) DeleteBudget(c *gin.Context) {
 // @Tags ledger
 // @Produce json
 // @Security BearerAuth
+// @Param X-Account-ID header string false "ID общего счета; по умолчанию личный счет пользователя"
 // @Success 200 {object} model.ReportsResponse
 // @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
 // @Failure 500 {object} model.ErrorResponse
 // @Router /api/ledger/reports [get]
 func (h *LedgerHandler) ListReports(c *gin.Context) {
-	accountID := middleware.UserIDFromContext(c)
+	accountID := accountIDFromRequest(c)
 	if accountID == "" {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not found in context"})
 		return
@@ -401,7 +635,7 @@ func (h *LedgerHandler) ListReports(c *gin.Context) {
 
 	items, err := h.service.ListReports(c.Request.Context(), accountID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeAuthError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"reports": items})
@@ -414,10 +648,12 @@ func (h *LedgerHandler) ListReports(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Security BearerAuth
+// @Param X-Account-ID header string false "ID общего счета; по умолчанию личный счет пользователя"
 // @Param request body model.CreateReportRequest true "Данные отчета"
 // @Success 201 {object} model.Report
 // @Failure 400 {object} model.ErrorResponse
 // @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
 // @Failure 500 {object} model.ErrorResponse
 // @Router /api/ledger/reports [post]
 func (h *LedgerHandler) CreateReport(c *gin.Context) {
@@ -427,7 +663,7 @@ func (h *LedgerHandler) CreateReport(c *gin.Context) {
 		return
 	}
 
-	accountID := middleware.UserIDFromContext(c)
+	accountID := accountIDFromRequest(c)
 	if accountID == "" {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "account_id is required"})
 		return
@@ -435,7 +671,7 @@ func (h *LedgerHandler) CreateReport(c *gin.Context) {
 
 	created, err := h.service.CreateReport(c.Request.Context(), accountID, req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeAuthError(c, err)
 		return
 	}
 	c.JSON(http.StatusCreated, created)
@@ -447,10 +683,12 @@ func (h *LedgerHandler) CreateReport(c *gin.Context) {
 // @Tags ledger
 // @Produce json
 // @Security BearerAuth
+// @Param X-Account-ID header string false "ID общего счета; по умолчанию личный счет пользователя"
 // @Param id path string true "ID отчета"
 // @Success 200 {object} model.Report
 // @Failure 400 {object} model.ErrorResponse
 // @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
 // @Failure 500 {object} model.ErrorResponse
 // @Router /api/ledger/reports/{id} [get]
 func (h *LedgerHandler) GetReport(c *gin.Context) {
@@ -460,7 +698,7 @@ func (h *LedgerHandler) GetReport(c *gin.Context) {
 		return
 	}
 
-	accountID := middleware.UserIDFromContext(c)
+	accountID := accountIDFromRequest(c)
 	if accountID == "" {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "account_id is required"})
 		return
@@ -468,7 +706,7 @@ func (h *LedgerHandler) GetReport(c *gin.Context) {
 
 	item, err := h.service.GetReport(c.Request.Context(), accountID, id)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeAuthError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, item)
@@ -481,11 +719,13 @@ func (h *LedgerHandler) GetReport(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Security BearerAuth
+// @Param X-Account-ID header string false "ID общего счета; по умолчанию личный счет пользователя"
 // @Param id path string true "ID отчета"
 // @Param request body model.UpdateReportRequest true "Данные отчета"
 // @Success 200 {object} model.Report
 // @Failure 400 {object} model.ErrorResponse
 // @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
 // @Failure 500 {object} model.ErrorResponse
 // @Router /api/ledger/reports/{id} [put]
 // @Router /api/ledger/reports/{id} [patch]
@@ -502,7 +742,7 @@ func (h *LedgerHandler) UpdateReport(c *gin.Context) {
 		return
 	}
 
-	accountID := middleware.UserIDFromContext(c)
+	accountID := accountIDFromRequest(c)
 	if accountID == "" {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "account_id is required"})
 		return
@@ -510,7 +750,7 @@ func (h *LedgerHandler) UpdateReport(c *gin.Context) {
 
 	updated, err := h.service.UpdateReport(c.Request.Context(), accountID, id, req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeAuthError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, updated)
@@ -522,10 +762,12 @@ func (h *LedgerHandler) UpdateReport(c *gin.Context) {
 // @Tags ledger
 // @Produce json
 // @Security BearerAuth
+// @Param X-Account-ID header string false "ID общего счета; по умолчанию личный счет пользователя"
 // @Param id path string true "ID отчета"
 // @Success 200 {object} model.DeleteResponse
 // @Failure 400 {object} model.ErrorResponse
 // @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
 // @Failure 500 {object} model.ErrorResponse
 // @Router /api/ledger/reports/{id} [delete]
 func (h *LedgerHandler) DeleteReport(c *gin.Context) {
@@ -535,7 +777,7 @@ func (h *LedgerHandler) DeleteReport(c *gin.Context) {
 		return
 	}
 
-	accountID := middleware.UserIDFromContext(c)
+	accountID := accountIDFromRequest(c)
 	if accountID == "" {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "account_id is required"})
 		return
@@ -543,7 +785,7 @@ func (h *LedgerHandler) DeleteReport(c *gin.Context) {
 
 	deleted, err := h.service.DeleteReport(c.Request.Context(), accountID, id)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeAuthError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, model.DeleteResponse{Deleted: deleted})
@@ -551,15 +793,17 @@ func (h *LedgerHandler) DeleteReport(c *gin.Context) {
 
 // ImportTransactions godoc
 // @Summary Импортировать транзакции из CSV
-// @Description Принимает CSV контент и импортирует транзакции.
+// @Description Принимает CSV контент и импортирует транзакции. Необязательная седьмая колонка splits задает части разделенной транзакции в виде "категория:сумма;категория:сумма".
 // @Tags ledger
 // @Accept json
 // @Produce json
 // @Security BearerAuth
+// @Param X-Account-ID header string false "ID общего счета; по умолчанию личный счет пользователя"
 // @Param request body model.ImportTransactionsRequest true "CSV данные"
 // @Success 200 {object} model.ImportTransactionsResponse
 // @Failure 400 {object} model.ErrorResponse
 // @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
 // @Failure 500 {object} model.ErrorResponse
 // @Router /api/ledger/import [post]
 func (h *LedgerHandler) ImportTransactions(c *gin.Context) {
@@ -569,7 +813,7 @@ func (h *LedgerHandler) ImportTransactions(c *gin.Context) {
 		return
 	}
 
-	accountID := middleware.UserIDFromContext(c)
+	accountID := accountIDFromRequest(c)
 	if accountID == "" {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "account_id is required"})
 		return
@@ -577,7 +821,7 @@ func (h *LedgerHandler) ImportTransactions(c *gin.Context) {
 
 	imported, err := h.service.ImportTransactionsCSV(c.Request.Context(), accountID, []byte(req.CSVContent), req.HasHeader)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeAuthError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, model.ImportTransactionsResponse{Imported: imported})
@@ -585,16 +829,18 @@ func (h *LedgerHandler) ImportTransactions(c *gin.Context) {
 
 // ExportTransactions godoc
 // @Summary Экспортировать транзакции в CSV
-// @Description Возвращает CSV контент транзакций пользователя.
+// @Description Возвращает CSV контент транзакций пользователя. Колонка splits содержит части разделенных транзакций.
 // @Tags ledger
 // @Produce json
 // @Security BearerAuth
+// @Param X-Account-ID header string false "ID общего счета; по умолчанию личный счет пользователя"
 // @Success 200 {object} model.ExportTransactionsResponse
 // @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
 // @Failure 500 {object} model.ErrorResponse
 // @Router /api/ledger/export [get]
 func (h *LedgerHandler) ExportTransactions(c *gin.Context) {
-	accountID := middleware.UserIDFromContext(c)
+	accountID := accountIDFromRequest(c)
 	if accountID == "" {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not found in context"})
 		return
@@ -602,8 +848,225 @@ func (h *LedgerHandler) ExportTransactions(c *gin.Context) {
 
 	csvContent, err := h.service.ExportTransactionsCSV(c.Request.Context(), accountID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeAuthError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, model.ExportTransactionsResponse{CSVContent: string(csvContent)})
 }
+
+// TransactionHistory godoc
+// @Summary Получить историю изменений транзакции
+// @Description Возвращает журнал изменений транзакции: кто, когда и что изменил.
+// @Tags ledger
+// @Produce json
+// @Security BearerAuth
+// @Param X-Account-ID header string false "ID общего счета; по умолчанию личный счет пользователя"
+// @Param id path string true "ID транзакции"
+// @Success 200 {object} model.HistoryResponse
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/ledger/transactions/{id}/history [get]
+func (h *LedgerHandler) TransactionHistory(c *gin.Context) {
+	h.history(c, "transaction")
+}
+
+// BudgetHistory godoc
+// @Summary Получить историю изменений бюджета
+// @Description Возвращает журнал изменений бюджета: кто, когда и что изменил.
+// @Tags ledger
+// @Produce json
+// @Security BearerAuth
+// @Param X-Account-ID header string false "ID общего счета; по умолчанию личный счет пользователя"
+// @Param id path string true "ID бюджета"
+// @Success 200 {object} model.HistoryResponse
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/ledger/budgets/{id}/history [get]
+func (h *LedgerHandler) BudgetHistory(c *gin.Context) {
+	h.history(c, "budget")
+}
+
+// AccessLog godoc
+// @Summary Получить журнал доступа к своему счету
+// @Description Возвращает записи о том, когда сотрудники поддержки и администраторы просматривали данные счета.
+// @Tags ledger
+// @Produce json
+// @Security BearerAuth
+// @Param X-Account-ID header string false "ID общего счета; по умолчанию личный счет пользователя"
+// @Success 200 {object} model.HistoryResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/ledger/access-log [get]
+func (h *LedgerHandler) AccessLog(c *gin.Context) {
+	accountID := accountIDFromRequest(c)
+	if accountID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not found in context"})
+		return
+	}
+
+	entries, err := h.service.ListHistory(c.Request.Context(), accountID, "account", accountID)
+	if err != nil {
+		writeAuthError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, model.HistoryResponse{Entries: entries})
+}
+
+func (h *LedgerHandler) history(c *gin.Context, entityType string) {
+	id := c.Param("id")
+	if id == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "id is required"})
+		return
+	}
+
+	accountID := accountIDFromRequest(c)
+	if accountID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not found in context"})
+		return
+	}
+
+	entries, err := h.service.ListHistory(c.Request.Context(), accountID, entityType, id)
+	if err != nil {
+		writeAuthError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, model.HistoryResponse{Entries: entries})
+}
+
+// ListTrash godoc
+// @Summary Получить корзину
+// @Description Возвращает удаленные транзакции, бюджеты и отчеты, которые еще можно восстановить.
+// @Tags ledger
+// @Produce json
+// @Security BearerAuth
+// @Param X-Account-ID header string false "ID общего счета; по умолчанию личный счет пользователя"
+// @Success 200 {object} model.TrashResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/ledger/trash [get]
+func (h *LedgerHandler) ListTrash(c *gin.Context) {
+	accountID := accountIDFromRequest(c)
+	if accountID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not found in context"})
+		return
+	}
+
+	trash, err := h.service.ListTrash(c.Request.Context(), accountID)
+	if err != nil {
+		writeAuthError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, trash)
+}
+
+// RestoreTransaction godoc
+// @Summary Восстановить транзакцию
+// @Description Восстанавливает удаленную транзакцию из корзины. Расход повторно проверяется на соответствие бюджету.
+// @Tags ledger
+// @Produce json
+// @Security BearerAuth
+// @Param X-Account-ID header string false "ID общего счета; по умолчанию личный счет пользователя"
+// @Param id path string true "ID транзакции"
+// @Success 200 {object} model.Transaction
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/ledger/transactions/{id}/restore [post]
+func (h *LedgerHandler) RestoreTransaction(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "id is required"})
+		return
+	}
+
+	accountID := accountIDFromRequest(c)
+	if accountID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not found in context"})
+		return
+	}
+
+	restored, err := h.service.RestoreTransaction(c.Request.Context(), accountID, id)
+	if err != nil {
+		writeAuthError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, restored)
+}
+
+// RestoreBudget godoc
+// @Summary Восстановить бюджет
+// @Description Восстанавливает удаленный бюджет из корзины. Если с тех пор создан бюджет с тем же названием и месяцем, возвращает 409.
+// @Tags ledger
+// @Produce json
+// @Security BearerAuth
+// @Param X-Account-ID header string false "ID общего счета; по умолчанию личный счет пользователя"
+// @Param id path string true "ID бюджета"
+// @Success 200 {object} model.Budget
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 409 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/ledger/budgets/{id}/restore [post]
+func (h *LedgerHandler) RestoreBudget(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "id is required"})
+		return
+	}
+
+	accountID := accountIDFromRequest(c)
+	if accountID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not found in context"})
+		return
+	}
+
+	restored, err := h.service.RestoreBudget(c.Request.Context(), accountID, id)
+	if err != nil {
+		writeAuthError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, restored)
+}
+
+// RestoreReport godoc
+// @Summary Восстановить отчет
+// @Description Восстанавливает удаленный отчет из корзины.
+// @Tags ledger
+// @Produce json
+// @Security BearerAuth
+// @Param X-Account-ID header string false "ID общего счета; по умолчанию личный счет пользователя"
+// @Param id path string true "ID отчета"
+// @Success 200 {object} model.Report
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/ledger/reports/{id}/restore [post]
+func (h *LedgerHandler) RestoreReport(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "id is required"})
+		return
+	}
+
+	accountID := accountIDFromRequest(c)
+	if accountID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not found in context"})
+		return
+	}
+
+	restored, err := h.service.RestoreReport(c.Request.Context(), accountID, id)
+	if err != nil {
+		writeAuthError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, restored)
+}
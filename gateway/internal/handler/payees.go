@@ -0,0 +1,168 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/Deevins/final-task-course-2-go-lang/gateway/internal/model"
+	"github.com/gin-gonic/gin"
+)
+
+// ListPayees godoc
+// @Summary Получить список получателей платежей
+// @Description Возвращает получателей платежей счета по имени, включая созданных автоматически по описаниям транзакций.
+// @Tags payees
+// @Produce json
+// @Security BearerAuth
+// @Param X-Account-ID header string false "ID общего счета; по умолчанию личный счет пользователя"
+// @Success 200 {object} model.PayeesResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/ledger/payees [get]
+func (h *LedgerHandler) ListPayees(c *gin.Context) {
+	accountID := accountIDFromRequest(c)
+	if accountID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "account_id is required"})
+		return
+	}
+
+	items, err := h.service.ListPayees(c.Request.Context(), accountID)
+	if err != nil {
+		writeAuthError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, model.PayeesResponse{Payees: items})
+}
+
+// CreatePayee godoc
+// @Summary Создать получателя платежа
+// @Description Создает получателя с псевдонимами и категорией по умолчанию. Новые транзакции, описание которых после нормализации совпадает с именем или псевдонимом, привязываются к получателю. Имя и псевдонимы не должны совпадать с другими получателями счета.
+// @Tags payees
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param X-Account-ID header string false "ID общего счета; по умолчанию личный счет пользователя"
+// @Param request body model.PayeeRequest true "Данные получателя"
+// @Success 201 {object} model.Payee
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 409 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/ledger/payees [post]
+func (h *LedgerHandler) CreatePayee(c *gin.Context) {
+	var req model.PayeeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	accountID := accountIDFromRequest(c)
+	if accountID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "account_id is required"})
+		return
+	}
+
+	created, err := h.service.CreatePayee(c.Request.Context(), accountID, req)
+	if err != nil {
+		writeAuthError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, created)
+}
+
+// GetPayee godoc
+// @Summary Получить получателя платежа
+// @Description Возвращает получателя платежа счета.
+// @Tags payees
+// @Produce json
+// @Security BearerAuth
+// @Param X-Account-ID header string false "ID общего счета; по умолчанию личный счет пользователя"
+// @Param id path string true "ID получателя"
+// @Success 200 {object} model.Payee
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/ledger/payees/{id} [get]
+func (h *LedgerHandler) GetPayee(c *gin.Context) {
+	accountID := accountIDFromRequest(c)
+	if accountID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "account_id is required"})
+		return
+	}
+
+	item, err := h.service.GetPayee(c.Request.Context(), accountID, c.Param("id"))
+	if err != nil {
+		writeAuthError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, item)
+}
+
+// UpdatePayee godoc
+// @Summary Изменить получателя платежа
+// @Description Заменяет имя, псевдонимы и категорию по умолчанию. Привязанные транзакции остаются привязанными, новые псевдонимы действуют для новых транзакций.
+// @Tags payees
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param X-Account-ID header string false "ID общего счета; по умолчанию личный счет пользователя"
+// @Param id path string true "ID получателя"
+// @Param request body model.PayeeRequest true "Данные получателя"
+// @Success 200 {object} model.Payee
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 409 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/ledger/payees/{id} [put]
+func (h *LedgerHandler) UpdatePayee(c *gin.Context) {
+	var req model.PayeeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	accountID := accountIDFromRequest(c)
+	if accountID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "account_id is required"})
+		return
+	}
+
+	updated, err := h.service.UpdatePayee(c.Request.Context(), accountID, c.Param("id"), req)
+	if err != nil {
+		writeAuthError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, updated)
+}
+
+// DeletePayee godoc
+// @Summary Удалить получателя платежа
+// @Description Удаляет получателя и отвязывает от него транзакции. Транзакции сохраняют свои категории.
+// @Tags payees
+// @Produce json
+// @Security BearerAuth
+// @Param X-Account-ID header string false "ID общего счета; по умолчанию личный счет пользователя"
+// @Param id path string true "ID получателя"
+// @Success 200 {object} model.DeleteResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/ledger/payees/{id} [delete]
+func (h *LedgerHandler) DeletePayee(c *gin.Context) {
+	accountID := accountIDFromRequest(c)
+	if accountID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "account_id is required"})
+		return
+	}
+
+	deleted, err := h.service.DeletePayee(c.Request.Context(), accountID, c.Param("id"))
+	if err != nil {
+		writeAuthError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, model.DeleteResponse{Deleted: deleted})
+}
@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/Deevins/final-task-course-2-go-lang/gateway/internal/model"
+	"github.com/gin-gonic/gin"
+)
+
+// RefundTransaction godoc
+// @Summary Оформить возврат по расходу
+// @Description Создает возврат: положительную транзакцию в категории и кошельке расхода. Возврат уменьшает расход категории в отчетах и освобождает бюджет месяца, в котором он проведен. Возвраты частичные; в сумме они не превышают расход, для разделенного расхода — каждую его часть.
+// @Tags ledger
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param X-Account-ID header string false "ID общего счета; по умолчанию личный счет пользователя"
+// @Param id path string true "ID расхода"
+// @Param request body model.RefundTransactionRequest true "Данные возврата"
+// @Success 201 {object} model.Transaction
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 409 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/ledger/transactions/{id}/refunds [post]
+func (h *LedgerHandler) RefundTransaction(c *gin.Context) {
+	var req model.RefundTransactionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	accountID := accountIDFromRequest(c)
+	if accountID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "account_id is required"})
+		return
+	}
+
+	created, err := h.service.RefundTransaction(c.Request.Context(), accountID, c.Param("id"), req)
+	if err != nil {
+		writeAuthError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, created)
+}
+
+// ListRefunds godoc
+// @Summary Получить возвраты по расходу
+// @Description Возвращает возвраты расхода, не удаленные в корзину.
+// @Tags ledger
+// @Produce json
+// @Security BearerAuth
+// @Param X-Account-ID header string false "ID общего счета; по умолчанию личный счет пользователя"
+// @Param id path string true "ID расхода"
+// @Success 200 {object} model.TransactionsResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/ledger/transactions/{id}/refunds [get]
+func (h *LedgerHandler) ListRefunds(c *gin.Context) {
+	accountID := accountIDFromRequest(c)
+	if accountID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "account_id is required"})
+		return
+	}
+
+	items, err := h.service.ListRefunds(c.Request.Context(), accountID, c.Param("id"))
+	if err != nil {
+		writeAuthError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, model.TransactionsResponse{Transactions: items})
+}
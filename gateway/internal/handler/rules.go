@@ -0,0 +1,240 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/Deevins/final-task-course-2-go-lang/gateway/internal/model"
+	"github.com/gin-gonic/gin"
+)
+
+// ListRules godoc
+// @Summary Получить список правил категоризации
+// @Description Возвращает правила счета в порядке применения: по возрастанию приоритета, затем по времени создания.
+// @Tags rules
+// @Produce json
+// @Security BearerAuth
+// @Param X-Account-ID header string false "ID общего счета; по умолчанию личный счет пользователя"
+// @Success 200 {object} model.RulesResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/ledger/rules [get]
+func (h *LedgerHandler) ListRules(c *gin.Context) {
+	accountID := accountIDFromRequest(c)
+	if accountID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "account_id is required"})
+		return
+	}
+
+	items, err := h.service.ListRules(c.Request.Context(), accountID)
+	if err != nil {
+		writeAuthError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, model.RulesResponse{Rules: items})
+}
+
+// CreateRule godoc
+// @Summary Создать правило категоризации
+// @Description Создает правило, которое назначает категорию новым транзакциям, в том числе импортированным. Нужно хотя бы одно условие: подстрока или регулярное выражение для описания, границы суммы по модулю, тип или валюта.
+// @Tags rules
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param X-Account-ID header string false "ID общего счета; по умолчанию личный счет пользователя"
+// @Param request body model.RuleRequest true "Данные правила"
+// @Success 201 {object} model.Rule
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/ledger/rules [post]
+func (h *LedgerHandler) CreateRule(c *gin.Context) {
+	var req model.RuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	accountID := accountIDFromRequest(c)
+	if accountID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "account_id is required"})
+		return
+	}
+
+	created, err := h.service.CreateRule(c.Request.Context(), accountID, req)
+	if err != nil {
+		writeAuthError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, created)
+}
+
+// GetRule godoc
+// @Summary Получить правило категоризации
+// @Description Возвращает правило счета.
+// @Tags rules
+// @Produce json
+// @Security BearerAuth
+// @Param X-Account-ID header string false "ID общего счета; по умолчанию личный счет пользователя"
+// @Param id path string true "ID правила"
+// @Success 200 {object} model.Rule
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/ledger/rules/{id} [get]
+func (h *LedgerHandler) GetRule(c *gin.Context) {
+	accountID := accountIDFromRequest(c)
+	if accountID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "account_id is required"})
+		return
+	}
+
+	item, err := h.service.GetRule(c.Request.Context(), accountID, c.Param("id"))
+	if err != nil {
+		writeAuthError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, item)
+}
+
+// UpdateRule godoc
+// @Summary Изменить правило категоризации
+// @Description Заменяет условия, приоритет и категорию правила. Уже категоризированные транзакции не меняются.
+// @Tags rules
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param X-Account-ID header string false "ID общего счета; по умолчанию личный счет пользователя"
+// @Param id path string true "ID правила"
+// @Param request body model.RuleRequest true "Данные правила"
+// @Success 200 {object} model.Rule
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/ledger/rules/{id} [put]
+func (h *LedgerHandler) UpdateRule(c *gin.Context) {
+	var req model.RuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	accountID := accountIDFromRequest(c)
+	if accountID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "account_id is required"})
+		return
+	}
+
+	updated, err := h.service.UpdateRule(c.Request.Context(), accountID, c.Param("id"), req)
+	if err != nil {
+		writeAuthError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, updated)
+}
+
+// DeleteRule godoc
+// @Summary Удалить правило категоризации
+// @Description Удаляет правило. Транзакции сохраняют назначенные им категории.
+// @Tags rules
+// @Produce json
+// @Security BearerAuth
+// @Param X-Account-ID header string false "ID общего счета; по умолчанию личный счет пользователя"
+// @Param id path string true "ID правила"
+// @Success 200 {object} model.DeleteResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/ledger/rules/{id} [delete]
+func (h *LedgerHandler) DeleteRule(c *gin.Context) {
+	accountID := accountIDFromRequest(c)
+	if accountID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "account_id is required"})
+		return
+	}
+
+	deleted, err := h.service.DeleteRule(c.Request.Context(), accountID, c.Param("id"))
+	if err != nil {
+		writeAuthError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, model.DeleteResponse{Deleted: deleted})
+}
+
+// PreviewRule godoc
+// @Summary Проверить правило на истории
+// @Description Возвращает транзакции счета, под которые подходят условия правила, не сохраняя его. Разделенные транзакции, переводы, возвраты и расходы с возвратами не категоризируются.
+// @Tags rules
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param X-Account-ID header string false "ID общего счета; по умолчанию личный счет пользователя"
+// @Param request body model.RulePreviewRequest true "Условия правила"
+// @Success 200 {object} model.RuleMatchesResponse
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/ledger/rules/preview [post]
+func (h *LedgerHandler) PreviewRule(c *gin.Context) {
+	var req model.RulePreviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	accountID := accountIDFromRequest(c)
+	if accountID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "account_id is required"})
+		return
+	}
+
+	matches, err := h.service.PreviewRule(c.Request.Context(), accountID, req)
+	if err != nil {
+		writeAuthError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, model.RuleMatchesResponse{Matches: matches})
+}
+
+// ApplyRules godoc
+// @Summary Применить правила к истории
+// @Description Применяет включенные правила ко всем транзакциям счета и возвращает транзакции, у которых меняется категория. С dry_run изменения только возвращаются, без сохранения.
+// @Tags rules
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param X-Account-ID header string false "ID общего счета; по умолчанию личный счет пользователя"
+// @Param request body model.ApplyRulesRequest false "Параметры применения"
+// @Success 200 {object} model.RuleMatchesResponse
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/ledger/rules/apply [post]
+func (h *LedgerHandler) ApplyRules(c *gin.Context) {
+	var req model.ApplyRulesRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	accountID := accountIDFromRequest(c)
+	if accountID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "account_id is required"})
+		return
+	}
+
+	matches, err := h.service.ApplyRules(c.Request.Context(), accountID, req.DryRun)
+	if err != nil {
+		writeAuthError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, model.RuleMatchesResponse{Matches: matches})
+}
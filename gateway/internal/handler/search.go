@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/Deevins/final-task-course-2-go-lang/gateway/internal/model"
+	"github.com/gin-gonic/gin"
+)
+
+var transactionSearchParams = []string{"from", "to", "category", "currency", "wallet_id", "tag", "payee_id", "min", "max", "q", "sort", "limit", "offset"}
+
+// parseTransactionSearchQuery reads search parameters of GET /api/ledger/transactions.
+// The boolean result reports whether any of them was supplied.
+func parseTransactionSearchQuery(c *gin.Context) (model.TransactionSearchQuery, bool, error) {
+	present := false
+	for _, name := range transactionSearchParams {
+		if _, ok := c.GetQuery(name); ok {
+			present = true
+			break
+		}
+	}
+	if !present {
+		return model.TransactionSearchQuery{}, false, nil
+	}
+
+	query := model.TransactionSearchQuery{
+		Category: c.Query("category"),
+		Currency: c.Query("currency"),
+		WalletID: c.Query("wallet_id"),
+		Tag:      c.Query("tag"),
+		PayeeID:  c.Query("payee_id"),
+		Query:    c.Query("q"),
+		Sort:     c.Query("sort"),
+	}
+	var err error
+	if query.From, err = parseSearchTime(c.Query("from"), false); err != nil {
+		return query, true, fmt.Errorf("invalid from: %w", err)
+	}
+	if query.To, err = parseSearchTime(c.Query("to"), true); err != nil {
+		return query, true, fmt.Errorf("invalid to: %w", err)
+	}
+	if query.Min, err = parseSearchAmount(c.Query("min")); err != nil {
+		return query, true, fmt.Errorf("invalid min: %w", err)
+	}
+	if query.Max, err = parseSearchAmount(c.Query("max")); err != nil {
+		return query, true, fmt.Errorf("invalid max: %w", err)
+	}
+	if query.Limit, err = parseSearchInt(c.Query("limit")); err != nil {
+		return query, true, fmt.Errorf("invalid limit: %w", err)
+	}
+	if query.Offset, err = parseSearchInt(c.Query("offset")); err != nil {
+		return query, true, fmt.Errorf("invalid offset: %w", err)
+	}
+	return query, true, nil
+}
+
+// parseSearchTime accepts RFC3339 or a plain date. A plain date used as the end
+// of a range covers the whole day.
+func parseSearchTime(value string, endOfDay bool) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if parsed, err := time.Parse(time.RFC3339, value); err == nil {
+		return parsed, nil
+	}
+	parsed, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("expected RFC3339 or YYYY-MM-DD")
+	}
+	if endOfDay {
+		parsed = parsed.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	}
+	return parsed, nil
+}
+
+func parseSearchAmount(value string) (*float64, error) {
+	if value == "" {
+		return nil, nil
+	}
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil, fmt.Errorf("expected a number")
+	}
+	return &parsed, nil
+}
+
+func parseSearchInt(value string) (int, error) {
+	if value == "" {
+		return 0, nil
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("expected an integer")
+	}
+	return parsed, nil
+}
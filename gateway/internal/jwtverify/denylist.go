@@ -0,0 +1,118 @@
+package jwtverify
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	authv1 "github.com/Deevins/final-task-course-2-go-lang/gateway/internal/pb/auth/v1"
+)
+
+// syncOverlap re-reads entries revoked shortly before the previous sync, so
+// revocations committed late are not missed.
+const syncOverlap = 30 * time.Second
+
+// RevocationSource lists revoked access tokens. It is implemented by the auth gateway service.
+type RevocationSource interface {
+	ListRevokedTokens(ctx context.Context, since time.Time) (*authv1.ListRevokedTokensResponse, error)
+}
+
+// Denylist is a local copy of the auth service denylist and user cutoffs,
+// kept up to date by incremental syncs.
+type Denylist struct {
+	source       RevocationSource
+	maxStaleness time.Duration
+
+	mu       sync.RWMutex
+	entries  map[string]time.Time
+	users    map[string]userCutoff
+	since    time.Time
+	syncedAt time.Time
+}
+
+// userCutoff rejects the tokens of a user issued before revokedBefore.
+type userCutoff struct {
+	revokedBefore time.Time
+	expiresAt     time.Time
+}
+
+// NewDenylist creates a denylist that is considered fresh for maxStaleness after a successful sync.
+func NewDenylist(source RevocationSource, maxStaleness time.Duration) *Denylist {
+	return &Denylist{
+		source:       source,
+		maxStaleness: maxStaleness,
+		entries:      make(map[string]time.Time),
+		users:        make(map[string]userCutoff),
+	}
+}
+
+// Sync fetches entries revoked since the previous sync and drops expired ones.
+func (d *Denylist) Sync(ctx context.Context) error {
+	d.mu.RLock()
+	since := d.since
+	d.mu.RUnlock()
+
+	resp, err := d.source.ListRevokedTokens(ctx, since)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, token := range resp.GetTokens() {
+		d.entries[token.GetJti()] = token.GetExpiresAt().AsTime()
+	}
+	for _, user := range resp.GetUsers() {
+		d.users[user.GetUserId()] = userCutoff{
+			revokedBefore: user.GetRevokedBefore().AsTime(),
+			expiresAt:     user.GetExpiresAt().AsTime(),
+		}
+	}
+	for jti, expiresAt := range d.entries {
+		if expiresAt.Before(now) {
+			delete(d.entries, jti)
+		}
+	}
+	for userID, cutoff := range d.users {
+		if cutoff.expiresAt.Before(now) {
+			delete(d.users, userID)
+		}
+	}
+	if resp.GetAsOf() != nil {
+		d.since = resp.GetAsOf().AsTime().Add(-syncOverlap)
+	}
+	d.syncedAt = now
+	return nil
+}
+
+// Run syncs the denylist every interval until the context is canceled.
+func (d *Denylist) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := d.Sync(ctx); err != nil {
+				log.Printf("denylist sync error: %v", err)
+			}
+		}
+	}
+}
+
+// Check reports whether the token is revoked, by its jti or by a cutoff of its
+// user, and whether the local copy is fresh enough to trust a negative answer.
+func (d *Denylist) Check(jti, userID string, issuedAt time.Time) (revoked bool, fresh bool) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	_, revoked = d.entries[jti]
+	if cutoff, ok := d.users[userID]; ok && cutoff.revokedBefore.After(issuedAt) {
+		revoked = true
+	}
+	fresh = !d.syncedAt.IsZero() && time.Since(d.syncedAt) <= d.maxStaleness
+	return revoked, fresh
+}
@@ -0,0 +1,94 @@
+package jwtverify
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	authv1 "github.com/Deevins/final-task-course-2-go-lang/gateway/internal/pb/auth/v1"
+)
+
+func TestDenylistSyncOverlapsPreviousSync(t *testing.T) {
+	ctx := context.Background()
+	asOf := time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+	expiresAt := time.Now().Add(time.Hour)
+	source := &fakeRevocations{responses: []*authv1.ListRevokedTokensResponse{
+		{
+			Tokens: []*authv1.RevokedToken{
+				{Jti: "jti-1", ExpiresAt: timestamppb.New(expiresAt)},
+				{Jti: "jti-expired", ExpiresAt: timestamppb.New(time.Now().Add(-time.Minute))},
+			},
+			AsOf: timestamppb.New(asOf),
+		},
+		{
+			// Revoked before asOf but committed after the first listing.
+			Tokens: []*authv1.RevokedToken{{Jti: "jti-late", ExpiresAt: timestamppb.New(expiresAt)}},
+			AsOf:   timestamppb.New(asOf.Add(time.Minute)),
+		},
+	}}
+	denylist := NewDenylist(source, time.Minute)
+
+	if revoked, fresh := denylist.Check("jti-1", "user-1", time.Now()); revoked || fresh {
+		t.Fatalf("expected an unsynced denylist to be stale, got revoked=%v fresh=%v", revoked, fresh)
+	}
+	if err := denylist.Sync(ctx); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	if err := denylist.Sync(ctx); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+
+	if len(source.since) != 2 || !source.since[0].IsZero() {
+		t.Fatalf("expected the first sync to list everything, got %v", source.since)
+	}
+	if want := asOf.Add(-syncOverlap); !source.since[1].Equal(want) {
+		t.Fatalf("expected the second sync to start at %v, got %v", want, source.since[1])
+	}
+	for _, jti := range []string{"jti-1", "jti-late"} {
+		if revoked, fresh := denylist.Check(jti, "user-1", time.Now()); !revoked || !fresh {
+			t.Fatalf("%s: expected a fresh revocation, got revoked=%v fresh=%v", jti, revoked, fresh)
+		}
+	}
+	if revoked, _ := denylist.Check("jti-expired", "user-1", time.Now()); revoked {
+		t.Fatal("expected expired entries to be dropped")
+	}
+}
+
+func TestDenylistRejectsTokensIssuedBeforeUserCutoff(t *testing.T) {
+	ctx := context.Background()
+	cutoff := time.Now().Truncate(time.Second)
+	source := &fakeRevocations{responses: []*authv1.ListRevokedTokensResponse{
+		{
+			Users: []*authv1.RevokedUser{
+				{UserId: "user-1", RevokedBefore: timestamppb.New(cutoff), ExpiresAt: timestamppb.New(cutoff.Add(time.Hour))},
+				{UserId: "user-expired", RevokedBefore: timestamppb.New(cutoff), ExpiresAt: timestamppb.New(time.Now().Add(-time.Minute))},
+			},
+			AsOf: timestamppb.Now(),
+		},
+	}}
+	denylist := NewDenylist(source, time.Minute)
+	if err := denylist.Sync(ctx); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		userID   string
+		issuedAt time.Time
+		revoked  bool
+	}{
+		{name: "issued before the cutoff", userID: "user-1", issuedAt: cutoff.Add(-time.Second), revoked: true},
+		{name: "issued at the cutoff", userID: "user-1", issuedAt: cutoff},
+		{name: "other user", userID: "user-2", issuedAt: cutoff.Add(-time.Second)},
+		{name: "expired cutoff", userID: "user-expired", issuedAt: cutoff.Add(-time.Second)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if revoked, fresh := denylist.Check("jti-1", tt.userID, tt.issuedAt); revoked != tt.revoked || !fresh {
+				t.Fatalf("expected revoked=%v, got revoked=%v fresh=%v", tt.revoked, revoked, fresh)
+			}
+		})
+	}
+}
@@ -0,0 +1,203 @@
+package jwtverify
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// refreshCooldown limits how often an unknown kid can trigger a JWKS fetch.
+const refreshCooldown = 10 * time.Second
+
+var (
+	// ErrKeysUnavailable means the key set could not be fetched, so the token
+	// cannot be verified locally.
+	ErrKeysUnavailable = errors.New("signing keys are unavailable")
+	ErrUnknownKey      = errors.New("unknown signing key")
+)
+
+type jwk struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Algorithm string `json:"alg"`
+	N         string `json:"n"`
+	E         string `json:"e"`
+	Curve     string `json:"crv"`
+	X         string `json:"x"`
+}
+
+type publicKey struct {
+	algorithm string
+	key       interface{}
+}
+
+// KeySet caches the public keys published by the auth service at its JWKS URL.
+type KeySet struct {
+	url    string
+	client *http.Client
+
+	mu          sync.RWMutex
+	keys        map[string]publicKey
+	loaded      bool
+	attemptedAt time.Time
+}
+
+func NewKeySet(url string, client *http.Client) *KeySet {
+	if client == nil {
+		client = &http.Client{Timeout: 5 * time.Second}
+	}
+	return &KeySet{url: url, client: client, keys: make(map[string]publicKey)}
+}
+
+// Refresh fetches the key set and replaces the cached keys.
+func (s *KeySet) Refresh(ctx context.Context) error {
+	s.mu.Lock()
+	s.attemptedAt = time.Now()
+	s.mu.Unlock()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("fetch jwks: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("fetch jwks: unexpected status %s", resp.Status)
+	}
+
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return fmt.Errorf("decode jwks: %w", err)
+	}
+
+	keys := make(map[string]publicKey, len(set.Keys))
+	for _, item := range set.Keys {
+		parsed, err := parseJWK(item)
+		if err != nil {
+			log.Printf("skip jwk %q: %v", item.KeyID, err)
+			continue
+		}
+		keys[item.KeyID] = parsed
+	}
+
+	s.mu.Lock()
+	s.keys = keys
+	s.loaded = true
+	s.mu.Unlock()
+	return nil
+}
+
+// Run refreshes the key set every interval until the context is canceled.
+func (s *KeySet) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.Refresh(ctx); err != nil {
+				log.Printf("jwks refresh error: %v", err)
+			}
+		}
+	}
+}
+
+// Keyfunc resolves the verification key by kid. An unknown kid triggers a
+// refresh, at most once per refreshCooldown, since the auth service may have
+// rotated its keys.
+func (s *KeySet) Keyfunc(ctx context.Context) jwt.Keyfunc {
+	return func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		if kid == "" {
+			return nil, errors.New("token has no kid")
+		}
+
+		found, ok, loaded := s.lookup(kid)
+		if !ok && s.canRefresh() {
+			if err := s.Refresh(ctx); err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrKeysUnavailable, err)
+			}
+			found, ok, loaded = s.lookup(kid)
+		}
+		if !loaded {
+			return nil, ErrKeysUnavailable
+		}
+		if !ok {
+			return nil, fmt.Errorf("%w %q", ErrUnknownKey, kid)
+		}
+		if token.Method.Alg() != found.algorithm {
+			return nil, fmt.Errorf("unexpected signing method: %s", token.Method.Alg())
+		}
+		return found.key, nil
+	}
+}
+
+func (s *KeySet) lookup(kid string) (publicKey, bool, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	key, ok := s.keys[kid]
+	return key, ok, s.loaded
+}
+
+func (s *KeySet) canRefresh() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return time.Since(s.attemptedAt) >= refreshCooldown
+}
+
+func parseJWK(item jwk) (publicKey, error) {
+	switch item.KeyType {
+	case "RSA":
+		if item.Algorithm != jwt.SigningMethodRS256.Alg() {
+			return publicKey{}, fmt.Errorf("unsupported RSA algorithm %q", item.Algorithm)
+		}
+		n, err := base64.RawURLEncoding.DecodeString(item.N)
+		if err != nil {
+			return publicKey{}, fmt.Errorf("decode n: %w", err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(item.E)
+		if err != nil {
+			return publicKey{}, fmt.Errorf("decode e: %w", err)
+		}
+		exponent := new(big.Int).SetBytes(e)
+		if !exponent.IsInt64() || exponent.Int64() > 1<<31-1 {
+			return publicKey{}, errors.New("invalid exponent")
+		}
+		return publicKey{
+			algorithm: item.Algorithm,
+			key:       &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exponent.Int64())},
+		}, nil
+	case "OKP":
+		if item.Curve != "Ed25519" || item.Algorithm != jwt.SigningMethodEdDSA.Alg() {
+			return publicKey{}, fmt.Errorf("unsupported OKP key %q/%q", item.Curve, item.Algorithm)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(item.X)
+		if err != nil {
+			return publicKey{}, fmt.Errorf("decode x: %w", err)
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return publicKey{}, errors.New("invalid Ed25519 key size")
+		}
+		return publicKey{algorithm: item.Algorithm, key: ed25519.PublicKey(x)}, nil
+	default:
+		return publicKey{}, fmt.Errorf("unsupported key type %q", item.KeyType)
+	}
+}
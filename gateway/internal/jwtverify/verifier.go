@@ -0,0 +1,105 @@
+package jwtverify
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	authv1 "github.com/Deevins/final-task-course-2-go-lang/gateway/internal/pb/auth/v1"
+)
+
+var ErrInvalidToken = errors.New("token is invalid")
+
+// Identity is the authenticated user of a valid access token.
+type Identity struct {
+	UserID        string
+	EmailVerified bool
+	Roles         []string
+}
+
+// accessClaims mirrors the claims the auth service puts into access tokens.
+type accessClaims struct {
+	jwt.RegisteredClaims
+	EmailVerified bool     `json:"email_verified"`
+	Roles         []string `json:"roles,omitempty"`
+}
+
+// RemoteValidator validates a token through the auth service.
+type RemoteValidator interface {
+	ValidateToken(ctx context.Context, accessToken string) (*authv1.ValidateTokenResponse, error)
+}
+
+// Verifier checks access tokens locally against the cached JWKS and denylist.
+// It falls back to the auth service when the keys cannot be fetched or the
+// denylist is stale.
+type Verifier struct {
+	keys     *KeySet
+	denylist *Denylist
+	remote   RemoteValidator
+}
+
+func NewVerifier(keys *KeySet, denylist *Denylist, remote RemoteValidator) *Verifier {
+	if keys == nil || denylist == nil || remote == nil {
+		panic("verifier requires key set, denylist and remote validator")
+	}
+	return &Verifier{keys: keys, denylist: denylist, remote: remote}
+}
+
+// NewRemoteVerifier returns a verifier that always asks the auth service.
+func NewRemoteVerifier(remote RemoteValidator) *Verifier {
+	if remote == nil {
+		panic("verifier requires remote validator")
+	}
+	return &Verifier{remote: remote}
+}
+
+// Verify returns the identity of a valid token or ErrInvalidToken.
+func (v *Verifier) Verify(ctx context.Context, accessToken string) (Identity, error) {
+	if v.keys == nil {
+		return v.verifyRemote(ctx, accessToken)
+	}
+
+	claims := &accessClaims{}
+	_, err := jwt.ParseWithClaims(
+		accessToken,
+		claims,
+		v.keys.Keyfunc(ctx),
+		jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodEdDSA.Alg()}),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		if errors.Is(err, ErrKeysUnavailable) {
+			return v.verifyRemote(ctx, accessToken)
+		}
+		return Identity{}, ErrInvalidToken
+	}
+	if claims.ID == "" || claims.Subject == "" {
+		return Identity{}, ErrInvalidToken
+	}
+
+	var issuedAt time.Time
+	if claims.IssuedAt != nil {
+		issuedAt = claims.IssuedAt.Time
+	}
+	revoked, fresh := v.denylist.Check(claims.ID, claims.Subject, issuedAt)
+	if revoked {
+		return Identity{}, ErrInvalidToken
+	}
+	if !fresh {
+		return v.verifyRemote(ctx, accessToken)
+	}
+	return Identity{UserID: claims.Subject, EmailVerified: claims.EmailVerified, Roles: claims.Roles}, nil
+}
+
+func (v *Verifier) verifyRemote(ctx context.Context, accessToken string) (Identity, error) {
+	resp, err := v.remote.ValidateToken(ctx, accessToken)
+	if err != nil {
+		return Identity{}, err
+	}
+	if !resp.GetValid() {
+		return Identity{}, ErrInvalidToken
+	}
+	return Identity{UserID: resp.GetUserId(), EmailVerified: resp.GetEmailVerified(), Roles: resp.GetRoles()}, nil
+}
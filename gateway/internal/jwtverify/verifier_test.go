@@ -0,0 +1,282 @@
+package jwtverify
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	authv1 "github.com/Deevins/final-task-course-2-go-lang/gateway/internal/pb/auth/v1"
+)
+
+// jwksServer publishes Ed25519 keys and counts how often they are fetched.
+type jwksServer struct {
+	*httptest.Server
+
+	mu      sync.Mutex
+	keys    map[string]ed25519.PublicKey
+	fetches int
+}
+
+func newJWKSServer(t *testing.T) *jwksServer {
+	t.Helper()
+	s := &jwksServer{keys: make(map[string]ed25519.PublicKey)}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.fetches++
+		items := make([]map[string]string, 0, len(s.keys))
+		for kid, key := range s.keys {
+			items = append(items, map[string]string{
+				"kty": "OKP",
+				"kid": kid,
+				"alg": "EdDSA",
+				"crv": "Ed25519",
+				"x":   base64.RawURLEncoding.EncodeToString(key),
+			})
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"keys": items})
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// addKey publishes a new key under kid and returns its private part.
+func (s *jwksServer) addKey(t *testing.T, kid string) ed25519.PrivateKey {
+	t.Helper()
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	s.mu.Lock()
+	s.keys[kid] = publicKey
+	s.mu.Unlock()
+	return privateKey
+}
+
+func (s *jwksServer) fetchCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.fetches
+}
+
+// fakeRemote stands in for the auth service and accepts every token.
+type fakeRemote struct {
+	calls int
+}
+
+func (r *fakeRemote) ValidateToken(ctx context.Context, accessToken string) (*authv1.ValidateTokenResponse, error) {
+	r.calls++
+	return &authv1.ValidateTokenResponse{Valid: true, UserId: "remote-user"}, nil
+}
+
+// fakeRevocations returns the configured responses in turn and records the
+// since argument of each call.
+type fakeRevocations struct {
+	responses []*authv1.ListRevokedTokensResponse
+	since     []time.Time
+}
+
+func (f *fakeRevocations) ListRevokedTokens(ctx context.Context, since time.Time) (*authv1.ListRevokedTokensResponse, error) {
+	f.since = append(f.since, since)
+	if len(f.responses) == 0 {
+		return &authv1.ListRevokedTokensResponse{AsOf: timestamppb.Now()}, nil
+	}
+	resp := f.responses[0]
+	f.responses = f.responses[1:]
+	return resp, nil
+}
+
+func signToken(t *testing.T, method jwt.SigningMethod, key interface{}, kid, jti string, expiresAt time.Time) string {
+	t.Helper()
+	token := jwt.NewWithClaims(method, accessClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        jti,
+			Subject:   "user-1",
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			// The tests sign tokens that are valid for an hour.
+			IssuedAt: jwt.NewNumericDate(expiresAt.Add(-time.Hour)),
+		},
+		Roles: []string{"user"},
+	})
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+	return signed
+}
+
+func TestVerifier(t *testing.T) {
+	ctx := context.Background()
+	hour := time.Now().Add(time.Hour)
+
+	setup := func(t *testing.T, revoked ...string) (*jwksServer, *KeySet, *Verifier, *fakeRemote) {
+		t.Helper()
+		server := newJWKSServer(t)
+		keys := NewKeySet(server.URL, server.Client())
+		tokens := make([]*authv1.RevokedToken, 0, len(revoked))
+		for _, jti := range revoked {
+			tokens = append(tokens, &authv1.RevokedToken{Jti: jti, ExpiresAt: timestamppb.New(hour)})
+		}
+		denylist := NewDenylist(&fakeRevocations{responses: []*authv1.ListRevokedTokensResponse{
+			{Tokens: tokens, AsOf: timestamppb.Now()},
+		}}, time.Minute)
+		if err := denylist.Sync(ctx); err != nil {
+			t.Fatalf("sync denylist: %v", err)
+		}
+		remote := &fakeRemote{}
+		return server, keys, NewVerifier(keys, denylist, remote), remote
+	}
+
+	t.Run("valid token is verified locally", func(t *testing.T) {
+		server, _, verifier, remote := setup(t)
+		key := server.addKey(t, "k1")
+
+		identity, err := verifier.Verify(ctx, signToken(t, jwt.SigningMethodEdDSA, key, "k1", "jti-1", hour))
+		if err != nil {
+			t.Fatalf("verify: %v", err)
+		}
+		if identity.UserID != "user-1" || len(identity.Roles) != 1 || identity.Roles[0] != "user" {
+			t.Fatalf("unexpected identity %+v", identity)
+		}
+		if remote.calls != 0 {
+			t.Fatalf("expected no remote calls, got %d", remote.calls)
+		}
+	})
+
+	t.Run("revoked jti is rejected", func(t *testing.T) {
+		server, _, verifier, remote := setup(t, "jti-revoked")
+		key := server.addKey(t, "k1")
+
+		_, err := verifier.Verify(ctx, signToken(t, jwt.SigningMethodEdDSA, key, "k1", "jti-revoked", hour))
+		if !errors.Is(err, ErrInvalidToken) {
+			t.Fatalf("expected invalid token, got %v", err)
+		}
+		if remote.calls != 0 {
+			t.Fatalf("expected no remote calls, got %d", remote.calls)
+		}
+	})
+
+	t.Run("token issued before the user cutoff is rejected", func(t *testing.T) {
+		server := newJWKSServer(t)
+		key := server.addKey(t, "k1")
+		keys := NewKeySet(server.URL, server.Client())
+		cutoff := time.Now().Add(-30 * time.Second)
+		denylist := NewDenylist(&fakeRevocations{responses: []*authv1.ListRevokedTokensResponse{
+			{
+				Users: []*authv1.RevokedUser{{UserId: "user-1", RevokedBefore: timestamppb.New(cutoff), ExpiresAt: timestamppb.New(hour)}},
+				AsOf:  timestamppb.Now(),
+			},
+		}}, time.Minute)
+		if err := denylist.Sync(ctx); err != nil {
+			t.Fatalf("sync denylist: %v", err)
+		}
+		remote := &fakeRemote{}
+		verifier := NewVerifier(keys, denylist, remote)
+
+		_, err := verifier.Verify(ctx, signToken(t, jwt.SigningMethodEdDSA, key, "k1", "jti-1", hour.Add(-time.Minute)))
+		if !errors.Is(err, ErrInvalidToken) {
+			t.Fatalf("expected invalid token, got %v", err)
+		}
+		if _, err := verifier.Verify(ctx, signToken(t, jwt.SigningMethodEdDSA, key, "k1", "jti-2", hour)); err != nil {
+			t.Fatalf("expected a token issued after the cutoff to be valid, got %v", err)
+		}
+		if remote.calls != 0 {
+			t.Fatalf("expected no remote calls, got %d", remote.calls)
+		}
+	})
+
+	t.Run("expired token is rejected", func(t *testing.T) {
+		server, _, verifier, remote := setup(t)
+		key := server.addKey(t, "k1")
+
+		_, err := verifier.Verify(ctx, signToken(t, jwt.SigningMethodEdDSA, key, "k1", "jti-1", time.Now().Add(-time.Minute)))
+		if !errors.Is(err, ErrInvalidToken) {
+			t.Fatalf("expected invalid token, got %v", err)
+		}
+		if remote.calls != 0 {
+			t.Fatalf("expected no remote calls, got %d", remote.calls)
+		}
+	})
+
+	t.Run("algorithm that does not match the kid is rejected", func(t *testing.T) {
+		server, _, verifier, _ := setup(t)
+		server.addKey(t, "k1")
+		rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			t.Fatalf("generate rsa key: %v", err)
+		}
+
+		_, err = verifier.Verify(ctx, signToken(t, jwt.SigningMethodRS256, rsaKey, "k1", "jti-1", hour))
+		if !errors.Is(err, ErrInvalidToken) {
+			t.Fatalf("expected invalid token, got %v", err)
+		}
+	})
+
+	t.Run("stale denylist falls back to the auth service", func(t *testing.T) {
+		server := newJWKSServer(t)
+		key := server.addKey(t, "k1")
+		keys := NewKeySet(server.URL, server.Client())
+		denylist := NewDenylist(&fakeRevocations{}, time.Minute)
+		if err := denylist.Sync(ctx); err != nil {
+			t.Fatalf("sync denylist: %v", err)
+		}
+		denylist.syncedAt = time.Now().Add(-2 * time.Minute)
+		remote := &fakeRemote{}
+		verifier := NewVerifier(keys, denylist, remote)
+
+		identity, err := verifier.Verify(ctx, signToken(t, jwt.SigningMethodEdDSA, key, "k1", "jti-1", hour))
+		if err != nil {
+			t.Fatalf("verify: %v", err)
+		}
+		if remote.calls != 1 || identity.UserID != "remote-user" {
+			t.Fatalf("expected the auth service to decide, got %d calls and %+v", remote.calls, identity)
+		}
+	})
+
+	t.Run("unknown kid refreshes the keys at most once per cooldown", func(t *testing.T) {
+		server, keys, verifier, _ := setup(t)
+		server.addKey(t, "k1")
+		if err := keys.Refresh(ctx); err != nil {
+			t.Fatalf("refresh: %v", err)
+		}
+		rotated := server.addKey(t, "k2")
+		token := signToken(t, jwt.SigningMethodEdDSA, rotated, "k2", "jti-1", hour)
+
+		// The keys were fetched just now, so the new kid is not looked up yet.
+		if _, err := verifier.Verify(ctx, token); !errors.Is(err, ErrInvalidToken) {
+			t.Fatalf("expected invalid token within the cooldown, got %v", err)
+		}
+		if fetches := server.fetchCount(); fetches != 1 {
+			t.Fatalf("expected 1 fetch within the cooldown, got %d", fetches)
+		}
+
+		keys.attemptedAt = time.Now().Add(-refreshCooldown)
+		if _, err := verifier.Verify(ctx, token); err != nil {
+			t.Fatalf("expected the rotated key to be fetched, got %v", err)
+		}
+		if fetches := server.fetchCount(); fetches != 2 {
+			t.Fatalf("expected 2 fetches, got %d", fetches)
+		}
+
+		unknown := signToken(t, jwt.SigningMethodEdDSA, rotated, "k3", "jti-2", hour)
+		if _, err := verifier.Verify(ctx, unknown); !errors.Is(err, ErrInvalidToken) {
+			t.Fatalf("expected invalid token for an unknown kid, got %v", err)
+		}
+		if fetches := server.fetchCount(); fetches != 2 {
+			t.Fatalf("expected no fetch within the cooldown, got %d", fetches)
+		}
+	})
+}
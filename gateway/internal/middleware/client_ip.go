@@ -0,0 +1,23 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/metadata"
+)
+
+// clientIPMetadataKey passes the address of the HTTP client to backend services,
+// which otherwise only see the gateway. The auth service uses it to limit
+// failed sign-in attempts per address.
+const clientIPMetadataKey = "x-client-ip"
+
+// ForwardClientIP adds the client address to the outgoing gRPC metadata. The
+// address honours forwarding headers only from the engine's trusted proxies.
+func ForwardClientIP() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if ip := c.ClientIP(); ip != "" {
+			ctx := metadata.AppendToOutgoingContext(c.Request.Context(), clientIPMetadataKey, ip)
+			c.Request = c.Request.WithContext(ctx)
+		}
+		c.Next()
+	}
+}
@@ -1,18 +1,36 @@
 package middleware
 
 import (
+	"context"
+	"errors"
 	"net/http"
+	"slices"
 	"strings"
 
-	"github.com/Deevins/final-task-course-2-go-lang/gateway/internal/service"
+	"github.com/Deevins/final-task-course-2-go-lang/gateway/internal/jwtverify"
 	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/metadata"
 )
 
-const userIDContextKey = "user_id"
+const (
+	userIDContextKey        = "user_id"
+	accessTokenContextKey   = "access_token"
+	emailVerifiedContextKey = "email_verified"
+	rolesContextKey         = "roles"
+	// userIDMetadataKey passes the authenticated user to backend services as the acting user.
+	userIDMetadataKey = "x-user-id"
+	// rolesMetadataKey passes the roles of the acting user, one value per role.
+	rolesMetadataKey = "x-user-roles"
+)
+
+// TokenVerifier returns the identity of a valid access token.
+type TokenVerifier interface {
+	Verify(ctx context.Context, accessToken string) (jwtverify.Identity, error)
+}
 
-func JWTAuth(authService service.AuthGatewayService) gin.HandlerFunc {
-	if authService == nil {
-		panic("JWT middleware requires auth service")
+func JWTAuth(verifier TokenVerifier) gin.HandlerFunc {
+	if verifier == nil {
+		panic("JWT middleware requires token verifier")
 	}
 
 	return func(c *gin.Context) {
@@ -34,17 +52,27 @@ func JWTAuth(authService service.AuthGatewayService) gin.HandlerFunc {
 			return
 		}
 
-		resp, err := authService.ValidateToken(c.Request.Context(), token)
+		identity, err := verifier.Verify(c.Request.Context(), token)
 		if err != nil {
+			if errors.Is(err, jwtverify.ErrInvalidToken) {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "token is invalid"})
+				return
+			}
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "token validation failed"})
 			return
 		}
-		if !resp.GetValid() {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "token is invalid"})
-			return
-		}
 
-		c.Set(userIDContextKey, resp.GetUserId())
+		c.Set(userIDContextKey, identity.UserID)
+		c.Set(accessTokenContextKey, token)
+		c.Set(emailVerifiedContextKey, identity.EmailVerified)
+		c.Set(rolesContextKey, identity.Roles)
+		pairs := make([]string, 0, 2+2*len(identity.Roles))
+		pairs = append(pairs, userIDMetadataKey, identity.UserID)
+		for _, role := range identity.Roles {
+			pairs = append(pairs, rolesMetadataKey, role)
+		}
+		ctx := metadata.AppendToOutgoingContext(c.Request.Context(), pairs...)
+		c.Request = c.Request.WithContext(ctx)
 		c.Next()
 	}
 }
@@ -60,3 +88,66 @@ func UserIDFromContext(c *gin.Context) string {
 	userID, _ := value.(string)
 	return userID
 }
+
+// AccessTokenFromContext returns the bearer token validated by JWTAuth.
+func AccessTokenFromContext(c *gin.Context) string {
+	if c == nil {
+		return ""
+	}
+	return c.GetString(accessTokenContextKey)
+}
+
+// EmailVerifiedFromContext reports whether the token validated by JWTAuth
+// belongs to a user with a confirmed email address.
+func EmailVerifiedFromContext(c *gin.Context) bool {
+	if c == nil {
+		return false
+	}
+	return c.GetBool(emailVerifiedContextKey)
+}
+
+// RequireVerifiedEmail restricts users with an unconfirmed email address to
+// read-only requests. It must run after JWTAuth.
+func RequireVerifiedEmail() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if EmailVerifiedFromContext(c) {
+			c.Next()
+			return
+		}
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			c.Next()
+		default:
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "email is not verified"})
+		}
+	}
+}
+
+// RolesFromContext returns the roles of the user authenticated by JWTAuth.
+func RolesFromContext(c *gin.Context) []string {
+	if c == nil {
+		return nil
+	}
+	value, ok := c.Get(rolesContextKey)
+	if !ok {
+		return nil
+	}
+	roles, _ := value.([]string)
+	return roles
+}
+
+// RequireRole lets through users with any of the roles. It must run after
+// JWTAuth. The roles come from the access token, so the backend services
+// still check them on their side.
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userRoles := RolesFromContext(c)
+		for _, role := range roles {
+			if slices.Contains(userRoles, role) {
+				c.Next()
+				return
+			}
+		}
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "insufficient role"})
+	}
+}
@@ -0,0 +1,148 @@
+package middleware
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/Deevins/final-task-course-2-go-lang/gateway/internal/jwtverify"
+	authv1 "github.com/Deevins/final-task-course-2-go-lang/gateway/internal/pb/auth/v1"
+	"github.com/Deevins/final-task-course-2-go-lang/gateway/internal/service"
+)
+
+const benchKeyID = "bench-key"
+
+// BenchmarkJWTAuth compares validating every request through the auth gRPC
+// service (over loopback TCP) with local verification against the cached JWKS
+// and denylist.
+func BenchmarkJWTAuth(b *testing.B) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		b.Fatalf("generate key: %v", err)
+	}
+	token := signBenchToken(b, privateKey)
+	authService := startBenchAuthServer(b, publicKey)
+
+	b.Run("remote", func(b *testing.B) {
+		runJWTAuthBenchmark(b, jwtverify.NewRemoteVerifier(authService), token)
+	})
+
+	b.Run("local", func(b *testing.B) {
+		jwks := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_ = json.NewEncoder(w).Encode(map[string]any{"keys": []map[string]string{{
+				"kty": "OKP",
+				"kid": benchKeyID,
+				"use": "sig",
+				"alg": "EdDSA",
+				"crv": "Ed25519",
+				"x":   base64.RawURLEncoding.EncodeToString(publicKey),
+			}}})
+		}))
+		b.Cleanup(jwks.Close)
+
+		ctx := context.Background()
+		keys := jwtverify.NewKeySet(jwks.URL, jwks.Client())
+		if err := keys.Refresh(ctx); err != nil {
+			b.Fatalf("refresh jwks: %v", err)
+		}
+		denylist := jwtverify.NewDenylist(authService, time.Hour)
+		if err := denylist.Sync(ctx); err != nil {
+			b.Fatalf("sync denylist: %v", err)
+		}
+
+		runJWTAuthBenchmark(b, jwtverify.NewVerifier(keys, denylist, authService), token)
+	})
+}
+
+func runJWTAuthBenchmark(b *testing.B, verifier TokenVerifier, token string) {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.GET("/ping", JWTAuth(verifier), func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		rec := httptest.NewRecorder()
+		engine.ServeHTTP(rec, req)
+		if rec.Code != http.StatusNoContent {
+			b.Fatalf("expected 204, got %d: %s", rec.Code, rec.Body.String())
+		}
+	}
+}
+
+func signBenchToken(b *testing.B, privateKey ed25519.PrivateKey) string {
+	b.Helper()
+	jwtToken := jwt.NewWithClaims(jwt.SigningMethodEdDSA, jwt.RegisteredClaims{
+		ID:        "bench-jti",
+		Subject:   "bench-user",
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+	})
+	jwtToken.Header["kid"] = benchKeyID
+	signed, err := jwtToken.SignedString(privateKey)
+	if err != nil {
+		b.Fatalf("sign token: %v", err)
+	}
+	return signed
+}
+
+// benchAuthServer verifies tokens the way the auth service does, without the denylist lookup.
+type benchAuthServer struct {
+	authv1.UnimplementedAuthServiceServer
+	publicKey ed25519.PublicKey
+}
+
+func (s *benchAuthServer) ValidateToken(ctx context.Context, req *authv1.ValidateTokenRequest) (*authv1.ValidateTokenResponse, error) {
+	claims := &jwt.RegisteredClaims{}
+	_, err := jwt.ParseWithClaims(req.GetAccessToken(), claims, func(*jwt.Token) (interface{}, error) {
+		return s.publicKey, nil
+	})
+	if err != nil {
+		return &authv1.ValidateTokenResponse{Valid: false}, nil
+	}
+	return &authv1.ValidateTokenResponse{
+		Valid:     true,
+		UserId:    claims.Subject,
+		ExpiresAt: timestamppb.New(claims.ExpiresAt.Time),
+	}, nil
+}
+
+func (s *benchAuthServer) ListRevokedTokens(ctx context.Context, req *authv1.ListRevokedTokensRequest) (*authv1.ListRevokedTokensResponse, error) {
+	return &authv1.ListRevokedTokensResponse{AsOf: timestamppb.Now()}, nil
+}
+
+func startBenchAuthServer(b *testing.B, publicKey ed25519.PublicKey) service.AuthGatewayService {
+	b.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		b.Fatalf("listen: %v", err)
+	}
+	server := grpc.NewServer()
+	authv1.RegisterAuthServiceServer(server, &benchAuthServer{publicKey: publicKey})
+	go func() { _ = server.Serve(lis) }()
+	b.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		b.Fatalf("dial: %v", err)
+	}
+	b.Cleanup(func() { _ = conn.Close() })
+	return service.NewAuthGatewayService(authv1.NewAuthServiceClient(conn))
+}
@@ -5,7 +5,7 @@ import "time"
 // SignUpRequest описывает запрос на регистрацию пользователя.
 type SignUpRequest struct {
 	Email    string `json:"email" binding:"required,email" example:"user@example.com"`
-	Password string `json:"password" binding:"required" example:"secret"`
+	Password string `json:"password" binding:"required" example:"tulip-garden-42"`
 	Name     string `json:"name" binding:"required" example:"Иван Иванов"`
 }
 
@@ -20,8 +20,126 @@ type SignInRequest struct {
 	Password string `json:"password" binding:"required" example:"secret"`
 }
 
-// SignInResponse описывает ответ после входа.
+// SignInResponse описывает ответ после входа и обновления токенов. Если у пользователя
+// включена двухфакторная аутентификация, вход возвращает только challenge_token,
+// который вместе с кодом передается в /api/auth/signin/2fa.
 type SignInResponse struct {
-	AccessToken string    `json:"access_token" example:"eyJhbGciOi..."`
-	ExpiresAt   time.Time `json:"expires_at" example:"2024-01-01T10:00:00Z"`
+	AccessToken        string    `json:"access_token,omitempty" example:"eyJhbGciOi..."`
+	ExpiresAt          time.Time `json:"expires_at,omitzero" example:"2024-01-01T10:00:00Z"`
+	RefreshToken       string    `json:"refresh_token,omitempty" example:"3q2-7wEjkF..."`
+	RefreshExpiresAt   time.Time `json:"refresh_expires_at,omitzero" example:"2024-01-31T10:00:00Z"`
+	TwoFactorRequired  bool      `json:"two_factor_required,omitempty" example:"false"`
+	ChallengeToken     string    `json:"challenge_token,omitempty" example:"kP0v7Zq..."`
+	ChallengeExpiresAt time.Time `json:"challenge_expires_at,omitzero" example:"2024-01-01T09:05:00Z"`
+}
+
+// VerifyTwoFactorRequest описывает второй шаг входа: challenge из ответа на вход
+// и 6-значный код из приложения-аутентификатора или резервный код.
+type VerifyTwoFactorRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required" example:"kP0v7Zq..."`
+	Code           string `json:"code" binding:"required" example:"123456"`
+}
+
+// EnrollTOTPResponse описывает начатое подключение приложения-аутентификатора.
+type EnrollTOTPResponse struct {
+	Secret string `json:"secret" example:"JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP"`
+	// ProvisioningURI показывается пользователю в виде QR-кода.
+	ProvisioningURI string `json:"provisioning_uri" example:"otpauth://totp/Ledger:user@example.com?secret=JBSWY3DPEHPK3PXP&issuer=Ledger"`
+}
+
+// ConfirmTOTPRequest описывает запрос на включение двухфакторной аутентификации.
+type ConfirmTOTPRequest struct {
+	Code string `json:"code" binding:"required" example:"123456"`
+}
+
+// ConfirmTOTPResponse содержит резервные коды. Они показываются только один раз.
+type ConfirmTOTPResponse struct {
+	RecoveryCodes []string `json:"recovery_codes" example:"k3v9q-7mx2p,a8d4r-zt6wn"`
+}
+
+// DisableTOTPRequest описывает запрос на отключение двухфакторной аутентификации.
+// Code — текущий код из приложения или неиспользованный резервный код.
+type DisableTOTPRequest struct {
+	Password string `json:"password" binding:"required" example:"secret"`
+	Code     string `json:"code" binding:"required" example:"123456"`
+}
+
+// RefreshRequest описывает запрос на обновление токенов.
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required" example:"3q2-7wEjkF..."`
+}
+
+// LogoutRequest описывает запрос на выход. Если refresh_token передан, завершается и его сессия.
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token" example:"3q2-7wEjkF..."`
+}
+
+// RevokeAllSessionsResponse описывает результат завершения всех сессий.
+type RevokeAllSessionsResponse struct {
+	RevokedSessions int64 `json:"revoked_sessions" example:"3"`
+}
+
+// UserProfile описывает профиль текущего пользователя.
+type UserProfile struct {
+	ID        string    `json:"id" example:"11111111-1111-1111-1111-111111111111"`
+	Email     string    `json:"email" example:"user@example.com"`
+	Name      string    `json:"name" example:"Иван Иванов"`
+	CreatedAt time.Time `json:"created_at" example:"2024-01-01T10:00:00Z"`
+	UpdatedAt time.Time `json:"updated_at" example:"2024-01-02T10:00:00Z"`
+	// EmailVerified показывает, подтвержден ли email. Без подтверждения доступ к Ledger только на чтение.
+	EmailVerified bool `json:"email_verified" example:"true"`
+	// TwoFactorEnabled показывает, требуется ли при входе код из приложения-аутентификатора.
+	TwoFactorEnabled bool `json:"two_factor_enabled" example:"false"`
+	// Roles перечисляет роли пользователя: user, support, admin.
+	Roles []string `json:"roles" example:"user"`
+}
+
+// UpdateProfileRequest описывает запрос на изменение профиля.
+type UpdateProfileRequest struct {
+	Name string `json:"name" binding:"required" example:"Иван Петров"`
+}
+
+// ChangePasswordRequest описывает запрос на смену пароля.
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required" example:"secret"`
+	NewPassword     string `json:"new_password" binding:"required" example:"maple-river-17"`
+}
+
+// DeleteAccountRequest описывает запрос на удаление аккаунта. Пароль подтверждает удаление.
+type DeleteAccountRequest struct {
+	Password string `json:"password" binding:"required" example:"secret"`
+}
+
+// ConfirmEmailRequest описывает запрос на подтверждение email по токену из письма.
+type ConfirmEmailRequest struct {
+	Token string `json:"token" binding:"required" example:"Xb3k9TQ..."`
+}
+
+// PasswordResetRequest описывает запрос на отправку письма для сброса пароля.
+type PasswordResetRequest struct {
+	Email string `json:"email" binding:"required,email" example:"user@example.com"`
+}
+
+// ResetPasswordRequest описывает запрос на установку нового пароля по токену из письма.
+type ResetPasswordRequest struct {
+	Token       string `json:"token" binding:"required" example:"Xb3k9TQ..."`
+	NewPassword string `json:"new_password" binding:"required" example:"maple-river-17"`
+}
+
+// IdentityProvidersResponse перечисляет внешних провайдеров входа.
+type IdentityProvidersResponse struct {
+	Providers []string `json:"providers" example:"google,mock"`
+}
+
+// OIDCAuthorization описывает начатый вход через внешнего провайдера.
+type OIDCAuthorization struct {
+	URL       string
+	State     string
+	ExpiresAt time.Time
+}
+
+// SetUserRolesRequest описывает запрос на назначение ролей пользователю.
+// Роль user назначается всегда.
+type SetUserRolesRequest struct {
+	Roles []string `json:"roles" binding:"required" example:"support"`
 }
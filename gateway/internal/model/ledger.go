@@ -1,51 +1,145 @@
 package model
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 // Transaction описывает транзакцию в Ledger.
 type Transaction struct {
-	ID          string    `json:"id" example:"11111111-1111-1111-1111-111111111111"`
-	AccountID   string    `json:"account_id" example:"22222222-2222-2222-2222-222222222222"`
-	Amount      float64   `json:"amount" example:"1250.50"`
-	Currency    string    `json:"currency" example:"RUB"`
-	Category    string    `json:"category" example:"Продукты"`
-	Description string    `json:"description" example:"Покупка в магазине"`
-	OccurredAt  time.Time `json:"occurred_at" example:"2024-01-01T10:00:00Z"`
-	CreatedAt   time.Time `json:"created_at" example:"2024-01-01T10:00:00Z"`
-	UpdatedAt   time.Time `json:"updated_at" example:"2024-01-01T10:00:00Z"`
+	ID          string             `json:"id" example:"11111111-1111-1111-1111-111111111111"`
+	AccountID   string             `json:"account_id" example:"22222222-2222-2222-2222-222222222222"`
+	Amount      float64            `json:"amount" example:"1250.50"`
+	Currency    string             `json:"currency" example:"RUB"`
+	Category    string             `json:"category" example:"Продукты"`
+	Description string             `json:"description" example:"Покупка в магазине"`
+	OccurredAt  time.Time          `json:"occurred_at" example:"2024-01-01T10:00:00Z"`
+	CreatedAt   time.Time          `json:"created_at" example:"2024-01-01T10:00:00Z"`
+	UpdatedAt   time.Time          `json:"updated_at" example:"2024-01-01T10:00:00Z"`
+	DeletedAt   *time.Time         `json:"deleted_at,omitempty" example:"2024-01-02T10:00:00Z"`
+	CreatedBy   string             `json:"created_by,omitempty" example:"33333333-3333-3333-3333-333333333333"`
+	WalletID    string             `json:"wallet_id,omitempty" example:"66666666-6666-6666-6666-666666666666"`
+	TransferID  string             `json:"transfer_id,omitempty" example:"77777777-7777-7777-7777-777777777777"`
+	RefundOf    string             `json:"refund_of,omitempty" example:"99999999-9999-9999-9999-999999999999"`
+	Splits      []TransactionSplit `json:"splits,omitempty"`
+	Tags        []string           `json:"tags,omitempty" example:"отпуск,италия"`
+	PayeeID     string             `json:"payee_id,omitempty" example:"dddddddd-dddd-dddd-dddd-dddddddddddd"`
+}
+
+// TransactionSplit описывает часть разделенной транзакции.
+// Части имеют знак транзакции и в сумме дают ее сумму.
+type TransactionSplit struct {
+	Category string  `json:"category" example:"Продукты"`
+	Amount   float64 `json:"amount" example:"-800"`
 }
 
 // CreateTransactionRequest описывает запрос на создание транзакции.
 type CreateTransactionRequest struct {
-	AccountID   string    `json:"account_id" example:"22222222-2222-2222-2222-222222222222"`
-	Amount      float64   `json:"amount" binding:"required" example:"1250.50"`
-	Currency    string    `json:"currency" binding:"required" example:"RUB"`
-	Category    string    `json:"category" binding:"required" example:"Продукты"`
-	Description string    `json:"description" example:"Покупка в магазине"`
-	OccurredAt  time.Time `json:"occurred_at" binding:"required" example:"2024-01-01T10:00:00Z"`
+	AccountID   string             `json:"account_id" example:"22222222-2222-2222-2222-222222222222"`
+	Amount      float64            `json:"amount" binding:"required" example:"1250.50"`
+	Currency    string             `json:"currency" binding:"required" example:"RUB"`
+	Category    string             `json:"category" example:"Продукты"`
+	Description string             `json:"description" example:"Покупка в магазине"`
+	OccurredAt  time.Time          `json:"occurred_at" binding:"required" example:"2024-01-01T10:00:00Z"`
+	WalletID    string             `json:"wallet_id" example:"66666666-6666-6666-6666-666666666666"`
+	Splits      []TransactionSplit `json:"splits"`
+	Tags        []string           `json:"tags" example:"отпуск,италия"`
+	PayeeID     string             `json:"payee_id" example:"dddddddd-dddd-dddd-dddd-dddddddddddd"`
 }
 
 // UpdateTransactionRequest описывает запрос на обновление транзакции.
 type UpdateTransactionRequest struct {
-	AccountID   string    `json:"account_id" example:"22222222-2222-2222-2222-222222222222"`
-	Amount      float64   `json:"amount" binding:"required" example:"1250.50"`
-	Currency    string    `json:"currency" binding:"required" example:"RUB"`
-	Category    string    `json:"category" binding:"required" example:"Продукты"`
-	Description string    `json:"description" example:"Покупка в магазине"`
-	OccurredAt  time.Time `json:"occurred_at" binding:"required" example:"2024-01-01T10:00:00Z"`
+	AccountID   string             `json:"account_id" example:"22222222-2222-2222-2222-222222222222"`
+	Amount      float64            `json:"amount" binding:"required" example:"1250.50"`
+	Currency    string             `json:"currency" binding:"required" example:"RUB"`
+	Category    string             `json:"category" example:"Продукты"`
+	Description string             `json:"description" example:"Покупка в магазине"`
+	OccurredAt  time.Time          `json:"occurred_at" binding:"required" example:"2024-01-01T10:00:00Z"`
+	WalletID    string             `json:"wallet_id" example:"66666666-6666-6666-6666-666666666666"`
+	Splits      []TransactionSplit `json:"splits"`
+	Tags        []string           `json:"tags" example:"отпуск,италия"`
+	PayeeID     string             `json:"payee_id" example:"dddddddd-dddd-dddd-dddd-dddddddddddd"`
+}
+
+// TransactionSearchQuery описывает параметры поиска транзакций.
+// Min и Max ограничивают сумму по модулю.
+type TransactionSearchQuery struct {
+	From     time.Time
+	To       time.Time
+	Category string
+	Currency string
+	WalletID string
+	PayeeID  string
+	Tag      string
+	Min      *float64
+	Max      *float64
+	Query    string
+	Sort     string
+	Limit    int
+	Offset   int
+}
+
+// BatchCreateTransactionsRequest описывает пакетный запрос на создание транзакций.
+type BatchCreateTransactionsRequest struct {
+	Transactions []CreateTransactionRequest `json:"transactions" binding:"required"`
+	Atomic       bool                       `json:"atomic" example:"false"`
+}
+
+// BatchUpdateTransactionItem описывает транзакцию в пакетном запросе на обновление.
+type BatchUpdateTransactionItem struct {
+	ID          string             `json:"id" example:"11111111-1111-1111-1111-111111111111"`
+	Amount      float64            `json:"amount" example:"1250.50"`
+	Currency    string             `json:"currency" example:"RUB"`
+	Category    string             `json:"category" example:"Продукты"`
+	Description string             `json:"description" example:"Покупка в магазине"`
+	OccurredAt  time.Time          `json:"occurred_at" example:"2024-01-01T10:00:00Z"`
+	WalletID    string             `json:"wallet_id" example:"66666666-6666-6666-6666-666666666666"`
+	Splits      []TransactionSplit `json:"splits"`
+	Tags        []string           `json:"tags" example:"отпуск,италия"`
+	PayeeID     string             `json:"payee_id" example:"dddddddd-dddd-dddd-dddd-dddddddddddd"`
+}
+
+// BatchUpdateTransactionsRequest описывает пакетный запрос на обновление транзакций.
+type BatchUpdateTransactionsRequest struct {
+	Transactions []BatchUpdateTransactionItem `json:"transactions" binding:"required"`
+	Atomic       bool                         `json:"atomic" example:"false"`
+}
+
+// RefundTransactionRequest описывает запрос на возврат по расходу.
+// Category обязательна для разделенных расходов.
+type RefundTransactionRequest struct {
+	Amount      float64   `json:"amount" binding:"required" example:"500"`
+	Category    string    `json:"category" example:"Продукты"`
+	Description string    `json:"description" example:"Возврат товара"`
+	OccurredAt  time.Time `json:"occurred_at" example:"2024-01-05T10:00:00Z"`
+}
+
+// BatchDeleteTransactionsRequest описывает пакетный запрос на удаление транзакций.
+type BatchDeleteTransactionsRequest struct {
+	IDs    []string `json:"ids" binding:"required"`
+	Atomic bool     `json:"atomic" example:"false"`
+}
+
+// BatchItemResult описывает результат обработки одного элемента пакета.
+type BatchItemResult struct {
+	Index       int          `json:"index" example:"0"`
+	Transaction *Transaction `json:"transaction,omitempty"`
+	Code        string       `json:"code" example:"OK"`
+	Error       string       `json:"error,omitempty" example:"budget exceeded: Продукты budget exceeded"`
 }
 
 // Budget описывает бюджет.
 type Budget struct {
-	ID        string    `json:"id" example:"11111111-1111-1111-1111-111111111111"`
-	AccountID string    `json:"account_id" example:"22222222-2222-2222-2222-222222222222"`
-	Name      string    `json:"name" example:"Еда"`
-	Amount    float64   `json:"amount" example:"10000"`
-	Currency  string    `json:"currency" example:"RUB"`
-	Period    string    `json:"period" example:"monthly"`
-	Month     time.Time `json:"month" example:"2024-01-01T00:00:00Z"`
-	CreatedAt time.Time `json:"created_at" example:"2024-01-01T00:00:00Z"`
-	UpdatedAt time.Time `json:"updated_at" example:"2024-01-01T00:00:00Z"`
+	ID        string     `json:"id" example:"11111111-1111-1111-1111-111111111111"`
+	AccountID string     `json:"account_id" example:"22222222-2222-2222-2222-222222222222"`
+	Name      string     `json:"name" example:"Еда"`
+	Amount    float64    `json:"amount" example:"10000"`
+	Currency  string     `json:"currency" example:"RUB"`
+	Period    string     `json:"period" example:"monthly"`
+	Month     time.Time  `json:"month" example:"2024-01-01T00:00:00Z"`
+	CreatedAt time.Time  `json:"created_at" example:"2024-01-01T00:00:00Z"`
+	UpdatedAt time.Time  `json:"updated_at" example:"2024-01-01T00:00:00Z"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty" example:"2024-01-02T00:00:00Z"`
 }
 
 // CreateBudgetRequest описывает запрос на создание бюджета.
@@ -77,6 +171,10 @@ type Report struct {
 	TotalExpense float64          `json:"total_expense" example:"30000"`
 	Currency     string           `json:"currency" example:"RUB"`
 	Categories   []ReportCategory `json:"categories"`
+	Tags         []ReportTag      `json:"tags"`
+	Payees       []ReportPayee    `json:"payees"`
+	Goals        []ReportGoal     `json:"goals"`
+	DeletedAt    *time.Time       `json:"deleted_at,omitempty" example:"2024-02-01T00:00:00Z"`
 }
 
 // ReportCategory описывает категорию расходов в отчете.
@@ -87,18 +185,45 @@ type ReportCategory struct {
 	BudgetUsagePercent *float64 `json:"budget_usage_percent" example:"60"`
 }
 
+// ReportTag описывает итоги по тегу в отчете. Транзакция учитывается в каждом
+// своем теге, поэтому итоги тегов могут пересекаться.
+type ReportTag struct {
+	Tag          string  `json:"tag" example:"отпуск"`
+	TotalIncome  float64 `json:"total_income" example:"0"`
+	TotalExpense float64 `json:"total_expense" example:"45000"`
+}
+
+// ReportPayee описывает расходы у получателя платежа за вычетом возвратов. Отчет содержит
+// получателей с наибольшими расходами.
+type ReportPayee struct {
+	PayeeID      string  `json:"payee_id" example:"dddddddd-dddd-dddd-dddd-dddddddddddd"`
+	Name         string  `json:"name" example:"Лента"`
+	TotalExpense float64 `json:"total_expense" example:"12500"`
+	Transactions int     `json:"transactions" example:"8"`
+}
+
+// ReportGoal описывает прогресс цели накоплений на конец периода отчета.
+type ReportGoal struct {
+	GoalID       string       `json:"goal_id" example:"eeeeeeee-eeee-eeee-eeee-eeeeeeeeeeee"`
+	Name         string       `json:"name" example:"Отпуск"`
+	TargetAmount float64      `json:"target_amount" example:"150000"`
+	Currency     string       `json:"currency" example:"RUB"`
+	Deadline     time.Time    `json:"deadline" example:"2024-06-30T00:00:00Z"`
+	Progress     GoalProgress `json:"progress"`
+}
+
 // CreateReportRequest описывает запрос на создание отчета.
 type CreateReportRequest struct {
-	Name        string    `json:"name" binding:"required" example:"Январь 2024"`
-	Period      string    `json:"period" binding:"required" example:"2024-01"`
-	Currency    string    `json:"currency" binding:"required" example:"RUB"`
+	Name     string `json:"name" binding:"required" example:"Январь 2024"`
+	Period   string `json:"period" binding:"required" example:"2024-01"`
+	Currency string `json:"currency" binding:"required" example:"RUB"`
 }
 
 // UpdateReportRequest описывает запрос на обновление отчета.
 type UpdateReportRequest struct {
-	Name        string    `json:"name" binding:"required" example:"Январь 2024"`
-	Period      string    `json:"period" binding:"required" example:"2024-01"`
-	Currency    string    `json:"currency" binding:"required" example:"RUB"`
+	Name     string `json:"name" binding:"required" example:"Январь 2024"`
+	Period   string `json:"period" binding:"required" example:"2024-01"`
+	Currency string `json:"currency" binding:"required" example:"RUB"`
 }
 
 // ImportTransactionsRequest описывает импорт транзакций из CSV.
@@ -121,3 +246,376 @@ type ExportTransactionsResponse struct {
 type DeleteResponse struct {
 	Deleted bool `json:"deleted" example:"true"`
 }
+
+// AuditEntry описывает запись журнала изменений.
+type AuditEntry struct {
+	ID         string          `json:"id" example:"33333333-3333-3333-3333-333333333333"`
+	AccountID  string          `json:"account_id" example:"22222222-2222-2222-2222-222222222222"`
+	ActorID    string          `json:"actor_id" example:"22222222-2222-2222-2222-222222222222"`
+	EntityType string          `json:"entity_type" example:"transaction"`
+	EntityID   string          `json:"entity_id" example:"11111111-1111-1111-1111-111111111111"`
+	Operation  string          `json:"operation" example:"update"`
+	Before     json.RawMessage `json:"before" swaggertype:"object"`
+	After      json.RawMessage `json:"after" swaggertype:"object"`
+	CreatedAt  time.Time       `json:"created_at" example:"2024-01-01T10:00:00Z"`
+}
+
+// Account описывает счет. Личный счет пользователя имеет ID пользователя и не может быть общим.
+type Account struct {
+	ID        string     `json:"id" example:"44444444-4444-4444-4444-444444444444"`
+	Name      string     `json:"name,omitempty" example:"Семейный бюджет"`
+	CreatedBy string     `json:"created_by" example:"33333333-3333-3333-3333-333333333333"`
+	CreatedAt *time.Time `json:"created_at,omitempty" example:"2024-01-01T10:00:00Z"`
+	Role      string     `json:"role" example:"owner"`
+	Personal  bool       `json:"personal" example:"false"`
+}
+
+// AccountsResponse описывает список счетов пользователя.
+type AccountsResponse struct {
+	Accounts []Account `json:"accounts"`
+}
+
+// CreateAccountRequest описывает запрос на создание общего счета.
+type CreateAccountRequest struct {
+	Name string `json:"name" binding:"required" example:"Семейный бюджет"`
+}
+
+// AccountMember описывает участника общего счета.
+type AccountMember struct {
+	AccountID string    `json:"account_id" example:"44444444-4444-4444-4444-444444444444"`
+	UserID    string    `json:"user_id" example:"33333333-3333-3333-3333-333333333333"`
+	Role      string    `json:"role" example:"editor"`
+	CreatedAt time.Time `json:"created_at" example:"2024-01-01T10:00:00Z"`
+}
+
+// MembersResponse описывает список участников общего счета.
+type MembersResponse struct {
+	Members []AccountMember `json:"members"`
+}
+
+// UpdateMemberRequest описывает запрос на изменение роли участника.
+type UpdateMemberRequest struct {
+	Role string `json:"role" binding:"required" example:"viewer"`
+}
+
+// Invitation описывает приглашение в общий счет.
+type Invitation struct {
+	ID        string    `json:"id" example:"55555555-5555-5555-5555-555555555555"`
+	AccountID string    `json:"account_id" example:"44444444-4444-4444-4444-444444444444"`
+	Role      string    `json:"role" example:"editor"`
+	CreatedBy string    `json:"created_by" example:"33333333-3333-3333-3333-333333333333"`
+	CreatedAt time.Time `json:"created_at" example:"2024-01-01T10:00:00Z"`
+	ExpiresAt time.Time `json:"expires_at" example:"2024-01-08T10:00:00Z"`
+}
+
+// InvitationsResponse описывает список действующих приглашений.
+type InvitationsResponse struct {
+	Invitations []Invitation `json:"invitations"`
+}
+
+// CreateInvitationRequest описывает запрос на создание приглашения.
+type CreateInvitationRequest struct {
+	Role string `json:"role" binding:"required" example:"editor"`
+}
+
+// CreateInvitationResponse описывает созданное приглашение. Токен возвращается только один раз.
+type CreateInvitationResponse struct {
+	Invitation Invitation `json:"invitation"`
+	Token      string     `json:"token" example:"q6Jv1n0bP0y4h2rPZK2m3Yb9cM7xQ1sW8eR5tU4iO0A"`
+}
+
+// AcceptInvitationRequest описывает запрос на принятие приглашения.
+type AcceptInvitationRequest struct {
+	Token string `json:"token" binding:"required" example:"q6Jv1n0bP0y4h2rPZK2m3Yb9cM7xQ1sW8eR5tU4iO0A"`
+}
+
+// Wallet описывает кошелек счета: наличные, карту или накопления. Баланс
+// считается по транзакциям кошелька.
+type Wallet struct {
+	ID        string    `json:"id" example:"66666666-6666-6666-6666-666666666666"`
+	AccountID string    `json:"account_id" example:"22222222-2222-2222-2222-222222222222"`
+	Name      string    `json:"name" example:"Наличные"`
+	Type      string    `json:"type" example:"cash"`
+	Currency  string    `json:"currency" example:"RUB"`
+	Balance   float64   `json:"balance" example:"15000"`
+	CreatedAt time.Time `json:"created_at" example:"2024-01-01T10:00:00Z"`
+	UpdatedAt time.Time `json:"updated_at" example:"2024-01-01T10:00:00Z"`
+}
+
+// WalletsResponse описывает список кошельков.
+type WalletsResponse struct {
+	Wallets []Wallet `json:"wallets"`
+}
+
+// CreateWalletRequest описывает запрос на создание кошелька.
+type CreateWalletRequest struct {
+	Name     string `json:"name" binding:"required" example:"Наличные"`
+	Type     string `json:"type" binding:"required" example:"cash"`
+	Currency string `json:"currency" binding:"required" example:"RUB"`
+}
+
+// UpdateWalletRequest описывает запрос на обновление кошелька.
+type UpdateWalletRequest struct {
+	Name     string `json:"name" binding:"required" example:"Наличные"`
+	Type     string `json:"type" binding:"required" example:"cash"`
+	Currency string `json:"currency" binding:"required" example:"RUB"`
+}
+
+// Transfer описывает перевод между кошельками.
+type Transfer struct {
+	ID           string    `json:"id" example:"77777777-7777-7777-7777-777777777777"`
+	AccountID    string    `json:"account_id" example:"22222222-2222-2222-2222-222222222222"`
+	FromWalletID string    `json:"from_wallet_id" example:"66666666-6666-6666-6666-666666666666"`
+	ToWalletID   string    `json:"to_wallet_id" example:"88888888-8888-8888-8888-888888888888"`
+	Amount       float64   `json:"amount" example:"5000"`
+	ToAmount     float64   `json:"to_amount" example:"5000"`
+	Description  string    `json:"description" example:"Пополнение накоплений"`
+	OccurredAt   time.Time `json:"occurred_at" example:"2024-01-01T10:00:00Z"`
+	CreatedBy    string    `json:"created_by,omitempty" example:"33333333-3333-3333-3333-333333333333"`
+}
+
+// CreateTransferRequest описывает запрос на перевод между кошельками.
+// ToAmount обязателен, если валюты кошельков различаются.
+type CreateTransferRequest struct {
+	FromWalletID string    `json:"from_wallet_id" binding:"required" example:"66666666-6666-6666-6666-666666666666"`
+	ToWalletID   string    `json:"to_wallet_id" binding:"required" example:"88888888-8888-8888-8888-888888888888"`
+	Amount       float64   `json:"amount" binding:"required" example:"5000"`
+	ToAmount     float64   `json:"to_amount" example:"5000"`
+	Description  string    `json:"description" example:"Пополнение накоплений"`
+	OccurredAt   time.Time `json:"occurred_at" example:"2024-01-01T10:00:00Z"`
+}
+
+// BalancePoint описывает баланс кошелька на конец дня или месяца, начинающегося с Date.
+type BalancePoint struct {
+	Date    time.Time `json:"date" example:"2024-01-01T00:00:00Z"`
+	Balance float64   `json:"balance" example:"15000"`
+}
+
+// BalanceHistoryResponse описывает историю баланса кошелька.
+type BalanceHistoryResponse struct {
+	Points []BalancePoint `json:"points"`
+}
+
+// Posting описывает проводку: дебет положительный, кредит отрицательный.
+type Posting struct {
+	Account  string  `json:"account" example:"assets:wallet:66666666-6666-6666-6666-666666666666"`
+	Type     string  `json:"type" example:"asset"`
+	Currency string  `json:"currency" example:"RUB"`
+	Amount   float64 `json:"amount" example:"1250.50"`
+}
+
+// JournalEntry описывает запись журнала двойной записи по одной транзакции.
+type JournalEntry struct {
+	TransactionID string    `json:"transaction_id" example:"11111111-1111-1111-1111-111111111111"`
+	AccountID     string    `json:"account_id" example:"22222222-2222-2222-2222-222222222222"`
+	OccurredAt    time.Time `json:"occurred_at" example:"2024-01-01T10:00:00Z"`
+	Description   string    `json:"description" example:"Покупка в магазине"`
+	Postings      []Posting `json:"postings"`
+	PostedAt      time.Time `json:"posted_at" example:"2024-01-01T10:00:00Z"`
+}
+
+// JournalEntriesResponse описывает список записей журнала.
+type JournalEntriesResponse struct {
+	Entries []JournalEntry `json:"entries"`
+}
+
+// TrialBalanceLine описывает сальдо счета журнала в одной валюте.
+type TrialBalanceLine struct {
+	Account  string  `json:"account" example:"expenses:Продукты"`
+	Type     string  `json:"type" example:"expense"`
+	Currency string  `json:"currency" example:"RUB"`
+	Debit    float64 `json:"debit" example:"30000"`
+	Credit   float64 `json:"credit" example:"0"`
+}
+
+// TrialBalanceTotal описывает итоги оборотно-сальдовой ведомости в одной валюте.
+type TrialBalanceTotal struct {
+	Currency string  `json:"currency" example:"RUB"`
+	Debit    float64 `json:"debit" example:"80000"`
+	Credit   float64 `json:"credit" example:"80000"`
+}
+
+// TrialBalance описывает оборотно-сальдовую ведомость счета. Дебет и кредит совпадают в каждой валюте.
+type TrialBalance struct {
+	AccountID string              `json:"account_id" example:"22222222-2222-2222-2222-222222222222"`
+	Lines     []TrialBalanceLine  `json:"lines"`
+	Totals    []TrialBalanceTotal `json:"totals"`
+}
+
+// Tag описывает тег транзакций счета.
+type Tag struct {
+	ID        string    `json:"id" example:"aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa"`
+	AccountID string    `json:"account_id" example:"22222222-2222-2222-2222-222222222222"`
+	Name      string    `json:"name" example:"отпуск"`
+	CreatedAt time.Time `json:"created_at" example:"2024-01-01T10:00:00Z"`
+	UpdatedAt time.Time `json:"updated_at" example:"2024-01-01T10:00:00Z"`
+}
+
+// TagsResponse описывает список тегов.
+type TagsResponse struct {
+	Tags []Tag `json:"tags"`
+}
+
+// TagRequest описывает запрос на создание или переименование тега.
+type TagRequest struct {
+	Name string `json:"name" binding:"required" example:"отпуск"`
+}
+
+// Attachment описывает файл, прикрепленный к транзакции: фото чека или счет в PDF.
+type Attachment struct {
+	ID            string    `json:"id" example:"bbbbbbbb-bbbb-bbbb-bbbb-bbbbbbbbbbbb"`
+	AccountID     string    `json:"account_id" example:"22222222-2222-2222-2222-222222222222"`
+	TransactionID string    `json:"transaction_id" example:"11111111-1111-1111-1111-111111111111"`
+	FileName      string    `json:"file_name" example:"receipt.jpg"`
+	ContentType   string    `json:"content_type" example:"image/jpeg"`
+	Size          int64     `json:"size" example:"245760"`
+	Checksum      string    `json:"checksum" example:"9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"`
+	CreatedBy     string    `json:"created_by,omitempty" example:"33333333-3333-3333-3333-333333333333"`
+	CreatedAt     time.Time `json:"created_at" example:"2024-01-01T10:00:00Z"`
+}
+
+// AttachmentsResponse описывает список вложений транзакции.
+type AttachmentsResponse struct {
+	Attachments []Attachment `json:"attachments"`
+}
+
+// Rule описывает правило автоматической категоризации. Правило срабатывает, когда выполнены
+// все его условия; пустые условия не проверяются.
+type Rule struct {
+	ID                  string    `json:"id" example:"cccccccc-cccc-cccc-cccc-cccccccccccc"`
+	AccountID           string    `json:"account_id" example:"22222222-2222-2222-2222-222222222222"`
+	Name                string    `json:"name" example:"Такси"`
+	Priority            int       `json:"priority" example:"10"`
+	DescriptionContains string    `json:"description_contains,omitempty" example:"YANDEX*TAXI"`
+	DescriptionPattern  string    `json:"description_pattern,omitempty" example:"(?i)^uber"`
+	MinAmount           *float64  `json:"min_amount,omitempty" example:"100"`
+	MaxAmount           *float64  `json:"max_amount,omitempty" example:"5000"`
+	Type                string    `json:"type,omitempty" example:"expense"`
+	Currency            string    `json:"currency,omitempty" example:"RUB"`
+	Category            string    `json:"category" example:"Транспорт"`
+	Enabled             bool      `json:"enabled" example:"true"`
+	CreatedAt           time.Time `json:"created_at" example:"2024-01-01T10:00:00Z"`
+	UpdatedAt           time.Time `json:"updated_at" example:"2024-01-01T10:00:00Z"`
+}
+
+// RulesResponse описывает список правил в порядке применения.
+type RulesResponse struct {
+	Rules []Rule `json:"rules"`
+}
+
+// RuleRequest описывает запрос на создание или изменение правила. Правила применяются по
+// возрастанию priority, срабатывает первое подходящее. Правило без enabled включено.
+type RuleRequest struct {
+	Name                string   `json:"name" binding:"required" example:"Такси"`
+	Priority            int      `json:"priority" example:"10"`
+	DescriptionContains string   `json:"description_contains" example:"YANDEX*TAXI"`
+	DescriptionPattern  string   `json:"description_pattern" example:"(?i)^uber"`
+	MinAmount           *float64 `json:"min_amount" example:"100"`
+	MaxAmount           *float64 `json:"max_amount" example:"5000"`
+	Type                string   `json:"type" example:"expense"`
+	Currency            string   `json:"currency" example:"RUB"`
+	Category            string   `json:"category" binding:"required" example:"Транспорт"`
+	Enabled             *bool    `json:"enabled" example:"true"`
+}
+
+// RulePreviewRequest описывает условия несохраненного правила для предпросмотра.
+type RulePreviewRequest struct {
+	DescriptionContains string   `json:"description_contains" example:"YANDEX*TAXI"`
+	DescriptionPattern  string   `json:"description_pattern" example:"(?i)^uber"`
+	MinAmount           *float64 `json:"min_amount" example:"100"`
+	MaxAmount           *float64 `json:"max_amount" example:"5000"`
+	Type                string   `json:"type" example:"expense"`
+	Currency            string   `json:"currency" example:"RUB"`
+	Category            string   `json:"category" binding:"required" example:"Транспорт"`
+}
+
+// ApplyRulesRequest описывает запрос на применение правил к истории.
+type ApplyRulesRequest struct {
+	// DryRun возвращает изменения, не сохраняя их.
+	DryRun bool `json:"dry_run" example:"true"`
+}
+
+// RuleMatch описывает транзакцию, подходящую под правило: транзакция приводится в текущем
+// виде, category — категория, которую назначает правило.
+type RuleMatch struct {
+	Transaction Transaction `json:"transaction"`
+	RuleID      string      `json:"rule_id,omitempty" example:"cccccccc-cccc-cccc-cccc-cccccccccccc"`
+	Category    string      `json:"category" example:"Транспорт"`
+}
+
+// RuleMatchesResponse описывает список транзакций, подходящих под правила.
+type RuleMatchesResponse struct {
+	Matches []RuleMatch `json:"matches"`
+}
+
+// Payee описывает получателя платежа: магазин или контрагента. Описание транзакции относится
+// к получателю, если после нормализации совпадает с его именем или псевдонимом: регистр,
+// слова с цифрами (номера магазинов) и города в конце не учитываются.
+type Payee struct {
+	ID              string    `json:"id" example:"dddddddd-dddd-dddd-dddd-dddddddddddd"`
+	AccountID       string    `json:"account_id" example:"22222222-2222-2222-2222-222222222222"`
+	Name            string    `json:"name" example:"Лента"`
+	Aliases         []string  `json:"aliases" example:"LENTA"`
+	DefaultCategory string    `json:"default_category,omitempty" example:"Продукты"`
+	CreatedAt       time.Time `json:"created_at" example:"2024-01-01T10:00:00Z"`
+	UpdatedAt       time.Time `json:"updated_at" example:"2024-01-01T10:00:00Z"`
+}
+
+// PayeesResponse описывает список получателей платежей.
+type PayeesResponse struct {
+	Payees []Payee `json:"payees"`
+}
+
+// PayeeRequest описывает запрос на создание или изменение получателя платежа.
+// DefaultCategory назначается новым транзакциям получателя, если не сработало ни одно правило.
+type PayeeRequest struct {
+	Name            string   `json:"name" binding:"required" example:"Лента"`
+	Aliases         []string `json:"aliases" example:"LENTA"`
+	DefaultCategory string   `json:"default_category" example:"Продукты"`
+}
+
+// Goal описывает цель накоплений. Взносы учитываются либо расходами в категории Category
+// или с тегом Tag за вычетом возвратов и доходов в них, либо балансом кошелька WalletID.
+type Goal struct {
+	ID           string       `json:"id" example:"eeeeeeee-eeee-eeee-eeee-eeeeeeeeeeee"`
+	AccountID    string       `json:"account_id" example:"22222222-2222-2222-2222-222222222222"`
+	Name         string       `json:"name" example:"Отпуск"`
+	TargetAmount float64      `json:"target_amount" example:"150000"`
+	Currency     string       `json:"currency" example:"RUB"`
+	Deadline     time.Time    `json:"deadline" example:"2024-06-30T00:00:00Z"`
+	Category     string       `json:"category,omitempty" example:"Накопления"`
+	WalletID     string       `json:"wallet_id,omitempty" example:"33333333-3333-3333-3333-333333333333"`
+	Tag          string       `json:"tag,omitempty" example:"отпуск"`
+	Progress     GoalProgress `json:"progress"`
+	CreatedAt    time.Time    `json:"created_at" example:"2024-01-01T10:00:00Z"`
+	UpdatedAt    time.Time    `json:"updated_at" example:"2024-01-01T10:00:00Z"`
+}
+
+// GoalProgress описывает накопленную сумму и ежемесячный взнос, нужный, чтобы успеть к сроку.
+// MonthsLeft считает календарные месяцы до срока включая текущий и равен нулю после срока,
+// тогда MonthlyRequired равен всему остатку.
+type GoalProgress struct {
+	Saved           float64 `json:"saved" example:"45000"`
+	Remaining       float64 `json:"remaining" example:"105000"`
+	Percent         float64 `json:"percent" example:"30"`
+	MonthsLeft      int     `json:"months_left" example:"6"`
+	MonthlyRequired float64 `json:"monthly_required" example:"17500"`
+}
+
+// GoalsResponse описывает список целей накоплений.
+type GoalsResponse struct {
+	Goals []Goal `json:"goals"`
+}
+
+// GoalRequest описывает запрос на создание или изменение цели накоплений. Нужно указать
+// ровно один источник взносов: category, tag или wallet_id; валюта цели с кошельком совпадает
+// с валютой кошелька.
+type GoalRequest struct {
+	Name         string    `json:"name" binding:"required" example:"Отпуск"`
+	TargetAmount float64   `json:"target_amount" binding:"required" example:"150000"`
+	Currency     string    `json:"currency" binding:"required" example:"RUB"`
+	Deadline     time.Time `json:"deadline" binding:"required" example:"2024-06-30T00:00:00Z"`
+	Category     string    `json:"category" example:"Накопления"`
+	Tag          string    `json:"tag" example:"отпуск"`
+	WalletID     string    `json:"wallet_id"`
+}
@@ -5,6 +5,20 @@ type ErrorResponse struct {
 	Error string `json:"error" example:"validation failed"`
 }
 
+// ValidationErrorResponse описывает ошибку валидации с перечнем нарушенных правил,
+// например требований к паролю.
+type ValidationErrorResponse struct {
+	Error      string           `json:"error" example:"password does not meet the policy"`
+	Violations []FieldViolation `json:"violations"`
+}
+
+// FieldViolation описывает нарушенное правило для поля запроса.
+type FieldViolation struct {
+	Field       string `json:"field" example:"password"`
+	Reason      string `json:"reason" example:"PASSWORD_TOO_SHORT"`
+	Description string `json:"description" example:"must be at least 10 characters long"`
+}
+
 // TransactionsResponse описывает список транзакций.
 type TransactionsResponse struct {
 	Transactions []Transaction `json:"transactions"`
@@ -19,3 +33,22 @@ type BudgetsResponse struct {
 type ReportsResponse struct {
 	Reports []Report `json:"reports"`
 }
+
+// HistoryResponse описывает историю изменений сущности.
+type HistoryResponse struct {
+	Entries []AuditEntry `json:"entries"`
+}
+
+// TrashResponse описывает удаленные сущности, которые можно восстановить.
+type TrashResponse struct {
+	Transactions []Transaction `json:"transactions"`
+	Budgets      []Budget      `json:"budgets"`
+	Reports      []Report      `json:"reports"`
+}
+
+// BatchTransactionsResponse описывает результаты пакетной операции над транзакциями.
+type BatchTransactionsResponse struct {
+	Results   []BatchItemResult `json:"results"`
+	Succeeded int               `json:"succeeded" example:"2"`
+	Failed    int               `json:"failed" example:"1"`
+}
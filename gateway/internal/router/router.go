@@ -6,10 +6,11 @@ import (
 	"github.com/Deevins/final-task-course-2-go-lang/gateway/internal/handler"
 )
 
-func Register(engine *gin.Engine, authHandler *handler.AuthHandler, ledgerHandler *handler.LedgerHandler, authMiddleware gin.HandlerFunc) {
+func Register(engine *gin.Engine, authHandler *handler.AuthHandler, ledgerHandler *handler.LedgerHandler, adminHandler *handler.AdminHandler, authMiddleware gin.HandlerFunc) {
 	registerSwagger(engine)
 
 	api := engine.Group("/api")
-	authHandler.Register(api)
+	authHandler.Register(api, authMiddleware)
 	ledgerHandler.Register(api, authMiddleware)
+	adminHandler.Register(api, authMiddleware)
 }
@@ -2,15 +2,38 @@ package service
 
 import (
 	"context"
+	"time"
 
 	"github.com/Deevins/final-task-course-2-go-lang/gateway/internal/model"
 	authv1 "github.com/Deevins/final-task-course-2-go-lang/gateway/internal/pb/auth/v1"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 type AuthGatewayService interface {
 	SignUp(ctx context.Context, req model.SignUpRequest) (*model.SignUpResponse, error)
 	SignIn(ctx context.Context, req model.SignInRequest) (*model.SignInResponse, error)
 	ValidateToken(ctx context.Context, accessToken string) (*authv1.ValidateTokenResponse, error)
+	ListRevokedTokens(ctx context.Context, since time.Time) (*authv1.ListRevokedTokensResponse, error)
+	Refresh(ctx context.Context, req model.RefreshRequest) (*model.SignInResponse, error)
+	Logout(ctx context.Context, accessToken string, req model.LogoutRequest) error
+	RevokeAllSessions(ctx context.Context, accessToken string) (*model.RevokeAllSessionsResponse, error)
+	GetMe(ctx context.Context, accessToken string) (*model.UserProfile, error)
+	UpdateProfile(ctx context.Context, accessToken string, req model.UpdateProfileRequest) (*model.UserProfile, error)
+	ChangePassword(ctx context.Context, accessToken string, req model.ChangePasswordRequest) (*model.SignInResponse, error)
+	DeleteAccount(ctx context.Context, accessToken string, req model.DeleteAccountRequest) error
+	RequestEmailVerification(ctx context.Context, accessToken string) error
+	ConfirmEmail(ctx context.Context, req model.ConfirmEmailRequest) error
+	RequestPasswordReset(ctx context.Context, req model.PasswordResetRequest) error
+	ResetPassword(ctx context.Context, req model.ResetPasswordRequest) error
+	VerifyTwoFactor(ctx context.Context, req model.VerifyTwoFactorRequest) (*model.SignInResponse, error)
+	EnrollTOTP(ctx context.Context, accessToken string) (*model.EnrollTOTPResponse, error)
+	ConfirmTOTP(ctx context.Context, accessToken string, req model.ConfirmTOTPRequest) (*model.ConfirmTOTPResponse, error)
+	DisableTOTP(ctx context.Context, accessToken string, req model.DisableTOTPRequest) error
+	ListIdentityProviders(ctx context.Context) (*model.IdentityProvidersResponse, error)
+	StartOIDCLogin(ctx context.Context, provider string) (*model.OIDCAuthorization, error)
+	CompleteOIDCLogin(ctx context.Context, provider, state, code string) (*model.SignInResponse, error)
+	SetUserRoles(ctx context.Context, accessToken, userID string, req model.SetUserRolesRequest) (*model.UserProfile, error)
+	LookupUser(ctx context.Context, accessToken, email string) (*model.UserProfile, error)
 }
 
 type authGatewayService struct {
@@ -44,15 +67,240 @@ func (s *authGatewayService) SignIn(ctx context.Context, req model.SignInRequest
 	if err != nil {
 		return nil, err
 	}
-	expiresAt := resp.GetExpiresAt()
-	var expiresTime model.SignInResponse
-	if expiresAt != nil {
-		expiresTime.ExpiresAt = expiresAt.AsTime()
+	return toSignInResponse(resp), nil
+}
+
+func (s *authGatewayService) VerifyTwoFactor(ctx context.Context, req model.VerifyTwoFactorRequest) (*model.SignInResponse, error) {
+	resp, err := s.client.VerifyTwoFactor(ctx, &authv1.VerifyTwoFactorRequest{
+		ChallengeToken: req.ChallengeToken,
+		Code:           req.Code,
+	})
+	if err != nil {
+		return nil, err
 	}
-	expiresTime.AccessToken = resp.GetAccessToken()
-	return &expiresTime, nil
+	return toSignInResponse(resp), nil
 }
 
 func (s *authGatewayService) ValidateToken(ctx context.Context, accessToken string) (*authv1.ValidateTokenResponse, error) {
 	return s.client.ValidateToken(ctx, &authv1.ValidateTokenRequest{AccessToken: accessToken})
 }
+
+func (s *authGatewayService) ListRevokedTokens(ctx context.Context, since time.Time) (*authv1.ListRevokedTokensResponse, error) {
+	req := &authv1.ListRevokedTokensRequest{}
+	if !since.IsZero() {
+		req.Since = timestamppb.New(since)
+	}
+	return s.client.ListRevokedTokens(ctx, req)
+}
+
+func (s *authGatewayService) Refresh(ctx context.Context, req model.RefreshRequest) (*model.SignInResponse, error) {
+	resp, err := s.client.Refresh(ctx, &authv1.RefreshRequest{RefreshToken: req.RefreshToken})
+	if err != nil {
+		return nil, err
+	}
+	return &model.SignInResponse{
+		AccessToken:      resp.GetAccessToken(),
+		ExpiresAt:        toTime(resp.GetExpiresAt()),
+		RefreshToken:     resp.GetRefreshToken(),
+		RefreshExpiresAt: toTime(resp.GetRefreshExpiresAt()),
+	}, nil
+}
+
+func (s *authGatewayService) Logout(ctx context.Context, accessToken string, req model.LogoutRequest) error {
+	_, err := s.client.Logout(ctx, &authv1.LogoutRequest{
+		AccessToken:  accessToken,
+		RefreshToken: req.RefreshToken,
+	})
+	return err
+}
+
+func (s *authGatewayService) RevokeAllSessions(ctx context.Context, accessToken string) (*model.RevokeAllSessionsResponse, error) {
+	resp, err := s.client.RevokeAllSessions(ctx, &authv1.RevokeAllSessionsRequest{AccessToken: accessToken})
+	if err != nil {
+		return nil, err
+	}
+	return &model.RevokeAllSessionsResponse{RevokedSessions: resp.GetRevokedSessions()}, nil
+}
+
+func (s *authGatewayService) GetMe(ctx context.Context, accessToken string) (*model.UserProfile, error) {
+	resp, err := s.client.GetMe(ctx, &authv1.GetMeRequest{AccessToken: accessToken})
+	if err != nil {
+		return nil, err
+	}
+	return toUserProfile(resp.GetUser()), nil
+}
+
+func (s *authGatewayService) UpdateProfile(ctx context.Context, accessToken string, req model.UpdateProfileRequest) (*model.UserProfile, error) {
+	resp, err := s.client.UpdateProfile(ctx, &authv1.UpdateProfileRequest{
+		AccessToken: accessToken,
+		Name:        req.Name,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toUserProfile(resp.GetUser()), nil
+}
+
+func (s *authGatewayService) ChangePassword(ctx context.Context, accessToken string, req model.ChangePasswordRequest) (*model.SignInResponse, error) {
+	resp, err := s.client.ChangePassword(ctx, &authv1.ChangePasswordRequest{
+		AccessToken:     accessToken,
+		CurrentPassword: req.CurrentPassword,
+		NewPassword:     req.NewPassword,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &model.SignInResponse{
+		AccessToken:      resp.GetAccessToken(),
+		ExpiresAt:        toTime(resp.GetExpiresAt()),
+		RefreshToken:     resp.GetRefreshToken(),
+		RefreshExpiresAt: toTime(resp.GetRefreshExpiresAt()),
+	}, nil
+}
+
+func (s *authGatewayService) DeleteAccount(ctx context.Context, accessToken string, req model.DeleteAccountRequest) error {
+	_, err := s.client.DeleteAccount(ctx, &authv1.DeleteAccountRequest{
+		AccessToken: accessToken,
+		Password:    req.Password,
+	})
+	return err
+}
+
+func (s *authGatewayService) RequestEmailVerification(ctx context.Context, accessToken string) error {
+	_, err := s.client.RequestEmailVerification(ctx, &authv1.RequestEmailVerificationRequest{AccessToken: accessToken})
+	return err
+}
+
+func (s *authGatewayService) ConfirmEmail(ctx context.Context, req model.ConfirmEmailRequest) error {
+	_, err := s.client.ConfirmEmail(ctx, &authv1.ConfirmEmailRequest{Token: req.Token})
+	return err
+}
+
+func (s *authGatewayService) RequestPasswordReset(ctx context.Context, req model.PasswordResetRequest) error {
+	_, err := s.client.RequestPasswordReset(ctx, &authv1.RequestPasswordResetRequest{Email: req.Email})
+	return err
+}
+
+func (s *authGatewayService) ResetPassword(ctx context.Context, req model.ResetPasswordRequest) error {
+	_, err := s.client.ResetPassword(ctx, &authv1.ResetPasswordRequest{
+		Token:       req.Token,
+		NewPassword: req.NewPassword,
+	})
+	return err
+}
+
+func (s *authGatewayService) EnrollTOTP(ctx context.Context, accessToken string) (*model.EnrollTOTPResponse, error) {
+	resp, err := s.client.EnrollTotp(ctx, &authv1.EnrollTotpRequest{AccessToken: accessToken})
+	if err != nil {
+		return nil, err
+	}
+	return &model.EnrollTOTPResponse{
+		Secret:          resp.GetSecret(),
+		ProvisioningURI: resp.GetProvisioningUri(),
+	}, nil
+}
+
+func (s *authGatewayService) ConfirmTOTP(ctx context.Context, accessToken string, req model.ConfirmTOTPRequest) (*model.ConfirmTOTPResponse, error) {
+	resp, err := s.client.ConfirmTotp(ctx, &authv1.ConfirmTotpRequest{
+		AccessToken: accessToken,
+		Code:        req.Code,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &model.ConfirmTOTPResponse{RecoveryCodes: resp.GetRecoveryCodes()}, nil
+}
+
+func (s *authGatewayService) DisableTOTP(ctx context.Context, accessToken string, req model.DisableTOTPRequest) error {
+	_, err := s.client.DisableTotp(ctx, &authv1.DisableTotpRequest{
+		AccessToken: accessToken,
+		Password:    req.Password,
+		Code:        req.Code,
+	})
+	return err
+}
+
+func (s *authGatewayService) ListIdentityProviders(ctx context.Context) (*model.IdentityProvidersResponse, error) {
+	resp, err := s.client.ListIdentityProviders(ctx, &authv1.ListIdentityProvidersRequest{})
+	if err != nil {
+		return nil, err
+	}
+	providers := resp.GetProviders()
+	if providers == nil {
+		providers = []string{}
+	}
+	return &model.IdentityProvidersResponse{Providers: providers}, nil
+}
+
+func (s *authGatewayService) StartOIDCLogin(ctx context.Context, provider string) (*model.OIDCAuthorization, error) {
+	resp, err := s.client.StartOidcLogin(ctx, &authv1.StartOidcLoginRequest{Provider: provider})
+	if err != nil {
+		return nil, err
+	}
+	return &model.OIDCAuthorization{
+		URL:       resp.GetAuthorizationUrl(),
+		State:     resp.GetState(),
+		ExpiresAt: toTime(resp.GetExpiresAt()),
+	}, nil
+}
+
+func (s *authGatewayService) CompleteOIDCLogin(ctx context.Context, provider, state, code string) (*model.SignInResponse, error) {
+	resp, err := s.client.CompleteOidcLogin(ctx, &authv1.CompleteOidcLoginRequest{
+		Provider: provider,
+		State:    state,
+		Code:     code,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toSignInResponse(resp), nil
+}
+
+func (s *authGatewayService) SetUserRoles(ctx context.Context, accessToken, userID string, req model.SetUserRolesRequest) (*model.UserProfile, error) {
+	resp, err := s.client.SetUserRoles(ctx, &authv1.SetUserRolesRequest{
+		AccessToken: accessToken,
+		UserId:      userID,
+		Roles:       req.Roles,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toUserProfile(resp.GetUser()), nil
+}
+
+func (s *authGatewayService) LookupUser(ctx context.Context, accessToken, email string) (*model.UserProfile, error) {
+	resp, err := s.client.LookupUser(ctx, &authv1.LookupUserRequest{AccessToken: accessToken, Email: email})
+	if err != nil {
+		return nil, err
+	}
+	return toUserProfile(resp.GetUser()), nil
+}
+
+func toSignInResponse(resp *authv1.SignInResponse) *model.SignInResponse {
+	if resp.GetTwoFactorRequired() {
+		return &model.SignInResponse{
+			TwoFactorRequired:  true,
+			ChallengeToken:     resp.GetChallengeToken(),
+			ChallengeExpiresAt: toTime(resp.GetChallengeExpiresAt()),
+		}
+	}
+	return &model.SignInResponse{
+		AccessToken:      resp.GetAccessToken(),
+		ExpiresAt:        toTime(resp.GetExpiresAt()),
+		RefreshToken:     resp.GetRefreshToken(),
+		RefreshExpiresAt: toTime(resp.GetRefreshExpiresAt()),
+	}
+}
+
+func toUserProfile(user *authv1.User) *model.UserProfile {
+	return &model.UserProfile{
+		ID:               user.GetId(),
+		Email:            user.GetEmail(),
+		Name:             user.GetName(),
+		CreatedAt:        toTime(user.GetCreatedAt()),
+		UpdatedAt:        toTime(user.GetUpdatedAt()),
+		EmailVerified:    user.GetEmailVerified(),
+		TwoFactorEnabled: user.GetTwoFactorEnabled(),
+		Roles:            user.GetRoles(),
+	}
+}
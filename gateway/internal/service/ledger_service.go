@@ -2,19 +2,31 @@ package service
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"io"
 	"time"
 
 	"github.com/Deevins/final-task-course-2-go-lang/gateway/internal/model"
 	ledgerv1 "github.com/Deevins/final-task-course-2-go-lang/gateway/internal/pb/ledger/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
 )
 
 type LedgerGatewayService interface {
 	ListTransactions(ctx context.Context, accountID string) ([]model.Transaction, error)
+	SearchTransactions(ctx context.Context, accountID string, query model.TransactionSearchQuery) ([]model.Transaction, error)
 	CreateTransaction(ctx context.Context, req model.CreateTransactionRequest) (*model.Transaction, error)
 	GetTransaction(ctx context.Context, id string) (*model.Transaction, error)
 	UpdateTransaction(ctx context.Context, id string, req model.UpdateTransactionRequest) (*model.Transaction, error)
 	DeleteTransaction(ctx context.Context, id string) (bool, error)
+	BatchCreateTransactions(ctx context.Context, accountID string, req model.BatchCreateTransactionsRequest) (*model.BatchTransactionsResponse, error)
+	BatchUpdateTransactions(ctx context.Context, accountID string, req model.BatchUpdateTransactionsRequest) (*model.BatchTransactionsResponse, error)
+	BatchDeleteTransactions(ctx context.Context, accountID string, req model.BatchDeleteTransactionsRequest) (*model.BatchTransactionsResponse, error)
+	RefundTransaction(ctx context.Context, accountID, id string, req model.RefundTransactionRequest) (*model.Transaction, error)
+	ListRefunds(ctx context.Context, accountID, id string) ([]model.Transaction, error)
 	ListBudgets(ctx context.Context, accountID string) ([]model.Budget, error)
 	CreateBudget(ctx context.Context, accountID string, req model.CreateBudgetRequest) (*model.Budget, error)
 	GetBudget(ctx context.Context, accountID, id string) (*model.Budget, error)
@@ -27,8 +39,62 @@ type LedgerGatewayService interface {
 	DeleteReport(ctx context.Context, accountID, id string) (bool, error)
 	ImportTransactionsCSV(ctx context.Context, accountID string, csvContent []byte, hasHeader bool) (int32, error)
 	ExportTransactionsCSV(ctx context.Context, accountID string) ([]byte, error)
+	ListHistory(ctx context.Context, accountID, entityType, id string) ([]model.AuditEntry, error)
+	ListTrash(ctx context.Context, accountID string) (*model.TrashResponse, error)
+	RestoreTransaction(ctx context.Context, accountID, id string) (*model.Transaction, error)
+	RestoreBudget(ctx context.Context, accountID, id string) (*model.Budget, error)
+	RestoreReport(ctx context.Context, accountID, id string) (*model.Report, error)
+	ListAccounts(ctx context.Context) ([]model.Account, error)
+	CreateAccount(ctx context.Context, req model.CreateAccountRequest) (*model.Account, error)
+	ListMembers(ctx context.Context, accountID string) ([]model.AccountMember, error)
+	UpdateMember(ctx context.Context, accountID, userID string, req model.UpdateMemberRequest) (*model.AccountMember, error)
+	RemoveMember(ctx context.Context, accountID, userID string) (bool, error)
+	ListInvitations(ctx context.Context, accountID string) ([]model.Invitation, error)
+	CreateInvitation(ctx context.Context, accountID string, req model.CreateInvitationRequest) (*model.CreateInvitationResponse, error)
+	RevokeInvitation(ctx context.Context, accountID, id string) (bool, error)
+	AcceptInvitation(ctx context.Context, req model.AcceptInvitationRequest) (*model.AccountMember, error)
+	ListWallets(ctx context.Context, accountID string) ([]model.Wallet, error)
+	CreateWallet(ctx context.Context, accountID string, req model.CreateWalletRequest) (*model.Wallet, error)
+	GetWallet(ctx context.Context, accountID, id string) (*model.Wallet, error)
+	UpdateWallet(ctx context.Context, accountID, id string, req model.UpdateWalletRequest) (*model.Wallet, error)
+	DeleteWallet(ctx context.Context, accountID, id string) (bool, error)
+	GetWalletBalanceHistory(ctx context.Context, accountID, id string, from, to time.Time, step string) ([]model.BalancePoint, error)
+	ListTags(ctx context.Context, accountID string) ([]model.Tag, error)
+	CreateTag(ctx context.Context, accountID string, req model.TagRequest) (*model.Tag, error)
+	GetTag(ctx context.Context, accountID, id string) (*model.Tag, error)
+	UpdateTag(ctx context.Context, accountID, id string, req model.TagRequest) (*model.Tag, error)
+	DeleteTag(ctx context.Context, accountID, id string) (bool, error)
+	UploadAttachment(ctx context.Context, accountID, transactionID, fileName string, content io.Reader) (*model.Attachment, error)
+	ListAttachments(ctx context.Context, accountID, transactionID string) ([]model.Attachment, error)
+	GetAttachment(ctx context.Context, accountID, id string) (*model.Attachment, error)
+	DownloadAttachment(ctx context.Context, accountID, id string) (*model.Attachment, io.ReadCloser, error)
+	DeleteAttachment(ctx context.Context, accountID, id string) (bool, error)
+	CreateRule(ctx context.Context, accountID string, req model.RuleRequest) (*model.Rule, error)
+	GetRule(ctx context.Context, accountID, id string) (*model.Rule, error)
+	UpdateRule(ctx context.Context, accountID, id string, req model.RuleRequest) (*model.Rule, error)
+	DeleteRule(ctx context.Context, accountID, id string) (bool, error)
+	ListRules(ctx context.Context, accountID string) ([]model.Rule, error)
+	PreviewRule(ctx context.Context, accountID string, req model.RulePreviewRequest) ([]model.RuleMatch, error)
+	ApplyRules(ctx context.Context, accountID string, dryRun bool) ([]model.RuleMatch, error)
+	CreatePayee(ctx context.Context, accountID string, req model.PayeeRequest) (*model.Payee, error)
+	GetPayee(ctx context.Context, accountID, id string) (*model.Payee, error)
+	UpdatePayee(ctx context.Context, accountID, id string, req model.PayeeRequest) (*model.Payee, error)
+	DeletePayee(ctx context.Context, accountID, id string) (bool, error)
+	ListPayees(ctx context.Context, accountID string) ([]model.Payee, error)
+	CreateGoal(ctx context.Context, accountID string, req model.GoalRequest) (*model.Goal, error)
+	GetGoal(ctx context.Context, accountID, id string) (*model.Goal, error)
+	UpdateGoal(ctx context.Context, accountID, id string, req model.GoalRequest) (*model.Goal, error)
+	DeleteGoal(ctx context.Context, accountID, id string) (bool, error)
+	ListGoals(ctx context.Context, accountID string) ([]model.Goal, error)
+	CreateTransfer(ctx context.Context, accountID string, req model.CreateTransferRequest) (*model.Transfer, error)
+	DeleteTransfer(ctx context.Context, accountID, id string) (bool, error)
+	ListJournalEntries(ctx context.Context, accountID string) ([]model.JournalEntry, error)
+	GetTrialBalance(ctx context.Context, accountID string) (*model.TrialBalance, error)
 }
 
+// attachmentChunkSize is the size of the content chunks of an upload.
+const attachmentChunkSize = 64 << 10
+
 type ledgerGatewayService struct {
 	client ledgerv1.LedgerServiceClient
 }
@@ -48,6 +114,38 @@ func (s *ledgerGatewayService) ListTransactions(ctx context.Context, accountID s
 	return fromProtoTransactions(resp.GetTransactions()), nil
 }
 
+func (s *ledgerGatewayService) SearchTransactions(ctx context.Context, accountID string, query model.TransactionSearchQuery) ([]model.Transaction, error) {
+	req := &ledgerv1.SearchTransactionsRequest{
+		AccountId: accountID,
+		Category:  query.Category,
+		Currency:  query.Currency,
+		WalletId:  query.WalletID,
+		Tag:       query.Tag,
+		PayeeId:   query.PayeeID,
+		Query:     query.Query,
+		Sort:      query.Sort,
+		Limit:     int32(query.Limit),
+		Offset:    int32(query.Offset),
+	}
+	if !query.From.IsZero() {
+		req.From = timestamppb.New(query.From)
+	}
+	if !query.To.IsZero() {
+		req.To = timestamppb.New(query.To)
+	}
+	if query.Min != nil {
+		req.MinAmount = wrapperspb.Double(*query.Min)
+	}
+	if query.Max != nil {
+		req.MaxAmount = wrapperspb.Double(*query.Max)
+	}
+	resp, err := s.client.SearchTransactions(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return fromProtoTransactions(resp.GetTransactions()), nil
+}
+
 func (s *ledgerGatewayService) CreateTransaction(ctx context.Context, req model.CreateTransactionRequest) (*model.Transaction, error) {
 	resp, err := s.client.CreateTransaction(ctx, &ledgerv1.CreateTransactionRequest{
 		Transaction: &ledgerv1.Transaction{
@@ -57,6 +155,10 @@ func (s *ledgerGatewayService) CreateTransaction(ctx context.Context, req model.
 			Category:    req.Category,
 			Description: req.Description,
 			OccurredAt:  timestamppb.New(req.OccurredAt),
+			WalletId:    req.WalletID,
+			Splits:      toProtoSplits(req.Splits),
+			Tags:        req.Tags,
+			PayeeId:     req.PayeeID,
 		},
 	})
 	if err != nil {
@@ -83,6 +185,10 @@ func (s *ledgerGatewayService) UpdateTransaction(ctx context.Context, id string,
 			Category:    req.Category,
 			Description: req.Description,
 			OccurredAt:  timestamppb.New(req.OccurredAt),
+			WalletId:    req.WalletID,
+			Splits:      toProtoSplits(req.Splits),
+			Tags:        req.Tags,
+			PayeeId:     req.PayeeID,
 		},
 	})
 	if err != nil {
@@ -99,6 +205,99 @@ func (s *ledgerGatewayService) DeleteTransaction(ctx context.Context, id string)
 	return resp.GetDeleted(), nil
 }
 
+func (s *ledgerGatewayService) BatchCreateTransactions(ctx context.Context, accountID string, req model.BatchCreateTransactionsRequest) (*model.BatchTransactionsResponse, error) {
+	items := make([]*ledgerv1.Transaction, 0, len(req.Transactions))
+	for _, item := range req.Transactions {
+		items = append(items, &ledgerv1.Transaction{
+			AccountId:   accountID,
+			Amount:      item.Amount,
+			Currency:    item.Currency,
+			Category:    item.Category,
+			Description: item.Description,
+			OccurredAt:  timestamppb.New(item.OccurredAt),
+			WalletId:    item.WalletID,
+			Splits:      toProtoSplits(item.Splits),
+			Tags:        item.Tags,
+			PayeeId:     item.PayeeID,
+		})
+	}
+	resp, err := s.client.BatchCreateTransactions(ctx, &ledgerv1.BatchCreateTransactionsRequest{
+		AccountId:    accountID,
+		Transactions: items,
+		Atomic:       req.Atomic,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return fromProtoBatchResponse(resp), nil
+}
+
+func (s *ledgerGatewayService) BatchUpdateTransactions(ctx context.Context, accountID string, req model.BatchUpdateTransactionsRequest) (*model.BatchTransactionsResponse, error) {
+	items := make([]*ledgerv1.Transaction, 0, len(req.Transactions))
+	for _, item := range req.Transactions {
+		items = append(items, &ledgerv1.Transaction{
+			Id:          item.ID,
+			AccountId:   accountID,
+			Amount:      item.Amount,
+			Currency:    item.Currency,
+			Category:    item.Category,
+			Description: item.Description,
+			OccurredAt:  timestamppb.New(item.OccurredAt),
+			WalletId:    item.WalletID,
+			Splits:      toProtoSplits(item.Splits),
+			Tags:        item.Tags,
+			PayeeId:     item.PayeeID,
+		})
+	}
+	resp, err := s.client.BatchUpdateTransactions(ctx, &ledgerv1.BatchUpdateTransactionsRequest{
+		AccountId:    accountID,
+		Transactions: items,
+		Atomic:       req.Atomic,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return fromProtoBatchResponse(resp), nil
+}
+
+func (s *ledgerGatewayService) BatchDeleteTransactions(ctx context.Context, accountID string, req model.BatchDeleteTransactionsRequest) (*model.BatchTransactionsResponse, error) {
+	resp, err := s.client.BatchDeleteTransactions(ctx, &ledgerv1.BatchDeleteTransactionsRequest{
+		AccountId: accountID,
+		Ids:       req.IDs,
+		Atomic:    req.Atomic,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return fromProtoBatchResponse(resp), nil
+}
+
+func (s *ledgerGatewayService) RefundTransaction(ctx context.Context, accountID, id string, req model.RefundTransactionRequest) (*model.Transaction, error) {
+	refund := &ledgerv1.RefundTransactionRequest{
+		AccountId:     accountID,
+		TransactionId: id,
+		Amount:        req.Amount,
+		Category:      req.Category,
+		Description:   req.Description,
+	}
+	if !req.OccurredAt.IsZero() {
+		refund.OccurredAt = timestamppb.New(req.OccurredAt)
+	}
+	resp, err := s.client.RefundTransaction(ctx, refund)
+	if err != nil {
+		return nil, err
+	}
+	return fromProtoTransaction(resp.GetTransaction()), nil
+}
+
+func (s *ledgerGatewayService) ListRefunds(ctx context.Context, accountID, id string) ([]model.Transaction, error) {
+	resp, err := s.client.ListRefunds(ctx, &ledgerv1.ListRefundsRequest{AccountId: accountID, TransactionId: id})
+	if err != nil {
+		return nil, err
+	}
+	return fromProtoTransactions(resp.GetTransactions()), nil
+}
+
 func (s *ledgerGatewayService) ListBudgets(ctx context.Context, accountID string) ([]model.Budget, error) {
 	resp, err := s.client.ListBudgets(ctx, &ledgerv1.ListBudgetsRequest{AccountId: accountID})
 	if err != nil {
@@ -233,140 +432,1192 @@ func (s *ledgerGatewayService) ExportTransactionsCSV(ctx context.Context, accoun
 	return resp.GetCsvContent(), nil
 }
 
-func fromProtoTransactions(items []*ledgerv1.Transaction) []model.Transaction {
-	out := make([]model.Transaction, 0, len(items))
-	for _, item := range items {
-		if item == nil {
-			continue
+func (s *ledgerGatewayService) ListHistory(ctx context.Context, accountID, entityType, id string) ([]model.AuditEntry, error) {
+	resp, err := s.client.ListHistory(ctx, &ledgerv1.ListHistoryRequest{
+		AccountId:  accountID,
+		EntityType: entityType,
+		EntityId:   id,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return fromProtoAuditEntries(resp.GetEntries()), nil
+}
+
+func (s *ledgerGatewayService) ListTrash(ctx context.Context, accountID string) (*model.TrashResponse, error) {
+	resp, err := s.client.ListDeleted(ctx, &ledgerv1.ListDeletedRequest{AccountId: accountID})
+	if err != nil {
+		return nil, err
+	}
+	return &model.TrashResponse{
+		Transactions: fromProtoTransactions(resp.GetTransactions()),
+		Budgets:      fromProtoBudgets(resp.GetBudgets()),
+		Reports:      fromProtoReports(resp.GetReports()),
+	}, nil
+}
+
+func (s *ledgerGatewayService) RestoreTransaction(ctx context.Context, accountID, id string) (*model.Transaction, error) {
+	resp, err := s.restore(ctx, accountID, "transaction", id)
+	if err != nil {
+		return nil, err
+	}
+	return fromProtoTransaction(resp.GetTransaction()), nil
+}
+
+func (s *ledgerGatewayService) RestoreBudget(ctx context.Context, accountID, id string) (*model.Budget, error) {
+	resp, err := s.restore(ctx, accountID, "budget", id)
+	if err != nil {
+		return nil, err
+	}
+	return fromProtoBudget(resp.GetBudget()), nil
+}
+
+func (s *ledgerGatewayService) RestoreReport(ctx context.Context, accountID, id string) (*model.Report, error) {
+	resp, err := s.restore(ctx, accountID, "report", id)
+	if err != nil {
+		return nil, err
+	}
+	return fromProtoReport(resp.GetReport()), nil
+}
+
+func (s *ledgerGatewayService) restore(ctx context.Context, accountID, entityType, id string) (*ledgerv1.RestoreResponse, error) {
+	return s.client.Restore(ctx, &ledgerv1.RestoreRequest{
+		AccountId:  accountID,
+		EntityType: entityType,
+		Id:         id,
+	})
+}
+
+func (s *ledgerGatewayService) ListAccounts(ctx context.Context) ([]model.Account, error) {
+	resp, err := s.client.ListAccounts(ctx, &ledgerv1.ListAccountsRequest{})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]model.Account, 0, len(resp.GetAccounts()))
+	for _, item := range resp.GetAccounts() {
+		if account := fromProtoAccount(item); account != nil {
+			out = append(out, *account)
 		}
-		if tx := fromProtoTransaction(item); tx != nil {
-			out = append(out, *tx)
+	}
+	return out, nil
+}
+
+func (s *ledgerGatewayService) CreateAccount(ctx context.Context, req model.CreateAccountRequest) (*model.Account, error) {
+	resp, err := s.client.CreateAccount(ctx, &ledgerv1.CreateAccountRequest{Name: req.Name})
+	if err != nil {
+		return nil, err
+	}
+	return fromProtoAccount(resp.GetAccount()), nil
+}
+
+func (s *ledgerGatewayService) ListMembers(ctx context.Context, accountID string) ([]model.AccountMember, error) {
+	resp, err := s.client.ListMembers(ctx, &ledgerv1.ListMembersRequest{AccountId: accountID})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]model.AccountMember, 0, len(resp.GetMembers()))
+	for _, item := range resp.GetMembers() {
+		if member := fromProtoAccountMember(item); member != nil {
+			out = append(out, *member)
 		}
 	}
-	return out
+	return out, nil
 }
 
-func fromProtoTransaction(item *ledgerv1.Transaction) *model.Transaction {
-	if item == nil {
-		return nil
+func (s *ledgerGatewayService) UpdateMember(ctx context.Context, accountID, userID string, req model.UpdateMemberRequest) (*model.AccountMember, error) {
+	resp, err := s.client.UpdateMember(ctx, &ledgerv1.UpdateMemberRequest{
+		AccountId: accountID,
+		UserId:    userID,
+		Role:      req.Role,
+	})
+	if err != nil {
+		return nil, err
 	}
-	return &model.Transaction{
-		ID:          item.GetId(),
-		AccountID:   item.GetAccountId(),
-		Amount:      item.GetAmount(),
-		Currency:    item.GetCurrency(),
-		Category:    item.GetCategory(),
-		Description: item.GetDescription(),
-		OccurredAt:  toTime(item.GetOccurredAt()),
-		CreatedAt:   toTime(item.GetCreatedAt()),
-		UpdatedAt:   toTime(item.GetUpdatedAt()),
+	return fromProtoAccountMember(resp.GetMember()), nil
+}
+
+func (s *ledgerGatewayService) RemoveMember(ctx context.Context, accountID, userID string) (bool, error) {
+	resp, err := s.client.RemoveMember(ctx, &ledgerv1.RemoveMemberRequest{AccountId: accountID, UserId: userID})
+	if err != nil {
+		return false, err
 	}
+	return resp.GetDeleted(), nil
 }
 
-func fromProtoBudgets(items []*ledgerv1.Budget) []model.Budget {
-	out := make([]model.Budget, 0, len(items))
-	for _, item := range items {
-		if item == nil {
-			continue
+func (s *ledgerGatewayService) ListInvitations(ctx context.Context, accountID string) ([]model.Invitation, error) {
+	resp, err := s.client.ListInvitations(ctx, &ledgerv1.ListInvitationsRequest{AccountId: accountID})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]model.Invitation, 0, len(resp.GetInvitations()))
+	for _, item := range resp.GetInvitations() {
+		if invitation := fromProtoInvitation(item); invitation != nil {
+			out = append(out, *invitation)
 		}
-		out = append(out, model.Budget{
-			ID:        item.GetId(),
-			AccountID: item.GetAccountId(),
-			Name:      item.GetName(),
-			Amount:    item.GetAmount(),
-			Currency:  item.GetCurrency(),
-			Period:    item.GetPeriod(),
-			Month:     toTime(item.GetMonth()),
-			CreatedAt: toTime(item.GetCreatedAt()),
-			UpdatedAt: toTime(item.GetUpdatedAt()),
-		})
 	}
-	return out
+	return out, nil
 }
 
-func fromProtoBudget(item *ledgerv1.Budget) *model.Budget {
-	if item == nil {
-		return nil
+func (s *ledgerGatewayService) CreateInvitation(ctx context.Context, accountID string, req model.CreateInvitationRequest) (*model.CreateInvitationResponse, error) {
+	resp, err := s.client.CreateInvitation(ctx, &ledgerv1.CreateInvitationRequest{AccountId: accountID, Role: req.Role})
+	if err != nil {
+		return nil, err
 	}
-	return &model.Budget{
-		ID:        item.GetId(),
-		AccountID: item.GetAccountId(),
-		Name:      item.GetName(),
-		Amount:    item.GetAmount(),
-		Currency:  item.GetCurrency(),
-		Period:    item.GetPeriod(),
-		Month:     toTime(item.GetMonth()),
-		CreatedAt: toTime(item.GetCreatedAt()),
-		UpdatedAt: toTime(item.GetUpdatedAt()),
+	out := &model.CreateInvitationResponse{Token: resp.GetToken()}
+	if invitation := fromProtoInvitation(resp.GetInvitation()); invitation != nil {
+		out.Invitation = *invitation
 	}
+	return out, nil
 }
 
-func fromProtoReports(items []*ledgerv1.Report) []model.Report {
-	out := make([]model.Report, 0, len(items))
-	for _, item := range items {
-		if item == nil {
-			continue
-		}
-		categories := fromProtoReportCategories(item.GetCategories())
-		out = append(out, model.Report{
-			ID:           item.GetId(),
-			AccountID:    item.GetAccountId(),
-			Name:         item.GetName(),
-			Period:       item.GetPeriod(),
-			GeneratedAt:  toTime(item.GetGeneratedAt()),
-			TotalIncome:  item.GetTotalIncome(),
-			TotalExpense: item.GetTotalExpense(),
-			Currency:     item.GetCurrency(),
-			Categories:   categories,
-		})
+func (s *ledgerGatewayService) RevokeInvitation(ctx context.Context, accountID, id string) (bool, error) {
+	resp, err := s.client.RevokeInvitation(ctx, &ledgerv1.RevokeInvitationRequest{AccountId: accountID, Id: id})
+	if err != nil {
+		return false, err
 	}
-	return out
+	return resp.GetDeleted(), nil
 }
 
-func fromProtoReport(item *ledgerv1.Report) *model.Report {
-	if item == nil {
-		return nil
+func (s *ledgerGatewayService) AcceptInvitation(ctx context.Context, req model.AcceptInvitationRequest) (*model.AccountMember, error) {
+	resp, err := s.client.AcceptInvitation(ctx, &ledgerv1.AcceptInvitationRequest{Token: req.Token})
+	if err != nil {
+		return nil, err
 	}
-	return &model.Report{
-		ID:           item.GetId(),
-		AccountID:    item.GetAccountId(),
-		Name:         item.GetName(),
-		Period:       item.GetPeriod(),
-		GeneratedAt:  toTime(item.GetGeneratedAt()),
-		TotalIncome:  item.GetTotalIncome(),
-		TotalExpense: item.GetTotalExpense(),
-		Currency:     item.GetCurrency(),
-		Categories:   fromProtoReportCategories(item.GetCategories()),
+	return fromProtoAccountMember(resp.GetMember()), nil
+}
+
+func (s *ledgerGatewayService) ListWallets(ctx context.Context, accountID string) ([]model.Wallet, error) {
+	resp, err := s.client.ListWallets(ctx, &ledgerv1.ListWalletsRequest{AccountId: accountID})
+	if err != nil {
+		return nil, err
 	}
+	return fromProtoWallets(resp.GetWallets()), nil
 }
 
-func fromProtoReportCategories(items []*ledgerv1.ReportCategory) []model.ReportCategory {
-	if len(items) == 0 {
-		return nil
+func (s *ledgerGatewayService) CreateWallet(ctx context.Context, accountID string, req model.CreateWalletRequest) (*model.Wallet, error) {
+	resp, err := s.client.CreateWallet(ctx, &ledgerv1.CreateWalletRequest{
+		Wallet: &ledgerv1.Wallet{
+			AccountId: accountID,
+			Name:      req.Name,
+			Type:      req.Type,
+			Currency:  req.Currency,
+		},
+	})
+	if err != nil {
+		return nil, err
 	}
-	out := make([]model.ReportCategory, 0, len(items))
-	for _, item := range items {
-		if item == nil {
-			continue
-		}
-		var usagePercent *float64
-		if item.GetBudgetUsagePercent() != nil {
-			value := item.GetBudgetUsagePercent().GetValue()
-			usagePercent = &value
-		}
-		out = append(out, model.ReportCategory{
-			Category:           item.GetCategory(),
-			TotalExpense:       item.GetTotalExpense(),
-			BudgetAmount:       item.GetBudgetAmount(),
-			BudgetUsagePercent: usagePercent,
-		})
+	return fromProtoWallet(resp.GetWallet()), nil
+}
+
+func (s *ledgerGatewayService) GetWallet(ctx context.Context, accountID, id string) (*model.Wallet, error) {
+	resp, err := s.client.GetWallet(ctx, &ledgerv1.GetWalletRequest{AccountId: accountID, Id: id})
+	if err != nil {
+		return nil, err
 	}
-	return out
+	return fromProtoWallet(resp.GetWallet()), nil
 }
 
-func toTime(ts *timestamppb.Timestamp) time.Time {
-	if ts == nil {
-		return time.Time{}
+func (s *ledgerGatewayService) UpdateWallet(ctx context.Context, accountID, id string, req model.UpdateWalletRequest) (*model.Wallet, error) {
+	resp, err := s.client.UpdateWallet(ctx, &ledgerv1.UpdateWalletRequest{
+		Wallet: &ledgerv1.Wallet{
+			Id:        id,
+			AccountId: accountID,
+			Name:      req.Name,
+			Type:      req.Type,
+			Currency:  req.Currency,
+		},
+	})
+	if err != nil {
+		return nil, err
 	}
-	return ts.AsTime()
+	return fromProtoWallet(resp.GetWallet()), nil
+}
+
+func (s *ledgerGatewayService) DeleteWallet(ctx context.Context, accountID, id string) (bool, error) {
+	resp, err := s.client.DeleteWallet(ctx, &ledgerv1.DeleteWalletRequest{AccountId: accountID, Id: id})
+	if err != nil {
+		return false, err
+	}
+	return resp.GetDeleted(), nil
+}
+
+func (s *ledgerGatewayService) GetWalletBalanceHistory(ctx context.Context, accountID, id string, from, to time.Time, step string) ([]model.BalancePoint, error) {
+	resp, err := s.client.GetWalletBalanceHistory(ctx, &ledgerv1.GetWalletBalanceHistoryRequest{
+		AccountId: accountID,
+		WalletId:  id,
+		From:      timestamppb.New(from),
+		To:        timestamppb.New(to),
+		Step:      step,
+	})
+	if err != nil {
+		return nil, err
+	}
+	points := make([]model.BalancePoint, 0, len(resp.GetPoints()))
+	for _, point := range resp.GetPoints() {
+		points = append(points, model.BalancePoint{Date: toTime(point.GetDate()), Balance: point.GetBalance()})
+	}
+	return points, nil
+}
+
+func (s *ledgerGatewayService) ListTags(ctx context.Context, accountID string) ([]model.Tag, error) {
+	resp, err := s.client.ListTags(ctx, &ledgerv1.ListTagsRequest{AccountId: accountID})
+	if err != nil {
+		return nil, err
+	}
+	tags := make([]model.Tag, 0, len(resp.GetTags()))
+	for _, item := range resp.GetTags() {
+		if tag := fromProtoTag(item); tag != nil {
+			tags = append(tags, *tag)
+		}
+	}
+	return tags, nil
+}
+
+func (s *ledgerGatewayService) CreateTag(ctx context.Context, accountID string, req model.TagRequest) (*model.Tag, error) {
+	resp, err := s.client.CreateTag(ctx, &ledgerv1.CreateTagRequest{
+		Tag: &ledgerv1.Tag{AccountId: accountID, Name: req.Name},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return fromProtoTag(resp.GetTag()), nil
+}
+
+func (s *ledgerGatewayService) GetTag(ctx context.Context, accountID, id string) (*model.Tag, error) {
+	resp, err := s.client.GetTag(ctx, &ledgerv1.GetTagRequest{AccountId: accountID, Id: id})
+	if err != nil {
+		return nil, err
+	}
+	return fromProtoTag(resp.GetTag()), nil
+}
+
+func (s *ledgerGatewayService) UpdateTag(ctx context.Context, accountID, id string, req model.TagRequest) (*model.Tag, error) {
+	resp, err := s.client.UpdateTag(ctx, &ledgerv1.UpdateTagRequest{
+		Tag: &ledgerv1.Tag{Id: id, AccountId: accountID, Name: req.Name},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return fromProtoTag(resp.GetTag()), nil
+}
+
+func (s *ledgerGatewayService) DeleteTag(ctx context.Context, accountID, id string) (bool, error) {
+	resp, err := s.client.DeleteTag(ctx, &ledgerv1.DeleteTagRequest{AccountId: accountID, Id: id})
+	if err != nil {
+		return false, err
+	}
+	return resp.GetDeleted(), nil
+}
+
+// UploadAttachment streams the content to the ledger: the attachment goes in
+// the first message, the content in the following ones.
+func (s *ledgerGatewayService) UploadAttachment(ctx context.Context, accountID, transactionID, fileName string, content io.Reader) (*model.Attachment, error) {
+	stream, err := s.client.UploadAttachment(ctx)
+	if err != nil {
+		return nil, err
+	}
+	err = stream.Send(&ledgerv1.UploadAttachmentRequest{
+		Payload: &ledgerv1.UploadAttachmentRequest_Attachment{Attachment: &ledgerv1.Attachment{
+			AccountId:     accountID,
+			TransactionId: transactionID,
+			FileName:      fileName,
+		}},
+	})
+	buf := make([]byte, attachmentChunkSize)
+	for err == nil {
+		n, readErr := content.Read(buf)
+		if n > 0 {
+			err = stream.Send(&ledgerv1.UploadAttachmentRequest{
+				Payload: &ledgerv1.UploadAttachmentRequest_Chunk{Chunk: buf[:n]},
+			})
+		}
+		if errors.Is(readErr, io.EOF) {
+			break
+		}
+		if readErr != nil {
+			return nil, readErr
+		}
+	}
+	// io.EOF means the ledger ended the stream early; its status comes with
+	// the response.
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	resp, err := stream.CloseAndRecv()
+	if err != nil {
+		return nil, err
+	}
+	return fromProtoAttachment(resp.GetAttachment()), nil
+}
+
+func (s *ledgerGatewayService) ListAttachments(ctx context.Context, accountID, transactionID string) ([]model.Attachment, error) {
+	resp, err := s.client.ListAttachments(ctx, &ledgerv1.ListAttachmentsRequest{AccountId: accountID, TransactionId: transactionID})
+	if err != nil {
+		return nil, err
+	}
+	attachments := make([]model.Attachment, 0, len(resp.GetAttachments()))
+	for _, item := range resp.GetAttachments() {
+		if attachment := fromProtoAttachment(item); attachment != nil {
+			attachments = append(attachments, *attachment)
+		}
+	}
+	return attachments, nil
+}
+
+func (s *ledgerGatewayService) GetAttachment(ctx context.Context, accountID, id string) (*model.Attachment, error) {
+	resp, err := s.client.GetAttachment(ctx, &ledgerv1.GetAttachmentRequest{AccountId: accountID, Id: id})
+	if err != nil {
+		return nil, err
+	}
+	return fromProtoAttachment(resp.GetAttachment()), nil
+}
+
+// DownloadAttachment returns the attachment and a reader of its content that
+// pulls chunks from the ledger. The caller closes the reader.
+func (s *ledgerGatewayService) DownloadAttachment(ctx context.Context, accountID, id string) (*model.Attachment, io.ReadCloser, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	stream, err := s.client.DownloadAttachment(ctx, &ledgerv1.DownloadAttachmentRequest{AccountId: accountID, Id: id})
+	if err != nil {
+		cancel()
+		return nil, nil, err
+	}
+	first, err := stream.Recv()
+	if err != nil {
+		cancel()
+		return nil, nil, err
+	}
+	if first.GetAttachment() == nil {
+		cancel()
+		return nil, nil, status.Error(codes.Internal, "download attachment: the first message must carry the attachment")
+	}
+	return fromProtoAttachment(first.GetAttachment()), &attachmentStreamReader{stream: stream, cancel: cancel}, nil
+}
+
+func (s *ledgerGatewayService) DeleteAttachment(ctx context.Context, accountID, id string) (bool, error) {
+	resp, err := s.client.DeleteAttachment(ctx, &ledgerv1.DeleteAttachmentRequest{AccountId: accountID, Id: id})
+	if err != nil {
+		return false, err
+	}
+	return resp.GetDeleted(), nil
+}
+
+func (s *ledgerGatewayService) CreateRule(ctx context.Context, accountID string, req model.RuleRequest) (*model.Rule, error) {
+	resp, err := s.client.CreateRule(ctx, &ledgerv1.CreateRuleRequest{Rule: toProtoRule(accountID, "", req)})
+	if err != nil {
+		return nil, err
+	}
+	return fromProtoRule(resp.GetRule()), nil
+}
+
+func (s *ledgerGatewayService) GetRule(ctx context.Context, accountID, id string) (*model.Rule, error) {
+	resp, err := s.client.GetRule(ctx, &ledgerv1.GetRuleRequest{AccountId: accountID, Id: id})
+	if err != nil {
+		return nil, err
+	}
+	return fromProtoRule(resp.GetRule()), nil
+}
+
+func (s *ledgerGatewayService) UpdateRule(ctx context.Context, accountID, id string, req model.RuleRequest) (*model.Rule, error) {
+	resp, err := s.client.UpdateRule(ctx, &ledgerv1.UpdateRuleRequest{Rule: toProtoRule(accountID, id, req)})
+	if err != nil {
+		return nil, err
+	}
+	return fromProtoRule(resp.GetRule()), nil
+}
+
+func (s *ledgerGatewayService) DeleteRule(ctx context.Context, accountID, id string) (bool, error) {
+	resp, err := s.client.DeleteRule(ctx, &ledgerv1.DeleteRuleRequest{AccountId: accountID, Id: id})
+	if err != nil {
+		return false, err
+	}
+	return resp.GetDeleted(), nil
+}
+
+func (s *ledgerGatewayService) ListRules(ctx context.Context, accountID string) ([]model.Rule, error) {
+	resp, err := s.client.ListRules(ctx, &ledgerv1.ListRulesRequest{AccountId: accountID})
+	if err != nil {
+		return nil, err
+	}
+	rules := make([]model.Rule, 0, len(resp.GetRules()))
+	for _, item := range resp.GetRules() {
+		if rule := fromProtoRule(item); rule != nil {
+			rules = append(rules, *rule)
+		}
+	}
+	return rules, nil
+}
+
+func (s *ledgerGatewayService) PreviewRule(ctx context.Context, accountID string, req model.RulePreviewRequest) ([]model.RuleMatch, error) {
+	rule := &ledgerv1.Rule{
+		AccountId:           accountID,
+		DescriptionContains: req.DescriptionContains,
+		DescriptionPattern:  req.DescriptionPattern,
+		Type:                req.Type,
+		Currency:            req.Currency,
+		Category:            req.Category,
+	}
+	if req.MinAmount != nil {
+		rule.MinAmount = wrapperspb.Double(*req.MinAmount)
+	}
+	if req.MaxAmount != nil {
+		rule.MaxAmount = wrapperspb.Double(*req.MaxAmount)
+	}
+	resp, err := s.client.PreviewRule(ctx, &ledgerv1.PreviewRuleRequest{Rule: rule})
+	if err != nil {
+		return nil, err
+	}
+	return fromProtoRuleMatches(resp.GetMatches()), nil
+}
+
+func (s *ledgerGatewayService) ApplyRules(ctx context.Context, accountID string, dryRun bool) ([]model.RuleMatch, error) {
+	resp, err := s.client.ApplyRules(ctx, &ledgerv1.ApplyRulesRequest{AccountId: accountID, DryRun: dryRun})
+	if err != nil {
+		return nil, err
+	}
+	return fromProtoRuleMatches(resp.GetMatches()), nil
+}
+
+func (s *ledgerGatewayService) CreatePayee(ctx context.Context, accountID string, req model.PayeeRequest) (*model.Payee, error) {
+	resp, err := s.client.CreatePayee(ctx, &ledgerv1.CreatePayeeRequest{Payee: toProtoPayee(accountID, "", req)})
+	if err != nil {
+		return nil, err
+	}
+	return fromProtoPayee(resp.GetPayee()), nil
+}
+
+func (s *ledgerGatewayService) GetPayee(ctx context.Context, accountID, id string) (*model.Payee, error) {
+	resp, err := s.client.GetPayee(ctx, &ledgerv1.GetPayeeRequest{AccountId: accountID, Id: id})
+	if err != nil {
+		return nil, err
+	}
+	return fromProtoPayee(resp.GetPayee()), nil
+}
+
+func (s *ledgerGatewayService) UpdatePayee(ctx context.Context, accountID, id string, req model.PayeeRequest) (*model.Payee, error) {
+	resp, err := s.client.UpdatePayee(ctx, &ledgerv1.UpdatePayeeRequest{Payee: toProtoPayee(accountID, id, req)})
+	if err != nil {
+		return nil, err
+	}
+	return fromProtoPayee(resp.GetPayee()), nil
+}
+
+func (s *ledgerGatewayService) DeletePayee(ctx context.Context, accountID, id string) (bool, error) {
+	resp, err := s.client.DeletePayee(ctx, &ledgerv1.DeletePayeeRequest{AccountId: accountID, Id: id})
+	if err != nil {
+		return false, err
+	}
+	return resp.GetDeleted(), nil
+}
+
+func (s *ledgerGatewayService) ListPayees(ctx context.Context, accountID string) ([]model.Payee, error) {
+	resp, err := s.client.ListPayees(ctx, &ledgerv1.ListPayeesRequest{AccountId: accountID})
+	if err != nil {
+		return nil, err
+	}
+	payees := make([]model.Payee, 0, len(resp.GetPayees()))
+	for _, item := range resp.GetPayees() {
+		if payee := fromProtoPayee(item); payee != nil {
+			payees = append(payees, *payee)
+		}
+	}
+	return payees, nil
+}
+
+func (s *ledgerGatewayService) CreateGoal(ctx context.Context, accountID string, req model.GoalRequest) (*model.Goal, error) {
+	resp, err := s.client.CreateGoal(ctx, &ledgerv1.CreateGoalRequest{Goal: toProtoGoal(accountID, "", req)})
+	if err != nil {
+		return nil, err
+	}
+	return fromProtoGoal(resp.GetGoal()), nil
+}
+
+func (s *ledgerGatewayService) GetGoal(ctx context.Context, accountID, id string) (*model.Goal, error) {
+	resp, err := s.client.GetGoal(ctx, &ledgerv1.GetGoalRequest{AccountId: accountID, Id: id})
+	if err != nil {
+		return nil, err
+	}
+	return fromProtoGoal(resp.GetGoal()), nil
+}
+
+func (s *ledgerGatewayService) UpdateGoal(ctx context.Context, accountID, id string, req model.GoalRequest) (*model.Goal, error) {
+	resp, err := s.client.UpdateGoal(ctx, &ledgerv1.UpdateGoalRequest{Goal: toProtoGoal(accountID, id, req)})
+	if err != nil {
+		return nil, err
+	}
+	return fromProtoGoal(resp.GetGoal()), nil
+}
+
+func (s *ledgerGatewayService) DeleteGoal(ctx context.Context, accountID, id string) (bool, error) {
+	resp, err := s.client.DeleteGoal(ctx, &ledgerv1.DeleteGoalRequest{AccountId: accountID, Id: id})
+	if err != nil {
+		return false, err
+	}
+	return resp.GetDeleted(), nil
+}
+
+func (s *ledgerGatewayService) ListGoals(ctx context.Context, accountID string) ([]model.Goal, error) {
+	resp, err := s.client.ListGoals(ctx, &ledgerv1.ListGoalsRequest{AccountId: accountID})
+	if err != nil {
+		return nil, err
+	}
+	goals := make([]model.Goal, 0, len(resp.GetGoals()))
+	for _, item := range resp.GetGoals() {
+		if goal := fromProtoGoal(item); goal != nil {
+			goals = append(goals, *goal)
+		}
+	}
+	return goals, nil
+}
+
+// attachmentStreamReader reads the content chunks of a download stream.
+type attachmentStreamReader struct {
+	stream ledgerv1.LedgerService_DownloadAttachmentClient
+	cancel context.CancelFunc
+	chunk  []byte
+}
+
+func (r *attachmentStreamReader) Read(p []byte) (int, error) {
+	for len(r.chunk) == 0 {
+		resp, err := r.stream.Recv()
+		if err != nil {
+			return 0, err
+		}
+		r.chunk = resp.GetChunk()
+	}
+	n := copy(p, r.chunk)
+	r.chunk = r.chunk[n:]
+	return n, nil
+}
+
+// Close cancels the stream, so an unfinished download stops on the ledger too.
+func (r *attachmentStreamReader) Close() error {
+	r.cancel()
+	return nil
+}
+
+func (s *ledgerGatewayService) CreateTransfer(ctx context.Context, accountID string, req model.CreateTransferRequest) (*model.Transfer, error) {
+	transfer := &ledgerv1.Transfer{
+		AccountId:    accountID,
+		FromWalletId: req.FromWalletID,
+		ToWalletId:   req.ToWalletID,
+		Amount:       req.Amount,
+		ToAmount:     req.ToAmount,
+		Description:  req.Description,
+	}
+	if !req.OccurredAt.IsZero() {
+		transfer.OccurredAt = timestamppb.New(req.OccurredAt)
+	}
+	resp, err := s.client.CreateTransfer(ctx, &ledgerv1.CreateTransferRequest{Transfer: transfer})
+	if err != nil {
+		return nil, err
+	}
+	return fromProtoTransfer(resp.GetTransfer()), nil
+}
+
+func (s *ledgerGatewayService) DeleteTransfer(ctx context.Context, accountID, id string) (bool, error) {
+	resp, err := s.client.DeleteTransfer(ctx, &ledgerv1.DeleteTransferRequest{AccountId: accountID, Id: id})
+	if err != nil {
+		return false, err
+	}
+	return resp.GetDeleted(), nil
+}
+
+func (s *ledgerGatewayService) ListJournalEntries(ctx context.Context, accountID string) ([]model.JournalEntry, error) {
+	resp, err := s.client.ListJournalEntries(ctx, &ledgerv1.ListJournalEntriesRequest{AccountId: accountID})
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]model.JournalEntry, 0, len(resp.GetEntries()))
+	for _, item := range resp.GetEntries() {
+		entry := model.JournalEntry{
+			TransactionID: item.GetTransactionId(),
+			AccountID:     item.GetAccountId(),
+			OccurredAt:    toTime(item.GetOccurredAt()),
+			Description:   item.GetDescription(),
+			Postings:      make([]model.Posting, 0, len(item.GetPostings())),
+			PostedAt:      toTime(item.GetPostedAt()),
+		}
+		for _, posting := range item.GetPostings() {
+			entry.Postings = append(entry.Postings, model.Posting{
+				Account:  posting.GetAccount(),
+				Type:     posting.GetType(),
+				Currency: posting.GetCurrency(),
+				Amount:   posting.GetAmount(),
+			})
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+func (s *ledgerGatewayService) GetTrialBalance(ctx context.Context, accountID string) (*model.TrialBalance, error) {
+	resp, err := s.client.GetTrialBalance(ctx, &ledgerv1.GetTrialBalanceRequest{AccountId: accountID})
+	if err != nil {
+		return nil, err
+	}
+	balance := &model.TrialBalance{
+		AccountID: resp.GetAccountId(),
+		Lines:     make([]model.TrialBalanceLine, 0, len(resp.GetLines())),
+		Totals:    make([]model.TrialBalanceTotal, 0, len(resp.GetTotals())),
+	}
+	for _, line := range resp.GetLines() {
+		balance.Lines = append(balance.Lines, model.TrialBalanceLine{
+			Account:  line.GetAccount(),
+			Type:     line.GetType(),
+			Currency: line.GetCurrency(),
+			Debit:    line.GetDebit(),
+			Credit:   line.GetCredit(),
+		})
+	}
+	for _, total := range resp.GetTotals() {
+		balance.Totals = append(balance.Totals, model.TrialBalanceTotal{
+			Currency: total.GetCurrency(),
+			Debit:    total.GetDebit(),
+			Credit:   total.GetCredit(),
+		})
+	}
+	return balance, nil
+}
+
+func fromProtoAccount(item *ledgerv1.Account) *model.Account {
+	if item == nil {
+		return nil
+	}
+	return &model.Account{
+		ID:        item.GetId(),
+		Name:      item.GetName(),
+		CreatedBy: item.GetCreatedBy(),
+		CreatedAt: toTimePtr(item.GetCreatedAt()),
+		Role:      item.GetRole(),
+		Personal:  item.GetPersonal(),
+	}
+}
+
+func fromProtoAccountMember(item *ledgerv1.AccountMember) *model.AccountMember {
+	if item == nil {
+		return nil
+	}
+	return &model.AccountMember{
+		AccountID: item.GetAccountId(),
+		UserID:    item.GetUserId(),
+		Role:      item.GetRole(),
+		CreatedAt: toTime(item.GetCreatedAt()),
+	}
+}
+
+func fromProtoInvitation(item *ledgerv1.Invitation) *model.Invitation {
+	if item == nil {
+		return nil
+	}
+	return &model.Invitation{
+		ID:        item.GetId(),
+		AccountID: item.GetAccountId(),
+		Role:      item.GetRole(),
+		CreatedBy: item.GetCreatedBy(),
+		CreatedAt: toTime(item.GetCreatedAt()),
+		ExpiresAt: toTime(item.GetExpiresAt()),
+	}
+}
+
+func fromProtoTransactions(items []*ledgerv1.Transaction) []model.Transaction {
+	out := make([]model.Transaction, 0, len(items))
+	for _, item := range items {
+		if item == nil {
+			continue
+		}
+		if tx := fromProtoTransaction(item); tx != nil {
+			out = append(out, *tx)
+		}
+	}
+	return out
+}
+
+func fromProtoTransaction(item *ledgerv1.Transaction) *model.Transaction {
+	if item == nil {
+		return nil
+	}
+	return &model.Transaction{
+		ID:          item.GetId(),
+		AccountID:   item.GetAccountId(),
+		Amount:      item.GetAmount(),
+		Currency:    item.GetCurrency(),
+		Category:    item.GetCategory(),
+		Description: item.GetDescription(),
+		OccurredAt:  toTime(item.GetOccurredAt()),
+		CreatedAt:   toTime(item.GetCreatedAt()),
+		UpdatedAt:   toTime(item.GetUpdatedAt()),
+		DeletedAt:   toTimePtr(item.GetDeletedAt()),
+		CreatedBy:   item.GetCreatedBy(),
+		WalletID:    item.GetWalletId(),
+		TransferID:  item.GetTransferId(),
+		RefundOf:    item.GetRefundOf(),
+		Splits:      fromProtoSplits(item.GetSplits()),
+		Tags:        item.GetTags(),
+		PayeeID:     item.GetPayeeId(),
+	}
+}
+
+func toProtoSplits(items []model.TransactionSplit) []*ledgerv1.TransactionSplit {
+	if len(items) == 0 {
+		return nil
+	}
+	out := make([]*ledgerv1.TransactionSplit, 0, len(items))
+	for _, item := range items {
+		out = append(out, &ledgerv1.TransactionSplit{Category: item.Category, Amount: item.Amount})
+	}
+	return out
+}
+
+func fromProtoSplits(items []*ledgerv1.TransactionSplit) []model.TransactionSplit {
+	if len(items) == 0 {
+		return nil
+	}
+	out := make([]model.TransactionSplit, 0, len(items))
+	for _, item := range items {
+		out = append(out, model.TransactionSplit{Category: item.GetCategory(), Amount: item.GetAmount()})
+	}
+	return out
+}
+
+func fromProtoWallets(items []*ledgerv1.Wallet) []model.Wallet {
+	out := make([]model.Wallet, 0, len(items))
+	for _, item := range items {
+		if wallet := fromProtoWallet(item); wallet != nil {
+			out = append(out, *wallet)
+		}
+	}
+	return out
+}
+
+func fromProtoWallet(item *ledgerv1.Wallet) *model.Wallet {
+	if item == nil {
+		return nil
+	}
+	return &model.Wallet{
+		ID:        item.GetId(),
+		AccountID: item.GetAccountId(),
+		Name:      item.GetName(),
+		Type:      item.GetType(),
+		Currency:  item.GetCurrency(),
+		Balance:   item.GetBalance(),
+		CreatedAt: toTime(item.GetCreatedAt()),
+		UpdatedAt: toTime(item.GetUpdatedAt()),
+	}
+}
+
+func fromProtoTag(item *ledgerv1.Tag) *model.Tag {
+	if item == nil {
+		return nil
+	}
+	return &model.Tag{
+		ID:        item.GetId(),
+		AccountID: item.GetAccountId(),
+		Name:      item.GetName(),
+		CreatedAt: toTime(item.GetCreatedAt()),
+		UpdatedAt: toTime(item.GetUpdatedAt()),
+	}
+}
+
+func fromProtoAttachment(item *ledgerv1.Attachment) *model.Attachment {
+	if item == nil {
+		return nil
+	}
+	return &model.Attachment{
+		ID:            item.GetId(),
+		AccountID:     item.GetAccountId(),
+		TransactionID: item.GetTransactionId(),
+		FileName:      item.GetFileName(),
+		ContentType:   item.GetContentType(),
+		Size:          item.GetSize(),
+		Checksum:      item.GetChecksum(),
+		CreatedBy:     item.GetCreatedBy(),
+		CreatedAt:     toTime(item.GetCreatedAt()),
+	}
+}
+
+func toProtoRule(accountID, id string, req model.RuleRequest) *ledgerv1.Rule {
+	enabled := true
+	if req.Enabled != nil {
+		enabled = *req.Enabled
+	}
+	rule := &ledgerv1.Rule{
+		Id:                  id,
+		AccountId:           accountID,
+		Name:                req.Name,
+		Priority:            int32(req.Priority),
+		DescriptionContains: req.DescriptionContains,
+		DescriptionPattern:  req.DescriptionPattern,
+		Type:                req.Type,
+		Currency:            req.Currency,
+		Category:            req.Category,
+		Enabled:             enabled,
+	}
+	if req.MinAmount != nil {
+		rule.MinAmount = wrapperspb.Double(*req.MinAmount)
+	}
+	if req.MaxAmount != nil {
+		rule.MaxAmount = wrapperspb.Double(*req.MaxAmount)
+	}
+	return rule
+}
+
+func fromProtoRule(item *ledgerv1.Rule) *model.Rule {
+	if item == nil {
+		return nil
+	}
+	rule := &model.Rule{
+		ID:                  item.GetId(),
+		AccountID:           item.GetAccountId(),
+		Name:                item.GetName(),
+		Priority:            int(item.GetPriority()),
+		DescriptionContains: item.GetDescriptionContains(),
+		DescriptionPattern:  item.GetDescriptionPattern(),
+		Type:                item.GetType(),
+		Currency:            item.GetCurrency(),
+		Category:            item.GetCategory(),
+		Enabled:             item.GetEnabled(),
+		CreatedAt:           toTime(item.GetCreatedAt()),
+		UpdatedAt:           toTime(item.GetUpdatedAt()),
+	}
+	if item.GetMinAmount() != nil {
+		value := item.GetMinAmount().GetValue()
+		rule.MinAmount = &value
+	}
+	if item.GetMaxAmount() != nil {
+		value := item.GetMaxAmount().GetValue()
+		rule.MaxAmount = &value
+	}
+	return rule
+}
+
+func toProtoPayee(accountID, id string, req model.PayeeRequest) *ledgerv1.Payee {
+	return &ledgerv1.Payee{
+		Id:              id,
+		AccountId:       accountID,
+		Name:            req.Name,
+		Aliases:         req.Aliases,
+		DefaultCategory: req.DefaultCategory,
+	}
+}
+
+func fromProtoPayee(item *ledgerv1.Payee) *model.Payee {
+	if item == nil {
+		return nil
+	}
+	aliases := item.GetAliases()
+	if aliases == nil {
+		aliases = []string{}
+	}
+	return &model.Payee{
+		ID:              item.GetId(),
+		AccountID:       item.GetAccountId(),
+		Name:            item.GetName(),
+		Aliases:         aliases,
+		DefaultCategory: item.GetDefaultCategory(),
+		CreatedAt:       toTime(item.GetCreatedAt()),
+		UpdatedAt:       toTime(item.GetUpdatedAt()),
+	}
+}
+
+func toProtoGoal(accountID, id string, req model.GoalRequest) *ledgerv1.Goal {
+	return &ledgerv1.Goal{
+		Id:           id,
+		AccountId:    accountID,
+		Name:         req.Name,
+		TargetAmount: req.TargetAmount,
+		Currency:     req.Currency,
+		Deadline:     timestamppb.New(req.Deadline),
+		Category:     req.Category,
+		WalletId:     req.WalletID,
+		Tag:          req.Tag,
+	}
+}
+
+func fromProtoGoal(item *ledgerv1.Goal) *model.Goal {
+	if item == nil {
+		return nil
+	}
+	return &model.Goal{
+		ID:           item.GetId(),
+		AccountID:    item.GetAccountId(),
+		Name:         item.GetName(),
+		TargetAmount: item.GetTargetAmount(),
+		Currency:     item.GetCurrency(),
+		Deadline:     toTime(item.GetDeadline()),
+		Category:     item.GetCategory(),
+		WalletID:     item.GetWalletId(),
+		Tag:          item.GetTag(),
+		Progress:     fromProtoGoalProgress(item.GetProgress()),
+		CreatedAt:    toTime(item.GetCreatedAt()),
+		UpdatedAt:    toTime(item.GetUpdatedAt()),
+	}
+}
+
+func fromProtoGoalProgress(item *ledgerv1.GoalProgress) model.GoalProgress {
+	return model.GoalProgress{
+		Saved:           item.GetSaved(),
+		Remaining:       item.GetRemaining(),
+		Percent:         item.GetPercent(),
+		MonthsLeft:      int(item.GetMonthsLeft()),
+		MonthlyRequired: item.GetMonthlyRequired(),
+	}
+}
+
+func fromProtoRuleMatches(items []*ledgerv1.RuleMatch) []model.RuleMatch {
+	matches := make([]model.RuleMatch, 0, len(items))
+	for _, item := range items {
+		match := model.RuleMatch{RuleID: item.GetRuleId(), Category: item.GetCategory()}
+		if tx := fromProtoTransaction(item.GetTransaction()); tx != nil {
+			match.Transaction = *tx
+		}
+		matches = append(matches, match)
+	}
+	return matches
+}
+
+func fromProtoTransfer(item *ledgerv1.Transfer) *model.Transfer {
+	if item == nil {
+		return nil
+	}
+	return &model.Transfer{
+		ID:           item.GetId(),
+		AccountID:    item.GetAccountId(),
+		FromWalletID: item.GetFromWalletId(),
+		ToWalletID:   item.GetToWalletId(),
+		Amount:       item.GetAmount(),
+		ToAmount:     item.GetToAmount(),
+		Description:  item.GetDescription(),
+		OccurredAt:   toTime(item.GetOccurredAt()),
+		CreatedBy:    item.GetCreatedBy(),
+	}
+}
+
+func fromProtoBudgets(items []*ledgerv1.Budget) []model.Budget {
+	out := make([]model.Budget, 0, len(items))
+	for _, item := range items {
+		if item == nil {
+			continue
+		}
+		out = append(out, model.Budget{
+			ID:        item.GetId(),
+			AccountID: item.GetAccountId(),
+			Name:      item.GetName(),
+			Amount:    item.GetAmount(),
+			Currency:  item.GetCurrency(),
+			Period:    item.GetPeriod(),
+			Month:     toTime(item.GetMonth()),
+			CreatedAt: toTime(item.GetCreatedAt()),
+			UpdatedAt: toTime(item.GetUpdatedAt()),
+			DeletedAt: toTimePtr(item.GetDeletedAt()),
+		})
+	}
+	return out
+}
+
+func fromProtoBudget(item *ledgerv1.Budget) *model.Budget {
+	if item == nil {
+		return nil
+	}
+	return &model.Budget{
+		ID:        item.GetId(),
+		AccountID: item.GetAccountId(),
+		Name:      item.GetName(),
+		Amount:    item.GetAmount(),
+		Currency:  item.GetCurrency(),
+		Period:    item.GetPeriod(),
+		Month:     toTime(item.GetMonth()),
+		CreatedAt: toTime(item.GetCreatedAt()),
+		UpdatedAt: toTime(item.GetUpdatedAt()),
+		DeletedAt: toTimePtr(item.GetDeletedAt()),
+	}
+}
+
+func fromProtoReports(items []*ledgerv1.Report) []model.Report {
+	out := make([]model.Report, 0, len(items))
+	for _, item := range items {
+		if item == nil {
+			continue
+		}
+		categories := fromProtoReportCategories(item.GetCategories())
+		out = append(out, model.Report{
+			ID:           item.GetId(),
+			AccountID:    item.GetAccountId(),
+			Name:         item.GetName(),
+			Period:       item.GetPeriod(),
+			GeneratedAt:  toTime(item.GetGeneratedAt()),
+			TotalIncome:  item.GetTotalIncome(),
+			TotalExpense: item.GetTotalExpense(),
+			Currency:     item.GetCurrency(),
+			Categories:   categories,
+			Tags:         fromProtoReportTags(item.GetTags()),
+			Payees:       fromProtoReportPayees(item.GetPayees()),
+			Goals:        fromProtoReportGoals(item.GetGoals()),
+			DeletedAt:    toTimePtr(item.GetDeletedAt()),
+		})
+	}
+	return out
+}
+
+func fromProtoReport(item *ledgerv1.Report) *model.Report {
+	if item == nil {
+		return nil
+	}
+	return &model.Report{
+		ID:           item.GetId(),
+		AccountID:    item.GetAccountId(),
+		Name:         item.GetName(),
+		Period:       item.GetPeriod(),
+		GeneratedAt:  toTime(item.GetGeneratedAt()),
+		TotalIncome:  item.GetTotalIncome(),
+		TotalExpense: item.GetTotalExpense(),
+		Currency:     item.GetCurrency(),
+		Categories:   fromProtoReportCategories(item.GetCategories()),
+		Tags:         fromProtoReportTags(item.GetTags()),
+		Payees:       fromProtoReportPayees(item.GetPayees()),
+		Goals:        fromProtoReportGoals(item.GetGoals()),
+		DeletedAt:    toTimePtr(item.GetDeletedAt()),
+	}
+}
+
+func fromProtoReportCategories(items []*ledgerv1.ReportCategory) []model.ReportCategory {
+	if len(items) == 0 {
+		return nil
+	}
+	out := make([]model.ReportCategory, 0, len(items))
+	for _, item := range items {
+		if item == nil {
+			continue
+		}
+		var usagePercent *float64
+		if item.GetBudgetUsagePercent() != nil {
+			value := item.GetBudgetUsagePercent().GetValue()
+			usagePercent = &value
+		}
+		out = append(out, model.ReportCategory{
+			Category:           item.GetCategory(),
+			TotalExpense:       item.GetTotalExpense(),
+			BudgetAmount:       item.GetBudgetAmount(),
+			BudgetUsagePercent: usagePercent,
+		})
+	}
+	return out
+}
+
+func fromProtoReportTags(items []*ledgerv1.ReportTag) []model.ReportTag {
+	if len(items) == 0 {
+		return nil
+	}
+	out := make([]model.ReportTag, 0, len(items))
+	for _, item := range items {
+		if item == nil {
+			continue
+		}
+		out = append(out, model.ReportTag{
+			Tag:          item.GetTag(),
+			TotalIncome:  item.GetTotalIncome(),
+			TotalExpense: item.GetTotalExpense(),
+		})
+	}
+	return out
+}
+
+func fromProtoReportPayees(items []*ledgerv1.ReportPayee) []model.ReportPayee {
+	if len(items) == 0 {
+		return nil
+	}
+	out := make([]model.ReportPayee, 0, len(items))
+	for _, item := range items {
+		if item == nil {
+			continue
+		}
+		out = append(out, model.ReportPayee{
+			PayeeID:      item.GetPayeeId(),
+			Name:         item.GetName(),
+			TotalExpense: item.GetTotalExpense(),
+			Transactions: int(item.GetTransactions()),
+		})
+	}
+	return out
+}
+
+func fromProtoReportGoals(items []*ledgerv1.ReportGoal) []model.ReportGoal {
+	if len(items) == 0 {
+		return nil
+	}
+	out := make([]model.ReportGoal, 0, len(items))
+	for _, item := range items {
+		if item == nil {
+			continue
+		}
+		out = append(out, model.ReportGoal{
+			GoalID:       item.GetGoalId(),
+			Name:         item.GetName(),
+			TargetAmount: item.GetTargetAmount(),
+			Currency:     item.GetCurrency(),
+			Deadline:     toTime(item.GetDeadline()),
+			Progress:     fromProtoGoalProgress(item.GetProgress()),
+		})
+	}
+	return out
+}
+
+func fromProtoBatchResponse(resp *ledgerv1.BatchTransactionsResponse) *model.BatchTransactionsResponse {
+	out := &model.BatchTransactionsResponse{
+		Results:   make([]model.BatchItemResult, 0, len(resp.GetResults())),
+		Succeeded: int(resp.GetSucceeded()),
+		Failed:    int(resp.GetFailed()),
+	}
+	for _, item := range resp.GetResults() {
+		out.Results = append(out.Results, model.BatchItemResult{
+			Index:       int(item.GetIndex()),
+			Transaction: fromProtoTransaction(item.GetTransaction()),
+			Code:        item.GetCode(),
+			Error:       item.GetError(),
+		})
+	}
+	return out
+}
+
+func fromProtoAuditEntries(items []*ledgerv1.AuditEntry) []model.AuditEntry {
+	out := make([]model.AuditEntry, 0, len(items))
+	for _, item := range items {
+		if item == nil {
+			continue
+		}
+		out = append(out, model.AuditEntry{
+			ID:         item.GetId(),
+			AccountID:  item.GetAccountId(),
+			ActorID:    item.GetActorId(),
+			EntityType: item.GetEntityType(),
+			EntityID:   item.GetEntityId(),
+			Operation:  item.GetOperation(),
+			Before:     rawJSON(item.GetBefore()),
+			After:      rawJSON(item.GetAfter()),
+			CreatedAt:  toTime(item.GetCreatedAt()),
+		})
+	}
+	return out
+}
+
+// rawJSON keeps an empty snapshot as JSON null.
+func rawJSON(data []byte) json.RawMessage {
+	if len(data) == 0 {
+		return nil
+	}
+	return json.RawMessage(data)
+}
+
+func toTime(ts *timestamppb.Timestamp) time.Time {
+	if ts == nil {
+		return time.Time{}
+	}
+	return ts.AsTime()
+}
+
+func toTimePtr(ts *timestamppb.Timestamp) *time.Time {
+	if ts == nil {
+		return nil
+	}
+	value := ts.AsTime()
+	return &value
 }
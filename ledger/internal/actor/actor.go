@@ -0,0 +1,67 @@
+// Package actor carries the identity of the user performing a ledger operation.
+package actor
+
+import (
+	"context"
+	"slices"
+)
+
+// MetadataKey is the gRPC metadata key the gateway uses to pass the JWT subject.
+const MetadataKey = "x-user-id"
+
+// RolesMetadataKey carries the roles of the JWT, one metadata value per role.
+const RolesMetadataKey = "x-user-roles"
+
+// ServiceTokenMetadataKey carries the secret shared by the internal services.
+// Calls without it are rejected, so only the gateway and other services can
+// name a user.
+const ServiceTokenMetadataKey = "x-service-token"
+
+// Roles that grant read access to other accounts.
+const (
+	RoleSupport = "support"
+	RoleAdmin   = "admin"
+)
+
+type contextKey struct{}
+
+type rolesContextKey struct{}
+
+type internalContextKey struct{}
+
+func WithUserID(ctx context.Context, userID string) context.Context {
+	return context.WithValue(ctx, contextKey{}, userID)
+}
+
+func UserIDFromContext(ctx context.Context) string {
+	userID, _ := ctx.Value(contextKey{}).(string)
+	return userID
+}
+
+func WithRoles(ctx context.Context, roles []string) context.Context {
+	return context.WithValue(ctx, rolesContextKey{}, roles)
+}
+
+func RolesFromContext(ctx context.Context) []string {
+	roles, _ := ctx.Value(rolesContextKey{}).([]string)
+	return roles
+}
+
+// WithInternal marks the call as made by an internal service on its own
+// behalf rather than for a user.
+func WithInternal(ctx context.Context) context.Context {
+	return context.WithValue(ctx, internalContextKey{}, true)
+}
+
+// IsInternal reports whether an internal service makes the call on its own
+// behalf. A call for a user is never internal.
+func IsInternal(ctx context.Context) bool {
+	internal, _ := ctx.Value(internalContextKey{}).(bool)
+	return internal && UserIDFromContext(ctx) == ""
+}
+
+// IsStaff reports whether the actor may read the ledgers of other users.
+func IsStaff(ctx context.Context) bool {
+	roles := RolesFromContext(ctx)
+	return slices.Contains(roles, RoleSupport) || slices.Contains(roles, RoleAdmin)
+}
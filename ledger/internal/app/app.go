@@ -3,6 +3,7 @@ package app
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log"
 	"net"
 	"net/http"
@@ -14,12 +15,15 @@ import (
 	"golang.org/x/sync/errgroup"
 	"google.golang.org/grpc"
 
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/blob"
 	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/cache"
 	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/config"
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/events"
 	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/grpcserver"
 	httpHandler "github.com/Deevins/final-task-course-2-go-lang/ledger/internal/handler/http"
 	pb "github.com/Deevins/final-task-course-2-go-lang/ledger/internal/pb/ledger/v1"
 	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/repository"
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/retention"
 	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/router"
 	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/service"
 	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/storage"
@@ -31,12 +35,17 @@ type App struct {
 	grpcListener    net.Listener
 	db              *pgxpool.Pool
 	redisClient     *redis.Client
+	outboxRelay     *events.Relay
+	trashPurger     *retention.Purger
 	shutdownTimeout time.Duration
 	httpPort        string
 	grpcPort        string
 }
 
 func New(ctx context.Context, cfg config.Config) (*App, error) {
+	if cfg.ServiceToken == "" {
+		return nil, errors.New("SERVICE_TOKEN is required")
+	}
 	engine := gin.Default()
 
 	db, err := storage.NewPostgresPool(ctx, cfg.PostgresDSN)
@@ -51,12 +60,22 @@ func New(ctx context.Context, cfg config.Config) (*App, error) {
 	}
 	log.Printf("connected to redis at %s", cfg.RedisAddr)
 
+	blobs, err := newBlobStore(cfg.Attachments)
+	if err != nil {
+		redisClient.Close()
+		db.Close()
+		return nil, err
+	}
+
 	repo := repository.NewPostgresLedgerRepository(db)
 	reportCache := storage.NewReportCache(redisClient, 5*time.Minute)
 	summaryCache := cache.NewReportSummaryCache(redisClient, 30*time.Second)
 	budgetCache := cache.NewBudgetListCache(redisClient, 20*time.Second)
-	ledgerService := service.NewLedgerService(repo, reportCache, summaryCache, budgetCache)
+	ledgerService := service.NewLedgerService(repo, reportCache, summaryCache, budgetCache, blobs)
 	validatedService := service.NewValidationService(ledgerService)
+	eventSink := events.NewRedisStreamSink(redisClient, cfg.Events.Stream, int64(cfg.Events.StreamMaxLen))
+	outboxRelay := events.NewRelay(repo, eventSink, cfg.Events.OutboxPollInterval)
+	trashPurger := retention.NewPurger(ledgerService, cfg.Trash.Retention, cfg.Trash.PurgeInterval)
 
 	healthHandler := httpHandler.NewHealthHandler()
 	router.Register(engine, healthHandler)
@@ -73,7 +92,10 @@ func New(ctx context.Context, cfg config.Config) (*App, error) {
 		return nil, err
 	}
 
-	grpcSrv := grpc.NewServer()
+	grpcSrv := grpc.NewServer(
+		grpc.UnaryInterceptor(grpcserver.ActorUnaryInterceptor(cfg.ServiceToken)),
+		grpc.StreamInterceptor(grpcserver.ActorStreamInterceptor(cfg.ServiceToken)),
+	)
 	pb.RegisterLedgerServiceServer(grpcSrv, grpcserver.NewLedgerServer(validatedService))
 
 	return &App{
@@ -82,12 +104,33 @@ func New(ctx context.Context, cfg config.Config) (*App, error) {
 		grpcListener:    lis,
 		db:              db,
 		redisClient:     redisClient,
+		outboxRelay:     outboxRelay,
+		trashPurger:     trashPurger,
 		shutdownTimeout: 5 * time.Second,
 		httpPort:        cfg.HTTPPort,
 		grpcPort:        cfg.GRPCPort,
 	}, nil
 }
 
+func newBlobStore(cfg config.AttachmentsConfig) (blob.Store, error) {
+	switch cfg.Backend {
+	case "fs":
+		log.Printf("storing attachments in %s", cfg.Dir)
+		return blob.NewFileStore(cfg.Dir)
+	case "s3":
+		log.Printf("storing attachments in s3 bucket %s at %s", cfg.S3Bucket, cfg.S3Endpoint)
+		return blob.NewS3Store(blob.S3Config{
+			Endpoint:  cfg.S3Endpoint,
+			Region:    cfg.S3Region,
+			Bucket:    cfg.S3Bucket,
+			AccessKey: cfg.S3AccessKey,
+			SecretKey: cfg.S3SecretKey,
+		})
+	default:
+		return nil, fmt.Errorf("unknown attachments backend %q", cfg.Backend)
+	}
+}
+
 func (a *App) Run(ctx context.Context) error {
 	g, gctx := errgroup.WithContext(ctx)
 
@@ -107,6 +150,16 @@ func (a *App) Run(ctx context.Context) error {
 		return nil
 	})
 
+	g.Go(func() error {
+		log.Printf("outbox relay started")
+		return a.outboxRelay.Run(gctx)
+	})
+
+	g.Go(func() error {
+		log.Printf("trash purger started")
+		return a.trashPurger.Run(gctx)
+	})
+
 	g.Go(func() error {
 		<-gctx.Done()
 		shutdownCtx, cancel := context.WithTimeout(ctx, a.shutdownTimeout)
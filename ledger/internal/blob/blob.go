@@ -0,0 +1,36 @@
+// Package blob stores the content of attachments outside of the database.
+package blob
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+)
+
+// ErrNotFound is returned when no object is stored under the key.
+var ErrNotFound = errors.New("blob not found")
+
+// Store keeps objects under slash-separated keys.
+type Store interface {
+	// Put stores the content under the key, replacing an existing object.
+	Put(ctx context.Context, key string, content io.Reader) error
+	// Open returns the content of the object. The caller closes it.
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes the object. Deleting a missing object is not an error.
+	Delete(ctx context.Context, key string) error
+}
+
+// validKey reports whether the key is a relative slash-separated path without
+// empty, "." or ".." segments, so that it cannot escape the store.
+func validKey(key string) bool {
+	if key == "" {
+		return false
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "" || segment == "." || segment == ".." || strings.ContainsRune(segment, '\\') {
+			return false
+		}
+	}
+	return true
+}
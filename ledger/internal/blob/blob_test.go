@@ -0,0 +1,120 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestStores(t *testing.T) {
+	tests := []struct {
+		name  string
+		store func(t *testing.T) Store
+	}{
+		{
+			name: "file",
+			store: func(t *testing.T) Store {
+				store, err := NewFileStore(t.TempDir())
+				if err != nil {
+					t.Fatalf("new file store: %v", err)
+				}
+				return store
+			},
+		},
+		{
+			name: "s3",
+			store: func(t *testing.T) Store {
+				server := httptest.NewServer(newFakeS3(t))
+				t.Cleanup(server.Close)
+				store, err := NewS3Store(S3Config{Endpoint: server.URL, Bucket: "attachments", AccessKey: "key", SecretKey: "secret"})
+				if err != nil {
+					t.Fatalf("new s3 store: %v", err)
+				}
+				return store
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			store := tt.store(t)
+
+			if err := store.Put(ctx, "account/receipt", strings.NewReader("first")); err != nil {
+				t.Fatalf("put: %v", err)
+			}
+			if err := store.Put(ctx, "account/receipt", strings.NewReader("second")); err != nil {
+				t.Fatalf("overwrite: %v", err)
+			}
+			content, err := store.Open(ctx, "account/receipt")
+			if err != nil {
+				t.Fatalf("open: %v", err)
+			}
+			data, err := io.ReadAll(content)
+			content.Close()
+			if err != nil || string(data) != "second" {
+				t.Fatalf("expected the latest content, got %q, %v", data, err)
+			}
+
+			if err := store.Delete(ctx, "account/receipt"); err != nil {
+				t.Fatalf("delete: %v", err)
+			}
+			if err := store.Delete(ctx, "account/receipt"); err != nil {
+				t.Fatalf("expected deleting a missing object to succeed, got %v", err)
+			}
+			if _, err := store.Open(ctx, "account/receipt"); !errors.Is(err, ErrNotFound) {
+				t.Fatalf("expected ErrNotFound, got %v", err)
+			}
+
+			for _, key := range []string{"", "../escape", "account//receipt", "account/./receipt"} {
+				if err := store.Put(ctx, key, strings.NewReader("x")); err == nil {
+					t.Fatalf("expected key %q to be rejected", key)
+				}
+			}
+		})
+	}
+}
+
+// newFakeS3 serves objects from memory and rejects requests that are not
+// signed for the expected credentials and bucket.
+func newFakeS3(t *testing.T) http.Handler {
+	var mu sync.Mutex
+	objects := map[string][]byte{}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth := r.Header.Get("Authorization")
+		if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=key/") ||
+			!strings.Contains(auth, "/us-east-1/s3/aws4_request") ||
+			r.Header.Get("X-Amz-Date") == "" || r.Header.Get("X-Amz-Content-Sha256") == "" {
+			t.Errorf("unsigned request: %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		key, ok := strings.CutPrefix(r.URL.Path, "/attachments/")
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.Method {
+		case http.MethodPut:
+			data, _ := io.ReadAll(r.Body)
+			objects[key] = data
+		case http.MethodGet:
+			data, ok := objects[key]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Write(data)
+		case http.MethodDelete:
+			delete(objects, key)
+			w.WriteHeader(http.StatusNoContent)
+		}
+	})
+}
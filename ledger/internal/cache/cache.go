@@ -12,7 +12,9 @@ import (
 	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/model"
 )
 
-const budgetListCacheKey = "budgets:all"
+// budgetListCacheKey carries the version of the JSON encoding of budgets so
+// entries written with an earlier encoding are not decoded.
+const budgetListCacheKey = "budgets:v2:all"
 
 var ErrNotFound = errors.New("cache: not found")
 
@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"strconv"
+	"time"
 )
 
 type Config struct {
@@ -12,6 +13,35 @@ type Config struct {
 	RedisAddr   string
 	RedisPass   string
 	RedisDB     int
+	Events      EventsConfig
+	Trash       TrashConfig
+	Attachments AttachmentsConfig
+	// ServiceToken is the secret shared by the internal services. Every gRPC
+	// call has to carry it.
+	ServiceToken string
+}
+
+type EventsConfig struct {
+	Stream             string
+	StreamMaxLen       int
+	OutboxPollInterval time.Duration
+}
+
+type TrashConfig struct {
+	Retention     time.Duration
+	PurgeInterval time.Duration
+}
+
+// AttachmentsConfig selects where the content of attachments is kept: "fs"
+// stores it below Dir, "s3" in a bucket of an S3-compatible service.
+type AttachmentsConfig struct {
+	Backend     string
+	Dir         string
+	S3Endpoint  string
+	S3Region    string
+	S3Bucket    string
+	S3AccessKey string
+	S3SecretKey string
 }
 
 func Load() Config {
@@ -22,6 +52,25 @@ func Load() Config {
 		RedisAddr:   getEnv("REDIS_ADDR", "localhost:6379"),
 		RedisPass:   getEnv("REDIS_PASSWORD", ""),
 		RedisDB:     getEnvInt("REDIS_DB", 0),
+		Events: EventsConfig{
+			Stream:             getEnv("EVENTS_STREAM", "ledger:events"),
+			StreamMaxLen:       getEnvInt("EVENTS_STREAM_MAX_LEN", 100000),
+			OutboxPollInterval: getEnvDuration("OUTBOX_POLL_INTERVAL", time.Second),
+		},
+		Trash: TrashConfig{
+			Retention:     getEnvDuration("TRASH_RETENTION", 30*24*time.Hour),
+			PurgeInterval: getEnvDuration("TRASH_PURGE_INTERVAL", time.Hour),
+		},
+		Attachments: AttachmentsConfig{
+			Backend:     getEnv("ATTACHMENTS_BACKEND", "fs"),
+			Dir:         getEnv("ATTACHMENTS_DIR", "data/attachments"),
+			S3Endpoint:  getEnv("ATTACHMENTS_S3_ENDPOINT", ""),
+			S3Region:    getEnv("ATTACHMENTS_S3_REGION", "us-east-1"),
+			S3Bucket:    getEnv("ATTACHMENTS_S3_BUCKET", ""),
+			S3AccessKey: getEnv("ATTACHMENTS_S3_ACCESS_KEY", ""),
+			S3SecretKey: getEnv("ATTACHMENTS_S3_SECRET_KEY", ""),
+		},
+		ServiceToken: getEnv("SERVICE_TOKEN", ""),
 	}
 }
 
@@ -43,3 +92,15 @@ func getEnvInt(key string, fallback int) int {
 	}
 	return parsed
 }
+
+func getEnvDuration(key string, fallback time.Duration) time.Duration {
+	value, ok := os.LookupEnv(key)
+	if !ok || value == "" {
+		return fallback
+	}
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		return fallback
+	}
+	return parsed
+}
@@ -0,0 +1,46 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/model"
+)
+
+// RedisStreamSink appends events to a Redis stream with XADD.
+type RedisStreamSink struct {
+	client *redis.Client
+	stream string
+	maxLen int64
+}
+
+func NewRedisStreamSink(client *redis.Client, stream string, maxLen int64) *RedisStreamSink {
+	return &RedisStreamSink{client: client, stream: stream, maxLen: maxLen}
+}
+
+func (s *RedisStreamSink) Publish(ctx context.Context, event model.Event) error {
+	args := &redis.XAddArgs{
+		Stream: s.stream,
+		Values: map[string]any{
+			"offset":       strconv.FormatInt(event.Offset, 10),
+			"id":           event.ID,
+			"account_id":   event.AccountID,
+			"type":         event.Type,
+			"aggregate_id": event.AggregateID,
+			"payload":      string(event.Payload),
+			"occurred_at":  event.OccurredAt.Format(time.RFC3339Nano),
+		},
+	}
+	if s.maxLen > 0 {
+		args.MaxLen = s.maxLen
+		args.Approx = true
+	}
+	if err := s.client.XAdd(ctx, args).Err(); err != nil {
+		return fmt.Errorf("xadd %s: %w", s.stream, err)
+	}
+	return nil
+}
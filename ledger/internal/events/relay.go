@@ -0,0 +1,85 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/model"
+)
+
+const defaultRelayBatchSize = 100
+
+// Sink receives outbox events. Implementations must tolerate redelivery:
+// an event is published at least once.
+type Sink interface {
+	Publish(ctx context.Context, event model.Event) error
+}
+
+type OutboxStore interface {
+	ListUnpublishedEvents(ctx context.Context, limit int) ([]model.Event, error)
+	MarkEventsPublished(ctx context.Context, offsets []int64) error
+}
+
+// Relay moves events from the outbox table to a sink.
+type Relay struct {
+	store     OutboxStore
+	sink      Sink
+	interval  time.Duration
+	batchSize int
+}
+
+func NewRelay(store OutboxStore, sink Sink, interval time.Duration) *Relay {
+	if interval <= 0 {
+		interval = time.Second
+	}
+	return &Relay{store: store, sink: sink, interval: interval, batchSize: defaultRelayBatchSize}
+}
+
+func (r *Relay) Run(ctx context.Context) error {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		for {
+			published, err := r.PublishPending(ctx)
+			if err != nil {
+				log.Printf("outbox relay: %v", err)
+				break
+			}
+			if published < r.batchSize {
+				break
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// PublishPending publishes one batch of unpublished events in offset order and
+// returns how many were delivered. It stops at the first failed publish so
+// ordering is preserved for the next attempt.
+func (r *Relay) PublishPending(ctx context.Context) (int, error) {
+	pending, err := r.store.ListUnpublishedEvents(ctx, r.batchSize)
+	if err != nil {
+		return 0, fmt.Errorf("list unpublished events: %w", err)
+	}
+	offsets := make([]int64, 0, len(pending))
+	var publishErr error
+	for _, event := range pending {
+		if err := r.sink.Publish(ctx, event); err != nil {
+			publishErr = fmt.Errorf("publish event %d: %w", event.Offset, err)
+			break
+		}
+		offsets = append(offsets, event.Offset)
+	}
+	if len(offsets) > 0 {
+		if err := r.store.MarkEventsPublished(ctx, offsets); err != nil {
+			return 0, fmt.Errorf("mark events published: %w", err)
+		}
+	}
+	return len(offsets), publishErr
+}
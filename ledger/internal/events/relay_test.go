@@ -0,0 +1,64 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/model"
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/repository"
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/storage"
+)
+
+type recordingSink struct {
+	published []model.Event
+	failOn    int64
+}
+
+func (s *recordingSink) Publish(ctx context.Context, event model.Event) error {
+	if event.Offset == s.failOn {
+		return errors.New("sink unavailable")
+	}
+	s.published = append(s.published, event)
+	return nil
+}
+
+func TestRelayPublishesInOrderAndRetriesFailures(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewInMemoryLedgerRepository(storage.NewInMemoryLedgerStorage())
+	for _, id := range []string{"e1", "e2", "e3"} {
+		if err := repo.AppendEvents(ctx, model.Event{ID: id, AccountID: "account-1", Type: model.EventTransactionCreated}); err != nil {
+			t.Fatalf("append event: %v", err)
+		}
+	}
+
+	sink := &recordingSink{failOn: 2}
+	relay := NewRelay(repo, sink, 0)
+
+	published, err := relay.PublishPending(ctx)
+	if err == nil {
+		t.Fatal("expected publish error")
+	}
+	if published != 1 {
+		t.Fatalf("expected 1 published event before failure, got %d", published)
+	}
+
+	sink.failOn = 0
+	published, err = relay.PublishPending(ctx)
+	if err != nil {
+		t.Fatalf("publish pending: %v", err)
+	}
+	if published != 2 {
+		t.Fatalf("expected 2 events on retry, got %d", published)
+	}
+	for i, event := range sink.published {
+		if event.Offset != int64(i+1) {
+			t.Fatalf("expected offset %d at position %d, got %d", i+1, i, event.Offset)
+		}
+	}
+
+	published, err = relay.PublishPending(ctx)
+	if err != nil || published != 0 {
+		t.Fatalf("expected nothing left to publish, got %d (%v)", published, err)
+	}
+}
@@ -0,0 +1,70 @@
+package grpcserver
+
+import (
+	"context"
+	"crypto/subtle"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/actor"
+)
+
+// ActorUnaryInterceptor authenticates the calling service by the service token
+// and copies the caller's user ID and roles from incoming metadata into the
+// request context. Calls carrying the token but no user come from internal
+// clients acting on their own behalf.
+func ActorUnaryInterceptor(serviceToken string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := withActor(ctx, serviceToken)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+func ActorStreamInterceptor(serviceToken string) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := withActor(ss.Context(), serviceToken)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &actorServerStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+type actorServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *actorServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func withActor(ctx context.Context, serviceToken string) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if !hasServiceToken(md, serviceToken) {
+		return nil, status.Error(codes.Unauthenticated, "service token is required")
+	}
+	values := md.Get(actor.MetadataKey)
+	if len(values) == 0 || values[0] == "" {
+		return actor.WithInternal(ctx), nil
+	}
+	ctx = actor.WithUserID(ctx, values[0])
+	if roles := md.Get(actor.RolesMetadataKey); len(roles) > 0 {
+		ctx = actor.WithRoles(ctx, roles)
+	}
+	return ctx, nil
+}
+
+func hasServiceToken(md metadata.MD, serviceToken string) bool {
+	if serviceToken == "" {
+		return false
+	}
+	values := md.Get(actor.ServiceTokenMetadataKey)
+	return len(values) == 1 && subtle.ConstantTimeCompare([]byte(values[0]), []byte(serviceToken)) == 1
+}
@@ -2,6 +2,8 @@ package grpcserver
 
 import (
 	"context"
+	"errors"
+	"io"
 	"time"
 
 	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/model"
@@ -14,6 +16,13 @@ import (
 	"google.golang.org/protobuf/types/known/wrapperspb"
 )
 
+const (
+	watchEventsPollInterval = time.Second
+	watchEventsBatchSize    = 100
+	// attachmentChunkSize is the size of the content chunks of a download.
+	attachmentChunkSize = 64 << 10
+)
+
 type LedgerServer struct {
 	pb.UnimplementedLedgerServiceServer
 	ledgerService service.LedgerService
@@ -29,6 +38,9 @@ func (s *LedgerServer) CreateTransaction(ctx context.Context, req *pb.CreateTran
 	if req.GetTransaction() == nil {
 		return nil, status.Error(codes.InvalidArgument, "transaction is required")
 	}
+	if err := s.authorizeWrite(ctx, req.GetTransaction().GetAccountId()); err != nil {
+		return nil, err
+	}
 
 	created, err := s.ledgerService.CreateTransaction(ctx, toModelTransaction(req.GetTransaction()))
 	if err != nil {
@@ -59,6 +71,14 @@ func (s *LedgerServer) GetTransaction(ctx context.Context, req *pb.GetTransactio
 		}
 		return nil, status.Errorf(codes.Internal, "get transaction: %v", err)
 	}
+	// Transactions of other accounts are reported as missing, so that their
+	// IDs cannot be probed.
+	if err := s.ledgerService.AuthorizeRead(ctx, transaction.AccountID, "transaction"); err != nil {
+		if service.IsAccessDenied(err) {
+			return nil, status.Error(codes.NotFound, "transaction not found")
+		}
+		return nil, status.Errorf(codes.Internal, "authorize read: %v", err)
+	}
 
 	return &pb.TransactionResponse{Transaction: toProtoTransaction(transaction)}, nil
 }
@@ -67,6 +87,12 @@ func (s *LedgerServer) UpdateTransaction(ctx context.Context, req *pb.UpdateTran
 	if req.GetTransaction() == nil {
 		return nil, status.Error(codes.InvalidArgument, "transaction is required")
 	}
+	if err := s.authorizeTransactionWrite(ctx, req.GetTransaction().GetId()); err != nil {
+		return nil, err
+	}
+	if err := s.authorizeWrite(ctx, req.GetTransaction().GetAccountId()); err != nil {
+		return nil, err
+	}
 
 	updated, err := s.ledgerService.UpdateTransaction(ctx, toModelTransaction(req.GetTransaction()))
 	if err != nil {
@@ -76,6 +102,9 @@ func (s *LedgerServer) UpdateTransaction(ctx context.Context, req *pb.UpdateTran
 		if service.IsValidationError(err) {
 			return nil, status.Errorf(codes.InvalidArgument, "update transaction: %v", err)
 		}
+		if service.IsTransferLeg(err) || service.IsRefundLinked(err) || service.IsRefundExceeded(err) {
+			return nil, status.Errorf(codes.FailedPrecondition, "update transaction: %v", err)
+		}
 		return nil, status.Errorf(codes.Internal, "update transaction: %v", err)
 	}
 
@@ -87,6 +116,13 @@ func (s *LedgerServer) DeleteTransaction(ctx context.Context, req *pb.DeleteTran
 		return nil, status.Error(codes.InvalidArgument, "id is required")
 	}
 
+	if err := s.authorizeTransactionWrite(ctx, req.GetId()); err != nil {
+		if status.Code(err) == codes.NotFound {
+			return &pb.DeleteResponse{Deleted: false}, nil
+		}
+		return nil, err
+	}
+
 	if err := s.ledgerService.DeleteTransaction(ctx, req.GetId()); err != nil {
 		if service.IsNotFound(err) {
 			return &pb.DeleteResponse{Deleted: false}, nil
@@ -94,6 +130,9 @@ func (s *LedgerServer) DeleteTransaction(ctx context.Context, req *pb.DeleteTran
 		if service.IsValidationError(err) {
 			return nil, status.Errorf(codes.InvalidArgument, "delete transaction: %v", err)
 		}
+		if service.IsTransferLeg(err) || service.IsRefundLinked(err) || service.IsRefundExceeded(err) {
+			return nil, status.Errorf(codes.FailedPrecondition, "delete transaction: %v", err)
+		}
 		return nil, status.Errorf(codes.Internal, "delete transaction: %v", err)
 	}
 
@@ -101,6 +140,9 @@ func (s *LedgerServer) DeleteTransaction(ctx context.Context, req *pb.DeleteTran
 }
 
 func (s *LedgerServer) ListTransactions(ctx context.Context, req *pb.ListTransactionsRequest) (*pb.ListTransactionsResponse, error) {
+	if err := s.authorizeRead(ctx, req.GetAccountId(), "transactions"); err != nil {
+		return nil, err
+	}
 	items := s.ledgerService.ListTransactions(ctx, req.GetAccountId())
 	resp := &pb.ListTransactionsResponse{}
 	resp.Transactions = make([]*pb.Transaction, 0, len(items))
@@ -110,10 +152,178 @@ func (s *LedgerServer) ListTransactions(ctx context.Context, req *pb.ListTransac
 	return resp, nil
 }
 
+func (s *LedgerServer) SearchTransactions(ctx context.Context, req *pb.SearchTransactionsRequest) (*pb.ListTransactionsResponse, error) {
+	if req.GetAccountId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "account_id is required")
+	}
+	if err := s.authorizeRead(ctx, req.GetAccountId(), "transactions"); err != nil {
+		return nil, err
+	}
+
+	filter := model.TransactionFilter{
+		AccountID: req.GetAccountId(),
+		From:      toTime(req.GetFrom()),
+		To:        toTime(req.GetTo()),
+		Category:  req.GetCategory(),
+		Currency:  req.GetCurrency(),
+		WalletID:  req.GetWalletId(),
+		PayeeID:   req.GetPayeeId(),
+		Query:     req.GetQuery(),
+		Tag:       req.GetTag(),
+		Sort:      req.GetSort(),
+		Limit:     int(req.GetLimit()),
+		Offset:    int(req.GetOffset()),
+	}
+	if req.GetMinAmount() != nil {
+		value := req.GetMinAmount().GetValue()
+		filter.MinAmount = &value
+	}
+	if req.GetMaxAmount() != nil {
+		value := req.GetMaxAmount().GetValue()
+		filter.MaxAmount = &value
+	}
+
+	items, err := s.ledgerService.SearchTransactions(ctx, filter)
+	if err != nil {
+		if service.IsValidationError(err) {
+			return nil, status.Errorf(codes.InvalidArgument, "search transactions: %v", err)
+		}
+		return nil, status.Errorf(codes.Internal, "search transactions: %v", err)
+	}
+
+	resp := &pb.ListTransactionsResponse{}
+	resp.Transactions = make([]*pb.Transaction, 0, len(items))
+	for _, tx := range items {
+		resp.Transactions = append(resp.Transactions, toProtoTransaction(tx))
+	}
+	return resp, nil
+}
+
+func (s *LedgerServer) BatchCreateTransactions(ctx context.Context, req *pb.BatchCreateTransactionsRequest) (*pb.BatchTransactionsResponse, error) {
+	if req.GetAccountId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "account_id is required")
+	}
+	if err := s.authorizeWrite(ctx, req.GetAccountId()); err != nil {
+		return nil, err
+	}
+
+	items := make([]model.Transaction, 0, len(req.GetTransactions()))
+	for _, tx := range req.GetTransactions() {
+		items = append(items, toModelTransaction(tx))
+	}
+	results, err := s.ledgerService.BatchCreateTransactions(ctx, req.GetAccountId(), items, req.GetAtomic())
+	if err != nil {
+		if service.IsValidationError(err) {
+			return nil, status.Errorf(codes.InvalidArgument, "batch create transactions: %v", err)
+		}
+		return nil, status.Errorf(codes.Internal, "batch create transactions: %v", err)
+	}
+	return toProtoBatchResponse(results), nil
+}
+
+func (s *LedgerServer) BatchUpdateTransactions(ctx context.Context, req *pb.BatchUpdateTransactionsRequest) (*pb.BatchTransactionsResponse, error) {
+	if req.GetAccountId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "account_id is required")
+	}
+	if err := s.authorizeWrite(ctx, req.GetAccountId()); err != nil {
+		return nil, err
+	}
+
+	items := make([]model.Transaction, 0, len(req.GetTransactions()))
+	for _, tx := range req.GetTransactions() {
+		items = append(items, toModelTransaction(tx))
+	}
+	results, err := s.ledgerService.BatchUpdateTransactions(ctx, req.GetAccountId(), items, req.GetAtomic())
+	if err != nil {
+		if service.IsValidationError(err) {
+			return nil, status.Errorf(codes.InvalidArgument, "batch update transactions: %v", err)
+		}
+		return nil, status.Errorf(codes.Internal, "batch update transactions: %v", err)
+	}
+	return toProtoBatchResponse(results), nil
+}
+
+func (s *LedgerServer) BatchDeleteTransactions(ctx context.Context, req *pb.BatchDeleteTransactionsRequest) (*pb.BatchTransactionsResponse, error) {
+	if req.GetAccountId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "account_id is required")
+	}
+	if err := s.authorizeWrite(ctx, req.GetAccountId()); err != nil {
+		return nil, err
+	}
+
+	results, err := s.ledgerService.BatchDeleteTransactions(ctx, req.GetAccountId(), req.GetIds(), req.GetAtomic())
+	if err != nil {
+		if service.IsValidationError(err) {
+			return nil, status.Errorf(codes.InvalidArgument, "batch delete transactions: %v", err)
+		}
+		return nil, status.Errorf(codes.Internal, "batch delete transactions: %v", err)
+	}
+	return toProtoBatchResponse(results), nil
+}
+
+func (s *LedgerServer) RefundTransaction(ctx context.Context, req *pb.RefundTransactionRequest) (*pb.TransactionResponse, error) {
+	if req.GetAccountId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "account_id is required")
+	}
+	if err := s.authorizeWrite(ctx, req.GetAccountId()); err != nil {
+		return nil, err
+	}
+
+	created, err := s.ledgerService.RefundTransaction(ctx, model.Refund{
+		AccountID:     req.GetAccountId(),
+		TransactionID: req.GetTransactionId(),
+		Amount:        req.GetAmount(),
+		Category:      req.GetCategory(),
+		Description:   req.GetDescription(),
+		OccurredAt:    toTime(req.GetOccurredAt()),
+	})
+	if err != nil {
+		if service.IsNotFound(err) {
+			return nil, status.Error(codes.NotFound, "transaction not found")
+		}
+		if service.IsValidationError(err) {
+			return nil, status.Errorf(codes.InvalidArgument, "refund transaction: %v", err)
+		}
+		if service.IsTransferLeg(err) || service.IsRefundExceeded(err) {
+			return nil, status.Errorf(codes.FailedPrecondition, "refund transaction: %v", err)
+		}
+		return nil, status.Errorf(codes.Internal, "refund transaction: %v", err)
+	}
+	return &pb.TransactionResponse{Transaction: toProtoTransaction(created)}, nil
+}
+
+func (s *LedgerServer) ListRefunds(ctx context.Context, req *pb.ListRefundsRequest) (*pb.ListTransactionsResponse, error) {
+	if req.GetAccountId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "account_id is required")
+	}
+	if err := s.authorizeRead(ctx, req.GetAccountId(), "transactions"); err != nil {
+		return nil, err
+	}
+
+	refunds, err := s.ledgerService.ListRefunds(ctx, req.GetAccountId(), req.GetTransactionId())
+	if err != nil {
+		if service.IsNotFound(err) {
+			return nil, status.Error(codes.NotFound, "transaction not found")
+		}
+		if service.IsValidationError(err) {
+			return nil, status.Errorf(codes.InvalidArgument, "list refunds: %v", err)
+		}
+		return nil, status.Errorf(codes.Internal, "list refunds: %v", err)
+	}
+	items := make([]*pb.Transaction, 0, len(refunds))
+	for _, tx := range refunds {
+		items = append(items, toProtoTransaction(tx))
+	}
+	return &pb.ListTransactionsResponse{Transactions: items}, nil
+}
+
 func (s *LedgerServer) CreateBudget(ctx context.Context, req *pb.CreateBudgetRequest) (*pb.BudgetResponse, error) {
 	if req.GetBudget() == nil {
 		return nil, status.Error(codes.InvalidArgument, "budget is required")
 	}
+	if err := s.authorizeWrite(ctx, req.GetBudget().GetAccountId()); err != nil {
+		return nil, err
+	}
 
 	created, err := s.ledgerService.CreateBudget(ctx, toModelBudget(req.GetBudget()))
 	if err != nil {
@@ -133,6 +343,9 @@ func (s *LedgerServer) GetBudget(ctx context.Context, req *pb.GetBudgetRequest)
 	if req.GetAccountId() == "" {
 		return nil, status.Error(codes.InvalidArgument, "account_id is required")
 	}
+	if err := s.authorizeRead(ctx, req.GetAccountId(), "budget"); err != nil {
+		return nil, err
+	}
 
 	budget, err := s.ledgerService.GetBudget(ctx, req.GetAccountId(), req.GetId())
 	if err != nil {
@@ -152,6 +365,9 @@ func (s *LedgerServer) UpdateBudget(ctx context.Context, req *pb.UpdateBudgetReq
 	if req.GetBudget() == nil {
 		return nil, status.Error(codes.InvalidArgument, "budget is required")
 	}
+	if err := s.authorizeWrite(ctx, req.GetBudget().GetAccountId()); err != nil {
+		return nil, err
+	}
 
 	updated, err := s.ledgerService.UpdateBudget(ctx, toModelBudget(req.GetBudget()))
 	if err != nil {
@@ -175,6 +391,10 @@ func (s *LedgerServer) DeleteBudget(ctx context.Context, req *pb.DeleteBudgetReq
 		return nil, status.Error(codes.InvalidArgument, "account_id is required")
 	}
 
+	if err := s.authorizeWrite(ctx, req.GetAccountId()); err != nil {
+		return nil, err
+	}
+
 	if err := s.ledgerService.DeleteBudget(ctx, req.GetAccountId(), req.GetId()); err != nil {
 		if service.IsNotFound(err) {
 			return &pb.DeleteResponse{Deleted: false}, nil
@@ -192,6 +412,9 @@ func (s *LedgerServer) ListBudgets(ctx context.Context, req *pb.ListBudgetsReque
 	if req.GetAccountId() == "" {
 		return nil, status.Error(codes.InvalidArgument, "account_id is required")
 	}
+	if err := s.authorizeRead(ctx, req.GetAccountId(), "budgets"); err != nil {
+		return nil, err
+	}
 	items := s.ledgerService.ListBudgets(ctx, req.GetAccountId())
 	resp := &pb.ListBudgetsResponse{}
 	resp.Budgets = make([]*pb.Budget, 0, len(items))
@@ -201,189 +424,1756 @@ func (s *LedgerServer) ListBudgets(ctx context.Context, req *pb.ListBudgetsReque
 	return resp, nil
 }
 
-func (s *LedgerServer) CreateReport(ctx context.Context, req *pb.CreateReportRequest) (*pb.ReportResponse, error) {
-	if req.GetReport() == nil {
-		return nil, status.Error(codes.InvalidArgument, "report is required")
+func (s *LedgerServer) CreateWallet(ctx context.Context, req *pb.CreateWalletRequest) (*pb.WalletResponse, error) {
+	if req.GetWallet() == nil {
+		return nil, status.Error(codes.InvalidArgument, "wallet is required")
+	}
+	if err := s.authorizeWrite(ctx, req.GetWallet().GetAccountId()); err != nil {
+		return nil, err
 	}
 
-	created, err := s.ledgerService.CreateReport(ctx, toModelReport(req.GetReport()))
+	created, err := s.ledgerService.CreateWallet(ctx, toModelWallet(req.GetWallet()))
 	if err != nil {
-		if service.IsValidationError(err) {
-			return nil, status.Errorf(codes.InvalidArgument, "create report: %v", err)
-		}
-		return nil, status.Errorf(codes.Internal, "create report: %v", err)
+		return nil, walletError("create wallet", err)
 	}
-
-	return &pb.ReportResponse{Report: toProtoReport(created)}, nil
+	return &pb.WalletResponse{Wallet: toProtoWallet(created)}, nil
 }
 
-func (s *LedgerServer) GetReport(ctx context.Context, req *pb.GetReportRequest) (*pb.ReportResponse, error) {
+func (s *LedgerServer) GetWallet(ctx context.Context, req *pb.GetWalletRequest) (*pb.WalletResponse, error) {
 	if req.GetId() == "" {
 		return nil, status.Error(codes.InvalidArgument, "id is required")
 	}
 	if req.GetAccountId() == "" {
 		return nil, status.Error(codes.InvalidArgument, "account_id is required")
 	}
+	if err := s.authorizeRead(ctx, req.GetAccountId(), "wallet"); err != nil {
+		return nil, err
+	}
 
-	report, err := s.ledgerService.GetReport(ctx, req.GetAccountId(), req.GetId())
+	wallet, err := s.ledgerService.GetWallet(ctx, req.GetAccountId(), req.GetId())
 	if err != nil {
-		if service.IsNotFound(err) {
-			return nil, status.Error(codes.NotFound, "report not found")
-		}
-		if service.IsValidationError(err) {
-			return nil, status.Errorf(codes.InvalidArgument, "get report: %v", err)
-		}
-		return nil, status.Errorf(codes.Internal, "get report: %v", err)
+		return nil, walletError("get wallet", err)
 	}
-
-	return &pb.ReportResponse{Report: toProtoReport(report)}, nil
+	return &pb.WalletResponse{Wallet: toProtoWallet(wallet)}, nil
 }
 
-func (s *LedgerServer) UpdateReport(ctx context.Context, req *pb.UpdateReportRequest) (*pb.ReportResponse, error) {
-	if req.GetReport() == nil {
-		return nil, status.Error(codes.InvalidArgument, "report is required")
+func (s *LedgerServer) UpdateWallet(ctx context.Context, req *pb.UpdateWalletRequest) (*pb.WalletResponse, error) {
+	if req.GetWallet() == nil {
+		return nil, status.Error(codes.InvalidArgument, "wallet is required")
+	}
+	if err := s.authorizeWrite(ctx, req.GetWallet().GetAccountId()); err != nil {
+		return nil, err
 	}
 
-	updated, err := s.ledgerService.UpdateReport(ctx, toModelReport(req.GetReport()))
+	updated, err := s.ledgerService.UpdateWallet(ctx, toModelWallet(req.GetWallet()))
 	if err != nil {
-		if service.IsNotFound(err) {
-			return nil, status.Error(codes.NotFound, "report not found")
-		}
-		if service.IsValidationError(err) {
-			return nil, status.Errorf(codes.InvalidArgument, "update report: %v", err)
-		}
-		return nil, status.Errorf(codes.Internal, "update report: %v", err)
+		return nil, walletError("update wallet", err)
 	}
-
-	return &pb.ReportResponse{Report: toProtoReport(updated)}, nil
+	return &pb.WalletResponse{Wallet: toProtoWallet(updated)}, nil
 }
 
-func (s *LedgerServer) DeleteReport(ctx context.Context, req *pb.DeleteReportRequest) (*pb.DeleteResponse, error) {
+func (s *LedgerServer) DeleteWallet(ctx context.Context, req *pb.DeleteWalletRequest) (*pb.DeleteResponse, error) {
 	if req.GetId() == "" {
 		return nil, status.Error(codes.InvalidArgument, "id is required")
 	}
 	if req.GetAccountId() == "" {
 		return nil, status.Error(codes.InvalidArgument, "account_id is required")
 	}
+	if err := s.authorizeWrite(ctx, req.GetAccountId()); err != nil {
+		return nil, err
+	}
 
-	if err := s.ledgerService.DeleteReport(ctx, req.GetAccountId(), req.GetId()); err != nil {
+	if err := s.ledgerService.DeleteWallet(ctx, req.GetAccountId(), req.GetId()); err != nil {
 		if service.IsNotFound(err) {
 			return &pb.DeleteResponse{Deleted: false}, nil
 		}
-		if service.IsValidationError(err) {
-			return nil, status.Errorf(codes.InvalidArgument, "delete report: %v", err)
-		}
-		return nil, status.Errorf(codes.Internal, "delete report: %v", err)
+		return nil, walletError("delete wallet", err)
 	}
-
 	return &pb.DeleteResponse{Deleted: true}, nil
 }
 
-func (s *LedgerServer) ListReports(ctx context.Context, req *pb.ListReportsRequest) (*pb.ListReportsResponse, error) {
+func (s *LedgerServer) ListWallets(ctx context.Context, req *pb.ListWalletsRequest) (*pb.ListWalletsResponse, error) {
 	if req.GetAccountId() == "" {
 		return nil, status.Error(codes.InvalidArgument, "account_id is required")
 	}
-	items := s.ledgerService.ListReports(ctx, req.GetAccountId())
-	resp := &pb.ListReportsResponse{}
-	resp.Reports = make([]*pb.Report, 0, len(items))
-	for _, report := range items {
-		resp.Reports = append(resp.Reports, toProtoReport(report))
+	if err := s.authorizeRead(ctx, req.GetAccountId(), "wallets"); err != nil {
+		return nil, err
+	}
+
+	items, err := s.ledgerService.ListWallets(ctx, req.GetAccountId())
+	if err != nil {
+		return nil, walletError("list wallets", err)
+	}
+	resp := &pb.ListWalletsResponse{Wallets: make([]*pb.Wallet, 0, len(items))}
+	for _, wallet := range items {
+		resp.Wallets = append(resp.Wallets, toProtoWallet(wallet))
 	}
 	return resp, nil
 }
 
-func (s *LedgerServer) ImportTransactionsCsv(ctx context.Context, req *pb.ImportTransactionsCsvRequest) (*pb.ImportTransactionsCsvResponse, error) {
+func (s *LedgerServer) CreateTransfer(ctx context.Context, req *pb.CreateTransferRequest) (*pb.TransferResponse, error) {
+	if req.GetTransfer() == nil {
+		return nil, status.Error(codes.InvalidArgument, "transfer is required")
+	}
+	if err := s.authorizeWrite(ctx, req.GetTransfer().GetAccountId()); err != nil {
+		return nil, err
+	}
+
+	created, err := s.ledgerService.CreateTransfer(ctx, toModelTransfer(req.GetTransfer()))
+	if err != nil {
+		return nil, walletError("create transfer", err)
+	}
+	return &pb.TransferResponse{Transfer: toProtoTransfer(created)}, nil
+}
+
+func (s *LedgerServer) DeleteTransfer(ctx context.Context, req *pb.DeleteTransferRequest) (*pb.DeleteResponse, error) {
+	if req.GetId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "id is required")
+	}
 	if req.GetAccountId() == "" {
 		return nil, status.Error(codes.InvalidArgument, "account_id is required")
 	}
-	if len(req.GetCsvContent()) == 0 {
-		return nil, status.Error(codes.InvalidArgument, "csv_content is required")
+	if err := s.authorizeWrite(ctx, req.GetAccountId()); err != nil {
+		return nil, err
 	}
 
-	count, err := s.ledgerService.ImportTransactionsCSV(ctx, req.GetAccountId(), req.GetCsvContent(), req.GetHasHeader())
-	if err != nil {
-		if service.IsValidationError(err) {
-			return nil, status.Errorf(codes.InvalidArgument, "import csv: %v", err)
+	if err := s.ledgerService.DeleteTransfer(ctx, req.GetAccountId(), req.GetId()); err != nil {
+		if service.IsNotFound(err) {
+			return &pb.DeleteResponse{Deleted: false}, nil
 		}
-		return nil, status.Errorf(codes.Internal, "import csv: %v", err)
+		return nil, walletError("delete transfer", err)
 	}
-
-	return &pb.ImportTransactionsCsvResponse{Imported: int32(count)}, nil
+	return &pb.DeleteResponse{Deleted: true}, nil
 }
 
-func (s *LedgerServer) ExportTransactionsCsv(ctx context.Context, req *pb.ExportTransactionsCsvRequest) (*pb.ExportTransactionsCsvResponse, error) {
-	csvContent, err := s.ledgerService.ExportTransactionsCSV(ctx, req.GetAccountId())
-	if err != nil {
-		return nil, status.Errorf(codes.Internal, "export csv: %v", err)
+func (s *LedgerServer) GetWalletBalanceHistory(ctx context.Context, req *pb.GetWalletBalanceHistoryRequest) (*pb.GetWalletBalanceHistoryResponse, error) {
+	if req.GetAccountId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "account_id is required")
+	}
+	if err := s.authorizeRead(ctx, req.GetAccountId(), "wallet"); err != nil {
+		return nil, err
 	}
 
-	return &pb.ExportTransactionsCsvResponse{CsvContent: csvContent}, nil
+	points, err := s.ledgerService.GetWalletBalanceHistory(ctx, req.GetAccountId(), req.GetWalletId(), toTime(req.GetFrom()), toTime(req.GetTo()), req.GetStep())
+	if err != nil {
+		return nil, walletError("get wallet balance history", err)
+	}
+	resp := &pb.GetWalletBalanceHistoryResponse{Points: make([]*pb.BalancePoint, 0, len(points))}
+	for _, point := range points {
+		resp.Points = append(resp.Points, &pb.BalancePoint{Date: timestamppb.New(point.Date), Balance: point.Balance})
+	}
+	return resp, nil
 }
 
-func toModelTransaction(tx *pb.Transaction) model.Transaction {
-	return model.Transaction{
-		ID:          tx.GetId(),
-		AccountID:   tx.GetAccountId(),
-		Amount:      tx.GetAmount(),
-		Currency:    tx.GetCurrency(),
-		Category:    tx.GetCategory(),
-		Description: tx.GetDescription(),
-		OccurredAt:  toTime(tx.GetOccurredAt()),
-		CreatedAt:   toTime(tx.GetCreatedAt()),
-		UpdatedAt:   toTime(tx.GetUpdatedAt()),
+func (s *LedgerServer) CreateTag(ctx context.Context, req *pb.CreateTagRequest) (*pb.TagResponse, error) {
+	if req.GetTag() == nil {
+		return nil, status.Error(codes.InvalidArgument, "tag is required")
+	}
+	if err := s.authorizeWrite(ctx, req.GetTag().GetAccountId()); err != nil {
+		return nil, err
 	}
-}
 
-func toProtoTransaction(tx model.Transaction) *pb.Transaction {
-	return &pb.Transaction{
-		Id:          tx.ID,
-		AccountId:   tx.AccountID,
-		Amount:      tx.Amount,
-		Currency:    tx.Currency,
-		Category:    tx.Category,
-		Description: tx.Description,
-		OccurredAt:  timestamppb.New(tx.OccurredAt),
-		CreatedAt:   timestamppb.New(tx.CreatedAt),
-		UpdatedAt:   timestamppb.New(tx.UpdatedAt),
+	created, err := s.ledgerService.CreateTag(ctx, toModelTag(req.GetTag()))
+	if err != nil {
+		return nil, tagError("create tag", err)
 	}
+	return &pb.TagResponse{Tag: toProtoTag(created)}, nil
 }
 
-func toModelBudget(budget *pb.Budget) model.Budget {
-	return model.Budget{
-		ID:        budget.GetId(),
-		AccountID: budget.GetAccountId(),
-		Name:      budget.GetName(),
-		Amount:    budget.GetAmount(),
-		Currency:  budget.GetCurrency(),
-		Period:    budget.GetPeriod(),
-		Month:     toTime(budget.GetMonth()),
-		CreatedAt: toTime(budget.GetCreatedAt()),
-		UpdatedAt: toTime(budget.GetUpdatedAt()),
+func (s *LedgerServer) GetTag(ctx context.Context, req *pb.GetTagRequest) (*pb.TagResponse, error) {
+	if req.GetId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "id is required")
+	}
+	if req.GetAccountId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "account_id is required")
+	}
+	if err := s.authorizeRead(ctx, req.GetAccountId(), "tag"); err != nil {
+		return nil, err
 	}
-}
 
-func toProtoBudget(budget model.Budget) *pb.Budget {
-	return &pb.Budget{
-		Id:        budget.ID,
-		AccountId: budget.AccountID,
-		Name:      budget.Name,
-		Amount:    budget.Amount,
-		Currency:  budget.Currency,
-		Period:    budget.Period,
-		Month:     timestamppb.New(budget.Month),
-		CreatedAt: timestamppb.New(budget.CreatedAt),
-		UpdatedAt: timestamppb.New(budget.UpdatedAt),
+	tag, err := s.ledgerService.GetTag(ctx, req.GetAccountId(), req.GetId())
+	if err != nil {
+		return nil, tagError("get tag", err)
 	}
+	return &pb.TagResponse{Tag: toProtoTag(tag)}, nil
 }
 
-func toModelReport(report *pb.Report) model.Report {
-	categories := make([]model.ReportCategory, 0, len(report.GetCategories()))
+func (s *LedgerServer) UpdateTag(ctx context.Context, req *pb.UpdateTagRequest) (*pb.TagResponse, error) {
+	if req.GetTag() == nil {
+		return nil, status.Error(codes.InvalidArgument, "tag is required")
+	}
+	if err := s.authorizeWrite(ctx, req.GetTag().GetAccountId()); err != nil {
+		return nil, err
+	}
+
+	updated, err := s.ledgerService.UpdateTag(ctx, toModelTag(req.GetTag()))
+	if err != nil {
+		return nil, tagError("update tag", err)
+	}
+	return &pb.TagResponse{Tag: toProtoTag(updated)}, nil
+}
+
+func (s *LedgerServer) DeleteTag(ctx context.Context, req *pb.DeleteTagRequest) (*pb.DeleteResponse, error) {
+	if req.GetId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "id is required")
+	}
+	if req.GetAccountId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "account_id is required")
+	}
+	if err := s.authorizeWrite(ctx, req.GetAccountId()); err != nil {
+		return nil, err
+	}
+
+	if err := s.ledgerService.DeleteTag(ctx, req.GetAccountId(), req.GetId()); err != nil {
+		if service.IsNotFound(err) {
+			return &pb.DeleteResponse{Deleted: false}, nil
+		}
+		return nil, tagError("delete tag", err)
+	}
+	return &pb.DeleteResponse{Deleted: true}, nil
+}
+
+func (s *LedgerServer) ListTags(ctx context.Context, req *pb.ListTagsRequest) (*pb.ListTagsResponse, error) {
+	if req.GetAccountId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "account_id is required")
+	}
+	if err := s.authorizeRead(ctx, req.GetAccountId(), "tags"); err != nil {
+		return nil, err
+	}
+
+	items, err := s.ledgerService.ListTags(ctx, req.GetAccountId())
+	if err != nil {
+		return nil, tagError("list tags", err)
+	}
+	resp := &pb.ListTagsResponse{Tags: make([]*pb.Tag, 0, len(items))}
+	for _, tag := range items {
+		resp.Tags = append(resp.Tags, toProtoTag(tag))
+	}
+	return resp, nil
+}
+
+// UploadAttachment expects the attachment in the first message and its
+// content in the following ones.
+func (s *LedgerServer) UploadAttachment(stream pb.LedgerService_UploadAttachmentServer) error {
+	first, err := stream.Recv()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return status.Error(codes.InvalidArgument, "attachment is required")
+		}
+		return err
+	}
+	attachment := first.GetAttachment()
+	if attachment == nil {
+		return status.Error(codes.InvalidArgument, "the first message must carry the attachment")
+	}
+	ctx := stream.Context()
+	if err := s.authorizeWrite(ctx, attachment.GetAccountId()); err != nil {
+		return err
+	}
+
+	created, err := s.ledgerService.UploadAttachment(ctx, toModelAttachment(attachment), &attachmentChunkReader{stream: stream})
+	if err != nil {
+		return attachmentError("upload attachment", err)
+	}
+	return stream.SendAndClose(&pb.AttachmentResponse{Attachment: toProtoAttachment(created)})
+}
+
+func (s *LedgerServer) GetAttachment(ctx context.Context, req *pb.GetAttachmentRequest) (*pb.AttachmentResponse, error) {
+	if req.GetId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "id is required")
+	}
+	if req.GetAccountId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "account_id is required")
+	}
+	if err := s.authorizeRead(ctx, req.GetAccountId(), "attachment"); err != nil {
+		return nil, err
+	}
+
+	attachment, err := s.ledgerService.GetAttachment(ctx, req.GetAccountId(), req.GetId())
+	if err != nil {
+		return nil, attachmentError("get attachment", err)
+	}
+	return &pb.AttachmentResponse{Attachment: toProtoAttachment(attachment)}, nil
+}
+
+// DownloadAttachment sends the attachment in the first message and its
+// content in chunks of attachmentChunkSize.
+func (s *LedgerServer) DownloadAttachment(req *pb.DownloadAttachmentRequest, stream pb.LedgerService_DownloadAttachmentServer) error {
+	if req.GetId() == "" {
+		return status.Error(codes.InvalidArgument, "id is required")
+	}
+	if req.GetAccountId() == "" {
+		return status.Error(codes.InvalidArgument, "account_id is required")
+	}
+	ctx := stream.Context()
+	if err := s.authorizeRead(ctx, req.GetAccountId(), "attachment"); err != nil {
+		return err
+	}
+
+	attachment, content, err := s.ledgerService.OpenAttachment(ctx, req.GetAccountId(), req.GetId())
+	if err != nil {
+		return attachmentError("download attachment", err)
+	}
+	defer content.Close()
+
+	if err := stream.Send(&pb.DownloadAttachmentResponse{
+		Payload: &pb.DownloadAttachmentResponse_Attachment{Attachment: toProtoAttachment(attachment)},
+	}); err != nil {
+		return err
+	}
+	buf := make([]byte, attachmentChunkSize)
+	for {
+		n, err := content.Read(buf)
+		if n > 0 {
+			if err := stream.Send(&pb.DownloadAttachmentResponse{
+				Payload: &pb.DownloadAttachmentResponse_Chunk{Chunk: buf[:n]},
+			}); err != nil {
+				return err
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return status.Errorf(codes.Internal, "download attachment: %v", err)
+		}
+	}
+}
+
+func (s *LedgerServer) ListAttachments(ctx context.Context, req *pb.ListAttachmentsRequest) (*pb.ListAttachmentsResponse, error) {
+	if req.GetAccountId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "account_id is required")
+	}
+	if req.GetTransactionId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "transaction_id is required")
+	}
+	if err := s.authorizeRead(ctx, req.GetAccountId(), "attachments"); err != nil {
+		return nil, err
+	}
+
+	items, err := s.ledgerService.ListAttachments(ctx, req.GetAccountId(), req.GetTransactionId())
+	if err != nil {
+		return nil, attachmentError("list attachments", err)
+	}
+	resp := &pb.ListAttachmentsResponse{Attachments: make([]*pb.Attachment, 0, len(items))}
+	for _, item := range items {
+		resp.Attachments = append(resp.Attachments, toProtoAttachment(item))
+	}
+	return resp, nil
+}
+
+func (s *LedgerServer) DeleteAttachment(ctx context.Context, req *pb.DeleteAttachmentRequest) (*pb.DeleteResponse, error) {
+	if req.GetId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "id is required")
+	}
+	if req.GetAccountId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "account_id is required")
+	}
+	if err := s.authorizeWrite(ctx, req.GetAccountId()); err != nil {
+		return nil, err
+	}
+
+	if err := s.ledgerService.DeleteAttachment(ctx, req.GetAccountId(), req.GetId()); err != nil {
+		if service.IsNotFound(err) {
+			return &pb.DeleteResponse{Deleted: false}, nil
+		}
+		return nil, attachmentError("delete attachment", err)
+	}
+	return &pb.DeleteResponse{Deleted: true}, nil
+}
+
+// attachmentChunkReader reads the content chunks of an upload stream.
+type attachmentChunkReader struct {
+	stream pb.LedgerService_UploadAttachmentServer
+	chunk  []byte
+}
+
+func (r *attachmentChunkReader) Read(p []byte) (int, error) {
+	for len(r.chunk) == 0 {
+		req, err := r.stream.Recv()
+		if err != nil {
+			return 0, err
+		}
+		if req.GetAttachment() != nil {
+			return 0, status.Error(codes.InvalidArgument, "attachment must be sent only once")
+		}
+		r.chunk = req.GetChunk()
+	}
+	n := copy(p, r.chunk)
+	r.chunk = r.chunk[n:]
+	return n, nil
+}
+
+func (s *LedgerServer) CreateRule(ctx context.Context, req *pb.CreateRuleRequest) (*pb.RuleResponse, error) {
+	if req.GetRule() == nil {
+		return nil, status.Error(codes.InvalidArgument, "rule is required")
+	}
+	if err := s.authorizeWrite(ctx, req.GetRule().GetAccountId()); err != nil {
+		return nil, err
+	}
+
+	created, err := s.ledgerService.CreateRule(ctx, toModelRule(req.GetRule()))
+	if err != nil {
+		return nil, ruleError("create rule", err)
+	}
+	return &pb.RuleResponse{Rule: toProtoRule(created)}, nil
+}
+
+func (s *LedgerServer) GetRule(ctx context.Context, req *pb.GetRuleRequest) (*pb.RuleResponse, error) {
+	if req.GetId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "id is required")
+	}
+	if req.GetAccountId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "account_id is required")
+	}
+	if err := s.authorizeRead(ctx, req.GetAccountId(), "rule"); err != nil {
+		return nil, err
+	}
+
+	rule, err := s.ledgerService.GetRule(ctx, req.GetAccountId(), req.GetId())
+	if err != nil {
+		return nil, ruleError("get rule", err)
+	}
+	return &pb.RuleResponse{Rule: toProtoRule(rule)}, nil
+}
+
+func (s *LedgerServer) UpdateRule(ctx context.Context, req *pb.UpdateRuleRequest) (*pb.RuleResponse, error) {
+	if req.GetRule() == nil {
+		return nil, status.Error(codes.InvalidArgument, "rule is required")
+	}
+	if err := s.authorizeWrite(ctx, req.GetRule().GetAccountId()); err != nil {
+		return nil, err
+	}
+
+	updated, err := s.ledgerService.UpdateRule(ctx, toModelRule(req.GetRule()))
+	if err != nil {
+		return nil, ruleError("update rule", err)
+	}
+	return &pb.RuleResponse{Rule: toProtoRule(updated)}, nil
+}
+
+func (s *LedgerServer) DeleteRule(ctx context.Context, req *pb.DeleteRuleRequest) (*pb.DeleteResponse, error) {
+	if req.GetId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "id is required")
+	}
+	if req.GetAccountId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "account_id is required")
+	}
+	if err := s.authorizeWrite(ctx, req.GetAccountId()); err != nil {
+		return nil, err
+	}
+
+	if err := s.ledgerService.DeleteRule(ctx, req.GetAccountId(), req.GetId()); err != nil {
+		if service.IsNotFound(err) {
+			return &pb.DeleteResponse{Deleted: false}, nil
+		}
+		return nil, ruleError("delete rule", err)
+	}
+	return &pb.DeleteResponse{Deleted: true}, nil
+}
+
+func (s *LedgerServer) ListRules(ctx context.Context, req *pb.ListRulesRequest) (*pb.ListRulesResponse, error) {
+	if req.GetAccountId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "account_id is required")
+	}
+	if err := s.authorizeRead(ctx, req.GetAccountId(), "rules"); err != nil {
+		return nil, err
+	}
+
+	rules, err := s.ledgerService.ListRules(ctx, req.GetAccountId())
+	if err != nil {
+		return nil, ruleError("list rules", err)
+	}
+	resp := &pb.ListRulesResponse{Rules: make([]*pb.Rule, 0, len(rules))}
+	for _, rule := range rules {
+		resp.Rules = append(resp.Rules, toProtoRule(rule))
+	}
+	return resp, nil
+}
+
+func (s *LedgerServer) PreviewRule(ctx context.Context, req *pb.PreviewRuleRequest) (*pb.RuleMatchesResponse, error) {
+	if req.GetRule() == nil {
+		return nil, status.Error(codes.InvalidArgument, "rule is required")
+	}
+	if err := s.authorizeRead(ctx, req.GetRule().GetAccountId(), "rules"); err != nil {
+		return nil, err
+	}
+
+	matches, err := s.ledgerService.PreviewRule(ctx, toModelRule(req.GetRule()))
+	if err != nil {
+		return nil, ruleError("preview rule", err)
+	}
+	return toProtoRuleMatches(matches), nil
+}
+
+// ApplyRules needs write access even as a dry run, which shows what a writer
+// is about to change.
+func (s *LedgerServer) ApplyRules(ctx context.Context, req *pb.ApplyRulesRequest) (*pb.RuleMatchesResponse, error) {
+	if req.GetAccountId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "account_id is required")
+	}
+	if err := s.authorizeWrite(ctx, req.GetAccountId()); err != nil {
+		return nil, err
+	}
+
+	matches, err := s.ledgerService.ApplyRules(ctx, req.GetAccountId(), req.GetDryRun())
+	if err != nil {
+		return nil, ruleError("apply rules", err)
+	}
+	return toProtoRuleMatches(matches), nil
+}
+
+func (s *LedgerServer) CreatePayee(ctx context.Context, req *pb.CreatePayeeRequest) (*pb.PayeeResponse, error) {
+	if req.GetPayee() == nil {
+		return nil, status.Error(codes.InvalidArgument, "payee is required")
+	}
+	if err := s.authorizeWrite(ctx, req.GetPayee().GetAccountId()); err != nil {
+		return nil, err
+	}
+
+	created, err := s.ledgerService.CreatePayee(ctx, toModelPayee(req.GetPayee()))
+	if err != nil {
+		return nil, payeeError("create payee", err)
+	}
+	return &pb.PayeeResponse{Payee: toProtoPayee(created)}, nil
+}
+
+func (s *LedgerServer) GetPayee(ctx context.Context, req *pb.GetPayeeRequest) (*pb.PayeeResponse, error) {
+	if req.GetId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "id is required")
+	}
+	if req.GetAccountId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "account_id is required")
+	}
+	if err := s.authorizeRead(ctx, req.GetAccountId(), "payee"); err != nil {
+		return nil, err
+	}
+
+	payee, err := s.ledgerService.GetPayee(ctx, req.GetAccountId(), req.GetId())
+	if err != nil {
+		return nil, payeeError("get payee", err)
+	}
+	return &pb.PayeeResponse{Payee: toProtoPayee(payee)}, nil
+}
+
+func (s *LedgerServer) UpdatePayee(ctx context.Context, req *pb.UpdatePayeeRequest) (*pb.PayeeResponse, error) {
+	if req.GetPayee() == nil {
+		return nil, status.Error(codes.InvalidArgument, "payee is required")
+	}
+	if err := s.authorizeWrite(ctx, req.GetPayee().GetAccountId()); err != nil {
+		return nil, err
+	}
+
+	updated, err := s.ledgerService.UpdatePayee(ctx, toModelPayee(req.GetPayee()))
+	if err != nil {
+		return nil, payeeError("update payee", err)
+	}
+	return &pb.PayeeResponse{Payee: toProtoPayee(updated)}, nil
+}
+
+func (s *LedgerServer) DeletePayee(ctx context.Context, req *pb.DeletePayeeRequest) (*pb.DeleteResponse, error) {
+	if req.GetId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "id is required")
+	}
+	if req.GetAccountId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "account_id is required")
+	}
+	if err := s.authorizeWrite(ctx, req.GetAccountId()); err != nil {
+		return nil, err
+	}
+
+	if err := s.ledgerService.DeletePayee(ctx, req.GetAccountId(), req.GetId()); err != nil {
+		if service.IsNotFound(err) {
+			return &pb.DeleteResponse{Deleted: false}, nil
+		}
+		return nil, payeeError("delete payee", err)
+	}
+	return &pb.DeleteResponse{Deleted: true}, nil
+}
+
+func (s *LedgerServer) ListPayees(ctx context.Context, req *pb.ListPayeesRequest) (*pb.ListPayeesResponse, error) {
+	if req.GetAccountId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "account_id is required")
+	}
+	if err := s.authorizeRead(ctx, req.GetAccountId(), "payees"); err != nil {
+		return nil, err
+	}
+
+	payees, err := s.ledgerService.ListPayees(ctx, req.GetAccountId())
+	if err != nil {
+		return nil, payeeError("list payees", err)
+	}
+	resp := &pb.ListPayeesResponse{Payees: make([]*pb.Payee, 0, len(payees))}
+	for _, payee := range payees {
+		resp.Payees = append(resp.Payees, toProtoPayee(payee))
+	}
+	return resp, nil
+}
+
+func (s *LedgerServer) CreateGoal(ctx context.Context, req *pb.CreateGoalRequest) (*pb.GoalResponse, error) {
+	if req.GetGoal() == nil {
+		return nil, status.Error(codes.InvalidArgument, "goal is required")
+	}
+	if err := s.authorizeWrite(ctx, req.GetGoal().GetAccountId()); err != nil {
+		return nil, err
+	}
+
+	created, err := s.ledgerService.CreateGoal(ctx, toModelGoal(req.GetGoal()))
+	if err != nil {
+		return nil, goalError("create goal", err)
+	}
+	return &pb.GoalResponse{Goal: toProtoGoal(created)}, nil
+}
+
+func (s *LedgerServer) GetGoal(ctx context.Context, req *pb.GetGoalRequest) (*pb.GoalResponse, error) {
+	if req.GetId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "id is required")
+	}
+	if req.GetAccountId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "account_id is required")
+	}
+	if err := s.authorizeRead(ctx, req.GetAccountId(), "goal"); err != nil {
+		return nil, err
+	}
+
+	goal, err := s.ledgerService.GetGoal(ctx, req.GetAccountId(), req.GetId())
+	if err != nil {
+		return nil, goalError("get goal", err)
+	}
+	return &pb.GoalResponse{Goal: toProtoGoal(goal)}, nil
+}
+
+func (s *LedgerServer) UpdateGoal(ctx context.Context, req *pb.UpdateGoalRequest) (*pb.GoalResponse, error) {
+	if req.GetGoal() == nil {
+		return nil, status.Error(codes.InvalidArgument, "goal is required")
+	}
+	if err := s.authorizeWrite(ctx, req.GetGoal().GetAccountId()); err != nil {
+		return nil, err
+	}
+
+	updated, err := s.ledgerService.UpdateGoal(ctx, toModelGoal(req.GetGoal()))
+	if err != nil {
+		return nil, goalError("update goal", err)
+	}
+	return &pb.GoalResponse{Goal: toProtoGoal(updated)}, nil
+}
+
+func (s *LedgerServer) DeleteGoal(ctx context.Context, req *pb.DeleteGoalRequest) (*pb.DeleteResponse, error) {
+	if req.GetId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "id is required")
+	}
+	if req.GetAccountId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "account_id is required")
+	}
+	if err := s.authorizeWrite(ctx, req.GetAccountId()); err != nil {
+		return nil, err
+	}
+
+	if err := s.ledgerService.DeleteGoal(ctx, req.GetAccountId(), req.GetId()); err != nil {
+		if service.IsNotFound(err) {
+			return &pb.DeleteResponse{Deleted: false}, nil
+		}
+		return nil, goalError("delete goal", err)
+	}
+	return &pb.DeleteResponse{Deleted: true}, nil
+}
+
+func (s *LedgerServer) ListGoals(ctx context.Context, req *pb.ListGoalsRequest) (*pb.ListGoalsResponse, error) {
+	if req.GetAccountId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "account_id is required")
+	}
+	if err := s.authorizeRead(ctx, req.GetAccountId(), "goals"); err != nil {
+		return nil, err
+	}
+
+	goals, err := s.ledgerService.ListGoals(ctx, req.GetAccountId())
+	if err != nil {
+		return nil, goalError("list goals", err)
+	}
+	resp := &pb.ListGoalsResponse{Goals: make([]*pb.Goal, 0, len(goals))}
+	for _, goal := range goals {
+		resp.Goals = append(resp.Goals, toProtoGoal(goal))
+	}
+	return resp, nil
+}
+
+func (s *LedgerServer) ListJournalEntries(ctx context.Context, req *pb.ListJournalEntriesRequest) (*pb.ListJournalEntriesResponse, error) {
+	if req.GetAccountId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "account_id is required")
+	}
+	if err := s.authorizeRead(ctx, req.GetAccountId(), "journal"); err != nil {
+		return nil, err
+	}
+
+	entries, err := s.ledgerService.ListJournalEntries(ctx, req.GetAccountId())
+	if err != nil {
+		if service.IsValidationError(err) {
+			return nil, status.Errorf(codes.InvalidArgument, "list journal entries: %v", err)
+		}
+		return nil, status.Errorf(codes.Internal, "list journal entries: %v", err)
+	}
+	resp := &pb.ListJournalEntriesResponse{Entries: make([]*pb.JournalEntry, 0, len(entries))}
+	for _, entry := range entries {
+		resp.Entries = append(resp.Entries, toProtoJournalEntry(entry))
+	}
+	return resp, nil
+}
+
+func (s *LedgerServer) GetTrialBalance(ctx context.Context, req *pb.GetTrialBalanceRequest) (*pb.TrialBalance, error) {
+	if req.GetAccountId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "account_id is required")
+	}
+	if err := s.authorizeRead(ctx, req.GetAccountId(), "journal"); err != nil {
+		return nil, err
+	}
+
+	balance, err := s.ledgerService.GetTrialBalance(ctx, req.GetAccountId())
+	if err != nil {
+		if service.IsValidationError(err) {
+			return nil, status.Errorf(codes.InvalidArgument, "get trial balance: %v", err)
+		}
+		return nil, status.Errorf(codes.Internal, "get trial balance: %v", err)
+	}
+	return toProtoTrialBalance(balance), nil
+}
+
+func (s *LedgerServer) CreateReport(ctx context.Context, req *pb.CreateReportRequest) (*pb.ReportResponse, error) {
+	if req.GetReport() == nil {
+		return nil, status.Error(codes.InvalidArgument, "report is required")
+	}
+	if err := s.authorizeWrite(ctx, req.GetReport().GetAccountId()); err != nil {
+		return nil, err
+	}
+
+	created, err := s.ledgerService.CreateReport(ctx, toModelReport(req.GetReport()))
+	if err != nil {
+		if service.IsValidationError(err) {
+			return nil, status.Errorf(codes.InvalidArgument, "create report: %v", err)
+		}
+		return nil, status.Errorf(codes.Internal, "create report: %v", err)
+	}
+
+	return &pb.ReportResponse{Report: toProtoReport(created)}, nil
+}
+
+func (s *LedgerServer) GetReport(ctx context.Context, req *pb.GetReportRequest) (*pb.ReportResponse, error) {
+	if req.GetId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "id is required")
+	}
+	if req.GetAccountId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "account_id is required")
+	}
+	if err := s.authorizeRead(ctx, req.GetAccountId(), "report"); err != nil {
+		return nil, err
+	}
+
+	report, err := s.ledgerService.GetReport(ctx, req.GetAccountId(), req.GetId())
+	if err != nil {
+		if service.IsNotFound(err) {
+			return nil, status.Error(codes.NotFound, "report not found")
+		}
+		if service.IsValidationError(err) {
+			return nil, status.Errorf(codes.InvalidArgument, "get report: %v", err)
+		}
+		return nil, status.Errorf(codes.Internal, "get report: %v", err)
+	}
+
+	return &pb.ReportResponse{Report: toProtoReport(report)}, nil
+}
+
+func (s *LedgerServer) UpdateReport(ctx context.Context, req *pb.UpdateReportRequest) (*pb.ReportResponse, error) {
+	if req.GetReport() == nil {
+		return nil, status.Error(codes.InvalidArgument, "report is required")
+	}
+	if err := s.authorizeWrite(ctx, req.GetReport().GetAccountId()); err != nil {
+		return nil, err
+	}
+
+	updated, err := s.ledgerService.UpdateReport(ctx, toModelReport(req.GetReport()))
+	if err != nil {
+		if service.IsNotFound(err) {
+			return nil, status.Error(codes.NotFound, "report not found")
+		}
+		if service.IsValidationError(err) {
+			return nil, status.Errorf(codes.InvalidArgument, "update report: %v", err)
+		}
+		return nil, status.Errorf(codes.Internal, "update report: %v", err)
+	}
+
+	return &pb.ReportResponse{Report: toProtoReport(updated)}, nil
+}
+
+func (s *LedgerServer) DeleteReport(ctx context.Context, req *pb.DeleteReportRequest) (*pb.DeleteResponse, error) {
+	if req.GetId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "id is required")
+	}
+	if req.GetAccountId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "account_id is required")
+	}
+
+	if err := s.authorizeWrite(ctx, req.GetAccountId()); err != nil {
+		return nil, err
+	}
+
+	if err := s.ledgerService.DeleteReport(ctx, req.GetAccountId(), req.GetId()); err != nil {
+		if service.IsNotFound(err) {
+			return &pb.DeleteResponse{Deleted: false}, nil
+		}
+		if service.IsValidationError(err) {
+			return nil, status.Errorf(codes.InvalidArgument, "delete report: %v", err)
+		}
+		return nil, status.Errorf(codes.Internal, "delete report: %v", err)
+	}
+
+	return &pb.DeleteResponse{Deleted: true}, nil
+}
+
+func (s *LedgerServer) ListReports(ctx context.Context, req *pb.ListReportsRequest) (*pb.ListReportsResponse, error) {
+	if req.GetAccountId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "account_id is required")
+	}
+	if err := s.authorizeRead(ctx, req.GetAccountId(), "reports"); err != nil {
+		return nil, err
+	}
+	items := s.ledgerService.ListReports(ctx, req.GetAccountId())
+	resp := &pb.ListReportsResponse{}
+	resp.Reports = make([]*pb.Report, 0, len(items))
+	for _, report := range items {
+		resp.Reports = append(resp.Reports, toProtoReport(report))
+	}
+	return resp, nil
+}
+
+func (s *LedgerServer) ImportTransactionsCsv(ctx context.Context, req *pb.ImportTransactionsCsvRequest) (*pb.ImportTransactionsCsvResponse, error) {
+	if req.GetAccountId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "account_id is required")
+	}
+	if err := s.authorizeWrite(ctx, req.GetAccountId()); err != nil {
+		return nil, err
+	}
+	if len(req.GetCsvContent()) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "csv_content is required")
+	}
+
+	count, err := s.ledgerService.ImportTransactionsCSV(ctx, req.GetAccountId(), req.GetCsvContent(), req.GetHasHeader())
+	if err != nil {
+		if service.IsValidationError(err) {
+			return nil, status.Errorf(codes.InvalidArgument, "import csv: %v", err)
+		}
+		return nil, status.Errorf(codes.Internal, "import csv: %v", err)
+	}
+
+	return &pb.ImportTransactionsCsvResponse{Imported: int32(count)}, nil
+}
+
+func (s *LedgerServer) ExportTransactionsCsv(ctx context.Context, req *pb.ExportTransactionsCsvRequest) (*pb.ExportTransactionsCsvResponse, error) {
+	if err := s.authorizeRead(ctx, req.GetAccountId(), "export"); err != nil {
+		return nil, err
+	}
+	csvContent, err := s.ledgerService.ExportTransactionsCSV(ctx, req.GetAccountId())
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "export csv: %v", err)
+	}
+
+	return &pb.ExportTransactionsCsvResponse{CsvContent: csvContent}, nil
+}
+
+func (s *LedgerServer) WatchEvents(req *pb.WatchEventsRequest, stream pb.LedgerService_WatchEventsServer) error {
+	if req.GetAccountId() == "" {
+		return status.Error(codes.InvalidArgument, "account_id is required")
+	}
+	if req.GetFromOffset() < 0 {
+		return status.Error(codes.InvalidArgument, "from_offset must not be negative")
+	}
+	if err := s.authorizeRead(stream.Context(), req.GetAccountId(), "events"); err != nil {
+		return err
+	}
+
+	ctx := stream.Context()
+	offset := req.GetFromOffset()
+	ticker := time.NewTicker(watchEventsPollInterval)
+	defer ticker.Stop()
+	for {
+		items, err := s.ledgerService.ListEvents(ctx, req.GetAccountId(), offset, watchEventsBatchSize)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			if service.IsValidationError(err) {
+				return status.Errorf(codes.InvalidArgument, "watch events: %v", err)
+			}
+			return status.Errorf(codes.Internal, "watch events: %v", err)
+		}
+		for _, event := range items {
+			if err := stream.Send(toProtoEvent(event)); err != nil {
+				return err
+			}
+			offset = event.Offset
+		}
+		if len(items) == watchEventsBatchSize {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *LedgerServer) ListHistory(ctx context.Context, req *pb.ListHistoryRequest) (*pb.ListHistoryResponse, error) {
+	if req.GetAccountId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "account_id is required")
+	}
+	if req.GetEntityId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "entity_id is required")
+	}
+	if err := s.authorizeRead(ctx, req.GetAccountId(), "history"); err != nil {
+		return nil, err
+	}
+
+	items, err := s.ledgerService.ListHistory(ctx, req.GetAccountId(), req.GetEntityType(), req.GetEntityId())
+	if err != nil {
+		if service.IsValidationError(err) {
+			return nil, status.Errorf(codes.InvalidArgument, "list history: %v", err)
+		}
+		return nil, status.Errorf(codes.Internal, "list history: %v", err)
+	}
+
+	resp := &pb.ListHistoryResponse{}
+	resp.Entries = make([]*pb.AuditEntry, 0, len(items))
+	for _, entry := range items {
+		resp.Entries = append(resp.Entries, toProtoAuditEntry(entry))
+	}
+	return resp, nil
+}
+
+func (s *LedgerServer) ListDeleted(ctx context.Context, req *pb.ListDeletedRequest) (*pb.ListDeletedResponse, error) {
+	if req.GetAccountId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "account_id is required")
+	}
+	if err := s.authorizeRead(ctx, req.GetAccountId(), "trash"); err != nil {
+		return nil, err
+	}
+
+	trash, err := s.ledgerService.ListTrash(ctx, req.GetAccountId())
+	if err != nil {
+		if service.IsValidationError(err) {
+			return nil, status.Errorf(codes.InvalidArgument, "list deleted: %v", err)
+		}
+		return nil, status.Errorf(codes.Internal, "list deleted: %v", err)
+	}
+
+	resp := &pb.ListDeletedResponse{
+		Transactions: make([]*pb.Transaction, 0, len(trash.Transactions)),
+		Budgets:      make([]*pb.Budget, 0, len(trash.Budgets)),
+		Reports:      make([]*pb.Report, 0, len(trash.Reports)),
+	}
+	for _, tx := range trash.Transactions {
+		resp.Transactions = append(resp.Transactions, toProtoTransaction(tx))
+	}
+	for _, budget := range trash.Budgets {
+		resp.Budgets = append(resp.Budgets, toProtoBudget(budget))
+	}
+	for _, report := range trash.Reports {
+		resp.Reports = append(resp.Reports, toProtoReport(report))
+	}
+	return resp, nil
+}
+
+func (s *LedgerServer) Restore(ctx context.Context, req *pb.RestoreRequest) (*pb.RestoreResponse, error) {
+	if req.GetAccountId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "account_id is required")
+	}
+	if req.GetId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "id is required")
+	}
+	if err := s.authorizeWrite(ctx, req.GetAccountId()); err != nil {
+		return nil, err
+	}
+
+	resp := &pb.RestoreResponse{}
+	var err error
+	switch req.GetEntityType() {
+	case model.AuditEntityTransaction:
+		var restored model.Transaction
+		restored, err = s.ledgerService.RestoreTransaction(ctx, req.GetAccountId(), req.GetId())
+		resp.Entity = &pb.RestoreResponse_Transaction{Transaction: toProtoTransaction(restored)}
+	case model.AuditEntityBudget:
+		var restored model.Budget
+		restored, err = s.ledgerService.RestoreBudget(ctx, req.GetAccountId(), req.GetId())
+		resp.Entity = &pb.RestoreResponse_Budget{Budget: toProtoBudget(restored)}
+	case model.AuditEntityReport:
+		var restored model.Report
+		restored, err = s.ledgerService.RestoreReport(ctx, req.GetAccountId(), req.GetId())
+		resp.Entity = &pb.RestoreResponse_Report{Report: toProtoReport(restored)}
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "unsupported entity_type %q", req.GetEntityType())
+	}
+	if err != nil {
+		if service.IsNotFound(err) {
+			return nil, status.Errorf(codes.NotFound, "restore %s: %v", req.GetEntityType(), err)
+		}
+		if service.IsValidationError(err) {
+			return nil, status.Errorf(codes.InvalidArgument, "restore %s: %v", req.GetEntityType(), err)
+		}
+		if service.IsBudgetExceeded(err) || service.IsBudgetMissing(err) || service.IsTransferLeg(err) ||
+			service.IsRefundLinked(err) || service.IsRefundExceeded(err) || service.IsBudgetExists(err) {
+			return nil, status.Errorf(codes.FailedPrecondition, "restore %s: %v", req.GetEntityType(), err)
+		}
+		return nil, status.Errorf(codes.Internal, "restore %s: %v", req.GetEntityType(), err)
+	}
+	return resp, nil
+}
+
+func (s *LedgerServer) PurgeAccount(ctx context.Context, req *pb.PurgeAccountRequest) (*pb.PurgeAccountResponse, error) {
+	if req.GetAccountId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "account_id is required")
+	}
+
+	purged, err := s.ledgerService.PurgeAccount(ctx, req.GetAccountId())
+	if err != nil {
+		if service.IsValidationError(err) {
+			return nil, status.Errorf(codes.InvalidArgument, "purge account: %v", err)
+		}
+		if service.IsAccessDenied(err) {
+			return nil, status.Error(codes.PermissionDenied, err.Error())
+		}
+		return nil, status.Errorf(codes.Internal, "purge account: %v", err)
+	}
+	return &pb.PurgeAccountResponse{Purged: purged}, nil
+}
+
+func (s *LedgerServer) CreateAccount(ctx context.Context, req *pb.CreateAccountRequest) (*pb.AccountResponse, error) {
+	account, err := s.ledgerService.CreateAccount(ctx, req.GetName())
+	if err != nil {
+		if service.IsValidationError(err) {
+			return nil, status.Errorf(codes.InvalidArgument, "create account: %v", err)
+		}
+		if service.IsAccessDenied(err) {
+			return nil, status.Error(codes.PermissionDenied, err.Error())
+		}
+		return nil, status.Errorf(codes.Internal, "create account: %v", err)
+	}
+	return &pb.AccountResponse{Account: toProtoAccount(account)}, nil
+}
+
+func (s *LedgerServer) ListAccounts(ctx context.Context, req *pb.ListAccountsRequest) (*pb.ListAccountsResponse, error) {
+	items, err := s.ledgerService.ListAccounts(ctx)
+	if err != nil {
+		if service.IsValidationError(err) {
+			return nil, status.Errorf(codes.InvalidArgument, "list accounts: %v", err)
+		}
+		if service.IsAccessDenied(err) {
+			return nil, status.Error(codes.PermissionDenied, err.Error())
+		}
+		return nil, status.Errorf(codes.Internal, "list accounts: %v", err)
+	}
+
+	resp := &pb.ListAccountsResponse{}
+	resp.Accounts = make([]*pb.Account, 0, len(items))
+	for _, account := range items {
+		resp.Accounts = append(resp.Accounts, toProtoAccount(account))
+	}
+	return resp, nil
+}
+
+func (s *LedgerServer) ListMembers(ctx context.Context, req *pb.ListMembersRequest) (*pb.ListMembersResponse, error) {
+	items, err := s.ledgerService.ListMembers(ctx, req.GetAccountId())
+	if err != nil {
+		return nil, membershipError("list members", err)
+	}
+
+	resp := &pb.ListMembersResponse{}
+	resp.Members = make([]*pb.AccountMember, 0, len(items))
+	for _, member := range items {
+		resp.Members = append(resp.Members, toProtoAccountMember(member))
+	}
+	return resp, nil
+}
+
+func (s *LedgerServer) UpdateMember(ctx context.Context, req *pb.UpdateMemberRequest) (*pb.MemberResponse, error) {
+	member, err := s.ledgerService.UpdateMember(ctx, req.GetAccountId(), req.GetUserId(), req.GetRole())
+	if err != nil {
+		return nil, membershipError("update member", err)
+	}
+	return &pb.MemberResponse{Member: toProtoAccountMember(member)}, nil
+}
+
+func (s *LedgerServer) RemoveMember(ctx context.Context, req *pb.RemoveMemberRequest) (*pb.DeleteResponse, error) {
+	if err := s.ledgerService.RemoveMember(ctx, req.GetAccountId(), req.GetUserId()); err != nil {
+		if service.IsNotFound(err) {
+			return &pb.DeleteResponse{Deleted: false}, nil
+		}
+		return nil, membershipError("remove member", err)
+	}
+	return &pb.DeleteResponse{Deleted: true}, nil
+}
+
+func (s *LedgerServer) CreateInvitation(ctx context.Context, req *pb.CreateInvitationRequest) (*pb.CreateInvitationResponse, error) {
+	invitation, token, err := s.ledgerService.CreateInvitation(ctx, req.GetAccountId(), req.GetRole())
+	if err != nil {
+		return nil, membershipError("create invitation", err)
+	}
+	return &pb.CreateInvitationResponse{Invitation: toProtoInvitation(invitation), Token: token}, nil
+}
+
+func (s *LedgerServer) ListInvitations(ctx context.Context, req *pb.ListInvitationsRequest) (*pb.ListInvitationsResponse, error) {
+	items, err := s.ledgerService.ListInvitations(ctx, req.GetAccountId())
+	if err != nil {
+		return nil, membershipError("list invitations", err)
+	}
+
+	resp := &pb.ListInvitationsResponse{}
+	resp.Invitations = make([]*pb.Invitation, 0, len(items))
+	for _, invitation := range items {
+		resp.Invitations = append(resp.Invitations, toProtoInvitation(invitation))
+	}
+	return resp, nil
+}
+
+func (s *LedgerServer) RevokeInvitation(ctx context.Context, req *pb.RevokeInvitationRequest) (*pb.DeleteResponse, error) {
+	if err := s.ledgerService.RevokeInvitation(ctx, req.GetAccountId(), req.GetId()); err != nil {
+		if service.IsNotFound(err) {
+			return &pb.DeleteResponse{Deleted: false}, nil
+		}
+		return nil, membershipError("revoke invitation", err)
+	}
+	return &pb.DeleteResponse{Deleted: true}, nil
+}
+
+func (s *LedgerServer) AcceptInvitation(ctx context.Context, req *pb.AcceptInvitationRequest) (*pb.MemberResponse, error) {
+	member, err := s.ledgerService.AcceptInvitation(ctx, req.GetToken())
+	if err != nil {
+		return nil, membershipError("accept invitation", err)
+	}
+	return &pb.MemberResponse{Member: toProtoAccountMember(member)}, nil
+}
+
+// membershipError maps errors of the account membership operations to codes.
+func membershipError(operation string, err error) error {
+	switch {
+	case service.IsValidationError(err):
+		return status.Errorf(codes.InvalidArgument, "%s: %v", operation, err)
+	case service.IsAccessDenied(err):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case service.IsNotFound(err):
+		return status.Errorf(codes.NotFound, "%s: account or member not found", operation)
+	case service.IsInvalidInvitation(err):
+		return status.Errorf(codes.NotFound, "%s: %v", operation, err)
+	case service.IsAlreadyMember(err):
+		return status.Errorf(codes.AlreadyExists, "%s: %v", operation, err)
+	case service.IsLastOwner(err):
+		return status.Errorf(codes.FailedPrecondition, "%s: %v", operation, err)
+	default:
+		return status.Errorf(codes.Internal, "%s: %v", operation, err)
+	}
+}
+
+// walletError maps errors of the wallet and transfer operations to codes.
+func walletError(operation string, err error) error {
+	switch {
+	case service.IsValidationError(err):
+		return status.Errorf(codes.InvalidArgument, "%s: %v", operation, err)
+	case service.IsNotFound(err):
+		return status.Errorf(codes.NotFound, "%s: wallet not found", operation)
+	case service.IsWalletInUse(err):
+		return status.Errorf(codes.FailedPrecondition, "%s: %v", operation, err)
+	default:
+		return status.Errorf(codes.Internal, "%s: %v", operation, err)
+	}
+}
+
+// tagError maps errors of the tag operations to codes.
+func tagError(operation string, err error) error {
+	switch {
+	case service.IsValidationError(err):
+		return status.Errorf(codes.InvalidArgument, "%s: %v", operation, err)
+	case service.IsNotFound(err):
+		return status.Errorf(codes.NotFound, "%s: tag not found", operation)
+	case service.IsTagExists(err):
+		return status.Errorf(codes.AlreadyExists, "%s: %v", operation, err)
+	default:
+		return status.Errorf(codes.Internal, "%s: %v", operation, err)
+	}
+}
+
+// attachmentError maps errors of the attachment operations to codes. Not
+// found covers both the attachment and the transaction it is uploaded to.
+func attachmentError(operation string, err error) error {
+	switch {
+	case service.IsValidationError(err):
+		return status.Errorf(codes.InvalidArgument, "%s: %v", operation, err)
+	case service.IsNotFound(err):
+		return status.Errorf(codes.NotFound, "%s: %v", operation, err)
+	default:
+		return status.Errorf(codes.Internal, "%s: %v", operation, err)
+	}
+}
+
+// payeeError maps errors of the payee operations to codes.
+func payeeError(operation string, err error) error {
+	switch {
+	case service.IsValidationError(err):
+		return status.Errorf(codes.InvalidArgument, "%s: %v", operation, err)
+	case service.IsNotFound(err):
+		return status.Errorf(codes.NotFound, "%s: payee not found", operation)
+	case service.IsPayeeExists(err):
+		return status.Errorf(codes.AlreadyExists, "%s: %v", operation, err)
+	default:
+		return status.Errorf(codes.Internal, "%s: %v", operation, err)
+	}
+}
+
+// goalError maps errors of the goal operations to codes.
+func goalError(operation string, err error) error {
+	switch {
+	case service.IsValidationError(err):
+		return status.Errorf(codes.InvalidArgument, "%s: %v", operation, err)
+	case service.IsNotFound(err):
+		return status.Errorf(codes.NotFound, "%s: goal not found", operation)
+	default:
+		return status.Errorf(codes.Internal, "%s: %v", operation, err)
+	}
+}
+
+// ruleError maps errors of the rule operations to codes.
+func ruleError(operation string, err error) error {
+	switch {
+	case service.IsValidationError(err):
+		return status.Errorf(codes.InvalidArgument, "%s: %v", operation, err)
+	case service.IsNotFound(err):
+		return status.Errorf(codes.NotFound, "%s: rule not found", operation)
+	default:
+		return status.Errorf(codes.Internal, "%s: %v", operation, err)
+	}
+}
+
+// authorizeRead checks that the caller may read the account; reads of other
+// accounts by staff are audited by the service.
+func (s *LedgerServer) authorizeRead(ctx context.Context, accountID, resource string) error {
+	if err := s.ledgerService.AuthorizeRead(ctx, accountID, resource); err != nil {
+		if service.IsAccessDenied(err) {
+			return status.Error(codes.PermissionDenied, err.Error())
+		}
+		return status.Errorf(codes.Internal, "authorize read: %v", err)
+	}
+	return nil
+}
+
+// authorizeWrite checks that the caller may change the data of the account.
+func (s *LedgerServer) authorizeWrite(ctx context.Context, accountID string) error {
+	if err := s.ledgerService.AuthorizeWrite(ctx, accountID); err != nil {
+		if service.IsAccessDenied(err) {
+			return status.Error(codes.PermissionDenied, err.Error())
+		}
+		return status.Errorf(codes.Internal, "authorize write: %v", err)
+	}
+	return nil
+}
+
+// authorizeTransactionWrite checks that the caller may change the account of
+// an existing transaction. Missing transactions are NotFound.
+func (s *LedgerServer) authorizeTransactionWrite(ctx context.Context, id string) error {
+	if id == "" {
+		return status.Error(codes.InvalidArgument, "id is required")
+	}
+	current, err := s.ledgerService.GetTransaction(ctx, id)
+	if err != nil {
+		if service.IsNotFound(err) {
+			return status.Error(codes.NotFound, "transaction not found")
+		}
+		return status.Errorf(codes.Internal, "get transaction: %v", err)
+	}
+	return s.authorizeWrite(ctx, current.AccountID)
+}
+
+func toProtoBatchResponse(results []model.BatchResult) *pb.BatchTransactionsResponse {
+	resp := &pb.BatchTransactionsResponse{Results: make([]*pb.BatchItemResult, 0, len(results))}
+	for _, result := range results {
+		item := &pb.BatchItemResult{Index: int32(result.Index), Code: codes.OK.String()}
+		if result.Err != nil {
+			item.Code = batchErrorCode(result.Err).String()
+			item.Error = result.Err.Error()
+			resp.Failed++
+		} else {
+			item.Transaction = toProtoTransaction(result.Transaction)
+			resp.Succeeded++
+		}
+		resp.Results = append(resp.Results, item)
+	}
+	return resp
+}
+
+func batchErrorCode(err error) codes.Code {
+	switch {
+	case service.IsValidationError(err):
+		return codes.InvalidArgument
+	case service.IsNotFound(err):
+		return codes.NotFound
+	case service.IsBudgetExceeded(err), service.IsBudgetMissing(err), service.IsTransferLeg(err),
+		service.IsRefundLinked(err), service.IsRefundExceeded(err):
+		return codes.FailedPrecondition
+	case service.IsTransactionExists(err):
+		return codes.AlreadyExists
+	case service.IsBatchAborted(err):
+		return codes.Aborted
+	default:
+		return codes.Internal
+	}
+}
+
+func toProtoAuditEntry(entry model.AuditEntry) *pb.AuditEntry {
+	return &pb.AuditEntry{
+		Id:         entry.ID,
+		AccountId:  entry.AccountID,
+		ActorId:    entry.ActorID,
+		EntityType: entry.EntityType,
+		EntityId:   entry.EntityID,
+		Operation:  entry.Operation,
+		Before:     entry.Before,
+		After:      entry.After,
+		CreatedAt:  timestamppb.New(entry.CreatedAt),
+	}
+}
+
+func toProtoAccount(account model.Account) *pb.Account {
+	resp := &pb.Account{
+		Id:        account.ID,
+		Name:      account.Name,
+		CreatedBy: account.CreatedBy,
+		Role:      account.Role,
+		Personal:  account.Personal,
+	}
+	if !account.CreatedAt.IsZero() {
+		resp.CreatedAt = timestamppb.New(account.CreatedAt)
+	}
+	return resp
+}
+
+func toProtoAccountMember(member model.AccountMember) *pb.AccountMember {
+	return &pb.AccountMember{
+		AccountId: member.AccountID,
+		UserId:    member.UserID,
+		Role:      member.Role,
+		CreatedAt: timestamppb.New(member.CreatedAt),
+	}
+}
+
+func toProtoInvitation(invitation model.Invitation) *pb.Invitation {
+	return &pb.Invitation{
+		Id:        invitation.ID,
+		AccountId: invitation.AccountID,
+		Role:      invitation.Role,
+		CreatedBy: invitation.CreatedBy,
+		CreatedAt: timestamppb.New(invitation.CreatedAt),
+		ExpiresAt: timestamppb.New(invitation.ExpiresAt),
+	}
+}
+
+func toProtoEvent(event model.Event) *pb.LedgerEvent {
+	return &pb.LedgerEvent{
+		Offset:      event.Offset,
+		Id:          event.ID,
+		AccountId:   event.AccountID,
+		Type:        event.Type,
+		AggregateId: event.AggregateID,
+		Payload:     event.Payload,
+		OccurredAt:  timestamppb.New(event.OccurredAt),
+	}
+}
+
+func toModelTransaction(tx *pb.Transaction) model.Transaction {
+	return model.Transaction{
+		ID:          tx.GetId(),
+		AccountID:   tx.GetAccountId(),
+		Amount:      tx.GetAmount(),
+		Currency:    tx.GetCurrency(),
+		Category:    tx.GetCategory(),
+		Description: tx.GetDescription(),
+		OccurredAt:  toTime(tx.GetOccurredAt()),
+		CreatedAt:   toTime(tx.GetCreatedAt()),
+		UpdatedAt:   toTime(tx.GetUpdatedAt()),
+		WalletID:    tx.GetWalletId(),
+		Splits:      toModelSplits(tx.GetSplits()),
+		Tags:        tx.GetTags(),
+		PayeeID:     tx.GetPayeeId(),
+	}
+}
+
+func toProtoTransaction(tx model.Transaction) *pb.Transaction {
+	return &pb.Transaction{
+		Id:          tx.ID,
+		AccountId:   tx.AccountID,
+		Amount:      tx.Amount,
+		Currency:    tx.Currency,
+		Category:    tx.Category,
+		Description: tx.Description,
+		OccurredAt:  timestamppb.New(tx.OccurredAt),
+		CreatedAt:   timestamppb.New(tx.CreatedAt),
+		UpdatedAt:   timestamppb.New(tx.UpdatedAt),
+		DeletedAt:   toProtoTimestamp(tx.DeletedAt),
+		CreatedBy:   tx.CreatedBy,
+		WalletId:    tx.WalletID,
+		TransferId:  tx.TransferID,
+		RefundOf:    tx.RefundOf,
+		Splits:      toProtoSplits(tx.Splits),
+		Tags:        tx.Tags,
+		PayeeId:     tx.PayeeID,
+	}
+}
+
+func toModelSplits(splits []*pb.TransactionSplit) []model.TransactionSplit {
+	if len(splits) == 0 {
+		return nil
+	}
+	items := make([]model.TransactionSplit, 0, len(splits))
+	for _, split := range splits {
+		items = append(items, model.TransactionSplit{Category: split.GetCategory(), Amount: split.GetAmount()})
+	}
+	return items
+}
+
+func toProtoSplits(splits []model.TransactionSplit) []*pb.TransactionSplit {
+	if len(splits) == 0 {
+		return nil
+	}
+	items := make([]*pb.TransactionSplit, 0, len(splits))
+	for _, split := range splits {
+		items = append(items, &pb.TransactionSplit{Category: split.Category, Amount: split.Amount})
+	}
+	return items
+}
+
+func toModelWallet(wallet *pb.Wallet) model.Wallet {
+	return model.Wallet{
+		ID:        wallet.GetId(),
+		AccountID: wallet.GetAccountId(),
+		Name:      wallet.GetName(),
+		Type:      wallet.GetType(),
+		Currency:  wallet.GetCurrency(),
+	}
+}
+
+func toProtoWallet(wallet model.Wallet) *pb.Wallet {
+	return &pb.Wallet{
+		Id:        wallet.ID,
+		AccountId: wallet.AccountID,
+		Name:      wallet.Name,
+		Type:      wallet.Type,
+		Currency:  wallet.Currency,
+		Balance:   wallet.Balance,
+		CreatedAt: timestamppb.New(wallet.CreatedAt),
+		UpdatedAt: timestamppb.New(wallet.UpdatedAt),
+	}
+}
+
+func toModelTag(tag *pb.Tag) model.Tag {
+	return model.Tag{
+		ID:        tag.GetId(),
+		AccountID: tag.GetAccountId(),
+		Name:      tag.GetName(),
+	}
+}
+
+func toProtoTag(tag model.Tag) *pb.Tag {
+	return &pb.Tag{
+		Id:        tag.ID,
+		AccountId: tag.AccountID,
+		Name:      tag.Name,
+		CreatedAt: timestamppb.New(tag.CreatedAt),
+		UpdatedAt: timestamppb.New(tag.UpdatedAt),
+	}
+}
+
+func toModelAttachment(attachment *pb.Attachment) model.Attachment {
+	return model.Attachment{
+		AccountID:     attachment.GetAccountId(),
+		TransactionID: attachment.GetTransactionId(),
+		FileName:      attachment.GetFileName(),
+	}
+}
+
+func toProtoAttachment(attachment model.Attachment) *pb.Attachment {
+	return &pb.Attachment{
+		Id:            attachment.ID,
+		AccountId:     attachment.AccountID,
+		TransactionId: attachment.TransactionID,
+		FileName:      attachment.FileName,
+		ContentType:   attachment.ContentType,
+		Size:          attachment.Size,
+		Checksum:      attachment.Checksum,
+		CreatedBy:     attachment.CreatedBy,
+		CreatedAt:     timestamppb.New(attachment.CreatedAt),
+	}
+}
+
+func toModelRule(rule *pb.Rule) model.CategoryRule {
+	result := model.CategoryRule{
+		ID:                  rule.GetId(),
+		AccountID:           rule.GetAccountId(),
+		Name:                rule.GetName(),
+		Priority:            int(rule.GetPriority()),
+		DescriptionContains: rule.GetDescriptionContains(),
+		DescriptionPattern:  rule.GetDescriptionPattern(),
+		Type:                rule.GetType(),
+		Currency:            rule.GetCurrency(),
+		Category:            rule.GetCategory(),
+		Enabled:             rule.GetEnabled(),
+	}
+	if rule.GetMinAmount() != nil {
+		value := rule.GetMinAmount().GetValue()
+		result.MinAmount = &value
+	}
+	if rule.GetMaxAmount() != nil {
+		value := rule.GetMaxAmount().GetValue()
+		result.MaxAmount = &value
+	}
+	return result
+}
+
+func toProtoRule(rule model.CategoryRule) *pb.Rule {
+	result := &pb.Rule{
+		Id:                  rule.ID,
+		AccountId:           rule.AccountID,
+		Name:                rule.Name,
+		Priority:            int32(rule.Priority),
+		DescriptionContains: rule.DescriptionContains,
+		DescriptionPattern:  rule.DescriptionPattern,
+		Type:                rule.Type,
+		Currency:            rule.Currency,
+		Category:            rule.Category,
+		Enabled:             rule.Enabled,
+		CreatedAt:           timestamppb.New(rule.CreatedAt),
+		UpdatedAt:           timestamppb.New(rule.UpdatedAt),
+	}
+	if rule.MinAmount != nil {
+		result.MinAmount = wrapperspb.Double(*rule.MinAmount)
+	}
+	if rule.MaxAmount != nil {
+		result.MaxAmount = wrapperspb.Double(*rule.MaxAmount)
+	}
+	return result
+}
+
+func toProtoRuleMatches(matches []model.RuleMatch) *pb.RuleMatchesResponse {
+	resp := &pb.RuleMatchesResponse{Matches: make([]*pb.RuleMatch, 0, len(matches))}
+	for _, match := range matches {
+		resp.Matches = append(resp.Matches, &pb.RuleMatch{
+			Transaction: toProtoTransaction(match.Transaction),
+			RuleId:      match.RuleID,
+			Category:    match.Category,
+		})
+	}
+	return resp
+}
+
+func toModelPayee(payee *pb.Payee) model.Payee {
+	return model.Payee{
+		ID:              payee.GetId(),
+		AccountID:       payee.GetAccountId(),
+		Name:            payee.GetName(),
+		Aliases:         payee.GetAliases(),
+		DefaultCategory: payee.GetDefaultCategory(),
+	}
+}
+
+func toProtoPayee(payee model.Payee) *pb.Payee {
+	return &pb.Payee{
+		Id:              payee.ID,
+		AccountId:       payee.AccountID,
+		Name:            payee.Name,
+		Aliases:         payee.Aliases,
+		DefaultCategory: payee.DefaultCategory,
+		CreatedAt:       timestamppb.New(payee.CreatedAt),
+		UpdatedAt:       timestamppb.New(payee.UpdatedAt),
+	}
+}
+
+func toModelGoal(goal *pb.Goal) model.Goal {
+	return model.Goal{
+		ID:           goal.GetId(),
+		AccountID:    goal.GetAccountId(),
+		Name:         goal.GetName(),
+		TargetAmount: goal.GetTargetAmount(),
+		Currency:     goal.GetCurrency(),
+		Deadline:     toTime(goal.GetDeadline()),
+		Category:     goal.GetCategory(),
+		WalletID:     goal.GetWalletId(),
+		Tag:          goal.GetTag(),
+	}
+}
+
+func toProtoGoal(goal model.Goal) *pb.Goal {
+	return &pb.Goal{
+		Id:           goal.ID,
+		AccountId:    goal.AccountID,
+		Name:         goal.Name,
+		TargetAmount: goal.TargetAmount,
+		Currency:     goal.Currency,
+		Deadline:     timestamppb.New(goal.Deadline),
+		Category:     goal.Category,
+		WalletId:     goal.WalletID,
+		Tag:          goal.Tag,
+		Progress:     toProtoGoalProgress(goal.Progress),
+		CreatedAt:    timestamppb.New(goal.CreatedAt),
+		UpdatedAt:    timestamppb.New(goal.UpdatedAt),
+	}
+}
+
+func toModelGoalProgress(progress *pb.GoalProgress) model.GoalProgress {
+	return model.GoalProgress{
+		Saved:           progress.GetSaved(),
+		Remaining:       progress.GetRemaining(),
+		Percent:         progress.GetPercent(),
+		MonthsLeft:      int(progress.GetMonthsLeft()),
+		MonthlyRequired: progress.GetMonthlyRequired(),
+	}
+}
+
+func toProtoGoalProgress(progress model.GoalProgress) *pb.GoalProgress {
+	return &pb.GoalProgress{
+		Saved:           progress.Saved,
+		Remaining:       progress.Remaining,
+		Percent:         progress.Percent,
+		MonthsLeft:      int32(progress.MonthsLeft),
+		MonthlyRequired: progress.MonthlyRequired,
+	}
+}
+
+func toModelTransfer(transfer *pb.Transfer) model.Transfer {
+	return model.Transfer{
+		ID:           transfer.GetId(),
+		AccountID:    transfer.GetAccountId(),
+		FromWalletID: transfer.GetFromWalletId(),
+		ToWalletID:   transfer.GetToWalletId(),
+		Amount:       transfer.GetAmount(),
+		ToAmount:     transfer.GetToAmount(),
+		Description:  transfer.GetDescription(),
+		OccurredAt:   toTime(transfer.GetOccurredAt()),
+	}
+}
+
+func toProtoTransfer(transfer model.Transfer) *pb.Transfer {
+	return &pb.Transfer{
+		Id:           transfer.ID,
+		AccountId:    transfer.AccountID,
+		FromWalletId: transfer.FromWalletID,
+		ToWalletId:   transfer.ToWalletID,
+		Amount:       transfer.Amount,
+		ToAmount:     transfer.ToAmount,
+		Description:  transfer.Description,
+		OccurredAt:   timestamppb.New(transfer.OccurredAt),
+		CreatedBy:    transfer.CreatedBy,
+	}
+}
+
+func toProtoJournalEntry(entry model.JournalEntry) *pb.JournalEntry {
+	resp := &pb.JournalEntry{
+		TransactionId: entry.TransactionID,
+		AccountId:     entry.AccountID,
+		OccurredAt:    timestamppb.New(entry.OccurredAt),
+		Description:   entry.Description,
+		Postings:      make([]*pb.Posting, 0, len(entry.Postings)),
+		PostedAt:      timestamppb.New(entry.PostedAt),
+	}
+	for _, posting := range entry.Postings {
+		resp.Postings = append(resp.Postings, &pb.Posting{
+			Account:  posting.Account,
+			Type:     posting.Type,
+			Currency: posting.Currency,
+			Amount:   posting.Amount,
+		})
+	}
+	return resp
+}
+
+func toProtoTrialBalance(balance model.TrialBalance) *pb.TrialBalance {
+	resp := &pb.TrialBalance{
+		AccountId: balance.AccountID,
+		Lines:     make([]*pb.TrialBalanceLine, 0, len(balance.Lines)),
+		Totals:    make([]*pb.TrialBalanceTotal, 0, len(balance.Totals)),
+	}
+	for _, line := range balance.Lines {
+		resp.Lines = append(resp.Lines, &pb.TrialBalanceLine{
+			Account:  line.Account,
+			Type:     line.Type,
+			Currency: line.Currency,
+			Debit:    line.Debit,
+			Credit:   line.Credit,
+		})
+	}
+	for _, total := range balance.Totals {
+		resp.Totals = append(resp.Totals, &pb.TrialBalanceTotal{Currency: total.Currency, Debit: total.Debit, Credit: total.Credit})
+	}
+	return resp
+}
+
+func toModelBudget(budget *pb.Budget) model.Budget {
+	return model.Budget{
+		ID:        budget.GetId(),
+		AccountID: budget.GetAccountId(),
+		Name:      budget.GetName(),
+		Amount:    budget.GetAmount(),
+		Currency:  budget.GetCurrency(),
+		Period:    budget.GetPeriod(),
+		Month:     toTime(budget.GetMonth()),
+		CreatedAt: toTime(budget.GetCreatedAt()),
+		UpdatedAt: toTime(budget.GetUpdatedAt()),
+	}
+}
+
+func toProtoBudget(budget model.Budget) *pb.Budget {
+	return &pb.Budget{
+		Id:        budget.ID,
+		AccountId: budget.AccountID,
+		Name:      budget.Name,
+		Amount:    budget.Amount,
+		Currency:  budget.Currency,
+		Period:    budget.Period,
+		Month:     timestamppb.New(budget.Month),
+		CreatedAt: timestamppb.New(budget.CreatedAt),
+		UpdatedAt: timestamppb.New(budget.UpdatedAt),
+		DeletedAt: toProtoTimestamp(budget.DeletedAt),
+	}
+}
+
+func toModelReport(report *pb.Report) model.Report {
+	categories := make([]model.ReportCategory, 0, len(report.GetCategories()))
 	for _, category := range report.GetCategories() {
 		if category == nil {
 			continue
 		}
 		categories = append(categories, toModelReportCategory(category))
 	}
+	tags := make([]model.ReportTag, 0, len(report.GetTags()))
+	for _, tag := range report.GetTags() {
+		if tag == nil {
+			continue
+		}
+		tags = append(tags, model.ReportTag{Tag: tag.GetTag(), TotalIncome: tag.GetTotalIncome(), TotalExpense: tag.GetTotalExpense()})
+	}
+	payees := make([]model.ReportPayee, 0, len(report.GetPayees()))
+	for _, payee := range report.GetPayees() {
+		if payee == nil {
+			continue
+		}
+		payees = append(payees, model.ReportPayee{PayeeID: payee.GetPayeeId(), Name: payee.GetName(), TotalExpense: payee.GetTotalExpense(), Transactions: int(payee.GetTransactions())})
+	}
+	goals := make([]model.ReportGoal, 0, len(report.GetGoals()))
+	for _, goal := range report.GetGoals() {
+		if goal == nil {
+			continue
+		}
+		goals = append(goals, model.ReportGoal{
+			GoalID:       goal.GetGoalId(),
+			Name:         goal.GetName(),
+			TargetAmount: goal.GetTargetAmount(),
+			Currency:     goal.GetCurrency(),
+			Deadline:     toTime(goal.GetDeadline()),
+			Progress:     toModelGoalProgress(goal.GetProgress()),
+		})
+	}
 	return model.Report{
 		ID:           report.GetId(),
 		AccountID:    report.GetAccountId(),
@@ -394,6 +2184,9 @@ func toModelReport(report *pb.Report) model.Report {
 		TotalExpense: report.GetTotalExpense(),
 		Currency:     report.GetCurrency(),
 		Categories:   categories,
+		Tags:         tags,
+		Payees:       payees,
+		Goals:        goals,
 	}
 }
 
@@ -402,6 +2195,25 @@ func toProtoReport(report model.Report) *pb.Report {
 	for _, category := range report.Categories {
 		categories = append(categories, toProtoReportCategory(category))
 	}
+	tags := make([]*pb.ReportTag, 0, len(report.Tags))
+	for _, tag := range report.Tags {
+		tags = append(tags, &pb.ReportTag{Tag: tag.Tag, TotalIncome: tag.TotalIncome, TotalExpense: tag.TotalExpense})
+	}
+	payees := make([]*pb.ReportPayee, 0, len(report.Payees))
+	for _, payee := range report.Payees {
+		payees = append(payees, &pb.ReportPayee{PayeeId: payee.PayeeID, Name: payee.Name, TotalExpense: payee.TotalExpense, Transactions: int32(payee.Transactions)})
+	}
+	goals := make([]*pb.ReportGoal, 0, len(report.Goals))
+	for _, goal := range report.Goals {
+		goals = append(goals, &pb.ReportGoal{
+			GoalId:       goal.GoalID,
+			Name:         goal.Name,
+			TargetAmount: goal.TargetAmount,
+			Currency:     goal.Currency,
+			Deadline:     timestamppb.New(goal.Deadline),
+			Progress:     toProtoGoalProgress(goal.Progress),
+		})
+	}
 	return &pb.Report{
 		Id:           report.ID,
 		AccountId:    report.AccountID,
@@ -412,6 +2224,10 @@ func toProtoReport(report model.Report) *pb.Report {
 		TotalExpense: report.TotalExpense,
 		Currency:     report.Currency,
 		Categories:   categories,
+		DeletedAt:    toProtoTimestamp(report.DeletedAt),
+		Tags:         tags,
+		Payees:       payees,
+		Goals:        goals,
 	}
 }
 
@@ -448,3 +2264,10 @@ func toTime(ts *timestamppb.Timestamp) time.Time {
 	}
 	return ts.AsTime()
 }
+
+func toProtoTimestamp(value *time.Time) *timestamppb.Timestamp {
+	if value == nil {
+		return nil
+	}
+	return timestamppb.New(*value)
+}
@@ -0,0 +1,43 @@
+package model
+
+import "time"
+
+// Member roles of a shared account, from least to most privileged.
+const (
+	MemberRoleViewer = "viewer"
+	MemberRoleEditor = "editor"
+	MemberRoleOwner  = "owner"
+)
+
+// Account is a ledger shared by its members. Every user also has a personal
+// account whose ID is the user ID; it has no row and no other members.
+type Account struct {
+	ID        string
+	Name      string
+	CreatedBy string
+	CreatedAt time.Time
+	// Role is the role of the user the account was listed for.
+	Role     string
+	Personal bool
+}
+
+type AccountMember struct {
+	AccountID string
+	UserID    string
+	Role      string
+	CreatedAt time.Time
+}
+
+// Invitation lets whoever holds its token join the account with the role.
+// Only the token hash is stored; an invitation is accepted at most once.
+type Invitation struct {
+	ID         string
+	AccountID  string
+	TokenHash  string
+	Role       string
+	CreatedBy  string
+	CreatedAt  time.Time
+	ExpiresAt  time.Time
+	AcceptedBy string
+	AcceptedAt *time.Time
+}
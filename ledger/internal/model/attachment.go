@@ -0,0 +1,19 @@
+package model
+
+import "time"
+
+// Attachment is a file attached to a transaction, such as a receipt photo or
+// an invoice. The content lives in a blob store under StorageKey.
+type Attachment struct {
+	ID            string `json:"id"`
+	AccountID     string `json:"account_id"`
+	TransactionID string `json:"transaction_id"`
+	FileName      string `json:"file_name"`
+	ContentType   string `json:"content_type"`
+	Size          int64  `json:"size"`
+	// Checksum is the hex-encoded SHA-256 of the content.
+	Checksum   string    `json:"checksum"`
+	StorageKey string    `json:"storage_key"`
+	CreatedBy  string    `json:"created_by"`
+	CreatedAt  time.Time `json:"created_at"`
+}
@@ -0,0 +1,41 @@
+package model
+
+import "time"
+
+const (
+	AuditEntityTransaction = "transaction"
+	AuditEntityBudget      = "budget"
+	AuditEntityReport      = "report"
+	AuditEntityWallet      = "wallet"
+	AuditEntityTag         = "tag"
+	AuditEntityAttachment  = "attachment"
+	AuditEntityRule        = "rule"
+	AuditEntityPayee       = "payee"
+	AuditEntityGoal        = "goal"
+	// AuditEntityAccount entries record reads of an account by staff; the
+	// entity ID is the account ID.
+	AuditEntityAccount = "account"
+	// AuditEntityMember entries record membership changes of a shared
+	// account; the entity ID is the user ID of the member.
+	AuditEntityMember = "member"
+
+	AuditOperationCreate  = "create"
+	AuditOperationUpdate  = "update"
+	AuditOperationDelete  = "delete"
+	AuditOperationRestore = "restore"
+	AuditOperationRead    = "read"
+)
+
+// AuditEntry is an append-only record of a single ledger mutation or staff read.
+// Before is empty for creations, restores and reads, After is empty for deletions.
+type AuditEntry struct {
+	ID         string
+	AccountID  string
+	ActorID    string
+	EntityType string
+	EntityID   string
+	Operation  string
+	Before     []byte
+	After      []byte
+	CreatedAt  time.Time
+}
@@ -0,0 +1,9 @@
+package model
+
+// BatchResult is the outcome of a single item of a batch operation.
+// Index refers to the item's position in the request; Err is nil on success.
+type BatchResult struct {
+	Index       int
+	Transaction Transaction
+	Err         error
+}
@@ -0,0 +1,31 @@
+package model
+
+import "time"
+
+const (
+	EventTransactionCreated  = "TransactionCreated"
+	EventTransactionUpdated  = "TransactionUpdated"
+	EventTransactionDeleted  = "TransactionDeleted"
+	EventTransactionRestored = "TransactionRestored"
+	EventBudgetChanged       = "BudgetChanged"
+	EventWalletChanged       = "WalletChanged"
+	EventTagChanged          = "TagChanged"
+	EventAttachmentChanged   = "AttachmentChanged"
+	EventRuleChanged         = "RuleChanged"
+	EventPayeeChanged        = "PayeeChanged"
+	EventGoalChanged         = "GoalChanged"
+	EventReportGenerated     = "ReportGenerated"
+	EventAccountPurged       = "AccountPurged"
+)
+
+// Event is a domain event recorded in the outbox together with the write that produced it.
+// Offset is assigned by the repository and grows monotonically.
+type Event struct {
+	Offset      int64
+	ID          string
+	AccountID   string
+	Type        string
+	AggregateID string
+	Payload     []byte
+	OccurredAt  time.Time
+}
@@ -0,0 +1,39 @@
+package model
+
+import "time"
+
+// Goal is a savings target of an account. Contributions are booked in
+// Category or marked with Tag, as expenses moving money aside, or kept in the
+// wallet WalletID; exactly one of the three is set. Progress is computed from
+// the live transactions in Currency and is not stored.
+type Goal struct {
+	ID           string  `json:"id"`
+	AccountID    string  `json:"account_id"`
+	Name         string  `json:"name"`
+	TargetAmount float64 `json:"target_amount"`
+	Currency     string  `json:"currency"`
+	// Deadline is the date the target should be reached by.
+	Deadline  time.Time    `json:"deadline"`
+	Category  string       `json:"category"`
+	WalletID  string       `json:"wallet_id"`
+	Tag       string       `json:"tag"`
+	Progress  GoalProgress `json:"progress"`
+	CreatedAt time.Time    `json:"created_at"`
+	UpdatedAt time.Time    `json:"updated_at"`
+}
+
+// GoalProgress is the state of a goal at a point in time and the savings rate
+// required to reach its target by the deadline.
+type GoalProgress struct {
+	// Saved sums the contributions: expenses in the category or with the tag
+	// net of refunds and withdrawals, or the balance of the wallet.
+	Saved     float64 `json:"saved"`
+	Remaining float64 `json:"remaining"`
+	Percent   float64 `json:"percent"`
+	// MonthsLeft counts the calendar months up to the deadline, the current
+	// and the deadline month included. It is zero once the deadline passed.
+	MonthsLeft int `json:"months_left"`
+	// MonthlyRequired is Remaining spread evenly over MonthsLeft, rounded up
+	// to cents; the whole Remaining once the deadline passed.
+	MonthlyRequired float64 `json:"monthly_required"`
+}
@@ -0,0 +1,59 @@
+package model
+
+import "time"
+
+// Types of the journal accounts.
+const (
+	JournalAccountAsset     = "asset"
+	JournalAccountLiability = "liability"
+	JournalAccountIncome    = "income"
+	JournalAccountExpense   = "expense"
+	JournalAccountEquity    = "equity"
+)
+
+// JournalEntry records one transaction in the double-entry journal. Entries
+// are derived from transactions: the ledger posts, replaces and removes them
+// together with the transaction they belong to, so the journal always covers
+// exactly the live transactions.
+type JournalEntry struct {
+	TransactionID string
+	AccountID     string
+	OccurredAt    time.Time
+	Description   string
+	Postings      []Posting
+	PostedAt      time.Time
+}
+
+// Posting moves Amount into one journal account: debits are positive and
+// credits negative. The postings of an entry sum up to zero per currency.
+type Posting struct {
+	Account  string
+	Type     string
+	Currency string
+	Amount   float64
+}
+
+// TrialBalance lists the balances of the journal accounts of an account.
+// Debits and credits match per currency in a consistent journal.
+type TrialBalance struct {
+	AccountID string
+	Lines     []TrialBalanceLine
+	Totals    []TrialBalanceTotal
+}
+
+// TrialBalanceLine is the balance of one journal account in one currency.
+// Exactly one of Debit and Credit is set for a non-zero balance.
+type TrialBalanceLine struct {
+	Account  string
+	Type     string
+	Currency string
+	Debit    float64
+	Credit   float64
+}
+
+// TrialBalanceTotal sums up the trial balance lines of one currency.
+type TrialBalanceTotal struct {
+	Currency string
+	Debit    float64
+	Credit   float64
+}
@@ -3,53 +3,129 @@ package model
 import "time"
 
 type Transaction struct {
-	ID          string
-	AccountID   string
-	Amount      float64
-	Currency    string
-	Category    string
-	Description string
-	OccurredAt  time.Time
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
+	ID          string    `json:"id"`
+	AccountID   string    `json:"account_id"`
+	Amount      float64   `json:"amount"`
+	Currency    string    `json:"currency"`
+	Category    string    `json:"category"`
+	Description string    `json:"description"`
+	OccurredAt  time.Time `json:"occurred_at"`
+	// CreatedBy is the member who created the transaction.
+	CreatedBy string `json:"created_by"`
+	// WalletID is the wallet the money moved in; empty for transactions
+	// outside any wallet.
+	WalletID string `json:"wallet_id"`
+	// TransferID links the two legs of a transfer between wallets. Legs are
+	// neither income nor expense.
+	TransferID string `json:"transfer_id"`
+	// RefundOf is the expense a refund gives money back for. Refunds are
+	// positive, in the category of the refunded expense, and reduce its
+	// expense instead of counting as income.
+	RefundOf string `json:"refund_of"`
+	// PayeeID is the payee of the transaction; empty when the description
+	// names none.
+	PayeeID string `json:"payee_id"`
+	// Splits divide the amount between categories. A split transaction has
+	// the category CategorySplit, and budgets, reports and the journal use
+	// the categories of its parts instead.
+	Splits []TransactionSplit `json:"splits"`
+	// Tags are the names of the tags of the transaction, sorted.
+	Tags      []string   `json:"tags"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	DeletedAt *time.Time `json:"deleted_at"`
+}
+
+// CategorySplit is the category of a transaction divided between categories.
+const CategorySplit = "split"
+
+// TransactionSplit is one category part of a split transaction. The amounts
+// of the parts have the sign of the transaction and sum up to its amount.
+type TransactionSplit struct {
+	Category string  `json:"category"`
+	Amount   float64 `json:"amount"`
 }
 
 type Budget struct {
-	ID        string
-	AccountID string
-	Name      string
-	Amount    float64
-	Currency  string
-	Period    string
-	Month     time.Time
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	ID        string     `json:"id"`
+	AccountID string     `json:"account_id"`
+	Name      string     `json:"name"`
+	Amount    float64    `json:"amount"`
+	Currency  string     `json:"currency"`
+	Period    string     `json:"period"`
+	Month     time.Time  `json:"month"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	DeletedAt *time.Time `json:"deleted_at"`
 }
 
 type Report struct {
-	ID           string
-	AccountID    string
-	Name         string
-	Period       string
-	GeneratedAt  time.Time
-	TotalIncome  float64
-	TotalExpense float64
-	Currency     string
-	Categories   []ReportCategory
+	ID           string           `json:"id"`
+	AccountID    string           `json:"account_id"`
+	Name         string           `json:"name"`
+	Period       string           `json:"period"`
+	GeneratedAt  time.Time        `json:"generated_at"`
+	TotalIncome  float64          `json:"total_income"`
+	TotalExpense float64          `json:"total_expense"`
+	Currency     string           `json:"currency"`
+	Categories   []ReportCategory `json:"categories"`
+	Tags         []ReportTag      `json:"tags"`
+	Payees       []ReportPayee    `json:"payees"`
+	Goals        []ReportGoal     `json:"goals"`
+	DeletedAt    *time.Time       `json:"deleted_at"`
+}
+
+// Trash holds soft-deleted entities of an account that can still be restored.
+type Trash struct {
+	Transactions []Transaction
+	Budgets      []Budget
+	Reports      []Report
 }
 
 type ReportCategory struct {
-	Category           string
-	TotalExpense       float64
-	BudgetAmount       float64
-	BudgetUsagePercent *float64
+	Category           string   `json:"category"`
+	TotalExpense       float64  `json:"total_expense"`
+	BudgetAmount       float64  `json:"budget_amount"`
+	BudgetUsagePercent *float64 `json:"budget_usage_percent"`
+}
+
+// ReportTag totals the transactions of a tag. A transaction counts towards
+// each of its tags, so tag totals may overlap.
+type ReportTag struct {
+	Tag          string  `json:"tag"`
+	TotalIncome  float64 `json:"total_income"`
+	TotalExpense float64 `json:"total_expense"`
+}
+
+// ReportGoal is the progress of a savings goal at the end of the report
+// period.
+type ReportGoal struct {
+	GoalID       string       `json:"goal_id"`
+	Name         string       `json:"name"`
+	TargetAmount float64      `json:"target_amount"`
+	Currency     string       `json:"currency"`
+	Deadline     time.Time    `json:"deadline"`
+	Progress     GoalProgress `json:"progress"`
+}
+
+// ReportPayee totals the expenses of a payee, net of their refunds.
+type ReportPayee struct {
+	PayeeID      string  `json:"payee_id"`
+	Name         string  `json:"name"`
+	TotalExpense float64 `json:"total_expense"`
+	Transactions int     `json:"transactions"`
 }
 
 type ReportSummary struct {
-	TotalIncome  float64
-	TotalExpense float64
-	Currency     string
-	Categories   []ReportCategory
+	TotalIncome  float64          `json:"total_income"`
+	TotalExpense float64          `json:"total_expense"`
+	Currency     string           `json:"currency"`
+	Categories   []ReportCategory `json:"categories"`
+	Tags         []ReportTag      `json:"tags"`
+	// Payees are the payees with the largest expenses, largest first.
+	Payees []ReportPayee `json:"payees"`
+	// Goals are the savings goals of the account by deadline.
+	Goals []ReportGoal `json:"goals"`
 }
 
 type TransactionCSVRow struct {
@@ -59,4 +135,6 @@ type TransactionCSVRow struct {
 	Category    string
 	Description string
 	OccurredAt  time.Time
+	Splits      []TransactionSplit
+	Tags        []string
 }
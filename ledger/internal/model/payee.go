@@ -0,0 +1,19 @@
+package model
+
+import "time"
+
+// Payee is the merchant or counterparty of transactions. Descriptions are
+// matched to payees by their normalized form, so "LENTA-123 SPB" and
+// "Lenta #45" both belong to the payee "Lenta".
+type Payee struct {
+	ID        string `json:"id"`
+	AccountID string `json:"account_id"`
+	Name      string `json:"name"`
+	// Aliases are further descriptions of the payee, matched like its name.
+	Aliases []string `json:"aliases"`
+	// DefaultCategory is assigned to new transactions of the payee that no
+	// categorization rule matches; empty to keep the given category.
+	DefaultCategory string    `json:"default_category"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
@@ -0,0 +1,15 @@
+package model
+
+import "time"
+
+// Refund gives back Amount of the expense TransactionID. Category selects the
+// refunded part of a split expense and defaults to the category of the
+// expense otherwise.
+type Refund struct {
+	AccountID     string
+	TransactionID string
+	Amount        float64
+	Category      string
+	Description   string
+	OccurredAt    time.Time
+}
@@ -0,0 +1,41 @@
+package model
+
+import "time"
+
+// Directions a categorization rule can be limited to.
+const (
+	RuleTypeIncome  = "income"
+	RuleTypeExpense = "expense"
+)
+
+// CategoryRule sets Category on the transactions matching all of its
+// conditions; empty conditions match any transaction. Rules are evaluated by
+// ascending Priority and the first matching rule wins. MinAmount and MaxAmount
+// bound the absolute amount, like in TransactionFilter.
+type CategoryRule struct {
+	ID        string `json:"id"`
+	AccountID string `json:"account_id"`
+	Name      string `json:"name"`
+	Priority  int    `json:"priority"`
+	// DescriptionContains is matched case-insensitively as a substring of the
+	// description, DescriptionPattern as a regular expression.
+	DescriptionContains string   `json:"description_contains"`
+	DescriptionPattern  string   `json:"description_pattern"`
+	MinAmount           *float64 `json:"min_amount"`
+	MaxAmount           *float64 `json:"max_amount"`
+	// Type is RuleTypeIncome, RuleTypeExpense or empty for both.
+	Type      string    `json:"type"`
+	Currency  string    `json:"currency"`
+	Category  string    `json:"category"`
+	Enabled   bool      `json:"enabled"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// RuleMatch is a transaction matched by a categorization rule together with
+// the category the rule assigns.
+type RuleMatch struct {
+	Transaction Transaction
+	RuleID      string
+	Category    string
+}
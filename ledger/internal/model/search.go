@@ -0,0 +1,32 @@
+package model
+
+import "time"
+
+const (
+	SortOccurredAtAsc  = "occurred_at"
+	SortOccurredAtDesc = "-occurred_at"
+	SortAmountAsc      = "amount"
+	SortAmountDesc     = "-amount"
+)
+
+// TransactionFilter narrows down transactions of one account. Zero values mean
+// "no restriction". MinAmount and MaxAmount compare against the absolute amount,
+// so that "expenses over 5000" is expressed as MinAmount = 5000. Query is matched
+// case-insensitively as a substring of the description.
+type TransactionFilter struct {
+	AccountID string
+	From      time.Time
+	To        time.Time
+	Category  string
+	Currency  string
+	WalletID  string
+	PayeeID   string
+	MinAmount *float64
+	MaxAmount *float64
+	Query     string
+	// Tag keeps transactions with the tag of that name.
+	Tag    string
+	Sort   string
+	Limit  int
+	Offset int
+}
@@ -0,0 +1,13 @@
+package model
+
+import "time"
+
+// Tag is a free-form label of an account. Transactions refer to tags by name;
+// renaming a tag renames it on all of its transactions.
+type Tag struct {
+	ID        string    `json:"id"`
+	AccountID string    `json:"account_id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -0,0 +1,52 @@
+package model
+
+import "time"
+
+const (
+	WalletTypeCash    = "cash"
+	WalletTypeDebit   = "debit"
+	WalletTypeSavings = "savings"
+	WalletTypeCredit  = "credit"
+
+	// CategoryTransfer is the category of both legs of a transfer.
+	CategoryTransfer = "transfer"
+
+	BalanceStepDay   = "day"
+	BalanceStepMonth = "month"
+)
+
+// Wallet is a cash, card or savings balance of an account. Transactions of a
+// wallet are in its currency. Balance is computed from the live transactions
+// of the wallet and is not stored.
+type Wallet struct {
+	ID        string    `json:"id"`
+	AccountID string    `json:"account_id"`
+	Name      string    `json:"name"`
+	Type      string    `json:"type"`
+	Currency  string    `json:"currency"`
+	Balance   float64   `json:"balance"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Transfer moves money between two wallets of an account. It is stored as two
+// transactions sharing the transfer ID: Amount leaves the source wallet and
+// ToAmount, in the currency of the destination wallet, enters the other one.
+type Transfer struct {
+	ID           string
+	AccountID    string
+	FromWalletID string
+	ToWalletID   string
+	Amount       float64
+	ToAmount     float64
+	Description  string
+	OccurredAt   time.Time
+	CreatedBy    string
+}
+
+// BalancePoint is the closing balance of a wallet at the end of the day or
+// month starting at Date.
+type BalancePoint struct {
+	Date    time.Time
+	Balance float64
+}
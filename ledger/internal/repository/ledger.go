@@ -2,6 +2,13 @@ package repository
 
 import (
 	"context"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/model"
 	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/storage"
@@ -10,29 +17,147 @@ import (
 type LedgerRepository interface {
 	CreateTransaction(ctx context.Context, tx model.Transaction) (model.Transaction, error)
 	GetTransaction(ctx context.Context, id string) (model.Transaction, error)
+	// LockTransaction returns a live transaction like GetTransaction and keeps
+	// it locked until the transaction of RunInTx ends, so writers checking
+	// totals against it, such as the sum of its refunds, run one at a time.
+	LockTransaction(ctx context.Context, id string) (model.Transaction, error)
 	UpdateTransaction(ctx context.Context, tx model.Transaction) (model.Transaction, error)
 	DeleteTransaction(ctx context.Context, id string) error
 	ListTransactions(ctx context.Context) []model.Transaction
+	SearchTransactions(ctx context.Context, filter model.TransactionFilter) ([]model.Transaction, error)
+	CreateTransactions(ctx context.Context, txs []model.Transaction) error
+	UpdateTransactions(ctx context.Context, txs []model.Transaction) error
+	DeleteTransactions(ctx context.Context, ids []string) error
+	ListDeletedTransactions(ctx context.Context, accountID string) []model.Transaction
+	// ExistingTransactionIDs returns the IDs among ids that belong to a live or
+	// deleted transaction of any account.
+	ExistingTransactionIDs(ctx context.Context, ids []string) ([]string, error)
+	RestoreTransaction(ctx context.Context, accountID, id string) (model.Transaction, error)
+	PurgeDeletedTransactions(ctx context.Context, before time.Time) (int64, error)
 
 	CreateBudget(ctx context.Context, budget model.Budget) (model.Budget, error)
 	GetBudget(ctx context.Context, accountID, id string) (model.Budget, error)
 	UpdateBudget(ctx context.Context, budget model.Budget) (model.Budget, error)
 	DeleteBudget(ctx context.Context, accountID, id string) error
 	ListBudgets(ctx context.Context, accountID string) []model.Budget
+	ListDeletedBudgets(ctx context.Context, accountID string) []model.Budget
+	RestoreBudget(ctx context.Context, accountID, id string) (model.Budget, error)
+	PurgeDeletedBudgets(ctx context.Context, before time.Time) (int64, error)
 
 	CreateReport(ctx context.Context, report model.Report) (model.Report, error)
 	GetReport(ctx context.Context, accountID, id string) (model.Report, error)
 	UpdateReport(ctx context.Context, report model.Report) (model.Report, error)
 	DeleteReport(ctx context.Context, accountID, id string) error
 	ListReports(ctx context.Context, accountID string) []model.Report
+	ListDeletedReports(ctx context.Context, accountID string) []model.Report
+	RestoreReport(ctx context.Context, accountID, id string) (model.Report, error)
+	PurgeDeletedReports(ctx context.Context, before time.Time) (int64, error)
+
+	AppendEvents(ctx context.Context, events ...model.Event) error
+	ListEvents(ctx context.Context, accountID string, afterOffset int64, limit int) ([]model.Event, error)
+	ListUnpublishedEvents(ctx context.Context, limit int) ([]model.Event, error)
+	MarkEventsPublished(ctx context.Context, offsets []int64) error
+
+	AppendAudit(ctx context.Context, entries ...model.AuditEntry) error
+	ListAudit(ctx context.Context, accountID, entityType, entityID string) ([]model.AuditEntry, error)
+
+	CreateAccount(ctx context.Context, account model.Account, owner model.AccountMember) error
+	GetAccount(ctx context.Context, id string) (model.Account, error)
+	// ListAccounts returns the shared accounts of the user with the user's role.
+	ListAccounts(ctx context.Context, userID string) ([]model.Account, error)
+	GetMember(ctx context.Context, accountID, userID string) (model.AccountMember, error)
+	ListMembers(ctx context.Context, accountID string) ([]model.AccountMember, error)
+	AddMember(ctx context.Context, member model.AccountMember) error
+	UpdateMember(ctx context.Context, member model.AccountMember) error
+	RemoveMember(ctx context.Context, accountID, userID string) error
+	CreateInvitation(ctx context.Context, invitation model.Invitation) error
+	// ListInvitations returns the invitations of the account that are neither
+	// accepted nor expired at now.
+	ListInvitations(ctx context.Context, accountID string, now time.Time) ([]model.Invitation, error)
+	DeleteInvitation(ctx context.Context, accountID, id string) error
+	// AcceptInvitation marks the pending invitation with the token hash as
+	// accepted by the user. Used and expired invitations are not found.
+	AcceptInvitation(ctx context.Context, tokenHash, userID string, now time.Time) (model.Invitation, error)
+
+	CreateWallet(ctx context.Context, wallet model.Wallet) error
+	GetWallet(ctx context.Context, accountID, id string) (model.Wallet, error)
+	UpdateWallet(ctx context.Context, wallet model.Wallet) error
+	DeleteWallet(ctx context.Context, accountID, id string) error
+	// ListWallets returns the wallets of the account in creation order.
+	ListWallets(ctx context.Context, accountID string) ([]model.Wallet, error)
+
+	CreateTag(ctx context.Context, tag model.Tag) error
+	GetTag(ctx context.Context, accountID, id string) (model.Tag, error)
+	// UpdateTag renames the tag on all transactions linked to it.
+	UpdateTag(ctx context.Context, tag model.Tag) error
+	// DeleteTag removes the tag and unlinks it from its transactions.
+	DeleteTag(ctx context.Context, accountID, id string) error
+	// ListTags returns the tags of the account ordered by name.
+	ListTags(ctx context.Context, accountID string) ([]model.Tag, error)
+
+	CreateAttachment(ctx context.Context, attachment model.Attachment) error
+	GetAttachment(ctx context.Context, accountID, id string) (model.Attachment, error)
+	DeleteAttachment(ctx context.Context, accountID, id string) error
+	// ListAttachments returns the attachments of the transaction, or of the
+	// whole account when transactionID is empty, in upload order.
+	ListAttachments(ctx context.Context, accountID, transactionID string) ([]model.Attachment, error)
+	// ListExpiredAttachments returns the attachments of the trashed
+	// transactions that PurgeDeletedTransactions removes for the cutoff.
+	ListExpiredAttachments(ctx context.Context, before time.Time) ([]model.Attachment, error)
+
+	CreateRule(ctx context.Context, rule model.CategoryRule) error
+	GetRule(ctx context.Context, accountID, id string) (model.CategoryRule, error)
+	UpdateRule(ctx context.Context, rule model.CategoryRule) error
+	DeleteRule(ctx context.Context, accountID, id string) error
+	// ListRules returns the rules of the account in evaluation order: by
+	// priority, then creation time.
+	ListRules(ctx context.Context, accountID string) ([]model.CategoryRule, error)
+
+	CreatePayee(ctx context.Context, payee model.Payee) error
+	GetPayee(ctx context.Context, accountID, id string) (model.Payee, error)
+	UpdatePayee(ctx context.Context, payee model.Payee) error
+	// DeletePayee removes the payee and unlinks its transactions, live and
+	// deleted.
+	DeletePayee(ctx context.Context, accountID, id string) error
+	// ListPayees returns the payees of the account ordered by name.
+	ListPayees(ctx context.Context, accountID string) ([]model.Payee, error)
+
+	CreateGoal(ctx context.Context, goal model.Goal) error
+	GetGoal(ctx context.Context, accountID, id string) (model.Goal, error)
+	UpdateGoal(ctx context.Context, goal model.Goal) error
+	DeleteGoal(ctx context.Context, accountID, id string) error
+	// ListGoals returns the goals of the account ordered by deadline, then
+	// name.
+	ListGoals(ctx context.Context, accountID string) ([]model.Goal, error)
+
+	// SaveJournalEntries stores the entries, replacing the entries previously
+	// posted for the same transactions.
+	SaveJournalEntries(ctx context.Context, entries []model.JournalEntry) error
+	// DeleteJournalEntries removes the entries of the transactions, if any.
+	DeleteJournalEntries(ctx context.Context, transactionIDs []string) error
+	// ListJournalEntries returns the entries of the account ordered by
+	// occurred_at and transaction ID.
+	ListJournalEntries(ctx context.Context, accountID string) ([]model.JournalEntry, error)
+
+	// PurgeAccount permanently removes all data of the account: live and trashed
+	// entities, wallets, tags, attachments, rules, payees, goals, journal entries, outbox events, audit entries and the memberships of the user
+	// with the same ID. It returns the number of removed rows.
+	PurgeAccount(ctx context.Context, accountID string) (int64, error)
+
+	// RunInTx executes fn against a repository bound to a single database transaction.
+	// The transaction is committed when fn returns nil and rolled back otherwise.
+	RunInTx(ctx context.Context, fn func(repo LedgerRepository) error) error
 }
 
 type InMemoryLedgerRepository struct {
 	store *storage.InMemoryLedgerStorage
+	// txMu serializes RunInTx calls. It is nil in the repository passed to a
+	// running RunInTx, which joins that call instead.
+	txMu *sync.Mutex
 }
 
 func NewInMemoryLedgerRepository(store *storage.InMemoryLedgerStorage) *InMemoryLedgerRepository {
-	return &InMemoryLedgerRepository{store: store}
+	return &InMemoryLedgerRepository{store: store, txMu: &sync.Mutex{}}
 }
 
 func (r *InMemoryLedgerRepository) CreateTransaction(ctx context.Context, tx model.Transaction) (model.Transaction, error) {
@@ -43,6 +168,11 @@ func (r *InMemoryLedgerRepository) GetTransaction(ctx context.Context, id string
 	return r.store.GetTransaction(id)
 }
 
+// LockTransaction needs no lock of its own: RunInTx calls already run one at a time.
+func (r *InMemoryLedgerRepository) LockTransaction(ctx context.Context, id string) (model.Transaction, error) {
+	return r.store.GetTransaction(id)
+}
+
 func (r *InMemoryLedgerRepository) UpdateTransaction(ctx context.Context, tx model.Transaction) (model.Transaction, error) {
 	return r.store.UpdateTransaction(tx)
 }
@@ -142,3 +272,711 @@ func (r *InMemoryLedgerRepository) ListReports(ctx context.Context, accountID st
 	}
 	return filtered
 }
+
+// SearchTransactions mirrors the Postgres implementation: same predicates, same ordering.
+func (r *InMemoryLedgerRepository) SearchTransactions(ctx context.Context, filter model.TransactionFilter) ([]model.Transaction, error) {
+	less, ok := transactionSortFuncs[filter.Sort]
+	if !ok {
+		return nil, fmt.Errorf("unsupported sort %q", filter.Sort)
+	}
+	items := r.store.ListTransactions()
+	filtered := make([]model.Transaction, 0, len(items))
+	for _, tx := range items {
+		if matchesTransactionFilter(tx, filter) {
+			filtered = append(filtered, tx)
+		}
+	}
+	sort.Slice(filtered, func(i, j int) bool { return less(filtered[i], filtered[j]) })
+	if filter.Offset > 0 {
+		if filter.Offset >= len(filtered) {
+			return []model.Transaction{}, nil
+		}
+		filtered = filtered[filter.Offset:]
+	}
+	if filter.Limit > 0 && len(filtered) > filter.Limit {
+		filtered = filtered[:filter.Limit]
+	}
+	return filtered, nil
+}
+
+var transactionSortFuncs = map[string]func(a, b model.Transaction) bool{
+	"":                       occurredAtDesc,
+	model.SortOccurredAtDesc: occurredAtDesc,
+	model.SortOccurredAtAsc: func(a, b model.Transaction) bool {
+		if !a.OccurredAt.Equal(b.OccurredAt) {
+			return a.OccurredAt.Before(b.OccurredAt)
+		}
+		return a.ID < b.ID
+	},
+	model.SortAmountDesc: func(a, b model.Transaction) bool {
+		if math.Abs(a.Amount) != math.Abs(b.Amount) {
+			return math.Abs(a.Amount) > math.Abs(b.Amount)
+		}
+		return a.ID < b.ID
+	},
+	model.SortAmountAsc: func(a, b model.Transaction) bool {
+		if math.Abs(a.Amount) != math.Abs(b.Amount) {
+			return math.Abs(a.Amount) < math.Abs(b.Amount)
+		}
+		return a.ID < b.ID
+	},
+}
+
+func occurredAtDesc(a, b model.Transaction) bool {
+	if !a.OccurredAt.Equal(b.OccurredAt) {
+		return a.OccurredAt.After(b.OccurredAt)
+	}
+	return a.ID < b.ID
+}
+
+func matchesTransactionFilter(tx model.Transaction, filter model.TransactionFilter) bool {
+	if tx.AccountID != filter.AccountID {
+		return false
+	}
+	if !filter.From.IsZero() && tx.OccurredAt.Before(filter.From) {
+		return false
+	}
+	if !filter.To.IsZero() && tx.OccurredAt.After(filter.To) {
+		return false
+	}
+	if filter.Category != "" && tx.Category != filter.Category && !hasSplitCategory(tx, filter.Category) {
+		return false
+	}
+	if filter.Currency != "" && tx.Currency != filter.Currency {
+		return false
+	}
+	if filter.WalletID != "" && tx.WalletID != filter.WalletID {
+		return false
+	}
+	if filter.PayeeID != "" && tx.PayeeID != filter.PayeeID {
+		return false
+	}
+	if filter.MinAmount != nil && math.Abs(tx.Amount) < *filter.MinAmount {
+		return false
+	}
+	if filter.MaxAmount != nil && math.Abs(tx.Amount) > *filter.MaxAmount {
+		return false
+	}
+	if filter.Query != "" && !strings.Contains(strings.ToLower(tx.Description), strings.ToLower(filter.Query)) {
+		return false
+	}
+	if filter.Tag != "" && !slices.Contains(tx.Tags, filter.Tag) {
+		return false
+	}
+	return true
+}
+
+func hasSplitCategory(tx model.Transaction, category string) bool {
+	for _, split := range tx.Splits {
+		if split.Category == category {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *InMemoryLedgerRepository) CreateTransactions(ctx context.Context, txs []model.Transaction) error {
+	for _, tx := range txs {
+		r.store.CreateTransaction(tx)
+	}
+	return nil
+}
+
+func (r *InMemoryLedgerRepository) UpdateTransactions(ctx context.Context, txs []model.Transaction) error {
+	for _, tx := range txs {
+		if _, err := r.store.UpdateTransaction(tx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *InMemoryLedgerRepository) DeleteTransactions(ctx context.Context, ids []string) error {
+	for _, id := range ids {
+		if err := r.store.DeleteTransaction(id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *InMemoryLedgerRepository) ListDeletedTransactions(ctx context.Context, accountID string) []model.Transaction {
+	items := r.store.ListDeletedTransactions()
+	filtered := make([]model.Transaction, 0, len(items))
+	for _, tx := range items {
+		if tx.AccountID == accountID {
+			filtered = append(filtered, tx)
+		}
+	}
+	return filtered
+}
+
+func (r *InMemoryLedgerRepository) ExistingTransactionIDs(ctx context.Context, ids []string) ([]string, error) {
+	existing := make([]string, 0)
+	for _, items := range [][]model.Transaction{r.store.ListTransactions(), r.store.ListDeletedTransactions()} {
+		for _, tx := range items {
+			if slices.Contains(ids, tx.ID) {
+				existing = append(existing, tx.ID)
+			}
+		}
+	}
+	return existing, nil
+}
+
+func (r *InMemoryLedgerRepository) RestoreTransaction(ctx context.Context, accountID, id string) (model.Transaction, error) {
+	for _, tx := range r.store.ListDeletedTransactions() {
+		if tx.ID == id && tx.AccountID == accountID {
+			return r.store.RestoreTransaction(id)
+		}
+	}
+	return model.Transaction{}, storage.ErrNotFound
+}
+
+func (r *InMemoryLedgerRepository) PurgeDeletedTransactions(ctx context.Context, before time.Time) (int64, error) {
+	return int64(r.store.PurgeDeletedTransactions(before)), nil
+}
+
+func (r *InMemoryLedgerRepository) ListDeletedBudgets(ctx context.Context, accountID string) []model.Budget {
+	items := r.store.ListDeletedBudgets()
+	filtered := make([]model.Budget, 0, len(items))
+	for _, budget := range items {
+		if budget.AccountID == accountID {
+			filtered = append(filtered, budget)
+		}
+	}
+	return filtered
+}
+
+func (r *InMemoryLedgerRepository) RestoreBudget(ctx context.Context, accountID, id string) (model.Budget, error) {
+	for _, budget := range r.store.ListDeletedBudgets() {
+		if budget.ID == id && budget.AccountID == accountID {
+			return r.store.RestoreBudget(id)
+		}
+	}
+	return model.Budget{}, storage.ErrNotFound
+}
+
+func (r *InMemoryLedgerRepository) PurgeDeletedBudgets(ctx context.Context, before time.Time) (int64, error) {
+	return int64(r.store.PurgeDeletedBudgets(before)), nil
+}
+
+func (r *InMemoryLedgerRepository) ListDeletedReports(ctx context.Context, accountID string) []model.Report {
+	items := r.store.ListDeletedReports()
+	filtered := make([]model.Report, 0, len(items))
+	for _, report := range items {
+		if report.AccountID == accountID {
+			filtered = append(filtered, report)
+		}
+	}
+	return filtered
+}
+
+func (r *InMemoryLedgerRepository) RestoreReport(ctx context.Context, accountID, id string) (model.Report, error) {
+	for _, report := range r.store.ListDeletedReports() {
+		if report.ID == id && report.AccountID == accountID {
+			return r.store.RestoreReport(id)
+		}
+	}
+	return model.Report{}, storage.ErrNotFound
+}
+
+func (r *InMemoryLedgerRepository) PurgeDeletedReports(ctx context.Context, before time.Time) (int64, error) {
+	return int64(r.store.PurgeDeletedReports(before)), nil
+}
+
+func (r *InMemoryLedgerRepository) AppendEvents(ctx context.Context, events ...model.Event) error {
+	r.store.AppendEvents(events...)
+	return nil
+}
+
+func (r *InMemoryLedgerRepository) ListEvents(ctx context.Context, accountID string, afterOffset int64, limit int) ([]model.Event, error) {
+	items := r.store.ListEvents()
+	filtered := make([]model.Event, 0, len(items))
+	for _, event := range items {
+		if event.AccountID != accountID || event.Offset <= afterOffset {
+			continue
+		}
+		filtered = append(filtered, event)
+	}
+	sort.Slice(filtered, func(i, j int) bool { return filtered[i].Offset < filtered[j].Offset })
+	if limit > 0 && len(filtered) > limit {
+		filtered = filtered[:limit]
+	}
+	return filtered, nil
+}
+
+func (r *InMemoryLedgerRepository) ListUnpublishedEvents(ctx context.Context, limit int) ([]model.Event, error) {
+	items := r.store.ListUnpublishedEvents()
+	if limit > 0 && len(items) > limit {
+		items = items[:limit]
+	}
+	return items, nil
+}
+
+func (r *InMemoryLedgerRepository) MarkEventsPublished(ctx context.Context, offsets []int64) error {
+	r.store.MarkEventsPublished(offsets)
+	return nil
+}
+
+func (r *InMemoryLedgerRepository) AppendAudit(ctx context.Context, entries ...model.AuditEntry) error {
+	r.store.AppendAudit(entries...)
+	return nil
+}
+
+func (r *InMemoryLedgerRepository) ListAudit(ctx context.Context, accountID, entityType, entityID string) ([]model.AuditEntry, error) {
+	items := r.store.ListAudit()
+	filtered := make([]model.AuditEntry, 0, len(items))
+	for _, entry := range items {
+		if entry.AccountID == accountID && entry.EntityType == entityType && entry.EntityID == entityID {
+			filtered = append(filtered, entry)
+		}
+	}
+	return filtered, nil
+}
+
+func (r *InMemoryLedgerRepository) PurgeAccount(ctx context.Context, accountID string) (int64, error) {
+	return int64(r.store.PurgeAccount(accountID)), nil
+}
+
+func (r *InMemoryLedgerRepository) CreateAccount(ctx context.Context, account model.Account, owner model.AccountMember) error {
+	r.store.CreateAccount(account, owner)
+	return nil
+}
+
+func (r *InMemoryLedgerRepository) GetAccount(ctx context.Context, id string) (model.Account, error) {
+	return r.store.GetAccount(id)
+}
+
+func (r *InMemoryLedgerRepository) ListAccounts(ctx context.Context, userID string) ([]model.Account, error) {
+	items := make([]model.Account, 0)
+	for _, member := range r.store.ListMembers() {
+		if member.UserID != userID {
+			continue
+		}
+		account, err := r.store.GetAccount(member.AccountID)
+		if err != nil {
+			return nil, err
+		}
+		account.Role = member.Role
+		items = append(items, account)
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if !items[i].CreatedAt.Equal(items[j].CreatedAt) {
+			return items[i].CreatedAt.Before(items[j].CreatedAt)
+		}
+		return items[i].ID < items[j].ID
+	})
+	return items, nil
+}
+
+func (r *InMemoryLedgerRepository) GetMember(ctx context.Context, accountID, userID string) (model.AccountMember, error) {
+	for _, member := range r.store.ListMembers() {
+		if member.AccountID == accountID && member.UserID == userID {
+			return member, nil
+		}
+	}
+	return model.AccountMember{}, storage.ErrNotFound
+}
+
+func (r *InMemoryLedgerRepository) ListMembers(ctx context.Context, accountID string) ([]model.AccountMember, error) {
+	items := make([]model.AccountMember, 0)
+	for _, member := range r.store.ListMembers() {
+		if member.AccountID == accountID {
+			items = append(items, member)
+		}
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if !items[i].CreatedAt.Equal(items[j].CreatedAt) {
+			return items[i].CreatedAt.Before(items[j].CreatedAt)
+		}
+		return items[i].UserID < items[j].UserID
+	})
+	return items, nil
+}
+
+func (r *InMemoryLedgerRepository) AddMember(ctx context.Context, member model.AccountMember) error {
+	r.store.PutMember(member)
+	return nil
+}
+
+func (r *InMemoryLedgerRepository) UpdateMember(ctx context.Context, member model.AccountMember) error {
+	if _, err := r.GetMember(ctx, member.AccountID, member.UserID); err != nil {
+		return err
+	}
+	r.store.PutMember(member)
+	return nil
+}
+
+func (r *InMemoryLedgerRepository) RemoveMember(ctx context.Context, accountID, userID string) error {
+	return r.store.RemoveMember(accountID, userID)
+}
+
+func (r *InMemoryLedgerRepository) CreateInvitation(ctx context.Context, invitation model.Invitation) error {
+	r.store.PutInvitation(invitation)
+	return nil
+}
+
+func (r *InMemoryLedgerRepository) ListInvitations(ctx context.Context, accountID string, now time.Time) ([]model.Invitation, error) {
+	items := make([]model.Invitation, 0)
+	for _, invitation := range r.store.ListInvitations() {
+		if invitation.AccountID == accountID && invitation.AcceptedAt == nil && invitation.ExpiresAt.After(now) {
+			items = append(items, invitation)
+		}
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if !items[i].CreatedAt.Equal(items[j].CreatedAt) {
+			return items[i].CreatedAt.Before(items[j].CreatedAt)
+		}
+		return items[i].ID < items[j].ID
+	})
+	return items, nil
+}
+
+func (r *InMemoryLedgerRepository) DeleteInvitation(ctx context.Context, accountID, id string) error {
+	for _, invitation := range r.store.ListInvitations() {
+		if invitation.ID == id && invitation.AccountID == accountID && invitation.AcceptedAt == nil {
+			r.store.DeleteInvitation(id)
+			return nil
+		}
+	}
+	return storage.ErrNotFound
+}
+
+func (r *InMemoryLedgerRepository) AcceptInvitation(ctx context.Context, tokenHash, userID string, now time.Time) (model.Invitation, error) {
+	return r.store.AcceptInvitation(tokenHash, userID, now)
+}
+
+func (r *InMemoryLedgerRepository) CreateWallet(ctx context.Context, wallet model.Wallet) error {
+	r.store.PutWallet(wallet)
+	return nil
+}
+
+func (r *InMemoryLedgerRepository) GetWallet(ctx context.Context, accountID, id string) (model.Wallet, error) {
+	wallet, err := r.store.GetWallet(id)
+	if err != nil {
+		return model.Wallet{}, err
+	}
+	if wallet.AccountID != accountID {
+		return model.Wallet{}, storage.ErrNotFound
+	}
+	return wallet, nil
+}
+
+func (r *InMemoryLedgerRepository) UpdateWallet(ctx context.Context, wallet model.Wallet) error {
+	if _, err := r.GetWallet(ctx, wallet.AccountID, wallet.ID); err != nil {
+		return err
+	}
+	r.store.PutWallet(wallet)
+	return nil
+}
+
+func (r *InMemoryLedgerRepository) DeleteWallet(ctx context.Context, accountID, id string) error {
+	if _, err := r.GetWallet(ctx, accountID, id); err != nil {
+		return err
+	}
+	return r.store.DeleteWallet(id)
+}
+
+func (r *InMemoryLedgerRepository) ListWallets(ctx context.Context, accountID string) ([]model.Wallet, error) {
+	items := make([]model.Wallet, 0)
+	for _, wallet := range r.store.ListWallets() {
+		if wallet.AccountID == accountID {
+			items = append(items, wallet)
+		}
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if !items[i].CreatedAt.Equal(items[j].CreatedAt) {
+			return items[i].CreatedAt.Before(items[j].CreatedAt)
+		}
+		return items[i].ID < items[j].ID
+	})
+	return items, nil
+}
+
+func (r *InMemoryLedgerRepository) CreateTag(ctx context.Context, tag model.Tag) error {
+	r.store.PutTag(tag)
+	return nil
+}
+
+func (r *InMemoryLedgerRepository) GetTag(ctx context.Context, accountID, id string) (model.Tag, error) {
+	tag, err := r.store.GetTag(id)
+	if err != nil {
+		return model.Tag{}, err
+	}
+	if tag.AccountID != accountID {
+		return model.Tag{}, storage.ErrNotFound
+	}
+	return tag, nil
+}
+
+func (r *InMemoryLedgerRepository) UpdateTag(ctx context.Context, tag model.Tag) error {
+	if _, err := r.GetTag(ctx, tag.AccountID, tag.ID); err != nil {
+		return err
+	}
+	return r.store.UpdateTag(tag)
+}
+
+func (r *InMemoryLedgerRepository) DeleteTag(ctx context.Context, accountID, id string) error {
+	if _, err := r.GetTag(ctx, accountID, id); err != nil {
+		return err
+	}
+	return r.store.DeleteTag(id)
+}
+
+func (r *InMemoryLedgerRepository) ListTags(ctx context.Context, accountID string) ([]model.Tag, error) {
+	items := make([]model.Tag, 0)
+	for _, tag := range r.store.ListTags() {
+		if tag.AccountID == accountID {
+			items = append(items, tag)
+		}
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].Name < items[j].Name })
+	return items, nil
+}
+
+func (r *InMemoryLedgerRepository) CreateAttachment(ctx context.Context, attachment model.Attachment) error {
+	r.store.PutAttachment(attachment)
+	return nil
+}
+
+func (r *InMemoryLedgerRepository) GetAttachment(ctx context.Context, accountID, id string) (model.Attachment, error) {
+	attachment, err := r.store.GetAttachment(id)
+	if err != nil {
+		return model.Attachment{}, err
+	}
+	if attachment.AccountID != accountID {
+		return model.Attachment{}, storage.ErrNotFound
+	}
+	return attachment, nil
+}
+
+func (r *InMemoryLedgerRepository) DeleteAttachment(ctx context.Context, accountID, id string) error {
+	if _, err := r.GetAttachment(ctx, accountID, id); err != nil {
+		return err
+	}
+	return r.store.DeleteAttachment(id)
+}
+
+func (r *InMemoryLedgerRepository) ListAttachments(ctx context.Context, accountID, transactionID string) ([]model.Attachment, error) {
+	items := make([]model.Attachment, 0)
+	for _, attachment := range r.store.ListAttachments() {
+		if attachment.AccountID != accountID {
+			continue
+		}
+		if transactionID != "" && attachment.TransactionID != transactionID {
+			continue
+		}
+		items = append(items, attachment)
+	}
+	sortAttachments(items)
+	return items, nil
+}
+
+func (r *InMemoryLedgerRepository) ListExpiredAttachments(ctx context.Context, before time.Time) ([]model.Attachment, error) {
+	expired := make(map[string]bool)
+	for _, tx := range r.store.ListDeletedTransactions() {
+		if tx.DeletedAt != nil && tx.DeletedAt.Before(before) {
+			expired[tx.ID] = true
+		}
+	}
+	items := make([]model.Attachment, 0)
+	for _, attachment := range r.store.ListAttachments() {
+		if expired[attachment.TransactionID] {
+			items = append(items, attachment)
+		}
+	}
+	sortAttachments(items)
+	return items, nil
+}
+
+func sortAttachments(items []model.Attachment) {
+	sort.Slice(items, func(i, j int) bool {
+		if !items[i].CreatedAt.Equal(items[j].CreatedAt) {
+			return items[i].CreatedAt.Before(items[j].CreatedAt)
+		}
+		return items[i].ID < items[j].ID
+	})
+}
+
+func (r *InMemoryLedgerRepository) CreateRule(ctx context.Context, rule model.CategoryRule) error {
+	r.store.PutRule(rule)
+	return nil
+}
+
+func (r *InMemoryLedgerRepository) GetRule(ctx context.Context, accountID, id string) (model.CategoryRule, error) {
+	rule, err := r.store.GetRule(id)
+	if err != nil {
+		return model.CategoryRule{}, err
+	}
+	if rule.AccountID != accountID {
+		return model.CategoryRule{}, storage.ErrNotFound
+	}
+	return rule, nil
+}
+
+func (r *InMemoryLedgerRepository) UpdateRule(ctx context.Context, rule model.CategoryRule) error {
+	if _, err := r.GetRule(ctx, rule.AccountID, rule.ID); err != nil {
+		return err
+	}
+	r.store.PutRule(rule)
+	return nil
+}
+
+func (r *InMemoryLedgerRepository) DeleteRule(ctx context.Context, accountID, id string) error {
+	if _, err := r.GetRule(ctx, accountID, id); err != nil {
+		return err
+	}
+	return r.store.DeleteRule(id)
+}
+
+func (r *InMemoryLedgerRepository) ListRules(ctx context.Context, accountID string) ([]model.CategoryRule, error) {
+	items := make([]model.CategoryRule, 0)
+	for _, rule := range r.store.ListRules() {
+		if rule.AccountID == accountID {
+			items = append(items, rule)
+		}
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Priority != items[j].Priority {
+			return items[i].Priority < items[j].Priority
+		}
+		if !items[i].CreatedAt.Equal(items[j].CreatedAt) {
+			return items[i].CreatedAt.Before(items[j].CreatedAt)
+		}
+		return items[i].ID < items[j].ID
+	})
+	return items, nil
+}
+
+func (r *InMemoryLedgerRepository) CreatePayee(ctx context.Context, payee model.Payee) error {
+	r.store.PutPayee(payee)
+	return nil
+}
+
+func (r *InMemoryLedgerRepository) GetPayee(ctx context.Context, accountID, id string) (model.Payee, error) {
+	payee, err := r.store.GetPayee(id)
+	if err != nil {
+		return model.Payee{}, err
+	}
+	if payee.AccountID != accountID {
+		return model.Payee{}, storage.ErrNotFound
+	}
+	return payee, nil
+}
+
+func (r *InMemoryLedgerRepository) UpdatePayee(ctx context.Context, payee model.Payee) error {
+	if _, err := r.GetPayee(ctx, payee.AccountID, payee.ID); err != nil {
+		return err
+	}
+	r.store.PutPayee(payee)
+	return nil
+}
+
+func (r *InMemoryLedgerRepository) DeletePayee(ctx context.Context, accountID, id string) error {
+	if _, err := r.GetPayee(ctx, accountID, id); err != nil {
+		return err
+	}
+	return r.store.DeletePayee(id)
+}
+
+func (r *InMemoryLedgerRepository) ListPayees(ctx context.Context, accountID string) ([]model.Payee, error) {
+	items := make([]model.Payee, 0)
+	for _, payee := range r.store.ListPayees() {
+		if payee.AccountID == accountID {
+			items = append(items, payee)
+		}
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Name != items[j].Name {
+			return items[i].Name < items[j].Name
+		}
+		return items[i].ID < items[j].ID
+	})
+	return items, nil
+}
+
+func (r *InMemoryLedgerRepository) CreateGoal(ctx context.Context, goal model.Goal) error {
+	r.store.PutGoal(goal)
+	return nil
+}
+
+func (r *InMemoryLedgerRepository) GetGoal(ctx context.Context, accountID, id string) (model.Goal, error) {
+	goal, err := r.store.GetGoal(id)
+	if err != nil {
+		return model.Goal{}, err
+	}
+	if goal.AccountID != accountID {
+		return model.Goal{}, storage.ErrNotFound
+	}
+	return goal, nil
+}
+
+func (r *InMemoryLedgerRepository) UpdateGoal(ctx context.Context, goal model.Goal) error {
+	if _, err := r.GetGoal(ctx, goal.AccountID, goal.ID); err != nil {
+		return err
+	}
+	r.store.PutGoal(goal)
+	return nil
+}
+
+func (r *InMemoryLedgerRepository) DeleteGoal(ctx context.Context, accountID, id string) error {
+	if _, err := r.GetGoal(ctx, accountID, id); err != nil {
+		return err
+	}
+	return r.store.DeleteGoal(id)
+}
+
+func (r *InMemoryLedgerRepository) ListGoals(ctx context.Context, accountID string) ([]model.Goal, error) {
+	items := make([]model.Goal, 0)
+	for _, goal := range r.store.ListGoals() {
+		if goal.AccountID == accountID {
+			items = append(items, goal)
+		}
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if !items[i].Deadline.Equal(items[j].Deadline) {
+			return items[i].Deadline.Before(items[j].Deadline)
+		}
+		if items[i].Name != items[j].Name {
+			return items[i].Name < items[j].Name
+		}
+		return items[i].ID < items[j].ID
+	})
+	return items, nil
+}
+
+// RunInTx runs fn one call at a time; the in-memory store has no rollback support.
+func (r *InMemoryLedgerRepository) RunInTx(ctx context.Context, fn func(repo LedgerRepository) error) error {
+	if r.txMu == nil {
+		return fn(r)
+	}
+	r.txMu.Lock()
+	defer r.txMu.Unlock()
+	return fn(&InMemoryLedgerRepository{store: r.store})
+}
+
+func (r *InMemoryLedgerRepository) SaveJournalEntries(ctx context.Context, entries []model.JournalEntry) error {
+	r.store.PutJournalEntries(entries...)
+	return nil
+}
+
+func (r *InMemoryLedgerRepository) DeleteJournalEntries(ctx context.Context, transactionIDs []string) error {
+	r.store.DeleteJournalEntries(transactionIDs...)
+	return nil
+}
+
+func (r *InMemoryLedgerRepository) ListJournalEntries(ctx context.Context, accountID string) ([]model.JournalEntry, error) {
+	items := make([]model.JournalEntry, 0)
+	for _, entry := range r.store.ListJournalEntries() {
+		if entry.AccountID == accountID {
+			items = append(items, entry)
+		}
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if !items[i].OccurredAt.Equal(items[j].OccurredAt) {
+			return items[i].OccurredAt.Before(items[j].OccurredAt)
+		}
+		return items[i].TransactionID < items[j].TransactionID
+	})
+	return items, nil
+}
@@ -4,8 +4,13 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/model"
@@ -18,6 +23,13 @@ type TransactionRepository interface {
 	UpdateTransaction(ctx context.Context, tx model.Transaction) (model.Transaction, error)
 	DeleteTransaction(ctx context.Context, id string) error
 	ListTransactions(ctx context.Context) []model.Transaction
+	SearchTransactions(ctx context.Context, filter model.TransactionFilter) ([]model.Transaction, error)
+	CreateTransactions(ctx context.Context, txs []model.Transaction) error
+	UpdateTransactions(ctx context.Context, txs []model.Transaction) error
+	DeleteTransactions(ctx context.Context, ids []string) error
+	ListDeletedTransactions(ctx context.Context, accountID string) []model.Transaction
+	RestoreTransaction(ctx context.Context, accountID, id string) (model.Transaction, error)
+	PurgeDeletedTransactions(ctx context.Context, before time.Time) (int64, error)
 }
 
 type BudgetRepository interface {
@@ -26,6 +38,9 @@ type BudgetRepository interface {
 	UpdateBudget(ctx context.Context, budget model.Budget) (model.Budget, error)
 	DeleteBudget(ctx context.Context, accountID, id string) error
 	ListBudgets(ctx context.Context, accountID string) []model.Budget
+	ListDeletedBudgets(ctx context.Context, accountID string) []model.Budget
+	RestoreBudget(ctx context.Context, accountID, id string) (model.Budget, error)
+	PurgeDeletedBudgets(ctx context.Context, before time.Time) (int64, error)
 }
 
 type ReportRepository interface {
@@ -34,10 +49,116 @@ type ReportRepository interface {
 	UpdateReport(ctx context.Context, report model.Report) (model.Report, error)
 	DeleteReport(ctx context.Context, accountID, id string) error
 	ListReports(ctx context.Context, accountID string) []model.Report
+	ListDeletedReports(ctx context.Context, accountID string) []model.Report
+	RestoreReport(ctx context.Context, accountID, id string) (model.Report, error)
+	PurgeDeletedReports(ctx context.Context, before time.Time) (int64, error)
+}
+
+type EventRepository interface {
+	AppendEvents(ctx context.Context, events ...model.Event) error
+	ListEvents(ctx context.Context, accountID string, afterOffset int64, limit int) ([]model.Event, error)
+	ListUnpublishedEvents(ctx context.Context, limit int) ([]model.Event, error)
+	MarkEventsPublished(ctx context.Context, offsets []int64) error
+}
+
+type AuditRepository interface {
+	AppendAudit(ctx context.Context, entries ...model.AuditEntry) error
+	ListAudit(ctx context.Context, accountID, entityType, entityID string) ([]model.AuditEntry, error)
+}
+
+type AccountRepository interface {
+	CreateAccount(ctx context.Context, account model.Account, owner model.AccountMember) error
+	GetAccount(ctx context.Context, id string) (model.Account, error)
+	ListAccounts(ctx context.Context, userID string) ([]model.Account, error)
+	GetMember(ctx context.Context, accountID, userID string) (model.AccountMember, error)
+	ListMembers(ctx context.Context, accountID string) ([]model.AccountMember, error)
+	AddMember(ctx context.Context, member model.AccountMember) error
+	UpdateMember(ctx context.Context, member model.AccountMember) error
+	RemoveMember(ctx context.Context, accountID, userID string) error
+	CreateInvitation(ctx context.Context, invitation model.Invitation) error
+	ListInvitations(ctx context.Context, accountID string, now time.Time) ([]model.Invitation, error)
+	DeleteInvitation(ctx context.Context, accountID, id string) error
+	AcceptInvitation(ctx context.Context, tokenHash, userID string, now time.Time) (model.Invitation, error)
+}
+
+type WalletRepository interface {
+	CreateWallet(ctx context.Context, wallet model.Wallet) error
+	GetWallet(ctx context.Context, accountID, id string) (model.Wallet, error)
+	UpdateWallet(ctx context.Context, wallet model.Wallet) error
+	DeleteWallet(ctx context.Context, accountID, id string) error
+	ListWallets(ctx context.Context, accountID string) ([]model.Wallet, error)
+}
+
+type TagRepository interface {
+	CreateTag(ctx context.Context, tag model.Tag) error
+	GetTag(ctx context.Context, accountID, id string) (model.Tag, error)
+	UpdateTag(ctx context.Context, tag model.Tag) error
+	DeleteTag(ctx context.Context, accountID, id string) error
+	ListTags(ctx context.Context, accountID string) ([]model.Tag, error)
+}
+
+type AttachmentRepository interface {
+	CreateAttachment(ctx context.Context, attachment model.Attachment) error
+	GetAttachment(ctx context.Context, accountID, id string) (model.Attachment, error)
+	DeleteAttachment(ctx context.Context, accountID, id string) error
+	ListAttachments(ctx context.Context, accountID, transactionID string) ([]model.Attachment, error)
+	ListExpiredAttachments(ctx context.Context, before time.Time) ([]model.Attachment, error)
+}
+
+type RuleRepository interface {
+	CreateRule(ctx context.Context, rule model.CategoryRule) error
+	GetRule(ctx context.Context, accountID, id string) (model.CategoryRule, error)
+	UpdateRule(ctx context.Context, rule model.CategoryRule) error
+	DeleteRule(ctx context.Context, accountID, id string) error
+	ListRules(ctx context.Context, accountID string) ([]model.CategoryRule, error)
+}
+
+type PayeeRepository interface {
+	CreatePayee(ctx context.Context, payee model.Payee) error
+	GetPayee(ctx context.Context, accountID, id string) (model.Payee, error)
+	UpdatePayee(ctx context.Context, payee model.Payee) error
+	DeletePayee(ctx context.Context, accountID, id string) error
+	ListPayees(ctx context.Context, accountID string) ([]model.Payee, error)
+}
+
+type GoalRepository interface {
+	CreateGoal(ctx context.Context, goal model.Goal) error
+	GetGoal(ctx context.Context, accountID, id string) (model.Goal, error)
+	UpdateGoal(ctx context.Context, goal model.Goal) error
+	DeleteGoal(ctx context.Context, accountID, id string) error
+	ListGoals(ctx context.Context, accountID string) ([]model.Goal, error)
+}
+
+type JournalRepository interface {
+	SaveJournalEntries(ctx context.Context, entries []model.JournalEntry) error
+	DeleteJournalEntries(ctx context.Context, transactionIDs []string) error
+	ListJournalEntries(ctx context.Context, accountID string) ([]model.JournalEntry, error)
+}
+
+// querier is implemented by both *pgxpool.Pool and pgx.Tx, so repositories can
+// run either on the pool or inside a transaction.
+type querier interface {
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults
+	CopyFrom(ctx context.Context, tableName pgx.Identifier, columnNames []string, rowSrc pgx.CopyFromSource) (int64, error)
+}
+
+// execBatch sends all queued statements in one round trip and returns the first error.
+func execBatch(ctx context.Context, db querier, batch *pgx.Batch) error {
+	results := db.SendBatch(ctx, batch)
+	for i := 0; i < batch.Len(); i++ {
+		if _, err := results.Exec(); err != nil {
+			_ = results.Close()
+			return err
+		}
+	}
+	return results.Close()
 }
 
 type PostgresTransactionRepository struct {
-	db *pgxpool.Pool
+	db querier
 }
 
 func NewPostgresTransactionRepository(db *pgxpool.Pool) *PostgresTransactionRepository {
@@ -46,21 +167,39 @@ func NewPostgresTransactionRepository(db *pgxpool.Pool) *PostgresTransactionRepo
 
 func (r *PostgresTransactionRepository) CreateTransaction(ctx context.Context, tx model.Transaction) (model.Transaction, error) {
 	const query = `
-		INSERT INTO transactions (id, account_id, amount, currency, category, description, occurred_at, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
-	_, err := r.db.Exec(ctx, query, tx.ID, tx.AccountID, tx.Amount, tx.Currency, tx.Category, tx.Description, tx.OccurredAt, tx.CreatedAt, tx.UpdatedAt)
+		INSERT INTO transactions (id, account_id, amount, currency, category, description, occurred_at, created_by, wallet_id, transfer_id, refund_of, payee_id, splits, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)`
+	splits, err := splitsJSON(tx.Splits)
+	if err != nil {
+		return model.Transaction{}, err
+	}
+	_, err = r.db.Exec(ctx, query, tx.ID, tx.AccountID, tx.Amount, tx.Currency, tx.Category, tx.Description, tx.OccurredAt, tx.CreatedBy, tx.WalletID, tx.TransferID, tx.RefundOf, tx.PayeeID, splits, tx.CreatedAt, tx.UpdatedAt)
 	if err != nil {
 		return model.Transaction{}, err
 	}
+	if err := r.saveTransactionTags(ctx, tx); err != nil {
+		return model.Transaction{}, err
+	}
 	return tx, nil
 }
 
 func (r *PostgresTransactionRepository) GetTransaction(ctx context.Context, id string) (model.Transaction, error) {
-	const query = `
-		SELECT id, account_id, amount, currency, category, description, occurred_at, created_at, updated_at
+	return r.getTransaction(ctx, id, "")
+}
+
+// LockTransaction reads the transaction with SELECT ... FOR UPDATE, so the row
+// stays locked until the surrounding database transaction ends.
+func (r *PostgresTransactionRepository) LockTransaction(ctx context.Context, id string) (model.Transaction, error) {
+	return r.getTransaction(ctx, id, " FOR UPDATE")
+}
+
+func (r *PostgresTransactionRepository) getTransaction(ctx context.Context, id, lock string) (model.Transaction, error) {
+	query := `
+		SELECT id, account_id, amount, currency, category, description, occurred_at, created_by, wallet_id, transfer_id, refund_of, payee_id, splits, ` + transactionTagsColumn + `, created_at, updated_at
 		FROM transactions
-		WHERE id = $1`
+		WHERE id = $1 AND deleted_at IS NULL` + lock
 	var tx model.Transaction
+	var splits []byte
 	err := r.db.QueryRow(ctx, query, id).Scan(
 		&tx.ID,
 		&tx.AccountID,
@@ -69,6 +208,13 @@ func (r *PostgresTransactionRepository) GetTransaction(ctx context.Context, id s
 		&tx.Category,
 		&tx.Description,
 		&tx.OccurredAt,
+		&tx.CreatedBy,
+		&tx.WalletID,
+		&tx.TransferID,
+		&tx.RefundOf,
+		&tx.PayeeID,
+		&splits,
+		&tx.Tags,
 		&tx.CreatedAt,
 		&tx.UpdatedAt,
 	)
@@ -78,26 +224,36 @@ func (r *PostgresTransactionRepository) GetTransaction(ctx context.Context, id s
 		}
 		return model.Transaction{}, err
 	}
+	if tx.Splits, err = decodeSplits(splits); err != nil {
+		return model.Transaction{}, err
+	}
 	return tx, nil
 }
 
 func (r *PostgresTransactionRepository) UpdateTransaction(ctx context.Context, tx model.Transaction) (model.Transaction, error) {
 	const query = `
 		UPDATE transactions
-		SET account_id = $2, amount = $3, currency = $4, category = $5, description = $6, occurred_at = $7, created_at = $8, updated_at = $9
-		WHERE id = $1`
-	result, err := r.db.Exec(ctx, query, tx.ID, tx.AccountID, tx.Amount, tx.Currency, tx.Category, tx.Description, tx.OccurredAt, tx.CreatedAt, tx.UpdatedAt)
+		SET account_id = $2, amount = $3, currency = $4, category = $5, description = $6, occurred_at = $7, created_at = $8, updated_at = $9, wallet_id = $10, splits = $11, payee_id = $12
+		WHERE id = $1 AND deleted_at IS NULL`
+	splits, err := splitsJSON(tx.Splits)
+	if err != nil {
+		return model.Transaction{}, err
+	}
+	result, err := r.db.Exec(ctx, query, tx.ID, tx.AccountID, tx.Amount, tx.Currency, tx.Category, tx.Description, tx.OccurredAt, tx.CreatedAt, tx.UpdatedAt, tx.WalletID, splits, tx.PayeeID)
 	if err != nil {
 		return model.Transaction{}, err
 	}
 	if result.RowsAffected() == 0 {
 		return model.Transaction{}, storage.ErrNotFound
 	}
+	if err := r.saveTransactionTags(ctx, tx); err != nil {
+		return model.Transaction{}, err
+	}
 	return tx, nil
 }
 
 func (r *PostgresTransactionRepository) DeleteTransaction(ctx context.Context, id string) error {
-	const query = `DELETE FROM transactions WHERE id = $1`
+	const query = `UPDATE transactions SET deleted_at = now() WHERE id = $1 AND deleted_at IS NULL`
 	result, err := r.db.Exec(ctx, query, id)
 	if err != nil {
 		return err
@@ -110,8 +266,9 @@ func (r *PostgresTransactionRepository) DeleteTransaction(ctx context.Context, i
 
 func (r *PostgresTransactionRepository) ListTransactions(ctx context.Context) []model.Transaction {
 	const query = `
-		SELECT id, account_id, amount, currency, category, description, occurred_at, created_at, updated_at
-		FROM transactions`
+		SELECT id, account_id, amount, currency, category, description, occurred_at, created_by, wallet_id, transfer_id, refund_of, payee_id, splits, ` + transactionTagsColumn + `, created_at, updated_at
+		FROM transactions
+		WHERE deleted_at IS NULL`
 	rows, err := r.db.Query(ctx, query)
 	if err != nil {
 		return nil
@@ -121,6 +278,250 @@ func (r *PostgresTransactionRepository) ListTransactions(ctx context.Context) []
 	items := []model.Transaction{}
 	for rows.Next() {
 		var tx model.Transaction
+		var splits []byte
+		if err := rows.Scan(
+			&tx.ID,
+			&tx.AccountID,
+			&tx.Amount,
+			&tx.Currency,
+			&tx.Category,
+			&tx.Description,
+			&tx.OccurredAt,
+			&tx.CreatedBy,
+			&tx.WalletID,
+			&tx.TransferID,
+			&tx.RefundOf,
+			&tx.PayeeID,
+			&splits,
+			&tx.Tags,
+			&tx.CreatedAt,
+			&tx.UpdatedAt,
+		); err != nil {
+			return nil
+		}
+		if tx.Splits, err = decodeSplits(splits); err != nil {
+			return nil
+		}
+		items = append(items, tx)
+	}
+	if err := rows.Err(); err != nil {
+		return nil
+	}
+	return items
+}
+
+// transactionSortOrders whitelists ORDER BY clauses accepted from TransactionFilter.Sort.
+var transactionSortOrders = map[string]string{
+	"":                       "occurred_at DESC, id",
+	model.SortOccurredAtDesc: "occurred_at DESC, id",
+	model.SortOccurredAtAsc:  "occurred_at ASC, id",
+	model.SortAmountDesc:     "ABS(amount) DESC, id",
+	model.SortAmountAsc:      "ABS(amount) ASC, id",
+}
+
+// SearchTransactions filters transactions in SQL. The description search uses ILIKE,
+// which is served by the trigram index on transactions.description.
+func (r *PostgresTransactionRepository) SearchTransactions(ctx context.Context, filter model.TransactionFilter) ([]model.Transaction, error) {
+	order, ok := transactionSortOrders[filter.Sort]
+	if !ok {
+		return nil, fmt.Errorf("unsupported sort %q", filter.Sort)
+	}
+	conditions := []string{"account_id = $1", "deleted_at IS NULL"}
+	args := []any{filter.AccountID}
+	where := func(condition string, value any) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+	if !filter.From.IsZero() {
+		where("occurred_at >= $%d", filter.From)
+	}
+	if !filter.To.IsZero() {
+		where("occurred_at <= $%d", filter.To)
+	}
+	if filter.Category != "" {
+		where(`(category = $%[1]d OR splits @> jsonb_build_array(jsonb_build_object('category', $%[1]d::text)))`, filter.Category)
+	}
+	if filter.Currency != "" {
+		where("currency = $%d", filter.Currency)
+	}
+	if filter.WalletID != "" {
+		where("wallet_id = $%d", filter.WalletID)
+	}
+	if filter.PayeeID != "" {
+		where("payee_id = $%d", filter.PayeeID)
+	}
+	if filter.MinAmount != nil {
+		where("ABS(amount) >= $%d", *filter.MinAmount)
+	}
+	if filter.MaxAmount != nil {
+		where("ABS(amount) <= $%d", *filter.MaxAmount)
+	}
+	if filter.Query != "" {
+		where(`description ILIKE $%d ESCAPE '\'`, "%"+escapeLike(filter.Query)+"%")
+	}
+	if filter.Tag != "" {
+		where(`EXISTS (
+			SELECT 1 FROM transaction_tags JOIN tags ON tags.id = transaction_tags.tag_id
+			WHERE transaction_tags.transaction_id = transactions.id AND tags.name = $%d)`, filter.Tag)
+	}
+
+	query := `
+		SELECT id, account_id, amount, currency, category, description, occurred_at, created_by, wallet_id, transfer_id, refund_of, payee_id, splits, ` + transactionTagsColumn + `, created_at, updated_at
+		FROM transactions
+		WHERE ` + strings.Join(conditions, " AND ") + `
+		ORDER BY ` + order
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+	if filter.Offset > 0 {
+		args = append(args, filter.Offset)
+		query += fmt.Sprintf(" OFFSET $%d", len(args))
+	}
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []model.Transaction{}
+	for rows.Next() {
+		var tx model.Transaction
+		var splits []byte
+		if err := rows.Scan(
+			&tx.ID,
+			&tx.AccountID,
+			&tx.Amount,
+			&tx.Currency,
+			&tx.Category,
+			&tx.Description,
+			&tx.OccurredAt,
+			&tx.CreatedBy,
+			&tx.WalletID,
+			&tx.TransferID,
+			&tx.RefundOf,
+			&tx.PayeeID,
+			&splits,
+			&tx.Tags,
+			&tx.CreatedAt,
+			&tx.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		if tx.Splits, err = decodeSplits(splits); err != nil {
+			return nil, err
+		}
+		items = append(items, tx)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
+}
+
+// CreateTransactions inserts all transactions with a single COPY.
+func (r *PostgresTransactionRepository) CreateTransactions(ctx context.Context, txs []model.Transaction) error {
+	columns := []string{"id", "account_id", "amount", "currency", "category", "description", "occurred_at", "created_by", "wallet_id", "transfer_id", "refund_of", "payee_id", "splits", "created_at", "updated_at"}
+	_, err := r.db.CopyFrom(ctx, pgx.Identifier{"transactions"}, columns, pgx.CopyFromSlice(len(txs), func(i int) ([]any, error) {
+		tx := txs[i]
+		splits, err := splitsJSON(tx.Splits)
+		if err != nil {
+			return nil, err
+		}
+		return []any{tx.ID, tx.AccountID, tx.Amount, tx.Currency, tx.Category, tx.Description, tx.OccurredAt, tx.CreatedBy, tx.WalletID, tx.TransferID, tx.RefundOf, tx.PayeeID, splits, tx.CreatedAt, tx.UpdatedAt}, nil
+	}))
+	if err != nil {
+		return err
+	}
+	return r.saveTransactionTags(ctx, txs...)
+}
+
+// UpdateTransactions updates all transactions in one batch. It fails with
+// storage.ErrNotFound if any of them does not exist.
+func (r *PostgresTransactionRepository) UpdateTransactions(ctx context.Context, txs []model.Transaction) error {
+	const query = `
+		UPDATE transactions
+		SET account_id = $2, amount = $3, currency = $4, category = $5, description = $6, occurred_at = $7, created_at = $8, updated_at = $9, wallet_id = $10, splits = $11, payee_id = $12
+		WHERE id = $1 AND deleted_at IS NULL`
+	batch := &pgx.Batch{}
+	for _, tx := range txs {
+		splits, err := splitsJSON(tx.Splits)
+		if err != nil {
+			return err
+		}
+		batch.Queue(query, tx.ID, tx.AccountID, tx.Amount, tx.Currency, tx.Category, tx.Description, tx.OccurredAt, tx.CreatedAt, tx.UpdatedAt, tx.WalletID, splits, tx.PayeeID)
+	}
+	results := r.db.SendBatch(ctx, batch)
+	defer results.Close()
+	for range txs {
+		result, err := results.Exec()
+		if err != nil {
+			return err
+		}
+		if result.RowsAffected() == 0 {
+			return storage.ErrNotFound
+		}
+	}
+	if err := results.Close(); err != nil {
+		return err
+	}
+	return r.saveTransactionTags(ctx, txs...)
+}
+
+// DeleteTransactions soft-deletes all transactions with one statement. It fails with
+// storage.ErrNotFound if any of them does not exist.
+func (r *PostgresTransactionRepository) DeleteTransactions(ctx context.Context, ids []string) error {
+	const query = `UPDATE transactions SET deleted_at = now() WHERE id = ANY($1) AND deleted_at IS NULL`
+	result, err := r.db.Exec(ctx, query, ids)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() != int64(len(ids)) {
+		return storage.ErrNotFound
+	}
+	return nil
+}
+
+func (r *PostgresTransactionRepository) ExistingTransactionIDs(ctx context.Context, ids []string) ([]string, error) {
+	const query = `SELECT id FROM transactions WHERE id = ANY($1)`
+	rows, err := r.db.Query(ctx, query, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	existing := make([]string, 0)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		existing = append(existing, id)
+	}
+	return existing, rows.Err()
+}
+
+func (r *PostgresTransactionRepository) ListDeletedTransactions(ctx context.Context, accountID string) []model.Transaction {
+	const query = `
+		SELECT id, account_id, amount, currency, category, description, occurred_at, created_by, wallet_id, transfer_id, refund_of, payee_id, splits, ` + transactionTagsColumn + `, created_at, updated_at, deleted_at
+		FROM transactions
+		WHERE account_id = $1 AND deleted_at IS NOT NULL
+		ORDER BY deleted_at DESC`
+	rows, err := r.db.Query(ctx, query, accountID)
+	if err != nil {
+		return nil
+	}
+	defer rows.Close()
+
+	items := []model.Transaction{}
+	for rows.Next() {
+		var tx model.Transaction
+		var splits []byte
 		if err := rows.Scan(
 			&tx.ID,
 			&tx.AccountID,
@@ -129,11 +530,22 @@ func (r *PostgresTransactionRepository) ListTransactions(ctx context.Context) []
 			&tx.Category,
 			&tx.Description,
 			&tx.OccurredAt,
+			&tx.CreatedBy,
+			&tx.WalletID,
+			&tx.TransferID,
+			&tx.RefundOf,
+			&tx.PayeeID,
+			&splits,
+			&tx.Tags,
 			&tx.CreatedAt,
 			&tx.UpdatedAt,
+			&tx.DeletedAt,
 		); err != nil {
 			return nil
 		}
+		if tx.Splits, err = decodeSplits(splits); err != nil {
+			return nil
+		}
 		items = append(items, tx)
 	}
 	if err := rows.Err(); err != nil {
@@ -142,8 +554,100 @@ func (r *PostgresTransactionRepository) ListTransactions(ctx context.Context) []
 	return items
 }
 
+func (r *PostgresTransactionRepository) RestoreTransaction(ctx context.Context, accountID, id string) (model.Transaction, error) {
+	const query = `
+		UPDATE transactions
+		SET deleted_at = NULL
+		WHERE id = $1 AND account_id = $2 AND deleted_at IS NOT NULL
+		RETURNING id, account_id, amount, currency, category, description, occurred_at, created_by, wallet_id, transfer_id, refund_of, payee_id, splits, ` + transactionTagsColumn + `, created_at, updated_at`
+	var tx model.Transaction
+	var splits []byte
+	err := r.db.QueryRow(ctx, query, id, accountID).Scan(
+		&tx.ID,
+		&tx.AccountID,
+		&tx.Amount,
+		&tx.Currency,
+		&tx.Category,
+		&tx.Description,
+		&tx.OccurredAt,
+		&tx.CreatedBy,
+		&tx.WalletID,
+		&tx.TransferID,
+		&tx.RefundOf,
+		&tx.PayeeID,
+		&splits,
+		&tx.Tags,
+		&tx.CreatedAt,
+		&tx.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.Transaction{}, storage.ErrNotFound
+		}
+		return model.Transaction{}, err
+	}
+	if tx.Splits, err = decodeSplits(splits); err != nil {
+		return model.Transaction{}, err
+	}
+	return tx, nil
+}
+
+// transactionTagsColumn selects the sorted tag names of a transaction.
+const transactionTagsColumn = `ARRAY(
+			SELECT tags.name FROM transaction_tags JOIN tags ON tags.id = transaction_tags.tag_id
+			WHERE transaction_tags.transaction_id = transactions.id ORDER BY tags.name)`
+
+// saveTransactionTags replaces the tag links of the transactions. Tags are
+// matched by name within the account of the transaction and must exist.
+func (r *PostgresTransactionRepository) saveTransactionTags(ctx context.Context, txs ...model.Transaction) error {
+	const (
+		unlink = `DELETE FROM transaction_tags WHERE transaction_id = $1`
+		link   = `
+			INSERT INTO transaction_tags (transaction_id, tag_id)
+			SELECT $1, id FROM tags WHERE account_id = $2 AND name = ANY($3)`
+	)
+	batch := &pgx.Batch{}
+	for _, tx := range txs {
+		batch.Queue(unlink, tx.ID)
+		if len(tx.Tags) > 0 {
+			batch.Queue(link, tx.ID, tx.AccountID, tx.Tags)
+		}
+	}
+	return execBatch(ctx, r.db, batch)
+}
+
+// splitsJSON encodes the parts of a split transaction for the splits column.
+func splitsJSON(splits []model.TransactionSplit) ([]byte, error) {
+	if splits == nil {
+		splits = []model.TransactionSplit{}
+	}
+	return json.Marshal(splits)
+}
+
+func decodeSplits(data []byte) ([]model.TransactionSplit, error) {
+	var splits []model.TransactionSplit
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &splits); err != nil {
+			return nil, err
+		}
+	}
+	if len(splits) == 0 {
+		return nil, nil
+	}
+	return splits, nil
+}
+
+func (r *PostgresTransactionRepository) PurgeDeletedTransactions(ctx context.Context, before time.Time) (int64, error) {
+	const query = `DELETE FROM transactions WHERE deleted_at IS NOT NULL AND deleted_at < $1`
+	result, err := r.db.Exec(ctx, query, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 type PostgresBudgetRepository struct {
-	db *pgxpool.Pool
+	db querier
 }
 
 func NewPostgresBudgetRepository(db *pgxpool.Pool) *PostgresBudgetRepository {
@@ -165,7 +669,7 @@ func (r *PostgresBudgetRepository) GetBudget(ctx context.Context, accountID, id
 	const query = `
 		SELECT id, account_id, name, amount, currency, period, month, created_at, updated_at
 		FROM budgets
-		WHERE id = $1 AND account_id = $2`
+		WHERE id = $1 AND account_id = $2 AND deleted_at IS NULL`
 	var budget model.Budget
 	err := r.db.QueryRow(ctx, query, id, accountID).Scan(
 		&budget.ID,
@@ -191,7 +695,7 @@ func (r *PostgresBudgetRepository) UpdateBudget(ctx context.Context, budget mode
 	const query = `
 		UPDATE budgets
 		SET name = $3, amount = $4, currency = $5, period = $6, month = $7, created_at = $8, updated_at = $9
-		WHERE id = $1 AND account_id = $2`
+		WHERE id = $1 AND account_id = $2 AND deleted_at IS NULL`
 	result, err := r.db.Exec(ctx, query, budget.ID, budget.AccountID, budget.Name, budget.Amount, budget.Currency, budget.Period, budget.Month, budget.CreatedAt, budget.UpdatedAt)
 	if err != nil {
 		return model.Budget{}, err
@@ -203,7 +707,7 @@ func (r *PostgresBudgetRepository) UpdateBudget(ctx context.Context, budget mode
 }
 
 func (r *PostgresBudgetRepository) DeleteBudget(ctx context.Context, accountID, id string) error {
-	const query = `DELETE FROM budgets WHERE id = $1 AND account_id = $2`
+	const query = `UPDATE budgets SET deleted_at = now() WHERE id = $1 AND account_id = $2 AND deleted_at IS NULL`
 	result, err := r.db.Exec(ctx, query, id, accountID)
 	if err != nil {
 		return err
@@ -218,7 +722,43 @@ func (r *PostgresBudgetRepository) ListBudgets(ctx context.Context, accountID st
 	const query = `
 		SELECT id, account_id, name, amount, currency, period, month, created_at, updated_at
 		FROM budgets
-		WHERE account_id = $1`
+		WHERE account_id = $1 AND deleted_at IS NULL`
+	rows, err := r.db.Query(ctx, query, accountID)
+	if err != nil {
+		return nil
+	}
+	defer rows.Close()
+
+	items := []model.Budget{}
+	for rows.Next() {
+		var budget model.Budget
+		if err := rows.Scan(
+			&budget.ID,
+			&budget.AccountID,
+			&budget.Name,
+			&budget.Amount,
+			&budget.Currency,
+			&budget.Period,
+			&budget.Month,
+			&budget.CreatedAt,
+			&budget.UpdatedAt,
+		); err != nil {
+			return nil
+		}
+		items = append(items, budget)
+	}
+	if err := rows.Err(); err != nil {
+		return nil
+	}
+	return items
+}
+
+func (r *PostgresBudgetRepository) ListDeletedBudgets(ctx context.Context, accountID string) []model.Budget {
+	const query = `
+		SELECT id, account_id, name, amount, currency, period, month, created_at, updated_at, deleted_at
+		FROM budgets
+		WHERE account_id = $1 AND deleted_at IS NOT NULL
+		ORDER BY deleted_at DESC`
 	rows, err := r.db.Query(ctx, query, accountID)
 	if err != nil {
 		return nil
@@ -238,6 +778,7 @@ func (r *PostgresBudgetRepository) ListBudgets(ctx context.Context, accountID st
 			&budget.Month,
 			&budget.CreatedAt,
 			&budget.UpdatedAt,
+			&budget.DeletedAt,
 		); err != nil {
 			return nil
 		}
@@ -249,8 +790,44 @@ func (r *PostgresBudgetRepository) ListBudgets(ctx context.Context, accountID st
 	return items
 }
 
+func (r *PostgresBudgetRepository) RestoreBudget(ctx context.Context, accountID, id string) (model.Budget, error) {
+	const query = `
+		UPDATE budgets
+		SET deleted_at = NULL
+		WHERE id = $1 AND account_id = $2 AND deleted_at IS NOT NULL
+		RETURNING id, account_id, name, amount, currency, period, month, created_at, updated_at`
+	var budget model.Budget
+	err := r.db.QueryRow(ctx, query, id, accountID).Scan(
+		&budget.ID,
+		&budget.AccountID,
+		&budget.Name,
+		&budget.Amount,
+		&budget.Currency,
+		&budget.Period,
+		&budget.Month,
+		&budget.CreatedAt,
+		&budget.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.Budget{}, storage.ErrNotFound
+		}
+		return model.Budget{}, err
+	}
+	return budget, nil
+}
+
+func (r *PostgresBudgetRepository) PurgeDeletedBudgets(ctx context.Context, before time.Time) (int64, error) {
+	const query = `DELETE FROM budgets WHERE deleted_at IS NOT NULL AND deleted_at < $1`
+	result, err := r.db.Exec(ctx, query, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 type PostgresReportRepository struct {
-	db *pgxpool.Pool
+	db querier
 }
 
 func NewPostgresReportRepository(db *pgxpool.Pool) *PostgresReportRepository {
@@ -259,12 +836,24 @@ func NewPostgresReportRepository(db *pgxpool.Pool) *PostgresReportRepository {
 
 func (r *PostgresReportRepository) CreateReport(ctx context.Context, report model.Report) (model.Report, error) {
 	const query = `
-		INSERT INTO reports (id, account_id, name, period, generated_at, total_income, total_expense, currency, categories)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
+		INSERT INTO reports (id, account_id, name, period, generated_at, total_income, total_expense, currency, categories, tags, payees, goals)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`
 	categories, err := json.Marshal(report.Categories)
 	if err != nil {
 		return model.Report{}, err
 	}
+	tags, err := json.Marshal(report.Tags)
+	if err != nil {
+		return model.Report{}, err
+	}
+	payees, err := json.Marshal(report.Payees)
+	if err != nil {
+		return model.Report{}, err
+	}
+	goals, err := json.Marshal(report.Goals)
+	if err != nil {
+		return model.Report{}, err
+	}
 	_, err = r.db.Exec(
 		ctx,
 		query,
@@ -277,6 +866,9 @@ func (r *PostgresReportRepository) CreateReport(ctx context.Context, report mode
 		report.TotalExpense,
 		report.Currency,
 		categories,
+		tags,
+		payees,
+		goals,
 	)
 	if err != nil {
 		return model.Report{}, err
@@ -286,11 +878,11 @@ func (r *PostgresReportRepository) CreateReport(ctx context.Context, report mode
 
 func (r *PostgresReportRepository) GetReport(ctx context.Context, accountID, id string) (model.Report, error) {
 	const query = `
-		SELECT id, account_id, name, period, generated_at, total_income, total_expense, currency, categories
+		SELECT id, account_id, name, period, generated_at, total_income, total_expense, currency, categories, tags, payees, goals
 		FROM reports
-		WHERE id = $1 AND account_id = $2`
+		WHERE id = $1 AND account_id = $2 AND deleted_at IS NULL`
 	var report model.Report
-	var categories []byte
+	var categories, tags, payees, goals []byte
 	err := r.db.QueryRow(ctx, query, id, accountID).Scan(
 		&report.ID,
 		&report.AccountID,
@@ -301,6 +893,9 @@ func (r *PostgresReportRepository) GetReport(ctx context.Context, accountID, id
 		&report.TotalExpense,
 		&report.Currency,
 		&categories,
+		&tags,
+		&payees,
+		&goals,
 	)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -313,18 +908,45 @@ func (r *PostgresReportRepository) GetReport(ctx context.Context, accountID, id
 			return model.Report{}, err
 		}
 	}
+	if len(tags) > 0 {
+		if err := json.Unmarshal(tags, &report.Tags); err != nil {
+			return model.Report{}, err
+		}
+	}
+	if len(payees) > 0 {
+		if err := json.Unmarshal(payees, &report.Payees); err != nil {
+			return model.Report{}, err
+		}
+	}
+	if len(goals) > 0 {
+		if err := json.Unmarshal(goals, &report.Goals); err != nil {
+			return model.Report{}, err
+		}
+	}
 	return report, nil
 }
 
 func (r *PostgresReportRepository) UpdateReport(ctx context.Context, report model.Report) (model.Report, error) {
 	const query = `
 		UPDATE reports
-		SET name = $3, period = $4, generated_at = $5, total_income = $6, total_expense = $7, currency = $8, categories = $9
-		WHERE id = $1 AND account_id = $2`
+		SET name = $3, period = $4, generated_at = $5, total_income = $6, total_expense = $7, currency = $8, categories = $9, tags = $10, payees = $11, goals = $12
+		WHERE id = $1 AND account_id = $2 AND deleted_at IS NULL`
 	categories, err := json.Marshal(report.Categories)
 	if err != nil {
 		return model.Report{}, err
 	}
+	tags, err := json.Marshal(report.Tags)
+	if err != nil {
+		return model.Report{}, err
+	}
+	payees, err := json.Marshal(report.Payees)
+	if err != nil {
+		return model.Report{}, err
+	}
+	goals, err := json.Marshal(report.Goals)
+	if err != nil {
+		return model.Report{}, err
+	}
 	result, err := r.db.Exec(
 		ctx,
 		query,
@@ -337,6 +959,9 @@ func (r *PostgresReportRepository) UpdateReport(ctx context.Context, report mode
 		report.TotalExpense,
 		report.Currency,
 		categories,
+		tags,
+		payees,
+		goals,
 	)
 	if err != nil {
 		return model.Report{}, err
@@ -348,7 +973,7 @@ func (r *PostgresReportRepository) UpdateReport(ctx context.Context, report mode
 }
 
 func (r *PostgresReportRepository) DeleteReport(ctx context.Context, accountID, id string) error {
-	const query = `DELETE FROM reports WHERE id = $1 AND account_id = $2`
+	const query = `UPDATE reports SET deleted_at = now() WHERE id = $1 AND account_id = $2 AND deleted_at IS NULL`
 	result, err := r.db.Exec(ctx, query, id, accountID)
 	if err != nil {
 		return err
@@ -361,9 +986,9 @@ func (r *PostgresReportRepository) DeleteReport(ctx context.Context, accountID,
 
 func (r *PostgresReportRepository) ListReports(ctx context.Context, accountID string) []model.Report {
 	const query = `
-		SELECT id, account_id, name, period, generated_at, total_income, total_expense, currency, categories
+		SELECT id, account_id, name, period, generated_at, total_income, total_expense, currency, categories, tags, payees, goals
 		FROM reports
-		WHERE account_id = $1`
+		WHERE account_id = $1 AND deleted_at IS NULL`
 	rows, err := r.db.Query(ctx, query, accountID)
 	if err != nil {
 		return nil
@@ -373,7 +998,7 @@ func (r *PostgresReportRepository) ListReports(ctx context.Context, accountID st
 	items := []model.Report{}
 	for rows.Next() {
 		var report model.Report
-		var categories []byte
+		var categories, tags, payees, goals []byte
 		if err := rows.Scan(
 			&report.ID,
 			&report.AccountID,
@@ -384,6 +1009,9 @@ func (r *PostgresReportRepository) ListReports(ctx context.Context, accountID st
 			&report.TotalExpense,
 			&report.Currency,
 			&categories,
+			&tags,
+			&payees,
+			&goals,
 		); err != nil {
 			return nil
 		}
@@ -392,6 +1020,82 @@ func (r *PostgresReportRepository) ListReports(ctx context.Context, accountID st
 				return nil
 			}
 		}
+		if len(tags) > 0 {
+			if err := json.Unmarshal(tags, &report.Tags); err != nil {
+				return nil
+			}
+		}
+		if len(payees) > 0 {
+			if err := json.Unmarshal(payees, &report.Payees); err != nil {
+				return nil
+			}
+		}
+		if len(goals) > 0 {
+			if err := json.Unmarshal(goals, &report.Goals); err != nil {
+				return nil
+			}
+		}
+		items = append(items, report)
+	}
+	if err := rows.Err(); err != nil {
+		return nil
+	}
+	return items
+}
+
+func (r *PostgresReportRepository) ListDeletedReports(ctx context.Context, accountID string) []model.Report {
+	const query = `
+		SELECT id, account_id, name, period, generated_at, total_income, total_expense, currency, categories, tags, payees, goals, deleted_at
+		FROM reports
+		WHERE account_id = $1 AND deleted_at IS NOT NULL
+		ORDER BY deleted_at DESC`
+	rows, err := r.db.Query(ctx, query, accountID)
+	if err != nil {
+		return nil
+	}
+	defer rows.Close()
+
+	items := []model.Report{}
+	for rows.Next() {
+		var report model.Report
+		var categories, tags, payees, goals []byte
+		if err := rows.Scan(
+			&report.ID,
+			&report.AccountID,
+			&report.Name,
+			&report.Period,
+			&report.GeneratedAt,
+			&report.TotalIncome,
+			&report.TotalExpense,
+			&report.Currency,
+			&categories,
+			&tags,
+			&payees,
+			&goals,
+			&report.DeletedAt,
+		); err != nil {
+			return nil
+		}
+		if len(categories) > 0 {
+			if err := json.Unmarshal(categories, &report.Categories); err != nil {
+				return nil
+			}
+		}
+		if len(tags) > 0 {
+			if err := json.Unmarshal(tags, &report.Tags); err != nil {
+				return nil
+			}
+		}
+		if len(payees) > 0 {
+			if err := json.Unmarshal(payees, &report.Payees); err != nil {
+				return nil
+			}
+		}
+		if len(goals) > 0 {
+			if err := json.Unmarshal(goals, &report.Goals); err != nil {
+				return nil
+			}
+		}
 		items = append(items, report)
 	}
 	if err := rows.Err(); err != nil {
@@ -399,3 +1103,280 @@ func (r *PostgresReportRepository) ListReports(ctx context.Context, accountID st
 	}
 	return items
 }
+
+func (r *PostgresReportRepository) RestoreReport(ctx context.Context, accountID, id string) (model.Report, error) {
+	const query = `
+		UPDATE reports
+		SET deleted_at = NULL
+		WHERE id = $1 AND account_id = $2 AND deleted_at IS NOT NULL
+		RETURNING id, account_id, name, period, generated_at, total_income, total_expense, currency, categories, tags, payees, goals`
+	var report model.Report
+	var categories, tags, payees, goals []byte
+	err := r.db.QueryRow(ctx, query, id, accountID).Scan(
+		&report.ID,
+		&report.AccountID,
+		&report.Name,
+		&report.Period,
+		&report.GeneratedAt,
+		&report.TotalIncome,
+		&report.TotalExpense,
+		&report.Currency,
+		&categories,
+		&tags,
+		&payees,
+		&goals,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.Report{}, storage.ErrNotFound
+		}
+		return model.Report{}, err
+	}
+	if len(categories) > 0 {
+		if err := json.Unmarshal(categories, &report.Categories); err != nil {
+			return model.Report{}, err
+		}
+	}
+	if len(tags) > 0 {
+		if err := json.Unmarshal(tags, &report.Tags); err != nil {
+			return model.Report{}, err
+		}
+	}
+	if len(payees) > 0 {
+		if err := json.Unmarshal(payees, &report.Payees); err != nil {
+			return model.Report{}, err
+		}
+	}
+	if len(goals) > 0 {
+		if err := json.Unmarshal(goals, &report.Goals); err != nil {
+			return model.Report{}, err
+		}
+	}
+	return report, nil
+}
+
+func (r *PostgresReportRepository) PurgeDeletedReports(ctx context.Context, before time.Time) (int64, error) {
+	const query = `DELETE FROM reports WHERE deleted_at IS NOT NULL AND deleted_at < $1`
+	result, err := r.db.Exec(ctx, query, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+type PostgresEventRepository struct {
+	db querier
+}
+
+func NewPostgresEventRepository(db *pgxpool.Pool) *PostgresEventRepository {
+	return &PostgresEventRepository{db: db}
+}
+
+// outboxLockClass namespaces the advisory locks that serialize outbox writers
+// of an account.
+const outboxLockClass = 0x6c656467
+
+// AppendEvents writes events to the outbox. Offsets come from a sequence and
+// are assigned on insert, but only become visible on commit, so concurrent
+// writers could commit them out of order and a reader resuming after the
+// highest offset it has seen would skip the late one. Writers of an account
+// therefore hold a transaction-level advisory lock until they commit, which
+// makes the offsets of each account visible in increasing order.
+func (r *PostgresEventRepository) AppendEvents(ctx context.Context, events ...model.Event) error {
+	const lockQuery = `SELECT pg_advisory_xact_lock($1, hashtext($2))`
+	const query = `
+		INSERT INTO outbox_events (id, account_id, event_type, aggregate_id, payload, occurred_at)
+		VALUES ($1, $2, $3, $4, $5, $6)`
+	accountIDs := make([]string, 0, 1)
+	for _, event := range events {
+		if !slices.Contains(accountIDs, event.AccountID) {
+			accountIDs = append(accountIDs, event.AccountID)
+		}
+	}
+	// Locks are taken in a fixed order so writers of several accounts cannot deadlock.
+	slices.Sort(accountIDs)
+	batch := &pgx.Batch{}
+	for _, accountID := range accountIDs {
+		batch.Queue(lockQuery, outboxLockClass, accountID)
+	}
+	for _, event := range events {
+		batch.Queue(query, event.ID, event.AccountID, event.Type, event.AggregateID, event.Payload, event.OccurredAt)
+	}
+	return execBatch(ctx, r.db, batch)
+}
+
+// ListEvents returns the events of an account after the given offset. See
+// AppendEvents for why resuming from the last seen offset does not skip events.
+func (r *PostgresEventRepository) ListEvents(ctx context.Context, accountID string, afterOffset int64, limit int) ([]model.Event, error) {
+	const query = `
+		SELECT event_offset, id, account_id, event_type, aggregate_id, payload, occurred_at
+		FROM outbox_events
+		WHERE account_id = $1 AND event_offset > $2
+		ORDER BY event_offset
+		LIMIT $3`
+	return r.queryEvents(ctx, query, accountID, afterOffset, limit)
+}
+
+func (r *PostgresEventRepository) ListUnpublishedEvents(ctx context.Context, limit int) ([]model.Event, error) {
+	const query = `
+		SELECT event_offset, id, account_id, event_type, aggregate_id, payload, occurred_at
+		FROM outbox_events
+		WHERE published_at IS NULL
+		ORDER BY event_offset
+		LIMIT $1`
+	return r.queryEvents(ctx, query, limit)
+}
+
+func (r *PostgresEventRepository) MarkEventsPublished(ctx context.Context, offsets []int64) error {
+	const query = `UPDATE outbox_events SET published_at = NOW() WHERE event_offset = ANY($1)`
+	_, err := r.db.Exec(ctx, query, offsets)
+	return err
+}
+
+func (r *PostgresEventRepository) queryEvents(ctx context.Context, query string, args ...any) ([]model.Event, error) {
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []model.Event{}
+	for rows.Next() {
+		var event model.Event
+		if err := rows.Scan(
+			&event.Offset,
+			&event.ID,
+			&event.AccountID,
+			&event.Type,
+			&event.AggregateID,
+			&event.Payload,
+			&event.OccurredAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+type PostgresAuditRepository struct {
+	db querier
+}
+
+func NewPostgresAuditRepository(db *pgxpool.Pool) *PostgresAuditRepository {
+	return &PostgresAuditRepository{db: db}
+}
+
+func (r *PostgresAuditRepository) AppendAudit(ctx context.Context, entries ...model.AuditEntry) error {
+	const query = `
+		INSERT INTO audit_log (id, account_id, actor_id, entity_type, entity_id, operation, before, after, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
+	batch := &pgx.Batch{}
+	for _, entry := range entries {
+		batch.Queue(
+			query,
+			entry.ID,
+			entry.AccountID,
+			entry.ActorID,
+			entry.EntityType,
+			entry.EntityID,
+			entry.Operation,
+			nullableJSON(entry.Before),
+			nullableJSON(entry.After),
+			entry.CreatedAt,
+		)
+	}
+	return execBatch(ctx, r.db, batch)
+}
+
+func (r *PostgresAuditRepository) ListAudit(ctx context.Context, accountID, entityType, entityID string) ([]model.AuditEntry, error) {
+	const query = `
+		SELECT id, account_id, actor_id, entity_type, entity_id, operation, before, after, created_at
+		FROM audit_log
+		WHERE account_id = $1 AND entity_type = $2 AND entity_id = $3
+		ORDER BY created_at, id`
+	rows, err := r.db.Query(ctx, query, accountID, entityType, entityID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []model.AuditEntry{}
+	for rows.Next() {
+		var entry model.AuditEntry
+		if err := rows.Scan(
+			&entry.ID,
+			&entry.AccountID,
+			&entry.ActorID,
+			&entry.EntityType,
+			&entry.EntityID,
+			&entry.Operation,
+			&entry.Before,
+			&entry.After,
+			&entry.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// nullableJSON maps an empty snapshot to SQL NULL instead of an invalid empty JSONB value.
+func nullableJSON(data []byte) any {
+	if len(data) == 0 {
+		return nil
+	}
+	return data
+}
+
+func (r *PostgresTransactionRepository) PurgeAccount(ctx context.Context, accountID string) (int64, error) {
+	const query = `DELETE FROM transactions WHERE account_id = $1`
+	result, err := r.db.Exec(ctx, query, accountID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+func (r *PostgresBudgetRepository) PurgeAccount(ctx context.Context, accountID string) (int64, error) {
+	const query = `DELETE FROM budgets WHERE account_id = $1`
+	result, err := r.db.Exec(ctx, query, accountID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+func (r *PostgresReportRepository) PurgeAccount(ctx context.Context, accountID string) (int64, error) {
+	const query = `DELETE FROM reports WHERE account_id = $1`
+	result, err := r.db.Exec(ctx, query, accountID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+func (r *PostgresEventRepository) PurgeAccount(ctx context.Context, accountID string) (int64, error) {
+	const query = `DELETE FROM outbox_events WHERE account_id = $1`
+	result, err := r.db.Exec(ctx, query, accountID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+func (r *PostgresAuditRepository) PurgeAccount(ctx context.Context, accountID string) (int64, error) {
+	const query = `DELETE FROM audit_log WHERE account_id = $1`
+	result, err := r.db.Exec(ctx, query, accountID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
@@ -0,0 +1,256 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/model"
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/storage"
+)
+
+type PostgresAccountRepository struct {
+	db querier
+}
+
+func NewPostgresAccountRepository(db *pgxpool.Pool) *PostgresAccountRepository {
+	return &PostgresAccountRepository{db: db}
+}
+
+// CreateAccount inserts the account together with its first owner.
+func (r *PostgresAccountRepository) CreateAccount(ctx context.Context, account model.Account, owner model.AccountMember) error {
+	batch := &pgx.Batch{}
+	batch.Queue(
+		`INSERT INTO accounts (id, name, created_by, created_at) VALUES ($1, $2, $3, $4)`,
+		account.ID, account.Name, account.CreatedBy, account.CreatedAt,
+	)
+	batch.Queue(
+		`INSERT INTO account_members (account_id, user_id, role, created_at) VALUES ($1, $2, $3, $4)`,
+		owner.AccountID, owner.UserID, owner.Role, owner.CreatedAt,
+	)
+	return execBatch(ctx, r.db, batch)
+}
+
+func (r *PostgresAccountRepository) GetAccount(ctx context.Context, id string) (model.Account, error) {
+	const query = `SELECT id, name, created_by, created_at FROM accounts WHERE id = $1`
+	var account model.Account
+	err := r.db.QueryRow(ctx, query, id).Scan(&account.ID, &account.Name, &account.CreatedBy, &account.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.Account{}, storage.ErrNotFound
+		}
+		return model.Account{}, err
+	}
+	return account, nil
+}
+
+// ListAccounts returns the shared accounts the user is a member of, with the
+// role of the user.
+func (r *PostgresAccountRepository) ListAccounts(ctx context.Context, userID string) ([]model.Account, error) {
+	const query = `
+		SELECT a.id, a.name, a.created_by, a.created_at, m.role
+		FROM accounts a
+		JOIN account_members m ON m.account_id = a.id
+		WHERE m.user_id = $1
+		ORDER BY a.created_at, a.id`
+	rows, err := r.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []model.Account{}
+	for rows.Next() {
+		var account model.Account
+		if err := rows.Scan(&account.ID, &account.Name, &account.CreatedBy, &account.CreatedAt, &account.Role); err != nil {
+			return nil, err
+		}
+		items = append(items, account)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (r *PostgresAccountRepository) GetMember(ctx context.Context, accountID, userID string) (model.AccountMember, error) {
+	const query = `
+		SELECT account_id, user_id, role, created_at
+		FROM account_members
+		WHERE account_id = $1 AND user_id = $2`
+	var member model.AccountMember
+	err := r.db.QueryRow(ctx, query, accountID, userID).Scan(&member.AccountID, &member.UserID, &member.Role, &member.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.AccountMember{}, storage.ErrNotFound
+		}
+		return model.AccountMember{}, err
+	}
+	return member, nil
+}
+
+func (r *PostgresAccountRepository) ListMembers(ctx context.Context, accountID string) ([]model.AccountMember, error) {
+	const query = `
+		SELECT account_id, user_id, role, created_at
+		FROM account_members
+		WHERE account_id = $1
+		ORDER BY created_at, user_id`
+	rows, err := r.db.Query(ctx, query, accountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []model.AccountMember{}
+	for rows.Next() {
+		var member model.AccountMember
+		if err := rows.Scan(&member.AccountID, &member.UserID, &member.Role, &member.CreatedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, member)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (r *PostgresAccountRepository) AddMember(ctx context.Context, member model.AccountMember) error {
+	const query = `
+		INSERT INTO account_members (account_id, user_id, role, created_at)
+		VALUES ($1, $2, $3, $4)`
+	_, err := r.db.Exec(ctx, query, member.AccountID, member.UserID, member.Role, member.CreatedAt)
+	return err
+}
+
+func (r *PostgresAccountRepository) UpdateMember(ctx context.Context, member model.AccountMember) error {
+	const query = `UPDATE account_members SET role = $3 WHERE account_id = $1 AND user_id = $2`
+	result, err := r.db.Exec(ctx, query, member.AccountID, member.UserID, member.Role)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
+
+func (r *PostgresAccountRepository) RemoveMember(ctx context.Context, accountID, userID string) error {
+	const query = `DELETE FROM account_members WHERE account_id = $1 AND user_id = $2`
+	result, err := r.db.Exec(ctx, query, accountID, userID)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
+
+func (r *PostgresAccountRepository) CreateInvitation(ctx context.Context, invitation model.Invitation) error {
+	const query = `
+		INSERT INTO account_invitations (id, account_id, token_hash, role, created_by, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`
+	_, err := r.db.Exec(
+		ctx,
+		query,
+		invitation.ID,
+		invitation.AccountID,
+		invitation.TokenHash,
+		invitation.Role,
+		invitation.CreatedBy,
+		invitation.CreatedAt,
+		invitation.ExpiresAt,
+	)
+	return err
+}
+
+// ListInvitations returns the invitations of the account that can still be accepted.
+func (r *PostgresAccountRepository) ListInvitations(ctx context.Context, accountID string, now time.Time) ([]model.Invitation, error) {
+	const query = `
+		SELECT id, account_id, token_hash, role, created_by, created_at, expires_at
+		FROM account_invitations
+		WHERE account_id = $1 AND accepted_at IS NULL AND expires_at > $2
+		ORDER BY created_at, id`
+	rows, err := r.db.Query(ctx, query, accountID, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []model.Invitation{}
+	for rows.Next() {
+		var invitation model.Invitation
+		if err := rows.Scan(
+			&invitation.ID,
+			&invitation.AccountID,
+			&invitation.TokenHash,
+			&invitation.Role,
+			&invitation.CreatedBy,
+			&invitation.CreatedAt,
+			&invitation.ExpiresAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, invitation)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// DeleteInvitation revokes an invitation that has not been accepted yet.
+func (r *PostgresAccountRepository) DeleteInvitation(ctx context.Context, accountID, id string) error {
+	const query = `DELETE FROM account_invitations WHERE id = $1 AND account_id = $2 AND accepted_at IS NULL`
+	result, err := r.db.Exec(ctx, query, id, accountID)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
+
+// AcceptInvitation marks a pending, unexpired invitation as accepted by the
+// user. Unknown, used and expired tokens are reported as storage.ErrNotFound.
+func (r *PostgresAccountRepository) AcceptInvitation(ctx context.Context, tokenHash, userID string, now time.Time) (model.Invitation, error) {
+	const query = `
+		UPDATE account_invitations
+		SET accepted_by = $2, accepted_at = $3
+		WHERE token_hash = $1 AND accepted_at IS NULL AND expires_at > $3
+		RETURNING id, account_id, token_hash, role, created_by, created_at, expires_at, accepted_by, accepted_at`
+	var invitation model.Invitation
+	err := r.db.QueryRow(ctx, query, tokenHash, userID, now).Scan(
+		&invitation.ID,
+		&invitation.AccountID,
+		&invitation.TokenHash,
+		&invitation.Role,
+		&invitation.CreatedBy,
+		&invitation.CreatedAt,
+		&invitation.ExpiresAt,
+		&invitation.AcceptedBy,
+		&invitation.AcceptedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.Invitation{}, storage.ErrNotFound
+		}
+		return model.Invitation{}, err
+	}
+	return invitation, nil
+}
+
+// PurgeAccount removes the memberships of a deleted user.
+func (r *PostgresAccountRepository) PurgeAccount(ctx context.Context, userID string) (int64, error) {
+	const query = `DELETE FROM account_members WHERE user_id = $1`
+	result, err := r.db.Exec(ctx, query, userID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
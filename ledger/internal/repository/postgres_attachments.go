@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/model"
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/storage"
+)
+
+const attachmentColumns = `
+		id, account_id, transaction_id, file_name, content_type, size, checksum, storage_key, created_by, created_at`
+
+type PostgresAttachmentRepository struct {
+	db querier
+}
+
+func NewPostgresAttachmentRepository(db *pgxpool.Pool) *PostgresAttachmentRepository {
+	return &PostgresAttachmentRepository{db: db}
+}
+
+func (r *PostgresAttachmentRepository) CreateAttachment(ctx context.Context, attachment model.Attachment) error {
+	const query = `
+		INSERT INTO attachments (` + attachmentColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`
+	_, err := r.db.Exec(ctx, query,
+		attachment.ID,
+		attachment.AccountID,
+		attachment.TransactionID,
+		attachment.FileName,
+		attachment.ContentType,
+		attachment.Size,
+		attachment.Checksum,
+		attachment.StorageKey,
+		attachment.CreatedBy,
+		attachment.CreatedAt,
+	)
+	return err
+}
+
+func (r *PostgresAttachmentRepository) GetAttachment(ctx context.Context, accountID, id string) (model.Attachment, error) {
+	const query = `
+		SELECT` + attachmentColumns + `
+		FROM attachments
+		WHERE id = $1 AND account_id = $2`
+	attachment, err := scanAttachment(r.db.QueryRow(ctx, query, id, accountID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.Attachment{}, storage.ErrNotFound
+		}
+		return model.Attachment{}, err
+	}
+	return attachment, nil
+}
+
+func (r *PostgresAttachmentRepository) DeleteAttachment(ctx context.Context, accountID, id string) error {
+	const query = `DELETE FROM attachments WHERE id = $1 AND account_id = $2`
+	result, err := r.db.Exec(ctx, query, id, accountID)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
+
+func (r *PostgresAttachmentRepository) ListAttachments(ctx context.Context, accountID, transactionID string) ([]model.Attachment, error) {
+	const query = `
+		SELECT` + attachmentColumns + `
+		FROM attachments
+		WHERE account_id = $1 AND ($2 = '' OR transaction_id = $2)
+		ORDER BY created_at, id`
+	return r.list(ctx, query, accountID, transactionID)
+}
+
+// ListExpiredAttachments matches the rows that PurgeDeletedTransactions
+// removes through the foreign key cascade.
+func (r *PostgresAttachmentRepository) ListExpiredAttachments(ctx context.Context, before time.Time) ([]model.Attachment, error) {
+	const query = `
+		SELECT` + attachmentColumns + `
+		FROM attachments
+		WHERE transaction_id IN (
+			SELECT id FROM transactions WHERE deleted_at IS NOT NULL AND deleted_at < $1
+		)
+		ORDER BY created_at, id`
+	return r.list(ctx, query, before)
+}
+
+func (r *PostgresAttachmentRepository) PurgeAccount(ctx context.Context, accountID string) (int64, error) {
+	const query = `DELETE FROM attachments WHERE account_id = $1`
+	result, err := r.db.Exec(ctx, query, accountID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+func (r *PostgresAttachmentRepository) list(ctx context.Context, query string, args ...any) ([]model.Attachment, error) {
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []model.Attachment{}
+	for rows.Next() {
+		attachment, err := scanAttachment(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, attachment)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func scanAttachment(row pgx.Row) (model.Attachment, error) {
+	var attachment model.Attachment
+	err := row.Scan(
+		&attachment.ID,
+		&attachment.AccountID,
+		&attachment.TransactionID,
+		&attachment.FileName,
+		&attachment.ContentType,
+		&attachment.Size,
+		&attachment.Checksum,
+		&attachment.StorageKey,
+		&attachment.CreatedBy,
+		&attachment.CreatedAt,
+	)
+	return attachment, err
+}
@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/model"
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/storage"
+)
+
+type PostgresGoalRepository struct {
+	db querier
+}
+
+func NewPostgresGoalRepository(db *pgxpool.Pool) *PostgresGoalRepository {
+	return &PostgresGoalRepository{db: db}
+}
+
+func (r *PostgresGoalRepository) CreateGoal(ctx context.Context, goal model.Goal) error {
+	const query = `
+		INSERT INTO goals (id, account_id, name, target_amount, currency, deadline, category, wallet_id, tag, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`
+	_, err := r.db.Exec(ctx, query, goal.ID, goal.AccountID, goal.Name, goal.TargetAmount, goal.Currency, goal.Deadline, goal.Category, goal.WalletID, goal.Tag, goal.CreatedAt, goal.UpdatedAt)
+	return err
+}
+
+func (r *PostgresGoalRepository) GetGoal(ctx context.Context, accountID, id string) (model.Goal, error) {
+	const query = `
+		SELECT id, account_id, name, target_amount, currency, deadline, category, wallet_id, tag, created_at, updated_at
+		FROM goals
+		WHERE id = $1 AND account_id = $2`
+	goal, err := scanGoal(r.db.QueryRow(ctx, query, id, accountID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.Goal{}, storage.ErrNotFound
+		}
+		return model.Goal{}, err
+	}
+	return goal, nil
+}
+
+func (r *PostgresGoalRepository) UpdateGoal(ctx context.Context, goal model.Goal) error {
+	const query = `
+		UPDATE goals
+		SET name = $3, target_amount = $4, currency = $5, deadline = $6, category = $7, wallet_id = $8, tag = $9, updated_at = $10
+		WHERE id = $1 AND account_id = $2`
+	result, err := r.db.Exec(ctx, query, goal.ID, goal.AccountID, goal.Name, goal.TargetAmount, goal.Currency, goal.Deadline, goal.Category, goal.WalletID, goal.Tag, goal.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
+
+func (r *PostgresGoalRepository) DeleteGoal(ctx context.Context, accountID, id string) error {
+	const query = `DELETE FROM goals WHERE id = $1 AND account_id = $2`
+	result, err := r.db.Exec(ctx, query, id, accountID)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
+
+func (r *PostgresGoalRepository) ListGoals(ctx context.Context, accountID string) ([]model.Goal, error) {
+	const query = `
+		SELECT id, account_id, name, target_amount, currency, deadline, category, wallet_id, tag, created_at, updated_at
+		FROM goals
+		WHERE account_id = $1
+		ORDER BY deadline, name, id`
+	rows, err := r.db.Query(ctx, query, accountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []model.Goal{}
+	for rows.Next() {
+		goal, err := scanGoal(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, goal)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (r *PostgresGoalRepository) PurgeAccount(ctx context.Context, accountID string) (int64, error) {
+	const query = `DELETE FROM goals WHERE account_id = $1`
+	result, err := r.db.Exec(ctx, query, accountID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+func scanGoal(row pgx.Row) (model.Goal, error) {
+	var goal model.Goal
+	err := row.Scan(
+		&goal.ID,
+		&goal.AccountID,
+		&goal.Name,
+		&goal.TargetAmount,
+		&goal.Currency,
+		&goal.Deadline,
+		&goal.Category,
+		&goal.WalletID,
+		&goal.Tag,
+		&goal.CreatedAt,
+		&goal.UpdatedAt,
+	)
+	return goal, err
+}
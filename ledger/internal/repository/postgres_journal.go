@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/model"
+)
+
+type PostgresJournalRepository struct {
+	db querier
+}
+
+func NewPostgresJournalRepository(db *pgxpool.Pool) *PostgresJournalRepository {
+	return &PostgresJournalRepository{db: db}
+}
+
+// SaveJournalEntries replaces the entries of the transactions. Postings of
+// replaced entries are removed by the cascading foreign key.
+func (r *PostgresJournalRepository) SaveJournalEntries(ctx context.Context, entries []model.JournalEntry) error {
+	ids := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		ids = append(ids, entry.TransactionID)
+	}
+	if err := r.DeleteJournalEntries(ctx, ids); err != nil {
+		return err
+	}
+
+	columns := []string{"transaction_id", "account_id", "occurred_at", "description", "posted_at"}
+	_, err := r.db.CopyFrom(ctx, pgx.Identifier{"journal_entries"}, columns, pgx.CopyFromSlice(len(entries), func(i int) ([]any, error) {
+		entry := entries[i]
+		return []any{entry.TransactionID, entry.AccountID, entry.OccurredAt, entry.Description, entry.PostedAt}, nil
+	}))
+	if err != nil {
+		return err
+	}
+
+	rows := make([][]any, 0, 2*len(entries))
+	for _, entry := range entries {
+		for line, posting := range entry.Postings {
+			rows = append(rows, []any{entry.TransactionID, line, posting.Account, posting.Type, posting.Currency, posting.Amount})
+		}
+	}
+	columns = []string{"transaction_id", "line", "journal_account", "account_type", "currency", "amount"}
+	_, err = r.db.CopyFrom(ctx, pgx.Identifier{"journal_postings"}, columns, pgx.CopyFromRows(rows))
+	return err
+}
+
+func (r *PostgresJournalRepository) DeleteJournalEntries(ctx context.Context, transactionIDs []string) error {
+	const query = `DELETE FROM journal_entries WHERE transaction_id = ANY($1)`
+	_, err := r.db.Exec(ctx, query, transactionIDs)
+	return err
+}
+
+func (r *PostgresJournalRepository) ListJournalEntries(ctx context.Context, accountID string) ([]model.JournalEntry, error) {
+	const query = `
+		SELECT e.transaction_id, e.account_id, e.occurred_at, e.description, e.posted_at,
+			p.journal_account, p.account_type, p.currency, p.amount
+		FROM journal_entries e
+		JOIN journal_postings p ON p.transaction_id = e.transaction_id
+		WHERE e.account_id = $1
+		ORDER BY e.occurred_at, e.transaction_id, p.line`
+	rows, err := r.db.Query(ctx, query, accountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []model.JournalEntry{}
+	for rows.Next() {
+		var entry model.JournalEntry
+		var posting model.Posting
+		if err := rows.Scan(
+			&entry.TransactionID,
+			&entry.AccountID,
+			&entry.OccurredAt,
+			&entry.Description,
+			&entry.PostedAt,
+			&posting.Account,
+			&posting.Type,
+			&posting.Currency,
+			&posting.Amount,
+		); err != nil {
+			return nil, err
+		}
+		if n := len(items); n > 0 && items[n-1].TransactionID == entry.TransactionID {
+			items[n-1].Postings = append(items[n-1].Postings, posting)
+			continue
+		}
+		entry.Postings = []model.Posting{posting}
+		items = append(items, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (r *PostgresJournalRepository) PurgeAccount(ctx context.Context, accountID string) (int64, error) {
+	const query = `DELETE FROM journal_entries WHERE account_id = $1`
+	result, err := r.db.Exec(ctx, query, accountID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
@@ -2,26 +2,91 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/model"
 )
 
 type PostgresLedgerRepository struct {
+	pool         *pgxpool.Pool
 	transactions *PostgresTransactionRepository
 	budgets      *PostgresBudgetRepository
 	reports      *PostgresReportRepository
+	events       *PostgresEventRepository
+	audit        *PostgresAuditRepository
+	accounts     *PostgresAccountRepository
+	wallets      *PostgresWalletRepository
+	tags         *PostgresTagRepository
+	attachments  *PostgresAttachmentRepository
+	rules        *PostgresRuleRepository
+	payees       *PostgresPayeeRepository
+	goals        *PostgresGoalRepository
+	journal      *PostgresJournalRepository
 }
 
 func NewPostgresLedgerRepository(db *pgxpool.Pool) *PostgresLedgerRepository {
 	return &PostgresLedgerRepository{
+		pool:         db,
 		transactions: NewPostgresTransactionRepository(db),
 		budgets:      NewPostgresBudgetRepository(db),
 		reports:      NewPostgresReportRepository(db),
+		events:       NewPostgresEventRepository(db),
+		audit:        NewPostgresAuditRepository(db),
+		accounts:     NewPostgresAccountRepository(db),
+		wallets:      NewPostgresWalletRepository(db),
+		tags:         NewPostgresTagRepository(db),
+		attachments:  NewPostgresAttachmentRepository(db),
+		rules:        NewPostgresRuleRepository(db),
+		payees:       NewPostgresPayeeRepository(db),
+		goals:        NewPostgresGoalRepository(db),
+		journal:      NewPostgresJournalRepository(db),
 	}
 }
 
+func newTxLedgerRepository(tx pgx.Tx) *PostgresLedgerRepository {
+	return &PostgresLedgerRepository{
+		transactions: &PostgresTransactionRepository{db: tx},
+		budgets:      &PostgresBudgetRepository{db: tx},
+		reports:      &PostgresReportRepository{db: tx},
+		events:       &PostgresEventRepository{db: tx},
+		audit:        &PostgresAuditRepository{db: tx},
+		accounts:     &PostgresAccountRepository{db: tx},
+		wallets:      &PostgresWalletRepository{db: tx},
+		tags:         &PostgresTagRepository{db: tx},
+		attachments:  &PostgresAttachmentRepository{db: tx},
+		rules:        &PostgresRuleRepository{db: tx},
+		payees:       &PostgresPayeeRepository{db: tx},
+		goals:        &PostgresGoalRepository{db: tx},
+		journal:      &PostgresJournalRepository{db: tx},
+	}
+}
+
+func (r *PostgresLedgerRepository) RunInTx(ctx context.Context, fn func(repo LedgerRepository) error) error {
+	if r.pool == nil {
+		// Already bound to a transaction: join it.
+		return fn(r)
+	}
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	if err := fn(newTxLedgerRepository(tx)); err != nil {
+		if rollbackErr := tx.Rollback(ctx); rollbackErr != nil && !errors.Is(rollbackErr, pgx.ErrTxClosed) {
+			return fmt.Errorf("%w (rollback error: %v)", err, rollbackErr)
+		}
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit tx: %w", err)
+	}
+	return nil
+}
+
 func (r *PostgresLedgerRepository) CreateTransaction(ctx context.Context, tx model.Transaction) (model.Transaction, error) {
 	return r.transactions.CreateTransaction(ctx, tx)
 }
@@ -30,6 +95,10 @@ func (r *PostgresLedgerRepository) GetTransaction(ctx context.Context, id string
 	return r.transactions.GetTransaction(ctx, id)
 }
 
+func (r *PostgresLedgerRepository) LockTransaction(ctx context.Context, id string) (model.Transaction, error) {
+	return r.transactions.LockTransaction(ctx, id)
+}
+
 func (r *PostgresLedgerRepository) UpdateTransaction(ctx context.Context, tx model.Transaction) (model.Transaction, error) {
 	return r.transactions.UpdateTransaction(ctx, tx)
 }
@@ -42,6 +111,38 @@ func (r *PostgresLedgerRepository) ListTransactions(ctx context.Context) []model
 	return r.transactions.ListTransactions(ctx)
 }
 
+func (r *PostgresLedgerRepository) SearchTransactions(ctx context.Context, filter model.TransactionFilter) ([]model.Transaction, error) {
+	return r.transactions.SearchTransactions(ctx, filter)
+}
+
+func (r *PostgresLedgerRepository) CreateTransactions(ctx context.Context, txs []model.Transaction) error {
+	return r.transactions.CreateTransactions(ctx, txs)
+}
+
+func (r *PostgresLedgerRepository) UpdateTransactions(ctx context.Context, txs []model.Transaction) error {
+	return r.transactions.UpdateTransactions(ctx, txs)
+}
+
+func (r *PostgresLedgerRepository) DeleteTransactions(ctx context.Context, ids []string) error {
+	return r.transactions.DeleteTransactions(ctx, ids)
+}
+
+func (r *PostgresLedgerRepository) ListDeletedTransactions(ctx context.Context, accountID string) []model.Transaction {
+	return r.transactions.ListDeletedTransactions(ctx, accountID)
+}
+
+func (r *PostgresLedgerRepository) ExistingTransactionIDs(ctx context.Context, ids []string) ([]string, error) {
+	return r.transactions.ExistingTransactionIDs(ctx, ids)
+}
+
+func (r *PostgresLedgerRepository) RestoreTransaction(ctx context.Context, accountID, id string) (model.Transaction, error) {
+	return r.transactions.RestoreTransaction(ctx, accountID, id)
+}
+
+func (r *PostgresLedgerRepository) PurgeDeletedTransactions(ctx context.Context, before time.Time) (int64, error) {
+	return r.transactions.PurgeDeletedTransactions(ctx, before)
+}
+
 func (r *PostgresLedgerRepository) CreateBudget(ctx context.Context, budget model.Budget) (model.Budget, error) {
 	return r.budgets.CreateBudget(ctx, budget)
 }
@@ -62,6 +163,18 @@ func (r *PostgresLedgerRepository) ListBudgets(ctx context.Context, accountID st
 	return r.budgets.ListBudgets(ctx, accountID)
 }
 
+func (r *PostgresLedgerRepository) ListDeletedBudgets(ctx context.Context, accountID string) []model.Budget {
+	return r.budgets.ListDeletedBudgets(ctx, accountID)
+}
+
+func (r *PostgresLedgerRepository) RestoreBudget(ctx context.Context, accountID, id string) (model.Budget, error) {
+	return r.budgets.RestoreBudget(ctx, accountID, id)
+}
+
+func (r *PostgresLedgerRepository) PurgeDeletedBudgets(ctx context.Context, before time.Time) (int64, error) {
+	return r.budgets.PurgeDeletedBudgets(ctx, before)
+}
+
 func (r *PostgresLedgerRepository) CreateReport(ctx context.Context, report model.Report) (model.Report, error) {
 	return r.reports.CreateReport(ctx, report)
 }
@@ -81,3 +194,248 @@ func (r *PostgresLedgerRepository) DeleteReport(ctx context.Context, accountID,
 func (r *PostgresLedgerRepository) ListReports(ctx context.Context, accountID string) []model.Report {
 	return r.reports.ListReports(ctx, accountID)
 }
+
+func (r *PostgresLedgerRepository) ListDeletedReports(ctx context.Context, accountID string) []model.Report {
+	return r.reports.ListDeletedReports(ctx, accountID)
+}
+
+func (r *PostgresLedgerRepository) RestoreReport(ctx context.Context, accountID, id string) (model.Report, error) {
+	return r.reports.RestoreReport(ctx, accountID, id)
+}
+
+func (r *PostgresLedgerRepository) PurgeDeletedReports(ctx context.Context, before time.Time) (int64, error) {
+	return r.reports.PurgeDeletedReports(ctx, before)
+}
+
+func (r *PostgresLedgerRepository) AppendEvents(ctx context.Context, events ...model.Event) error {
+	return r.events.AppendEvents(ctx, events...)
+}
+
+func (r *PostgresLedgerRepository) ListEvents(ctx context.Context, accountID string, afterOffset int64, limit int) ([]model.Event, error) {
+	return r.events.ListEvents(ctx, accountID, afterOffset, limit)
+}
+
+func (r *PostgresLedgerRepository) ListUnpublishedEvents(ctx context.Context, limit int) ([]model.Event, error) {
+	return r.events.ListUnpublishedEvents(ctx, limit)
+}
+
+func (r *PostgresLedgerRepository) MarkEventsPublished(ctx context.Context, offsets []int64) error {
+	return r.events.MarkEventsPublished(ctx, offsets)
+}
+
+func (r *PostgresLedgerRepository) AppendAudit(ctx context.Context, entries ...model.AuditEntry) error {
+	return r.audit.AppendAudit(ctx, entries...)
+}
+
+func (r *PostgresLedgerRepository) ListAudit(ctx context.Context, accountID, entityType, entityID string) ([]model.AuditEntry, error) {
+	return r.audit.ListAudit(ctx, accountID, entityType, entityID)
+}
+
+func (r *PostgresLedgerRepository) CreateAccount(ctx context.Context, account model.Account, owner model.AccountMember) error {
+	return r.accounts.CreateAccount(ctx, account, owner)
+}
+
+func (r *PostgresLedgerRepository) GetAccount(ctx context.Context, id string) (model.Account, error) {
+	return r.accounts.GetAccount(ctx, id)
+}
+
+func (r *PostgresLedgerRepository) ListAccounts(ctx context.Context, userID string) ([]model.Account, error) {
+	return r.accounts.ListAccounts(ctx, userID)
+}
+
+func (r *PostgresLedgerRepository) GetMember(ctx context.Context, accountID, userID string) (model.AccountMember, error) {
+	return r.accounts.GetMember(ctx, accountID, userID)
+}
+
+func (r *PostgresLedgerRepository) ListMembers(ctx context.Context, accountID string) ([]model.AccountMember, error) {
+	return r.accounts.ListMembers(ctx, accountID)
+}
+
+func (r *PostgresLedgerRepository) AddMember(ctx context.Context, member model.AccountMember) error {
+	return r.accounts.AddMember(ctx, member)
+}
+
+func (r *PostgresLedgerRepository) UpdateMember(ctx context.Context, member model.AccountMember) error {
+	return r.accounts.UpdateMember(ctx, member)
+}
+
+func (r *PostgresLedgerRepository) RemoveMember(ctx context.Context, accountID, userID string) error {
+	return r.accounts.RemoveMember(ctx, accountID, userID)
+}
+
+func (r *PostgresLedgerRepository) CreateInvitation(ctx context.Context, invitation model.Invitation) error {
+	return r.accounts.CreateInvitation(ctx, invitation)
+}
+
+func (r *PostgresLedgerRepository) ListInvitations(ctx context.Context, accountID string, now time.Time) ([]model.Invitation, error) {
+	return r.accounts.ListInvitations(ctx, accountID, now)
+}
+
+func (r *PostgresLedgerRepository) DeleteInvitation(ctx context.Context, accountID, id string) error {
+	return r.accounts.DeleteInvitation(ctx, accountID, id)
+}
+
+func (r *PostgresLedgerRepository) AcceptInvitation(ctx context.Context, tokenHash, userID string, now time.Time) (model.Invitation, error) {
+	return r.accounts.AcceptInvitation(ctx, tokenHash, userID, now)
+}
+
+func (r *PostgresLedgerRepository) CreateWallet(ctx context.Context, wallet model.Wallet) error {
+	return r.wallets.CreateWallet(ctx, wallet)
+}
+
+func (r *PostgresLedgerRepository) GetWallet(ctx context.Context, accountID, id string) (model.Wallet, error) {
+	return r.wallets.GetWallet(ctx, accountID, id)
+}
+
+func (r *PostgresLedgerRepository) UpdateWallet(ctx context.Context, wallet model.Wallet) error {
+	return r.wallets.UpdateWallet(ctx, wallet)
+}
+
+func (r *PostgresLedgerRepository) DeleteWallet(ctx context.Context, accountID, id string) error {
+	return r.wallets.DeleteWallet(ctx, accountID, id)
+}
+
+func (r *PostgresLedgerRepository) ListWallets(ctx context.Context, accountID string) ([]model.Wallet, error) {
+	return r.wallets.ListWallets(ctx, accountID)
+}
+
+func (r *PostgresLedgerRepository) CreateTag(ctx context.Context, tag model.Tag) error {
+	return r.tags.CreateTag(ctx, tag)
+}
+
+func (r *PostgresLedgerRepository) GetTag(ctx context.Context, accountID, id string) (model.Tag, error) {
+	return r.tags.GetTag(ctx, accountID, id)
+}
+
+func (r *PostgresLedgerRepository) UpdateTag(ctx context.Context, tag model.Tag) error {
+	return r.tags.UpdateTag(ctx, tag)
+}
+
+func (r *PostgresLedgerRepository) DeleteTag(ctx context.Context, accountID, id string) error {
+	return r.tags.DeleteTag(ctx, accountID, id)
+}
+
+func (r *PostgresLedgerRepository) ListTags(ctx context.Context, accountID string) ([]model.Tag, error) {
+	return r.tags.ListTags(ctx, accountID)
+}
+
+func (r *PostgresLedgerRepository) CreateAttachment(ctx context.Context, attachment model.Attachment) error {
+	return r.attachments.CreateAttachment(ctx, attachment)
+}
+
+func (r *PostgresLedgerRepository) GetAttachment(ctx context.Context, accountID, id string) (model.Attachment, error) {
+	return r.attachments.GetAttachment(ctx, accountID, id)
+}
+
+func (r *PostgresLedgerRepository) DeleteAttachment(ctx context.Context, accountID, id string) error {
+	return r.attachments.DeleteAttachment(ctx, accountID, id)
+}
+
+func (r *PostgresLedgerRepository) ListAttachments(ctx context.Context, accountID, transactionID string) ([]model.Attachment, error) {
+	return r.attachments.ListAttachments(ctx, accountID, transactionID)
+}
+
+func (r *PostgresLedgerRepository) ListExpiredAttachments(ctx context.Context, before time.Time) ([]model.Attachment, error) {
+	return r.attachments.ListExpiredAttachments(ctx, before)
+}
+
+func (r *PostgresLedgerRepository) CreateRule(ctx context.Context, rule model.CategoryRule) error {
+	return r.rules.CreateRule(ctx, rule)
+}
+
+func (r *PostgresLedgerRepository) GetRule(ctx context.Context, accountID, id string) (model.CategoryRule, error) {
+	return r.rules.GetRule(ctx, accountID, id)
+}
+
+func (r *PostgresLedgerRepository) UpdateRule(ctx context.Context, rule model.CategoryRule) error {
+	return r.rules.UpdateRule(ctx, rule)
+}
+
+func (r *PostgresLedgerRepository) DeleteRule(ctx context.Context, accountID, id string) error {
+	return r.rules.DeleteRule(ctx, accountID, id)
+}
+
+func (r *PostgresLedgerRepository) ListRules(ctx context.Context, accountID string) ([]model.CategoryRule, error) {
+	return r.rules.ListRules(ctx, accountID)
+}
+
+func (r *PostgresLedgerRepository) CreatePayee(ctx context.Context, payee model.Payee) error {
+	return r.payees.CreatePayee(ctx, payee)
+}
+
+func (r *PostgresLedgerRepository) GetPayee(ctx context.Context, accountID, id string) (model.Payee, error) {
+	return r.payees.GetPayee(ctx, accountID, id)
+}
+
+func (r *PostgresLedgerRepository) UpdatePayee(ctx context.Context, payee model.Payee) error {
+	return r.payees.UpdatePayee(ctx, payee)
+}
+
+func (r *PostgresLedgerRepository) DeletePayee(ctx context.Context, accountID, id string) error {
+	return r.payees.DeletePayee(ctx, accountID, id)
+}
+
+func (r *PostgresLedgerRepository) ListPayees(ctx context.Context, accountID string) ([]model.Payee, error) {
+	return r.payees.ListPayees(ctx, accountID)
+}
+
+func (r *PostgresLedgerRepository) CreateGoal(ctx context.Context, goal model.Goal) error {
+	return r.goals.CreateGoal(ctx, goal)
+}
+
+func (r *PostgresLedgerRepository) GetGoal(ctx context.Context, accountID, id string) (model.Goal, error) {
+	return r.goals.GetGoal(ctx, accountID, id)
+}
+
+func (r *PostgresLedgerRepository) UpdateGoal(ctx context.Context, goal model.Goal) error {
+	return r.goals.UpdateGoal(ctx, goal)
+}
+
+func (r *PostgresLedgerRepository) DeleteGoal(ctx context.Context, accountID, id string) error {
+	return r.goals.DeleteGoal(ctx, accountID, id)
+}
+
+func (r *PostgresLedgerRepository) ListGoals(ctx context.Context, accountID string) ([]model.Goal, error) {
+	return r.goals.ListGoals(ctx, accountID)
+}
+
+func (r *PostgresLedgerRepository) SaveJournalEntries(ctx context.Context, entries []model.JournalEntry) error {
+	return r.journal.SaveJournalEntries(ctx, entries)
+}
+
+func (r *PostgresLedgerRepository) DeleteJournalEntries(ctx context.Context, transactionIDs []string) error {
+	return r.journal.DeleteJournalEntries(ctx, transactionIDs)
+}
+
+func (r *PostgresLedgerRepository) ListJournalEntries(ctx context.Context, accountID string) ([]model.JournalEntry, error) {
+	return r.journal.ListJournalEntries(ctx, accountID)
+}
+
+func (r *PostgresLedgerRepository) PurgeAccount(ctx context.Context, accountID string) (int64, error) {
+	purgers := []func(context.Context, string) (int64, error){
+		// Attachments go first: they would otherwise be removed uncounted by
+		// the cascade from their transactions.
+		r.attachments.PurgeAccount,
+		r.transactions.PurgeAccount,
+		r.budgets.PurgeAccount,
+		r.reports.PurgeAccount,
+		r.events.PurgeAccount,
+		r.audit.PurgeAccount,
+		r.accounts.PurgeAccount,
+		r.wallets.PurgeAccount,
+		r.tags.PurgeAccount,
+		r.rules.PurgeAccount,
+		r.payees.PurgeAccount,
+		r.goals.PurgeAccount,
+		r.journal.PurgeAccount,
+	}
+	var purged int64
+	for _, purge := range purgers {
+		count, err := purge(ctx, accountID)
+		if err != nil {
+			return 0, err
+		}
+		purged += count
+	}
+	return purged, nil
+}
@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/model"
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/storage"
+)
+
+type PostgresPayeeRepository struct {
+	db querier
+}
+
+func NewPostgresPayeeRepository(db *pgxpool.Pool) *PostgresPayeeRepository {
+	return &PostgresPayeeRepository{db: db}
+}
+
+func (r *PostgresPayeeRepository) CreatePayee(ctx context.Context, payee model.Payee) error {
+	const query = `
+		INSERT INTO payees (id, account_id, name, aliases, default_category, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`
+	_, err := r.db.Exec(ctx, query, payee.ID, payee.AccountID, payee.Name, payeeAliases(payee.Aliases), payee.DefaultCategory, payee.CreatedAt, payee.UpdatedAt)
+	return err
+}
+
+func (r *PostgresPayeeRepository) GetPayee(ctx context.Context, accountID, id string) (model.Payee, error) {
+	const query = `
+		SELECT id, account_id, name, aliases, default_category, created_at, updated_at
+		FROM payees
+		WHERE id = $1 AND account_id = $2`
+	payee, err := scanPayee(r.db.QueryRow(ctx, query, id, accountID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.Payee{}, storage.ErrNotFound
+		}
+		return model.Payee{}, err
+	}
+	return payee, nil
+}
+
+func (r *PostgresPayeeRepository) UpdatePayee(ctx context.Context, payee model.Payee) error {
+	const query = `
+		UPDATE payees
+		SET name = $3, aliases = $4, default_category = $5, updated_at = $6
+		WHERE id = $1 AND account_id = $2`
+	result, err := r.db.Exec(ctx, query, payee.ID, payee.AccountID, payee.Name, payeeAliases(payee.Aliases), payee.DefaultCategory, payee.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
+
+// DeletePayee removes the payee and unlinks its transactions, including
+// deleted ones, so that a restored transaction does not refer to it.
+func (r *PostgresPayeeRepository) DeletePayee(ctx context.Context, accountID, id string) error {
+	const unlink = `UPDATE transactions SET payee_id = '' WHERE account_id = $1 AND payee_id = $2`
+	if _, err := r.db.Exec(ctx, unlink, accountID, id); err != nil {
+		return err
+	}
+	const query = `DELETE FROM payees WHERE id = $1 AND account_id = $2`
+	result, err := r.db.Exec(ctx, query, id, accountID)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
+
+func (r *PostgresPayeeRepository) ListPayees(ctx context.Context, accountID string) ([]model.Payee, error) {
+	const query = `
+		SELECT id, account_id, name, aliases, default_category, created_at, updated_at
+		FROM payees
+		WHERE account_id = $1
+		ORDER BY name, id`
+	rows, err := r.db.Query(ctx, query, accountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []model.Payee{}
+	for rows.Next() {
+		payee, err := scanPayee(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, payee)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (r *PostgresPayeeRepository) PurgeAccount(ctx context.Context, accountID string) (int64, error) {
+	const query = `DELETE FROM payees WHERE account_id = $1`
+	result, err := r.db.Exec(ctx, query, accountID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+func scanPayee(row pgx.Row) (model.Payee, error) {
+	var payee model.Payee
+	err := row.Scan(
+		&payee.ID,
+		&payee.AccountID,
+		&payee.Name,
+		&payee.Aliases,
+		&payee.DefaultCategory,
+		&payee.CreatedAt,
+		&payee.UpdatedAt,
+	)
+	return payee, err
+}
+
+// payeeAliases keeps a payee without aliases from being stored as NULL.
+func payeeAliases(aliases []string) []string {
+	if aliases == nil {
+		return []string{}
+	}
+	return aliases
+}
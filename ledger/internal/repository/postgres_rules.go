@@ -0,0 +1,160 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/model"
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/storage"
+)
+
+const ruleColumns = `
+		id, account_id, name, priority, description_contains, description_pattern, min_amount, max_amount,
+		type, currency, category, enabled, created_at, updated_at`
+
+type PostgresRuleRepository struct {
+	db querier
+}
+
+func NewPostgresRuleRepository(db *pgxpool.Pool) *PostgresRuleRepository {
+	return &PostgresRuleRepository{db: db}
+}
+
+func (r *PostgresRuleRepository) CreateRule(ctx context.Context, rule model.CategoryRule) error {
+	const query = `
+		INSERT INTO category_rules (` + ruleColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)`
+	_, err := r.db.Exec(ctx, query,
+		rule.ID,
+		rule.AccountID,
+		rule.Name,
+		rule.Priority,
+		rule.DescriptionContains,
+		rule.DescriptionPattern,
+		rule.MinAmount,
+		rule.MaxAmount,
+		rule.Type,
+		rule.Currency,
+		rule.Category,
+		rule.Enabled,
+		rule.CreatedAt,
+		rule.UpdatedAt,
+	)
+	return err
+}
+
+func (r *PostgresRuleRepository) GetRule(ctx context.Context, accountID, id string) (model.CategoryRule, error) {
+	const query = `
+		SELECT` + ruleColumns + `
+		FROM category_rules
+		WHERE id = $1 AND account_id = $2`
+	rule, err := scanRule(r.db.QueryRow(ctx, query, id, accountID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.CategoryRule{}, storage.ErrNotFound
+		}
+		return model.CategoryRule{}, err
+	}
+	return rule, nil
+}
+
+func (r *PostgresRuleRepository) UpdateRule(ctx context.Context, rule model.CategoryRule) error {
+	const query = `
+		UPDATE category_rules
+		SET name = $3, priority = $4, description_contains = $5, description_pattern = $6, min_amount = $7,
+			max_amount = $8, type = $9, currency = $10, category = $11, enabled = $12, updated_at = $13
+		WHERE id = $1 AND account_id = $2`
+	result, err := r.db.Exec(ctx, query,
+		rule.ID,
+		rule.AccountID,
+		rule.Name,
+		rule.Priority,
+		rule.DescriptionContains,
+		rule.DescriptionPattern,
+		rule.MinAmount,
+		rule.MaxAmount,
+		rule.Type,
+		rule.Currency,
+		rule.Category,
+		rule.Enabled,
+		rule.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
+
+func (r *PostgresRuleRepository) DeleteRule(ctx context.Context, accountID, id string) error {
+	const query = `DELETE FROM category_rules WHERE id = $1 AND account_id = $2`
+	result, err := r.db.Exec(ctx, query, id, accountID)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
+
+func (r *PostgresRuleRepository) ListRules(ctx context.Context, accountID string) ([]model.CategoryRule, error) {
+	const query = `
+		SELECT` + ruleColumns + `
+		FROM category_rules
+		WHERE account_id = $1
+		ORDER BY priority, created_at, id`
+	rows, err := r.db.Query(ctx, query, accountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []model.CategoryRule{}
+	for rows.Next() {
+		rule, err := scanRule(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, rule)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (r *PostgresRuleRepository) PurgeAccount(ctx context.Context, accountID string) (int64, error) {
+	const query = `DELETE FROM category_rules WHERE account_id = $1`
+	result, err := r.db.Exec(ctx, query, accountID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+func scanRule(row pgx.Row) (model.CategoryRule, error) {
+	var rule model.CategoryRule
+	err := row.Scan(
+		&rule.ID,
+		&rule.AccountID,
+		&rule.Name,
+		&rule.Priority,
+		&rule.DescriptionContains,
+		&rule.DescriptionPattern,
+		&rule.MinAmount,
+		&rule.MaxAmount,
+		&rule.Type,
+		&rule.Currency,
+		&rule.Category,
+		&rule.Enabled,
+		&rule.CreatedAt,
+		&rule.UpdatedAt,
+	)
+	return rule, err
+}
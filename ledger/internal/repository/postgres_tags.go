@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/model"
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/storage"
+)
+
+type PostgresTagRepository struct {
+	db querier
+}
+
+func NewPostgresTagRepository(db *pgxpool.Pool) *PostgresTagRepository {
+	return &PostgresTagRepository{db: db}
+}
+
+func (r *PostgresTagRepository) CreateTag(ctx context.Context, tag model.Tag) error {
+	const query = `
+		INSERT INTO tags (id, account_id, name, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5)`
+	_, err := r.db.Exec(ctx, query, tag.ID, tag.AccountID, tag.Name, tag.CreatedAt, tag.UpdatedAt)
+	return err
+}
+
+func (r *PostgresTagRepository) GetTag(ctx context.Context, accountID, id string) (model.Tag, error) {
+	const query = `
+		SELECT id, account_id, name, created_at, updated_at
+		FROM tags
+		WHERE id = $1 AND account_id = $2`
+	var tag model.Tag
+	err := r.db.QueryRow(ctx, query, id, accountID).Scan(
+		&tag.ID,
+		&tag.AccountID,
+		&tag.Name,
+		&tag.CreatedAt,
+		&tag.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.Tag{}, storage.ErrNotFound
+		}
+		return model.Tag{}, err
+	}
+	return tag, nil
+}
+
+// UpdateTag renames the tag. Transactions link to tags by ID, so they pick up
+// the new name without being rewritten.
+func (r *PostgresTagRepository) UpdateTag(ctx context.Context, tag model.Tag) error {
+	const query = `
+		UPDATE tags
+		SET name = $3, updated_at = $4
+		WHERE id = $1 AND account_id = $2`
+	result, err := r.db.Exec(ctx, query, tag.ID, tag.AccountID, tag.Name, tag.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
+
+// DeleteTag removes the tag; its links to transactions are removed by the
+// foreign key cascade.
+func (r *PostgresTagRepository) DeleteTag(ctx context.Context, accountID, id string) error {
+	const query = `DELETE FROM tags WHERE id = $1 AND account_id = $2`
+	result, err := r.db.Exec(ctx, query, id, accountID)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
+
+func (r *PostgresTagRepository) ListTags(ctx context.Context, accountID string) ([]model.Tag, error) {
+	const query = `
+		SELECT id, account_id, name, created_at, updated_at
+		FROM tags
+		WHERE account_id = $1
+		ORDER BY name`
+	rows, err := r.db.Query(ctx, query, accountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []model.Tag{}
+	for rows.Next() {
+		var tag model.Tag
+		if err := rows.Scan(
+			&tag.ID,
+			&tag.AccountID,
+			&tag.Name,
+			&tag.CreatedAt,
+			&tag.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, tag)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (r *PostgresTagRepository) PurgeAccount(ctx context.Context, accountID string) (int64, error) {
+	const query = `DELETE FROM tags WHERE account_id = $1`
+	result, err := r.db.Exec(ctx, query, accountID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
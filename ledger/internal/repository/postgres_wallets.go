@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/model"
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/storage"
+)
+
+type PostgresWalletRepository struct {
+	db querier
+}
+
+func NewPostgresWalletRepository(db *pgxpool.Pool) *PostgresWalletRepository {
+	return &PostgresWalletRepository{db: db}
+}
+
+func (r *PostgresWalletRepository) CreateWallet(ctx context.Context, wallet model.Wallet) error {
+	const query = `
+		INSERT INTO wallets (id, account_id, name, type, currency, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`
+	_, err := r.db.Exec(ctx, query, wallet.ID, wallet.AccountID, wallet.Name, wallet.Type, wallet.Currency, wallet.CreatedAt, wallet.UpdatedAt)
+	return err
+}
+
+func (r *PostgresWalletRepository) GetWallet(ctx context.Context, accountID, id string) (model.Wallet, error) {
+	const query = `
+		SELECT id, account_id, name, type, currency, created_at, updated_at
+		FROM wallets
+		WHERE id = $1 AND account_id = $2`
+	var wallet model.Wallet
+	err := r.db.QueryRow(ctx, query, id, accountID).Scan(
+		&wallet.ID,
+		&wallet.AccountID,
+		&wallet.Name,
+		&wallet.Type,
+		&wallet.Currency,
+		&wallet.CreatedAt,
+		&wallet.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.Wallet{}, storage.ErrNotFound
+		}
+		return model.Wallet{}, err
+	}
+	return wallet, nil
+}
+
+func (r *PostgresWalletRepository) UpdateWallet(ctx context.Context, wallet model.Wallet) error {
+	const query = `
+		UPDATE wallets
+		SET name = $3, type = $4, currency = $5, updated_at = $6
+		WHERE id = $1 AND account_id = $2`
+	result, err := r.db.Exec(ctx, query, wallet.ID, wallet.AccountID, wallet.Name, wallet.Type, wallet.Currency, wallet.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
+
+func (r *PostgresWalletRepository) DeleteWallet(ctx context.Context, accountID, id string) error {
+	const query = `DELETE FROM wallets WHERE id = $1 AND account_id = $2`
+	result, err := r.db.Exec(ctx, query, id, accountID)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
+
+func (r *PostgresWalletRepository) ListWallets(ctx context.Context, accountID string) ([]model.Wallet, error) {
+	const query = `
+		SELECT id, account_id, name, type, currency, created_at, updated_at
+		FROM wallets
+		WHERE account_id = $1
+		ORDER BY created_at, id`
+	rows, err := r.db.Query(ctx, query, accountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []model.Wallet{}
+	for rows.Next() {
+		var wallet model.Wallet
+		if err := rows.Scan(
+			&wallet.ID,
+			&wallet.AccountID,
+			&wallet.Name,
+			&wallet.Type,
+			&wallet.Currency,
+			&wallet.CreatedAt,
+			&wallet.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, wallet)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (r *PostgresWalletRepository) PurgeAccount(ctx context.Context, accountID string) (int64, error) {
+	const query = `DELETE FROM wallets WHERE account_id = $1`
+	result, err := r.db.Exec(ctx, query, accountID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
@@ -0,0 +1,56 @@
+package retention
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+)
+
+// Store permanently removes soft-deleted entities deleted before the cutoff.
+type Store interface {
+	PurgeDeleted(ctx context.Context, before time.Time) (int64, error)
+}
+
+// Purger periodically empties the trash of entities older than the retention period.
+type Purger struct {
+	store     Store
+	retention time.Duration
+	interval  time.Duration
+	now       func() time.Time
+}
+
+func NewPurger(store Store, retention, interval time.Duration) *Purger {
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	return &Purger{store: store, retention: retention, interval: interval, now: time.Now}
+}
+
+func (p *Purger) Run(ctx context.Context) error {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		purged, err := p.PurgeExpired(ctx)
+		if err != nil {
+			log.Printf("trash purger: %v", err)
+		} else if purged > 0 {
+			log.Printf("trash purger: removed %d entities", purged)
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// PurgeExpired removes entities that have been in the trash longer than the retention period.
+func (p *Purger) PurgeExpired(ctx context.Context) (int64, error) {
+	cutoff := p.now().UTC().Add(-p.retention)
+	purged, err := p.store.PurgeDeleted(ctx, cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("purge deleted before %s: %w", cutoff.Format(time.RFC3339), err)
+	}
+	return purged, nil
+}
@@ -0,0 +1,50 @@
+package service
+
+import (
+	"context"
+
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/model"
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/repository"
+)
+
+type accountPurgedPayload struct {
+	PurgedRecords int64 `json:"purged_records"`
+}
+
+// PurgeAccount permanently removes all ledger data of a deleted user account,
+// including the content of its attachments.
+// An AccountPurged event is recorded so that consumers can drop their copies.
+// Purging an account that has no data is not an error. Only internal clients
+// purge accounts.
+func (s *DefaultLedgerService) PurgeAccount(ctx context.Context, accountID string) (int64, error) {
+	if err := authorizeInternal(ctx); err != nil {
+		return 0, err
+	}
+	reports := append(s.repo.ListReports(ctx, accountID), s.repo.ListDeletedReports(ctx, accountID)...)
+
+	var purged int64
+	var attachments []model.Attachment
+	err := s.repo.RunInTx(ctx, func(repo repository.LedgerRepository) error {
+		var err error
+		attachments, err = repo.ListAttachments(ctx, accountID, "")
+		if err != nil {
+			return err
+		}
+		count, err := repo.PurgeAccount(ctx, accountID)
+		if err != nil {
+			return err
+		}
+		purged = count
+		return appendEvent(ctx, repo, model.EventAccountPurged, accountID, accountID, accountPurgedPayload{PurgedRecords: count})
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	s.deleteBlobs(ctx, attachments...)
+	for _, report := range reports {
+		s.invalidateReportCache(ctx, report.ID)
+	}
+	s.invalidateBudgetCache(ctx)
+	return purged, nil
+}
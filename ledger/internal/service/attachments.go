@@ -0,0 +1,197 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/actor"
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/model"
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/repository"
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/storage"
+)
+
+const (
+	// maxAttachmentSize limits the content of a single attachment.
+	maxAttachmentSize = 10 << 20
+	// attachmentSniffLength is how much content is inspected to detect its type.
+	attachmentSniffLength = 512
+)
+
+// attachmentContentTypes are the content types accepted for attachments:
+// photos of receipts and PDF invoices. The type is detected from the content,
+// not taken from the client.
+var attachmentContentTypes = map[string]bool{
+	"image/jpeg":      true,
+	"image/png":       true,
+	"image/gif":       true,
+	"image/webp":      true,
+	"application/pdf": true,
+}
+
+var (
+	errAttachmentsDisabled = errors.New("attachment storage is not configured")
+	errAttachmentTooLarge  = fmt.Errorf("%w: attachment must not exceed %d bytes", ErrValidation, maxAttachmentSize)
+)
+
+// UploadAttachment stores the content of a file attached to a live transaction.
+// The content is written to the blob store before the metadata, and removed
+// again if the metadata cannot be saved.
+func (s *DefaultLedgerService) UploadAttachment(ctx context.Context, attachment model.Attachment, content io.Reader) (model.Attachment, error) {
+	if s.blobs == nil {
+		return model.Attachment{}, errAttachmentsDisabled
+	}
+	tx, err := s.repo.GetTransaction(ctx, attachment.TransactionID)
+	if err != nil {
+		return model.Attachment{}, err
+	}
+	if tx.AccountID != attachment.AccountID {
+		return model.Attachment{}, storage.ErrNotFound
+	}
+
+	reader := bufio.NewReaderSize(content, attachmentSniffLength)
+	head, err := reader.Peek(attachmentSniffLength)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return model.Attachment{}, err
+	}
+	if len(head) == 0 {
+		return model.Attachment{}, fmt.Errorf("%w: attachment is empty", ErrValidation)
+	}
+	contentType, _, _ := strings.Cut(http.DetectContentType(head), ";")
+	if !attachmentContentTypes[contentType] {
+		return model.Attachment{}, fmt.Errorf("%w: unsupported attachment type %s", ErrValidation, contentType)
+	}
+
+	attachment.ID = uuid.NewString()
+	attachment.ContentType = contentType
+	attachment.StorageKey = attachment.AccountID + "/" + attachment.ID
+	attachment.CreatedBy = actor.UserIDFromContext(ctx)
+	attachment.CreatedAt = time.Now().UTC()
+
+	body := &attachmentReader{r: reader, hash: sha256.New()}
+	if err := s.blobs.Put(ctx, attachment.StorageKey, body); err != nil {
+		s.deleteBlobs(ctx, attachment)
+		if body.size > maxAttachmentSize {
+			return model.Attachment{}, errAttachmentTooLarge
+		}
+		return model.Attachment{}, fmt.Errorf("store attachment: %w", err)
+	}
+	attachment.Size = body.size
+	attachment.Checksum = hex.EncodeToString(body.hash.Sum(nil))
+
+	err = s.repo.RunInTx(ctx, func(repo repository.LedgerRepository) error {
+		if err := repo.CreateAttachment(ctx, attachment); err != nil {
+			return err
+		}
+		if err := appendAudit(ctx, repo, model.AuditEntityAttachment, attachment.AccountID, attachment.ID, model.AuditOperationCreate, nil, attachment); err != nil {
+			return err
+		}
+		return appendEvent(ctx, repo, model.EventAttachmentChanged, attachment.AccountID, attachment.ID, attachmentChangedPayload{Operation: changeOperationCreated, Attachment: attachment})
+	})
+	if err != nil {
+		s.deleteBlobs(ctx, attachment)
+		return model.Attachment{}, err
+	}
+	return attachment, nil
+}
+
+func (s *DefaultLedgerService) GetAttachment(ctx context.Context, accountID, id string) (model.Attachment, error) {
+	return s.repo.GetAttachment(ctx, accountID, id)
+}
+
+// OpenAttachment returns the metadata and the content of an attachment. The
+// caller closes the content.
+func (s *DefaultLedgerService) OpenAttachment(ctx context.Context, accountID, id string) (model.Attachment, io.ReadCloser, error) {
+	if s.blobs == nil {
+		return model.Attachment{}, nil, errAttachmentsDisabled
+	}
+	attachment, err := s.repo.GetAttachment(ctx, accountID, id)
+	if err != nil {
+		return model.Attachment{}, nil, err
+	}
+	content, err := s.blobs.Open(ctx, attachment.StorageKey)
+	if err != nil {
+		return model.Attachment{}, nil, fmt.Errorf("open attachment %s: %w", attachment.ID, err)
+	}
+	return attachment, content, nil
+}
+
+// ListAttachments returns the attachments of a live transaction in upload
+// order. Attachments of trashed transactions come back with a restore.
+func (s *DefaultLedgerService) ListAttachments(ctx context.Context, accountID, transactionID string) ([]model.Attachment, error) {
+	tx, err := s.repo.GetTransaction(ctx, transactionID)
+	if err != nil {
+		return nil, err
+	}
+	if tx.AccountID != accountID {
+		return nil, storage.ErrNotFound
+	}
+	return s.repo.ListAttachments(ctx, accountID, transactionID)
+}
+
+// DeleteAttachment removes an attachment. Its content is removed from the
+// blob store once the metadata is gone.
+func (s *DefaultLedgerService) DeleteAttachment(ctx context.Context, accountID, id string) error {
+	var current model.Attachment
+	err := s.repo.RunInTx(ctx, func(repo repository.LedgerRepository) error {
+		var err error
+		current, err = repo.GetAttachment(ctx, accountID, id)
+		if err != nil {
+			return err
+		}
+		if err := repo.DeleteAttachment(ctx, accountID, id); err != nil {
+			return err
+		}
+		if err := appendAudit(ctx, repo, model.AuditEntityAttachment, current.AccountID, current.ID, model.AuditOperationDelete, current, nil); err != nil {
+			return err
+		}
+		return appendEvent(ctx, repo, model.EventAttachmentChanged, current.AccountID, current.ID, attachmentChangedPayload{Operation: changeOperationDeleted, Attachment: current})
+	})
+	if err != nil {
+		return err
+	}
+	s.deleteBlobs(ctx, current)
+	return nil
+}
+
+// deleteBlobs removes the content of attachments whose metadata is gone. A
+// failure only leaves an orphaned object behind, so it is logged.
+func (s *DefaultLedgerService) deleteBlobs(ctx context.Context, attachments ...model.Attachment) {
+	if s.blobs == nil {
+		return
+	}
+	for _, attachment := range attachments {
+		if err := s.blobs.Delete(ctx, attachment.StorageKey); err != nil {
+			log.Printf("delete attachment %s content: %v", attachment.ID, err)
+		}
+	}
+}
+
+// attachmentReader hashes and counts the content passing through it and fails
+// once the content exceeds maxAttachmentSize.
+type attachmentReader struct {
+	r    io.Reader
+	hash hash.Hash
+	size int64
+}
+
+func (r *attachmentReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.size += int64(n)
+	r.hash.Write(p[:n])
+	if r.size > maxAttachmentSize {
+		return n, errAttachmentTooLarge
+	}
+	return n, err
+}
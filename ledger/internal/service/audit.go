@@ -0,0 +1,57 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/actor"
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/model"
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/repository"
+)
+
+func (s *DefaultLedgerService) ListHistory(ctx context.Context, accountID, entityType, entityID string) ([]model.AuditEntry, error) {
+	return s.repo.ListAudit(ctx, accountID, entityType, entityID)
+}
+
+// appendAudit records who changed an entity and its snapshots before and after the change.
+// A nil before or after is stored as an empty snapshot.
+func appendAudit(ctx context.Context, repo repository.LedgerRepository, entityType, accountID, entityID, operation string, before, after any) error {
+	entry, err := newAuditEntry(ctx, entityType, accountID, entityID, operation, before, after)
+	if err != nil {
+		return err
+	}
+	return repo.AppendAudit(ctx, entry)
+}
+
+func newAuditEntry(ctx context.Context, entityType, accountID, entityID, operation string, before, after any) (model.AuditEntry, error) {
+	beforeData, err := auditSnapshot(before)
+	if err != nil {
+		return model.AuditEntry{}, fmt.Errorf("encode %s audit snapshot: %w", entityType, err)
+	}
+	afterData, err := auditSnapshot(after)
+	if err != nil {
+		return model.AuditEntry{}, fmt.Errorf("encode %s audit snapshot: %w", entityType, err)
+	}
+	return model.AuditEntry{
+		ID:         uuid.NewString(),
+		AccountID:  accountID,
+		ActorID:    actor.UserIDFromContext(ctx),
+		EntityType: entityType,
+		EntityID:   entityID,
+		Operation:  operation,
+		Before:     beforeData,
+		After:      afterData,
+		CreatedAt:  time.Now().UTC(),
+	}, nil
+}
+
+func auditSnapshot(value any) ([]byte, error) {
+	if value == nil {
+		return nil, nil
+	}
+	return json.Marshal(value)
+}
@@ -0,0 +1,367 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/actor"
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/model"
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/repository"
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/storage"
+)
+
+// BatchCreateTransactions creates transactions for one account. Budgets are checked
+// item by item, taking earlier items of the same batch into account. In atomic mode
+// nothing is written unless every item passes. Items reusing the ID of a live or
+// deleted transaction of any account fail with ErrTransactionExists.
+func (s *DefaultLedgerService) BatchCreateTransactions(ctx context.Context, accountID string, items []model.Transaction, atomic bool) ([]model.BatchResult, error) {
+	results := newBatchResults(len(items))
+	budgets := s.repo.ListBudgets(ctx, accountID)
+	existing := s.ListTransactions(ctx, accountID)
+	taken, err := s.takenTransactionIDs(ctx, items)
+	if err != nil {
+		return nil, err
+	}
+	wallets, err := s.walletsByID(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	matchers, err := s.ruleMatchers(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	payees, err := s.loadPayees(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	createdBy := actor.UserIDFromContext(ctx)
+
+	accepted := make([]model.Transaction, 0, len(items))
+	acceptedIdx := make([]int, 0, len(items))
+	for i, tx := range items {
+		if tx.ID == "" {
+			tx.ID = uuid.NewString()
+		}
+		if taken[tx.ID] {
+			results[i].Err = fmt.Errorf("%w: %s", ErrTransactionExists, tx.ID)
+			continue
+		}
+		if tx.OccurredAt.IsZero() {
+			tx.OccurredAt = now
+		}
+		tx.AccountID = accountID
+		tx.CreatedAt = now
+		tx.CreatedBy = createdBy
+		tx.TransferID = ""
+		tx.RefundOf = ""
+		tx.UpdatedAt = now
+		applySplits(&tx)
+		applyTags(&tx)
+		if err := payees.link(&tx, now); err != nil {
+			results[i].Err = err
+			continue
+		}
+		if err := applyRules(&tx, matchers, payees, false); err != nil {
+			results[i].Err = err
+			continue
+		}
+		if err := checkWallet(tx, wallets); err != nil {
+			results[i].Err = err
+			continue
+		}
+		if err := checkBudget(tx, budgets, existing); err != nil {
+			results[i].Err = err
+			continue
+		}
+		taken[tx.ID] = true
+		existing = append(existing, tx)
+		accepted = append(accepted, tx)
+		acceptedIdx = append(acceptedIdx, i)
+	}
+	if atomic && len(accepted) < len(items) {
+		return abortBatch(results, acceptedIdx), nil
+	}
+	if len(accepted) == 0 {
+		return results, nil
+	}
+
+	err = s.repo.RunInTx(ctx, func(repo repository.LedgerRepository) error {
+		if err := ensureTags(ctx, repo, accountID, accepted...); err != nil {
+			return err
+		}
+		if err := ensurePayees(ctx, repo, payees, accepted...); err != nil {
+			return err
+		}
+		if err := repo.CreateTransactions(ctx, accepted); err != nil {
+			return err
+		}
+		if err := postJournal(ctx, repo, accepted); err != nil {
+			return err
+		}
+		return recordBatch(ctx, repo, model.AuditOperationCreate, model.EventTransactionCreated, nil, accepted)
+	})
+	if err != nil {
+		return nil, err
+	}
+	for i, tx := range accepted {
+		results[acceptedIdx[i]].Transaction = tx
+	}
+	return results, nil
+}
+
+// takenTransactionIDs returns the client-supplied IDs of items that are in use.
+// IDs are unique across accounts, so every account is checked.
+func (s *DefaultLedgerService) takenTransactionIDs(ctx context.Context, items []model.Transaction) (map[string]bool, error) {
+	ids := make([]string, 0, len(items))
+	for _, tx := range items {
+		if tx.ID != "" {
+			ids = append(ids, tx.ID)
+		}
+	}
+	taken := make(map[string]bool, len(ids))
+	if len(ids) == 0 {
+		return taken, nil
+	}
+	existing, err := s.repo.ExistingTransactionIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range existing {
+		taken[id] = true
+	}
+	return taken, nil
+}
+
+// BatchUpdateTransactions updates transactions of one account. Like UpdateTransaction,
+// it does not re-check budgets and refuses legs of transfers and refunds.
+func (s *DefaultLedgerService) BatchUpdateTransactions(ctx context.Context, accountID string, items []model.Transaction, atomic bool) ([]model.BatchResult, error) {
+	results := newBatchResults(len(items))
+	live := s.ListTransactions(ctx, accountID)
+	current := transactionsByID(live)
+	wallets, err := s.walletsByID(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	payees, err := s.loadPayees(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+
+	before := make([]model.Transaction, 0, len(items))
+	accepted := make([]model.Transaction, 0, len(items))
+	acceptedIdx := make([]int, 0, len(items))
+	for i, tx := range items {
+		existing, ok := current[tx.ID]
+		if !ok {
+			results[i].Err = storage.ErrNotFound
+			continue
+		}
+		if existing.TransferID != "" {
+			results[i].Err = ErrTransferLeg
+			continue
+		}
+		if existing.RefundOf != "" {
+			results[i].Err = ErrRefundLinked
+			continue
+		}
+		if len(tx.Splits) == 0 {
+			if err := validateCategoryNotReserved(tx.Category); err != nil {
+				results[i].Err = err
+				continue
+			}
+		}
+		tx.AccountID = accountID
+		tx.CreatedAt = existing.CreatedAt
+		tx.CreatedBy = existing.CreatedBy
+		tx.TransferID = ""
+		tx.RefundOf = ""
+		tx.UpdatedAt = now
+		if tx.OccurredAt.IsZero() {
+			tx.OccurredAt = existing.OccurredAt
+		}
+		applySplits(&tx)
+		applyTags(&tx)
+		if err := payees.link(&tx, now); err != nil {
+			results[i].Err = err
+			continue
+		}
+		if err := checkWallet(tx, wallets); err != nil {
+			results[i].Err = err
+			continue
+		}
+		if err := checkRefunds(tx, refundsOf(live, tx.ID)); err != nil {
+			results[i].Err = err
+			continue
+		}
+		before = append(before, existing)
+		accepted = append(accepted, tx)
+		acceptedIdx = append(acceptedIdx, i)
+	}
+	if atomic && len(accepted) < len(items) {
+		return abortBatch(results, acceptedIdx), nil
+	}
+	if len(accepted) == 0 {
+		return results, nil
+	}
+
+	err = s.repo.RunInTx(ctx, func(repo repository.LedgerRepository) error {
+		if err := ensureTags(ctx, repo, accountID, accepted...); err != nil {
+			return err
+		}
+		if err := ensurePayees(ctx, repo, payees, accepted...); err != nil {
+			return err
+		}
+		if err := repo.UpdateTransactions(ctx, accepted); err != nil {
+			return err
+		}
+		if err := postJournal(ctx, repo, accepted); err != nil {
+			return err
+		}
+		return recordBatch(ctx, repo, model.AuditOperationUpdate, model.EventTransactionUpdated, before, accepted)
+	})
+	if err != nil {
+		return nil, err
+	}
+	for i, tx := range accepted {
+		results[acceptedIdx[i]].Transaction = tx
+	}
+	return results, nil
+}
+
+// BatchDeleteTransactions moves transactions of one account to the trash. Legs of
+// transfers are deleted through DeleteTransfer; an expense with refunds only
+// together with all of them.
+func (s *DefaultLedgerService) BatchDeleteTransactions(ctx context.Context, accountID string, ids []string, atomic bool) ([]model.BatchResult, error) {
+	results := newBatchResults(len(ids))
+	live := s.ListTransactions(ctx, accountID)
+	current := transactionsByID(live)
+	deleting := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		deleting[id] = true
+	}
+
+	deleted := make([]model.Transaction, 0, len(ids))
+	deletedIDs := make([]string, 0, len(ids))
+	acceptedIdx := make([]int, 0, len(ids))
+	for i, id := range ids {
+		existing, ok := current[id]
+		if !ok {
+			results[i].Err = storage.ErrNotFound
+			continue
+		}
+		if existing.TransferID != "" {
+			results[i].Err = ErrTransferLeg
+			continue
+		}
+		if !refundsDeleted(refundsOf(live, id), deleting) {
+			results[i].Err = ErrRefundLinked
+			continue
+		}
+		deleted = append(deleted, existing)
+		deletedIDs = append(deletedIDs, id)
+		acceptedIdx = append(acceptedIdx, i)
+	}
+	if atomic && len(deleted) < len(ids) {
+		return abortBatch(results, acceptedIdx), nil
+	}
+	if len(deleted) == 0 {
+		return results, nil
+	}
+
+	err := s.repo.RunInTx(ctx, func(repo repository.LedgerRepository) error {
+		if err := repo.DeleteTransactions(ctx, deletedIDs); err != nil {
+			return err
+		}
+		if err := unpostJournal(ctx, repo, deleted); err != nil {
+			return err
+		}
+		return recordBatch(ctx, repo, model.AuditOperationDelete, model.EventTransactionDeleted, deleted, nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+	for i, tx := range deleted {
+		results[acceptedIdx[i]].Transaction = tx
+	}
+	return results, nil
+}
+
+// recordBatch appends audit entries and events for a batch write. before and after
+// are aligned by position; either may be nil for creations and deletions.
+func recordBatch(ctx context.Context, repo repository.LedgerRepository, operation, eventType string, before, after []model.Transaction) error {
+	count := len(after)
+	if after == nil {
+		count = len(before)
+	}
+	entries := make([]model.AuditEntry, 0, count)
+	events := make([]model.Event, 0, count)
+	for i := 0; i < count; i++ {
+		var prev, next any
+		var tx model.Transaction
+		if before != nil {
+			prev = before[i]
+			tx = before[i]
+		}
+		if after != nil {
+			next = after[i]
+			tx = after[i]
+		}
+		entry, err := newAuditEntry(ctx, model.AuditEntityTransaction, tx.AccountID, tx.ID, operation, prev, next)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, entry)
+
+		var payload any = tx
+		if eventType == model.EventTransactionDeleted {
+			payload = transactionDeletedPayload{Transaction: tx}
+		}
+		event, err := newEvent(eventType, tx.AccountID, tx.ID, payload)
+		if err != nil {
+			return err
+		}
+		events = append(events, event)
+	}
+	if err := repo.AppendAudit(ctx, entries...); err != nil {
+		return err
+	}
+	return repo.AppendEvents(ctx, events...)
+}
+
+func newBatchResults(n int) []model.BatchResult {
+	results := make([]model.BatchResult, n)
+	for i := range results {
+		results[i].Index = i
+	}
+	return results
+}
+
+// abortBatch marks the items that passed as aborted, leaving the failures intact.
+func abortBatch(results []model.BatchResult, passed []int) []model.BatchResult {
+	for _, i := range passed {
+		results[i].Err = ErrBatchAborted
+	}
+	return results
+}
+
+func refundsDeleted(refunds []model.Transaction, deleting map[string]bool) bool {
+	for _, refund := range refunds {
+		if !deleting[refund.ID] {
+			return false
+		}
+	}
+	return true
+}
+
+func transactionsByID(items []model.Transaction) map[string]model.Transaction {
+	byID := make(map[string]model.Transaction, len(items))
+	for _, tx := range items {
+		byID[tx.ID] = tx
+	}
+	return byID
+}
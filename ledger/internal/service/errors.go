@@ -6,6 +6,49 @@ var (
 	ErrValidation     = errors.New("validation error")
 	ErrBudgetExceeded = errors.New("budget exceeded")
 	ErrBudgetMissing  = errors.New("budget is required")
+	// ErrBatchAborted marks items of an atomic batch that were not written
+	// because another item failed.
+	ErrBatchAborted = errors.New("batch aborted")
+	// ErrAccessDenied is returned when a user reads an account they are not a
+	// member of without a staff role, or changes it without a writing role.
+	ErrAccessDenied = errors.New("access to the account is denied")
+	// ErrLastOwner is returned when a change would leave a shared account
+	// without an owner.
+	ErrLastOwner = errors.New("account must keep at least one owner")
+	// ErrAlreadyMember is returned when a member accepts another invitation
+	// to the same account.
+	ErrAlreadyMember = errors.New("user is already a member of the account")
+	// ErrInvalidInvitation is returned for unknown, revoked, used or expired
+	// invitation tokens.
+	ErrInvalidInvitation = errors.New("invalid or expired invitation")
+	// ErrWalletInUse is returned when a wallet that has transactions is
+	// deleted or gets another currency.
+	ErrWalletInUse = errors.New("wallet has transactions")
+	// ErrTransferLeg is returned when a leg of a transfer is changed on its
+	// own instead of through the transfer.
+	ErrTransferLeg = errors.New("transaction is a leg of a transfer")
+	// ErrRefundExceeded is returned when the refunds of an expense would sum
+	// up to more than the expense.
+	ErrRefundExceeded = errors.New("refunds exceed the refunded transaction")
+	// ErrRefundLinked is returned when a refund is changed through the
+	// transaction API, restored without its expense, or when an expense with
+	// live refunds is deleted.
+	ErrRefundLinked = errors.New("transaction is linked to a refund")
+	// ErrTagExists is returned when a tag is created or renamed to the name
+	// of another tag of the account.
+	ErrTagExists = errors.New("tag already exists")
+	// ErrPayeeExists is returned when the name or an alias of a payee
+	// normalizes like the name or an alias of another payee of the account.
+	ErrPayeeExists = errors.New("payee already exists")
+	// ErrBudgetExists is returned when a budget is restored while a live
+	// budget with the same name and month exists.
+	ErrBudgetExists = errors.New("budget already exists")
+	// ErrTransactionExists is returned for batch items whose ID is already
+	// used by a live or deleted transaction of the account.
+	ErrTransactionExists = errors.New("transaction already exists")
+	// ErrJournalInvariant is returned by VerifyJournal when the journal does
+	// not balance or does not match the transactions.
+	ErrJournalInvariant = errors.New("journal invariant violated")
 )
 
 func IsValidationError(err error) bool {
@@ -19,3 +62,59 @@ func IsBudgetExceeded(err error) bool {
 func IsBudgetMissing(err error) bool {
 	return errors.Is(err, ErrBudgetMissing)
 }
+
+func IsBatchAborted(err error) bool {
+	return errors.Is(err, ErrBatchAborted)
+}
+
+func IsAccessDenied(err error) bool {
+	return errors.Is(err, ErrAccessDenied)
+}
+
+func IsLastOwner(err error) bool {
+	return errors.Is(err, ErrLastOwner)
+}
+
+func IsAlreadyMember(err error) bool {
+	return errors.Is(err, ErrAlreadyMember)
+}
+
+func IsInvalidInvitation(err error) bool {
+	return errors.Is(err, ErrInvalidInvitation)
+}
+
+func IsWalletInUse(err error) bool {
+	return errors.Is(err, ErrWalletInUse)
+}
+
+func IsTransferLeg(err error) bool {
+	return errors.Is(err, ErrTransferLeg)
+}
+
+func IsRefundExceeded(err error) bool {
+	return errors.Is(err, ErrRefundExceeded)
+}
+
+func IsRefundLinked(err error) bool {
+	return errors.Is(err, ErrRefundLinked)
+}
+
+func IsTagExists(err error) bool {
+	return errors.Is(err, ErrTagExists)
+}
+
+func IsPayeeExists(err error) bool {
+	return errors.Is(err, ErrPayeeExists)
+}
+
+func IsBudgetExists(err error) bool {
+	return errors.Is(err, ErrBudgetExists)
+}
+
+func IsTransactionExists(err error) bool {
+	return errors.Is(err, ErrTransactionExists)
+}
+
+func IsJournalInvariant(err error) bool {
+	return errors.Is(err, ErrJournalInvariant)
+}
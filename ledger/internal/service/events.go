@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/model"
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/repository"
+)
+
+// Operations reported by the BudgetChanged, WalletChanged, TagChanged,
+// AttachmentChanged and RuleChanged events.
+const (
+	changeOperationCreated  = "created"
+	changeOperationUpdated  = "updated"
+	changeOperationDeleted  = "deleted"
+	changeOperationRestored = "restored"
+)
+
+type budgetChangedPayload struct {
+	Operation string       `json:"operation"`
+	Budget    model.Budget `json:"budget"`
+}
+
+type walletChangedPayload struct {
+	Operation string       `json:"operation"`
+	Wallet    model.Wallet `json:"wallet"`
+}
+
+type tagChangedPayload struct {
+	Operation string    `json:"operation"`
+	Tag       model.Tag `json:"tag"`
+}
+
+type attachmentChangedPayload struct {
+	Operation  string           `json:"operation"`
+	Attachment model.Attachment `json:"attachment"`
+}
+
+type ruleChangedPayload struct {
+	Operation string             `json:"operation"`
+	Rule      model.CategoryRule `json:"rule"`
+}
+
+type payeeChangedPayload struct {
+	Operation string      `json:"operation"`
+	Payee     model.Payee `json:"payee"`
+}
+
+type goalChangedPayload struct {
+	Operation string     `json:"operation"`
+	Goal      model.Goal `json:"goal"`
+}
+
+type transactionDeletedPayload struct {
+	Transaction model.Transaction `json:"transaction"`
+}
+
+func (s *DefaultLedgerService) ListEvents(ctx context.Context, accountID string, afterOffset int64, limit int) ([]model.Event, error) {
+	return s.repo.ListEvents(ctx, accountID, afterOffset, limit)
+}
+
+// appendEvent records a domain event through repo, which is expected to be bound
+// to the same transaction as the write the event describes.
+func appendEvent(ctx context.Context, repo repository.LedgerRepository, eventType, accountID, aggregateID string, payload any) error {
+	event, err := newEvent(eventType, accountID, aggregateID, payload)
+	if err != nil {
+		return err
+	}
+	return repo.AppendEvents(ctx, event)
+}
+
+func newEvent(eventType, accountID, aggregateID string, payload any) (model.Event, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return model.Event{}, fmt.Errorf("encode %s event: %w", eventType, err)
+	}
+	return model.Event{
+		ID:          uuid.NewString(),
+		AccountID:   accountID,
+		Type:        eventType,
+		AggregateID: aggregateID,
+		Payload:     data,
+		OccurredAt:  time.Now().UTC(),
+	}, nil
+}
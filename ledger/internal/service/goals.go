@@ -0,0 +1,205 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/model"
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/repository"
+)
+
+// CreateGoal adds a savings goal to the account. A goal saving in a wallet
+// must be in the currency of the wallet.
+func (s *DefaultLedgerService) CreateGoal(ctx context.Context, goal model.Goal) (model.Goal, error) {
+	if err := s.checkGoalWallet(ctx, goal); err != nil {
+		return model.Goal{}, err
+	}
+	now := time.Now().UTC()
+	if goal.ID == "" {
+		goal.ID = uuid.NewString()
+	}
+	goal.Progress = model.GoalProgress{}
+	goal.CreatedAt = now
+	goal.UpdatedAt = now
+	err := s.repo.RunInTx(ctx, func(repo repository.LedgerRepository) error {
+		if err := repo.CreateGoal(ctx, goal); err != nil {
+			return err
+		}
+		if err := appendAudit(ctx, repo, model.AuditEntityGoal, goal.AccountID, goal.ID, model.AuditOperationCreate, nil, goal); err != nil {
+			return err
+		}
+		return appendEvent(ctx, repo, model.EventGoalChanged, goal.AccountID, goal.ID, goalChangedPayload{Operation: changeOperationCreated, Goal: goal})
+	})
+	if err != nil {
+		return model.Goal{}, err
+	}
+	goal.Progress = goalProgress(goal, s.ListTransactions(ctx, goal.AccountID), now)
+	return goal, nil
+}
+
+// GetGoal returns the goal with its current progress.
+func (s *DefaultLedgerService) GetGoal(ctx context.Context, accountID, id string) (model.Goal, error) {
+	goal, err := s.repo.GetGoal(ctx, accountID, id)
+	if err != nil {
+		return model.Goal{}, err
+	}
+	goal.Progress = goalProgress(goal, s.ListTransactions(ctx, accountID), time.Now().UTC())
+	return goal, nil
+}
+
+// UpdateGoal replaces the target, deadline and contribution source of a goal.
+func (s *DefaultLedgerService) UpdateGoal(ctx context.Context, goal model.Goal) (model.Goal, error) {
+	current, err := s.repo.GetGoal(ctx, goal.AccountID, goal.ID)
+	if err != nil {
+		return model.Goal{}, err
+	}
+	if err := s.checkGoalWallet(ctx, goal); err != nil {
+		return model.Goal{}, err
+	}
+	goal.Progress = model.GoalProgress{}
+	goal.CreatedAt = current.CreatedAt
+	goal.UpdatedAt = time.Now().UTC()
+	err = s.repo.RunInTx(ctx, func(repo repository.LedgerRepository) error {
+		if err := repo.UpdateGoal(ctx, goal); err != nil {
+			return err
+		}
+		if err := appendAudit(ctx, repo, model.AuditEntityGoal, goal.AccountID, goal.ID, model.AuditOperationUpdate, current, goal); err != nil {
+			return err
+		}
+		return appendEvent(ctx, repo, model.EventGoalChanged, goal.AccountID, goal.ID, goalChangedPayload{Operation: changeOperationUpdated, Goal: goal})
+	})
+	if err != nil {
+		return model.Goal{}, err
+	}
+	goal.Progress = goalProgress(goal, s.ListTransactions(ctx, goal.AccountID), goal.UpdatedAt)
+	return goal, nil
+}
+
+// DeleteGoal removes a goal. Its contributions stay as they are.
+func (s *DefaultLedgerService) DeleteGoal(ctx context.Context, accountID, id string) error {
+	return s.repo.RunInTx(ctx, func(repo repository.LedgerRepository) error {
+		current, err := repo.GetGoal(ctx, accountID, id)
+		if err != nil {
+			return err
+		}
+		if err := repo.DeleteGoal(ctx, accountID, id); err != nil {
+			return err
+		}
+		if err := appendAudit(ctx, repo, model.AuditEntityGoal, current.AccountID, current.ID, model.AuditOperationDelete, current, nil); err != nil {
+			return err
+		}
+		return appendEvent(ctx, repo, model.EventGoalChanged, current.AccountID, current.ID, goalChangedPayload{Operation: changeOperationDeleted, Goal: current})
+	})
+}
+
+// ListGoals returns the goals of the account by deadline with their current
+// progress.
+func (s *DefaultLedgerService) ListGoals(ctx context.Context, accountID string) ([]model.Goal, error) {
+	goals, err := s.repo.ListGoals(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	transactions := s.ListTransactions(ctx, accountID)
+	now := time.Now().UTC()
+	for i := range goals {
+		goals[i].Progress = goalProgress(goals[i], transactions, now)
+	}
+	return goals, nil
+}
+
+func (s *DefaultLedgerService) checkGoalWallet(ctx context.Context, goal model.Goal) error {
+	if goal.WalletID == "" {
+		return nil
+	}
+	wallets, err := s.walletsByID(ctx, goal.AccountID)
+	if err != nil {
+		return err
+	}
+	wallet, ok := wallets[goal.WalletID]
+	if !ok {
+		return fmt.Errorf("%w: wallet %s not found", ErrValidation, goal.WalletID)
+	}
+	if wallet.Currency != goal.Currency {
+		return fmt.Errorf("%w: currency must match the wallet currency %s", ErrValidation, wallet.Currency)
+	}
+	return nil
+}
+
+// goalProgress computes the progress of a goal from the transactions that
+// occurred up to asOf. Contributions in a category or with a tag are
+// expenses, so refunds and income in the category or with the tag are
+// withdrawals; a wallet contributes its balance, transfers included.
+func goalProgress(goal model.Goal, transactions []model.Transaction, asOf time.Time) model.GoalProgress {
+	saved := 0.0
+	for _, tx := range transactions {
+		if tx.OccurredAt.After(asOf) || tx.Currency != goal.Currency {
+			continue
+		}
+		if goal.WalletID != "" {
+			if tx.WalletID == goal.WalletID {
+				saved += tx.Amount
+			}
+			continue
+		}
+		if goal.Tag != "" {
+			if slices.Contains(tx.Tags, goal.Tag) {
+				saved -= tx.Amount
+			}
+			continue
+		}
+		for _, part := range transactionParts(tx) {
+			if part.Category == goal.Category {
+				saved -= part.Amount
+			}
+		}
+	}
+
+	progress := model.GoalProgress{
+		Saved:      saved,
+		Remaining:  math.Max(goal.TargetAmount-saved, 0),
+		MonthsLeft: monthsLeft(asOf, goal.Deadline),
+	}
+	if goal.TargetAmount > 0 {
+		progress.Percent = saved / goal.TargetAmount * 100
+	}
+	progress.MonthlyRequired = progress.Remaining
+	if progress.MonthsLeft > 0 {
+		progress.MonthlyRequired = math.Ceil(progress.Remaining/float64(progress.MonthsLeft)*100) / 100
+	}
+	return progress
+}
+
+// monthsLeft counts the calendar months from the month of asOf to the month of
+// the deadline, both included, or returns zero when the deadline passed.
+func monthsLeft(asOf, deadline time.Time) int {
+	from, to := dateOnly(asOf), dateOnly(deadline)
+	if to.Before(from) {
+		return 0
+	}
+	return (to.Year()-from.Year())*12 + int(to.Month()-from.Month()) + 1
+}
+
+// reportGoals returns the progress of the goals at the end of a report
+// period. With a currency, only the goals in that currency are reported.
+func reportGoals(goals []model.Goal, transactions []model.Transaction, end time.Time, currency string) []model.ReportGoal {
+	items := make([]model.ReportGoal, 0, len(goals))
+	for _, goal := range goals {
+		if currency != "" && goal.Currency != currency {
+			continue
+		}
+		items = append(items, model.ReportGoal{
+			GoalID:       goal.ID,
+			Name:         goal.Name,
+			TargetAmount: goal.TargetAmount,
+			Currency:     goal.Currency,
+			Deadline:     goal.Deadline,
+			Progress:     goalProgress(goal, transactions, end),
+		})
+	}
+	return items
+}
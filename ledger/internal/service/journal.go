@@ -0,0 +1,241 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/model"
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/repository"
+)
+
+// Journal accounts that do not depend on a wallet or category. Transactions
+// outside any wallet are posted to the unassigned asset account; both legs of
+// a transfer are posted against the transfers equity account, which nets to
+// zero per transfer between wallets in the same currency.
+const (
+	journalAccountUnassigned = "assets:unassigned"
+	journalAccountTransfers  = "equity:transfers"
+)
+
+// journalTolerance absorbs floating point drift when sums are compared to zero.
+const journalTolerance = 1e-6
+
+// ListJournalEntries returns the journal entries of the account.
+func (s *DefaultLedgerService) ListJournalEntries(ctx context.Context, accountID string) ([]model.JournalEntry, error) {
+	return s.repo.ListJournalEntries(ctx, accountID)
+}
+
+// GetTrialBalance sums up the journal of the account per journal account and
+// currency.
+func (s *DefaultLedgerService) GetTrialBalance(ctx context.Context, accountID string) (model.TrialBalance, error) {
+	entries, err := s.repo.ListJournalEntries(ctx, accountID)
+	if err != nil {
+		return model.TrialBalance{}, err
+	}
+	return trialBalance(accountID, entries), nil
+}
+
+// VerifyJournal checks the invariants of the journal of the account: every
+// entry balances, the trial balance balances, and the entries match the live
+// transactions one to one. Violations are reported with ErrJournalInvariant.
+func (s *DefaultLedgerService) VerifyJournal(ctx context.Context, accountID string) error {
+	entries, err := s.repo.ListJournalEntries(ctx, accountID)
+	if err != nil {
+		return err
+	}
+	wallets, err := s.walletsByID(ctx, accountID)
+	if err != nil {
+		return err
+	}
+
+	var problems []error
+	for _, entry := range entries {
+		if err := checkEntryBalanced(entry); err != nil {
+			problems = append(problems, err)
+		}
+	}
+	for _, total := range trialBalance(accountID, entries).Totals {
+		if math.Abs(total.Debit-total.Credit) > journalTolerance {
+			problems = append(problems, fmt.Errorf("trial balance in %s: debit %v, credit %v", total.Currency, total.Debit, total.Credit))
+		}
+	}
+
+	posted := make(map[string]model.JournalEntry, len(entries))
+	for _, entry := range entries {
+		posted[entry.TransactionID] = entry
+	}
+	for _, tx := range s.ListTransactions(ctx, accountID) {
+		entry, ok := posted[tx.ID]
+		if !ok {
+			problems = append(problems, fmt.Errorf("transaction %s is not posted", tx.ID))
+			continue
+		}
+		delete(posted, tx.ID)
+		if !samePostings(entry.Postings, journalEntry(tx, wallets, time.Time{}).Postings) {
+			problems = append(problems, fmt.Errorf("entry of transaction %s does not match it", tx.ID))
+		}
+	}
+	for id := range posted {
+		problems = append(problems, fmt.Errorf("entry %s has no live transaction", id))
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %w", ErrJournalInvariant, errors.Join(problems...))
+	}
+	return nil
+}
+
+// postJournal posts the entries of the transactions, replacing earlier entries
+// of the same transactions. It runs in the database transaction of the write.
+func postJournal(ctx context.Context, repo repository.LedgerRepository, txs []model.Transaction) error {
+	if len(txs) == 0 {
+		return nil
+	}
+	now := time.Now().UTC()
+	wallets := make(map[string]map[string]model.Wallet)
+	entries := make([]model.JournalEntry, 0, len(txs))
+	for _, tx := range txs {
+		byID, ok := wallets[tx.AccountID]
+		if !ok {
+			items, err := repo.ListWallets(ctx, tx.AccountID)
+			if err != nil {
+				return err
+			}
+			byID = make(map[string]model.Wallet, len(items))
+			for _, wallet := range items {
+				byID[wallet.ID] = wallet
+			}
+			wallets[tx.AccountID] = byID
+		}
+		entries = append(entries, journalEntry(tx, byID, now))
+	}
+	return repo.SaveJournalEntries(ctx, entries)
+}
+
+// unpostJournal removes the entries of transactions moved to the trash.
+func unpostJournal(ctx context.Context, repo repository.LedgerRepository, txs []model.Transaction) error {
+	if len(txs) == 0 {
+		return nil
+	}
+	ids := make([]string, 0, len(txs))
+	for _, tx := range txs {
+		ids = append(ids, tx.ID)
+	}
+	return repo.DeleteJournalEntries(ctx, ids)
+}
+
+// journalEntry derives the entry of a transaction. The amount enters the
+// account of the wallet, a liability for credit wallets, and leaves the
+// counter account: income for non-negative amounts, expense for negative
+// ones and refunds, and equity for transfer legs. Each part of a split
+// transaction leaves the counter account of its own category.
+func journalEntry(tx model.Transaction, wallets map[string]model.Wallet, postedAt time.Time) model.JournalEntry {
+	walletAccount, walletType := journalAccountUnassigned, model.JournalAccountAsset
+	if wallet, ok := wallets[tx.WalletID]; ok {
+		walletAccount, walletType = "assets:wallet:"+wallet.ID, model.JournalAccountAsset
+		if wallet.Type == model.WalletTypeCredit {
+			walletAccount, walletType = "liabilities:wallet:"+wallet.ID, model.JournalAccountLiability
+		}
+	}
+	postings := []model.Posting{{Account: walletAccount, Type: walletType, Currency: tx.Currency, Amount: tx.Amount}}
+	if tx.TransferID != "" {
+		postings = append(postings, model.Posting{Account: journalAccountTransfers, Type: model.JournalAccountEquity, Currency: tx.Currency, Amount: -tx.Amount})
+	} else {
+		for _, part := range transactionParts(tx) {
+			counterAccount, counterType := "income:"+part.Category, model.JournalAccountIncome
+			if tx.Amount < 0 || tx.RefundOf != "" {
+				counterAccount, counterType = "expenses:"+part.Category, model.JournalAccountExpense
+			}
+			postings = append(postings, model.Posting{Account: counterAccount, Type: counterType, Currency: tx.Currency, Amount: -part.Amount})
+		}
+	}
+	return model.JournalEntry{
+		TransactionID: tx.ID,
+		AccountID:     tx.AccountID,
+		OccurredAt:    tx.OccurredAt,
+		Description:   tx.Description,
+		Postings:      postings,
+		PostedAt:      postedAt,
+	}
+}
+
+func checkEntryBalanced(entry model.JournalEntry) error {
+	if len(entry.Postings) < 2 {
+		return fmt.Errorf("entry %s has %d postings", entry.TransactionID, len(entry.Postings))
+	}
+	sums := map[string]float64{}
+	for _, posting := range entry.Postings {
+		sums[posting.Currency] += posting.Amount
+	}
+	for currency, sum := range sums {
+		if math.Abs(sum) > journalTolerance {
+			return fmt.Errorf("entry %s is off by %v %s", entry.TransactionID, sum, currency)
+		}
+	}
+	return nil
+}
+
+func samePostings(a, b []model.Posting) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Account != b[i].Account || a[i].Type != b[i].Type || a[i].Currency != b[i].Currency ||
+			math.Abs(a[i].Amount-b[i].Amount) > journalTolerance {
+			return false
+		}
+	}
+	return true
+}
+
+// trialBalance nets the postings per journal account and currency. Lines are
+// ordered by account and currency; zero balances are kept so that accounts
+// that netted out remain visible.
+func trialBalance(accountID string, entries []model.JournalEntry) model.TrialBalance {
+	type key struct{ account, currency string }
+	balances := map[key]float64{}
+	types := map[string]string{}
+	for _, entry := range entries {
+		for _, posting := range entry.Postings {
+			balances[key{posting.Account, posting.Currency}] += posting.Amount
+			types[posting.Account] = posting.Type
+		}
+	}
+
+	result := model.TrialBalance{AccountID: accountID, Lines: make([]model.TrialBalanceLine, 0, len(balances))}
+	totals := map[string]*model.TrialBalanceTotal{}
+	for k, balance := range balances {
+		line := model.TrialBalanceLine{Account: k.account, Type: types[k.account], Currency: k.currency}
+		if balance >= 0 {
+			line.Debit = balance
+		} else {
+			line.Credit = -balance
+		}
+		result.Lines = append(result.Lines, line)
+		total, ok := totals[k.currency]
+		if !ok {
+			total = &model.TrialBalanceTotal{Currency: k.currency}
+			totals[k.currency] = total
+		}
+		total.Debit += line.Debit
+		total.Credit += line.Credit
+	}
+	sort.Slice(result.Lines, func(i, j int) bool {
+		if result.Lines[i].Account != result.Lines[j].Account {
+			return result.Lines[i].Account < result.Lines[j].Account
+		}
+		return result.Lines[i].Currency < result.Lines[j].Currency
+	})
+	result.Totals = make([]model.TrialBalanceTotal, 0, len(totals))
+	for _, total := range totals {
+		result.Totals = append(result.Totals, *total)
+	}
+	sort.Slice(result.Totals, func(i, j int) bool {
+		return result.Totals[i].Currency < result.Totals[j].Currency
+	})
+	return result
+}
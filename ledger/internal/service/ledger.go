@@ -6,6 +6,7 @@ import (
 	"encoding/csv"
 	"errors"
 	"fmt"
+	"io"
 	"log"
 	"sort"
 	"strconv"
@@ -14,6 +15,8 @@ import (
 
 	"github.com/google/uuid"
 
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/actor"
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/blob"
 	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/cache"
 	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/model"
 	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/repository"
@@ -27,6 +30,8 @@ const (
 	csvHeaderCategory    = "category"
 	csvHeaderDescription = "description"
 	csvHeaderOccurredAt  = "occurred_at"
+	csvHeaderSplits      = "splits"
+	csvHeaderTags        = "tags"
 )
 
 type LedgerService interface {
@@ -35,6 +40,13 @@ type LedgerService interface {
 	UpdateTransaction(ctx context.Context, tx model.Transaction) (model.Transaction, error)
 	DeleteTransaction(ctx context.Context, id string) error
 	ListTransactions(ctx context.Context, accountID string) []model.Transaction
+	SearchTransactions(ctx context.Context, filter model.TransactionFilter) ([]model.Transaction, error)
+
+	BatchCreateTransactions(ctx context.Context, accountID string, items []model.Transaction, atomic bool) ([]model.BatchResult, error)
+	BatchUpdateTransactions(ctx context.Context, accountID string, items []model.Transaction, atomic bool) ([]model.BatchResult, error)
+	BatchDeleteTransactions(ctx context.Context, accountID string, ids []string, atomic bool) ([]model.BatchResult, error)
+	RefundTransaction(ctx context.Context, refund model.Refund) (model.Transaction, error)
+	ListRefunds(ctx context.Context, accountID, transactionID string) ([]model.Transaction, error)
 
 	CreateBudget(ctx context.Context, budget model.Budget) (model.Budget, error)
 	GetBudget(ctx context.Context, accountID, id string) (model.Budget, error)
@@ -42,6 +54,49 @@ type LedgerService interface {
 	DeleteBudget(ctx context.Context, accountID, id string) error
 	ListBudgets(ctx context.Context, accountID string) []model.Budget
 
+	CreateWallet(ctx context.Context, wallet model.Wallet) (model.Wallet, error)
+	GetWallet(ctx context.Context, accountID, id string) (model.Wallet, error)
+	UpdateWallet(ctx context.Context, wallet model.Wallet) (model.Wallet, error)
+	DeleteWallet(ctx context.Context, accountID, id string) error
+	ListWallets(ctx context.Context, accountID string) ([]model.Wallet, error)
+	CreateTransfer(ctx context.Context, transfer model.Transfer) (model.Transfer, error)
+	DeleteTransfer(ctx context.Context, accountID, id string) error
+	GetWalletBalanceHistory(ctx context.Context, accountID, walletID string, from, to time.Time, step string) ([]model.BalancePoint, error)
+
+	CreateTag(ctx context.Context, tag model.Tag) (model.Tag, error)
+	GetTag(ctx context.Context, accountID, id string) (model.Tag, error)
+	UpdateTag(ctx context.Context, tag model.Tag) (model.Tag, error)
+	DeleteTag(ctx context.Context, accountID, id string) error
+	ListTags(ctx context.Context, accountID string) ([]model.Tag, error)
+
+	UploadAttachment(ctx context.Context, attachment model.Attachment, content io.Reader) (model.Attachment, error)
+	GetAttachment(ctx context.Context, accountID, id string) (model.Attachment, error)
+	OpenAttachment(ctx context.Context, accountID, id string) (model.Attachment, io.ReadCloser, error)
+	ListAttachments(ctx context.Context, accountID, transactionID string) ([]model.Attachment, error)
+	DeleteAttachment(ctx context.Context, accountID, id string) error
+
+	CreateRule(ctx context.Context, rule model.CategoryRule) (model.CategoryRule, error)
+	GetRule(ctx context.Context, accountID, id string) (model.CategoryRule, error)
+	UpdateRule(ctx context.Context, rule model.CategoryRule) (model.CategoryRule, error)
+	DeleteRule(ctx context.Context, accountID, id string) error
+	ListRules(ctx context.Context, accountID string) ([]model.CategoryRule, error)
+	PreviewRule(ctx context.Context, rule model.CategoryRule) ([]model.RuleMatch, error)
+	ApplyRules(ctx context.Context, accountID string, dryRun bool) ([]model.RuleMatch, error)
+	CreatePayee(ctx context.Context, payee model.Payee) (model.Payee, error)
+	GetPayee(ctx context.Context, accountID, id string) (model.Payee, error)
+	UpdatePayee(ctx context.Context, payee model.Payee) (model.Payee, error)
+	DeletePayee(ctx context.Context, accountID, id string) error
+	ListPayees(ctx context.Context, accountID string) ([]model.Payee, error)
+	CreateGoal(ctx context.Context, goal model.Goal) (model.Goal, error)
+	GetGoal(ctx context.Context, accountID, id string) (model.Goal, error)
+	UpdateGoal(ctx context.Context, goal model.Goal) (model.Goal, error)
+	DeleteGoal(ctx context.Context, accountID, id string) error
+	ListGoals(ctx context.Context, accountID string) ([]model.Goal, error)
+
+	ListJournalEntries(ctx context.Context, accountID string) ([]model.JournalEntry, error)
+	GetTrialBalance(ctx context.Context, accountID string) (model.TrialBalance, error)
+	VerifyJournal(ctx context.Context, accountID string) error
+
 	CreateReport(ctx context.Context, report model.Report) (model.Report, error)
 	GetReport(ctx context.Context, accountID, id string) (model.Report, error)
 	UpdateReport(ctx context.Context, report model.Report) (model.Report, error)
@@ -52,6 +107,28 @@ type LedgerService interface {
 	ExportTransactionsCSV(ctx context.Context, accountID string) ([]byte, error)
 
 	GetReportSummary(ctx context.Context, accountID string, from, to time.Time) (model.ReportSummary, error)
+
+	ListEvents(ctx context.Context, accountID string, afterOffset int64, limit int) ([]model.Event, error)
+	ListHistory(ctx context.Context, accountID, entityType, entityID string) ([]model.AuditEntry, error)
+	AuthorizeRead(ctx context.Context, accountID, resource string) error
+	AuthorizeWrite(ctx context.Context, accountID string) error
+
+	CreateAccount(ctx context.Context, name string) (model.Account, error)
+	ListAccounts(ctx context.Context) ([]model.Account, error)
+	ListMembers(ctx context.Context, accountID string) ([]model.AccountMember, error)
+	UpdateMember(ctx context.Context, accountID, userID, role string) (model.AccountMember, error)
+	RemoveMember(ctx context.Context, accountID, userID string) error
+	CreateInvitation(ctx context.Context, accountID, role string) (model.Invitation, string, error)
+	ListInvitations(ctx context.Context, accountID string) ([]model.Invitation, error)
+	RevokeInvitation(ctx context.Context, accountID, id string) error
+	AcceptInvitation(ctx context.Context, token string) (model.AccountMember, error)
+
+	ListTrash(ctx context.Context, accountID string) (model.Trash, error)
+	RestoreTransaction(ctx context.Context, accountID, id string) (model.Transaction, error)
+	RestoreBudget(ctx context.Context, accountID, id string) (model.Budget, error)
+	RestoreReport(ctx context.Context, accountID, id string) (model.Report, error)
+	PurgeDeleted(ctx context.Context, before time.Time) (int64, error)
+	PurgeAccount(ctx context.Context, accountID string) (int64, error)
 }
 
 type DefaultLedgerService struct {
@@ -59,6 +136,8 @@ type DefaultLedgerService struct {
 	cache              *storage.ReportCache
 	reportSummaryCache cache.ReportSummaryCache
 	budgetListCache    cache.BudgetListCache
+	// blobs holds the content of attachments; without it uploads fail.
+	blobs blob.Store
 }
 
 func NewLedgerService(
@@ -66,16 +145,25 @@ func NewLedgerService(
 	reportCache *storage.ReportCache,
 	reportSummaryCache cache.ReportSummaryCache,
 	budgetListCache cache.BudgetListCache,
+	blobs blob.Store,
 ) *DefaultLedgerService {
 	return &DefaultLedgerService{
 		repo:               repo,
 		cache:              reportCache,
 		reportSummaryCache: reportSummaryCache,
 		budgetListCache:    budgetListCache,
+		blobs:              blobs,
 	}
 }
 
 func (s *DefaultLedgerService) CreateTransaction(ctx context.Context, tx model.Transaction) (model.Transaction, error) {
+	return s.createTransaction(ctx, tx, false)
+}
+
+// createTransaction creates tx. Rules replace the category of imported
+// transactions, which is usually the category of the bank, but keep the
+// category a user chose.
+func (s *DefaultLedgerService) createTransaction(ctx context.Context, tx model.Transaction, imported bool) (model.Transaction, error) {
 	now := time.Now().UTC()
 	if tx.ID == "" {
 		tx.ID = uuid.NewString()
@@ -85,10 +173,56 @@ func (s *DefaultLedgerService) CreateTransaction(ctx context.Context, tx model.T
 	}
 	tx.CreatedAt = now
 	tx.UpdatedAt = now
+	tx.CreatedBy = actor.UserIDFromContext(ctx)
+	tx.TransferID = ""
+	tx.RefundOf = ""
+	applySplits(&tx)
+	applyTags(&tx)
+	payees, err := s.loadPayees(ctx, tx.AccountID)
+	if err != nil {
+		return model.Transaction{}, err
+	}
+	if err := payees.link(&tx, now); err != nil {
+		return model.Transaction{}, err
+	}
+	matchers, err := s.ruleMatchers(ctx, tx.AccountID)
+	if err != nil {
+		return model.Transaction{}, err
+	}
+	if err := applyRules(&tx, matchers, payees, imported); err != nil {
+		return model.Transaction{}, err
+	}
+	if err := s.ensureWallet(ctx, tx); err != nil {
+		return model.Transaction{}, err
+	}
 	if err := s.ensureBudgetAvailable(ctx, tx); err != nil {
 		return model.Transaction{}, err
 	}
-	return s.repo.CreateTransaction(ctx, tx)
+	var created model.Transaction
+	err = s.repo.RunInTx(ctx, func(repo repository.LedgerRepository) error {
+		if err := ensureTags(ctx, repo, tx.AccountID, tx); err != nil {
+			return err
+		}
+		if err := ensurePayees(ctx, repo, payees, tx); err != nil {
+			return err
+		}
+		var err error
+		created, err = repo.CreateTransaction(ctx, tx)
+		if err != nil {
+			return err
+		}
+		if err := postJournal(ctx, repo, []model.Transaction{created}); err != nil {
+			return err
+		}
+		if err := appendAudit(ctx, repo, model.AuditEntityTransaction, created.AccountID, created.ID, model.AuditOperationCreate, nil, created); err != nil {
+			return err
+		}
+		return appendEvent(ctx, repo, model.EventTransactionCreated, created.AccountID, created.ID, created)
+	})
+	if err != nil {
+		return model.Transaction{}, err
+	}
+	return created, nil
 }
 
 func (s *DefaultLedgerService) GetTransaction(ctx context.Context, id string) (model.Transaction, error) {
@@ -100,16 +234,94 @@ func (s *DefaultLedgerService) UpdateTransaction(ctx context.Context, tx model.T
 	if err != nil {
 		return model.Transaction{}, err
 	}
+	if current.TransferID != "" {
+		return model.Transaction{}, ErrTransferLeg
+	}
+	if current.RefundOf != "" {
+		return model.Transaction{}, ErrRefundLinked
+	}
+	if len(tx.Splits) == 0 {
+		if err := validateCategoryNotReserved(tx.Category); err != nil {
+			return model.Transaction{}, err
+		}
+	}
 	tx.CreatedAt = current.CreatedAt
+	tx.CreatedBy = current.CreatedBy
+	tx.TransferID = ""
+	tx.RefundOf = ""
 	tx.UpdatedAt = time.Now().UTC()
 	if tx.OccurredAt.IsZero() {
 		tx.OccurredAt = current.OccurredAt
 	}
-	return s.repo.UpdateTransaction(ctx, tx)
+	applySplits(&tx)
+	applyTags(&tx)
+	payees, err := s.loadPayees(ctx, tx.AccountID)
+	if err != nil {
+		return model.Transaction{}, err
+	}
+	if err := payees.link(&tx, tx.UpdatedAt); err != nil {
+		return model.Transaction{}, err
+	}
+	if err := s.ensureWallet(ctx, tx); err != nil {
+		return model.Transaction{}, err
+	}
+	var updated model.Transaction
+	err = s.repo.RunInTx(ctx, func(repo repository.LedgerRepository) error {
+		// Refunds are checked under the lock they are created with.
+		if _, err := repo.LockTransaction(ctx, tx.ID); err != nil {
+			return err
+		}
+		if err := checkRefunds(tx, refundsOf(repo.ListTransactions(ctx), tx.ID)); err != nil {
+			return err
+		}
+		if err := ensureTags(ctx, repo, tx.AccountID, tx); err != nil {
+			return err
+		}
+		if err := ensurePayees(ctx, repo, payees, tx); err != nil {
+			return err
+		}
+		var err error
+		updated, err = repo.UpdateTransaction(ctx, tx)
+		if err != nil {
+			return err
+		}
+		if err := postJournal(ctx, repo, []model.Transaction{updated}); err != nil {
+			return err
+		}
+		if err := appendAudit(ctx, repo, model.AuditEntityTransaction, updated.AccountID, updated.ID, model.AuditOperationUpdate, current, updated); err != nil {
+			return err
+		}
+		return appendEvent(ctx, repo, model.EventTransactionUpdated, updated.AccountID, updated.ID, updated)
+	})
+	if err != nil {
+		return model.Transaction{}, err
+	}
+	return updated, nil
 }
 
 func (s *DefaultLedgerService) DeleteTransaction(ctx context.Context, id string) error {
-	return s.repo.DeleteTransaction(ctx, id)
+	return s.repo.RunInTx(ctx, func(repo repository.LedgerRepository) error {
+		current, err := repo.LockTransaction(ctx, id)
+		if err != nil {
+			return err
+		}
+		if current.TransferID != "" {
+			return ErrTransferLeg
+		}
+		if len(refundsOf(repo.ListTransactions(ctx), id)) > 0 {
+			return fmt.Errorf("%w: delete the refunds first", ErrRefundLinked)
+		}
+		if err := repo.DeleteTransaction(ctx, id); err != nil {
+			return err
+		}
+		if err := unpostJournal(ctx, repo, []model.Transaction{current}); err != nil {
+			return err
+		}
+		if err := appendAudit(ctx, repo, model.AuditEntityTransaction, current.AccountID, current.ID, model.AuditOperationDelete, current, nil); err != nil {
+			return err
+		}
+		return appendEvent(ctx, repo, model.EventTransactionDeleted, current.AccountID, current.ID, transactionDeletedPayload{Transaction: current})
+	})
 }
 
 func (s *DefaultLedgerService) ListTransactions(ctx context.Context, accountID string) []model.Transaction {
@@ -126,6 +338,10 @@ func (s *DefaultLedgerService) ListTransactions(ctx context.Context, accountID s
 	return filtered
 }
 
+func (s *DefaultLedgerService) SearchTransactions(ctx context.Context, filter model.TransactionFilter) ([]model.Transaction, error) {
+	return s.repo.SearchTransactions(ctx, filter)
+}
+
 func (s *DefaultLedgerService) CreateBudget(ctx context.Context, budget model.Budget) (model.Budget, error) {
 	now := time.Now().UTC()
 	if budget.ID == "" {
@@ -133,7 +349,18 @@ func (s *DefaultLedgerService) CreateBudget(ctx context.Context, budget model.Bu
 	}
 	budget.CreatedAt = now
 	budget.UpdatedAt = now
-	created, err := s.repo.CreateBudget(ctx, budget)
+	var created model.Budget
+	err := s.repo.RunInTx(ctx, func(repo repository.LedgerRepository) error {
+		var err error
+		created, err = repo.CreateBudget(ctx, budget)
+		if err != nil {
+			return err
+		}
+		if err := appendAudit(ctx, repo, model.AuditEntityBudget, created.AccountID, created.ID, model.AuditOperationCreate, nil, created); err != nil {
+			return err
+		}
+		return appendEvent(ctx, repo, model.EventBudgetChanged, created.AccountID, created.ID, budgetChangedPayload{Operation: changeOperationCreated, Budget: created})
+	})
 	if err != nil {
 		return model.Budget{}, err
 	}
@@ -155,7 +382,18 @@ func (s *DefaultLedgerService) UpdateBudget(ctx context.Context, budget model.Bu
 	if budget.Month.IsZero() {
 		budget.Month = current.Month
 	}
-	updated, err := s.repo.UpdateBudget(ctx, budget)
+	var updated model.Budget
+	err = s.repo.RunInTx(ctx, func(repo repository.LedgerRepository) error {
+		var err error
+		updated, err = repo.UpdateBudget(ctx, budget)
+		if err != nil {
+			return err
+		}
+		if err := appendAudit(ctx, repo, model.AuditEntityBudget, updated.AccountID, updated.ID, model.AuditOperationUpdate, current, updated); err != nil {
+			return err
+		}
+		return appendEvent(ctx, repo, model.EventBudgetChanged, updated.AccountID, updated.ID, budgetChangedPayload{Operation: changeOperationUpdated, Budget: updated})
+	})
 	if err != nil {
 		return model.Budget{}, err
 	}
@@ -164,7 +402,20 @@ func (s *DefaultLedgerService) UpdateBudget(ctx context.Context, budget model.Bu
 }
 
 func (s *DefaultLedgerService) DeleteBudget(ctx context.Context, accountID, id string) error {
-	if err := s.repo.DeleteBudget(ctx, accountID, id); err != nil {
+	err := s.repo.RunInTx(ctx, func(repo repository.LedgerRepository) error {
+		current, err := repo.GetBudget(ctx, accountID, id)
+		if err != nil {
+			return err
+		}
+		if err := repo.DeleteBudget(ctx, accountID, id); err != nil {
+			return err
+		}
+		if err := appendAudit(ctx, repo, model.AuditEntityBudget, current.AccountID, current.ID, model.AuditOperationDelete, current, nil); err != nil {
+			return err
+		}
+		return appendEvent(ctx, repo, model.EventBudgetChanged, current.AccountID, current.ID, budgetChangedPayload{Operation: changeOperationDeleted, Budget: current})
+	})
+	if err != nil {
 		return err
 	}
 	s.invalidateBudgetCache(ctx)
@@ -205,12 +456,34 @@ func (s *DefaultLedgerService) CreateReport(ctx context.Context, report model.Re
 	}
 	transactions := s.ListTransactions(ctx, report.AccountID)
 	budgets := s.ListBudgets(ctx, report.AccountID)
-	reportTotals := buildReportSummary(transactions, budgets, start, end, report.Currency)
+	payees, err := s.repo.ListPayees(ctx, report.AccountID)
+	if err != nil {
+		return model.Report{}, err
+	}
+	goals, err := s.repo.ListGoals(ctx, report.AccountID)
+	if err != nil {
+		return model.Report{}, err
+	}
+	reportTotals := buildReportSummary(transactions, budgets, payees, goals, start, end, report.Currency)
 	report.TotalIncome = reportTotals.TotalIncome
 	report.TotalExpense = reportTotals.TotalExpense
 	report.Currency = reportTotals.Currency
 	report.Categories = reportTotals.Categories
-	created, err := s.repo.CreateReport(ctx, report)
+	report.Tags = reportTotals.Tags
+	report.Payees = reportTotals.Payees
+	report.Goals = reportTotals.Goals
+	var created model.Report
+	err = s.repo.RunInTx(ctx, func(repo repository.LedgerRepository) error {
+		var err error
+		created, err = repo.CreateReport(ctx, report)
+		if err != nil {
+			return err
+		}
+		if err := appendAudit(ctx, repo, model.AuditEntityReport, created.AccountID, created.ID, model.AuditOperationCreate, nil, created); err != nil {
+			return err
+		}
+		return appendEvent(ctx, repo, model.EventReportGenerated, created.AccountID, created.ID, created)
+	})
 	if err != nil {
 		return model.Report{}, err
 	}
@@ -263,12 +536,34 @@ func (s *DefaultLedgerService) UpdateReport(ctx context.Context, report model.Re
 	}
 	transactions := s.ListTransactions(ctx, report.AccountID)
 	budgets := s.ListBudgets(ctx, report.AccountID)
-	reportTotals := buildReportSummary(transactions, budgets, start, end, report.Currency)
+	payees, err := s.repo.ListPayees(ctx, report.AccountID)
+	if err != nil {
+		return model.Report{}, err
+	}
+	goals, err := s.repo.ListGoals(ctx, report.AccountID)
+	if err != nil {
+		return model.Report{}, err
+	}
+	reportTotals := buildReportSummary(transactions, budgets, payees, goals, start, end, report.Currency)
 	report.TotalIncome = reportTotals.TotalIncome
 	report.TotalExpense = reportTotals.TotalExpense
 	report.Currency = reportTotals.Currency
 	report.Categories = reportTotals.Categories
-	updated, err := s.repo.UpdateReport(ctx, report)
+	report.Tags = reportTotals.Tags
+	report.Payees = reportTotals.Payees
+	report.Goals = reportTotals.Goals
+	var updated model.Report
+	err = s.repo.RunInTx(ctx, func(repo repository.LedgerRepository) error {
+		var err error
+		updated, err = repo.UpdateReport(ctx, report)
+		if err != nil {
+			return err
+		}
+		if err := appendAudit(ctx, repo, model.AuditEntityReport, updated.AccountID, updated.ID, model.AuditOperationUpdate, current, updated); err != nil {
+			return err
+		}
+		return appendEvent(ctx, repo, model.EventReportGenerated, updated.AccountID, updated.ID, updated)
+	})
 	if err != nil {
 		return model.Report{}, err
 	}
@@ -277,7 +572,17 @@ func (s *DefaultLedgerService) UpdateReport(ctx context.Context, report model.Re
 }
 
 func (s *DefaultLedgerService) DeleteReport(ctx context.Context, accountID, id string) error {
-	if err := s.repo.DeleteReport(ctx, accountID, id); err != nil {
+	err := s.repo.RunInTx(ctx, func(repo repository.LedgerRepository) error {
+		current, err := repo.GetReport(ctx, accountID, id)
+		if err != nil {
+			return err
+		}
+		if err := repo.DeleteReport(ctx, accountID, id); err != nil {
+			return err
+		}
+		return appendAudit(ctx, repo, model.AuditEntityReport, current.AccountID, current.ID, model.AuditOperationDelete, current, nil)
+	})
+	if err != nil {
 		return err
 	}
 	s.invalidateReportCache(ctx, id)
@@ -303,8 +608,8 @@ func (s *DefaultLedgerService) ImportTransactionsCSV(ctx context.Context, accoun
 	count := 0
 	for i := start; i < len(records); i++ {
 		row := records[i]
-		if len(row) != 6 {
-			return count, fmt.Errorf("row %d: expected 6 columns", i+1)
+		if len(row) < 6 || len(row) > 8 {
+			return count, fmt.Errorf("row %d: expected 6 to 8 columns", i+1)
 		}
 
 		record, err := parseCSVRecord(row)
@@ -312,33 +617,52 @@ func (s *DefaultLedgerService) ImportTransactionsCSV(ctx context.Context, accoun
 			return count, fmt.Errorf("row %d: %w", i+1, err)
 		}
 
-		_, err = s.CreateTransaction(ctx, model.Transaction{
+		tx := model.Transaction{
 			AccountID:   accountID,
 			Amount:      record.Amount,
 			Currency:    record.Currency,
 			Category:    record.Category,
 			Description: record.Description,
 			OccurredAt:  record.OccurredAt,
-		})
-		if err != nil {
-			return count, err
+			Splits:      record.Splits,
+			Tags:        record.Tags,
+		}
+		if err := validateTransactionTags(tx.Tags); err != nil {
+			return count, fmt.Errorf("row %d: %w", i+1, err)
+		}
+		if len(tx.Splits) > 0 {
+			if err := validateSplits(tx); err != nil {
+				return count, fmt.Errorf("row %d: %w", i+1, err)
+			}
+		} else if err := validateCategoryNotReserved(tx.Category); err != nil {
+			return count, fmt.Errorf("row %d: %w", i+1, err)
+		}
+		if _, err := s.createTransaction(ctx, tx, true); err != nil {
+			return count, fmt.Errorf("row %d: %w", i+1, err)
 		}
 		count++
 	}
 	return count, nil
 }
 
+// ExportTransactionsCSV writes the transactions of an account in the format
+// ImportTransactionsCSV reads. Transfer legs and refunds are left out: the file
+// has no columns linking them, so importing them back would turn a transfer into
+// an unrelated income and expense and a refund into plain income.
 func (s *DefaultLedgerService) ExportTransactionsCSV(ctx context.Context, accountID string) ([]byte, error) {
 	transactions := s.ListTransactions(ctx, accountID)
 	buf := &bytes.Buffer{}
 	writer := csv.NewWriter(buf)
 
-	header := []string{csvHeaderAccountID, csvHeaderAmount, csvHeaderCurrency, csvHeaderCategory, csvHeaderDescription, csvHeaderOccurredAt}
+	header := []string{csvHeaderAccountID, csvHeaderAmount, csvHeaderCurrency, csvHeaderCategory, csvHeaderDescription, csvHeaderOccurredAt, csvHeaderSplits, csvHeaderTags}
 	if err := writer.Write(header); err != nil {
 		return nil, fmt.Errorf("write header: %w", err)
 	}
 
 	for _, tx := range transactions {
+		if tx.TransferID != "" || tx.RefundOf != "" {
+			continue
+		}
 		record := csvRecordFromTransaction(tx)
 		if err := writer.Write(record); err != nil {
 			return nil, fmt.Errorf("write record: %w", err)
@@ -374,7 +698,15 @@ func (s *DefaultLedgerService) GetReportSummary(ctx context.Context, accountID s
 	}
 	transactions := s.ListTransactions(ctx, accountID)
 	budgets := s.ListBudgets(ctx, accountID)
-	summary := buildReportSummary(transactions, budgets, from, to, "")
+	payees, err := s.repo.ListPayees(ctx, accountID)
+	if err != nil {
+		return model.ReportSummary{}, err
+	}
+	goals, err := s.repo.ListGoals(ctx, accountID)
+	if err != nil {
+		return model.ReportSummary{}, err
+	}
+	summary := buildReportSummary(transactions, budgets, payees, goals, from, to, "")
 	if s.reportSummaryCache != nil {
 		_ = s.reportSummaryCache.SetSummary(ctx, cacheKey, summary)
 	}
@@ -385,15 +717,33 @@ func (s *DefaultLedgerService) ensureBudgetAvailable(ctx context.Context, tx mod
 	if tx.Amount >= 0 {
 		return nil
 	}
-	budgets := s.repo.ListBudgets(ctx, tx.AccountID)
-	expense := -tx.Amount
-	transactions := s.ListTransactions(ctx, tx.AccountID)
+	return checkBudget(tx, s.repo.ListBudgets(ctx, tx.AccountID), s.ListTransactions(ctx, tx.AccountID))
+}
+
+// checkBudget verifies that the expense tx fits into its monthly budgets given the
+// account's existing transactions. Each part of a split expense is checked against
+// the budget of its own category, and refunds in the month free up their category.
+// Transfers between wallets are not expenses.
+func checkBudget(tx model.Transaction, budgets []model.Budget, transactions []model.Transaction) error {
+	if tx.Amount >= 0 || tx.TransferID != "" {
+		return nil
+	}
+	for _, part := range transactionParts(tx) {
+		if err := checkCategoryBudget(tx, part, budgets, transactions); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func checkCategoryBudget(tx model.Transaction, part model.TransactionSplit, budgets []model.Budget, transactions []model.Transaction) error {
+	expense := -part.Amount
 	matchedBudget := false
 	for _, budget := range budgets {
 		if budget.Currency != tx.Currency {
 			continue
 		}
-		if budget.Name != tx.Category {
+		if budget.Name != part.Category {
 			continue
 		}
 		budgetStart, budgetEnd := monthRange(budget.Month)
@@ -403,16 +753,17 @@ func (s *DefaultLedgerService) ensureBudgetAvailable(ctx context.Context, tx mod
 		matchedBudget = true
 		total := 0.0
 		for _, existing := range transactions {
-			if existing.Amount >= 0 {
-				continue
-			}
-			if existing.Currency != tx.Currency || existing.Category != tx.Category {
+			if existing.Currency != tx.Currency {
 				continue
 			}
 			if !withinPeriod(existing.OccurredAt, budgetStart, budgetEnd) {
 				continue
 			}
-			total += -existing.Amount
+			for _, expense := range expenseParts(existing) {
+				if expense.Category == part.Category {
+					total += expense.Amount
+				}
+			}
 		}
 		if total+expense > budget.Amount {
 			return fmt.Errorf("%w: %s budget exceeded", ErrBudgetExceeded, budget.Name)
@@ -422,7 +773,7 @@ func (s *DefaultLedgerService) ensureBudgetAvailable(ctx context.Context, tx mod
 		return fmt.Errorf(
 			"%w: create a budget for category %q in %s before adding transactions",
 			ErrBudgetMissing,
-			tx.Category,
+			part.Category,
 			tx.OccurredAt.Format("2006-01"),
 		)
 	}
@@ -450,13 +801,33 @@ func (s *DefaultLedgerService) invalidateBudgetCache(ctx context.Context) {
 	_ = s.budgetListCache.DeleteBudgets(ctx)
 }
 
-func buildReportSummary(transactions []model.Transaction, budgets []model.Budget, start, end time.Time, currency string) model.ReportSummary {
+// buildReportSummary totals income and expenses of the period. Transfers move
+// money between wallets of the account and count as neither. The parts of a
+// split expense count towards their own categories, and refunds reduce the
+// expense of their category instead of counting as income. Every transaction
+// also counts towards each of its tags, and expenses and refunds towards
+// their payee. Goals are reported with their progress at the end of the
+// period.
+func buildReportSummary(transactions []model.Transaction, budgets []model.Budget, payees []model.Payee, goals []model.Goal, start, end time.Time, currency string) model.ReportSummary {
 	categoryTotals := map[string]float64{}
+	tagTotals := map[string]*model.ReportTag{}
+	tagTotal := func(tag string) *model.ReportTag {
+		total, ok := tagTotals[tag]
+		if !ok {
+			total = &model.ReportTag{Tag: tag}
+			tagTotals[tag] = total
+		}
+		return total
+	}
+	payeeTotals := map[string]*model.ReportPayee{}
 	totalIncome := 0.0
 	totalExpense := 0.0
 	resolvedCurrency := currency
 
 	for _, tx := range transactions {
+		if tx.TransferID != "" {
+			continue
+		}
 		if tx.OccurredAt.Before(start) || tx.OccurredAt.After(end) {
 			continue
 		}
@@ -466,13 +837,31 @@ func buildReportSummary(transactions []model.Transaction, budgets []model.Budget
 		if resolvedCurrency == "" {
 			resolvedCurrency = tx.Currency
 		}
-		if tx.Amount >= 0 {
+		if tx.Amount >= 0 && tx.RefundOf == "" {
 			totalIncome += tx.Amount
+			for _, tag := range tx.Tags {
+				tagTotal(tag).TotalIncome += tx.Amount
+			}
 			continue
 		}
-		expense := -tx.Amount
-		totalExpense += expense
-		categoryTotals[tx.Category] += expense
+		for _, expense := range expenseParts(tx) {
+			totalExpense += expense.Amount
+			categoryTotals[expense.Category] += expense.Amount
+			for _, tag := range tx.Tags {
+				tagTotal(tag).TotalExpense += expense.Amount
+			}
+		}
+		if tx.PayeeID != "" {
+			total, ok := payeeTotals[tx.PayeeID]
+			if !ok {
+				total = &model.ReportPayee{PayeeID: tx.PayeeID}
+				payeeTotals[tx.PayeeID] = total
+			}
+			total.TotalExpense -= tx.Amount
+			if tx.RefundOf == "" {
+				total.Transactions++
+			}
+		}
 	}
 
 	categories := make([]string, 0, len(categoryTotals))
@@ -498,11 +887,20 @@ func buildReportSummary(transactions []model.Transaction, budgets []model.Budget
 		})
 	}
 
+	tags := make([]model.ReportTag, 0, len(tagTotals))
+	for _, total := range tagTotals {
+		tags = append(tags, *total)
+	}
+	sort.Slice(tags, func(i, j int) bool { return tags[i].Tag < tags[j].Tag })
+
 	return model.ReportSummary{
 		TotalIncome:  totalIncome,
 		TotalExpense: totalExpense,
 		Currency:     resolvedCurrency,
 		Categories:   results,
+		Tags:         tags,
+		Payees:       reportPayees(payeeTotals, payees),
+		Goals:        reportGoals(goals, transactions, end, currency),
 	}
 }
 
@@ -593,9 +991,11 @@ func parsePeriod(value string) (time.Time, time.Time, error) {
 	return start, end, nil
 }
 
+// reportSummaryCacheKey carries the version of the JSON encoding of summaries
+// so entries written with an earlier encoding are not decoded.
 func reportSummaryCacheKey(from, to time.Time) string {
 	const layout = "2006-01-02"
-	return fmt.Sprintf("report:summary:%s:%s", from.Format(layout), to.Format(layout))
+	return fmt.Sprintf("report:summary:v2:%s:%s", from.Format(layout), to.Format(layout))
 }
 
 func monthRange(month time.Time) (time.Time, time.Time) {
@@ -654,15 +1054,60 @@ func parseCSVRecord(row []string) (model.TransactionCSVRow, error) {
 		return model.TransactionCSVRow{}, fmt.Errorf("parse occurred_at: %w", err)
 	}
 
+	var splits []model.TransactionSplit
+	if len(row) > 6 {
+		splits, err = parseCSVSplits(row[6])
+		if err != nil {
+			return model.TransactionCSVRow{}, fmt.Errorf("parse splits: %w", err)
+		}
+	}
+
+	var tags []string
+	if len(row) > 7 && strings.TrimSpace(row[7]) != "" {
+		tags = strings.Split(row[7], ";")
+	}
+
 	return model.TransactionCSVRow{
 		Amount:      amount,
 		Currency:    row[2],
 		Category:    row[3],
 		Description: row[4],
 		OccurredAt:  occurredAt,
+		Splits:      splits,
+		Tags:        tags,
 	}, nil
 }
 
+// parseCSVSplits reads the parts of a split transaction written as
+// "category:amount;category:amount". An empty value means no splits.
+func parseCSVSplits(value string) ([]model.TransactionSplit, error) {
+	if strings.TrimSpace(value) == "" {
+		return nil, nil
+	}
+	parts := strings.Split(value, ";")
+	splits := make([]model.TransactionSplit, 0, len(parts))
+	for _, part := range parts {
+		idx := strings.LastIndex(part, ":")
+		if idx < 0 {
+			return nil, fmt.Errorf("expected category:amount, got %q", part)
+		}
+		amount, err := strconv.ParseFloat(strings.TrimSpace(part[idx+1:]), 64)
+		if err != nil {
+			return nil, fmt.Errorf("amount of %q: %w", part, err)
+		}
+		splits = append(splits, model.TransactionSplit{Category: strings.TrimSpace(part[:idx]), Amount: amount})
+	}
+	return splits, nil
+}
+
+func formatCSVSplits(splits []model.TransactionSplit) string {
+	parts := make([]string, 0, len(splits))
+	for _, split := range splits {
+		parts = append(parts, split.Category+":"+strconv.FormatFloat(split.Amount, 'f', -1, 64))
+	}
+	return strings.Join(parts, ";")
+}
+
 func csvRecordFromTransaction(tx model.Transaction) []string {
 	record := model.TransactionCSVRow{
 		AccountID:   tx.AccountID,
@@ -671,6 +1116,8 @@ func csvRecordFromTransaction(tx model.Transaction) []string {
 		Category:    tx.Category,
 		Description: tx.Description,
 		OccurredAt:  tx.OccurredAt,
+		Splits:      tx.Splits,
+		Tags:        tx.Tags,
 	}
 	return []string{
 		record.AccountID,
@@ -679,5 +1126,7 @@ func csvRecordFromTransaction(tx model.Transaction) []string {
 		record.Category,
 		record.Description,
 		record.OccurredAt.Format(time.RFC3339),
+		formatCSVSplits(record.Splits),
+		strings.Join(record.Tags, ";"),
 	}
 }
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/actor"
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/model"
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/repository"
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/storage"
+)
+
+func TestAuthorizeReadAuditsStaffAccess(t *testing.T) {
+	store := storage.NewInMemoryLedgerStorage()
+	repo := repository.NewInMemoryLedgerRepository(store)
+	service := NewValidationService(NewLedgerService(repo, nil, nil, nil, nil))
+	accountID := "account-owner"
+
+	owner := actor.WithUserID(context.Background(), accountID)
+	if err := service.AuthorizeRead(owner, accountID, "transactions"); err != nil {
+		t.Fatalf("owner read: %v", err)
+	}
+	if err := service.AuthorizeRead(actor.WithInternal(context.Background()), accountID, "transactions"); err != nil {
+		t.Fatalf("internal read: %v", err)
+	}
+	if err := service.AuthorizeRead(context.Background(), accountID, "transactions"); !errors.Is(err, ErrAccessDenied) {
+		t.Fatalf("expected calls without an actor to be denied, got %v", err)
+	}
+	if err := service.AuthorizeWrite(context.Background(), accountID); !errors.Is(err, ErrAccessDenied) {
+		t.Fatalf("expected writes without an actor to be denied, got %v", err)
+	}
+
+	stranger := actor.WithRoles(actor.WithUserID(context.Background(), "stranger"), []string{"user"})
+	if err := service.AuthorizeRead(stranger, accountID, "transactions"); !errors.Is(err, ErrAccessDenied) {
+		t.Fatalf("expected ErrAccessDenied, got %v", err)
+	}
+
+	support := actor.WithRoles(actor.WithUserID(context.Background(), "support-1"), []string{"user", actor.RoleSupport})
+	if err := service.AuthorizeRead(support, accountID, "budgets"); err != nil {
+		t.Fatalf("support read: %v", err)
+	}
+
+	entries, err := service.ListHistory(owner, accountID, model.AuditEntityAccount, accountID)
+	if err != nil {
+		t.Fatalf("list access history: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected only the staff read to be audited, got %d entries", len(entries))
+	}
+	entry := entries[0]
+	if entry.ActorID != "support-1" || entry.Operation != model.AuditOperationRead || entry.Before != nil {
+		t.Fatalf("unexpected audit entry: %+v", entry)
+	}
+	var access accountAccess
+	if err := json.Unmarshal(entry.After, &access); err != nil {
+		t.Fatalf("decode access snapshot: %v", err)
+	}
+	if access.Resource != "budgets" || !slices.Contains(access.Roles, actor.RoleSupport) {
+		t.Fatalf("unexpected access snapshot: %+v", access)
+	}
+
+	if _, err := service.ListHistory(owner, accountID, model.AuditEntityAccount, "other-account"); !IsValidationError(err) {
+		t.Fatalf("expected validation error for another account id, got %v", err)
+	}
+}
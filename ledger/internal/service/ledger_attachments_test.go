@@ -0,0 +1,159 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/blob"
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/model"
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/repository"
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/storage"
+)
+
+func TestAttachments(t *testing.T) {
+	const accountID = "account-attachments"
+	png := append([]byte("\x89PNG\r\n\x1a\n"), bytes.Repeat([]byte{1}, 2048)...)
+
+	createTransaction := func(t *testing.T, service LedgerService, accountID string) model.Transaction {
+		t.Helper()
+		tx, err := service.CreateTransaction(context.Background(), model.Transaction{AccountID: accountID, Amount: 100, Currency: "USD", Category: "salary", OccurredAt: time.Now().UTC()})
+		if err != nil {
+			t.Fatalf("create transaction: %v", err)
+		}
+		return tx
+	}
+	upload := func(service LedgerService, tx model.Transaction, name string, content []byte) (model.Attachment, error) {
+		attachment := model.Attachment{AccountID: tx.AccountID, TransactionID: tx.ID, FileName: name}
+		return service.UploadAttachment(context.Background(), attachment, bytes.NewReader(content))
+	}
+	countBlobs := func(t *testing.T, dir string) int {
+		t.Helper()
+		count := 0
+		err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+			if err == nil && !entry.IsDir() {
+				count++
+			}
+			return err
+		})
+		if err != nil {
+			t.Fatalf("walk blob directory: %v", err)
+		}
+		return count
+	}
+
+	tests := []struct {
+		name string
+		run  func(t *testing.T, service LedgerService, dir string)
+	}{
+		{
+			name: "uploaded content is stored and downloaded unchanged",
+			run: func(t *testing.T, service LedgerService, dir string) {
+				ctx := context.Background()
+				tx := createTransaction(t, service, accountID)
+
+				created, err := upload(service, tx, " receipt.png ", png)
+				if err != nil {
+					t.Fatalf("upload: %v", err)
+				}
+				if created.FileName != "receipt.png" || created.ContentType != "image/png" || created.Size != int64(len(png)) || created.Checksum == "" {
+					t.Fatalf("unexpected attachment %+v", created)
+				}
+
+				attachment, content, err := service.OpenAttachment(ctx, accountID, created.ID)
+				if err != nil {
+					t.Fatalf("open: %v", err)
+				}
+				data, err := io.ReadAll(content)
+				content.Close()
+				if err != nil || !bytes.Equal(data, png) || attachment.ID != created.ID {
+					t.Fatalf("expected the uploaded content, got %d bytes, %v", len(data), err)
+				}
+
+				items, err := service.ListAttachments(ctx, accountID, tx.ID)
+				if err != nil || len(items) != 1 || items[0].ID != created.ID {
+					t.Fatalf("expected the attachment in the list, got %+v, %v", items, err)
+				}
+				if _, err := service.GetAttachment(ctx, "another-account", created.ID); !IsNotFound(err) {
+					t.Fatalf("expected attachments of other accounts to be hidden, got %v", err)
+				}
+			},
+		},
+		{
+			name: "invalid uploads are rejected without leaving content behind",
+			run: func(t *testing.T, service LedgerService, dir string) {
+				tx := createTransaction(t, service, accountID)
+				foreign := createTransaction(t, service, "another-account")
+
+				if _, err := upload(service, tx, "notes.txt", []byte("plain text")); !IsValidationError(err) {
+					t.Fatalf("expected unsupported types to be rejected, got %v", err)
+				}
+				large := append([]byte("%PDF-1.7\n"), bytes.Repeat([]byte{0}, maxAttachmentSize)...)
+				if _, err := upload(service, tx, "invoice.pdf", large); !IsValidationError(err) {
+					t.Fatalf("expected oversized attachments to be rejected, got %v", err)
+				}
+				if _, err := upload(service, tx, "../receipt.png", png); !IsValidationError(err) {
+					t.Fatalf("expected file names with paths to be rejected, got %v", err)
+				}
+				foreign.AccountID = accountID
+				if _, err := upload(service, foreign, "receipt.png", png); !IsNotFound(err) {
+					t.Fatalf("expected transactions of other accounts to be rejected, got %v", err)
+				}
+				if count := countBlobs(t, dir); count != 0 {
+					t.Fatalf("expected no stored content, found %d objects", count)
+				}
+			},
+		},
+		{
+			name: "content is removed with the attachment and with its purged transaction",
+			run: func(t *testing.T, service LedgerService, dir string) {
+				ctx := context.Background()
+				tx := createTransaction(t, service, accountID)
+				first, err := upload(service, tx, "first.png", png)
+				if err != nil {
+					t.Fatalf("upload first: %v", err)
+				}
+				if _, err := upload(service, tx, "second.png", png); err != nil {
+					t.Fatalf("upload second: %v", err)
+				}
+
+				if err := service.DeleteAttachment(ctx, accountID, first.ID); err != nil {
+					t.Fatalf("delete attachment: %v", err)
+				}
+				if count := countBlobs(t, dir); count != 1 {
+					t.Fatalf("expected one stored object after the delete, found %d", count)
+				}
+
+				if err := service.DeleteTransaction(ctx, tx.ID); err != nil {
+					t.Fatalf("delete transaction: %v", err)
+				}
+				if count := countBlobs(t, dir); count != 1 {
+					t.Fatalf("expected trashed transactions to keep their attachments, found %d objects", count)
+				}
+				if _, err := service.PurgeDeleted(ctx, time.Now().Add(time.Minute)); err != nil {
+					t.Fatalf("purge: %v", err)
+				}
+				if count := countBlobs(t, dir); count != 0 {
+					t.Fatalf("expected purged transactions to lose their attachments, found %d objects", count)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			blobs, err := blob.NewFileStore(dir)
+			if err != nil {
+				t.Fatalf("new file store: %v", err)
+			}
+			store := storage.NewInMemoryLedgerStorage()
+			service := NewValidationService(NewLedgerService(repository.NewInMemoryLedgerRepository(store), nil, nil, nil, blobs))
+			tt.run(t, service, dir)
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/actor"
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/model"
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/repository"
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/storage"
+)
+
+func TestLedgerWritesRecordAuditHistory(t *testing.T) {
+	ctx := actor.WithUserID(context.Background(), "user-1")
+	store := storage.NewInMemoryLedgerStorage()
+	repo := repository.NewInMemoryLedgerRepository(store)
+	service := NewLedgerService(repo, nil, nil, nil, nil)
+
+	accountID := "account-audit"
+	month := time.Date(2024, time.July, 1, 0, 0, 0, 0, time.UTC)
+	if _, err := service.CreateBudget(ctx, model.Budget{
+		AccountID: accountID,
+		Name:      "Food",
+		Amount:    100,
+		Currency:  "USD",
+		Period:    "monthly",
+		Month:     month,
+	}); err != nil {
+		t.Fatalf("create budget: %v", err)
+	}
+	tx, err := service.CreateTransaction(ctx, model.Transaction{
+		AccountID:   accountID,
+		Amount:      -10,
+		Currency:    "USD",
+		Category:    "Food",
+		Description: "lunch",
+		OccurredAt:  month.AddDate(0, 0, 1),
+	})
+	if err != nil {
+		t.Fatalf("create transaction: %v", err)
+	}
+	tx.Description = "dinner"
+	if _, err := service.UpdateTransaction(ctx, tx); err != nil {
+		t.Fatalf("update transaction: %v", err)
+	}
+	if err := service.DeleteTransaction(ctx, tx.ID); err != nil {
+		t.Fatalf("delete transaction: %v", err)
+	}
+
+	entries, err := service.ListHistory(ctx, accountID, model.AuditEntityTransaction, tx.ID)
+	if err != nil {
+		t.Fatalf("list history: %v", err)
+	}
+	wantOps := []string{model.AuditOperationCreate, model.AuditOperationUpdate, model.AuditOperationDelete}
+	if len(entries) != len(wantOps) {
+		t.Fatalf("expected %d entries, got %d", len(wantOps), len(entries))
+	}
+	for i, entry := range entries {
+		if entry.Operation != wantOps[i] {
+			t.Fatalf("entry %d: expected operation %q, got %q", i, wantOps[i], entry.Operation)
+		}
+		if entry.ActorID != "user-1" {
+			t.Fatalf("entry %d: expected actor user-1, got %q", i, entry.ActorID)
+		}
+	}
+	if entries[0].Before != nil {
+		t.Fatalf("expected empty before snapshot on create, got %s", entries[0].Before)
+	}
+	if entries[2].After != nil {
+		t.Fatalf("expected empty after snapshot on delete, got %s", entries[2].After)
+	}
+
+	var before, after model.Transaction
+	if err := json.Unmarshal(entries[1].Before, &before); err != nil {
+		t.Fatalf("decode before snapshot: %v", err)
+	}
+	if err := json.Unmarshal(entries[1].After, &after); err != nil {
+		t.Fatalf("decode after snapshot: %v", err)
+	}
+	if before.Description != "lunch" || after.Description != "dinner" {
+		t.Fatalf("unexpected snapshots: before %q, after %q", before.Description, after.Description)
+	}
+
+	other, err := service.ListHistory(ctx, "other-account", model.AuditEntityTransaction, tx.ID)
+	if err != nil {
+		t.Fatalf("list history for other account: %v", err)
+	}
+	if len(other) != 0 {
+		t.Fatalf("expected no entries for other account, got %d", len(other))
+	}
+}
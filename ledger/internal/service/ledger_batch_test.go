@@ -0,0 +1,217 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/model"
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/repository"
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/storage"
+)
+
+func newBatchTestService(t *testing.T, accountID string, month time.Time) LedgerService {
+	t.Helper()
+	store := storage.NewInMemoryLedgerStorage()
+	repo := repository.NewInMemoryLedgerRepository(store)
+	svc := NewValidationService(NewLedgerService(repo, nil, nil, nil, nil))
+	if _, err := svc.CreateBudget(context.Background(), model.Budget{
+		AccountID: accountID,
+		Name:      "Food",
+		Amount:    100,
+		Currency:  "USD",
+		Period:    "monthly",
+		Month:     month,
+	}); err != nil {
+		t.Fatalf("create budget: %v", err)
+	}
+	return svc
+}
+
+func TestBatchCreateTransactionsBestEffortReportsPerItemResults(t *testing.T) {
+	ctx := context.Background()
+	accountID := "account-batch"
+	month := time.Date(2024, time.September, 1, 0, 0, 0, 0, time.UTC)
+	svc := newBatchTestService(t, accountID, month)
+
+	items := []model.Transaction{
+		{Amount: -60, Currency: "USD", Category: "Food", OccurredAt: month.AddDate(0, 0, 1)},
+		{Amount: 0, Currency: "USD", Category: "Food", OccurredAt: month.AddDate(0, 0, 1)},
+		{Amount: -50, Currency: "USD", Category: "Food", OccurredAt: month.AddDate(0, 0, 2)},
+		{Amount: 500, Currency: "USD", Category: "Salary", OccurredAt: month.AddDate(0, 0, 3)},
+	}
+	results, err := svc.BatchCreateTransactions(ctx, accountID, items, false)
+	if err != nil {
+		t.Fatalf("batch create: %v", err)
+	}
+	if len(results) != len(items) {
+		t.Fatalf("expected %d results, got %d", len(items), len(results))
+	}
+	for i, result := range results {
+		if result.Index != i {
+			t.Fatalf("result %d: expected index %d, got %d", i, i, result.Index)
+		}
+	}
+	if results[0].Err != nil || results[0].Transaction.ID == "" {
+		t.Fatalf("expected first item to be created, got %v", results[0].Err)
+	}
+	if !IsValidationError(results[1].Err) {
+		t.Fatalf("expected validation error for zero amount, got %v", results[1].Err)
+	}
+	if !IsBudgetExceeded(results[2].Err) {
+		t.Fatalf("expected earlier batch items to count against the budget, got %v", results[2].Err)
+	}
+	if results[3].Err != nil {
+		t.Fatalf("expected income to be created, got %v", results[3].Err)
+	}
+	if items := svc.ListTransactions(ctx, accountID); len(items) != 2 {
+		t.Fatalf("expected 2 stored transactions, got %d", len(items))
+	}
+}
+
+func TestBatchCreateTransactionsAtomicWritesNothingOnFailure(t *testing.T) {
+	ctx := context.Background()
+	accountID := "account-batch-atomic"
+	month := time.Date(2024, time.September, 1, 0, 0, 0, 0, time.UTC)
+	svc := newBatchTestService(t, accountID, month)
+
+	items := []model.Transaction{
+		{Amount: -10, Currency: "USD", Category: "Food", OccurredAt: month.AddDate(0, 0, 1)},
+		{Amount: -10, Currency: "USD", Category: "Travel", OccurredAt: month.AddDate(0, 0, 1)},
+	}
+	results, err := svc.BatchCreateTransactions(ctx, accountID, items, true)
+	if err != nil {
+		t.Fatalf("batch create: %v", err)
+	}
+	if !IsBatchAborted(results[0].Err) {
+		t.Fatalf("expected valid item to be aborted, got %v", results[0].Err)
+	}
+	if !IsBudgetMissing(results[1].Err) {
+		t.Fatalf("expected missing budget error, got %v", results[1].Err)
+	}
+	if items := svc.ListTransactions(ctx, accountID); len(items) != 0 {
+		t.Fatalf("expected no stored transactions, got %d", len(items))
+	}
+}
+
+func TestBatchCreateTransactionsRejectsTakenIDs(t *testing.T) {
+	ctx := context.Background()
+	accountID := "account-batch-ids"
+	month := time.Date(2024, time.September, 1, 0, 0, 0, 0, time.UTC)
+	svc := newBatchTestService(t, accountID, month)
+
+	live, err := svc.CreateTransaction(ctx, model.Transaction{
+		AccountID: accountID, Amount: 100, Currency: "USD", Category: "Salary", OccurredAt: month,
+	})
+	if err != nil {
+		t.Fatalf("create transaction: %v", err)
+	}
+	trashed, err := svc.CreateTransaction(ctx, model.Transaction{
+		AccountID: accountID, Amount: 50, Currency: "USD", Category: "Salary", OccurredAt: month,
+	})
+	if err != nil {
+		t.Fatalf("create transaction: %v", err)
+	}
+	if err := svc.DeleteTransaction(ctx, trashed.ID); err != nil {
+		t.Fatalf("delete transaction: %v", err)
+	}
+	foreign, err := svc.CreateTransaction(ctx, model.Transaction{
+		AccountID: "account-batch-ids-other", Amount: 70, Currency: "USD", Category: "Salary", OccurredAt: month,
+	})
+	if err != nil {
+		t.Fatalf("create transaction: %v", err)
+	}
+
+	items := []model.Transaction{
+		{ID: "batch-new", Amount: 10, Currency: "USD", Category: "Salary", OccurredAt: month},
+		{ID: "batch-new", Amount: 20, Currency: "USD", Category: "Salary", OccurredAt: month},
+		{ID: live.ID, Amount: 30, Currency: "USD", Category: "Salary", OccurredAt: month},
+		{ID: trashed.ID, Amount: 40, Currency: "USD", Category: "Salary", OccurredAt: month},
+		{ID: foreign.ID, Amount: 60, Currency: "USD", Category: "Salary", OccurredAt: month},
+	}
+	results, err := svc.BatchCreateTransactions(ctx, accountID, items, false)
+	if err != nil {
+		t.Fatalf("batch create: %v", err)
+	}
+	if results[0].Err != nil {
+		t.Fatalf("expected first item to be created, got %v", results[0].Err)
+	}
+	if !IsValidationError(results[1].Err) {
+		t.Fatalf("expected duplicate id in the batch to be rejected, got %v", results[1].Err)
+	}
+	if !IsTransactionExists(results[2].Err) {
+		t.Fatalf("expected conflict with a live transaction, got %v", results[2].Err)
+	}
+	if !IsTransactionExists(results[3].Err) {
+		t.Fatalf("expected conflict with a deleted transaction, got %v", results[3].Err)
+	}
+	if !IsTransactionExists(results[4].Err) {
+		t.Fatalf("expected conflict with a transaction of another account, got %v", results[4].Err)
+	}
+	if items := svc.ListTransactions(ctx, accountID); len(items) != 2 {
+		t.Fatalf("expected 2 stored transactions, got %d", len(items))
+	}
+}
+
+func TestBatchUpdateAndDeleteTransactions(t *testing.T) {
+	ctx := context.Background()
+	accountID := "account-batch-update"
+	month := time.Date(2024, time.September, 1, 0, 0, 0, 0, time.UTC)
+	svc := newBatchTestService(t, accountID, month)
+
+	created, err := svc.BatchCreateTransactions(ctx, accountID, []model.Transaction{
+		{Amount: -10, Currency: "USD", Category: "Food", Description: "a", OccurredAt: month.AddDate(0, 0, 1)},
+		{Amount: -20, Currency: "USD", Category: "Food", Description: "b", OccurredAt: month.AddDate(0, 0, 2)},
+	}, true)
+	if err != nil {
+		t.Fatalf("batch create: %v", err)
+	}
+	first, second := created[0].Transaction, created[1].Transaction
+
+	first.Description = "updated"
+	updated, err := svc.BatchUpdateTransactions(ctx, accountID, []model.Transaction{
+		first,
+		{ID: "missing", Amount: -1, Currency: "USD", Category: "Food"},
+	}, false)
+	if err != nil {
+		t.Fatalf("batch update: %v", err)
+	}
+	if updated[0].Err != nil || updated[0].Transaction.Description != "updated" {
+		t.Fatalf("expected first item updated, got %+v", updated[0])
+	}
+	if !IsNotFound(updated[1].Err) {
+		t.Fatalf("expected not found for missing transaction, got %v", updated[1].Err)
+	}
+
+	deleted, err := svc.BatchDeleteTransactions(ctx, accountID, []string{first.ID, second.ID, "missing"}, true)
+	if err != nil {
+		t.Fatalf("batch delete: %v", err)
+	}
+	if !IsBatchAborted(deleted[0].Err) || !IsBatchAborted(deleted[1].Err) || !IsNotFound(deleted[2].Err) {
+		t.Fatalf("unexpected atomic delete results: %+v", deleted)
+	}
+	if items := svc.ListTransactions(ctx, accountID); len(items) != 2 {
+		t.Fatalf("expected atomic delete to keep transactions, got %d", len(items))
+	}
+
+	deleted, err = svc.BatchDeleteTransactions(ctx, accountID, []string{first.ID, second.ID}, true)
+	if err != nil {
+		t.Fatalf("batch delete: %v", err)
+	}
+	for _, result := range deleted {
+		if result.Err != nil {
+			t.Fatalf("expected delete to succeed, got %v", result.Err)
+		}
+	}
+	trash, err := svc.ListTrash(ctx, accountID)
+	if err != nil {
+		t.Fatalf("list trash: %v", err)
+	}
+	if len(trash.Transactions) != 2 {
+		t.Fatalf("expected 2 transactions in trash, got %d", len(trash.Transactions))
+	}
+
+	if _, err := svc.BatchDeleteTransactions(ctx, accountID, make([]string, MaxBatchSize+1), false); !IsValidationError(err) {
+		t.Fatalf("expected validation error for oversized batch, got %v", err)
+	}
+}
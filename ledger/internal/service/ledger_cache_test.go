@@ -22,7 +22,7 @@ func TestGetReportSummaryCachesResults(t *testing.T) {
 	summaryCache.SetSummaryFunc = func(ctx context.Context, key string, summary model.ReportSummary) error {
 		return nil
 	}
-	service := NewLedgerService(repo, nil, summaryCache, nil)
+	service := NewLedgerService(repo, nil, summaryCache, nil, nil)
 
 	accountID := "account-1"
 	category := "Food"
@@ -79,7 +79,7 @@ func TestListBudgetsUsesCacheAndInvalidatesOnWrite(t *testing.T) {
 	budgetCache.DeleteBudgetsFunc = func(ctx context.Context) error {
 		return nil
 	}
-	service := NewLedgerService(repo, nil, nil, budgetCache)
+	service := NewLedgerService(repo, nil, nil, budgetCache, nil)
 
 	accountID := "account-2"
 	budget := model.Budget{
@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/model"
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/repository"
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/storage"
+)
+
+func TestLedgerWritesRecordDomainEvents(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewInMemoryLedgerStorage()
+	repo := repository.NewInMemoryLedgerRepository(store)
+	service := NewLedgerService(repo, nil, nil, nil, nil)
+
+	accountID := "account-events"
+	month := time.Date(2024, time.July, 1, 0, 0, 0, 0, time.UTC)
+
+	budget, err := service.CreateBudget(ctx, model.Budget{
+		AccountID: accountID,
+		Name:      "Food",
+		Amount:    100,
+		Currency:  "USD",
+		Period:    "monthly",
+		Month:     month,
+	})
+	if err != nil {
+		t.Fatalf("create budget: %v", err)
+	}
+	tx, err := service.CreateTransaction(ctx, model.Transaction{
+		AccountID:  accountID,
+		Amount:     -10,
+		Currency:   "USD",
+		Category:   "Food",
+		OccurredAt: month.AddDate(0, 0, 1),
+	})
+	if err != nil {
+		t.Fatalf("create transaction: %v", err)
+	}
+	tx.Description = "updated"
+	if _, err := service.UpdateTransaction(ctx, tx); err != nil {
+		t.Fatalf("update transaction: %v", err)
+	}
+	if err := service.DeleteTransaction(ctx, tx.ID); err != nil {
+		t.Fatalf("delete transaction: %v", err)
+	}
+	if _, err := service.CreateReport(ctx, model.Report{AccountID: accountID, Name: "July", Period: "2024-07"}); err != nil {
+		t.Fatalf("create report: %v", err)
+	}
+
+	items, err := service.ListEvents(ctx, accountID, 0, 100)
+	if err != nil {
+		t.Fatalf("list events: %v", err)
+	}
+	wantTypes := []string{
+		model.EventBudgetChanged,
+		model.EventTransactionCreated,
+		// The new description names a payee, which is created on the way.
+		model.EventPayeeChanged,
+		model.EventTransactionUpdated,
+		model.EventTransactionDeleted,
+		model.EventReportGenerated,
+	}
+	if len(items) != len(wantTypes) {
+		t.Fatalf("expected %d events, got %d", len(wantTypes), len(items))
+	}
+	for i, event := range items {
+		if event.Type != wantTypes[i] {
+			t.Fatalf("event %d: expected type %q, got %q", i, wantTypes[i], event.Type)
+		}
+		if i > 0 && event.Offset <= items[i-1].Offset {
+			t.Fatalf("expected increasing offsets, got %d after %d", event.Offset, items[i-1].Offset)
+		}
+	}
+	if items[0].AggregateID != budget.ID {
+		t.Fatalf("expected budget aggregate %q, got %q", budget.ID, items[0].AggregateID)
+	}
+
+	var budgetPayload struct {
+		Operation string `json:"operation"`
+		Budget    struct {
+			ID        string `json:"id"`
+			AccountID string `json:"account_id"`
+		} `json:"budget"`
+	}
+	if err := json.Unmarshal(items[0].Payload, &budgetPayload); err != nil {
+		t.Fatalf("decode payload: %v", err)
+	}
+	if budgetPayload.Operation != "created" || budgetPayload.Budget.ID != budget.ID || budgetPayload.Budget.AccountID != accountID {
+		t.Fatalf("expected a snake_case payload, got %s", items[0].Payload)
+	}
+	var deletedPayload map[string]map[string]any
+	if err := json.Unmarshal(items[4].Payload, &deletedPayload); err != nil {
+		t.Fatalf("decode payload: %v", err)
+	}
+	if deletedPayload["transaction"]["occurred_at"] == nil {
+		t.Fatalf("expected a snake_case payload, got %s", items[4].Payload)
+	}
+
+	resumed, err := service.ListEvents(ctx, accountID, items[3].Offset, 100)
+	if err != nil {
+		t.Fatalf("list events from offset: %v", err)
+	}
+	if len(resumed) != 2 || resumed[0].Type != model.EventTransactionDeleted {
+		t.Fatalf("expected to resume after offset %d, got %+v", items[3].Offset, resumed)
+	}
+
+	other, err := service.ListEvents(ctx, "another-account", 0, 100)
+	if err != nil {
+		t.Fatalf("list events for another account: %v", err)
+	}
+	if len(other) != 0 {
+		t.Fatalf("expected no events for another account, got %d", len(other))
+	}
+}
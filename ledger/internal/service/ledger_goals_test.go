@@ -0,0 +1,241 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/model"
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/repository"
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/storage"
+)
+
+func TestGoals(t *testing.T) {
+	const accountID = "account-goals"
+	now := time.Now().UTC()
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	previous := month.AddDate(0, -1, 0)
+	// Six months left counting the current one.
+	deadline := month.AddDate(0, 5, 0)
+
+	setup := func(t *testing.T, service LedgerService) {
+		t.Helper()
+		for _, start := range []time.Time{previous, month} {
+			if _, err := service.CreateBudget(context.Background(), model.Budget{
+				AccountID: accountID,
+				Name:      "Savings",
+				Amount:    5000,
+				Currency:  "USD",
+				Period:    "monthly",
+				Month:     start,
+			}); err != nil {
+				t.Fatalf("create budget: %v", err)
+			}
+		}
+	}
+	create := func(t *testing.T, service LedgerService, amount float64, currency string, occurredAt time.Time) {
+		t.Helper()
+		if _, err := service.CreateTransaction(context.Background(), model.Transaction{AccountID: accountID, Amount: amount, Currency: currency, Category: "Savings", OccurredAt: occurredAt}); err != nil {
+			t.Fatalf("create transaction: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name string
+		run  func(t *testing.T, service LedgerService)
+	}{
+		{
+			name: "category contributions and the required monthly rate",
+			run: func(t *testing.T, service LedgerService) {
+				ctx := context.Background()
+				setup(t, service)
+				create(t, service, -300, "USD", month)
+				create(t, service, 50, "USD", month)
+				create(t, service, 70, "EUR", month)
+
+				goal, err := service.CreateGoal(ctx, model.Goal{AccountID: accountID, Name: " Vacation ", TargetAmount: 1000, Currency: "USD", Deadline: deadline.Add(15 * time.Hour), Category: "Savings"})
+				if err != nil {
+					t.Fatalf("create goal: %v", err)
+				}
+				if goal.Name != "Vacation" || !goal.Deadline.Equal(deadline) {
+					t.Fatalf("expected a trimmed name and a date deadline, got %+v", goal)
+				}
+				want := model.GoalProgress{Saved: 250, Remaining: 750, Percent: 25, MonthsLeft: 6, MonthlyRequired: 125}
+				if goal.Progress != want {
+					t.Fatalf("expected progress %+v, got %+v", want, goal.Progress)
+				}
+
+				goal.TargetAmount = 200
+				goal.Deadline = previous
+				updated, err := service.UpdateGoal(ctx, goal)
+				if err != nil {
+					t.Fatalf("update goal: %v", err)
+				}
+				if updated.Progress.Remaining != 0 || updated.Progress.MonthsLeft != 0 || updated.Progress.MonthlyRequired != 0 {
+					t.Fatalf("expected a reached goal, got %+v", updated.Progress)
+				}
+				goal.TargetAmount = 1000
+				if updated, err = service.UpdateGoal(ctx, goal); err != nil {
+					t.Fatalf("update goal: %v", err)
+				}
+				if updated.Progress.MonthsLeft != 0 || updated.Progress.MonthlyRequired != 750 {
+					t.Fatalf("expected the whole remainder to be due after the deadline, got %+v", updated.Progress)
+				}
+
+				if err := service.DeleteGoal(ctx, accountID, goal.ID); err != nil {
+					t.Fatalf("delete goal: %v", err)
+				}
+				history, err := service.ListHistory(ctx, accountID, model.AuditEntityGoal, goal.ID)
+				if err != nil {
+					t.Fatalf("history: %v", err)
+				}
+				if len(history) != 4 {
+					t.Fatalf("expected create, two updates and delete in the history, got %+v", history)
+				}
+			},
+		},
+		{
+			name: "wallet goals follow the wallet balance",
+			run: func(t *testing.T, service LedgerService) {
+				ctx := context.Background()
+				cash := mustCreateWallet(t, service, accountID, "Cash", "USD")
+				savings := mustCreateWallet(t, service, accountID, "Savings", "USD")
+				if _, err := service.CreateTransaction(ctx, model.Transaction{AccountID: accountID, Amount: 1000, Currency: "USD", Category: "Salary", OccurredAt: month, WalletID: cash.ID}); err != nil {
+					t.Fatalf("create transaction: %v", err)
+				}
+				if _, err := service.CreateTransfer(ctx, model.Transfer{AccountID: accountID, FromWalletID: cash.ID, ToWalletID: savings.ID, Amount: 400, OccurredAt: month}); err != nil {
+					t.Fatalf("create transfer: %v", err)
+				}
+
+				if _, err := service.CreateGoal(ctx, model.Goal{AccountID: accountID, Name: "Car", TargetAmount: 1000, Currency: "EUR", Deadline: deadline, WalletID: savings.ID}); !IsValidationError(err) {
+					t.Fatalf("expected a goal in another currency than its wallet to be rejected, got %v", err)
+				}
+				if _, err := service.CreateGoal(ctx, model.Goal{AccountID: accountID, Name: "Car", TargetAmount: 1000, Currency: "USD", Deadline: deadline, WalletID: "missing"}); !IsValidationError(err) {
+					t.Fatalf("expected an unknown wallet to be rejected, got %v", err)
+				}
+				car, err := service.CreateGoal(ctx, model.Goal{AccountID: accountID, Name: "Car", TargetAmount: 1000, Currency: "USD", Deadline: deadline, WalletID: savings.ID})
+				if err != nil {
+					t.Fatalf("create goal: %v", err)
+				}
+				goals, err := service.ListGoals(ctx, accountID)
+				if err != nil {
+					t.Fatalf("list goals: %v", err)
+				}
+				if len(goals) != 1 || goals[0].ID != car.ID || goals[0].Progress.Saved != 400 || goals[0].Progress.MonthlyRequired != 100 {
+					t.Fatalf("expected the transfer into the wallet to count, got %+v", goals)
+				}
+			},
+		},
+		{
+			name: "reports show progress at the end of the period",
+			run: func(t *testing.T, service LedgerService) {
+				ctx := context.Background()
+				setup(t, service)
+				create(t, service, -100, "USD", previous)
+				create(t, service, -200, "USD", month)
+				goal, err := service.CreateGoal(ctx, model.Goal{AccountID: accountID, Name: "Laptop", TargetAmount: 800, Currency: "USD", Deadline: deadline, Category: "Savings"})
+				if err != nil {
+					t.Fatalf("create goal: %v", err)
+				}
+
+				report, err := service.CreateReport(ctx, model.Report{AccountID: accountID, Name: "Previous", Period: previous.Format("2006-01")})
+				if err != nil {
+					t.Fatalf("create report: %v", err)
+				}
+				want := model.ReportGoal{
+					GoalID:       goal.ID,
+					Name:         "Laptop",
+					TargetAmount: 800,
+					Currency:     "USD",
+					Deadline:     deadline,
+					Progress:     model.GoalProgress{Saved: 100, Remaining: 700, Percent: 12.5, MonthsLeft: 7, MonthlyRequired: 100},
+				}
+				if len(report.Goals) != 1 || report.Goals[0] != want {
+					t.Fatalf("expected %+v in the report, got %+v", want, report.Goals)
+				}
+
+				other, err := service.CreateReport(ctx, model.Report{AccountID: accountID, Name: "Euro", Period: month.Format("2006-01"), Currency: "EUR"})
+				if err != nil {
+					t.Fatalf("create report: %v", err)
+				}
+				if len(other.Goals) != 0 {
+					t.Fatalf("expected goals in other currencies to be left out, got %+v", other.Goals)
+				}
+			},
+		},
+		{
+			name: "tagged transactions contribute across categories",
+			run: func(t *testing.T, service LedgerService) {
+				ctx := context.Background()
+				setup(t, service)
+				for _, tx := range []model.Transaction{
+					{Amount: -200, Currency: "USD", Category: "Savings", Tags: []string{"vacation"}},
+					{Amount: 500, Currency: "USD", Category: "Salary", Tags: []string{"vacation", "bonus"}},
+					{Amount: -400, Currency: "USD", Category: "Savings"},
+					{Amount: -100, Currency: "USD", Category: "Savings", Tags: []string{"vacation"}},
+				} {
+					tx.AccountID = accountID
+					tx.OccurredAt = month
+					if _, err := service.CreateTransaction(ctx, tx); err != nil {
+						t.Fatalf("create transaction: %v", err)
+					}
+				}
+
+				goal, err := service.CreateGoal(ctx, model.Goal{AccountID: accountID, Name: "Vacation", TargetAmount: 1000, Currency: "USD", Deadline: deadline, Tag: " vacation "})
+				if err != nil {
+					t.Fatalf("create goal: %v", err)
+				}
+				if goal.Tag != "vacation" {
+					t.Fatalf("expected a trimmed tag, got %q", goal.Tag)
+				}
+				// Tagged income is a withdrawal, like income in a goal category.
+				if goal.Progress.Saved != -200 {
+					t.Fatalf("expected tagged expenses net of tagged income, got %+v", goal.Progress)
+				}
+				goals, err := service.ListGoals(ctx, accountID)
+				if err != nil {
+					t.Fatalf("list goals: %v", err)
+				}
+				if len(goals) != 1 || goals[0].Tag != "vacation" || goals[0].Progress != goal.Progress {
+					t.Fatalf("expected the tag goal with its progress, got %+v", goals)
+				}
+			},
+		},
+		{
+			name: "invalid goals are rejected",
+			run: func(t *testing.T, service LedgerService) {
+				ctx := context.Background()
+				valid := model.Goal{AccountID: accountID, Name: "Goal", TargetAmount: 100, Currency: "USD", Deadline: deadline, Category: "Savings"}
+				invalid := []func(goal *model.Goal){
+					func(goal *model.Goal) { goal.AccountID = "" },
+					func(goal *model.Goal) { goal.Name = " " },
+					func(goal *model.Goal) { goal.TargetAmount = 0 },
+					func(goal *model.Goal) { goal.Currency = "" },
+					func(goal *model.Goal) { goal.Deadline = time.Time{} },
+					func(goal *model.Goal) { goal.Category = "" },
+					func(goal *model.Goal) { goal.WalletID = "wallet" },
+					func(goal *model.Goal) { goal.Category = model.CategoryTransfer },
+					func(goal *model.Goal) { goal.Tag = "vacation" },
+					func(goal *model.Goal) { goal.Category, goal.Tag = "", "a;b" },
+				}
+				for i, change := range invalid {
+					goal := valid
+					change(&goal)
+					if _, err := service.CreateGoal(ctx, goal); !IsValidationError(err) {
+						t.Fatalf("case %d: expected goal %+v to be rejected, got %v", i, goal, err)
+					}
+				}
+				if err := service.DeleteGoal(ctx, accountID, "missing"); !IsNotFound(err) {
+					t.Fatalf("expected a missing goal to be reported, got %v", err)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := storage.NewInMemoryLedgerStorage()
+			tt.run(t, NewValidationService(NewLedgerService(repository.NewInMemoryLedgerRepository(store), nil, nil, nil, nil)))
+		})
+	}
+}
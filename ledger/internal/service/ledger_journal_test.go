@@ -0,0 +1,178 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/model"
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/repository"
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/storage"
+)
+
+func TestJournal(t *testing.T) {
+	const accountID = "account-journal"
+	month := time.Date(2024, time.October, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		run  func(t *testing.T, service LedgerService, store *storage.InMemoryLedgerStorage)
+	}{
+		{
+			name: "writes keep the journal balanced",
+			run: func(t *testing.T, service LedgerService, store *storage.InMemoryLedgerStorage) {
+				ctx := context.Background()
+				if _, err := service.CreateBudget(ctx, model.Budget{
+					AccountID: accountID,
+					Name:      "Food",
+					Amount:    500,
+					Currency:  "USD",
+					Period:    "monthly",
+					Month:     month,
+				}); err != nil {
+					t.Fatalf("create budget: %v", err)
+				}
+				card := mustCreateWallet(t, service, accountID, "Card", "USD")
+				credit := mustCreateWallet(t, service, accountID, "Credit card", "USD")
+
+				steps := []struct {
+					name string
+					run  func() error
+				}{
+					{"income", func() error {
+						_, err := service.CreateTransaction(ctx, model.Transaction{AccountID: accountID, Amount: 1000, Currency: "USD", Category: "salary", OccurredAt: month, WalletID: card.ID})
+						return err
+					}},
+					{"expense outside wallets", func() error {
+						_, err := service.CreateTransaction(ctx, model.Transaction{AccountID: accountID, Amount: -40, Currency: "USD", Category: "Food", OccurredAt: month})
+						return err
+					}},
+					{"batch", func() error {
+						results, err := service.BatchCreateTransactions(ctx, accountID, []model.Transaction{
+							{Amount: -60, Currency: "USD", Category: "Food", OccurredAt: month, WalletID: credit.ID},
+							{Amount: 25, Currency: "EUR", Category: "gift", OccurredAt: month},
+						}, true)
+						if err != nil {
+							return err
+						}
+						_, err = service.BatchDeleteTransactions(ctx, accountID, []string{results[1].Transaction.ID}, true)
+						return err
+					}},
+					{"update", func() error {
+						for _, tx := range service.ListTransactions(ctx, accountID) {
+							if tx.Category == "salary" {
+								tx.Amount = 1200
+								_, err := service.UpdateTransaction(ctx, tx)
+								return err
+							}
+						}
+						return errors.New("salary not found")
+					}},
+					{"transfer", func() error {
+						_, err := service.CreateTransfer(ctx, model.Transfer{AccountID: accountID, FromWalletID: card.ID, ToWalletID: credit.ID, Amount: 60, OccurredAt: month})
+						return err
+					}},
+					{"wallet type change", func() error {
+						credit.Type = model.WalletTypeCredit
+						_, err := service.UpdateWallet(ctx, credit)
+						return err
+					}},
+					{"delete and restore", func() error {
+						var expense model.Transaction
+						for _, tx := range service.ListTransactions(ctx, accountID) {
+							if tx.Category == "Food" && tx.WalletID == "" {
+								expense = tx
+							}
+						}
+						if err := service.DeleteTransaction(ctx, expense.ID); err != nil {
+							return err
+						}
+						if err := service.VerifyJournal(ctx, accountID); err != nil {
+							return err
+						}
+						_, err := service.RestoreTransaction(ctx, accountID, expense.ID)
+						return err
+					}},
+				}
+				for _, step := range steps {
+					if err := step.run(); err != nil {
+						t.Fatalf("%s: %v", step.name, err)
+					}
+					if err := service.VerifyJournal(ctx, accountID); err != nil {
+						t.Fatalf("%s: %v", step.name, err)
+					}
+				}
+
+				balance, err := service.GetTrialBalance(ctx, accountID)
+				if err != nil {
+					t.Fatalf("trial balance: %v", err)
+				}
+				lines := map[string]model.TrialBalanceLine{}
+				for _, line := range balance.Lines {
+					lines[line.Account] = line
+				}
+				want := map[string][2]float64{
+					"assets:wallet:" + card.ID:        {1140, 0},
+					"liabilities:wallet:" + credit.ID: {0, 0},
+					"assets:unassigned":               {0, 40},
+					"income:salary":                   {0, 1200},
+					"expenses:Food":                   {100, 0},
+					"equity:transfers":                {0, 0},
+				}
+				if len(lines) != len(want) {
+					t.Fatalf("expected %d journal accounts, got %+v", len(want), balance.Lines)
+				}
+				for account, amounts := range want {
+					line := lines[account]
+					if line.Debit != amounts[0] || line.Credit != amounts[1] {
+						t.Fatalf("%s: expected debit %v and credit %v, got %+v", account, amounts[0], amounts[1], line)
+					}
+				}
+				if lines["liabilities:wallet:"+credit.ID].Type != model.JournalAccountLiability {
+					t.Fatalf("expected the credit wallet to be a liability, got %+v", lines["liabilities:wallet:"+credit.ID])
+				}
+				if len(balance.Totals) != 1 || balance.Totals[0].Debit != 1240 || balance.Totals[0].Credit != 1240 {
+					t.Fatalf("expected balanced USD totals of 1240, got %+v", balance.Totals)
+				}
+			},
+		},
+		{
+			name: "verification reports a broken journal",
+			run: func(t *testing.T, service LedgerService, store *storage.InMemoryLedgerStorage) {
+				ctx := context.Background()
+				created, err := service.CreateTransaction(ctx, model.Transaction{AccountID: accountID, Amount: 100, Currency: "USD", Category: "salary", OccurredAt: month})
+				if err != nil {
+					t.Fatalf("create transaction: %v", err)
+				}
+				entries, err := service.ListJournalEntries(ctx, accountID)
+				if err != nil {
+					t.Fatalf("list journal entries: %v", err)
+				}
+				if len(entries) != 1 || entries[0].TransactionID != created.ID || len(entries[0].Postings) != 2 {
+					t.Fatalf("expected one entry with two postings, got %+v", entries)
+				}
+
+				unbalanced := entries[0]
+				unbalanced.Postings[1].Amount = -90
+				store.PutJournalEntries(unbalanced)
+				if err := service.VerifyJournal(ctx, accountID); !IsJournalInvariant(err) {
+					t.Fatalf("expected an unbalanced entry to be reported, got %v", err)
+				}
+
+				store.DeleteJournalEntries(created.ID)
+				if err := service.VerifyJournal(ctx, accountID); !IsJournalInvariant(err) {
+					t.Fatalf("expected a missing entry to be reported, got %v", err)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := storage.NewInMemoryLedgerStorage()
+			repo := repository.NewInMemoryLedgerRepository(store)
+			tt.run(t, NewValidationService(NewLedgerService(repo, nil, nil, nil, nil)), store)
+		})
+	}
+}
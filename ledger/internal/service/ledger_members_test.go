@@ -0,0 +1,247 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/actor"
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/model"
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/repository"
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/storage"
+)
+
+func TestSharedAccountMembership(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T, service LedgerService, store *storage.InMemoryLedgerStorage)
+	}{
+		{
+			name: "members read and write by role",
+			run: func(t *testing.T, service LedgerService, store *storage.InMemoryLedgerStorage) {
+				owner := actor.WithUserID(context.Background(), "owner")
+				account := mustCreateAccount(t, service, owner)
+				editor := joinAccount(t, service, owner, account.ID, "editor", model.MemberRoleEditor)
+				viewer := joinAccount(t, service, owner, account.ID, "viewer", model.MemberRoleViewer)
+				stranger := actor.WithUserID(context.Background(), "stranger")
+
+				for _, ctx := range []context.Context{owner, editor, viewer} {
+					if err := service.AuthorizeRead(ctx, account.ID, "transactions"); err != nil {
+						t.Fatalf("member read: %v", err)
+					}
+				}
+				for _, ctx := range []context.Context{owner, editor} {
+					if err := service.AuthorizeWrite(ctx, account.ID); err != nil {
+						t.Fatalf("member write: %v", err)
+					}
+				}
+				if err := service.AuthorizeWrite(viewer, account.ID); !errors.Is(err, ErrAccessDenied) {
+					t.Fatalf("expected viewers not to write, got %v", err)
+				}
+				if err := service.AuthorizeRead(stranger, account.ID, "transactions"); !errors.Is(err, ErrAccessDenied) {
+					t.Fatalf("expected strangers not to read, got %v", err)
+				}
+				if err := service.AuthorizeWrite(editor, "owner"); !errors.Is(err, ErrAccessDenied) {
+					t.Fatalf("expected the personal account of the owner to stay private, got %v", err)
+				}
+				if _, _, err := service.CreateInvitation(editor, account.ID, model.MemberRoleViewer); !errors.Is(err, ErrAccessDenied) {
+					t.Fatalf("expected only owners to invite, got %v", err)
+				}
+
+				accounts, err := service.ListAccounts(viewer)
+				if err != nil {
+					t.Fatalf("list accounts: %v", err)
+				}
+				if len(accounts) != 2 || !accounts[0].Personal || accounts[1].ID != account.ID || accounts[1].Role != model.MemberRoleViewer {
+					t.Fatalf("expected the personal and the shared account, got %+v", accounts)
+				}
+			},
+		},
+		{
+			name: "transactions record the creating member",
+			run: func(t *testing.T, service LedgerService, store *storage.InMemoryLedgerStorage) {
+				owner := actor.WithUserID(context.Background(), "owner")
+				account := mustCreateAccount(t, service, owner)
+				editor := joinAccount(t, service, owner, account.ID, "editor", model.MemberRoleEditor)
+
+				created, err := service.CreateTransaction(editor, model.Transaction{
+					AccountID: account.ID,
+					Amount:    100,
+					Currency:  "USD",
+					Category:  "salary",
+				})
+				if err != nil {
+					t.Fatalf("create transaction: %v", err)
+				}
+				if created.CreatedBy != "editor" {
+					t.Fatalf("expected the editor as creator, got %q", created.CreatedBy)
+				}
+
+				created.Description = "bonus"
+				updated, err := service.UpdateTransaction(owner, created)
+				if err != nil {
+					t.Fatalf("update transaction: %v", err)
+				}
+				if updated.CreatedBy != "editor" {
+					t.Fatalf("expected the creator to be kept on update, got %q", updated.CreatedBy)
+				}
+
+				results, err := service.BatchCreateTransactions(owner, account.ID, []model.Transaction{{Amount: 50, Currency: "USD", Category: "salary"}}, true)
+				if err != nil || results[0].Err != nil {
+					t.Fatalf("batch create: %v %v", err, results)
+				}
+				if results[0].Transaction.CreatedBy != "owner" {
+					t.Fatalf("expected the owner as creator of the batch, got %q", results[0].Transaction.CreatedBy)
+				}
+			},
+		},
+		{
+			name: "invitations are single use and expire",
+			run: func(t *testing.T, service LedgerService, store *storage.InMemoryLedgerStorage) {
+				owner := actor.WithUserID(context.Background(), "owner")
+				account := mustCreateAccount(t, service, owner)
+
+				invitation, token, err := service.CreateInvitation(owner, account.ID, model.MemberRoleEditor)
+				if err != nil {
+					t.Fatalf("create invitation: %v", err)
+				}
+				if invitation.TokenHash == token {
+					t.Fatal("expected only the token hash to be stored")
+				}
+				pending, err := service.ListInvitations(owner, account.ID)
+				if err != nil || len(pending) != 1 {
+					t.Fatalf("expected one pending invitation, got %v %v", pending, err)
+				}
+
+				first := actor.WithUserID(context.Background(), "first")
+				if _, err := service.AcceptInvitation(first, token); err != nil {
+					t.Fatalf("accept invitation: %v", err)
+				}
+				second := actor.WithUserID(context.Background(), "second")
+				if _, err := service.AcceptInvitation(second, token); !errors.Is(err, ErrInvalidInvitation) {
+					t.Fatalf("expected a used invitation to be rejected, got %v", err)
+				}
+
+				expired, expiredToken, err := service.CreateInvitation(owner, account.ID, model.MemberRoleViewer)
+				if err != nil {
+					t.Fatalf("create invitation: %v", err)
+				}
+				expired.ExpiresAt = time.Now().UTC().Add(-time.Minute)
+				store.PutInvitation(expired)
+				if _, err := service.AcceptInvitation(second, expiredToken); !errors.Is(err, ErrInvalidInvitation) {
+					t.Fatalf("expected an expired invitation to be rejected, got %v", err)
+				}
+
+				_, againToken, err := service.CreateInvitation(owner, account.ID, model.MemberRoleViewer)
+				if err != nil {
+					t.Fatalf("create invitation: %v", err)
+				}
+				if _, err := service.AcceptInvitation(first, againToken); !errors.Is(err, ErrAlreadyMember) {
+					t.Fatalf("expected ErrAlreadyMember, got %v", err)
+				}
+			},
+		},
+		{
+			name: "accounts keep an owner",
+			run: func(t *testing.T, service LedgerService, store *storage.InMemoryLedgerStorage) {
+				owner := actor.WithUserID(context.Background(), "owner")
+				account := mustCreateAccount(t, service, owner)
+				editor := joinAccount(t, service, owner, account.ID, "editor", model.MemberRoleEditor)
+
+				if _, err := service.UpdateMember(owner, account.ID, "owner", model.MemberRoleEditor); !errors.Is(err, ErrLastOwner) {
+					t.Fatalf("expected the last owner to stay, got %v", err)
+				}
+				if err := service.RemoveMember(owner, account.ID, "owner"); !errors.Is(err, ErrLastOwner) {
+					t.Fatalf("expected the last owner not to leave, got %v", err)
+				}
+				if _, err := service.UpdateMember(editor, account.ID, "editor", model.MemberRoleOwner); !errors.Is(err, ErrAccessDenied) {
+					t.Fatalf("expected editors not to change roles, got %v", err)
+				}
+
+				if _, err := service.UpdateMember(owner, account.ID, "editor", model.MemberRoleOwner); err != nil {
+					t.Fatalf("promote member: %v", err)
+				}
+				if err := service.RemoveMember(owner, account.ID, "owner"); err != nil {
+					t.Fatalf("leave account: %v", err)
+				}
+				if err := service.AuthorizeRead(owner, account.ID, "transactions"); !errors.Is(err, ErrAccessDenied) {
+					t.Fatalf("expected a former member to lose access, got %v", err)
+				}
+
+				entries, err := service.ListHistory(editor, account.ID, model.AuditEntityMember, "owner")
+				if err != nil {
+					t.Fatalf("list member history: %v", err)
+				}
+				if len(entries) != 2 || entries[1].Operation != model.AuditOperationDelete {
+					t.Fatalf("expected the membership changes to be audited, got %+v", entries)
+				}
+			},
+		},
+		{
+			name: "memberships change only on behalf of a user",
+			run: func(t *testing.T, service LedgerService, store *storage.InMemoryLedgerStorage) {
+				owner := actor.WithUserID(context.Background(), "owner")
+				account := mustCreateAccount(t, service, owner)
+				_, token, err := service.CreateInvitation(owner, account.ID, model.MemberRoleEditor)
+				if err != nil {
+					t.Fatalf("create invitation: %v", err)
+				}
+
+				unvalidated := NewLedgerService(repository.NewInMemoryLedgerRepository(store), nil, nil, nil, nil)
+				for _, ctx := range []context.Context{context.Background(), actor.WithInternal(context.Background())} {
+					if _, err := unvalidated.CreateAccount(ctx, "Family"); !errors.Is(err, ErrAccessDenied) {
+						t.Fatalf("expected accounts to need an owner, got %v", err)
+					}
+					if _, err := unvalidated.AcceptInvitation(ctx, token); !errors.Is(err, ErrAccessDenied) {
+						t.Fatalf("expected invitations to need a user, got %v", err)
+					}
+					if _, err := service.UpdateMember(ctx, account.ID, "owner", model.MemberRoleEditor); !errors.Is(err, ErrAccessDenied) {
+						t.Fatalf("expected role changes to need an owner, got %v", err)
+					}
+					if err := service.RemoveMember(ctx, account.ID, "owner"); !errors.Is(err, ErrAccessDenied) {
+						t.Fatalf("expected removals to need a user, got %v", err)
+					}
+					if _, _, err := service.CreateInvitation(ctx, account.ID, model.MemberRoleViewer); !errors.Is(err, ErrAccessDenied) {
+						t.Fatalf("expected invitations to need an owner, got %v", err)
+					}
+				}
+				if _, err := service.AcceptInvitation(actor.WithUserID(context.Background(), "editor"), token); err != nil {
+					t.Fatalf("expected the invitation to stay valid, got %v", err)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := storage.NewInMemoryLedgerStorage()
+			repo := repository.NewInMemoryLedgerRepository(store)
+			tt.run(t, NewValidationService(NewLedgerService(repo, nil, nil, nil, nil)), store)
+		})
+	}
+}
+
+func mustCreateAccount(t *testing.T, service LedgerService, ctx context.Context) model.Account {
+	t.Helper()
+	account, err := service.CreateAccount(ctx, "Family")
+	if err != nil {
+		t.Fatalf("create account: %v", err)
+	}
+	return account
+}
+
+// joinAccount invites the user with the role and accepts the invitation on
+// their behalf. It returns the context of the new member.
+func joinAccount(t *testing.T, service LedgerService, owner context.Context, accountID, userID, role string) context.Context {
+	t.Helper()
+	_, token, err := service.CreateInvitation(owner, accountID, role)
+	if err != nil {
+		t.Fatalf("create invitation: %v", err)
+	}
+	member := actor.WithUserID(context.Background(), userID)
+	if _, err := service.AcceptInvitation(member, token); err != nil {
+		t.Fatalf("accept invitation: %v", err)
+	}
+	return member
+}
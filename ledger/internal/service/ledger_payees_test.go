@@ -0,0 +1,228 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/model"
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/repository"
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/storage"
+)
+
+func TestPayees(t *testing.T) {
+	const accountID = "account-payees"
+	month := time.Date(2024, time.November, 1, 0, 0, 0, 0, time.UTC)
+
+	setup := func(t *testing.T, service LedgerService) {
+		t.Helper()
+		for _, name := range []string{"Food", "Transport", "Bank"} {
+			if _, err := service.CreateBudget(context.Background(), model.Budget{
+				AccountID: accountID,
+				Name:      name,
+				Amount:    5000,
+				Currency:  "USD",
+				Period:    "monthly",
+				Month:     month,
+			}); err != nil {
+				t.Fatalf("create budget %s: %v", name, err)
+			}
+		}
+	}
+	createExpense := func(t *testing.T, service LedgerService, amount float64, category, description string) model.Transaction {
+		t.Helper()
+		tx, err := service.CreateTransaction(context.Background(), model.Transaction{AccountID: accountID, Amount: amount, Currency: "USD", Category: category, Description: description, OccurredAt: month})
+		if err != nil {
+			t.Fatalf("create transaction %q: %v", description, err)
+		}
+		return tx
+	}
+
+	tests := []struct {
+		name string
+		run  func(t *testing.T, service LedgerService)
+	}{
+		{
+			name: "descriptions link transactions to normalized payees",
+			run: func(t *testing.T, service LedgerService) {
+				ctx := context.Background()
+				setup(t, service)
+				first := createExpense(t, service, -30, "Food", "LENTA-123 SPB")
+				second := createExpense(t, service, -20, "Food", "Lenta #45")
+				if first.PayeeID == "" || first.PayeeID != second.PayeeID {
+					t.Fatalf("expected both transactions to link to one payee, got %q and %q", first.PayeeID, second.PayeeID)
+				}
+				if none := createExpense(t, service, -5, "Food", "#12 / 34"); none.PayeeID != "" {
+					t.Fatalf("expected a description without a name to link no payee, got %q", none.PayeeID)
+				}
+
+				payees, err := service.ListPayees(ctx, accountID)
+				if err != nil {
+					t.Fatalf("list payees: %v", err)
+				}
+				if len(payees) != 1 || payees[0].Name != "Lenta" || payees[0].ID != first.PayeeID {
+					t.Fatalf("expected the payee Lenta to be created, got %+v", payees)
+				}
+
+				content := "account_id,amount,currency,category,description,occurred_at,splits,tags\n" +
+					accountID + ",-15,USD,Food,Lenta 7 Moscow,2024-11-05T12:00:00Z,,\n"
+				if _, err := service.ImportTransactionsCSV(ctx, accountID, []byte(content), true); err != nil {
+					t.Fatalf("import: %v", err)
+				}
+				results, err := service.BatchCreateTransactions(ctx, accountID, []model.Transaction{
+					{Amount: -10, Currency: "USD", Category: "Food", Description: "lenta_99", OccurredAt: month},
+					{Amount: -10, Currency: "USD", Category: "Food", Description: "Lenta", PayeeID: "missing", OccurredAt: month},
+				}, false)
+				if err != nil {
+					t.Fatalf("batch create: %v", err)
+				}
+				if results[0].Transaction.PayeeID != first.PayeeID {
+					t.Fatalf("expected the batch item to link to Lenta, got %q", results[0].Transaction.PayeeID)
+				}
+				if !IsValidationError(results[1].Err) {
+					t.Fatalf("expected an unknown payee to be rejected, got %v", results[1].Err)
+				}
+				refund, err := service.RefundTransaction(ctx, model.Refund{AccountID: accountID, TransactionID: first.ID, Amount: 10, Description: "refund", OccurredAt: month})
+				if err != nil {
+					t.Fatalf("refund: %v", err)
+				}
+				if refund.PayeeID != first.PayeeID {
+					t.Fatalf("expected the refund to carry the payee of the expense, got %q", refund.PayeeID)
+				}
+
+				linked, err := service.SearchTransactions(ctx, model.TransactionFilter{AccountID: accountID, PayeeID: first.PayeeID})
+				if err != nil {
+					t.Fatalf("search: %v", err)
+				}
+				if len(linked) != 5 {
+					t.Fatalf("expected the created, imported, batch and refund transactions of Lenta, got %+v", linked)
+				}
+				if payees, _ := service.ListPayees(ctx, accountID); len(payees) != 1 {
+					t.Fatalf("expected no further payees, got %+v", payees)
+				}
+			},
+		},
+		{
+			name: "aliases, default categories and deletion",
+			run: func(t *testing.T, service LedgerService) {
+				ctx := context.Background()
+				setup(t, service)
+				taxi, err := service.CreatePayee(ctx, model.Payee{AccountID: accountID, Name: "Yandex Taxi", Aliases: []string{" YANDEX*GO ", "Yandex.Go"}, DefaultCategory: "Transport"})
+				if err != nil {
+					t.Fatalf("create payee: %v", err)
+				}
+				if len(taxi.Aliases) != 1 || taxi.Aliases[0] != "YANDEX*GO" {
+					t.Fatalf("expected trimmed aliases without duplicate keys, got %q", taxi.Aliases)
+				}
+				ride := createExpense(t, service, -12, "", "YANDEX.GO 4521 MSK")
+				if ride.PayeeID != taxi.ID || ride.Category != "Transport" {
+					t.Fatalf("expected the alias to link the payee and its default category, got %+v", ride)
+				}
+				if _, err := service.CreateRule(ctx, model.CategoryRule{AccountID: accountID, Name: "airport", DescriptionContains: "airport", Category: "Food", Enabled: true}); err != nil {
+					t.Fatalf("create rule: %v", err)
+				}
+				airport := createExpense(t, service, -40, "", "Yandex Taxi airport")
+				if airport.Category != "Food" {
+					t.Fatalf("expected a matching rule to win over the default category, got %q", airport.Category)
+				}
+				if airport.PayeeID == taxi.ID {
+					t.Fatalf("expected a description with more words to name another payee")
+				}
+
+				if _, err := service.CreatePayee(ctx, model.Payee{AccountID: accountID, Name: "yandex-go 12"}); !IsPayeeExists(err) {
+					t.Fatalf("expected a payee matching an alias to be rejected, got %v", err)
+				}
+				taxi.Aliases = nil
+				if _, err := service.UpdatePayee(ctx, taxi); err != nil {
+					t.Fatalf("update payee: %v", err)
+				}
+				if _, err := service.CreatePayee(ctx, model.Payee{AccountID: accountID, Name: "Yandex Go"}); err != nil {
+					t.Fatalf("expected the removed alias to be free, got %v", err)
+				}
+
+				if err := service.DeletePayee(ctx, accountID, taxi.ID); err != nil {
+					t.Fatalf("delete payee: %v", err)
+				}
+				unlinked, err := service.GetTransaction(ctx, ride.ID)
+				if err != nil {
+					t.Fatalf("get transaction: %v", err)
+				}
+				if unlinked.PayeeID != "" || unlinked.Category != "Transport" {
+					t.Fatalf("expected the transaction to lose the payee and keep its category, got %+v", unlinked)
+				}
+				history, err := service.ListHistory(ctx, accountID, model.AuditEntityPayee, taxi.ID)
+				if err != nil {
+					t.Fatalf("history: %v", err)
+				}
+				if len(history) != 3 {
+					t.Fatalf("expected create, update and delete in the history, got %+v", history)
+				}
+			},
+		},
+		{
+			name: "reports rank payees by expense",
+			run: func(t *testing.T, service LedgerService) {
+				ctx := context.Background()
+				setup(t, service)
+				big := createExpense(t, service, -100, "Food", "Perekrestok 12")
+				createExpense(t, service, -30, "Food", "Perekrestok 7 SPB")
+				createExpense(t, service, -60, "Transport", "Metro")
+				createExpense(t, service, -40, "Food", "Cafe")
+				if _, err := service.CreateTransaction(ctx, model.Transaction{AccountID: accountID, Amount: 500, Currency: "USD", Category: "Salary", Description: "Employer", OccurredAt: month}); err != nil {
+					t.Fatalf("create income: %v", err)
+				}
+				if _, err := service.RefundTransaction(ctx, model.Refund{AccountID: accountID, TransactionID: big.ID, Amount: 80, OccurredAt: month}); err != nil {
+					t.Fatalf("refund: %v", err)
+				}
+
+				summary, err := service.GetReportSummary(ctx, accountID, month, month.AddDate(0, 1, 0))
+				if err != nil {
+					t.Fatalf("summary: %v", err)
+				}
+				want := []model.ReportPayee{
+					{PayeeID: summary.Payees[0].PayeeID, Name: "Metro", TotalExpense: 60, Transactions: 1},
+					{PayeeID: big.PayeeID, Name: "Perekrestok", TotalExpense: 50, Transactions: 2},
+				}
+				if len(summary.Payees) != 3 || summary.Payees[0] != want[0] || summary.Payees[1] != want[1] || summary.Payees[2].Name != "Cafe" {
+					t.Fatalf("expected payees by net expense without income, got %+v", summary.Payees)
+				}
+
+				report, err := service.CreateReport(ctx, model.Report{AccountID: accountID, Name: "November", Period: "2024-11"})
+				if err != nil {
+					t.Fatalf("create report: %v", err)
+				}
+				if len(report.Payees) != 3 || report.Payees[1].PayeeID != big.PayeeID {
+					t.Fatalf("expected the report to rank payees, got %+v", report.Payees)
+				}
+			},
+		},
+		{
+			name: "invalid payees are rejected",
+			run: func(t *testing.T, service LedgerService) {
+				ctx := context.Background()
+				invalid := []model.Payee{
+					{AccountID: accountID, Name: " "},
+					{AccountID: accountID, Name: "#123"},
+					{AccountID: accountID, Name: "Shop", Aliases: []string{""}},
+					{AccountID: accountID, Name: "Shop", DefaultCategory: model.CategorySplit},
+					{Name: "Shop"},
+				}
+				for _, payee := range invalid {
+					if _, err := service.CreatePayee(ctx, payee); !IsValidationError(err) {
+						t.Fatalf("expected payee %+v to be rejected, got %v", payee, err)
+					}
+				}
+				if err := service.DeletePayee(ctx, accountID, "missing"); !IsNotFound(err) {
+					t.Fatalf("expected a missing payee to be reported, got %v", err)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := storage.NewInMemoryLedgerStorage()
+			tt.run(t, NewValidationService(NewLedgerService(repository.NewInMemoryLedgerRepository(store), nil, nil, nil, nil)))
+		})
+	}
+}
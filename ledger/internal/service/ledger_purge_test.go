@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/actor"
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/model"
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/repository"
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/storage"
+)
+
+func TestPurgeAccountRemovesOnlyAccountData(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewInMemoryLedgerStorage()
+	repo := repository.NewInMemoryLedgerRepository(store)
+	service := NewValidationService(NewLedgerService(repo, nil, nil, nil, nil))
+
+	month := time.Date(2024, time.September, 1, 0, 0, 0, 0, time.UTC)
+	for _, accountID := range []string{"account-purged", "account-kept"} {
+		if _, err := service.CreateBudget(ctx, model.Budget{
+			AccountID: accountID,
+			Name:      "Food",
+			Amount:    100,
+			Currency:  "USD",
+			Period:    "monthly",
+			Month:     month,
+		}); err != nil {
+			t.Fatalf("create budget: %v", err)
+		}
+		tx, err := service.CreateTransaction(ctx, model.Transaction{
+			AccountID:  accountID,
+			Amount:     -20,
+			Currency:   "USD",
+			Category:   "Food",
+			OccurredAt: month.AddDate(0, 0, 1),
+		})
+		if err != nil {
+			t.Fatalf("create transaction: %v", err)
+		}
+		if err := service.DeleteTransaction(ctx, tx.ID); err != nil {
+			t.Fatalf("delete transaction: %v", err)
+		}
+		if _, err := service.CreateReport(ctx, model.Report{AccountID: accountID, Name: "September", Period: "2024-09"}); err != nil {
+			t.Fatalf("create report: %v", err)
+		}
+	}
+
+	owner := actor.WithUserID(ctx, "account-purged")
+	if _, err := service.PurgeAccount(owner, "account-purged"); !IsAccessDenied(err) {
+		t.Fatalf("expected users not to purge accounts, got %v", err)
+	}
+	purged, err := service.PurgeAccount(actor.WithInternal(ctx), "account-purged")
+	if err != nil {
+		t.Fatalf("purge account: %v", err)
+	}
+	if purged == 0 {
+		t.Fatal("expected purged records to be reported")
+	}
+
+	if items := service.ListBudgets(ctx, "account-purged"); len(items) != 0 {
+		t.Fatalf("expected budgets to be purged, got %d", len(items))
+	}
+	if items := service.ListReports(ctx, "account-purged"); len(items) != 0 {
+		t.Fatalf("expected reports to be purged, got %d", len(items))
+	}
+	trash, err := service.ListTrash(ctx, "account-purged")
+	if err != nil {
+		t.Fatalf("list trash: %v", err)
+	}
+	if len(trash.Transactions) != 0 {
+		t.Fatalf("expected deleted transactions to be purged, got %d", len(trash.Transactions))
+	}
+	events, err := service.ListEvents(ctx, "account-purged", 0, 100)
+	if err != nil {
+		t.Fatalf("list events: %v", err)
+	}
+	if len(events) != 1 || events[0].Type != model.EventAccountPurged {
+		t.Fatalf("expected only the AccountPurged event to remain, got %+v", events)
+	}
+
+	if items := service.ListBudgets(ctx, "account-kept"); len(items) != 1 {
+		t.Fatalf("expected other account budgets to be kept, got %d", len(items))
+	}
+	if items := service.ListReports(ctx, "account-kept"); len(items) != 1 {
+		t.Fatalf("expected other account reports to be kept, got %d", len(items))
+	}
+
+	purged, err = service.PurgeAccount(actor.WithInternal(ctx), "account-purged")
+	if err != nil {
+		t.Fatalf("purge account again: %v", err)
+	}
+	if purged != 1 {
+		t.Fatalf("expected only the previous purge event to be removed, got %d", purged)
+	}
+
+	if _, err := service.PurgeAccount(ctx, ""); !IsValidationError(err) {
+		t.Fatalf("expected validation error for empty account id, got %v", err)
+	}
+}
@@ -0,0 +1,259 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/model"
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/repository"
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/storage"
+)
+
+func TestRefunds(t *testing.T) {
+	const accountID = "account-refunds"
+	month := time.Date(2024, time.December, 1, 0, 0, 0, 0, time.UTC)
+
+	setup := func(t *testing.T, service LedgerService, amount float64) model.Transaction {
+		t.Helper()
+		ctx := context.Background()
+		for _, name := range []string{"Food", "Home"} {
+			if _, err := service.CreateBudget(ctx, model.Budget{
+				AccountID: accountID,
+				Name:      name,
+				Amount:    100,
+				Currency:  "USD",
+				Period:    "monthly",
+				Month:     month,
+			}); err != nil {
+				t.Fatalf("create budget %s: %v", name, err)
+			}
+		}
+		expense, err := service.CreateTransaction(ctx, model.Transaction{AccountID: accountID, Amount: amount, Currency: "USD", Category: "Food", OccurredAt: month})
+		if err != nil {
+			t.Fatalf("create expense: %v", err)
+		}
+		return expense
+	}
+	refund := func(expense model.Transaction, amount float64) model.Refund {
+		return model.Refund{AccountID: accountID, TransactionID: expense.ID, Amount: amount, OccurredAt: month.AddDate(0, 0, 3)}
+	}
+
+	tests := []struct {
+		name string
+		run  func(t *testing.T, service LedgerService)
+	}{
+		{
+			name: "refund reduces the expense and frees the budget",
+			run: func(t *testing.T, service LedgerService) {
+				ctx := context.Background()
+				expense := setup(t, service, -80)
+
+				created, err := service.RefundTransaction(ctx, refund(expense, 30))
+				if err != nil {
+					t.Fatalf("refund: %v", err)
+				}
+				if created.RefundOf != expense.ID || created.Category != "Food" || created.Amount != 30 {
+					t.Fatalf("expected a refund of the expense, got %+v", created)
+				}
+
+				summary, err := service.GetReportSummary(ctx, accountID, month, month.AddDate(0, 1, -1))
+				if err != nil {
+					t.Fatalf("report summary: %v", err)
+				}
+				if summary.TotalIncome != 0 || summary.TotalExpense != 50 || len(summary.Categories) != 1 || summary.Categories[0].TotalExpense != 50 {
+					t.Fatalf("expected the refund to reduce the Food expense, got %+v", summary)
+				}
+				if _, err := service.CreateTransaction(ctx, model.Transaction{AccountID: accountID, Amount: -50, Currency: "USD", Category: "Food", OccurredAt: month}); err != nil {
+					t.Fatalf("expected the refund to free the budget: %v", err)
+				}
+
+				if err := service.VerifyJournal(ctx, accountID); err != nil {
+					t.Fatalf("verify journal: %v", err)
+				}
+				balance, err := service.GetTrialBalance(ctx, accountID)
+				if err != nil {
+					t.Fatalf("trial balance: %v", err)
+				}
+				for _, line := range balance.Lines {
+					if line.Account == "expenses:Food" && line.Debit != 100 {
+						t.Fatalf("expected Food expenses of 100 in the journal, got %+v", line)
+					}
+				}
+
+				refunds, err := service.ListRefunds(ctx, accountID, expense.ID)
+				if err != nil || len(refunds) != 1 || refunds[0].ID != created.ID {
+					t.Fatalf("expected the refund to be listed, got %+v, %v", refunds, err)
+				}
+			},
+		},
+		{
+			name: "refunds never exceed the expense",
+			run: func(t *testing.T, service LedgerService) {
+				ctx := context.Background()
+				expense := setup(t, service, -80)
+
+				if _, err := service.RefundTransaction(ctx, refund(expense, 60)); err != nil {
+					t.Fatalf("partial refund: %v", err)
+				}
+				if _, err := service.RefundTransaction(ctx, refund(expense, 30)); !IsRefundExceeded(err) {
+					t.Fatalf("expected refunds above the expense to be rejected, got %v", err)
+				}
+				if _, err := service.RefundTransaction(ctx, refund(expense, 20)); err != nil {
+					t.Fatalf("refund of the rest: %v", err)
+				}
+
+				income, err := service.CreateTransaction(ctx, model.Transaction{AccountID: accountID, Amount: 100, Currency: "USD", Category: "salary", OccurredAt: month})
+				if err != nil {
+					t.Fatalf("create income: %v", err)
+				}
+				if _, err := service.RefundTransaction(ctx, refund(income, 10)); !IsValidationError(err) {
+					t.Fatalf("expected income refunds to be rejected, got %v", err)
+				}
+
+				split, err := service.CreateTransaction(ctx, model.Transaction{AccountID: accountID, Amount: -15, Currency: "USD", OccurredAt: month, Splits: []model.TransactionSplit{
+					{Category: "Food", Amount: -5},
+					{Category: "Home", Amount: -10},
+				}})
+				if err != nil {
+					t.Fatalf("create split expense: %v", err)
+				}
+				if _, err := service.RefundTransaction(ctx, refund(split, 5)); !IsValidationError(err) {
+					t.Fatalf("expected the refunded part to be required, got %v", err)
+				}
+				partRefund := refund(split, 10)
+				partRefund.Category = "Food"
+				if _, err := service.RefundTransaction(ctx, partRefund); !IsRefundExceeded(err) {
+					t.Fatalf("expected refunds above the part to be rejected, got %v", err)
+				}
+				partRefund.Category = "Home"
+				if _, err := service.RefundTransaction(ctx, partRefund); err != nil {
+					t.Fatalf("refund of a part: %v", err)
+				}
+			},
+		},
+		{
+			name: "refunds stay linked to the expense",
+			run: func(t *testing.T, service LedgerService) {
+				ctx := context.Background()
+				expense := setup(t, service, -80)
+				created, err := service.RefundTransaction(ctx, refund(expense, 50))
+				if err != nil {
+					t.Fatalf("refund: %v", err)
+				}
+
+				if err := service.DeleteTransaction(ctx, expense.ID); !IsRefundLinked(err) {
+					t.Fatalf("expected an expense with refunds to stay, got %v", err)
+				}
+				created.Amount = 10
+				if _, err := service.UpdateTransaction(ctx, created); !IsRefundLinked(err) {
+					t.Fatalf("expected refunds to be immutable, got %v", err)
+				}
+				expense.Amount = -40
+				if _, err := service.UpdateTransaction(ctx, expense); !IsRefundExceeded(err) {
+					t.Fatalf("expected the expense to keep covering its refunds, got %v", err)
+				}
+
+				if err := service.DeleteTransaction(ctx, created.ID); err != nil {
+					t.Fatalf("delete refund: %v", err)
+				}
+				if err := service.DeleteTransaction(ctx, expense.ID); err != nil {
+					t.Fatalf("delete expense: %v", err)
+				}
+				if _, err := service.RestoreTransaction(ctx, accountID, created.ID); !IsRefundLinked(err) {
+					t.Fatalf("expected the refund to wait for its expense, got %v", err)
+				}
+				if _, err := service.RestoreTransaction(ctx, accountID, expense.ID); err != nil {
+					t.Fatalf("restore expense: %v", err)
+				}
+				if _, err := service.RestoreTransaction(ctx, accountID, created.ID); err != nil {
+					t.Fatalf("restore refund: %v", err)
+				}
+
+				results, err := service.BatchDeleteTransactions(ctx, accountID, []string{expense.ID, created.ID}, true)
+				if err != nil {
+					t.Fatalf("batch delete: %v", err)
+				}
+				for _, result := range results {
+					if result.Err != nil {
+						t.Fatalf("expected the expense to be deleted with its refunds, got %v", result.Err)
+					}
+				}
+				if err := service.VerifyJournal(ctx, accountID); err != nil {
+					t.Fatalf("verify journal: %v", err)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := storage.NewInMemoryLedgerStorage()
+			tt.run(t, NewValidationService(NewLedgerService(repository.NewInMemoryLedgerRepository(store), nil, nil, nil, nil)))
+		})
+	}
+}
+
+// slowCreateRepository delays creating transactions, so concurrent requests
+// overlap between reading the stored refunds and writing a new one.
+type slowCreateRepository struct {
+	repository.LedgerRepository
+}
+
+func (r slowCreateRepository) CreateTransaction(ctx context.Context, tx model.Transaction) (model.Transaction, error) {
+	time.Sleep(10 * time.Millisecond)
+	return r.LedgerRepository.CreateTransaction(ctx, tx)
+}
+
+func (r slowCreateRepository) RunInTx(ctx context.Context, fn func(repo repository.LedgerRepository) error) error {
+	return r.LedgerRepository.RunInTx(ctx, func(repo repository.LedgerRepository) error {
+		return fn(slowCreateRepository{repo})
+	})
+}
+
+func TestConcurrentRefundsNeverExceedTheExpense(t *testing.T) {
+	const accountID = "account-refunds"
+	ctx := context.Background()
+	store := storage.NewInMemoryLedgerStorage()
+	service := NewValidationService(NewLedgerService(slowCreateRepository{repository.NewInMemoryLedgerRepository(store)}, nil, nil, nil, nil))
+	month := time.Date(2024, time.December, 1, 0, 0, 0, 0, time.UTC)
+	if _, err := service.CreateBudget(ctx, model.Budget{AccountID: accountID, Name: "Food", Amount: 100, Currency: "USD", Period: "monthly", Month: month}); err != nil {
+		t.Fatalf("create budget: %v", err)
+	}
+	expense, err := service.CreateTransaction(ctx, model.Transaction{AccountID: accountID, Amount: -80, Currency: "USD", Category: "Food", OccurredAt: month})
+	if err != nil {
+		t.Fatalf("create expense: %v", err)
+	}
+
+	const attempts = 8
+	errs := make(chan error, attempts)
+	var wg sync.WaitGroup
+	for range attempts {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := service.RefundTransaction(ctx, model.Refund{AccountID: accountID, TransactionID: expense.ID, Amount: 30, OccurredAt: month.AddDate(0, 0, 3)})
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	created := 0
+	for err := range errs {
+		switch {
+		case err == nil:
+			created++
+		case !IsRefundExceeded(err):
+			t.Fatalf("expected the refund to be created or rejected, got %v", err)
+		}
+	}
+	refunds, err := service.ListRefunds(ctx, accountID, expense.ID)
+	if err != nil {
+		t.Fatalf("list refunds: %v", err)
+	}
+	if created != 2 || len(refunds) != 2 {
+		t.Fatalf("expected 2 refunds of 30 within the expense of 80, got %d created and %d stored", created, len(refunds))
+	}
+}
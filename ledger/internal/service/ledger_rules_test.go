@@ -0,0 +1,244 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/model"
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/repository"
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/storage"
+)
+
+func TestRules(t *testing.T) {
+	const accountID = "account-rules"
+	month := time.Date(2024, time.December, 1, 0, 0, 0, 0, time.UTC)
+	amount := func(value float64) *float64 { return &value }
+
+	setup := func(t *testing.T, service LedgerService) {
+		t.Helper()
+		for _, name := range []string{"Food", "Transport", "Other", "Coffee", "Bank"} {
+			if _, err := service.CreateBudget(context.Background(), model.Budget{
+				AccountID: accountID,
+				Name:      name,
+				Amount:    5000,
+				Currency:  "USD",
+				Period:    "monthly",
+				Month:     month,
+			}); err != nil {
+				t.Fatalf("create budget %s: %v", name, err)
+			}
+		}
+	}
+	createRule := func(t *testing.T, service LedgerService, rule model.CategoryRule) model.CategoryRule {
+		t.Helper()
+		rule.AccountID = accountID
+		created, err := service.CreateRule(context.Background(), rule)
+		if err != nil {
+			t.Fatalf("create rule %s: %v", rule.Name, err)
+		}
+		return created
+	}
+
+	tests := []struct {
+		name string
+		run  func(t *testing.T, service LedgerService)
+	}{
+		{
+			name: "rules categorize new transactions in priority order",
+			run: func(t *testing.T, service LedgerService) {
+				ctx := context.Background()
+				setup(t, service)
+				createRule(t, service, model.CategoryRule{Name: "disabled", Priority: 0, DescriptionContains: "taxi", Category: "Food"})
+				taxi := createRule(t, service, model.CategoryRule{Name: "taxi", Priority: 2, DescriptionContains: "yandex*taxi", Category: "Transport", Enabled: true})
+				large := createRule(t, service, model.CategoryRule{Name: "large", Priority: 1, DescriptionPattern: `(?i)^yandex`, MinAmount: amount(500), Type: model.RuleTypeExpense, Category: "Other", Enabled: true})
+
+				rules, err := service.ListRules(ctx, accountID)
+				if err != nil {
+					t.Fatalf("list rules: %v", err)
+				}
+				if len(rules) != 3 || rules[1].ID != large.ID || rules[2].ID != taxi.ID {
+					t.Fatalf("expected rules by priority, got %+v", rules)
+				}
+
+				small, err := service.CreateTransaction(ctx, model.Transaction{AccountID: accountID, Amount: -300, Currency: "USD", Description: "YANDEX*TAXI MOSCOW", OccurredAt: month})
+				if err != nil {
+					t.Fatalf("create transaction: %v", err)
+				}
+				if small.Category != "Transport" {
+					t.Fatalf("expected the taxi rule to apply, got %q", small.Category)
+				}
+				big, err := service.CreateTransaction(ctx, model.Transaction{AccountID: accountID, Amount: -700, Currency: "USD", Description: "Yandex*Taxi", OccurredAt: month})
+				if err != nil {
+					t.Fatalf("create transaction: %v", err)
+				}
+				if big.Category != "Other" {
+					t.Fatalf("expected the rule with the lower priority value to win, got %q", big.Category)
+				}
+				income, err := service.CreateTransaction(ctx, model.Transaction{AccountID: accountID, Amount: 900, Currency: "USD", Category: "Salary", Description: "Yandex payroll", OccurredAt: month})
+				if err != nil {
+					t.Fatalf("create income: %v", err)
+				}
+				if income.Category != "Salary" {
+					t.Fatalf("expected income to keep its category, got %q", income.Category)
+				}
+				chosen, err := service.CreateTransaction(ctx, model.Transaction{AccountID: accountID, Amount: -50, Currency: "USD", Category: "Bank", Description: "yandex*taxi fee", OccurredAt: month})
+				if err != nil {
+					t.Fatalf("create transaction: %v", err)
+				}
+				if chosen.Category != "Bank" {
+					t.Fatalf("expected the chosen category to be kept, got %q", chosen.Category)
+				}
+				if _, err := service.CreateTransaction(ctx, model.Transaction{AccountID: accountID, Amount: -50, Currency: "USD", Description: "bakery", OccurredAt: month}); !IsValidationError(err) {
+					t.Fatalf("expected a category to be required when no rule matches, got %v", err)
+				}
+
+				content := "account_id,amount,currency,category,description,occurred_at,splits,tags\n" +
+					accountID + ",-200,USD,5812,yandex*taxi,2024-12-05T12:00:00Z,,\n"
+				if _, err := service.ImportTransactionsCSV(ctx, accountID, []byte(content), true); err != nil {
+					t.Fatalf("import: %v", err)
+				}
+				results, err := service.BatchCreateTransactions(ctx, accountID, []model.Transaction{
+					{Amount: -100, Currency: "USD", Description: "yandex*taxi airport", OccurredAt: month},
+					{Amount: -100, Currency: "USD", Description: "yandex*taxi split", OccurredAt: month, Splits: []model.TransactionSplit{
+						{Category: "Food", Amount: -60},
+						{Category: "Bank", Amount: -40},
+					}},
+				}, true)
+				if err != nil {
+					t.Fatalf("batch create: %v", err)
+				}
+				if results[0].Transaction.Category != "Transport" {
+					t.Fatalf("expected the batch item to be categorized, got %q", results[0].Transaction.Category)
+				}
+				if results[1].Transaction.Category != model.CategorySplit {
+					t.Fatalf("expected the split transaction to be left alone, got %q", results[1].Transaction.Category)
+				}
+
+				imported, err := service.SearchTransactions(ctx, model.TransactionFilter{AccountID: accountID, Query: "yandex*taxi", Category: "Transport"})
+				if err != nil {
+					t.Fatalf("search: %v", err)
+				}
+				if len(imported) != 3 {
+					t.Fatalf("expected the created, imported and batch transactions in Transport, got %+v", imported)
+				}
+			},
+		},
+		{
+			name: "preview and apply rules to history",
+			run: func(t *testing.T, service LedgerService) {
+				ctx := context.Background()
+				setup(t, service)
+				first, err := service.CreateTransaction(ctx, model.Transaction{AccountID: accountID, Amount: -5, Currency: "USD", Category: "Food", Description: "Starbucks #12", OccurredAt: month})
+				if err != nil {
+					t.Fatalf("create transaction: %v", err)
+				}
+				second, err := service.CreateTransaction(ctx, model.Transaction{AccountID: accountID, Amount: -7, Currency: "USD", Category: "Bank", Description: "STARBUCKS", OccurredAt: month})
+				if err != nil {
+					t.Fatalf("create transaction: %v", err)
+				}
+				refunded, err := service.CreateTransaction(ctx, model.Transaction{AccountID: accountID, Amount: -20, Currency: "USD", Category: "Food", Description: "starbucks beans", OccurredAt: month})
+				if err != nil {
+					t.Fatalf("create transaction: %v", err)
+				}
+				if _, err := service.RefundTransaction(ctx, model.Refund{AccountID: accountID, TransactionID: refunded.ID, Amount: 5, OccurredAt: month}); err != nil {
+					t.Fatalf("refund: %v", err)
+				}
+
+				rule := model.CategoryRule{AccountID: accountID, DescriptionContains: "starbucks", Type: model.RuleTypeExpense, Category: "Coffee"}
+				preview, err := service.PreviewRule(ctx, rule)
+				if err != nil {
+					t.Fatalf("preview: %v", err)
+				}
+				if len(preview) != 2 {
+					t.Fatalf("expected the preview to skip the refunded expense, got %+v", preview)
+				}
+
+				rule.Name = "coffee"
+				rule.Enabled = true
+				createRule(t, service, rule)
+				planned, err := service.ApplyRules(ctx, accountID, true)
+				if err != nil {
+					t.Fatalf("dry run: %v", err)
+				}
+				if len(planned) != 2 || planned[0].Category != "Coffee" {
+					t.Fatalf("expected two planned changes, got %+v", planned)
+				}
+				unchanged, err := service.GetTransaction(ctx, first.ID)
+				if err != nil {
+					t.Fatalf("get transaction: %v", err)
+				}
+				if unchanged.Category != "Food" {
+					t.Fatalf("expected the dry run to change nothing, got %q", unchanged.Category)
+				}
+
+				applied, err := service.ApplyRules(ctx, accountID, false)
+				if err != nil {
+					t.Fatalf("apply: %v", err)
+				}
+				if len(applied) != 2 {
+					t.Fatalf("expected two changes, got %+v", applied)
+				}
+				for _, id := range []string{first.ID, second.ID} {
+					tx, err := service.GetTransaction(ctx, id)
+					if err != nil {
+						t.Fatalf("get transaction: %v", err)
+					}
+					if tx.Category != "Coffee" {
+						t.Fatalf("expected %s to be recategorized, got %q", id, tx.Category)
+					}
+				}
+				history, err := service.ListHistory(ctx, accountID, model.AuditEntityTransaction, first.ID)
+				if err != nil {
+					t.Fatalf("history: %v", err)
+				}
+				if last := history[len(history)-1]; last.Operation != model.AuditOperationUpdate {
+					t.Fatalf("expected the change to be audited, got %+v", last)
+				}
+				if err := service.VerifyJournal(ctx, accountID); err != nil {
+					t.Fatalf("verify journal: %v", err)
+				}
+				again, err := service.ApplyRules(ctx, accountID, false)
+				if err != nil {
+					t.Fatalf("apply again: %v", err)
+				}
+				if len(again) != 0 {
+					t.Fatalf("expected nothing left to change, got %+v", again)
+				}
+			},
+		},
+		{
+			name: "invalid rules are rejected",
+			run: func(t *testing.T, service LedgerService) {
+				ctx := context.Background()
+				invalid := []model.CategoryRule{
+					{AccountID: accountID, Name: "no conditions", Category: "Food"},
+					{AccountID: accountID, Name: "bad pattern", DescriptionPattern: "(", Category: "Food"},
+					{AccountID: accountID, Name: "no category", DescriptionContains: "coffee"},
+					{AccountID: accountID, Name: "split", DescriptionContains: "coffee", Category: model.CategorySplit},
+					{AccountID: accountID, Name: "bounds", MinAmount: amount(10), MaxAmount: amount(5), Category: "Food"},
+					{AccountID: accountID, Name: "type", Type: "transfer", Category: "Food"},
+					{AccountID: accountID, Name: " ", DescriptionContains: "coffee", Category: "Food"},
+				}
+				for _, rule := range invalid {
+					if _, err := service.CreateRule(ctx, rule); !IsValidationError(err) {
+						t.Fatalf("expected rule %q to be rejected, got %v", rule.Name, err)
+					}
+				}
+				if _, err := service.PreviewRule(ctx, model.CategoryRule{AccountID: accountID, DescriptionPattern: "[", Category: "Food"}); !IsValidationError(err) {
+					t.Fatalf("expected the preview of an invalid rule to be rejected, got %v", err)
+				}
+				if err := service.DeleteRule(ctx, accountID, "missing"); !IsNotFound(err) {
+					t.Fatalf("expected a missing rule to be reported, got %v", err)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := storage.NewInMemoryLedgerStorage()
+			tt.run(t, NewValidationService(NewLedgerService(repository.NewInMemoryLedgerRepository(store), nil, nil, nil, nil)))
+		})
+	}
+}
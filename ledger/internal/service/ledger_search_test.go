@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/model"
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/repository"
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/storage"
+)
+
+func TestSearchTransactionsAppliesFiltersAndSort(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewInMemoryLedgerStorage()
+	repo := repository.NewInMemoryLedgerRepository(store)
+	svc := NewValidationService(NewLedgerService(repo, nil, nil, nil, nil))
+
+	accountID := "account-search"
+	q3 := time.Date(2024, time.July, 1, 0, 0, 0, 0, time.UTC)
+	seed := []model.Transaction{
+		{ID: "t1", AccountID: accountID, Amount: -6000, Currency: "RUB", Category: "Groceries", Description: "LENTA hypermarket", OccurredAt: q3.AddDate(0, 0, 5)},
+		{ID: "t2", AccountID: accountID, Amount: -7500, Currency: "RUB", Category: "Groceries", Description: "Lenta online", OccurredAt: q3.AddDate(0, 2, 1)},
+		{ID: "t3", AccountID: accountID, Amount: -1200, Currency: "RUB", Category: "Groceries", Description: "Lenta", OccurredAt: q3.AddDate(0, 1, 0)},
+		{ID: "t4", AccountID: accountID, Amount: -9000, Currency: "RUB", Category: "Groceries", Description: "Perekrestok", OccurredAt: q3.AddDate(0, 1, 0)},
+		{ID: "t5", AccountID: accountID, Amount: -8000, Currency: "RUB", Category: "Groceries", Description: "Lenta", OccurredAt: q3.AddDate(0, -1, 0)},
+		{ID: "t6", AccountID: "other", Amount: -8000, Currency: "RUB", Category: "Groceries", Description: "Lenta", OccurredAt: q3.AddDate(0, 1, 0)},
+	}
+	for _, tx := range seed {
+		if _, err := repo.CreateTransaction(ctx, tx); err != nil {
+			t.Fatalf("seed transaction: %v", err)
+		}
+	}
+
+	minAmount := 5000.0
+	items, err := svc.SearchTransactions(ctx, model.TransactionFilter{
+		AccountID: accountID,
+		From:      q3,
+		To:        q3.AddDate(0, 3, 0).Add(-time.Nanosecond),
+		Category:  "Groceries",
+		Currency:  "RUB",
+		MinAmount: &minAmount,
+		Query:     "lenta",
+		Sort:      model.SortAmountDesc,
+	})
+	if err != nil {
+		t.Fatalf("search transactions: %v", err)
+	}
+	if len(items) != 2 || items[0].ID != "t2" || items[1].ID != "t1" {
+		t.Fatalf("expected [t2 t1], got %+v", items)
+	}
+
+	items, err = svc.SearchTransactions(ctx, model.TransactionFilter{AccountID: accountID, Sort: model.SortOccurredAtAsc, Limit: 2, Offset: 1})
+	if err != nil {
+		t.Fatalf("search transactions: %v", err)
+	}
+	if len(items) != 2 || items[0].ID != "t1" {
+		t.Fatalf("expected page starting with t1, got %+v", items)
+	}
+
+	if _, err := svc.SearchTransactions(ctx, model.TransactionFilter{AccountID: accountID, Sort: "description"}); !IsValidationError(err) {
+		t.Fatalf("expected validation error for unsupported sort, got %v", err)
+	}
+}
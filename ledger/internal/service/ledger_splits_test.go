@@ -0,0 +1,184 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/model"
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/repository"
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/storage"
+)
+
+func TestSplitTransactions(t *testing.T) {
+	const accountID = "account-splits"
+	month := time.Date(2024, time.November, 1, 0, 0, 0, 0, time.UTC)
+
+	createBudgets := func(t *testing.T, service LedgerService, amounts map[string]float64) {
+		t.Helper()
+		for name, amount := range amounts {
+			if _, err := service.CreateBudget(context.Background(), model.Budget{
+				AccountID: accountID,
+				Name:      name,
+				Amount:    amount,
+				Currency:  "USD",
+				Period:    "monthly",
+				Month:     month,
+			}); err != nil {
+				t.Fatalf("create budget %s: %v", name, err)
+			}
+		}
+	}
+	split := func(amount float64, parts ...model.TransactionSplit) model.Transaction {
+		return model.Transaction{AccountID: accountID, Amount: amount, Currency: "USD", OccurredAt: month, Splits: parts}
+	}
+
+	tests := []struct {
+		name string
+		run  func(t *testing.T, service LedgerService)
+	}{
+		{
+			name: "validation rejects inconsistent parts",
+			run: func(t *testing.T, service LedgerService) {
+				ctx := context.Background()
+				invalid := map[string]model.Transaction{
+					"single part":      split(-50, model.TransactionSplit{Category: "Food", Amount: -50}),
+					"wrong sum":        split(-50, model.TransactionSplit{Category: "Food", Amount: -30}, model.TransactionSplit{Category: "Home", Amount: -30}),
+					"wrong sign":       split(-50, model.TransactionSplit{Category: "Food", Amount: -60}, model.TransactionSplit{Category: "Home", Amount: 10}),
+					"repeated part":    split(-50, model.TransactionSplit{Category: "Food", Amount: -20}, model.TransactionSplit{Category: "Food", Amount: -30}),
+					"no category":      split(-50, model.TransactionSplit{Category: "Food", Amount: -20}, model.TransactionSplit{Amount: -30}),
+					"reserved plain":   {AccountID: accountID, Amount: 10, Currency: "USD", Category: model.CategorySplit},
+					"missing category": {AccountID: accountID, Amount: 10, Currency: "USD"},
+				}
+				for name, tx := range invalid {
+					if _, err := service.CreateTransaction(ctx, tx); !IsValidationError(err) {
+						t.Fatalf("%s: expected a validation error, got %v", name, err)
+					}
+				}
+			},
+		},
+		{
+			name: "each part is checked against its own budget",
+			run: func(t *testing.T, service LedgerService) {
+				ctx := context.Background()
+				createBudgets(t, service, map[string]float64{"Food": 100, "Home": 50})
+
+				created, err := service.CreateTransaction(ctx, split(-120,
+					model.TransactionSplit{Category: "Food", Amount: -80},
+					model.TransactionSplit{Category: "Home", Amount: -40},
+				))
+				if err != nil {
+					t.Fatalf("create split transaction: %v", err)
+				}
+				if created.Category != model.CategorySplit || len(created.Splits) != 2 {
+					t.Fatalf("expected a split transaction, got %+v", created)
+				}
+
+				if _, err := service.CreateTransaction(ctx, model.Transaction{AccountID: accountID, Amount: -20, Currency: "USD", Category: "Home", OccurredAt: month}); !IsBudgetExceeded(err) {
+					t.Fatalf("expected the Home part to count towards its budget, got %v", err)
+				}
+				if _, err := service.CreateTransaction(ctx, split(-30,
+					model.TransactionSplit{Category: "Food", Amount: -10},
+					model.TransactionSplit{Category: "Travel", Amount: -20},
+				)); !IsBudgetMissing(err) {
+					t.Fatalf("expected a part without a budget to be rejected, got %v", err)
+				}
+				if _, err := service.CreateTransaction(ctx, model.Transaction{AccountID: accountID, Amount: -20, Currency: "USD", Category: "Food", OccurredAt: month}); err != nil {
+					t.Fatalf("expected the rest of the Food budget to be available: %v", err)
+				}
+			},
+		},
+		{
+			name: "reports and the journal attribute each part",
+			run: func(t *testing.T, service LedgerService) {
+				ctx := context.Background()
+				createBudgets(t, service, map[string]float64{"Food": 100, "Home": 50})
+				created, err := service.CreateTransaction(ctx, split(-120,
+					model.TransactionSplit{Category: "Food", Amount: -80},
+					model.TransactionSplit{Category: "Home", Amount: -40},
+				))
+				if err != nil {
+					t.Fatalf("create split transaction: %v", err)
+				}
+
+				summary, err := service.GetReportSummary(ctx, accountID, month, month.AddDate(0, 1, -1))
+				if err != nil {
+					t.Fatalf("report summary: %v", err)
+				}
+				if summary.TotalExpense != 120 || len(summary.Categories) != 2 ||
+					summary.Categories[0].Category != "Food" || summary.Categories[0].TotalExpense != 80 ||
+					summary.Categories[1].Category != "Home" || summary.Categories[1].TotalExpense != 40 {
+					t.Fatalf("expected the parts in their categories, got %+v", summary)
+				}
+
+				entries, err := service.ListJournalEntries(ctx, accountID)
+				if err != nil {
+					t.Fatalf("list journal entries: %v", err)
+				}
+				if len(entries) != 1 || len(entries[0].Postings) != 3 ||
+					entries[0].Postings[1].Account != "expenses:Food" || entries[0].Postings[2].Account != "expenses:Home" {
+					t.Fatalf("expected a posting per part, got %+v", entries)
+				}
+
+				created.Splits = nil
+				created.Category = "Food"
+				created.Amount = -90
+				if _, err := service.UpdateTransaction(ctx, created); err != nil {
+					t.Fatalf("merge the parts: %v", err)
+				}
+				if err := service.VerifyJournal(ctx, accountID); err != nil {
+					t.Fatalf("verify journal: %v", err)
+				}
+				found, err := service.SearchTransactions(ctx, model.TransactionFilter{AccountID: accountID, Category: "Home"})
+				if err != nil || len(found) != 0 {
+					t.Fatalf("expected no Home transactions after the merge, got %+v, %v", found, err)
+				}
+			},
+		},
+		{
+			name: "csv export and import keep the parts",
+			run: func(t *testing.T, service LedgerService) {
+				ctx := context.Background()
+				createBudgets(t, service, map[string]float64{"Food": 200, "Home": 100})
+				if _, err := service.CreateTransaction(ctx, split(-120,
+					model.TransactionSplit{Category: "Food", Amount: -80.5},
+					model.TransactionSplit{Category: "Home", Amount: -39.5},
+				)); err != nil {
+					t.Fatalf("create split transaction: %v", err)
+				}
+
+				exported, err := service.ExportTransactionsCSV(ctx, accountID)
+				if err != nil {
+					t.Fatalf("export: %v", err)
+				}
+				if !strings.Contains(string(exported), "Food:-80.5;Home:-39.5") {
+					t.Fatalf("expected the parts in the export, got %s", exported)
+				}
+				if _, err := service.ImportTransactionsCSV(ctx, accountID, exported, true); err != nil {
+					t.Fatalf("import: %v", err)
+				}
+
+				found, err := service.SearchTransactions(ctx, model.TransactionFilter{AccountID: accountID, Category: "Home"})
+				if err != nil {
+					t.Fatalf("search: %v", err)
+				}
+				if len(found) != 2 {
+					t.Fatalf("expected both split transactions to match their Home part, got %+v", found)
+				}
+				for _, tx := range found {
+					if len(tx.Splits) != 2 || tx.Splits[0] != (model.TransactionSplit{Category: "Food", Amount: -80.5}) {
+						t.Fatalf("expected the parts to survive the round trip, got %+v", tx)
+					}
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := storage.NewInMemoryLedgerStorage()
+			tt.run(t, NewValidationService(NewLedgerService(repository.NewInMemoryLedgerRepository(store), nil, nil, nil, nil)))
+		})
+	}
+}
@@ -0,0 +1,189 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/model"
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/repository"
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/storage"
+)
+
+func TestTags(t *testing.T) {
+	const accountID = "account-tags"
+	month := time.Date(2024, time.November, 1, 0, 0, 0, 0, time.UTC)
+
+	setup := func(t *testing.T, service LedgerService) {
+		t.Helper()
+		for _, name := range []string{"Food", "Travel"} {
+			if _, err := service.CreateBudget(context.Background(), model.Budget{
+				AccountID: accountID,
+				Name:      name,
+				Amount:    1000,
+				Currency:  "USD",
+				Period:    "monthly",
+				Month:     month,
+			}); err != nil {
+				t.Fatalf("create budget %s: %v", name, err)
+			}
+		}
+	}
+	tagNames := func(t *testing.T, service LedgerService) []string {
+		t.Helper()
+		tags, err := service.ListTags(context.Background(), accountID)
+		if err != nil {
+			t.Fatalf("list tags: %v", err)
+		}
+		names := make([]string, 0, len(tags))
+		for _, tag := range tags {
+			names = append(names, tag.Name)
+		}
+		return names
+	}
+
+	tests := []struct {
+		name string
+		run  func(t *testing.T, service LedgerService)
+	}{
+		{
+			name: "transactions create their tags and are found by tag",
+			run: func(t *testing.T, service LedgerService) {
+				ctx := context.Background()
+				setup(t, service)
+
+				created, err := service.CreateTransaction(ctx, model.Transaction{AccountID: accountID, Amount: -40, Currency: "USD", Category: "Food", OccurredAt: month, Tags: []string{" vacation ", "italy", "vacation"}})
+				if err != nil {
+					t.Fatalf("create transaction: %v", err)
+				}
+				if !slices.Equal(created.Tags, []string{"italy", "vacation"}) {
+					t.Fatalf("expected trimmed, sorted and unique tags, got %v", created.Tags)
+				}
+				if _, err := service.CreateTransaction(ctx, model.Transaction{AccountID: accountID, Amount: -10, Currency: "USD", Category: "Food", OccurredAt: month}); err != nil {
+					t.Fatalf("create untagged transaction: %v", err)
+				}
+				if names := tagNames(t, service); !slices.Equal(names, []string{"italy", "vacation"}) {
+					t.Fatalf("expected the tags to be created, got %v", names)
+				}
+
+				found, err := service.SearchTransactions(ctx, model.TransactionFilter{AccountID: accountID, Tag: "vacation"})
+				if err != nil {
+					t.Fatalf("search by tag: %v", err)
+				}
+				if len(found) != 1 || found[0].ID != created.ID {
+					t.Fatalf("expected the tagged transaction, got %+v", found)
+				}
+
+				if _, err := service.CreateTransaction(ctx, model.Transaction{AccountID: accountID, Amount: -10, Currency: "USD", Category: "Food", OccurredAt: month, Tags: []string{"a;b"}}); !IsValidationError(err) {
+					t.Fatalf("expected tags with semicolons to be rejected, got %v", err)
+				}
+			},
+		},
+		{
+			name: "renaming and deleting a tag updates its transactions",
+			run: func(t *testing.T, service LedgerService) {
+				ctx := context.Background()
+				setup(t, service)
+				created, err := service.CreateTransaction(ctx, model.Transaction{AccountID: accountID, Amount: -40, Currency: "USD", Category: "Food", OccurredAt: month, Tags: []string{"trip", "work"}})
+				if err != nil {
+					t.Fatalf("create transaction: %v", err)
+				}
+				tags, err := service.ListTags(ctx, accountID)
+				if err != nil || len(tags) != 2 {
+					t.Fatalf("expected two tags, got %+v, %v", tags, err)
+				}
+				trip, work := tags[0], tags[1]
+
+				if _, err := service.CreateTag(ctx, model.Tag{AccountID: accountID, Name: "work"}); !IsTagExists(err) {
+					t.Fatalf("expected duplicate tags to be rejected, got %v", err)
+				}
+				trip.Name = "work"
+				if _, err := service.UpdateTag(ctx, trip); !IsTagExists(err) {
+					t.Fatalf("expected renaming onto another tag to be rejected, got %v", err)
+				}
+				trip.Name = "business trip"
+				if _, err := service.UpdateTag(ctx, trip); err != nil {
+					t.Fatalf("rename tag: %v", err)
+				}
+				if err := service.DeleteTag(ctx, accountID, work.ID); err != nil {
+					t.Fatalf("delete tag: %v", err)
+				}
+
+				updated, err := service.GetTransaction(ctx, created.ID)
+				if err != nil {
+					t.Fatalf("get transaction: %v", err)
+				}
+				if !slices.Equal(updated.Tags, []string{"business trip"}) {
+					t.Fatalf("expected the renamed tag only, got %v", updated.Tags)
+				}
+			},
+		},
+		{
+			name: "reports break totals down by tag",
+			run: func(t *testing.T, service LedgerService) {
+				ctx := context.Background()
+				setup(t, service)
+				expense, err := service.CreateTransaction(ctx, model.Transaction{AccountID: accountID, Amount: -100, Currency: "USD", OccurredAt: month, Tags: []string{"trip"}, Splits: []model.TransactionSplit{
+					{Category: "Food", Amount: -30},
+					{Category: "Travel", Amount: -70},
+				}})
+				if err != nil {
+					t.Fatalf("create expense: %v", err)
+				}
+				if _, err := service.CreateTransaction(ctx, model.Transaction{AccountID: accountID, Amount: 500, Currency: "USD", Category: "salary", OccurredAt: month, Tags: []string{"work"}}); err != nil {
+					t.Fatalf("create income: %v", err)
+				}
+				refund := model.Refund{AccountID: accountID, TransactionID: expense.ID, Amount: 20, Category: "Travel", OccurredAt: month.AddDate(0, 0, 1)}
+				if _, err := service.RefundTransaction(ctx, refund); err != nil {
+					t.Fatalf("refund: %v", err)
+				}
+
+				report, err := service.CreateReport(ctx, model.Report{AccountID: accountID, Name: "November", Period: "2024-11"})
+				if err != nil {
+					t.Fatalf("create report: %v", err)
+				}
+				expected := []model.ReportTag{
+					{Tag: "trip", TotalExpense: 80},
+					{Tag: "work", TotalIncome: 500},
+				}
+				if !slices.Equal(report.Tags, expected) {
+					t.Fatalf("expected tag totals %+v, got %+v", expected, report.Tags)
+				}
+			},
+		},
+		{
+			name: "tags round-trip through csv",
+			run: func(t *testing.T, service LedgerService) {
+				ctx := context.Background()
+				setup(t, service)
+				content := "account_id,amount,currency,category,description,occurred_at,splits,tags\n" +
+					accountID + ",-25,USD,Food,lunch,2024-11-05T12:00:00Z,,work;lunch\n" +
+					accountID + ",-15,USD,Food,snack,2024-11-06T12:00:00Z,,\n"
+				count, err := service.ImportTransactionsCSV(ctx, accountID, []byte(content), true)
+				if err != nil || count != 2 {
+					t.Fatalf("import csv: %d, %v", count, err)
+				}
+				if names := tagNames(t, service); !slices.Equal(names, []string{"lunch", "work"}) {
+					t.Fatalf("expected imported tags to be created, got %v", names)
+				}
+
+				exported, err := service.ExportTransactionsCSV(ctx, accountID)
+				if err != nil {
+					t.Fatalf("export csv: %v", err)
+				}
+				if !strings.Contains(string(exported), ",lunch;work\n") {
+					t.Fatalf("expected tags in the exported csv, got %s", exported)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := storage.NewInMemoryLedgerStorage()
+			tt.run(t, NewValidationService(NewLedgerService(repository.NewInMemoryLedgerRepository(store), nil, nil, nil, nil)))
+		})
+	}
+}
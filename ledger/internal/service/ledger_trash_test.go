@@ -0,0 +1,177 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/model"
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/repository"
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/storage"
+)
+
+func TestDeletedTransactionsMoveToTrashAndRestoreRechecksBudget(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewInMemoryLedgerStorage()
+	repo := repository.NewInMemoryLedgerRepository(store)
+	service := NewLedgerService(repo, nil, nil, nil, nil)
+
+	accountID := "account-trash"
+	month := time.Date(2024, time.August, 1, 0, 0, 0, 0, time.UTC)
+	if _, err := service.CreateBudget(ctx, model.Budget{
+		AccountID: accountID,
+		Name:      "Food",
+		Amount:    100,
+		Currency:  "USD",
+		Period:    "monthly",
+		Month:     month,
+	}); err != nil {
+		t.Fatalf("create budget: %v", err)
+	}
+	deleted, err := service.CreateTransaction(ctx, model.Transaction{
+		AccountID:  accountID,
+		Amount:     -80,
+		Currency:   "USD",
+		Category:   "Food",
+		OccurredAt: month.AddDate(0, 0, 2),
+	})
+	if err != nil {
+		t.Fatalf("create transaction: %v", err)
+	}
+	if err := service.DeleteTransaction(ctx, deleted.ID); err != nil {
+		t.Fatalf("delete transaction: %v", err)
+	}
+
+	if items := service.ListTransactions(ctx, accountID); len(items) != 0 {
+		t.Fatalf("expected deleted transaction to be excluded from list, got %d items", len(items))
+	}
+	if _, err := service.GetTransaction(ctx, deleted.ID); !IsNotFound(err) {
+		t.Fatalf("expected not found for deleted transaction, got %v", err)
+	}
+	trash, err := service.ListTrash(ctx, accountID)
+	if err != nil {
+		t.Fatalf("list trash: %v", err)
+	}
+	if len(trash.Transactions) != 1 || trash.Transactions[0].ID != deleted.ID {
+		t.Fatalf("expected deleted transaction in trash, got %+v", trash.Transactions)
+	}
+	if trash.Transactions[0].DeletedAt == nil {
+		t.Fatal("expected deleted_at to be set")
+	}
+
+	// The deleted expense no longer counts against the budget.
+	if _, err := service.CreateTransaction(ctx, model.Transaction{
+		AccountID:  accountID,
+		Amount:     -50,
+		Currency:   "USD",
+		Category:   "Food",
+		OccurredAt: month.AddDate(0, 0, 3),
+	}); err != nil {
+		t.Fatalf("create transaction after delete: %v", err)
+	}
+
+	if _, err := service.RestoreTransaction(ctx, accountID, deleted.ID); !IsBudgetExceeded(err) {
+		t.Fatalf("expected budget exceeded on restore, got %v", err)
+	}
+	if trash, _ := service.ListTrash(ctx, accountID); len(trash.Transactions) != 1 {
+		t.Fatalf("expected transaction to stay in trash after failed restore, got %d", len(trash.Transactions))
+	}
+
+	budget := service.ListBudgets(ctx, accountID)[0]
+	budget.Amount = 200
+	if _, err := service.UpdateBudget(ctx, budget); err != nil {
+		t.Fatalf("update budget: %v", err)
+	}
+	restored, err := service.RestoreTransaction(ctx, accountID, deleted.ID)
+	if err != nil {
+		t.Fatalf("restore transaction: %v", err)
+	}
+	if restored.DeletedAt != nil {
+		t.Fatal("expected restored transaction to have no deleted_at")
+	}
+	if items := service.ListTransactions(ctx, accountID); len(items) != 2 {
+		t.Fatalf("expected 2 transactions after restore, got %d", len(items))
+	}
+	if _, err := service.RestoreTransaction(ctx, accountID, deleted.ID); !IsNotFound(err) {
+		t.Fatalf("expected not found when restoring twice, got %v", err)
+	}
+}
+
+func TestPurgeDeletedRemovesExpiredTrash(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewInMemoryLedgerStorage()
+	repo := repository.NewInMemoryLedgerRepository(store)
+	service := NewLedgerService(repo, nil, nil, nil, nil)
+
+	accountID := "account-purge"
+	report, err := service.CreateReport(ctx, model.Report{AccountID: accountID, Name: "August", Period: "2024-08"})
+	if err != nil {
+		t.Fatalf("create report: %v", err)
+	}
+	if err := service.DeleteReport(ctx, accountID, report.ID); err != nil {
+		t.Fatalf("delete report: %v", err)
+	}
+
+	purged, err := service.PurgeDeleted(ctx, time.Now().UTC().Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("purge deleted: %v", err)
+	}
+	if purged != 0 {
+		t.Fatalf("expected recently deleted report to be kept, purged %d", purged)
+	}
+
+	purged, err = service.PurgeDeleted(ctx, time.Now().UTC().Add(time.Second))
+	if err != nil {
+		t.Fatalf("purge deleted: %v", err)
+	}
+	if purged != 1 {
+		t.Fatalf("expected 1 purged entity, got %d", purged)
+	}
+	if _, err := service.RestoreReport(ctx, accountID, report.ID); !IsNotFound(err) {
+		t.Fatalf("expected purged report to be gone, got %v", err)
+	}
+}
+
+func TestRestoreBudgetConflictsWithRecreatedBudget(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewInMemoryLedgerStorage()
+	service := NewLedgerService(repository.NewInMemoryLedgerRepository(store), nil, nil, nil, nil)
+
+	budget := model.Budget{
+		AccountID: "account-budget-trash",
+		Name:      "Food",
+		Amount:    100,
+		Currency:  "USD",
+		Period:    "monthly",
+		Month:     time.Date(2024, time.August, 1, 0, 0, 0, 0, time.UTC),
+	}
+	deleted, err := service.CreateBudget(ctx, budget)
+	if err != nil {
+		t.Fatalf("create budget: %v", err)
+	}
+	if err := service.DeleteBudget(ctx, budget.AccountID, deleted.ID); err != nil {
+		t.Fatalf("delete budget: %v", err)
+	}
+	recreated, err := service.CreateBudget(ctx, budget)
+	if err != nil {
+		t.Fatalf("recreate budget: %v", err)
+	}
+
+	if _, err := service.RestoreBudget(ctx, budget.AccountID, deleted.ID); !IsBudgetExists(err) {
+		t.Fatalf("expected a conflict with the recreated budget, got %v", err)
+	}
+	if trash, _ := service.ListTrash(ctx, budget.AccountID); len(trash.Budgets) != 1 {
+		t.Fatalf("expected the budget to stay in the trash, got %+v", trash.Budgets)
+	}
+
+	if err := service.DeleteBudget(ctx, budget.AccountID, recreated.ID); err != nil {
+		t.Fatalf("delete budget: %v", err)
+	}
+	restored, err := service.RestoreBudget(ctx, budget.AccountID, deleted.ID)
+	if err != nil {
+		t.Fatalf("restore budget: %v", err)
+	}
+	if restored.ID != deleted.ID {
+		t.Fatalf("expected budget %s to be restored, got %s", deleted.ID, restored.ID)
+	}
+}
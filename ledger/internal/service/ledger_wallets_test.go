@@ -0,0 +1,286 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/model"
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/repository"
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/storage"
+)
+
+func TestWalletsAndTransfers(t *testing.T) {
+	const accountID = "account-wallets"
+	day := time.Date(2024, time.September, 10, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		run  func(t *testing.T, service LedgerService, store *storage.InMemoryLedgerStorage)
+	}{
+		{
+			name: "transfer moves money between wallets",
+			run: func(t *testing.T, service LedgerService, store *storage.InMemoryLedgerStorage) {
+				ctx := context.Background()
+				cash := mustCreateWallet(t, service, accountID, "Cash", "USD")
+				savings := mustCreateWallet(t, service, accountID, "Savings", "USD")
+				if _, err := service.CreateTransaction(ctx, model.Transaction{
+					AccountID:  accountID,
+					Amount:     500,
+					Currency:   "USD",
+					Category:   "salary",
+					OccurredAt: day,
+					WalletID:   cash.ID,
+				}); err != nil {
+					t.Fatalf("create transaction: %v", err)
+				}
+
+				transfer, err := service.CreateTransfer(ctx, model.Transfer{
+					AccountID:    accountID,
+					FromWalletID: cash.ID,
+					ToWalletID:   savings.ID,
+					Amount:       200,
+					OccurredAt:   day,
+				})
+				if err != nil {
+					t.Fatalf("create transfer: %v", err)
+				}
+				if transfer.ToAmount != 200 {
+					t.Fatalf("expected the amount to be credited, got %v", transfer.ToAmount)
+				}
+
+				wallets, err := service.ListWallets(ctx, accountID)
+				if err != nil {
+					t.Fatalf("list wallets: %v", err)
+				}
+				if len(wallets) != 2 || wallets[0].Balance != 300 || wallets[1].Balance != 200 {
+					t.Fatalf("expected balances 300 and 200, got %+v", wallets)
+				}
+
+				// Transfers are neither income nor expense.
+				summary, err := service.GetReportSummary(ctx, accountID, day.AddDate(0, 0, -1), day.AddDate(0, 0, 1))
+				if err != nil {
+					t.Fatalf("report summary: %v", err)
+				}
+				if summary.TotalIncome != 500 || summary.TotalExpense != 0 {
+					t.Fatalf("expected transfers to stay out of the summary, got %+v", summary)
+				}
+			},
+		},
+		{
+			name: "transfer legs change only together",
+			run: func(t *testing.T, service LedgerService, store *storage.InMemoryLedgerStorage) {
+				ctx := context.Background()
+				cash := mustCreateWallet(t, service, accountID, "Cash", "USD")
+				savings := mustCreateWallet(t, service, accountID, "Savings", "USD")
+				transfer, err := service.CreateTransfer(ctx, model.Transfer{
+					AccountID:    accountID,
+					FromWalletID: cash.ID,
+					ToWalletID:   savings.ID,
+					Amount:       50,
+					OccurredAt:   day,
+				})
+				if err != nil {
+					t.Fatalf("create transfer: %v", err)
+				}
+				legs := service.ListTransactions(ctx, accountID)
+				if len(legs) != 2 {
+					t.Fatalf("expected two legs, got %d", len(legs))
+				}
+				if err := service.DeleteTransaction(ctx, legs[0].ID); !errors.Is(err, ErrTransferLeg) {
+					t.Fatalf("expected legs not to be deleted alone, got %v", err)
+				}
+				if _, err := service.UpdateTransaction(ctx, legs[0]); !errors.Is(err, ErrTransferLeg) {
+					t.Fatalf("expected legs not to be updated, got %v", err)
+				}
+				if err := service.DeleteWallet(ctx, accountID, cash.ID); !errors.Is(err, ErrWalletInUse) {
+					t.Fatalf("expected wallets with transactions to stay, got %v", err)
+				}
+
+				reused, err := service.CreateTransfer(ctx, model.Transfer{
+					ID:           transfer.ID,
+					AccountID:    accountID,
+					FromWalletID: savings.ID,
+					ToWalletID:   cash.ID,
+					Amount:       10,
+					OccurredAt:   day,
+				})
+				if err != nil {
+					t.Fatalf("create transfer: %v", err)
+				}
+				if reused.ID == transfer.ID {
+					t.Fatal("expected the transfer id to be generated by the ledger")
+				}
+				if err := service.DeleteTransfer(ctx, accountID, reused.ID); err != nil {
+					t.Fatalf("delete transfer: %v", err)
+				}
+
+				if err := service.DeleteTransfer(ctx, accountID, transfer.ID); err != nil {
+					t.Fatalf("delete transfer: %v", err)
+				}
+				if items := service.ListTransactions(ctx, accountID); len(items) != 0 {
+					t.Fatalf("expected both legs in the trash, got %d", len(items))
+				}
+				if _, err := service.RestoreTransaction(ctx, accountID, legs[1].ID); err != nil {
+					t.Fatalf("restore leg: %v", err)
+				}
+				if items := service.ListTransactions(ctx, accountID); len(items) != 2 {
+					t.Fatalf("expected both legs to be restored, got %d", len(items))
+				}
+			},
+		},
+		{
+			name: "transfers between currencies need the credited amount",
+			run: func(t *testing.T, service LedgerService, store *storage.InMemoryLedgerStorage) {
+				ctx := context.Background()
+				usd := mustCreateWallet(t, service, accountID, "Dollars", "USD")
+				eur := mustCreateWallet(t, service, accountID, "Euros", "EUR")
+				transfer := model.Transfer{AccountID: accountID, FromWalletID: usd.ID, ToWalletID: eur.ID, Amount: 100}
+				if _, err := service.CreateTransfer(ctx, transfer); !IsValidationError(err) {
+					t.Fatalf("expected validation error, got %v", err)
+				}
+				transfer.ToAmount = 90
+				if _, err := service.CreateTransfer(ctx, transfer); err != nil {
+					t.Fatalf("create transfer: %v", err)
+				}
+				eur, err := service.GetWallet(ctx, accountID, eur.ID)
+				if err != nil {
+					t.Fatalf("get wallet: %v", err)
+				}
+				if eur.Balance != 90 {
+					t.Fatalf("expected 90 EUR, got %v", eur.Balance)
+				}
+				if _, err := service.CreateTransaction(ctx, model.Transaction{
+					AccountID: accountID,
+					Amount:    10,
+					Currency:  "USD",
+					Category:  "salary",
+					WalletID:  eur.ID,
+				}); !IsValidationError(err) {
+					t.Fatalf("expected the wallet currency to be enforced, got %v", err)
+				}
+			},
+		},
+		{
+			name: "csv export leaves out transfers and refunds",
+			run: func(t *testing.T, service LedgerService, store *storage.InMemoryLedgerStorage) {
+				ctx := context.Background()
+				cash := mustCreateWallet(t, service, accountID, "Cash", "USD")
+				savings := mustCreateWallet(t, service, accountID, "Savings", "USD")
+				if _, err := service.CreateTransaction(ctx, model.Transaction{
+					AccountID: accountID, Amount: 500, Currency: "USD", Category: "salary", OccurredAt: day, WalletID: cash.ID,
+				}); err != nil {
+					t.Fatalf("create transaction: %v", err)
+				}
+				if _, err := service.CreateTransfer(ctx, model.Transfer{
+					AccountID: accountID, FromWalletID: cash.ID, ToWalletID: savings.ID, Amount: 200, OccurredAt: day,
+				}); err != nil {
+					t.Fatalf("create transfer: %v", err)
+				}
+				if _, err := service.CreateBudget(ctx, model.Budget{
+					AccountID: accountID, Name: "Food", Amount: 100, Currency: "USD", Period: "monthly",
+					Month: time.Date(2024, time.September, 1, 0, 0, 0, 0, time.UTC),
+				}); err != nil {
+					t.Fatalf("create budget: %v", err)
+				}
+				expense, err := service.CreateTransaction(ctx, model.Transaction{
+					AccountID: accountID, Amount: -40, Currency: "USD", Category: "Food", OccurredAt: day,
+				})
+				if err != nil {
+					t.Fatalf("create expense: %v", err)
+				}
+				if _, err := service.RefundTransaction(ctx, model.Refund{
+					AccountID: accountID, TransactionID: expense.ID, Amount: 10, OccurredAt: day,
+				}); err != nil {
+					t.Fatalf("refund: %v", err)
+				}
+
+				exported, err := service.ExportTransactionsCSV(ctx, accountID)
+				if err != nil {
+					t.Fatalf("export csv: %v", err)
+				}
+				if rows := strings.Count(string(exported), "\n"); rows != 3 {
+					t.Fatalf("expected a header and 2 rows, got:\n%s", exported)
+				}
+				if strings.Contains(string(exported), model.CategoryTransfer) {
+					t.Fatalf("expected transfer legs to be left out, got:\n%s", exported)
+				}
+
+				content := "account_id,amount,currency,category,description,occurred_at\n" +
+					accountID + ",-200,USD,transfer,,2024-09-10T12:00:00Z\n"
+				if _, err := service.ImportTransactionsCSV(ctx, accountID, []byte(content), true); !IsValidationError(err) {
+					t.Fatalf("expected the transfer category to be rejected on import, got %v", err)
+				}
+				if _, err := service.CreateTransaction(ctx, model.Transaction{
+					AccountID: accountID, Amount: -5, Currency: "USD", Category: model.CategoryTransfer, OccurredAt: day,
+				}); !IsValidationError(err) {
+					t.Fatalf("expected the transfer category to be reserved, got %v", err)
+				}
+				expense.Category = model.CategoryTransfer
+				if _, err := service.UpdateTransaction(ctx, expense); !IsValidationError(err) {
+					t.Fatalf("expected the transfer category to be reserved on update, got %v", err)
+				}
+			},
+		},
+		{
+			name: "balance history carries balances forward",
+			run: func(t *testing.T, service LedgerService, store *storage.InMemoryLedgerStorage) {
+				ctx := context.Background()
+				cash := mustCreateWallet(t, service, accountID, "Cash", "USD")
+				for _, offset := range []int{-5, 0, 2} {
+					if _, err := service.CreateTransaction(ctx, model.Transaction{
+						AccountID:  accountID,
+						Amount:     10,
+						Currency:   "USD",
+						Category:   "salary",
+						OccurredAt: day.AddDate(0, 0, offset),
+						WalletID:   cash.ID,
+					}); err != nil {
+						t.Fatalf("create transaction: %v", err)
+					}
+				}
+
+				points, err := service.GetWalletBalanceHistory(ctx, accountID, cash.ID, day, day.AddDate(0, 0, 3), "")
+				if err != nil {
+					t.Fatalf("balance history: %v", err)
+				}
+				want := []float64{20, 20, 30, 30}
+				if len(points) != len(want) {
+					t.Fatalf("expected %d points, got %+v", len(want), points)
+				}
+				for i, point := range points {
+					if point.Balance != want[i] {
+						t.Fatalf("point %d: expected %v, got %v", i, want[i], point.Balance)
+					}
+				}
+				if _, err := service.GetWalletBalanceHistory(ctx, accountID, cash.ID, day, day.AddDate(2, 0, 0), model.BalanceStepDay); !IsValidationError(err) {
+					t.Fatalf("expected too long histories to be rejected, got %v", err)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := storage.NewInMemoryLedgerStorage()
+			repo := repository.NewInMemoryLedgerRepository(store)
+			tt.run(t, NewValidationService(NewLedgerService(repo, nil, nil, nil, nil)), store)
+		})
+	}
+}
+
+func mustCreateWallet(t *testing.T, service LedgerService, accountID, name, currency string) model.Wallet {
+	t.Helper()
+	wallet, err := service.CreateWallet(context.Background(), model.Wallet{
+		AccountID: accountID,
+		Name:      name,
+		Type:      model.WalletTypeCash,
+		Currency:  currency,
+	})
+	if err != nil {
+		t.Fatalf("create wallet: %v", err)
+	}
+	return wallet
+}
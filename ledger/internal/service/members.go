@@ -0,0 +1,252 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/actor"
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/model"
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/repository"
+)
+
+const (
+	// invitationTTL is how long an invitation token can be accepted.
+	invitationTTL = 7 * 24 * time.Hour
+	// invitationTokenBytes is the entropy of an invitation token.
+	invitationTokenBytes = 32
+)
+
+// CreateAccount creates a shared account owned by the actor.
+func (s *DefaultLedgerService) CreateAccount(ctx context.Context, name string) (model.Account, error) {
+	userID, err := requireUser(ctx)
+	if err != nil {
+		return model.Account{}, err
+	}
+	now := time.Now().UTC()
+	account := model.Account{
+		ID:        uuid.NewString(),
+		Name:      name,
+		CreatedBy: userID,
+		CreatedAt: now,
+		Role:      model.MemberRoleOwner,
+	}
+	owner := model.AccountMember{
+		AccountID: account.ID,
+		UserID:    account.CreatedBy,
+		Role:      model.MemberRoleOwner,
+		CreatedAt: now,
+	}
+	err = s.repo.RunInTx(ctx, func(repo repository.LedgerRepository) error {
+		if err := repo.CreateAccount(ctx, account, owner); err != nil {
+			return err
+		}
+		return appendAudit(ctx, repo, model.AuditEntityMember, account.ID, owner.UserID, model.AuditOperationCreate, nil, owner)
+	})
+	if err != nil {
+		return model.Account{}, err
+	}
+	return account, nil
+}
+
+// ListAccounts returns the personal account of the actor followed by the
+// shared accounts the actor is a member of.
+func (s *DefaultLedgerService) ListAccounts(ctx context.Context) ([]model.Account, error) {
+	userID, err := requireUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	shared, err := s.repo.ListAccounts(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	personal := model.Account{ID: userID, CreatedBy: userID, Role: model.MemberRoleOwner, Personal: true}
+	return append([]model.Account{personal}, shared...), nil
+}
+
+// ListMembers returns the members of a shared account to anyone who may read it.
+func (s *DefaultLedgerService) ListMembers(ctx context.Context, accountID string) ([]model.AccountMember, error) {
+	if err := s.AuthorizeRead(ctx, accountID, "members"); err != nil {
+		return nil, err
+	}
+	if _, err := s.repo.GetAccount(ctx, accountID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListMembers(ctx, accountID)
+}
+
+// UpdateMember changes the role of a member. Only owners change roles, and the
+// last owner cannot be demoted.
+func (s *DefaultLedgerService) UpdateMember(ctx context.Context, accountID, userID, role string) (model.AccountMember, error) {
+	if err := s.authorizeOwner(ctx, accountID); err != nil {
+		return model.AccountMember{}, err
+	}
+	var updated model.AccountMember
+	err := s.repo.RunInTx(ctx, func(repo repository.LedgerRepository) error {
+		current, err := repo.GetMember(ctx, accountID, userID)
+		if err != nil {
+			return err
+		}
+		if current.Role == model.MemberRoleOwner && role != model.MemberRoleOwner {
+			if err := ensureAnotherOwner(ctx, repo, accountID, userID); err != nil {
+				return err
+			}
+		}
+		updated = current
+		updated.Role = role
+		if err := repo.UpdateMember(ctx, updated); err != nil {
+			return err
+		}
+		return appendAudit(ctx, repo, model.AuditEntityMember, accountID, userID, model.AuditOperationUpdate, current, updated)
+	})
+	if err != nil {
+		return model.AccountMember{}, err
+	}
+	return updated, nil
+}
+
+// RemoveMember removes a member from a shared account. Owners remove anyone and
+// every member may leave; the last owner cannot be removed.
+func (s *DefaultLedgerService) RemoveMember(ctx context.Context, accountID, userID string) error {
+	actorID, err := requireUser(ctx)
+	if err != nil {
+		return err
+	}
+	if actorID != userID {
+		if err := s.authorizeOwner(ctx, accountID); err != nil {
+			return err
+		}
+	}
+	return s.repo.RunInTx(ctx, func(repo repository.LedgerRepository) error {
+		current, err := repo.GetMember(ctx, accountID, userID)
+		if err != nil {
+			return err
+		}
+		if current.Role == model.MemberRoleOwner {
+			if err := ensureAnotherOwner(ctx, repo, accountID, userID); err != nil {
+				return err
+			}
+		}
+		if err := repo.RemoveMember(ctx, accountID, userID); err != nil {
+			return err
+		}
+		return appendAudit(ctx, repo, model.AuditEntityMember, accountID, userID, model.AuditOperationDelete, current, nil)
+	})
+}
+
+// CreateInvitation issues an invitation to a shared account. The token is
+// returned only here; the ledger keeps its hash.
+func (s *DefaultLedgerService) CreateInvitation(ctx context.Context, accountID, role string) (model.Invitation, string, error) {
+	if err := s.authorizeOwner(ctx, accountID); err != nil {
+		return model.Invitation{}, "", err
+	}
+	if _, err := s.repo.GetAccount(ctx, accountID); err != nil {
+		return model.Invitation{}, "", err
+	}
+	token, err := newInvitationToken()
+	if err != nil {
+		return model.Invitation{}, "", err
+	}
+	now := time.Now().UTC()
+	invitation := model.Invitation{
+		ID:        uuid.NewString(),
+		AccountID: accountID,
+		TokenHash: hashInvitationToken(token),
+		Role:      role,
+		CreatedBy: actor.UserIDFromContext(ctx),
+		CreatedAt: now,
+		ExpiresAt: now.Add(invitationTTL),
+	}
+	if err := s.repo.CreateInvitation(ctx, invitation); err != nil {
+		return model.Invitation{}, "", err
+	}
+	return invitation, token, nil
+}
+
+// ListInvitations returns the pending invitations of a shared account.
+func (s *DefaultLedgerService) ListInvitations(ctx context.Context, accountID string) ([]model.Invitation, error) {
+	if err := s.authorizeOwner(ctx, accountID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListInvitations(ctx, accountID, time.Now().UTC())
+}
+
+// RevokeInvitation deletes a pending invitation.
+func (s *DefaultLedgerService) RevokeInvitation(ctx context.Context, accountID, id string) error {
+	if err := s.authorizeOwner(ctx, accountID); err != nil {
+		return err
+	}
+	return s.repo.DeleteInvitation(ctx, accountID, id)
+}
+
+// AcceptInvitation makes the actor a member of the invitation's account with
+// the invited role. An invitation can be accepted once.
+func (s *DefaultLedgerService) AcceptInvitation(ctx context.Context, token string) (model.AccountMember, error) {
+	userID, err := requireUser(ctx)
+	if err != nil {
+		return model.AccountMember{}, err
+	}
+	now := time.Now().UTC()
+	var member model.AccountMember
+	err = s.repo.RunInTx(ctx, func(repo repository.LedgerRepository) error {
+		invitation, err := repo.AcceptInvitation(ctx, hashInvitationToken(token), userID, now)
+		if err != nil {
+			if IsNotFound(err) {
+				return ErrInvalidInvitation
+			}
+			return err
+		}
+		if _, err := repo.GetMember(ctx, invitation.AccountID, userID); err == nil {
+			return ErrAlreadyMember
+		} else if !IsNotFound(err) {
+			return err
+		}
+		member = model.AccountMember{
+			AccountID: invitation.AccountID,
+			UserID:    userID,
+			Role:      invitation.Role,
+			CreatedAt: now,
+		}
+		if err := repo.AddMember(ctx, member); err != nil {
+			return err
+		}
+		return appendAudit(ctx, repo, model.AuditEntityMember, member.AccountID, member.UserID, model.AuditOperationCreate, nil, member)
+	})
+	if err != nil {
+		return model.AccountMember{}, err
+	}
+	return member, nil
+}
+
+// ensureAnotherOwner fails with ErrLastOwner unless the account has an owner
+// other than the user.
+func ensureAnotherOwner(ctx context.Context, repo repository.LedgerRepository, accountID, userID string) error {
+	members, err := repo.ListMembers(ctx, accountID)
+	if err != nil {
+		return err
+	}
+	for _, member := range members {
+		if member.UserID != userID && member.Role == model.MemberRoleOwner {
+			return nil
+		}
+	}
+	return ErrLastOwner
+}
+
+func newInvitationToken() (string, error) {
+	buf := make([]byte, invitationTokenBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+func hashInvitationToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
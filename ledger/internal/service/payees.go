@@ -0,0 +1,303 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/model"
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/repository"
+)
+
+// topPayees limits the payees of a report to those with the largest expenses.
+const topPayees = 10
+
+// CreatePayee adds a payee to the account. Transactions created afterwards are
+// linked to it when their description normalizes like its name or one of its
+// aliases.
+func (s *DefaultLedgerService) CreatePayee(ctx context.Context, payee model.Payee) (model.Payee, error) {
+	payees, err := s.repo.ListPayees(ctx, payee.AccountID)
+	if err != nil {
+		return model.Payee{}, err
+	}
+	if err := checkPayeeKeys(payee, payees); err != nil {
+		return model.Payee{}, err
+	}
+	now := time.Now().UTC()
+	if payee.ID == "" {
+		payee.ID = uuid.NewString()
+	}
+	payee.CreatedAt = now
+	payee.UpdatedAt = now
+	err = s.repo.RunInTx(ctx, func(repo repository.LedgerRepository) error {
+		return createPayee(ctx, repo, payee)
+	})
+	if err != nil {
+		return model.Payee{}, err
+	}
+	return payee, nil
+}
+
+func (s *DefaultLedgerService) GetPayee(ctx context.Context, accountID, id string) (model.Payee, error) {
+	return s.repo.GetPayee(ctx, accountID, id)
+}
+
+// UpdatePayee replaces the name, aliases and default category of a payee.
+// Linked transactions stay linked; new aliases apply to new transactions.
+func (s *DefaultLedgerService) UpdatePayee(ctx context.Context, payee model.Payee) (model.Payee, error) {
+	current, err := s.repo.GetPayee(ctx, payee.AccountID, payee.ID)
+	if err != nil {
+		return model.Payee{}, err
+	}
+	payees, err := s.repo.ListPayees(ctx, payee.AccountID)
+	if err != nil {
+		return model.Payee{}, err
+	}
+	if err := checkPayeeKeys(payee, payees); err != nil {
+		return model.Payee{}, err
+	}
+	payee.CreatedAt = current.CreatedAt
+	payee.UpdatedAt = time.Now().UTC()
+	err = s.repo.RunInTx(ctx, func(repo repository.LedgerRepository) error {
+		if err := repo.UpdatePayee(ctx, payee); err != nil {
+			return err
+		}
+		if err := appendAudit(ctx, repo, model.AuditEntityPayee, payee.AccountID, payee.ID, model.AuditOperationUpdate, current, payee); err != nil {
+			return err
+		}
+		return appendEvent(ctx, repo, model.EventPayeeChanged, payee.AccountID, payee.ID, payeeChangedPayload{Operation: changeOperationUpdated, Payee: payee})
+	})
+	if err != nil {
+		return model.Payee{}, err
+	}
+	return payee, nil
+}
+
+// DeletePayee removes a payee and unlinks its transactions, which keep their
+// categories.
+func (s *DefaultLedgerService) DeletePayee(ctx context.Context, accountID, id string) error {
+	return s.repo.RunInTx(ctx, func(repo repository.LedgerRepository) error {
+		current, err := repo.GetPayee(ctx, accountID, id)
+		if err != nil {
+			return err
+		}
+		if err := repo.DeletePayee(ctx, accountID, id); err != nil {
+			return err
+		}
+		if err := appendAudit(ctx, repo, model.AuditEntityPayee, current.AccountID, current.ID, model.AuditOperationDelete, current, nil); err != nil {
+			return err
+		}
+		return appendEvent(ctx, repo, model.EventPayeeChanged, current.AccountID, current.ID, payeeChangedPayload{Operation: changeOperationDeleted, Payee: current})
+	})
+}
+
+// ListPayees returns the payees of the account ordered by name.
+func (s *DefaultLedgerService) ListPayees(ctx context.Context, accountID string) ([]model.Payee, error) {
+	return s.repo.ListPayees(ctx, accountID)
+}
+
+func createPayee(ctx context.Context, repo repository.LedgerRepository, payee model.Payee) error {
+	if err := repo.CreatePayee(ctx, payee); err != nil {
+		return err
+	}
+	if err := appendAudit(ctx, repo, model.AuditEntityPayee, payee.AccountID, payee.ID, model.AuditOperationCreate, nil, payee); err != nil {
+		return err
+	}
+	return appendEvent(ctx, repo, model.EventPayeeChanged, payee.AccountID, payee.ID, payeeChangedPayload{Operation: changeOperationCreated, Payee: payee})
+}
+
+// checkPayeeKeys rejects a payee whose name or aliases normalize like those
+// of another payee of the account, since descriptions could not tell them
+// apart.
+func checkPayeeKeys(payee model.Payee, payees []model.Payee) error {
+	owners := make(map[string]model.Payee)
+	for _, other := range payees {
+		if other.ID == payee.ID {
+			continue
+		}
+		for _, key := range payeeKeys(other) {
+			owners[key] = other
+		}
+	}
+	for _, key := range payeeKeys(payee) {
+		if other, ok := owners[key]; ok {
+			return fmt.Errorf("%w: %q matches payee %q", ErrPayeeExists, key, other.Name)
+		}
+	}
+	return nil
+}
+
+// payeeKeys returns the normalized name and aliases of a payee.
+func payeeKeys(payee model.Payee) []string {
+	keys := make([]string, 0, len(payee.Aliases)+1)
+	for _, value := range append([]string{payee.Name}, payee.Aliases...) {
+		if key := normalizePayee(value); key != "" {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// payeeSeparators are turned into spaces before a description is split into
+// words, so that "YANDEX*TAXI" and "Yandex Taxi" normalize alike.
+var payeeSeparators = strings.NewReplacer(
+	"-", " ", "_", " ", "#", " ", "№", " ", "*", " ", "/", " ", `\`, " ",
+	".", " ", ",", " ", ":", " ", ";", " ", "(", " ", ")", " ", `"`, " ",
+	"'", "", "«", " ", "»", " ",
+)
+
+// payeeLocations are trailing words naming a city or a country, which card
+// processors append to merchant names.
+var payeeLocations = map[string]bool{
+	"spb": true, "st": true, "saint": true, "sankt": true, "petersburg": true, "peterburg": true,
+	"msk": true, "moscow": true, "moskva": true, "g": true,
+	"ru": true, "rus": true, "russia": true,
+	"спб": true, "санкт": true, "петербург": true, "мск": true, "москва": true, "г": true,
+	"рф": true, "россия": true,
+}
+
+// normalizePayee returns the key payees are matched by: the lowercase words
+// of the description without store numbers and trailing city or country
+// names. "LENTA-123 SPB" and "Lenta #45" both normalize to "lenta". An empty
+// key means the description names no payee.
+func normalizePayee(description string) string {
+	words := strings.Fields(payeeSeparators.Replace(strings.ToLower(description)))
+	kept := words[:0]
+	for _, word := range words {
+		if strings.ContainsFunc(word, unicode.IsDigit) {
+			continue
+		}
+		kept = append(kept, word)
+	}
+	// The name itself is kept even if it is a city: a payee called "Moscow"
+	// is still a payee.
+	for len(kept) > 1 && payeeLocations[kept[len(kept)-1]] {
+		kept = kept[:len(kept)-1]
+	}
+	return strings.Join(kept, " ")
+}
+
+// payeeName turns a normalized key into the name of a payee created for it.
+func payeeName(key string) string {
+	words := strings.Fields(key)
+	for i, word := range words {
+		first, size := utf8.DecodeRuneInString(word)
+		words[i] = string(unicode.ToUpper(first)) + word[size:]
+	}
+	return strings.Join(words, " ")
+}
+
+// payeeIndex looks up the payees of an account by ID and by the normalized
+// forms of their names and aliases.
+type payeeIndex struct {
+	accountID string
+	byID      map[string]model.Payee
+	byKey     map[string]model.Payee
+	// created are the payees that link made up for descriptions matching no
+	// payee. ensurePayees stores those in use.
+	created []model.Payee
+}
+
+// loadPayees indexes the payees of the account.
+func (s *DefaultLedgerService) loadPayees(ctx context.Context, accountID string) (*payeeIndex, error) {
+	payees, err := s.repo.ListPayees(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	index := &payeeIndex{
+		accountID: accountID,
+		byID:      make(map[string]model.Payee, len(payees)),
+		byKey:     make(map[string]model.Payee, len(payees)),
+	}
+	for _, payee := range payees {
+		index.add(payee)
+	}
+	return index, nil
+}
+
+func (i *payeeIndex) add(payee model.Payee) {
+	i.byID[payee.ID] = payee
+	for _, key := range payeeKeys(payee) {
+		i.byKey[key] = payee
+	}
+}
+
+// link sets the payee of a transaction. A given PayeeID must be a payee of the
+// account; otherwise the payee is found by the normalized description and
+// made up when the account has none yet.
+func (i *payeeIndex) link(tx *model.Transaction, now time.Time) error {
+	if tx.PayeeID != "" {
+		if _, ok := i.byID[tx.PayeeID]; !ok {
+			return fmt.Errorf("%w: payee %s not found", ErrValidation, tx.PayeeID)
+		}
+		return nil
+	}
+	key := normalizePayee(tx.Description)
+	if key == "" {
+		return nil
+	}
+	payee, ok := i.byKey[key]
+	if !ok {
+		payee = model.Payee{ID: uuid.NewString(), AccountID: i.accountID, Name: payeeName(key), CreatedAt: now, UpdatedAt: now}
+		i.add(payee)
+		i.created = append(i.created, payee)
+	}
+	tx.PayeeID = payee.ID
+	return nil
+}
+
+// defaultCategory returns the default category of the payee of tx, if any.
+func (i *payeeIndex) defaultCategory(tx model.Transaction) string {
+	return i.byID[tx.PayeeID].DefaultCategory
+}
+
+// ensurePayees stores the payees made up for the transactions. repo is
+// expected to be bound to the transaction writing the transactions.
+func ensurePayees(ctx context.Context, repo repository.LedgerRepository, index *payeeIndex, txs ...model.Transaction) error {
+	used := make(map[string]bool, len(txs))
+	for _, tx := range txs {
+		used[tx.PayeeID] = true
+	}
+	for _, payee := range index.created {
+		if !used[payee.ID] {
+			continue
+		}
+		if err := createPayee(ctx, repo, payee); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reportPayees returns the payees with the largest expenses, net of refunds.
+func reportPayees(totals map[string]*model.ReportPayee, payees []model.Payee) []model.ReportPayee {
+	names := make(map[string]string, len(payees))
+	for _, payee := range payees {
+		names[payee.ID] = payee.Name
+	}
+	items := make([]model.ReportPayee, 0, len(totals))
+	for id, total := range totals {
+		name, ok := names[id]
+		if !ok || total.TotalExpense <= 0 {
+			continue
+		}
+		total.Name = name
+		items = append(items, *total)
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].TotalExpense != items[j].TotalExpense {
+			return items[i].TotalExpense > items[j].TotalExpense
+		}
+		return items[i].Name < items[j].Name
+	})
+	if len(items) > topPayees {
+		items = items[:topPayees]
+	}
+	return items
+}
@@ -0,0 +1,178 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/actor"
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/model"
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/repository"
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/storage"
+)
+
+// RefundTransaction records a refund of an expense: a positive transaction in
+// the category and wallet of the expense that reduces its expense in the month
+// of the refund. Refunds carry the tags and the payee of the expense. Refunds
+// of an expense, per part for split expenses, never sum up to more than the
+// expense: the expense is locked while its refunds are checked, so concurrent
+// refunds are checked one after another.
+func (s *DefaultLedgerService) RefundTransaction(ctx context.Context, refund model.Refund) (model.Transaction, error) {
+	original, err := s.repo.GetTransaction(ctx, refund.TransactionID)
+	if err != nil {
+		return model.Transaction{}, err
+	}
+	if original.AccountID != refund.AccountID {
+		return model.Transaction{}, storage.ErrNotFound
+	}
+	if original.TransferID != "" {
+		return model.Transaction{}, ErrTransferLeg
+	}
+	if original.Amount >= 0 || original.RefundOf != "" {
+		return model.Transaction{}, fmt.Errorf("%w: only expenses can be refunded", ErrValidation)
+	}
+
+	category := refund.Category
+	if category == "" {
+		if len(original.Splits) > 0 {
+			return model.Transaction{}, fmt.Errorf("%w: category of the refunded part is required", ErrValidation)
+		}
+		category = original.Category
+	}
+	if !hasExpenseCategory(original, category) {
+		return model.Transaction{}, fmt.Errorf("%w: transaction has no expense in category %q", ErrValidation, category)
+	}
+
+	now := time.Now().UTC()
+	occurredAt := refund.OccurredAt
+	if occurredAt.IsZero() {
+		occurredAt = now
+	}
+	if occurredAt.Before(original.OccurredAt) {
+		return model.Transaction{}, fmt.Errorf("%w: refund cannot precede the refunded transaction", ErrValidation)
+	}
+	tx := model.Transaction{
+		ID:          uuid.NewString(),
+		AccountID:   original.AccountID,
+		Amount:      refund.Amount,
+		Currency:    original.Currency,
+		Category:    category,
+		Description: refund.Description,
+		OccurredAt:  occurredAt,
+		CreatedBy:   actor.UserIDFromContext(ctx),
+		WalletID:    original.WalletID,
+		RefundOf:    original.ID,
+		PayeeID:     original.PayeeID,
+		Tags:        original.Tags,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	var created model.Transaction
+	err = s.repo.RunInTx(ctx, func(repo repository.LedgerRepository) error {
+		locked, err := repo.LockTransaction(ctx, original.ID)
+		if err != nil {
+			return err
+		}
+		refunds := refundsOf(repo.ListTransactions(ctx), locked.ID)
+		if err := checkRefunds(locked, append(refunds, tx)); err != nil {
+			return err
+		}
+
+		created, err = repo.CreateTransaction(ctx, tx)
+		if err != nil {
+			return err
+		}
+		if err := postJournal(ctx, repo, []model.Transaction{created}); err != nil {
+			return err
+		}
+		if err := appendAudit(ctx, repo, model.AuditEntityTransaction, created.AccountID, created.ID, model.AuditOperationCreate, nil, created); err != nil {
+			return err
+		}
+		return appendEvent(ctx, repo, model.EventTransactionCreated, created.AccountID, created.ID, created)
+	})
+	if err != nil {
+		return model.Transaction{}, err
+	}
+	return created, nil
+}
+
+// ListRefunds returns the live refunds of a transaction of the account.
+func (s *DefaultLedgerService) ListRefunds(ctx context.Context, accountID, transactionID string) ([]model.Transaction, error) {
+	original, err := s.repo.GetTransaction(ctx, transactionID)
+	if err != nil {
+		return nil, err
+	}
+	if original.AccountID != accountID {
+		return nil, storage.ErrNotFound
+	}
+	return refundsOf(s.ListTransactions(ctx, accountID), transactionID), nil
+}
+
+// checkRefunds verifies that refunds stay within the expense of original in
+// every category they refund.
+func checkRefunds(original model.Transaction, refunds []model.Transaction) error {
+	if len(refunds) == 0 {
+		return nil
+	}
+	expenses := map[string]float64{}
+	if original.Amount < 0 && original.TransferID == "" {
+		for _, part := range transactionParts(original) {
+			expenses[part.Category] += -part.Amount
+		}
+	}
+	refunded := map[string]float64{}
+	for _, refund := range refunds {
+		if refund.Currency != original.Currency {
+			return fmt.Errorf("%w: refund %s is in %s", ErrRefundExceeded, refund.ID, refund.Currency)
+		}
+		refunded[refund.Category] += refund.Amount
+	}
+	for category, amount := range refunded {
+		if amount > expenses[category]+journalTolerance {
+			return fmt.Errorf("%w: refunds of %q total %v of %v", ErrRefundExceeded, category, amount, expenses[category])
+		}
+	}
+	return nil
+}
+
+// expenseParts returns what a transaction adds to the expenses of its
+// categories: the parts of an expense as positive amounts and a refund as a
+// negative amount in its category. Income and transfers add nothing.
+func expenseParts(tx model.Transaction) []model.TransactionSplit {
+	switch {
+	case tx.TransferID != "":
+		return nil
+	case tx.RefundOf != "":
+		return []model.TransactionSplit{{Category: tx.Category, Amount: -tx.Amount}}
+	case tx.Amount >= 0:
+		return nil
+	}
+	parts := transactionParts(tx)
+	expenses := make([]model.TransactionSplit, 0, len(parts))
+	for _, part := range parts {
+		expenses = append(expenses, model.TransactionSplit{Category: part.Category, Amount: -part.Amount})
+	}
+	return expenses
+}
+
+func hasExpenseCategory(tx model.Transaction, category string) bool {
+	for _, part := range transactionParts(tx) {
+		if part.Category == category {
+			return true
+		}
+	}
+	return false
+}
+
+func refundsOf(transactions []model.Transaction, transactionID string) []model.Transaction {
+	var refunds []model.Transaction
+	for _, tx := range transactions {
+		if tx.RefundOf == transactionID {
+			refunds = append(refunds, tx)
+		}
+	}
+	return refunds
+}
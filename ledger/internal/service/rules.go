@@ -0,0 +1,275 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/model"
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/repository"
+)
+
+// CreateRule adds a categorization rule to the account. It applies to
+// transactions created afterwards; ApplyRules re-categorizes existing ones.
+func (s *DefaultLedgerService) CreateRule(ctx context.Context, rule model.CategoryRule) (model.CategoryRule, error) {
+	now := time.Now().UTC()
+	if rule.ID == "" {
+		rule.ID = uuid.NewString()
+	}
+	rule.CreatedAt = now
+	rule.UpdatedAt = now
+	err := s.repo.RunInTx(ctx, func(repo repository.LedgerRepository) error {
+		if err := repo.CreateRule(ctx, rule); err != nil {
+			return err
+		}
+		if err := appendAudit(ctx, repo, model.AuditEntityRule, rule.AccountID, rule.ID, model.AuditOperationCreate, nil, rule); err != nil {
+			return err
+		}
+		return appendEvent(ctx, repo, model.EventRuleChanged, rule.AccountID, rule.ID, ruleChangedPayload{Operation: changeOperationCreated, Rule: rule})
+	})
+	if err != nil {
+		return model.CategoryRule{}, err
+	}
+	return rule, nil
+}
+
+func (s *DefaultLedgerService) GetRule(ctx context.Context, accountID, id string) (model.CategoryRule, error) {
+	return s.repo.GetRule(ctx, accountID, id)
+}
+
+func (s *DefaultLedgerService) UpdateRule(ctx context.Context, rule model.CategoryRule) (model.CategoryRule, error) {
+	current, err := s.repo.GetRule(ctx, rule.AccountID, rule.ID)
+	if err != nil {
+		return model.CategoryRule{}, err
+	}
+	rule.CreatedAt = current.CreatedAt
+	rule.UpdatedAt = time.Now().UTC()
+	err = s.repo.RunInTx(ctx, func(repo repository.LedgerRepository) error {
+		if err := repo.UpdateRule(ctx, rule); err != nil {
+			return err
+		}
+		if err := appendAudit(ctx, repo, model.AuditEntityRule, rule.AccountID, rule.ID, model.AuditOperationUpdate, current, rule); err != nil {
+			return err
+		}
+		return appendEvent(ctx, repo, model.EventRuleChanged, rule.AccountID, rule.ID, ruleChangedPayload{Operation: changeOperationUpdated, Rule: rule})
+	})
+	if err != nil {
+		return model.CategoryRule{}, err
+	}
+	return rule, nil
+}
+
+// DeleteRule removes a rule. Transactions it has categorized keep their
+// category.
+func (s *DefaultLedgerService) DeleteRule(ctx context.Context, accountID, id string) error {
+	return s.repo.RunInTx(ctx, func(repo repository.LedgerRepository) error {
+		current, err := repo.GetRule(ctx, accountID, id)
+		if err != nil {
+			return err
+		}
+		if err := repo.DeleteRule(ctx, accountID, id); err != nil {
+			return err
+		}
+		if err := appendAudit(ctx, repo, model.AuditEntityRule, current.AccountID, current.ID, model.AuditOperationDelete, current, nil); err != nil {
+			return err
+		}
+		return appendEvent(ctx, repo, model.EventRuleChanged, current.AccountID, current.ID, ruleChangedPayload{Operation: changeOperationDeleted, Rule: current})
+	})
+}
+
+// ListRules returns the rules of the account in evaluation order.
+func (s *DefaultLedgerService) ListRules(ctx context.Context, accountID string) ([]model.CategoryRule, error) {
+	return s.repo.ListRules(ctx, accountID)
+}
+
+// PreviewRule returns the live transactions of the account that the rule
+// matches, whether or not it is enabled. Nothing is saved.
+func (s *DefaultLedgerService) PreviewRule(ctx context.Context, rule model.CategoryRule) ([]model.RuleMatch, error) {
+	matcher, err := compileRule(rule)
+	if err != nil {
+		return nil, err
+	}
+	live := s.ListTransactions(ctx, rule.AccountID)
+	refunded := refundedIDs(live)
+	matches := make([]model.RuleMatch, 0)
+	for _, tx := range live {
+		if !categorizable(tx) || refunded[tx.ID] || !matcher.matches(tx) {
+			continue
+		}
+		matches = append(matches, model.RuleMatch{Transaction: tx, RuleID: rule.ID, Category: rule.Category})
+	}
+	return matches, nil
+}
+
+// ApplyRules runs the enabled rules of the account over its live
+// transactions and returns those whose category changes, with their current
+// category. Unless dryRun is set, the changes are written in one database
+// transaction, like a batch update.
+func (s *DefaultLedgerService) ApplyRules(ctx context.Context, accountID string, dryRun bool) ([]model.RuleMatch, error) {
+	matchers, err := s.ruleMatchers(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	live := s.ListTransactions(ctx, accountID)
+	refunded := refundedIDs(live)
+	now := time.Now().UTC()
+
+	matches := make([]model.RuleMatch, 0)
+	before := make([]model.Transaction, 0)
+	after := make([]model.Transaction, 0)
+	for _, tx := range live {
+		if !categorizable(tx) || refunded[tx.ID] {
+			continue
+		}
+		rule, ok := matchRule(matchers, tx)
+		if !ok || rule.Category == tx.Category {
+			continue
+		}
+		matches = append(matches, model.RuleMatch{Transaction: tx, RuleID: rule.ID, Category: rule.Category})
+		updated := tx
+		updated.Category = rule.Category
+		updated.UpdatedAt = now
+		before = append(before, tx)
+		after = append(after, updated)
+	}
+	if dryRun || len(after) == 0 {
+		return matches, nil
+	}
+
+	err = s.repo.RunInTx(ctx, func(repo repository.LedgerRepository) error {
+		if err := repo.UpdateTransactions(ctx, after); err != nil {
+			return err
+		}
+		if err := postJournal(ctx, repo, after); err != nil {
+			return err
+		}
+		return recordBatch(ctx, repo, model.AuditOperationUpdate, model.EventTransactionUpdated, before, after)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return matches, nil
+}
+
+// ruleMatcher is a rule prepared for matching.
+type ruleMatcher struct {
+	rule     model.CategoryRule
+	contains string
+	pattern  *regexp.Regexp
+}
+
+func compileRule(rule model.CategoryRule) (ruleMatcher, error) {
+	matcher := ruleMatcher{rule: rule, contains: strings.ToLower(rule.DescriptionContains)}
+	if rule.DescriptionPattern != "" {
+		pattern, err := regexp.Compile(rule.DescriptionPattern)
+		if err != nil {
+			return ruleMatcher{}, fmt.Errorf("rule %s: compile description pattern: %w", rule.ID, err)
+		}
+		matcher.pattern = pattern
+	}
+	return matcher, nil
+}
+
+func (m ruleMatcher) matches(tx model.Transaction) bool {
+	rule := m.rule
+	if rule.Currency != "" && rule.Currency != tx.Currency {
+		return false
+	}
+	switch rule.Type {
+	case model.RuleTypeIncome:
+		if tx.Amount <= 0 {
+			return false
+		}
+	case model.RuleTypeExpense:
+		if tx.Amount >= 0 {
+			return false
+		}
+	}
+	amount := math.Abs(tx.Amount)
+	if rule.MinAmount != nil && amount < *rule.MinAmount {
+		return false
+	}
+	if rule.MaxAmount != nil && amount > *rule.MaxAmount {
+		return false
+	}
+	if m.contains != "" && !strings.Contains(strings.ToLower(tx.Description), m.contains) {
+		return false
+	}
+	if m.pattern != nil && !m.pattern.MatchString(tx.Description) {
+		return false
+	}
+	return true
+}
+
+// ruleMatchers returns the enabled rules of the account in evaluation order.
+func (s *DefaultLedgerService) ruleMatchers(ctx context.Context, accountID string) ([]ruleMatcher, error) {
+	rules, err := s.repo.ListRules(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	matchers := make([]ruleMatcher, 0, len(rules))
+	for _, rule := range rules {
+		if !rule.Enabled {
+			continue
+		}
+		matcher, err := compileRule(rule)
+		if err != nil {
+			return nil, err
+		}
+		matchers = append(matchers, matcher)
+	}
+	return matchers, nil
+}
+
+func matchRule(matchers []ruleMatcher, tx model.Transaction) (model.CategoryRule, bool) {
+	for _, matcher := range matchers {
+		if matcher.matches(tx) {
+			return matcher.rule, true
+		}
+	}
+	return model.CategoryRule{}, false
+}
+
+// applyRules sets the category of a new transaction from the first matching
+// rule or, when no rule matches, from the default category of its payee. A
+// category the user chose is kept; only an empty one, or one that came from
+// an imported file, is replaced. It runs before budgets are checked, so the
+// budget of the assigned category applies. A transaction that is still
+// without a category is rejected.
+func applyRules(tx *model.Transaction, matchers []ruleMatcher, payees *payeeIndex, imported bool) error {
+	if categorizable(*tx) && (tx.Category == "" || imported) {
+		if rule, ok := matchRule(matchers, *tx); ok {
+			tx.Category = rule.Category
+		} else if category := payees.defaultCategory(*tx); category != "" {
+			tx.Category = category
+		}
+	}
+	if tx.Category == "" {
+		return fmt.Errorf("%w: category is required when no rule or payee sets it", ErrValidation)
+	}
+	return nil
+}
+
+// categorizable reports whether rules may set the category of a transaction.
+// Split transactions carry categories on their parts, while legs of
+// transfers and refunds take theirs from the transfer and the expense.
+func categorizable(tx model.Transaction) bool {
+	return len(tx.Splits) == 0 && tx.TransferID == "" && tx.RefundOf == ""
+}
+
+// refundedIDs returns the expenses that have refunds. Rules leave them alone,
+// since their refunds are tied to the category of the expense.
+func refundedIDs(transactions []model.Transaction) map[string]bool {
+	refunded := make(map[string]bool)
+	for _, tx := range transactions {
+		if tx.RefundOf != "" {
+			refunded[tx.RefundOf] = true
+		}
+	}
+	return refunded
+}
@@ -0,0 +1,20 @@
+package service
+
+import "github.com/Deevins/final-task-course-2-go-lang/ledger/internal/model"
+
+// transactionParts returns the category parts of a transaction: the parts of
+// a split transaction, or the whole amount in its category otherwise.
+func transactionParts(tx model.Transaction) []model.TransactionSplit {
+	if len(tx.Splits) > 0 {
+		return tx.Splits
+	}
+	return []model.TransactionSplit{{Category: tx.Category, Amount: tx.Amount}}
+}
+
+// applySplits gives a split transaction the split category, so that it is not
+// mistaken for a transaction in the category of one of its parts.
+func applySplits(tx *model.Transaction) {
+	if len(tx.Splits) > 0 {
+		tx.Category = model.CategorySplit
+	}
+}
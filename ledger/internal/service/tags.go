@@ -0,0 +1,166 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/model"
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/repository"
+)
+
+// CreateTag adds a tag to the account. Tag names are unique within an account.
+func (s *DefaultLedgerService) CreateTag(ctx context.Context, tag model.Tag) (model.Tag, error) {
+	tags, err := s.repo.ListTags(ctx, tag.AccountID)
+	if err != nil {
+		return model.Tag{}, err
+	}
+	if _, ok := findTag(tags, tag.Name); ok {
+		return model.Tag{}, fmt.Errorf("%w: %q", ErrTagExists, tag.Name)
+	}
+	now := time.Now().UTC()
+	if tag.ID == "" {
+		tag.ID = uuid.NewString()
+	}
+	tag.CreatedAt = now
+	tag.UpdatedAt = now
+	err = s.repo.RunInTx(ctx, func(repo repository.LedgerRepository) error {
+		return createTag(ctx, repo, tag)
+	})
+	if err != nil {
+		return model.Tag{}, err
+	}
+	return tag, nil
+}
+
+func (s *DefaultLedgerService) GetTag(ctx context.Context, accountID, id string) (model.Tag, error) {
+	return s.repo.GetTag(ctx, accountID, id)
+}
+
+// UpdateTag renames a tag together with all of its transactions. Reports
+// keep the tag names they were generated with.
+func (s *DefaultLedgerService) UpdateTag(ctx context.Context, tag model.Tag) (model.Tag, error) {
+	current, err := s.repo.GetTag(ctx, tag.AccountID, tag.ID)
+	if err != nil {
+		return model.Tag{}, err
+	}
+	tags, err := s.repo.ListTags(ctx, tag.AccountID)
+	if err != nil {
+		return model.Tag{}, err
+	}
+	if existing, ok := findTag(tags, tag.Name); ok && existing.ID != tag.ID {
+		return model.Tag{}, fmt.Errorf("%w: %q", ErrTagExists, tag.Name)
+	}
+	tag.CreatedAt = current.CreatedAt
+	tag.UpdatedAt = time.Now().UTC()
+	err = s.repo.RunInTx(ctx, func(repo repository.LedgerRepository) error {
+		if err := repo.UpdateTag(ctx, tag); err != nil {
+			return err
+		}
+		if err := appendAudit(ctx, repo, model.AuditEntityTag, tag.AccountID, tag.ID, model.AuditOperationUpdate, current, tag); err != nil {
+			return err
+		}
+		return appendEvent(ctx, repo, model.EventTagChanged, tag.AccountID, tag.ID, tagChangedPayload{Operation: changeOperationUpdated, Tag: tag})
+	})
+	if err != nil {
+		return model.Tag{}, err
+	}
+	return tag, nil
+}
+
+// DeleteTag removes a tag from the account and from all of its transactions.
+func (s *DefaultLedgerService) DeleteTag(ctx context.Context, accountID, id string) error {
+	return s.repo.RunInTx(ctx, func(repo repository.LedgerRepository) error {
+		current, err := repo.GetTag(ctx, accountID, id)
+		if err != nil {
+			return err
+		}
+		if err := repo.DeleteTag(ctx, accountID, id); err != nil {
+			return err
+		}
+		if err := appendAudit(ctx, repo, model.AuditEntityTag, current.AccountID, current.ID, model.AuditOperationDelete, current, nil); err != nil {
+			return err
+		}
+		return appendEvent(ctx, repo, model.EventTagChanged, current.AccountID, current.ID, tagChangedPayload{Operation: changeOperationDeleted, Tag: current})
+	})
+}
+
+// ListTags returns the tags of the account ordered by name.
+func (s *DefaultLedgerService) ListTags(ctx context.Context, accountID string) ([]model.Tag, error) {
+	return s.repo.ListTags(ctx, accountID)
+}
+
+func createTag(ctx context.Context, repo repository.LedgerRepository, tag model.Tag) error {
+	if err := repo.CreateTag(ctx, tag); err != nil {
+		return err
+	}
+	if err := appendAudit(ctx, repo, model.AuditEntityTag, tag.AccountID, tag.ID, model.AuditOperationCreate, nil, tag); err != nil {
+		return err
+	}
+	return appendEvent(ctx, repo, model.EventTagChanged, tag.AccountID, tag.ID, tagChangedPayload{Operation: changeOperationCreated, Tag: tag})
+}
+
+// ensureTags creates the tags of the transactions that the account does not
+// have yet, so that transactions can be tagged without creating tags first.
+// repo is expected to be bound to the transaction writing the transactions.
+func ensureTags(ctx context.Context, repo repository.LedgerRepository, accountID string, txs ...model.Transaction) error {
+	var names []string
+	for _, tx := range txs {
+		names = append(names, tx.Tags...)
+	}
+	if len(names) == 0 {
+		return nil
+	}
+	tags, err := repo.ListTags(ctx, accountID)
+	if err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	for _, name := range normalizeTags(names) {
+		if _, ok := findTag(tags, name); ok {
+			continue
+		}
+		tag := model.Tag{ID: uuid.NewString(), AccountID: accountID, Name: name, CreatedAt: now, UpdatedAt: now}
+		if err := createTag(ctx, repo, tag); err != nil {
+			return err
+		}
+		tags = append(tags, tag)
+	}
+	return nil
+}
+
+// applyTags trims the tags of a transaction, drops duplicates and sorts them.
+func applyTags(tx *model.Transaction) {
+	tx.Tags = normalizeTags(tx.Tags)
+}
+
+func normalizeTags(tags []string) []string {
+	if len(tags) == 0 {
+		return nil
+	}
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			normalized = append(normalized, tag)
+		}
+	}
+	slices.Sort(normalized)
+	normalized = slices.Compact(normalized)
+	if len(normalized) == 0 {
+		return nil
+	}
+	return normalized
+}
+
+func findTag(tags []model.Tag, name string) (model.Tag, bool) {
+	for _, tag := range tags {
+		if tag.Name == name {
+			return tag, true
+		}
+	}
+	return model.Tag{}, false
+}
@@ -0,0 +1,199 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/model"
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/repository"
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/storage"
+)
+
+func (s *DefaultLedgerService) ListTrash(ctx context.Context, accountID string) (model.Trash, error) {
+	return model.Trash{
+		Transactions: s.repo.ListDeletedTransactions(ctx, accountID),
+		Budgets:      s.repo.ListDeletedBudgets(ctx, accountID),
+		Reports:      s.repo.ListDeletedReports(ctx, accountID),
+	}, nil
+}
+
+// RestoreTransaction moves a transaction out of the trash. Restoring an expense
+// is subject to the same budget check as creating it, and restoring a refund
+// to the same limits as refunding. Restoring a leg of a transfer restores the
+// whole transfer.
+func (s *DefaultLedgerService) RestoreTransaction(ctx context.Context, accountID, id string) (model.Transaction, error) {
+	trashed := s.repo.ListDeletedTransactions(ctx, accountID)
+	deleted, err := findDeletedTransaction(trashed, id)
+	if err != nil {
+		return model.Transaction{}, err
+	}
+	if deleted.TransferID != "" {
+		return s.restoreTransfer(ctx, accountID, deleted, trashed)
+	}
+	if deleted.RefundOf != "" {
+		if err := s.ensureRefundRestorable(ctx, accountID, deleted); err != nil {
+			return model.Transaction{}, err
+		}
+	}
+	if err := s.ensureBudgetAvailable(ctx, deleted); err != nil {
+		return model.Transaction{}, err
+	}
+	var restored model.Transaction
+	err = s.repo.RunInTx(ctx, func(repo repository.LedgerRepository) error {
+		var err error
+		restored, err = repo.RestoreTransaction(ctx, accountID, id)
+		if err != nil {
+			return err
+		}
+		if err := postJournal(ctx, repo, []model.Transaction{restored}); err != nil {
+			return err
+		}
+		if err := appendAudit(ctx, repo, model.AuditEntityTransaction, restored.AccountID, restored.ID, model.AuditOperationRestore, nil, restored); err != nil {
+			return err
+		}
+		return appendEvent(ctx, repo, model.EventTransactionRestored, restored.AccountID, restored.ID, restored)
+	})
+	if err != nil {
+		return model.Transaction{}, err
+	}
+	return restored, nil
+}
+
+// ensureRefundRestorable checks that the expense of a trashed refund is live
+// and has room for the refund again.
+func (s *DefaultLedgerService) ensureRefundRestorable(ctx context.Context, accountID string, refund model.Transaction) error {
+	live := s.ListTransactions(ctx, accountID)
+	original, ok := transactionsByID(live)[refund.RefundOf]
+	if !ok {
+		return fmt.Errorf("%w: restore the refunded transaction %s first", ErrRefundLinked, refund.RefundOf)
+	}
+	return checkRefunds(original, append(refundsOf(live, original.ID), refund))
+}
+
+// restoreTransfer restores both legs of the transfer of leg and returns leg.
+func (s *DefaultLedgerService) restoreTransfer(ctx context.Context, accountID string, leg model.Transaction, trashed []model.Transaction) (model.Transaction, error) {
+	var restored model.Transaction
+	err := s.repo.RunInTx(ctx, func(repo repository.LedgerRepository) error {
+		for _, tx := range transferLegs(trashed, leg.TransferID) {
+			tx, err := repo.RestoreTransaction(ctx, accountID, tx.ID)
+			if err != nil {
+				return err
+			}
+			if err := postJournal(ctx, repo, []model.Transaction{tx}); err != nil {
+				return err
+			}
+			if err := appendAudit(ctx, repo, model.AuditEntityTransaction, tx.AccountID, tx.ID, model.AuditOperationRestore, nil, tx); err != nil {
+				return err
+			}
+			if err := appendEvent(ctx, repo, model.EventTransactionRestored, tx.AccountID, tx.ID, tx); err != nil {
+				return err
+			}
+			if tx.ID == leg.ID {
+				restored = tx
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return model.Transaction{}, err
+	}
+	return restored, nil
+}
+
+// RestoreBudget moves a budget out of the trash unless a live budget with the
+// same name and month has been created in the meantime.
+func (s *DefaultLedgerService) RestoreBudget(ctx context.Context, accountID, id string) (model.Budget, error) {
+	deleted, err := findDeletedBudget(s.repo.ListDeletedBudgets(ctx, accountID), id)
+	if err != nil {
+		return model.Budget{}, err
+	}
+	for _, budget := range s.repo.ListBudgets(ctx, accountID) {
+		if budget.Name == deleted.Name && budget.Month.Equal(deleted.Month) {
+			return model.Budget{}, fmt.Errorf("%w: %q for %s", ErrBudgetExists, deleted.Name, deleted.Month.Format("2006-01"))
+		}
+	}
+	var restored model.Budget
+	err = s.repo.RunInTx(ctx, func(repo repository.LedgerRepository) error {
+		var err error
+		restored, err = repo.RestoreBudget(ctx, accountID, id)
+		if err != nil {
+			return err
+		}
+		if err := appendAudit(ctx, repo, model.AuditEntityBudget, restored.AccountID, restored.ID, model.AuditOperationRestore, nil, restored); err != nil {
+			return err
+		}
+		return appendEvent(ctx, repo, model.EventBudgetChanged, restored.AccountID, restored.ID, budgetChangedPayload{Operation: changeOperationRestored, Budget: restored})
+	})
+	if err != nil {
+		return model.Budget{}, err
+	}
+	s.invalidateBudgetCache(ctx)
+	return restored, nil
+}
+
+func (s *DefaultLedgerService) RestoreReport(ctx context.Context, accountID, id string) (model.Report, error) {
+	var restored model.Report
+	err := s.repo.RunInTx(ctx, func(repo repository.LedgerRepository) error {
+		var err error
+		restored, err = repo.RestoreReport(ctx, accountID, id)
+		if err != nil {
+			return err
+		}
+		return appendAudit(ctx, repo, model.AuditEntityReport, restored.AccountID, restored.ID, model.AuditOperationRestore, nil, restored)
+	})
+	if err != nil {
+		return model.Report{}, err
+	}
+	return restored, nil
+}
+
+// PurgeDeleted permanently removes every entity that has been in the trash since before the given time.
+// The attachments of purged transactions are removed from the blob store after the commit.
+func (s *DefaultLedgerService) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	var purged int64
+	var attachments []model.Attachment
+	err := s.repo.RunInTx(ctx, func(repo repository.LedgerRepository) error {
+		var err error
+		attachments, err = repo.ListExpiredAttachments(ctx, before)
+		if err != nil {
+			return err
+		}
+		purgers := []func(context.Context, time.Time) (int64, error){
+			repo.PurgeDeletedTransactions,
+			repo.PurgeDeletedBudgets,
+			repo.PurgeDeletedReports,
+		}
+		for _, purge := range purgers {
+			count, err := purge(ctx, before)
+			if err != nil {
+				return err
+			}
+			purged += count
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	s.deleteBlobs(ctx, attachments...)
+	return purged, nil
+}
+
+func findDeletedTransaction(items []model.Transaction, id string) (model.Transaction, error) {
+	for _, tx := range items {
+		if tx.ID == id {
+			return tx, nil
+		}
+	}
+	return model.Transaction{}, storage.ErrNotFound
+}
+
+func findDeletedBudget(items []model.Budget, id string) (model.Budget, error) {
+	for _, budget := range items {
+		if budget.ID == id {
+			return budget, nil
+		}
+	}
+	return model.Budget{}, storage.ErrNotFound
+}
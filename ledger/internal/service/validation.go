@@ -3,11 +3,44 @@ package service
 import (
 	"context"
 	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"strings"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/actor"
 	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/model"
 )
 
+const (
+	maxEventsPageSize = 1000
+	// MaxBatchSize limits the number of items accepted by a single batch call.
+	MaxBatchSize = 500
+
+	maxSearchPageSize    = 1000
+	maxSearchQueryLength = 200
+
+	maxAccountNameLength = 100
+	maxWalletNameLength  = 100
+	maxTagNameLength     = 50
+	// maxTransactionTags limits the number of tags of a single transaction.
+	maxTransactionTags = 20
+	// maxFileNameLength limits the name of an attachment.
+	maxFileNameLength = 255
+	maxRuleNameLength = 100
+	// maxRulePatternLength limits the description conditions of a rule.
+	maxRulePatternLength = 500
+	maxPayeeNameLength   = 100
+	// maxPayeeAliases limits the number of aliases of a single payee.
+	maxPayeeAliases   = 20
+	maxGoalNameLength = 100
+	// maxBalancePoints limits a balance history to a year of days.
+	maxBalancePoints = 366
+)
+
 type ValidationService struct {
 	next LedgerService
 }
@@ -48,6 +81,134 @@ func (s *ValidationService) ListTransactions(ctx context.Context, accountID stri
 	return s.next.ListTransactions(ctx, accountID)
 }
 
+func (s *ValidationService) SearchTransactions(ctx context.Context, filter model.TransactionFilter) ([]model.Transaction, error) {
+	if filter.AccountID == "" {
+		return nil, fmt.Errorf("%w: account id is required", ErrValidation)
+	}
+	if !filter.From.IsZero() && !filter.To.IsZero() && filter.To.Before(filter.From) {
+		return nil, fmt.Errorf("%w: search end before start", ErrValidation)
+	}
+	if filter.MinAmount != nil && *filter.MinAmount < 0 {
+		return nil, fmt.Errorf("%w: min amount must not be negative", ErrValidation)
+	}
+	if filter.MinAmount != nil && filter.MaxAmount != nil && *filter.MaxAmount < *filter.MinAmount {
+		return nil, fmt.Errorf("%w: max amount is less than min amount", ErrValidation)
+	}
+	if len(filter.Query) > maxSearchQueryLength {
+		return nil, fmt.Errorf("%w: search query must not exceed %d characters", ErrValidation, maxSearchQueryLength)
+	}
+	if utf8.RuneCountInString(filter.Tag) > maxTagNameLength {
+		return nil, fmt.Errorf("%w: tag must not exceed %d characters", ErrValidation, maxTagNameLength)
+	}
+	switch filter.Sort {
+	case "", model.SortOccurredAtAsc, model.SortOccurredAtDesc, model.SortAmountAsc, model.SortAmountDesc:
+	default:
+		return nil, fmt.Errorf("%w: unsupported sort %q", ErrValidation, filter.Sort)
+	}
+	if filter.Limit < 0 || filter.Limit > maxSearchPageSize {
+		return nil, fmt.Errorf("%w: limit must be between 0 and %d", ErrValidation, maxSearchPageSize)
+	}
+	if filter.Offset < 0 {
+		return nil, fmt.Errorf("%w: offset must not be negative", ErrValidation)
+	}
+	return s.next.SearchTransactions(ctx, filter)
+}
+
+func (s *ValidationService) BatchCreateTransactions(ctx context.Context, accountID string, items []model.Transaction, atomic bool) ([]model.BatchResult, error) {
+	if err := validateBatchSize(accountID, len(items)); err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool, len(items))
+	return validateBatch(items, atomic, func(tx model.Transaction) error {
+		if tx.AccountID == "" {
+			tx.AccountID = accountID
+		}
+		if tx.AccountID != accountID {
+			return fmt.Errorf("%w: transaction belongs to another account", ErrValidation)
+		}
+		if err := validateTransaction(tx, false); err != nil {
+			return err
+		}
+		if tx.ID == "" {
+			return nil
+		}
+		if seen[tx.ID] {
+			return fmt.Errorf("%w: duplicate transaction id %s", ErrValidation, tx.ID)
+		}
+		seen[tx.ID] = true
+		return nil
+	}, func(valid []model.Transaction) ([]model.BatchResult, error) {
+		return s.next.BatchCreateTransactions(ctx, accountID, valid, atomic)
+	})
+}
+
+func (s *ValidationService) BatchUpdateTransactions(ctx context.Context, accountID string, items []model.Transaction, atomic bool) ([]model.BatchResult, error) {
+	if err := validateBatchSize(accountID, len(items)); err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool, len(items))
+	return validateBatch(items, atomic, func(tx model.Transaction) error {
+		if tx.AccountID == "" {
+			tx.AccountID = accountID
+		}
+		if tx.AccountID != accountID {
+			return fmt.Errorf("%w: transaction belongs to another account", ErrValidation)
+		}
+		if err := validateTransaction(tx, true); err != nil {
+			return err
+		}
+		if seen[tx.ID] {
+			return fmt.Errorf("%w: duplicate transaction id %s", ErrValidation, tx.ID)
+		}
+		seen[tx.ID] = true
+		return nil
+	}, func(valid []model.Transaction) ([]model.BatchResult, error) {
+		return s.next.BatchUpdateTransactions(ctx, accountID, valid, atomic)
+	})
+}
+
+func (s *ValidationService) BatchDeleteTransactions(ctx context.Context, accountID string, ids []string, atomic bool) ([]model.BatchResult, error) {
+	if err := validateBatchSize(accountID, len(ids)); err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool, len(ids))
+	return validateBatch(ids, atomic, func(id string) error {
+		if id == "" {
+			return fmt.Errorf("%w: transaction id is required", ErrValidation)
+		}
+		if seen[id] {
+			return fmt.Errorf("%w: duplicate transaction id %s", ErrValidation, id)
+		}
+		seen[id] = true
+		return nil
+	}, func(valid []string) ([]model.BatchResult, error) {
+		return s.next.BatchDeleteTransactions(ctx, accountID, valid, atomic)
+	})
+}
+
+func (s *ValidationService) RefundTransaction(ctx context.Context, refund model.Refund) (model.Transaction, error) {
+	if refund.AccountID == "" {
+		return model.Transaction{}, fmt.Errorf("%w: account id is required", ErrValidation)
+	}
+	if refund.TransactionID == "" {
+		return model.Transaction{}, fmt.Errorf("%w: transaction id is required", ErrValidation)
+	}
+	if refund.Amount <= 0 {
+		return model.Transaction{}, fmt.Errorf("%w: refund amount must be positive", ErrValidation)
+	}
+	return s.next.RefundTransaction(ctx, refund)
+}
+
+func (s *ValidationService) ListRefunds(ctx context.Context, accountID, transactionID string) ([]model.Transaction, error) {
+	if accountID == "" {
+		return nil, fmt.Errorf("%w: account id is required", ErrValidation)
+	}
+	if transactionID == "" {
+		return nil, fmt.Errorf("%w: transaction id is required", ErrValidation)
+	}
+	return s.next.ListRefunds(ctx, accountID, transactionID)
+}
+
 func (s *ValidationService) CreateBudget(ctx context.Context, budget model.Budget) (model.Budget, error) {
 	if err := validateBudget(budget, false); err != nil {
 		return model.Budget{}, err
@@ -86,6 +247,336 @@ func (s *ValidationService) ListBudgets(ctx context.Context, accountID string) [
 	return s.next.ListBudgets(ctx, accountID)
 }
 
+func (s *ValidationService) CreateWallet(ctx context.Context, wallet model.Wallet) (model.Wallet, error) {
+	if err := validateWallet(&wallet, false); err != nil {
+		return model.Wallet{}, err
+	}
+	return s.next.CreateWallet(ctx, wallet)
+}
+
+func (s *ValidationService) GetWallet(ctx context.Context, accountID, id string) (model.Wallet, error) {
+	if accountID == "" {
+		return model.Wallet{}, fmt.Errorf("%w: account id is required", ErrValidation)
+	}
+	if id == "" {
+		return model.Wallet{}, fmt.Errorf("%w: wallet id is required", ErrValidation)
+	}
+	return s.next.GetWallet(ctx, accountID, id)
+}
+
+func (s *ValidationService) UpdateWallet(ctx context.Context, wallet model.Wallet) (model.Wallet, error) {
+	if err := validateWallet(&wallet, true); err != nil {
+		return model.Wallet{}, err
+	}
+	return s.next.UpdateWallet(ctx, wallet)
+}
+
+func (s *ValidationService) DeleteWallet(ctx context.Context, accountID, id string) error {
+	if accountID == "" {
+		return fmt.Errorf("%w: account id is required", ErrValidation)
+	}
+	if id == "" {
+		return fmt.Errorf("%w: wallet id is required", ErrValidation)
+	}
+	return s.next.DeleteWallet(ctx, accountID, id)
+}
+
+func (s *ValidationService) ListWallets(ctx context.Context, accountID string) ([]model.Wallet, error) {
+	if accountID == "" {
+		return nil, fmt.Errorf("%w: account id is required", ErrValidation)
+	}
+	return s.next.ListWallets(ctx, accountID)
+}
+
+func (s *ValidationService) CreateTransfer(ctx context.Context, transfer model.Transfer) (model.Transfer, error) {
+	if transfer.AccountID == "" {
+		return model.Transfer{}, fmt.Errorf("%w: account id is required", ErrValidation)
+	}
+	if transfer.FromWalletID == "" || transfer.ToWalletID == "" {
+		return model.Transfer{}, fmt.Errorf("%w: source and destination wallets are required", ErrValidation)
+	}
+	if transfer.FromWalletID == transfer.ToWalletID {
+		return model.Transfer{}, fmt.Errorf("%w: transfer needs two different wallets", ErrValidation)
+	}
+	if transfer.Amount <= 0 {
+		return model.Transfer{}, fmt.Errorf("%w: amount must be positive", ErrValidation)
+	}
+	if transfer.ToAmount < 0 {
+		return model.Transfer{}, fmt.Errorf("%w: to amount must not be negative", ErrValidation)
+	}
+	return s.next.CreateTransfer(ctx, transfer)
+}
+
+func (s *ValidationService) DeleteTransfer(ctx context.Context, accountID, id string) error {
+	if accountID == "" {
+		return fmt.Errorf("%w: account id is required", ErrValidation)
+	}
+	if id == "" {
+		return fmt.Errorf("%w: transfer id is required", ErrValidation)
+	}
+	return s.next.DeleteTransfer(ctx, accountID, id)
+}
+
+func (s *ValidationService) GetWalletBalanceHistory(ctx context.Context, accountID, walletID string, from, to time.Time, step string) ([]model.BalancePoint, error) {
+	if accountID == "" {
+		return nil, fmt.Errorf("%w: account id is required", ErrValidation)
+	}
+	if walletID == "" {
+		return nil, fmt.Errorf("%w: wallet id is required", ErrValidation)
+	}
+	if from.IsZero() || to.IsZero() {
+		return nil, fmt.Errorf("%w: balance history dates are required", ErrValidation)
+	}
+	if to.Before(from) {
+		return nil, fmt.Errorf("%w: balance history end before start", ErrValidation)
+	}
+	switch step {
+	case "":
+		step = model.BalanceStepDay
+	case model.BalanceStepDay, model.BalanceStepMonth:
+	default:
+		return nil, fmt.Errorf("%w: unsupported step %q", ErrValidation, step)
+	}
+	if countBalancePeriods(from, to, step) > maxBalancePoints {
+		return nil, fmt.Errorf("%w: balance history must not exceed %d points", ErrValidation, maxBalancePoints)
+	}
+	return s.next.GetWalletBalanceHistory(ctx, accountID, walletID, from, to, step)
+}
+
+func (s *ValidationService) CreateTag(ctx context.Context, tag model.Tag) (model.Tag, error) {
+	if err := validateTag(&tag, false); err != nil {
+		return model.Tag{}, err
+	}
+	return s.next.CreateTag(ctx, tag)
+}
+
+func (s *ValidationService) GetTag(ctx context.Context, accountID, id string) (model.Tag, error) {
+	if accountID == "" {
+		return model.Tag{}, fmt.Errorf("%w: account id is required", ErrValidation)
+	}
+	if id == "" {
+		return model.Tag{}, fmt.Errorf("%w: tag id is required", ErrValidation)
+	}
+	return s.next.GetTag(ctx, accountID, id)
+}
+
+func (s *ValidationService) UpdateTag(ctx context.Context, tag model.Tag) (model.Tag, error) {
+	if err := validateTag(&tag, true); err != nil {
+		return model.Tag{}, err
+	}
+	return s.next.UpdateTag(ctx, tag)
+}
+
+func (s *ValidationService) DeleteTag(ctx context.Context, accountID, id string) error {
+	if accountID == "" {
+		return fmt.Errorf("%w: account id is required", ErrValidation)
+	}
+	if id == "" {
+		return fmt.Errorf("%w: tag id is required", ErrValidation)
+	}
+	return s.next.DeleteTag(ctx, accountID, id)
+}
+
+func (s *ValidationService) ListTags(ctx context.Context, accountID string) ([]model.Tag, error) {
+	if accountID == "" {
+		return nil, fmt.Errorf("%w: account id is required", ErrValidation)
+	}
+	return s.next.ListTags(ctx, accountID)
+}
+
+func (s *ValidationService) UploadAttachment(ctx context.Context, attachment model.Attachment, content io.Reader) (model.Attachment, error) {
+	if attachment.AccountID == "" {
+		return model.Attachment{}, fmt.Errorf("%w: account id is required", ErrValidation)
+	}
+	if attachment.TransactionID == "" {
+		return model.Attachment{}, fmt.Errorf("%w: transaction id is required", ErrValidation)
+	}
+	if content == nil {
+		return model.Attachment{}, fmt.Errorf("%w: attachment content is required", ErrValidation)
+	}
+	attachment.FileName = strings.TrimSpace(attachment.FileName)
+	if err := validateFileName(attachment.FileName); err != nil {
+		return model.Attachment{}, err
+	}
+	return s.next.UploadAttachment(ctx, attachment, content)
+}
+
+func (s *ValidationService) GetAttachment(ctx context.Context, accountID, id string) (model.Attachment, error) {
+	if err := validateAttachmentRef(accountID, id); err != nil {
+		return model.Attachment{}, err
+	}
+	return s.next.GetAttachment(ctx, accountID, id)
+}
+
+func (s *ValidationService) OpenAttachment(ctx context.Context, accountID, id string) (model.Attachment, io.ReadCloser, error) {
+	if err := validateAttachmentRef(accountID, id); err != nil {
+		return model.Attachment{}, nil, err
+	}
+	return s.next.OpenAttachment(ctx, accountID, id)
+}
+
+func (s *ValidationService) ListAttachments(ctx context.Context, accountID, transactionID string) ([]model.Attachment, error) {
+	if accountID == "" {
+		return nil, fmt.Errorf("%w: account id is required", ErrValidation)
+	}
+	if transactionID == "" {
+		return nil, fmt.Errorf("%w: transaction id is required", ErrValidation)
+	}
+	return s.next.ListAttachments(ctx, accountID, transactionID)
+}
+
+func (s *ValidationService) DeleteAttachment(ctx context.Context, accountID, id string) error {
+	if err := validateAttachmentRef(accountID, id); err != nil {
+		return err
+	}
+	return s.next.DeleteAttachment(ctx, accountID, id)
+}
+
+func (s *ValidationService) CreateRule(ctx context.Context, rule model.CategoryRule) (model.CategoryRule, error) {
+	if err := validateRule(&rule, false); err != nil {
+		return model.CategoryRule{}, err
+	}
+	return s.next.CreateRule(ctx, rule)
+}
+
+func (s *ValidationService) GetRule(ctx context.Context, accountID, id string) (model.CategoryRule, error) {
+	if err := validateRuleRef(accountID, id); err != nil {
+		return model.CategoryRule{}, err
+	}
+	return s.next.GetRule(ctx, accountID, id)
+}
+
+func (s *ValidationService) UpdateRule(ctx context.Context, rule model.CategoryRule) (model.CategoryRule, error) {
+	if err := validateRule(&rule, true); err != nil {
+		return model.CategoryRule{}, err
+	}
+	return s.next.UpdateRule(ctx, rule)
+}
+
+func (s *ValidationService) DeleteRule(ctx context.Context, accountID, id string) error {
+	if err := validateRuleRef(accountID, id); err != nil {
+		return err
+	}
+	return s.next.DeleteRule(ctx, accountID, id)
+}
+
+func (s *ValidationService) ListRules(ctx context.Context, accountID string) ([]model.CategoryRule, error) {
+	if accountID == "" {
+		return nil, fmt.Errorf("%w: account id is required", ErrValidation)
+	}
+	return s.next.ListRules(ctx, accountID)
+}
+
+// PreviewRule checks the conditions and the category of the rule; an unsaved
+// rule needs no name.
+func (s *ValidationService) PreviewRule(ctx context.Context, rule model.CategoryRule) ([]model.RuleMatch, error) {
+	if rule.AccountID == "" {
+		return nil, fmt.Errorf("%w: account id is required", ErrValidation)
+	}
+	if err := validateRuleConditions(&rule); err != nil {
+		return nil, err
+	}
+	return s.next.PreviewRule(ctx, rule)
+}
+
+func (s *ValidationService) ApplyRules(ctx context.Context, accountID string, dryRun bool) ([]model.RuleMatch, error) {
+	if accountID == "" {
+		return nil, fmt.Errorf("%w: account id is required", ErrValidation)
+	}
+	return s.next.ApplyRules(ctx, accountID, dryRun)
+}
+
+func (s *ValidationService) CreatePayee(ctx context.Context, payee model.Payee) (model.Payee, error) {
+	if err := validatePayee(&payee, false); err != nil {
+		return model.Payee{}, err
+	}
+	return s.next.CreatePayee(ctx, payee)
+}
+
+func (s *ValidationService) GetPayee(ctx context.Context, accountID, id string) (model.Payee, error) {
+	if err := validatePayeeRef(accountID, id); err != nil {
+		return model.Payee{}, err
+	}
+	return s.next.GetPayee(ctx, accountID, id)
+}
+
+func (s *ValidationService) UpdatePayee(ctx context.Context, payee model.Payee) (model.Payee, error) {
+	if err := validatePayee(&payee, true); err != nil {
+		return model.Payee{}, err
+	}
+	return s.next.UpdatePayee(ctx, payee)
+}
+
+func (s *ValidationService) DeletePayee(ctx context.Context, accountID, id string) error {
+	if err := validatePayeeRef(accountID, id); err != nil {
+		return err
+	}
+	return s.next.DeletePayee(ctx, accountID, id)
+}
+
+func (s *ValidationService) ListPayees(ctx context.Context, accountID string) ([]model.Payee, error) {
+	if accountID == "" {
+		return nil, fmt.Errorf("%w: account id is required", ErrValidation)
+	}
+	return s.next.ListPayees(ctx, accountID)
+}
+
+func (s *ValidationService) CreateGoal(ctx context.Context, goal model.Goal) (model.Goal, error) {
+	if err := validateGoal(&goal, false); err != nil {
+		return model.Goal{}, err
+	}
+	return s.next.CreateGoal(ctx, goal)
+}
+
+func (s *ValidationService) GetGoal(ctx context.Context, accountID, id string) (model.Goal, error) {
+	if err := validateGoalRef(accountID, id); err != nil {
+		return model.Goal{}, err
+	}
+	return s.next.GetGoal(ctx, accountID, id)
+}
+
+func (s *ValidationService) UpdateGoal(ctx context.Context, goal model.Goal) (model.Goal, error) {
+	if err := validateGoal(&goal, true); err != nil {
+		return model.Goal{}, err
+	}
+	return s.next.UpdateGoal(ctx, goal)
+}
+
+func (s *ValidationService) DeleteGoal(ctx context.Context, accountID, id string) error {
+	if err := validateGoalRef(accountID, id); err != nil {
+		return err
+	}
+	return s.next.DeleteGoal(ctx, accountID, id)
+}
+
+func (s *ValidationService) ListGoals(ctx context.Context, accountID string) ([]model.Goal, error) {
+	if accountID == "" {
+		return nil, fmt.Errorf("%w: account id is required", ErrValidation)
+	}
+	return s.next.ListGoals(ctx, accountID)
+}
+
+func (s *ValidationService) ListJournalEntries(ctx context.Context, accountID string) ([]model.JournalEntry, error) {
+	if accountID == "" {
+		return nil, fmt.Errorf("%w: account id is required", ErrValidation)
+	}
+	return s.next.ListJournalEntries(ctx, accountID)
+}
+
+func (s *ValidationService) GetTrialBalance(ctx context.Context, accountID string) (model.TrialBalance, error) {
+	if accountID == "" {
+		return model.TrialBalance{}, fmt.Errorf("%w: account id is required", ErrValidation)
+	}
+	return s.next.GetTrialBalance(ctx, accountID)
+}
+
+func (s *ValidationService) VerifyJournal(ctx context.Context, accountID string) error {
+	if accountID == "" {
+		return fmt.Errorf("%w: account id is required", ErrValidation)
+	}
+	return s.next.VerifyJournal(ctx, accountID)
+}
+
 func (s *ValidationService) CreateReport(ctx context.Context, report model.Report) (model.Report, error) {
 	if report.AccountID == "" {
 		return model.Report{}, fmt.Errorf("%w: account id is required", ErrValidation)
@@ -166,6 +657,251 @@ func (s *ValidationService) GetReportSummary(ctx context.Context, accountID stri
 	return s.next.GetReportSummary(ctx, accountID, from, to)
 }
 
+func (s *ValidationService) ListEvents(ctx context.Context, accountID string, afterOffset int64, limit int) ([]model.Event, error) {
+	if accountID == "" {
+		return nil, fmt.Errorf("%w: account id is required", ErrValidation)
+	}
+	if afterOffset < 0 {
+		return nil, fmt.Errorf("%w: offset must not be negative", ErrValidation)
+	}
+	if limit <= 0 || limit > maxEventsPageSize {
+		return nil, fmt.Errorf("%w: limit must be between 1 and %d", ErrValidation, maxEventsPageSize)
+	}
+	return s.next.ListEvents(ctx, accountID, afterOffset, limit)
+}
+
+func (s *ValidationService) ListHistory(ctx context.Context, accountID, entityType, entityID string) ([]model.AuditEntry, error) {
+	if accountID == "" {
+		return nil, fmt.Errorf("%w: account id is required", ErrValidation)
+	}
+	switch entityType {
+	case model.AuditEntityTransaction, model.AuditEntityBudget, model.AuditEntityReport, model.AuditEntityWallet, model.AuditEntityTag, model.AuditEntityAttachment, model.AuditEntityRule, model.AuditEntityPayee,
+		model.AuditEntityGoal:
+	case model.AuditEntityMember:
+	case model.AuditEntityAccount:
+		if entityID != accountID {
+			return nil, fmt.Errorf("%w: account history entity id must be the account id", ErrValidation)
+		}
+	default:
+		return nil, fmt.Errorf("%w: unsupported entity type %q", ErrValidation, entityType)
+	}
+	if entityID == "" {
+		return nil, fmt.Errorf("%w: entity id is required", ErrValidation)
+	}
+	return s.next.ListHistory(ctx, accountID, entityType, entityID)
+}
+
+func (s *ValidationService) AuthorizeRead(ctx context.Context, accountID, resource string) error {
+	return s.next.AuthorizeRead(ctx, accountID, resource)
+}
+
+func (s *ValidationService) AuthorizeWrite(ctx context.Context, accountID string) error {
+	return s.next.AuthorizeWrite(ctx, accountID)
+}
+
+func (s *ValidationService) CreateAccount(ctx context.Context, name string) (model.Account, error) {
+	if err := validateActor(ctx); err != nil {
+		return model.Account{}, err
+	}
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return model.Account{}, fmt.Errorf("%w: account name is required", ErrValidation)
+	}
+	if utf8.RuneCountInString(name) > maxAccountNameLength {
+		return model.Account{}, fmt.Errorf("%w: account name must not exceed %d characters", ErrValidation, maxAccountNameLength)
+	}
+	return s.next.CreateAccount(ctx, name)
+}
+
+func (s *ValidationService) ListAccounts(ctx context.Context) ([]model.Account, error) {
+	if err := validateActor(ctx); err != nil {
+		return nil, err
+	}
+	return s.next.ListAccounts(ctx)
+}
+
+func (s *ValidationService) ListMembers(ctx context.Context, accountID string) ([]model.AccountMember, error) {
+	if accountID == "" {
+		return nil, fmt.Errorf("%w: account id is required", ErrValidation)
+	}
+	return s.next.ListMembers(ctx, accountID)
+}
+
+func (s *ValidationService) UpdateMember(ctx context.Context, accountID, userID, role string) (model.AccountMember, error) {
+	if err := validateMember(accountID, userID); err != nil {
+		return model.AccountMember{}, err
+	}
+	if err := validateMemberRole(role); err != nil {
+		return model.AccountMember{}, err
+	}
+	return s.next.UpdateMember(ctx, accountID, userID, role)
+}
+
+func (s *ValidationService) RemoveMember(ctx context.Context, accountID, userID string) error {
+	if err := validateMember(accountID, userID); err != nil {
+		return err
+	}
+	return s.next.RemoveMember(ctx, accountID, userID)
+}
+
+func (s *ValidationService) CreateInvitation(ctx context.Context, accountID, role string) (model.Invitation, string, error) {
+	if accountID == "" {
+		return model.Invitation{}, "", fmt.Errorf("%w: account id is required", ErrValidation)
+	}
+	if err := validateMemberRole(role); err != nil {
+		return model.Invitation{}, "", err
+	}
+	return s.next.CreateInvitation(ctx, accountID, role)
+}
+
+func (s *ValidationService) ListInvitations(ctx context.Context, accountID string) ([]model.Invitation, error) {
+	if accountID == "" {
+		return nil, fmt.Errorf("%w: account id is required", ErrValidation)
+	}
+	return s.next.ListInvitations(ctx, accountID)
+}
+
+func (s *ValidationService) RevokeInvitation(ctx context.Context, accountID, id string) error {
+	if accountID == "" {
+		return fmt.Errorf("%w: account id is required", ErrValidation)
+	}
+	if id == "" {
+		return fmt.Errorf("%w: invitation id is required", ErrValidation)
+	}
+	return s.next.RevokeInvitation(ctx, accountID, id)
+}
+
+func (s *ValidationService) AcceptInvitation(ctx context.Context, token string) (model.AccountMember, error) {
+	if err := validateActor(ctx); err != nil {
+		return model.AccountMember{}, err
+	}
+	if token == "" {
+		return model.AccountMember{}, fmt.Errorf("%w: invitation token is required", ErrValidation)
+	}
+	return s.next.AcceptInvitation(ctx, token)
+}
+
+func (s *ValidationService) ListTrash(ctx context.Context, accountID string) (model.Trash, error) {
+	if accountID == "" {
+		return model.Trash{}, fmt.Errorf("%w: account id is required", ErrValidation)
+	}
+	return s.next.ListTrash(ctx, accountID)
+}
+
+func (s *ValidationService) RestoreTransaction(ctx context.Context, accountID, id string) (model.Transaction, error) {
+	if accountID == "" {
+		return model.Transaction{}, fmt.Errorf("%w: account id is required", ErrValidation)
+	}
+	if id == "" {
+		return model.Transaction{}, fmt.Errorf("%w: transaction id is required", ErrValidation)
+	}
+	return s.next.RestoreTransaction(ctx, accountID, id)
+}
+
+func (s *ValidationService) RestoreBudget(ctx context.Context, accountID, id string) (model.Budget, error) {
+	if accountID == "" {
+		return model.Budget{}, fmt.Errorf("%w: account id is required", ErrValidation)
+	}
+	if id == "" {
+		return model.Budget{}, fmt.Errorf("%w: budget id is required", ErrValidation)
+	}
+	return s.next.RestoreBudget(ctx, accountID, id)
+}
+
+func (s *ValidationService) RestoreReport(ctx context.Context, accountID, id string) (model.Report, error) {
+	if accountID == "" {
+		return model.Report{}, fmt.Errorf("%w: account id is required", ErrValidation)
+	}
+	if id == "" {
+		return model.Report{}, fmt.Errorf("%w: report id is required", ErrValidation)
+	}
+	return s.next.RestoreReport(ctx, accountID, id)
+}
+
+func (s *ValidationService) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	if before.IsZero() {
+		return 0, fmt.Errorf("%w: purge cutoff is required", ErrValidation)
+	}
+	return s.next.PurgeDeleted(ctx, before)
+}
+
+func (s *ValidationService) PurgeAccount(ctx context.Context, accountID string) (int64, error) {
+	if accountID == "" {
+		return 0, fmt.Errorf("%w: account id is required", ErrValidation)
+	}
+	return s.next.PurgeAccount(ctx, accountID)
+}
+
+// validateActor requires a user behind the call, for operations that act on
+// behalf of the user rather than on an account.
+func validateActor(ctx context.Context) error {
+	if actor.UserIDFromContext(ctx) == "" {
+		return fmt.Errorf("%w: user id is required", ErrValidation)
+	}
+	return nil
+}
+
+func validateMember(accountID, userID string) error {
+	if accountID == "" {
+		return fmt.Errorf("%w: account id is required", ErrValidation)
+	}
+	if userID == "" {
+		return fmt.Errorf("%w: user id is required", ErrValidation)
+	}
+	return nil
+}
+
+func validateMemberRole(role string) error {
+	switch role {
+	case model.MemberRoleViewer, model.MemberRoleEditor, model.MemberRoleOwner:
+		return nil
+	default:
+		return fmt.Errorf("%w: unsupported member role %q", ErrValidation, role)
+	}
+}
+
+func validateBatchSize(accountID string, size int) error {
+	if accountID == "" {
+		return fmt.Errorf("%w: account id is required", ErrValidation)
+	}
+	if size == 0 || size > MaxBatchSize {
+		return fmt.Errorf("%w: batch must contain between 1 and %d items", ErrValidation, MaxBatchSize)
+	}
+	return nil
+}
+
+// validateBatch checks every item, forwards only the valid ones to next and maps
+// the results back to the original positions. In atomic mode an invalid item
+// aborts the whole batch without calling next.
+func validateBatch[T any](items []T, atomic bool, validate func(T) error, next func([]T) ([]model.BatchResult, error)) ([]model.BatchResult, error) {
+	results := newBatchResults(len(items))
+	valid := make([]T, 0, len(items))
+	validIdx := make([]int, 0, len(items))
+	for i, item := range items {
+		if err := validate(item); err != nil {
+			results[i].Err = err
+			continue
+		}
+		valid = append(valid, item)
+		validIdx = append(validIdx, i)
+	}
+	if atomic && len(valid) < len(items) {
+		return abortBatch(results, validIdx), nil
+	}
+	if len(valid) == 0 {
+		return results, nil
+	}
+	forwarded, err := next(valid)
+	if err != nil {
+		return nil, err
+	}
+	for _, result := range forwarded {
+		result.Index = validIdx[result.Index]
+		results[result.Index] = result
+	}
+	return results, nil
+}
+
 func validateTransaction(tx model.Transaction, requireID bool) error {
 	if requireID && tx.ID == "" {
 		return fmt.Errorf("%w: transaction id is required", ErrValidation)
@@ -179,9 +915,66 @@ func validateTransaction(tx model.Transaction, requireID bool) error {
 	if tx.Currency == "" {
 		return fmt.Errorf("%w: currency is required", ErrValidation)
 	}
+	if err := validateTransactionTags(tx.Tags); err != nil {
+		return err
+	}
+	if len(tx.Splits) > 0 {
+		return validateSplits(tx)
+	}
 	if tx.Category == "" {
+		if !requireID {
+			// New transactions may leave the category to the rules and payees.
+			return nil
+		}
 		return fmt.Errorf("%w: category is required", ErrValidation)
 	}
+	if requireID && tx.Category == model.CategoryTransfer {
+		// Updates of transfer legs are refused with ErrTransferLeg, and the
+		// ledger rejects the category for any other transaction.
+		return nil
+	}
+	return validateCategoryNotReserved(tx.Category)
+}
+
+// validateCategoryNotReserved rejects the categories the ledger assigns itself:
+// the one of split transactions and the one of transfer legs.
+func validateCategoryNotReserved(category string) error {
+	switch category {
+	case model.CategorySplit:
+		return fmt.Errorf("%w: category %q is reserved for split transactions", ErrValidation, category)
+	case model.CategoryTransfer:
+		return fmt.Errorf("%w: category %q is reserved for transfers", ErrValidation, category)
+	}
+	return nil
+}
+
+// validateSplits checks the parts of a split transaction: at least two parts in
+// distinct categories, each with the sign of the amount, summing up to it.
+func validateSplits(tx model.Transaction) error {
+	if len(tx.Splits) < 2 {
+		return fmt.Errorf("%w: a split transaction needs at least two parts", ErrValidation)
+	}
+	seen := make(map[string]struct{}, len(tx.Splits))
+	sum := 0.0
+	for i, split := range tx.Splits {
+		if split.Category == "" || split.Category == model.CategorySplit {
+			return fmt.Errorf("%w: split %d: category is required", ErrValidation, i+1)
+		}
+		if split.Category == model.CategoryTransfer {
+			return fmt.Errorf("%w: split %d: category %q is reserved for transfers", ErrValidation, i+1, split.Category)
+		}
+		if _, ok := seen[split.Category]; ok {
+			return fmt.Errorf("%w: split %d: category %q is used more than once", ErrValidation, i+1, split.Category)
+		}
+		seen[split.Category] = struct{}{}
+		if split.Amount == 0 || (split.Amount < 0) != (tx.Amount < 0) {
+			return fmt.Errorf("%w: split %d: amount must be non-zero and have the sign of the transaction", ErrValidation, i+1)
+		}
+		sum += split.Amount
+	}
+	if math.Abs(sum-tx.Amount) > journalTolerance {
+		return fmt.Errorf("%w: splits sum up to %v instead of %v", ErrValidation, sum, tx.Amount)
+	}
 	return nil
 }
 
@@ -210,6 +1003,99 @@ func validateBudget(budget model.Budget, requireID bool) error {
 	return nil
 }
 
+// validateTag trims the tag name in place before checking it.
+func validateTag(tag *model.Tag, requireID bool) error {
+	if requireID && tag.ID == "" {
+		return fmt.Errorf("%w: tag id is required", ErrValidation)
+	}
+	if tag.AccountID == "" {
+		return fmt.Errorf("%w: account id is required", ErrValidation)
+	}
+	tag.Name = strings.TrimSpace(tag.Name)
+	return validateTagName(tag.Name)
+}
+
+// validateTransactionTags checks the tags of a transaction as they will be
+// stored: trimmed and without duplicates.
+func validateTransactionTags(tags []string) error {
+	for _, tag := range tags {
+		if err := validateTagName(strings.TrimSpace(tag)); err != nil {
+			return err
+		}
+	}
+	if len(normalizeTags(tags)) > maxTransactionTags {
+		return fmt.Errorf("%w: a transaction can have at most %d tags", ErrValidation, maxTransactionTags)
+	}
+	return nil
+}
+
+// validateTagName rejects names that cannot round-trip through the CSV tags
+// column, where tags are separated by semicolons.
+func validateTagName(name string) error {
+	if name == "" {
+		return fmt.Errorf("%w: tag name is required", ErrValidation)
+	}
+	if utf8.RuneCountInString(name) > maxTagNameLength {
+		return fmt.Errorf("%w: tag name must not exceed %d characters", ErrValidation, maxTagNameLength)
+	}
+	if strings.Contains(name, ";") {
+		return fmt.Errorf("%w: tag name must not contain %q", ErrValidation, ";")
+	}
+	return nil
+}
+
+func validateAttachmentRef(accountID, id string) error {
+	if accountID == "" {
+		return fmt.Errorf("%w: account id is required", ErrValidation)
+	}
+	if id == "" {
+		return fmt.Errorf("%w: attachment id is required", ErrValidation)
+	}
+	return nil
+}
+
+// validateFileName checks the name an attachment is downloaded with. Path
+// separators and control characters are rejected so the name is safe to put
+// into a Content-Disposition header.
+func validateFileName(name string) error {
+	if name == "" {
+		return fmt.Errorf("%w: file name is required", ErrValidation)
+	}
+	if utf8.RuneCountInString(name) > maxFileNameLength {
+		return fmt.Errorf("%w: file name must not exceed %d characters", ErrValidation, maxFileNameLength)
+	}
+	if !utf8.ValidString(name) || strings.ContainsAny(name, `/\"`) || strings.ContainsFunc(name, unicode.IsControl) {
+		return fmt.Errorf("%w: file name contains invalid characters", ErrValidation)
+	}
+	return nil
+}
+
+// validateWallet trims the wallet name in place before checking it.
+func validateWallet(wallet *model.Wallet, requireID bool) error {
+	if requireID && wallet.ID == "" {
+		return fmt.Errorf("%w: wallet id is required", ErrValidation)
+	}
+	if wallet.AccountID == "" {
+		return fmt.Errorf("%w: account id is required", ErrValidation)
+	}
+	wallet.Name = strings.TrimSpace(wallet.Name)
+	if wallet.Name == "" {
+		return fmt.Errorf("%w: wallet name is required", ErrValidation)
+	}
+	if utf8.RuneCountInString(wallet.Name) > maxWalletNameLength {
+		return fmt.Errorf("%w: wallet name must not exceed %d characters", ErrValidation, maxWalletNameLength)
+	}
+	switch wallet.Type {
+	case model.WalletTypeCash, model.WalletTypeDebit, model.WalletTypeSavings, model.WalletTypeCredit:
+	default:
+		return fmt.Errorf("%w: unsupported wallet type %q", ErrValidation, wallet.Type)
+	}
+	if wallet.Currency == "" {
+		return fmt.Errorf("%w: currency is required", ErrValidation)
+	}
+	return nil
+}
+
 func validateMonth(month time.Time) error {
 	if month.IsZero() {
 		return fmt.Errorf("month is required")
@@ -222,3 +1108,187 @@ func validateMonth(month time.Time) error {
 	}
 	return nil
 }
+
+func validateRuleRef(accountID, id string) error {
+	if accountID == "" {
+		return fmt.Errorf("%w: account id is required", ErrValidation)
+	}
+	if id == "" {
+		return fmt.Errorf("%w: rule id is required", ErrValidation)
+	}
+	return nil
+}
+
+func validateRule(rule *model.CategoryRule, requireID bool) error {
+	if requireID && rule.ID == "" {
+		return fmt.Errorf("%w: rule id is required", ErrValidation)
+	}
+	if rule.AccountID == "" {
+		return fmt.Errorf("%w: account id is required", ErrValidation)
+	}
+	rule.Name = strings.TrimSpace(rule.Name)
+	if rule.Name == "" {
+		return fmt.Errorf("%w: rule name is required", ErrValidation)
+	}
+	if utf8.RuneCountInString(rule.Name) > maxRuleNameLength {
+		return fmt.Errorf("%w: rule name must not exceed %d characters", ErrValidation, maxRuleNameLength)
+	}
+	return validateRuleConditions(rule)
+}
+
+// validateRuleConditions checks what a rule matches and the category it
+// assigns. A rule without conditions would recategorize every transaction,
+// so at least one is required.
+func validateRuleConditions(rule *model.CategoryRule) error {
+	rule.Category = strings.TrimSpace(rule.Category)
+	if rule.Category == "" {
+		return fmt.Errorf("%w: rule category is required", ErrValidation)
+	}
+	if err := validateCategoryNotReserved(rule.Category); err != nil {
+		return err
+	}
+	rule.DescriptionContains = strings.TrimSpace(rule.DescriptionContains)
+	if utf8.RuneCountInString(rule.DescriptionContains) > maxRulePatternLength {
+		return fmt.Errorf("%w: description condition must not exceed %d characters", ErrValidation, maxRulePatternLength)
+	}
+	if utf8.RuneCountInString(rule.DescriptionPattern) > maxRulePatternLength {
+		return fmt.Errorf("%w: description pattern must not exceed %d characters", ErrValidation, maxRulePatternLength)
+	}
+	if rule.DescriptionPattern != "" {
+		if _, err := regexp.Compile(rule.DescriptionPattern); err != nil {
+			return fmt.Errorf("%w: invalid description pattern: %v", ErrValidation, err)
+		}
+	}
+	if rule.MinAmount != nil && *rule.MinAmount < 0 {
+		return fmt.Errorf("%w: min amount must not be negative", ErrValidation)
+	}
+	if rule.MinAmount != nil && rule.MaxAmount != nil && *rule.MaxAmount < *rule.MinAmount {
+		return fmt.Errorf("%w: max amount is less than min amount", ErrValidation)
+	}
+	switch rule.Type {
+	case "", model.RuleTypeIncome, model.RuleTypeExpense:
+	default:
+		return fmt.Errorf("%w: rule type must be %q or %q", ErrValidation, model.RuleTypeIncome, model.RuleTypeExpense)
+	}
+	if rule.DescriptionContains == "" && rule.DescriptionPattern == "" && rule.MinAmount == nil && rule.MaxAmount == nil &&
+		rule.Type == "" && rule.Currency == "" {
+		return fmt.Errorf("%w: rule needs at least one condition", ErrValidation)
+	}
+	return nil
+}
+
+func validatePayeeRef(accountID, id string) error {
+	if accountID == "" {
+		return fmt.Errorf("%w: account id is required", ErrValidation)
+	}
+	if id == "" {
+		return fmt.Errorf("%w: payee id is required", ErrValidation)
+	}
+	return nil
+}
+
+// validatePayee trims the name, aliases and default category of the payee in
+// place and drops aliases that normalize like the name or an earlier alias.
+// The name and every alias must keep something after normalization, or no
+// description could match them.
+func validatePayee(payee *model.Payee, requireID bool) error {
+	if requireID && payee.ID == "" {
+		return fmt.Errorf("%w: payee id is required", ErrValidation)
+	}
+	if payee.AccountID == "" {
+		return fmt.Errorf("%w: account id is required", ErrValidation)
+	}
+	payee.Name = strings.TrimSpace(payee.Name)
+	if err := validatePayeeName("payee name", payee.Name); err != nil {
+		return err
+	}
+	keys := map[string]bool{normalizePayee(payee.Name): true}
+	aliases := make([]string, 0, len(payee.Aliases))
+	for _, alias := range payee.Aliases {
+		alias = strings.TrimSpace(alias)
+		if err := validatePayeeName("payee alias", alias); err != nil {
+			return err
+		}
+		if key := normalizePayee(alias); !keys[key] {
+			keys[key] = true
+			aliases = append(aliases, alias)
+		}
+	}
+	if len(aliases) > maxPayeeAliases {
+		return fmt.Errorf("%w: a payee can have at most %d aliases", ErrValidation, maxPayeeAliases)
+	}
+	payee.Aliases = aliases
+	payee.DefaultCategory = strings.TrimSpace(payee.DefaultCategory)
+	return validateCategoryNotReserved(payee.DefaultCategory)
+}
+
+func validatePayeeName(field, name string) error {
+	if name == "" {
+		return fmt.Errorf("%w: %s is required", ErrValidation, field)
+	}
+	if utf8.RuneCountInString(name) > maxPayeeNameLength {
+		return fmt.Errorf("%w: %s must not exceed %d characters", ErrValidation, field, maxPayeeNameLength)
+	}
+	if normalizePayee(name) == "" {
+		return fmt.Errorf("%w: %s %q must contain a word without digits", ErrValidation, field, name)
+	}
+	return nil
+}
+
+func validateGoalRef(accountID, id string) error {
+	if accountID == "" {
+		return fmt.Errorf("%w: account id is required", ErrValidation)
+	}
+	if id == "" {
+		return fmt.Errorf("%w: goal id is required", ErrValidation)
+	}
+	return nil
+}
+
+// validateGoal trims the name, category and tag of the goal and truncates its
+// deadline to the date in place before checking them. Contributions come
+// from exactly one source: the category, the tag or the wallet.
+func validateGoal(goal *model.Goal, requireID bool) error {
+	if requireID && goal.ID == "" {
+		return fmt.Errorf("%w: goal id is required", ErrValidation)
+	}
+	if goal.AccountID == "" {
+		return fmt.Errorf("%w: account id is required", ErrValidation)
+	}
+	goal.Name = strings.TrimSpace(goal.Name)
+	if goal.Name == "" {
+		return fmt.Errorf("%w: goal name is required", ErrValidation)
+	}
+	if utf8.RuneCountInString(goal.Name) > maxGoalNameLength {
+		return fmt.Errorf("%w: goal name must not exceed %d characters", ErrValidation, maxGoalNameLength)
+	}
+	if goal.TargetAmount <= 0 {
+		return fmt.Errorf("%w: target amount must be positive", ErrValidation)
+	}
+	if goal.Currency == "" {
+		return fmt.Errorf("%w: currency is required", ErrValidation)
+	}
+	if goal.Deadline.IsZero() {
+		return fmt.Errorf("%w: deadline is required", ErrValidation)
+	}
+	goal.Deadline = dateOnly(goal.Deadline)
+	goal.Category = strings.TrimSpace(goal.Category)
+	goal.Tag = strings.TrimSpace(goal.Tag)
+	sources := 0
+	for _, source := range []string{goal.Category, goal.Tag, goal.WalletID} {
+		if source != "" {
+			sources++
+		}
+	}
+	switch {
+	case sources == 0:
+		return fmt.Errorf("%w: goal needs a contribution category, tag or wallet", ErrValidation)
+	case sources > 1:
+		return fmt.Errorf("%w: goal takes contributions from only one of a category, a tag or a wallet", ErrValidation)
+	case goal.Category == model.CategorySplit || goal.Category == model.CategoryTransfer:
+		return fmt.Errorf("%w: category %q cannot hold contributions", ErrValidation, goal.Category)
+	case goal.Tag != "":
+		return validateTagName(goal.Tag)
+	}
+	return nil
+}
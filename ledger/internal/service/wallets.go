@@ -0,0 +1,340 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/actor"
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/model"
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/repository"
+	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/storage"
+)
+
+func (s *DefaultLedgerService) CreateWallet(ctx context.Context, wallet model.Wallet) (model.Wallet, error) {
+	now := time.Now().UTC()
+	if wallet.ID == "" {
+		wallet.ID = uuid.NewString()
+	}
+	wallet.Balance = 0
+	wallet.CreatedAt = now
+	wallet.UpdatedAt = now
+	err := s.repo.RunInTx(ctx, func(repo repository.LedgerRepository) error {
+		if err := repo.CreateWallet(ctx, wallet); err != nil {
+			return err
+		}
+		if err := appendAudit(ctx, repo, model.AuditEntityWallet, wallet.AccountID, wallet.ID, model.AuditOperationCreate, nil, wallet); err != nil {
+			return err
+		}
+		return appendEvent(ctx, repo, model.EventWalletChanged, wallet.AccountID, wallet.ID, walletChangedPayload{Operation: changeOperationCreated, Wallet: wallet})
+	})
+	if err != nil {
+		return model.Wallet{}, err
+	}
+	return wallet, nil
+}
+
+func (s *DefaultLedgerService) GetWallet(ctx context.Context, accountID, id string) (model.Wallet, error) {
+	wallet, err := s.repo.GetWallet(ctx, accountID, id)
+	if err != nil {
+		return model.Wallet{}, err
+	}
+	wallet.Balance = walletBalances(s.ListTransactions(ctx, accountID))[wallet.ID]
+	return wallet, nil
+}
+
+// UpdateWallet renames a wallet or changes its type. The currency of a wallet
+// can change only while it has no transactions.
+func (s *DefaultLedgerService) UpdateWallet(ctx context.Context, wallet model.Wallet) (model.Wallet, error) {
+	current, err := s.repo.GetWallet(ctx, wallet.AccountID, wallet.ID)
+	if err != nil {
+		return model.Wallet{}, err
+	}
+	if wallet.Currency != current.Currency && s.walletHasTransactions(ctx, wallet.AccountID, wallet.ID) {
+		return model.Wallet{}, fmt.Errorf("%w: currency cannot change", ErrWalletInUse)
+	}
+	wallet.Balance = 0
+	wallet.CreatedAt = current.CreatedAt
+	wallet.UpdatedAt = time.Now().UTC()
+	err = s.repo.RunInTx(ctx, func(repo repository.LedgerRepository) error {
+		if err := repo.UpdateWallet(ctx, wallet); err != nil {
+			return err
+		}
+		// Credit wallets are liabilities in the journal: repost the
+		// transactions of the wallet when its type changes.
+		if wallet.Type != current.Type {
+			if err := postJournal(ctx, repo, walletTransactions(s.ListTransactions(ctx, wallet.AccountID), wallet.ID)); err != nil {
+				return err
+			}
+		}
+		if err := appendAudit(ctx, repo, model.AuditEntityWallet, wallet.AccountID, wallet.ID, model.AuditOperationUpdate, current, wallet); err != nil {
+			return err
+		}
+		return appendEvent(ctx, repo, model.EventWalletChanged, wallet.AccountID, wallet.ID, walletChangedPayload{Operation: changeOperationUpdated, Wallet: wallet})
+	})
+	if err != nil {
+		return model.Wallet{}, err
+	}
+	wallet.Balance = walletBalances(s.ListTransactions(ctx, wallet.AccountID))[wallet.ID]
+	return wallet, nil
+}
+
+// DeleteWallet removes a wallet without transactions, including trashed ones.
+func (s *DefaultLedgerService) DeleteWallet(ctx context.Context, accountID, id string) error {
+	if s.walletHasTransactions(ctx, accountID, id) {
+		return ErrWalletInUse
+	}
+	return s.repo.RunInTx(ctx, func(repo repository.LedgerRepository) error {
+		current, err := repo.GetWallet(ctx, accountID, id)
+		if err != nil {
+			return err
+		}
+		if err := repo.DeleteWallet(ctx, accountID, id); err != nil {
+			return err
+		}
+		if err := appendAudit(ctx, repo, model.AuditEntityWallet, current.AccountID, current.ID, model.AuditOperationDelete, current, nil); err != nil {
+			return err
+		}
+		return appendEvent(ctx, repo, model.EventWalletChanged, current.AccountID, current.ID, walletChangedPayload{Operation: changeOperationDeleted, Wallet: current})
+	})
+}
+
+// ListWallets returns the wallets of the account with their current balances.
+func (s *DefaultLedgerService) ListWallets(ctx context.Context, accountID string) ([]model.Wallet, error) {
+	wallets, err := s.repo.ListWallets(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	balances := walletBalances(s.ListTransactions(ctx, accountID))
+	for i := range wallets {
+		wallets[i].Balance = balances[wallets[i].ID]
+	}
+	return wallets, nil
+}
+
+// CreateTransfer writes both legs of a transfer in one database transaction.
+// Wallets in the same currency move the same amount; between currencies the
+// credited ToAmount is required. Transfers are not subject to budgets. The
+// transfer ID links the legs, so it is always generated here: a reused ID would
+// tie the legs of two transfers together.
+func (s *DefaultLedgerService) CreateTransfer(ctx context.Context, transfer model.Transfer) (model.Transfer, error) {
+	wallets, err := s.walletsByID(ctx, transfer.AccountID)
+	if err != nil {
+		return model.Transfer{}, err
+	}
+	from, ok := wallets[transfer.FromWalletID]
+	if !ok {
+		return model.Transfer{}, fmt.Errorf("%w: wallet %s not found", ErrValidation, transfer.FromWalletID)
+	}
+	to, ok := wallets[transfer.ToWalletID]
+	if !ok {
+		return model.Transfer{}, fmt.Errorf("%w: wallet %s not found", ErrValidation, transfer.ToWalletID)
+	}
+	switch {
+	case from.Currency == to.Currency && transfer.ToAmount == 0:
+		transfer.ToAmount = transfer.Amount
+	case from.Currency == to.Currency && transfer.ToAmount != transfer.Amount:
+		return model.Transfer{}, fmt.Errorf("%w: to amount must match the amount between wallets in %s", ErrValidation, from.Currency)
+	case from.Currency != to.Currency && transfer.ToAmount == 0:
+		return model.Transfer{}, fmt.Errorf("%w: to amount in %s is required", ErrValidation, to.Currency)
+	}
+
+	now := time.Now().UTC()
+	transfer.ID = uuid.NewString()
+	if transfer.OccurredAt.IsZero() {
+		transfer.OccurredAt = now
+	}
+	transfer.CreatedBy = actor.UserIDFromContext(ctx)
+	leg := func(wallet model.Wallet, amount float64) model.Transaction {
+		return model.Transaction{
+			ID:          uuid.NewString(),
+			AccountID:   transfer.AccountID,
+			Amount:      amount,
+			Currency:    wallet.Currency,
+			Category:    model.CategoryTransfer,
+			Description: transfer.Description,
+			OccurredAt:  transfer.OccurredAt,
+			CreatedBy:   transfer.CreatedBy,
+			WalletID:    wallet.ID,
+			TransferID:  transfer.ID,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		}
+	}
+	legs := []model.Transaction{leg(from, -transfer.Amount), leg(to, transfer.ToAmount)}
+	err = s.repo.RunInTx(ctx, func(repo repository.LedgerRepository) error {
+		if err := repo.CreateTransactions(ctx, legs); err != nil {
+			return err
+		}
+		if err := postJournal(ctx, repo, legs); err != nil {
+			return err
+		}
+		return recordBatch(ctx, repo, model.AuditOperationCreate, model.EventTransactionCreated, nil, legs)
+	})
+	if err != nil {
+		return model.Transfer{}, err
+	}
+	return transfer, nil
+}
+
+// DeleteTransfer moves both legs of a transfer to the trash.
+func (s *DefaultLedgerService) DeleteTransfer(ctx context.Context, accountID, id string) error {
+	legs := transferLegs(s.ListTransactions(ctx, accountID), id)
+	if len(legs) == 0 {
+		return storage.ErrNotFound
+	}
+	ids := make([]string, 0, len(legs))
+	for _, tx := range legs {
+		ids = append(ids, tx.ID)
+	}
+	return s.repo.RunInTx(ctx, func(repo repository.LedgerRepository) error {
+		if err := repo.DeleteTransactions(ctx, ids); err != nil {
+			return err
+		}
+		if err := unpostJournal(ctx, repo, legs); err != nil {
+			return err
+		}
+		return recordBatch(ctx, repo, model.AuditOperationDelete, model.EventTransactionDeleted, legs, nil)
+	})
+}
+
+// GetWalletBalanceHistory returns the closing balance of the wallet for every
+// day or month between from and to. Balances include transactions made before
+// from.
+func (s *DefaultLedgerService) GetWalletBalanceHistory(ctx context.Context, accountID, walletID string, from, to time.Time, step string) ([]model.BalancePoint, error) {
+	if _, err := s.repo.GetWallet(ctx, accountID, walletID); err != nil {
+		return nil, err
+	}
+	transactions := walletTransactions(s.ListTransactions(ctx, accountID), walletID)
+	sort.Slice(transactions, func(i, j int) bool {
+		return transactions[i].OccurredAt.Before(transactions[j].OccurredAt)
+	})
+
+	periods := balancePeriods(from, to, step)
+	points := make([]model.BalancePoint, 0, len(periods))
+	balance := 0.0
+	next := 0
+	for i, start := range periods {
+		end := to
+		if i+1 < len(periods) {
+			end = periods[i+1].Add(-time.Nanosecond)
+		}
+		for next < len(transactions) && !transactions[next].OccurredAt.After(end) {
+			balance += transactions[next].Amount
+			next++
+		}
+		points = append(points, model.BalancePoint{Date: start, Balance: balance})
+	}
+	return points, nil
+}
+
+// ensureWallet checks the wallet of a single transaction; see checkWallet.
+func (s *DefaultLedgerService) ensureWallet(ctx context.Context, tx model.Transaction) error {
+	if tx.WalletID == "" {
+		return nil
+	}
+	wallets, err := s.walletsByID(ctx, tx.AccountID)
+	if err != nil {
+		return err
+	}
+	return checkWallet(tx, wallets)
+}
+
+func (s *DefaultLedgerService) walletsByID(ctx context.Context, accountID string) (map[string]model.Wallet, error) {
+	items, err := s.repo.ListWallets(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]model.Wallet, len(items))
+	for _, wallet := range items {
+		byID[wallet.ID] = wallet
+	}
+	return byID, nil
+}
+
+// walletHasTransactions reports whether live or trashed transactions refer to the wallet.
+func (s *DefaultLedgerService) walletHasTransactions(ctx context.Context, accountID, walletID string) bool {
+	for _, items := range [][]model.Transaction{s.ListTransactions(ctx, accountID), s.repo.ListDeletedTransactions(ctx, accountID)} {
+		for _, tx := range items {
+			if tx.WalletID == walletID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// checkWallet verifies that the wallet of tx, if any, belongs to the account and
+// holds the currency of tx.
+func checkWallet(tx model.Transaction, wallets map[string]model.Wallet) error {
+	if tx.WalletID == "" {
+		return nil
+	}
+	wallet, ok := wallets[tx.WalletID]
+	if !ok {
+		return fmt.Errorf("%w: wallet %s not found", ErrValidation, tx.WalletID)
+	}
+	if wallet.Currency != tx.Currency {
+		return fmt.Errorf("%w: wallet %s holds %s, not %s", ErrValidation, wallet.Name, wallet.Currency, tx.Currency)
+	}
+	return nil
+}
+
+func walletBalances(transactions []model.Transaction) map[string]float64 {
+	balances := map[string]float64{}
+	for _, tx := range transactions {
+		if tx.WalletID != "" {
+			balances[tx.WalletID] += tx.Amount
+		}
+	}
+	return balances
+}
+
+func walletTransactions(transactions []model.Transaction, walletID string) []model.Transaction {
+	items := make([]model.Transaction, 0)
+	for _, tx := range transactions {
+		if tx.WalletID == walletID {
+			items = append(items, tx)
+		}
+	}
+	return items
+}
+
+func transferLegs(transactions []model.Transaction, transferID string) []model.Transaction {
+	legs := make([]model.Transaction, 0, 2)
+	for _, tx := range transactions {
+		if tx.TransferID == transferID {
+			legs = append(legs, tx)
+		}
+	}
+	return legs
+}
+
+// balancePeriods returns the starts of the days or calendar months that
+// overlap [from, to].
+func balancePeriods(from, to time.Time, step string) []time.Time {
+	start := dateOnly(from)
+	advance := func(t time.Time) time.Time { return t.AddDate(0, 0, 1) }
+	if step == model.BalanceStepMonth {
+		start, _ = monthRange(from)
+		advance = func(t time.Time) time.Time { return t.AddDate(0, 1, 0) }
+	}
+	periods := make([]time.Time, 0)
+	for ; !start.After(to); start = advance(start) {
+		periods = append(periods, start)
+	}
+	return periods
+}
+
+// countBalancePeriods returns len(balancePeriods(from, to, step)) without
+// building the slice.
+func countBalancePeriods(from, to time.Time, step string) int {
+	from, to = from.UTC(), to.UTC()
+	if step == model.BalanceStepMonth {
+		return (to.Year()-from.Year())*12 + int(to.Month()) - int(from.Month()) + 1
+	}
+	return int(dateOnly(to).Sub(dateOnly(from)).Hours()/24) + 1
+}
@@ -12,7 +12,9 @@ import (
 	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/model"
 )
 
-const reportCacheKeyPrefix = "ledger:report:"
+// reportCacheKeyPrefix carries the version of the JSON encoding of reports so
+// entries written with an earlier encoding are not decoded.
+const reportCacheKeyPrefix = "ledger:report:v2:"
 
 type ReportCache struct {
 	client *redis.Client
@@ -2,7 +2,9 @@ package storage
 
 import (
 	"errors"
+	"slices"
 	"sync"
+	"time"
 
 	"github.com/Deevins/final-task-course-2-go-lang/ledger/internal/model"
 )
@@ -14,6 +16,30 @@ type InMemoryLedgerStorage struct {
 	transactions map[string]model.Transaction
 	budgets      map[string]model.Budget
 	reports      map[string]model.Report
+	// Soft-deleted entities are moved out of the live maps into the trash.
+	deletedTransactions map[string]model.Transaction
+	deletedBudgets      map[string]model.Budget
+	deletedReports      map[string]model.Report
+	events              []model.Event
+	lastEventOffset     int64
+	audit               []model.AuditEntry
+	published           map[int64]bool
+	accounts            map[string]model.Account
+	members             map[memberKey]model.AccountMember
+	invitations         map[string]model.Invitation
+	wallets             map[string]model.Wallet
+	tags                map[string]model.Tag
+	attachments         map[string]model.Attachment
+	rules               map[string]model.CategoryRule
+	payees              map[string]model.Payee
+	goals               map[string]model.Goal
+	// Journal entries are keyed by the ID of their transaction.
+	journal map[string]model.JournalEntry
+}
+
+type memberKey struct {
+	accountID string
+	userID    string
 }
 
 func NewInMemoryLedgerStorage() *InMemoryLedgerStorage {
@@ -21,6 +47,22 @@ func NewInMemoryLedgerStorage() *InMemoryLedgerStorage {
 		transactions: make(map[string]model.Transaction),
 		budgets:      make(map[string]model.Budget),
 		reports:      make(map[string]model.Report),
+		published:    make(map[int64]bool),
+
+		deletedTransactions: make(map[string]model.Transaction),
+		deletedBudgets:      make(map[string]model.Budget),
+		deletedReports:      make(map[string]model.Report),
+
+		accounts:    make(map[string]model.Account),
+		members:     make(map[memberKey]model.AccountMember),
+		invitations: make(map[string]model.Invitation),
+		wallets:     make(map[string]model.Wallet),
+		tags:        make(map[string]model.Tag),
+		attachments: make(map[string]model.Attachment),
+		rules:       make(map[string]model.CategoryRule),
+		payees:      make(map[string]model.Payee),
+		goals:       make(map[string]model.Goal),
+		journal:     make(map[string]model.JournalEntry),
 	}
 }
 
@@ -54,13 +96,55 @@ func (s *InMemoryLedgerStorage) UpdateTransaction(tx model.Transaction) (model.T
 func (s *InMemoryLedgerStorage) DeleteTransaction(id string) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	if _, ok := s.transactions[id]; !ok {
+	tx, ok := s.transactions[id]
+	if !ok {
 		return ErrNotFound
 	}
+	deletedAt := time.Now().UTC()
+	tx.DeletedAt = &deletedAt
+	s.deletedTransactions[id] = tx
 	delete(s.transactions, id)
 	return nil
 }
 
+func (s *InMemoryLedgerStorage) ListDeletedTransactions() []model.Transaction {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	items := make([]model.Transaction, 0, len(s.deletedTransactions))
+	for _, tx := range s.deletedTransactions {
+		items = append(items, tx)
+	}
+	return items
+}
+
+func (s *InMemoryLedgerStorage) RestoreTransaction(id string) (model.Transaction, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	tx, ok := s.deletedTransactions[id]
+	if !ok {
+		return model.Transaction{}, ErrNotFound
+	}
+	tx.DeletedAt = nil
+	s.transactions[id] = tx
+	delete(s.deletedTransactions, id)
+	return tx, nil
+}
+
+// PurgeDeletedTransactions permanently removes entities deleted before the given time.
+func (s *InMemoryLedgerStorage) PurgeDeletedTransactions(before time.Time) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	purged := 0
+	for id, tx := range s.deletedTransactions {
+		if tx.DeletedAt != nil && tx.DeletedAt.Before(before) {
+			delete(s.deletedTransactions, id)
+			s.deleteTransactionAttachments(id)
+			purged++
+		}
+	}
+	return purged
+}
+
 func (s *InMemoryLedgerStorage) ListTransactions() []model.Transaction {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
@@ -101,13 +185,54 @@ func (s *InMemoryLedgerStorage) UpdateBudget(budget model.Budget) (model.Budget,
 func (s *InMemoryLedgerStorage) DeleteBudget(id string) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	if _, ok := s.budgets[id]; !ok {
+	budget, ok := s.budgets[id]
+	if !ok {
 		return ErrNotFound
 	}
+	deletedAt := time.Now().UTC()
+	budget.DeletedAt = &deletedAt
+	s.deletedBudgets[id] = budget
 	delete(s.budgets, id)
 	return nil
 }
 
+func (s *InMemoryLedgerStorage) ListDeletedBudgets() []model.Budget {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	items := make([]model.Budget, 0, len(s.deletedBudgets))
+	for _, budget := range s.deletedBudgets {
+		items = append(items, budget)
+	}
+	return items
+}
+
+func (s *InMemoryLedgerStorage) RestoreBudget(id string) (model.Budget, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	budget, ok := s.deletedBudgets[id]
+	if !ok {
+		return model.Budget{}, ErrNotFound
+	}
+	budget.DeletedAt = nil
+	s.budgets[id] = budget
+	delete(s.deletedBudgets, id)
+	return budget, nil
+}
+
+// PurgeDeletedBudgets permanently removes entities deleted before the given time.
+func (s *InMemoryLedgerStorage) PurgeDeletedBudgets(before time.Time) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	purged := 0
+	for id, budget := range s.deletedBudgets {
+		if budget.DeletedAt != nil && budget.DeletedAt.Before(before) {
+			delete(s.deletedBudgets, id)
+			purged++
+		}
+	}
+	return purged
+}
+
 func (s *InMemoryLedgerStorage) ListBudgets() []model.Budget {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
@@ -148,13 +273,54 @@ func (s *InMemoryLedgerStorage) UpdateReport(report model.Report) (model.Report,
 func (s *InMemoryLedgerStorage) DeleteReport(id string) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	if _, ok := s.reports[id]; !ok {
+	report, ok := s.reports[id]
+	if !ok {
 		return ErrNotFound
 	}
+	deletedAt := time.Now().UTC()
+	report.DeletedAt = &deletedAt
+	s.deletedReports[id] = report
 	delete(s.reports, id)
 	return nil
 }
 
+func (s *InMemoryLedgerStorage) ListDeletedReports() []model.Report {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	items := make([]model.Report, 0, len(s.deletedReports))
+	for _, report := range s.deletedReports {
+		items = append(items, report)
+	}
+	return items
+}
+
+func (s *InMemoryLedgerStorage) RestoreReport(id string) (model.Report, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	report, ok := s.deletedReports[id]
+	if !ok {
+		return model.Report{}, ErrNotFound
+	}
+	report.DeletedAt = nil
+	s.reports[id] = report
+	delete(s.deletedReports, id)
+	return report, nil
+}
+
+// PurgeDeletedReports permanently removes entities deleted before the given time.
+func (s *InMemoryLedgerStorage) PurgeDeletedReports(before time.Time) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	purged := 0
+	for id, report := range s.deletedReports {
+		if report.DeletedAt != nil && report.DeletedAt.Before(before) {
+			delete(s.deletedReports, id)
+			purged++
+		}
+	}
+	return purged
+}
+
 func (s *InMemoryLedgerStorage) ListReports() []model.Report {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
@@ -164,3 +330,556 @@ func (s *InMemoryLedgerStorage) ListReports() []model.Report {
 	}
 	return items
 }
+
+func (s *InMemoryLedgerStorage) AppendEvents(events ...model.Event) []model.Event {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored := make([]model.Event, 0, len(events))
+	for _, event := range events {
+		s.lastEventOffset++
+		event.Offset = s.lastEventOffset
+		s.events = append(s.events, event)
+		stored = append(stored, event)
+	}
+	return stored
+}
+
+func (s *InMemoryLedgerStorage) ListEvents() []model.Event {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	items := make([]model.Event, len(s.events))
+	copy(items, s.events)
+	return items
+}
+
+func (s *InMemoryLedgerStorage) ListUnpublishedEvents() []model.Event {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	items := make([]model.Event, 0)
+	for _, event := range s.events {
+		if !s.published[event.Offset] {
+			items = append(items, event)
+		}
+	}
+	return items
+}
+
+func (s *InMemoryLedgerStorage) MarkEventsPublished(offsets []int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, offset := range offsets {
+		s.published[offset] = true
+	}
+}
+
+func (s *InMemoryLedgerStorage) AppendAudit(entries ...model.AuditEntry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.audit = append(s.audit, entries...)
+}
+
+func (s *InMemoryLedgerStorage) ListAudit() []model.AuditEntry {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	items := make([]model.AuditEntry, len(s.audit))
+	copy(items, s.audit)
+	return items
+}
+
+// PurgeAccount removes every entity, wallet, tag, attachment, journal entry, event and audit entry of the account,
+// including trashed entities, and returns the number of removed records.
+func (s *InMemoryLedgerStorage) PurgeAccount(accountID string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	purged := 0
+	for _, items := range []map[string]model.Transaction{s.transactions, s.deletedTransactions} {
+		for id, tx := range items {
+			if tx.AccountID == accountID {
+				delete(items, id)
+				purged++
+			}
+		}
+	}
+	for _, items := range []map[string]model.Budget{s.budgets, s.deletedBudgets} {
+		for id, budget := range items {
+			if budget.AccountID == accountID {
+				delete(items, id)
+				purged++
+			}
+		}
+	}
+	for _, items := range []map[string]model.Report{s.reports, s.deletedReports} {
+		for id, report := range items {
+			if report.AccountID == accountID {
+				delete(items, id)
+				purged++
+			}
+		}
+	}
+	events := s.events[:0]
+	for _, event := range s.events {
+		if event.AccountID == accountID {
+			delete(s.published, event.Offset)
+			purged++
+			continue
+		}
+		events = append(events, event)
+	}
+	s.events = events
+	audit := s.audit[:0]
+	for _, entry := range s.audit {
+		if entry.AccountID == accountID {
+			purged++
+			continue
+		}
+		audit = append(audit, entry)
+	}
+	s.audit = audit
+	for id, wallet := range s.wallets {
+		if wallet.AccountID == accountID {
+			delete(s.wallets, id)
+			purged++
+		}
+	}
+	for id, tag := range s.tags {
+		if tag.AccountID == accountID {
+			delete(s.tags, id)
+			purged++
+		}
+	}
+	for id, attachment := range s.attachments {
+		if attachment.AccountID == accountID {
+			delete(s.attachments, id)
+			purged++
+		}
+	}
+	for id, rule := range s.rules {
+		if rule.AccountID == accountID {
+			delete(s.rules, id)
+			purged++
+		}
+	}
+	for id, payee := range s.payees {
+		if payee.AccountID == accountID {
+			delete(s.payees, id)
+			purged++
+		}
+	}
+	for id, goal := range s.goals {
+		if goal.AccountID == accountID {
+			delete(s.goals, id)
+			purged++
+		}
+	}
+	for id, entry := range s.journal {
+		if entry.AccountID == accountID {
+			delete(s.journal, id)
+			purged++
+		}
+	}
+	for key := range s.members {
+		if key.userID == accountID {
+			delete(s.members, key)
+			purged++
+		}
+	}
+	return purged
+}
+
+// CreateAccount stores the shared account together with its first owner.
+func (s *InMemoryLedgerStorage) CreateAccount(account model.Account, owner model.AccountMember) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.accounts[account.ID] = account
+	s.members[memberKey{accountID: owner.AccountID, userID: owner.UserID}] = owner
+}
+
+func (s *InMemoryLedgerStorage) GetAccount(id string) (model.Account, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	account, ok := s.accounts[id]
+	if !ok {
+		return model.Account{}, ErrNotFound
+	}
+	return account, nil
+}
+
+func (s *InMemoryLedgerStorage) PutMember(member model.AccountMember) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.members[memberKey{accountID: member.AccountID, userID: member.UserID}] = member
+}
+
+func (s *InMemoryLedgerStorage) RemoveMember(accountID, userID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := memberKey{accountID: accountID, userID: userID}
+	if _, ok := s.members[key]; !ok {
+		return ErrNotFound
+	}
+	delete(s.members, key)
+	return nil
+}
+
+func (s *InMemoryLedgerStorage) ListMembers() []model.AccountMember {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	items := make([]model.AccountMember, 0, len(s.members))
+	for _, member := range s.members {
+		items = append(items, member)
+	}
+	return items
+}
+
+func (s *InMemoryLedgerStorage) PutInvitation(invitation model.Invitation) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.invitations[invitation.ID] = invitation
+}
+
+func (s *InMemoryLedgerStorage) DeleteInvitation(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.invitations, id)
+}
+
+func (s *InMemoryLedgerStorage) ListInvitations() []model.Invitation {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	items := make([]model.Invitation, 0, len(s.invitations))
+	for _, invitation := range s.invitations {
+		items = append(items, invitation)
+	}
+	return items
+}
+
+// AcceptInvitation marks the pending invitation with the token hash as
+// accepted. Used and expired invitations are not found.
+func (s *InMemoryLedgerStorage) AcceptInvitation(tokenHash, userID string, now time.Time) (model.Invitation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id, invitation := range s.invitations {
+		if invitation.TokenHash != tokenHash {
+			continue
+		}
+		if invitation.AcceptedAt != nil || !invitation.ExpiresAt.After(now) {
+			return model.Invitation{}, ErrNotFound
+		}
+		invitation.AcceptedBy = userID
+		invitation.AcceptedAt = &now
+		s.invitations[id] = invitation
+		return invitation, nil
+	}
+	return model.Invitation{}, ErrNotFound
+}
+
+func (s *InMemoryLedgerStorage) PutWallet(wallet model.Wallet) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.wallets[wallet.ID] = wallet
+}
+
+func (s *InMemoryLedgerStorage) GetWallet(id string) (model.Wallet, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	wallet, ok := s.wallets[id]
+	if !ok {
+		return model.Wallet{}, ErrNotFound
+	}
+	return wallet, nil
+}
+
+func (s *InMemoryLedgerStorage) DeleteWallet(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.wallets[id]; !ok {
+		return ErrNotFound
+	}
+	delete(s.wallets, id)
+	return nil
+}
+
+func (s *InMemoryLedgerStorage) ListWallets() []model.Wallet {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	items := make([]model.Wallet, 0, len(s.wallets))
+	for _, wallet := range s.wallets {
+		items = append(items, wallet)
+	}
+	return items
+}
+
+func (s *InMemoryLedgerStorage) PutTag(tag model.Tag) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tags[tag.ID] = tag
+}
+
+func (s *InMemoryLedgerStorage) GetTag(id string) (model.Tag, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	tag, ok := s.tags[id]
+	if !ok {
+		return model.Tag{}, ErrNotFound
+	}
+	return tag, nil
+}
+
+// UpdateTag stores the tag and renames it on the live and trashed
+// transactions of its account.
+func (s *InMemoryLedgerStorage) UpdateTag(tag model.Tag) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	current, ok := s.tags[tag.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	s.tags[tag.ID] = tag
+	s.retagTransactions(current, tag.Name)
+	return nil
+}
+
+// DeleteTag removes the tag and unlinks it from the transactions of its
+// account.
+func (s *InMemoryLedgerStorage) DeleteTag(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	current, ok := s.tags[id]
+	if !ok {
+		return ErrNotFound
+	}
+	delete(s.tags, id)
+	s.retagTransactions(current, "")
+	return nil
+}
+
+// retagTransactions replaces the tag on the transactions of its account with
+// name, or removes it when name is empty. Tags stay sorted.
+func (s *InMemoryLedgerStorage) retagTransactions(tag model.Tag, name string) {
+	for _, items := range []map[string]model.Transaction{s.transactions, s.deletedTransactions} {
+		for id, tx := range items {
+			if tx.AccountID != tag.AccountID || !slices.Contains(tx.Tags, tag.Name) {
+				continue
+			}
+			tags := make([]string, 0, len(tx.Tags))
+			for _, existing := range tx.Tags {
+				if existing != tag.Name {
+					tags = append(tags, existing)
+				}
+			}
+			if name != "" {
+				tags = append(tags, name)
+				slices.Sort(tags)
+			}
+			tx.Tags = tags
+			items[id] = tx
+		}
+	}
+}
+
+func (s *InMemoryLedgerStorage) ListTags() []model.Tag {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	items := make([]model.Tag, 0, len(s.tags))
+	for _, tag := range s.tags {
+		items = append(items, tag)
+	}
+	return items
+}
+
+func (s *InMemoryLedgerStorage) PutAttachment(attachment model.Attachment) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.attachments[attachment.ID] = attachment
+}
+
+func (s *InMemoryLedgerStorage) GetAttachment(id string) (model.Attachment, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	attachment, ok := s.attachments[id]
+	if !ok {
+		return model.Attachment{}, ErrNotFound
+	}
+	return attachment, nil
+}
+
+func (s *InMemoryLedgerStorage) DeleteAttachment(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.attachments[id]; !ok {
+		return ErrNotFound
+	}
+	delete(s.attachments, id)
+	return nil
+}
+
+func (s *InMemoryLedgerStorage) ListAttachments() []model.Attachment {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	items := make([]model.Attachment, 0, len(s.attachments))
+	for _, attachment := range s.attachments {
+		items = append(items, attachment)
+	}
+	return items
+}
+
+// deleteTransactionAttachments removes the attachments of a purged
+// transaction, like the foreign key cascade in Postgres.
+func (s *InMemoryLedgerStorage) deleteTransactionAttachments(transactionID string) {
+	for id, attachment := range s.attachments {
+		if attachment.TransactionID == transactionID {
+			delete(s.attachments, id)
+		}
+	}
+}
+
+func (s *InMemoryLedgerStorage) PutRule(rule model.CategoryRule) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rules[rule.ID] = rule
+}
+
+func (s *InMemoryLedgerStorage) GetRule(id string) (model.CategoryRule, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	rule, ok := s.rules[id]
+	if !ok {
+		return model.CategoryRule{}, ErrNotFound
+	}
+	return rule, nil
+}
+
+func (s *InMemoryLedgerStorage) DeleteRule(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.rules[id]; !ok {
+		return ErrNotFound
+	}
+	delete(s.rules, id)
+	return nil
+}
+
+func (s *InMemoryLedgerStorage) ListRules() []model.CategoryRule {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	items := make([]model.CategoryRule, 0, len(s.rules))
+	for _, rule := range s.rules {
+		items = append(items, rule)
+	}
+	return items
+}
+
+func (s *InMemoryLedgerStorage) PutPayee(payee model.Payee) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	payee.Aliases = append([]string(nil), payee.Aliases...)
+	s.payees[payee.ID] = payee
+}
+
+func (s *InMemoryLedgerStorage) GetPayee(id string) (model.Payee, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	payee, ok := s.payees[id]
+	if !ok {
+		return model.Payee{}, ErrNotFound
+	}
+	payee.Aliases = append([]string(nil), payee.Aliases...)
+	return payee, nil
+}
+
+// DeletePayee removes the payee and unlinks its live and deleted
+// transactions.
+func (s *InMemoryLedgerStorage) DeletePayee(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.payees[id]; !ok {
+		return ErrNotFound
+	}
+	delete(s.payees, id)
+	for _, transactions := range []map[string]model.Transaction{s.transactions, s.deletedTransactions} {
+		for txID, tx := range transactions {
+			if tx.PayeeID == id {
+				tx.PayeeID = ""
+				transactions[txID] = tx
+			}
+		}
+	}
+	return nil
+}
+
+func (s *InMemoryLedgerStorage) ListPayees() []model.Payee {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	items := make([]model.Payee, 0, len(s.payees))
+	for _, payee := range s.payees {
+		payee.Aliases = append([]string(nil), payee.Aliases...)
+		items = append(items, payee)
+	}
+	return items
+}
+
+func (s *InMemoryLedgerStorage) PutGoal(goal model.Goal) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.goals[goal.ID] = goal
+}
+
+func (s *InMemoryLedgerStorage) GetGoal(id string) (model.Goal, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	goal, ok := s.goals[id]
+	if !ok {
+		return model.Goal{}, ErrNotFound
+	}
+	return goal, nil
+}
+
+func (s *InMemoryLedgerStorage) DeleteGoal(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.goals[id]; !ok {
+		return ErrNotFound
+	}
+	delete(s.goals, id)
+	return nil
+}
+
+func (s *InMemoryLedgerStorage) ListGoals() []model.Goal {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	items := make([]model.Goal, 0, len(s.goals))
+	for _, goal := range s.goals {
+		items = append(items, goal)
+	}
+	return items
+}
+
+// PutJournalEntries stores the entries, replacing earlier entries of the same
+// transactions.
+func (s *InMemoryLedgerStorage) PutJournalEntries(entries ...model.JournalEntry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, entry := range entries {
+		entry.Postings = append([]model.Posting(nil), entry.Postings...)
+		s.journal[entry.TransactionID] = entry
+	}
+}
+
+func (s *InMemoryLedgerStorage) DeleteJournalEntries(transactionIDs ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, id := range transactionIDs {
+		delete(s.journal, id)
+	}
+}
+
+func (s *InMemoryLedgerStorage) ListJournalEntries() []model.JournalEntry {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	items := make([]model.JournalEntry, 0, len(s.journal))
+	for _, entry := range s.journal {
+		entry.Postings = append([]model.Posting(nil), entry.Postings...)
+		items = append(items, entry)
+	}
+	return items
+}
@@ -0,0 +1,16 @@
+-- +goose Up
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+ALTER TABLE budgets ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+ALTER TABLE reports ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS transactions_deleted_at_idx ON transactions (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS budgets_deleted_at_idx ON budgets (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS reports_deleted_at_idx ON reports (deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS reports_deleted_at_idx;
+DROP INDEX IF EXISTS budgets_deleted_at_idx;
+DROP INDEX IF EXISTS transactions_deleted_at_idx;
+ALTER TABLE reports DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE budgets DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE transactions DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,11 @@
+-- +goose Up
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS transactions_account_occurred_at_idx ON transactions (account_id, occurred_at) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS transactions_account_category_idx ON transactions (account_id, category) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS transactions_description_trgm_idx ON transactions USING gin (description gin_trgm_ops);
+
+-- +goose Down
+DROP INDEX IF EXISTS transactions_description_trgm_idx;
+DROP INDEX IF EXISTS transactions_account_category_idx;
+DROP INDEX IF EXISTS transactions_account_occurred_at_idx;
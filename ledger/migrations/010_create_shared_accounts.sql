@@ -0,0 +1,41 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS accounts (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    created_by TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS account_members (
+    account_id TEXT NOT NULL REFERENCES accounts (id) ON DELETE CASCADE,
+    user_id TEXT NOT NULL,
+    role TEXT NOT NULL CHECK (role IN ('owner', 'editor', 'viewer')),
+    created_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (account_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS account_members_user_id_idx ON account_members (user_id);
+
+CREATE TABLE IF NOT EXISTS account_invitations (
+    id TEXT PRIMARY KEY,
+    account_id TEXT NOT NULL REFERENCES accounts (id) ON DELETE CASCADE,
+    token_hash TEXT NOT NULL UNIQUE,
+    role TEXT NOT NULL CHECK (role IN ('owner', 'editor', 'viewer')),
+    created_by TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    accepted_by TEXT,
+    accepted_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS account_invitations_account_id_idx ON account_invitations (account_id);
+
+-- Until now every account belonged to a single user, who created all of its transactions.
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS created_by TEXT NOT NULL DEFAULT '';
+UPDATE transactions SET created_by = account_id WHERE created_by = '';
+
+-- +goose Down
+ALTER TABLE transactions DROP COLUMN IF EXISTS created_by;
+DROP TABLE IF EXISTS account_invitations;
+DROP TABLE IF EXISTS account_members;
+DROP TABLE IF EXISTS accounts;
@@ -0,0 +1,26 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS wallets (
+    id TEXT PRIMARY KEY,
+    account_id TEXT NOT NULL,
+    name TEXT NOT NULL,
+    type TEXT NOT NULL CHECK (type IN ('cash', 'debit', 'savings', 'credit')),
+    currency TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS wallets_account_id_idx ON wallets (account_id);
+
+-- Existing transactions stay outside any wallet.
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS wallet_id TEXT NOT NULL DEFAULT '';
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS transfer_id TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS transactions_wallet_id_idx ON transactions (wallet_id) WHERE wallet_id <> '';
+CREATE INDEX IF NOT EXISTS transactions_transfer_id_idx ON transactions (transfer_id) WHERE transfer_id <> '';
+
+-- +goose Down
+DROP INDEX IF EXISTS transactions_transfer_id_idx;
+DROP INDEX IF EXISTS transactions_wallet_id_idx;
+ALTER TABLE transactions DROP COLUMN IF EXISTS transfer_id;
+ALTER TABLE transactions DROP COLUMN IF EXISTS wallet_id;
+DROP TABLE IF EXISTS wallets;
@@ -0,0 +1,64 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS journal_entries (
+    transaction_id TEXT PRIMARY KEY,
+    account_id TEXT NOT NULL,
+    occurred_at TIMESTAMPTZ NOT NULL,
+    description TEXT NOT NULL,
+    posted_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS journal_entries_account_occurred_at_idx ON journal_entries (account_id, occurred_at);
+
+-- Debits are positive and credits negative; the postings of an entry sum up
+-- to zero per currency.
+CREATE TABLE IF NOT EXISTS journal_postings (
+    transaction_id TEXT NOT NULL REFERENCES journal_entries (transaction_id) ON DELETE CASCADE,
+    line SMALLINT NOT NULL,
+    journal_account TEXT NOT NULL,
+    account_type TEXT NOT NULL CHECK (account_type IN ('asset', 'liability', 'income', 'expense', 'equity')),
+    currency TEXT NOT NULL,
+    amount DOUBLE PRECISION NOT NULL,
+    PRIMARY KEY (transaction_id, line)
+);
+
+CREATE INDEX IF NOT EXISTS journal_postings_journal_account_idx ON journal_postings (journal_account);
+
+-- Post the live transactions with the rules of the ledger service.
+INSERT INTO journal_entries (transaction_id, account_id, occurred_at, description, posted_at)
+SELECT id, account_id, occurred_at, description, now()
+FROM transactions
+WHERE deleted_at IS NULL
+ON CONFLICT (transaction_id) DO NOTHING;
+
+INSERT INTO journal_postings (transaction_id, line, journal_account, account_type, currency, amount)
+SELECT t.id, 0,
+    CASE
+        WHEN w.id IS NULL THEN 'assets:unassigned'
+        WHEN w.type = 'credit' THEN 'liabilities:wallet:' || w.id
+        ELSE 'assets:wallet:' || w.id
+    END,
+    CASE WHEN w.type = 'credit' THEN 'liability' ELSE 'asset' END,
+    t.currency, t.amount
+FROM transactions t
+LEFT JOIN wallets w ON w.id = t.wallet_id AND w.account_id = t.account_id
+WHERE t.deleted_at IS NULL
+UNION ALL
+SELECT t.id, 1,
+    CASE
+        WHEN t.transfer_id <> '' THEN 'equity:transfers'
+        WHEN t.amount >= 0 THEN 'income:' || t.category
+        ELSE 'expenses:' || t.category
+    END,
+    CASE
+        WHEN t.transfer_id <> '' THEN 'equity'
+        WHEN t.amount >= 0 THEN 'income'
+        ELSE 'expense'
+    END,
+    t.currency, -t.amount
+FROM transactions t
+WHERE t.deleted_at IS NULL
+ON CONFLICT (transaction_id, line) DO NOTHING;
+
+-- +goose Down
+DROP TABLE IF EXISTS journal_postings;
+DROP TABLE IF EXISTS journal_entries;
@@ -0,0 +1,10 @@
+-- +goose Up
+-- Parts of split transactions as [{"Category": ..., "Amount": ...}]; empty
+-- for transactions in a single category.
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS splits JSONB NOT NULL DEFAULT '[]';
+
+CREATE INDEX IF NOT EXISTS transactions_splits_idx ON transactions USING GIN (splits jsonb_path_ops);
+
+-- +goose Down
+DROP INDEX IF EXISTS transactions_splits_idx;
+ALTER TABLE transactions DROP COLUMN IF EXISTS splits;
@@ -0,0 +1,9 @@
+-- +goose Up
+-- Refunds reference the expense they give money back for.
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS refund_of TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS transactions_refund_of_idx ON transactions (refund_of) WHERE refund_of <> '';
+
+-- +goose Down
+DROP INDEX IF EXISTS transactions_refund_of_idx;
+ALTER TABLE transactions DROP COLUMN IF EXISTS refund_of;
@@ -0,0 +1,22 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS category_rules (
+    id TEXT PRIMARY KEY,
+    account_id TEXT NOT NULL,
+    name TEXT NOT NULL,
+    priority INTEGER NOT NULL DEFAULT 0,
+    description_contains TEXT NOT NULL DEFAULT '',
+    description_pattern TEXT NOT NULL DEFAULT '',
+    min_amount DOUBLE PRECISION,
+    max_amount DOUBLE PRECISION,
+    type TEXT NOT NULL DEFAULT '',
+    currency TEXT NOT NULL DEFAULT '',
+    category TEXT NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS category_rules_account_id_idx ON category_rules (account_id, priority);
+
+-- +goose Down
+DROP TABLE IF EXISTS category_rules;
@@ -0,0 +1,25 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS payees (
+    id TEXT PRIMARY KEY,
+    account_id TEXT NOT NULL,
+    name TEXT NOT NULL,
+    aliases TEXT[] NOT NULL DEFAULT '{}',
+    default_category TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS payees_account_id_idx ON payees (account_id);
+
+-- Transactions reference their payee; empty when the description names none.
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS payee_id TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS transactions_payee_id_idx ON transactions (payee_id) WHERE payee_id <> '';
+
+ALTER TABLE reports ADD COLUMN IF NOT EXISTS payees JSONB NOT NULL DEFAULT '[]';
+
+-- +goose Down
+ALTER TABLE reports DROP COLUMN IF EXISTS payees;
+DROP INDEX IF EXISTS transactions_payee_id_idx;
+ALTER TABLE transactions DROP COLUMN IF EXISTS payee_id;
+DROP TABLE IF EXISTS payees;
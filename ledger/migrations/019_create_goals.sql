@@ -0,0 +1,23 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS goals (
+    id TEXT PRIMARY KEY,
+    account_id TEXT NOT NULL,
+    name TEXT NOT NULL,
+    target_amount DOUBLE PRECISION NOT NULL CHECK (target_amount > 0),
+    currency TEXT NOT NULL,
+    deadline DATE NOT NULL,
+    -- Contributions are booked in the category or kept in the wallet.
+    category TEXT NOT NULL DEFAULT '',
+    wallet_id TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL,
+    CHECK ((category = '') <> (wallet_id = ''))
+);
+
+CREATE INDEX IF NOT EXISTS goals_account_id_idx ON goals (account_id);
+
+ALTER TABLE reports ADD COLUMN IF NOT EXISTS goals JSONB NOT NULL DEFAULT '[]';
+
+-- +goose Down
+ALTER TABLE reports DROP COLUMN IF EXISTS goals;
+DROP TABLE IF EXISTS goals;
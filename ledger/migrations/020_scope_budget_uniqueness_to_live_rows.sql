@@ -0,0 +1,7 @@
+-- +goose Up
+DROP INDEX IF EXISTS budgets_account_name_month_idx;
+CREATE UNIQUE INDEX IF NOT EXISTS budgets_account_name_month_idx ON budgets (account_id, name, month) WHERE deleted_at IS NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS budgets_account_name_month_idx;
+CREATE UNIQUE INDEX IF NOT EXISTS budgets_account_name_month_idx ON budgets (account_id, name, month);
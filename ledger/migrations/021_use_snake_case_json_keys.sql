@@ -0,0 +1,127 @@
+-- +goose Up
+-- Models are encoded with snake_case JSON keys; rewrite the parts of split
+-- transactions and the breakdowns of stored reports written with Go field names.
+UPDATE transactions
+SET splits = (
+    SELECT jsonb_agg(jsonb_build_object('category', s->'Category', 'amount', s->'Amount') ORDER BY n)
+    FROM jsonb_array_elements(splits) WITH ORDINALITY AS e (s, n)
+)
+WHERE jsonb_typeof(splits) = 'array' AND jsonb_array_length(splits) > 0;
+
+UPDATE reports
+SET categories = (
+    SELECT jsonb_agg(jsonb_build_object(
+        'category', c->'Category',
+        'total_expense', c->'TotalExpense',
+        'budget_amount', c->'BudgetAmount',
+        'budget_usage_percent', c->'BudgetUsagePercent'
+    ) ORDER BY n)
+    FROM jsonb_array_elements(categories) WITH ORDINALITY AS e (c, n)
+)
+WHERE jsonb_typeof(categories) = 'array' AND jsonb_array_length(categories) > 0;
+
+UPDATE reports
+SET tags = (
+    SELECT jsonb_agg(jsonb_build_object(
+        'tag', t->'Tag',
+        'total_income', t->'TotalIncome',
+        'total_expense', t->'TotalExpense'
+    ) ORDER BY n)
+    FROM jsonb_array_elements(tags) WITH ORDINALITY AS e (t, n)
+)
+WHERE jsonb_typeof(tags) = 'array' AND jsonb_array_length(tags) > 0;
+
+UPDATE reports
+SET payees = (
+    SELECT jsonb_agg(jsonb_build_object(
+        'payee_id', p->'PayeeID',
+        'name', p->'Name',
+        'total_expense', p->'TotalExpense',
+        'transactions', p->'Transactions'
+    ) ORDER BY n)
+    FROM jsonb_array_elements(payees) WITH ORDINALITY AS e (p, n)
+)
+WHERE jsonb_typeof(payees) = 'array' AND jsonb_array_length(payees) > 0;
+
+UPDATE reports
+SET goals = (
+    SELECT jsonb_agg(jsonb_build_object(
+        'goal_id', g->'GoalID',
+        'name', g->'Name',
+        'target_amount', g->'TargetAmount',
+        'currency', g->'Currency',
+        'deadline', g->'Deadline',
+        'progress', jsonb_build_object(
+            'saved', g->'Progress'->'Saved',
+            'remaining', g->'Progress'->'Remaining',
+            'percent', g->'Progress'->'Percent',
+            'months_left', g->'Progress'->'MonthsLeft',
+            'monthly_required', g->'Progress'->'MonthlyRequired'
+        )
+    ) ORDER BY n)
+    FROM jsonb_array_elements(goals) WITH ORDINALITY AS e (g, n)
+)
+WHERE jsonb_typeof(goals) = 'array' AND jsonb_array_length(goals) > 0;
+
+-- +goose Down
+UPDATE transactions
+SET splits = (
+    SELECT jsonb_agg(jsonb_build_object('Category', s->'category', 'Amount', s->'amount') ORDER BY n)
+    FROM jsonb_array_elements(splits) WITH ORDINALITY AS e (s, n)
+)
+WHERE jsonb_typeof(splits) = 'array' AND jsonb_array_length(splits) > 0;
+
+UPDATE reports
+SET categories = (
+    SELECT jsonb_agg(jsonb_build_object(
+        'Category', c->'category',
+        'TotalExpense', c->'total_expense',
+        'BudgetAmount', c->'budget_amount',
+        'BudgetUsagePercent', c->'budget_usage_percent'
+    ) ORDER BY n)
+    FROM jsonb_array_elements(categories) WITH ORDINALITY AS e (c, n)
+)
+WHERE jsonb_typeof(categories) = 'array' AND jsonb_array_length(categories) > 0;
+
+UPDATE reports
+SET tags = (
+    SELECT jsonb_agg(jsonb_build_object(
+        'Tag', t->'tag',
+        'TotalIncome', t->'total_income',
+        'TotalExpense', t->'total_expense'
+    ) ORDER BY n)
+    FROM jsonb_array_elements(tags) WITH ORDINALITY AS e (t, n)
+)
+WHERE jsonb_typeof(tags) = 'array' AND jsonb_array_length(tags) > 0;
+
+UPDATE reports
+SET payees = (
+    SELECT jsonb_agg(jsonb_build_object(
+        'PayeeID', p->'payee_id',
+        'Name', p->'name',
+        'TotalExpense', p->'total_expense',
+        'Transactions', p->'transactions'
+    ) ORDER BY n)
+    FROM jsonb_array_elements(payees) WITH ORDINALITY AS e (p, n)
+)
+WHERE jsonb_typeof(payees) = 'array' AND jsonb_array_length(payees) > 0;
+
+UPDATE reports
+SET goals = (
+    SELECT jsonb_agg(jsonb_build_object(
+        'GoalID', g->'goal_id',
+        'Name', g->'name',
+        'TargetAmount', g->'target_amount',
+        'Currency', g->'currency',
+        'Deadline', g->'deadline',
+        'Progress', jsonb_build_object(
+            'Saved', g->'progress'->'saved',
+            'Remaining', g->'progress'->'remaining',
+            'Percent', g->'progress'->'percent',
+            'MonthsLeft', g->'progress'->'months_left',
+            'MonthlyRequired', g->'progress'->'monthly_required'
+        )
+    ) ORDER BY n)
+    FROM jsonb_array_elements(goals) WITH ORDINALITY AS e (g, n)
+)
+WHERE jsonb_typeof(goals) = 'array' AND jsonb_array_length(goals) > 0;
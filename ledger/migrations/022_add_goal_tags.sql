@@ -0,0 +1,13 @@
+-- +goose Up
+-- Goals can also take contributions from the transactions carrying a tag.
+ALTER TABLE goals ADD COLUMN IF NOT EXISTS tag TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE goals DROP CONSTRAINT IF EXISTS goals_check;
+ALTER TABLE goals ADD CONSTRAINT goals_source_check
+    CHECK (num_nonnulls(NULLIF(category, ''), NULLIF(wallet_id, ''), NULLIF(tag, '')) = 1);
+
+-- +goose Down
+DELETE FROM goals WHERE tag <> '';
+ALTER TABLE goals DROP CONSTRAINT IF EXISTS goals_source_check;
+ALTER TABLE goals ADD CONSTRAINT goals_check CHECK ((category = '') <> (wallet_id = ''));
+ALTER TABLE goals DROP COLUMN IF EXISTS tag;
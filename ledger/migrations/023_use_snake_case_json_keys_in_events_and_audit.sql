@@ -0,0 +1,74 @@
+-- +goose Up
+-- Outbox payloads and audit snapshots written before the models had JSON tags
+-- use Go field names. Every tag is the snake_case form of its field name, so
+-- the keys are rewritten at any depth.
+-- +goose StatementBegin
+CREATE FUNCTION ledger_json_keys_to_snake_case(value JSONB) RETURNS JSONB AS $$
+BEGIN
+    CASE jsonb_typeof(value)
+    WHEN 'object' THEN
+        RETURN (
+            SELECT coalesce(jsonb_object_agg(
+                lower(regexp_replace(regexp_replace(key, '([A-Z]+)([A-Z][a-z])', '\1_\2', 'g'), '([a-z0-9])([A-Z])', '\1_\2', 'g')),
+                ledger_json_keys_to_snake_case(item)
+            ), '{}'::JSONB)
+            FROM jsonb_each(value) AS e (key, item)
+        );
+    WHEN 'array' THEN
+        RETURN (
+            SELECT coalesce(jsonb_agg(ledger_json_keys_to_snake_case(item) ORDER BY n), '[]'::JSONB)
+            FROM jsonb_array_elements(value) WITH ORDINALITY AS e (item, n)
+        );
+    ELSE
+        RETURN value;
+    END CASE;
+END;
+$$ LANGUAGE plpgsql IMMUTABLE;
+-- +goose StatementEnd
+
+UPDATE outbox_events SET payload = ledger_json_keys_to_snake_case(payload);
+
+UPDATE audit_log
+SET before = ledger_json_keys_to_snake_case(before),
+    after = ledger_json_keys_to_snake_case(after)
+WHERE before IS NOT NULL OR after IS NOT NULL;
+
+DROP FUNCTION ledger_json_keys_to_snake_case(JSONB);
+
+-- +goose Down
+-- The only initialism in the field names is ID.
+-- +goose StatementBegin
+CREATE FUNCTION ledger_json_keys_to_field_names(value JSONB) RETURNS JSONB AS $$
+BEGIN
+    CASE jsonb_typeof(value)
+    WHEN 'object' THEN
+        RETURN (
+            SELECT coalesce(jsonb_object_agg(
+                (
+                    SELECT string_agg(CASE part WHEN 'id' THEN 'ID' ELSE initcap(part) END, '' ORDER BY n)
+                    FROM regexp_split_to_table(key, '_') WITH ORDINALITY AS p (part, n)
+                ),
+                ledger_json_keys_to_field_names(item)
+            ), '{}'::JSONB)
+            FROM jsonb_each(value) AS e (key, item)
+        );
+    WHEN 'array' THEN
+        RETURN (
+            SELECT coalesce(jsonb_agg(ledger_json_keys_to_field_names(item) ORDER BY n), '[]'::JSONB)
+            FROM jsonb_array_elements(value) WITH ORDINALITY AS e (item, n)
+        );
+    ELSE
+        RETURN value;
+    END CASE;
+END;
+$$ LANGUAGE plpgsql IMMUTABLE;
+-- +goose StatementEnd
+
+UPDATE outbox_events SET payload = ledger_json_keys_to_field_names(payload);
+
+UPDATE audit_log
+SET before = ledger_json_keys_to_field_names(before),
+    after = ledger_json_keys_to_field_names(after)
+WHERE before IS NOT NULL OR after IS NOT NULL;
+
+DROP FUNCTION ledger_json_keys_to_field_names(JSONB);